@@ -0,0 +1,21 @@
+import { fromBinary } from "@bufbuild/protobuf";
+import { GiftWithdrawPaidAfterExpiryEventSchema } from "@giftduels/protobuf-js/giftduels/gift/v1/events_pb";
+import type { ConsumerHandler } from "@/amqp/consumer";
+import { NotificationService } from "@/services/notification";
+
+export class GiftWithdrawPaidAfterExpiryHandler {
+	constructor(private notification: NotificationService) {}
+
+	public readonly handle: ConsumerHandler = async (raw, _props, ctrl) => {
+		const evt = fromBinary(GiftWithdrawPaidAfterExpiryEventSchema, raw);
+
+		await this.notification.sendGiftWithdrawPaidAfterExpiryNotification(
+			Number(evt.ownerTelegramId?.value),
+			{
+				starsAmount: evt.starsAmount?.value ?? 0,
+			},
+		);
+
+		return ctrl.ack();
+	};
+}
@@ -11,6 +11,10 @@ export class GiftWithdrawUserNotFoundHandler {
 
 		await this.notification.sendGiftWithdrawUserNotFoundNotification(
 			Number(evt.ownerTelegramId?.value),
+			{
+				giftName: evt.title,
+				slug: evt.slug,
+			},
 		);
 
 		return ctrl.ack();
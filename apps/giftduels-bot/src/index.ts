@@ -5,6 +5,7 @@ import { DuelStartedHandler } from "./amqp/handlers/DuelStartedHandler";
 import { GiftDepositedHandler } from "./amqp/handlers/GiftDepositedHandler";
 import { GiftWithdrawFailedHandler } from "./amqp/handlers/GiftWithdrawFailedHandler";
 import { GiftWithdrawnHandler } from "./amqp/handlers/GiftWithdrawnHandler";
+import { GiftWithdrawPaidAfterExpiryHandler } from "./amqp/handlers/GiftWithdrawPaidAfterExpiryHandler";
 import { GiftWithdrawUserNotFoundHandler } from "./amqp/handlers/GiftWithdrawUserNotFoundHandler";
 import { getContainer } from "./container";
 import { grpcServerPlugin } from "./grpc/plugin";
@@ -92,6 +93,23 @@ async function main() {
 				);
 			},
 		),
+		new Consumer(
+			{
+				exchange: {
+					name: "gift.events",
+					type: "topic",
+				},
+				routingKey: "gift.withdraw.paid-after-expiry",
+				maxRetries: 3,
+			},
+			async (message, properties, ctrl) => {
+				new GiftWithdrawPaidAfterExpiryHandler(notificationService).handle(
+					message,
+					properties,
+					ctrl,
+				);
+			},
+		),
 		new Consumer(
 			{
 				exchange: {
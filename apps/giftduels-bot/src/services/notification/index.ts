@@ -73,10 +73,30 @@ Please try again later or contact support if the issue persists 👉 @GiftDuelsH
 		await this.send(telegramUserId, { text, parseMode: "HTML" });
 	}
 
-	async sendGiftWithdrawUserNotFoundNotification(telegramUserId: number) {
-		const text = `⚠️ Warning! It seems you haven't interacted with our bot for a while, so we can't send you the gift.
+	async sendGiftWithdrawUserNotFoundNotification(
+		telegramUserId: number,
+		payload: { giftName: string; slug: string },
+	) {
+		const { giftName, slug } = payload;
+		const text = `⚠️ We couldn't deliver <a href="https://t.me/nft/${slug}">${giftName}</a>: Telegram doesn't let us reach you yet.
+
+The gift is back in your inventory and the withdrawal fee has been refunded.
+
+To withdraw it:
+1. Open this bot and press <b>Start</b> (or send any message, e.g. a sticker)
+2. Try withdrawing your gift again`;
+
+		await this.send(telegramUserId, { text, parseMode: "HTML" });
+	}
+
+	async sendGiftWithdrawPaidAfterExpiryNotification(
+		telegramUserId: number,
+		payload: { starsAmount: number },
+	) {
+		const { starsAmount } = payload;
+		const text = `⚠️ Your payment of ${starsAmount} ⭐ arrived after the withdrawal invoice expired, and the gifts are no longer available for withdrawal.
 
-Please send a sticker and try withdrawing your gift again.`;
+The gifts were not withdrawn. Your payment will be refunded — contact support if you have any questions 👉 @GiftDuelsHelp`;
 
 		await this.send(telegramUserId, { text, parseMode: "HTML" });
 	}
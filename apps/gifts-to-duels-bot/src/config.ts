@@ -23,6 +23,10 @@ const EnvSchema = z.object({
 	AMQP_PASSWORD: z.string(),
 	AMQP_VHOST: z.string().default("/"),
 
+	// gRPC
+	GRPC_TELEGRAM_CUSTODY_SERVICE_HOST: z.string().default("0.0.0.0"),
+	GRPC_TELEGRAM_CUSTODY_SERVICE_PORT: z.coerce.number().default(50061),
+
 	// Logging
 	LOG_LEVEL: z.enum(["debug", "info", "warn", "error"]).default("info"),
 });
@@ -80,6 +84,13 @@ class Config {
 	get logLevel() {
 		return this.env.LOG_LEVEL;
 	}
+
+	get grpc() {
+		return {
+			host: this.env.GRPC_TELEGRAM_CUSTODY_SERVICE_HOST,
+			port: this.env.GRPC_TELEGRAM_CUSTODY_SERVICE_PORT,
+		};
+	}
 }
 
 export const config = new Config(_env.data);
@@ -25,6 +25,10 @@ import {
 	TelegramUserId,
 	TelegramUserIdSchema,
 } from "@giftduels/protobuf-js/giftduels/shared/v1/common_pb";
+import {
+	CustodyGift,
+	CustodyGiftSchema,
+} from "@giftduels/protobuf-js/giftduels/telegrambot/v1/custody_service_pb";
 import { Api } from "telegram";
 import { logger } from "@/logger";
 
@@ -448,3 +452,23 @@ export function parseSavedStarGiftsToEvents(
 		parseSavedStarGiftToEvent(savedGift, ownerTelegramId),
 	);
 }
+
+/**
+ * Parse SavedStarGift of the custody account for reconciliation.
+ * Only unique (collectible) gifts are deposited, so regular ones are skipped.
+ */
+export function parseSavedStarGiftToCustody(
+	savedGift: Api.SavedStarGift,
+): CustodyGift | undefined {
+	if (!(savedGift.gift instanceof Api.StarGiftUnique)) {
+		return undefined;
+	}
+
+	const uniqueGift = savedGift.gift;
+	return create(CustodyGiftSchema, {
+		telegramGiftId: BigInt(uniqueGift.id.toString()),
+		collectibleId: uniqueGift.num,
+		title: uniqueGift.title,
+		slug: uniqueGift.slug || slugify(uniqueGift.title),
+	});
+}
@@ -0,0 +1,102 @@
+import { create, fromBinary, toBinary } from "@bufbuild/protobuf";
+import { timestampNow } from "@bufbuild/protobuf/wkt";
+import {
+	type CustodyGift,
+	type GetCustodyInventoryRequest,
+	GetCustodyInventoryRequestSchema,
+	type GetCustodyInventoryResponse,
+	GetCustodyInventoryResponseSchema,
+} from "@giftduels/protobuf-js/giftduels/telegrambot/v1/custody_service_pb";
+import * as grpc from "@grpc/grpc-js";
+import { config } from "@/config";
+import { parseSavedStarGiftToCustody } from "@/domain/gift";
+import { logger } from "@/logger";
+import type { Userbot } from "@/telegram/userbot/Userbot";
+
+const SERVICE_NAME = "giftduels.telegrambot.v1.TelegramCustodyPrivateService";
+
+const custodyServiceDefinition: grpc.ServiceDefinition = {
+	GetCustodyInventory: {
+		path: `/${SERVICE_NAME}/GetCustodyInventory`,
+		requestStream: false,
+		responseStream: false,
+		requestSerialize: (msg: GetCustodyInventoryRequest) =>
+			Buffer.from(toBinary(GetCustodyInventoryRequestSchema, msg)),
+		requestDeserialize: (buf: Buffer) =>
+			fromBinary(GetCustodyInventoryRequestSchema, buf),
+		responseSerialize: (msg: GetCustodyInventoryResponse) =>
+			Buffer.from(toBinary(GetCustodyInventoryResponseSchema, msg)),
+		responseDeserialize: (buf: Buffer) =>
+			fromBinary(GetCustodyInventoryResponseSchema, buf),
+	},
+};
+
+export function createGrpcServer(userbot: Userbot): grpc.Server {
+	const server = new grpc.Server();
+
+	server.addService(custodyServiceDefinition, {
+		GetCustodyInventory(
+			_call: grpc.ServerUnaryCall<
+				GetCustodyInventoryRequest,
+				GetCustodyInventoryResponse
+			>,
+			callback: grpc.sendUnaryData<GetCustodyInventoryResponse>,
+		) {
+			userbot
+				.getUserGifts(undefined, Number.POSITIVE_INFINITY)
+				.then(({ gifts }) => {
+					const custodyGifts = gifts
+						.map(parseSavedStarGiftToCustody)
+						.filter((g): g is CustodyGift => g !== undefined);
+
+					logger.info(
+						{ total: gifts.length, unique: custodyGifts.length },
+						"📦 Custody inventory fetched",
+					);
+
+					callback(
+						null,
+						create(GetCustodyInventoryResponseSchema, {
+							gifts: custodyGifts,
+							fetchedAt: timestampNow(),
+						}),
+					);
+				})
+				.catch((err) => {
+					logger.error({ err }, "❌ Failed to fetch custody inventory");
+					callback(
+						{ code: grpc.status.INTERNAL, message: err.message },
+						null,
+					);
+				});
+		},
+	});
+
+	return server;
+}
+
+export function startGrpcServer(server: grpc.Server): Promise<number> {
+	return new Promise((resolve, reject) => {
+		server.bindAsync(
+			`${config.grpc.host}:${config.grpc.port}`,
+			grpc.ServerCredentials.createInsecure(),
+			(err, port) => {
+				if (err) {
+					reject(err);
+					return;
+				}
+				logger.info(`✅ gRPC server listening on ${port}`);
+				resolve(port);
+			},
+		);
+	});
+}
+
+export function stopGrpcServer(server: grpc.Server): Promise<void> {
+	return new Promise((resolve) => {
+		server.tryShutdown((err) => {
+			if (err) logger.error({ err }, "gRPC shutdown error");
+			resolve();
+		});
+	});
+}
@@ -1,6 +1,7 @@
 import { closeAmqp, connectAmqp } from "@/amqp/connection";
 import { Userbot } from "@/telegram/userbot/Userbot";
 import { Consumer } from "./amqp/consumer";
+import { createGrpcServer, startGrpcServer, stopGrpcServer } from "./grpc/server";
 import { handleGiftWithdrawRequested } from "./services/eventhandler/withdraw";
 import { setupShutdownHooks } from "./shutdown";
 import { nftGiftHandler } from "./telegram/handlers/gift";
@@ -39,8 +40,13 @@ async function main() {
 
 	await giftWithdrawRequestedConsumer.start();
 
-	// 5) graceful shutdown
+	// 5) gRPC: инвентарь custody-аккаунта для сверки в service-gift
+	const grpcServer = createGrpcServer(userbot);
+	await startGrpcServer(grpcServer);
+
+	// 6) graceful shutdown
 	setupShutdownHooks(async () => {
+		await stopGrpcServer(grpcServer);
 		await userbot.close();
 		await closeAmqp();
 	});
@@ -111,7 +111,7 @@ export async function handleGiftWithdrawRequested(
 
 	try {
 		log.info("🎁 Пытаемся вывести подарок через Telegram API");
-		await userbot.transferGift({
+		const result = await userbot.transferGift({
 			userId: Number(ownerTelegramId),
 			messageId: upgradeMessageId,
 			giftId: giftId,
@@ -119,8 +119,15 @@ export async function handleGiftWithdrawRequested(
 			collectibleId: event.collectibleId,
 			title: event.title,
 			slug: event.slug,
+			commissionAmount: event.commissionAmount,
 		});
 
+		// подарок не отправлен — service-gift откатит вывод по событию UserNotFound
+		if (result === "user_not_found") {
+			log.warn("👤 Пользователь не найден, вывод будет отменён");
+			return ctrl.ack();
+		}
+
 		const withdrawnEvent = create(GiftWithdrawnEventSchema, {
 			$typeName: GiftWithdrawnEventSchema.typeName,
 			slug: event.slug,
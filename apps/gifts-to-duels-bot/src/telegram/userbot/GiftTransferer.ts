@@ -4,6 +4,7 @@ import {
 	GiftIdSchema,
 	GiftTelegramIdSchema,
 	TelegramUserIdSchema,
+	TonAmount,
 } from "@giftduels/protobuf-js/giftduels/shared/v1/common_pb";
 import { Api, TelegramClient } from "telegram";
 import { v4 as uuidv4 } from "uuid";
@@ -21,8 +22,16 @@ export interface TransferParams {
 	collectibleId: number;
 	title: string;
 	slug: string;
+	/** TON-комиссия за вывод, возвращается пользователю при откате */
+	commissionAmount?: TonAmount;
 }
 
+/**
+ * Итог перевода: "user_not_found" означает, что подарок не отправлен
+ * и вывод будет отменён по событию UserNotFound
+ */
+export type TransferResult = "transferred" | "user_not_found";
+
 export class GiftTransferer {
 	constructor(
 		private client: TelegramClient,
@@ -37,10 +46,10 @@ export class GiftTransferer {
 	async transferGift(
 		peer: Api.TypeInputPeer | undefined,
 		params: TransferParams,
-	): Promise<void> {
+	): Promise<TransferResult> {
 		if (!peer) {
 			await this.publishUserNotFound(params);
-			return;
+			return "user_not_found";
 		}
 
 		// 1) бесплатный transfer
@@ -51,7 +60,7 @@ export class GiftTransferer {
 					toId: peer,
 				}),
 			);
-			return;
+			return "transferred";
 		} catch (err) {
 			if (!this.isPaymentRequired(err)) {
 				throw err; // какая-то другая ошибка
@@ -60,6 +69,7 @@ export class GiftTransferer {
 
 		// 2) платный transfer (invoice → form → send)
 		await this.sendInvoice(peer, params);
+		return "transferred";
 	}
 
 	/** Проверяем, что Telegram вернул именно PAYMENT_REQUIRED */
@@ -114,6 +124,7 @@ export class GiftTransferer {
 			collectibleId: params.collectibleId,
 			title: params.title,
 			slug: params.slug,
+			commissionAmount: params.commissionAmount,
 		});
 
 		await this.publisher.publishProto({
@@ -7,6 +7,7 @@ import { GiftFetcher } from "@/telegram/userbot/GiftFetcher";
 import {
 	GiftTransferer,
 	TransferParams,
+	TransferResult,
 } from "@/telegram/userbot/GiftTransferer";
 import { PeerResolver } from "@/telegram/userbot/PeerResolver";
 
@@ -72,7 +73,7 @@ export class Userbot {
 	 * Обёртка над GiftTransferer: резолвит peer по params.userId
 	 * и вызывает transferGift.
 	 */
-	async transferGift(params: TransferParams): Promise<void> {
+	async transferGift(params: TransferParams): Promise<TransferResult> {
 		const peer = await this.peerResolver.resolve(params.userId);
 		return this.giftTransferer.transferGift(peer, params);
 	}
@@ -2,6 +2,7 @@ import { createClient } from "@connectrpc/connect";
 import { createConnectTransport } from "@connectrpc/connect-web";
 import { DuelPublicService } from "@giftduels/protobuf-js/giftduels/duel/v1/duel_public_service_pb";
 import { EventPublicService } from "@giftduels/protobuf-js/giftduels/event/v1/public_service_pb";
+import { GiftCatalogService } from "@giftduels/protobuf-js/giftduels/gift/v1/gift_catalog_service_pb";
 import { GiftPublicService } from "@giftduels/protobuf-js/giftduels/gift/v1/gift_public_service_pb";
 import { IdentityPublicService } from "@giftduels/protobuf-js/giftduels/identity/v1/public_service_pb";
 import { PaymentPublicService } from "@giftduels/protobuf-js/giftduels/payment/v1/public_service_pb";
@@ -16,6 +17,8 @@ export const transport = createConnectTransport({
 
 export const giftClient = createClient(GiftPublicService, transport);
 
+export const giftCatalogClient = createClient(GiftCatalogService, transport);
+
 export const identityClient = createClient(IdentityPublicService, transport);
 
 export const paymentClient = createClient(PaymentPublicService, transport);
@@ -1,6 +1,10 @@
 -- name: GetDuelByID :one
 SELECT * FROM duels WHERE id = $1;
 
+-- name: GetDuelsByIDs :many
+SELECT * FROM duels WHERE id = ANY(sqlc.arg('ids')::uuid[])
+ORDER BY created_at DESC;
+
 -- name: GetDuels :many
 SELECT * FROM duels
 ORDER BY created_at DESC
@@ -111,3 +115,10 @@ JOIN duel_stakes s ON s.duel_id = d.id
 WHERE s.gift_id = $1
 ORDER BY d.created_at DESC
 LIMIT 1;
+
+-- name: FindDuelsByGiftIDs :many
+SELECT DISTINCT ON (s.gift_id) s.gift_id, d.id AS duel_id
+FROM duels d
+JOIN duel_stakes s ON s.duel_id = d.id
+WHERE s.gift_id = ANY(sqlc.arg('gift_ids')::uuid[])
+ORDER BY s.gift_id, d.created_at DESC;
@@ -5,6 +5,8 @@ import (
 
 	"github.com/ThreeDotsLabs/watermill/message"
 	"github.com/ThreeDotsLabs/watermill/message/router/middleware"
+	"github.com/peterparker2005/giftduels/apps/service-duel/internal/config"
+	duelevents "github.com/peterparker2005/giftduels/packages/events/duel"
 	"github.com/peterparker2005/giftduels/packages/logger-go"
 )
 
@@ -19,6 +21,7 @@ const (
 func ProvideRouter(
 	log *logger.Logger,
 	pub message.Publisher,
+	cfg *config.Config,
 ) (*message.Router, error) {
 	r, err := message.NewRouter(message.RouterConfig{}, logger.NewWatermill(log))
 	if err != nil {
@@ -31,8 +34,8 @@ func ProvideRouter(
 		InitialInterval: defaultInitialInterval,
 		Multiplier:      defaultMultiplier,
 	}
-	// TODO: add poison key to config?
-	poison, err := middleware.PoisonQueue(pub, "duel.events.poison")
+	duelCfg := duelevents.Config(cfg.ServiceName.String())
+	poison, err := middleware.PoisonQueue(pub, duelCfg.PoisonTopic())
 	if err != nil {
 		return nil, err
 	}
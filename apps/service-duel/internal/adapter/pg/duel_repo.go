@@ -74,6 +74,42 @@ func (r *duelRepository) GetDuelByID(
 	return duel, nil
 }
 
+func (r *duelRepository) GetDuelSummariesByIDs(
+	ctx context.Context,
+	ids []duelDomain.ID,
+) ([]*duelDomain.Duel, error) {
+	pgIDs := make([]pgtype.UUID, len(ids))
+	for i, id := range ids {
+		pgID, err := pgUUID(id.String())
+		if err != nil {
+			return nil, err
+		}
+		pgIDs[i] = pgID
+	}
+
+	sqlcDuels, err := r.q.GetDuelsByIDs(ctx, pgIDs)
+	if err != nil {
+		r.logger.Error("failed to get duels by ids", zap.Error(err))
+		return nil, err
+	}
+
+	duels := make([]*duelDomain.Duel, len(sqlcDuels))
+	for i, sqlcDuel := range sqlcDuels {
+		duel, mapErr := mapDuel(&sqlcDuel)
+		if mapErr != nil {
+			r.logger.Error("failed to map duel", zap.Error(mapErr))
+			return nil, mapErr
+		}
+		if err = r.loadParticipants(ctx, sqlcDuel.ID, duel); err != nil {
+			r.logger.Error("failed to load duel participants", zap.Error(err))
+			return nil, err
+		}
+		duels[i] = duel
+	}
+
+	return duels, nil
+}
+
 func (r *duelRepository) GetDuelList(
 	ctx context.Context,
 	pageRequest *shared.PageRequest,
@@ -472,3 +508,33 @@ func (r *duelRepository) FindDuelByGiftID(
 	}
 	return duelDomain.NewID(duelID.String())
 }
+
+func (r *duelRepository) FindDuelsByGiftIDs(
+	ctx context.Context,
+	giftIDs []string,
+) (map[string]duelDomain.ID, error) {
+	pgGiftIDs := make([]pgtype.UUID, len(giftIDs))
+	for i, giftID := range giftIDs {
+		pgGiftID, err := pgUUID(giftID)
+		if err != nil {
+			return nil, err
+		}
+		pgGiftIDs[i] = pgGiftID
+	}
+
+	rows, err := r.q.FindDuelsByGiftIDs(ctx, pgGiftIDs)
+	if err != nil {
+		r.logger.Error("failed to find duels by gift ids", zap.Error(err))
+		return nil, err
+	}
+
+	out := make(map[string]duelDomain.ID, len(rows))
+	for _, row := range rows {
+		duelID, idErr := duelDomain.NewID(row.DuelID.String())
+		if idErr != nil {
+			return nil, idErr
+		}
+		out[row.GiftID.String()] = duelID
+	}
+	return out, nil
+}
@@ -179,6 +179,39 @@ func (q *Queries) FindDuelByGiftID(ctx context.Context, giftID pgtype.UUID) (pgt
 	return id, err
 }
 
+const findDuelsByGiftIDs = `-- name: FindDuelsByGiftIDs :many
+SELECT DISTINCT ON (s.gift_id) s.gift_id, d.id AS duel_id
+FROM duels d
+JOIN duel_stakes s ON s.duel_id = d.id
+WHERE s.gift_id = ANY($1::uuid[])
+ORDER BY s.gift_id, d.created_at DESC
+`
+
+type FindDuelsByGiftIDsRow struct {
+	GiftID pgtype.UUID
+	DuelID pgtype.UUID
+}
+
+func (q *Queries) FindDuelsByGiftIDs(ctx context.Context, giftIds []pgtype.UUID) ([]FindDuelsByGiftIDsRow, error) {
+	rows, err := q.db.Query(ctx, findDuelsByGiftIDs, giftIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FindDuelsByGiftIDsRow
+	for rows.Next() {
+		var i FindDuelsByGiftIDsRow
+		if err := rows.Scan(&i.GiftID, &i.DuelID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const get1v1Duels = `-- name: Get1v1Duels :many
 SELECT id, display_number, is_private, max_players, max_gifts, winner_telegram_user_id, next_roll_deadline, status, created_at, updated_at, completed_at FROM duels
 WHERE status IN ('waiting_for_opponent', 'in_progress')
@@ -407,6 +440,43 @@ func (q *Queries) GetDuels(ctx context.Context, arg GetDuelsParams) ([]Duel, err
 	return items, nil
 }
 
+const getDuelsByIDs = `-- name: GetDuelsByIDs :many
+SELECT id, display_number, is_private, max_players, max_gifts, winner_telegram_user_id, next_roll_deadline, status, created_at, updated_at, completed_at FROM duels WHERE id = ANY($1::uuid[])
+ORDER BY created_at DESC
+`
+
+func (q *Queries) GetDuelsByIDs(ctx context.Context, ids []pgtype.UUID) ([]Duel, error) {
+	rows, err := q.db.Query(ctx, getDuelsByIDs, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Duel
+	for rows.Next() {
+		var i Duel
+		if err := rows.Scan(
+			&i.ID,
+			&i.DisplayNumber,
+			&i.IsPrivate,
+			&i.MaxPlayers,
+			&i.MaxGifts,
+			&i.WinnerTelegramUserID,
+			&i.NextRollDeadline,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CompletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getMyDuels = `-- name: GetMyDuels :many
 SELECT DISTINCT d.id, d.display_number, d.is_private, d.max_players, d.max_gifts, d.winner_telegram_user_id, d.next_roll_deadline, d.status, d.created_at, d.updated_at, d.completed_at FROM duels d
 JOIN duel_participants dp ON d.id = dp.duel_id
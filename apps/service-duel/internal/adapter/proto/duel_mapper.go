@@ -86,6 +86,35 @@ func MapDuel(duel *duelDomain.Duel) (*duelv1.Duel, error) {
 	return result, nil
 }
 
+// MapDuelSummary maps a duel loaded with participants only.
+func MapDuelSummary(duel *duelDomain.Duel) *duelv1.DuelSummary {
+	result := &duelv1.DuelSummary{
+		DuelId:        &sharedv1.DuelId{Value: duel.ID.String()},
+		DisplayNumber: duel.DisplayNumber,
+		Status:        MapDuelStatus(duel.Status),
+		CreatedAt:     timestamppb.New(duel.CreatedAt),
+	}
+
+	if duel.WinnerID != nil {
+		result.WinnerTelegramUserId = &sharedv1.TelegramUserId{
+			Value: int64(*duel.WinnerID),
+		}
+	}
+
+	if duel.CompletedAt != nil {
+		result.CompletedAt = timestamppb.New(*duel.CompletedAt)
+	}
+
+	result.ParticipantTelegramUserIds = make([]*sharedv1.TelegramUserId, len(duel.Participants))
+	for i, participant := range duel.Participants {
+		result.ParticipantTelegramUserIds[i] = &sharedv1.TelegramUserId{
+			Value: participant.TelegramUserID.Int64(),
+		}
+	}
+
+	return result
+}
+
 func MapDuelParams(params duelDomain.Params) (*duelv1.DuelParams, error) {
 	maxPlayers, err := safecast.ToUint32(params.MaxPlayers)
 	if err != nil {
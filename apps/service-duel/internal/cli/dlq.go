@@ -0,0 +1,23 @@
+package cli
+
+import (
+	"github.com/peterparker2005/giftduels/apps/service-duel/internal/config"
+	"github.com/peterparker2005/giftduels/packages/cli-go/command/dlq"
+	duelEvents "github.com/peterparker2005/giftduels/packages/events/duel"
+	"github.com/spf13/cobra"
+)
+
+func newCmdDLQ() *cobra.Command {
+	return dlq.NewCmdDLQ(func() (*dlq.Setup, error) {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return nil, err
+		}
+
+		// у duel-worker'а пока нет обработчиков, payload выводится как есть
+		return &dlq.Setup{
+			AMQPURI: cfg.AMQP.Address(),
+			Poison:  duelEvents.Config(cfg.ServiceName.String()),
+		}, nil
+	})
+}
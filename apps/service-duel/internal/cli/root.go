@@ -12,6 +12,7 @@ func rootCmd() *cobra.Command {
 		newCmdServe(),
 		newCmdMigrate(),
 		newCmdWorker(),
+		newCmdDLQ(),
 	)
 
 	return cmd
@@ -27,6 +27,9 @@ type Repository interface {
 	UpdateNextRollDeadline(ctx context.Context, duelID ID, nextRollDeadline time.Time) error
 
 	GetDuelByID(ctx context.Context, id ID) (*Duel, error)
+	// GetDuelSummariesByIDs возвращает дуэли только с участниками,
+	// без ставок и раундов. Неизвестные id пропускаются.
+	GetDuelSummariesByIDs(ctx context.Context, ids []ID) ([]*Duel, error)
 	GetDuelList(
 		ctx context.Context,
 		pageRequest *shared.PageRequest,
@@ -34,4 +37,7 @@ type Repository interface {
 		telegramUserID *TelegramUserID,
 	) ([]*Duel, int64, error)
 	FindDuelByGiftID(ctx context.Context, giftID string) (ID, error)
+	// FindDuelsByGiftIDs возвращает последнюю дуэль для каждого подарка.
+	// Подарки, которые ни разу не ставились, в результат не попадают.
+	FindDuelsByGiftIDs(ctx context.Context, giftIDs []string) (map[string]ID, error)
 }
@@ -24,6 +24,7 @@ type DuelCreateCommand struct {
 	repo              dueldomain.Repository
 	txManager         pg.TxManager
 	giftPrivateClient giftv1.GiftPrivateServiceClient
+	stakeConfirmer    *stakeConfirmer
 }
 
 func NewDuelCreateCommand(
@@ -38,6 +39,7 @@ func NewDuelCreateCommand(
 		txManager:         txManager,
 		log:               log,
 		giftPrivateClient: clients.Gift.Private,
+		stakeConfirmer:    newStakeConfirmer(clients.Gift.Private, log),
 		publisher:         publisher,
 	}
 }
@@ -61,13 +63,14 @@ func (c *DuelCreateCommand) Execute(
 	// 2. Подготовка defer‑компенсатора
 	var (
 		stakedGiftIDs []string
+		duel          *dueldomain.Duel
 		duelID        dueldomain.ID
 		execErr       error
 	)
 	defer func() {
 		if execErr != nil {
 			if len(stakedGiftIDs) > 0 {
-				if compErr := c.returnStakedGifts(stakedGiftIDs); compErr != nil {
+				if compErr := c.returnStakedGifts(duel.ID, stakedGiftIDs); compErr != nil {
 					c.log.Error("compensation failed", zap.Error(compErr))
 				}
 			}
@@ -79,7 +82,7 @@ func (c *DuelCreateCommand) Execute(
 
 	// 3. Создаем доменный объект
 	repo := c.repo.WithTx(tx)
-	duel := dueldomain.NewDuel(params.Params)
+	duel = dueldomain.NewDuel(params.Params)
 	creator, err := dueldomain.NewParticipantBuilder().
 		WithTelegramUserID(dueldomain.TelegramUserID(telegramUserID)).
 		WithPhoto("").
@@ -111,7 +114,12 @@ func (c *DuelCreateCommand) Execute(
 		return "", ErrTransactionFailed
 	}
 
-	// 8. Публикация события о создании дуэли (ошибки логируем, но не откатываем)
+	// 8. Подтверждаем ставки только после коммита — гифты остаются в игре, лишь
+	// если дуэль сохранена. Гифты стейкались под duel.ID, поэтому подтверждаем
+	// по нему же; при сбое подтверждение повторяется в фоне.
+	c.stakeConfirmer.confirm(ctx, duel.ID, stakedGiftIDs)
+
+	// 9. Публикация события о создании дуэли (ошибки логируем, но не откатываем)
 	if pubErr := c.publishDuelCreated(duel); pubErr != nil {
 		c.log.Error("failed to publish duel created", zap.Error(pubErr))
 	}
@@ -190,10 +198,11 @@ func (c *DuelCreateCommand) saveDuel(
 	return duelID, nil
 }
 
-func (c *DuelCreateCommand) returnStakedGifts(giftIDs []string) error {
+func (c *DuelCreateCommand) returnStakedGifts(duelID dueldomain.ID, giftIDs []string) error {
 	for _, giftID := range giftIDs {
 		c.log.Info("returning gift from game due to error", zap.String("giftID", giftID))
 		msg := message.NewMessage(uuid.New().String(), []byte(giftID))
+		msg.Metadata.Set(duelevents.MetadataDuelID, duelID.String())
 		if err := c.publisher.Publish(duelevents.TopicDuelCreateFailed.String(), msg); err != nil {
 			c.log.Error(
 				"failed to return gift during cleanup",
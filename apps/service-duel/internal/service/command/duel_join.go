@@ -12,6 +12,7 @@ import (
 	duelevents "github.com/peterparker2005/giftduels/packages/events/duel"
 	"github.com/peterparker2005/giftduels/packages/grpc-go/clients"
 	"github.com/peterparker2005/giftduels/packages/logger-go"
+	duelv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/duel/v1"
 	giftv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/gift/v1"
 	sharedv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/shared/v1"
 	"github.com/peterparker2005/giftduels/packages/tonamount-go"
@@ -25,6 +26,7 @@ type DuelJoinCommand struct {
 	repo              dueldomain.Repository
 	txManager         pg.TxManager
 	giftPrivateClient giftv1.GiftPrivateServiceClient
+	stakeConfirmer    *stakeConfirmer
 	scheduler         dueldomain.Scheduler
 }
 
@@ -41,6 +43,7 @@ func NewDuelJoinCommand(
 		txManager:         txManager,
 		log:               log,
 		giftPrivateClient: clients.Gift.Private,
+		stakeConfirmer:    newStakeConfirmer(clients.Gift.Private, log),
 		publisher:         publisher,
 		scheduler:         scheduler,
 	}
@@ -66,7 +69,7 @@ func (c *DuelJoinCommand) Execute(
 	defer func() {
 		if execErr != nil {
 			if len(stakedGiftIDs) > 0 {
-				if compErr := c.returnStakedGifts(stakedGiftIDs); compErr != nil {
+				if compErr := c.returnStakedGifts(duelID, stakedGiftIDs); compErr != nil {
 					c.log.Error("compensation failed", zap.Error(compErr))
 				}
 			}
@@ -164,6 +167,7 @@ func (c *DuelJoinCommand) Execute(
 		if execErr = tx.Commit(ctx); execErr != nil {
 			return ErrTransactionFailed
 		}
+		c.stakeConfirmer.confirm(ctx, duelID, stakedGiftIDs)
 		return nil
 	}
 
@@ -177,6 +181,9 @@ func (c *DuelJoinCommand) Execute(
 		return ErrTransactionFailed
 	}
 
+	// 12.1. Подтверждаем ставки только после коммита
+	c.stakeConfirmer.confirm(ctx, duelID, stakedGiftIDs)
+
 	// 13. Публикация события о присоединении к дуэли (ошибки логируем, но не откатываем)
 	if pubErr := c.publishDuelJoined(duel, telegramUserID); pubErr != nil {
 		c.log.Error("failed to publish duel joined", zap.Error(pubErr))
@@ -253,10 +260,64 @@ func (c *DuelJoinCommand) reserveStakes(
 	return stakes, staked, nil
 }
 
-func (c *DuelJoinCommand) returnStakedGifts(giftIDs []string) error {
+// ExecuteAutoPick присоединяет пользователя к дуэли, подбирая его подарки
+// под диапазон входа, и возвращает ID поставленных подарков.
+func (c *DuelJoinCommand) ExecuteAutoPick(
+	ctx context.Context,
+	duelID dueldomain.ID,
+	telegramUserID int64,
+) ([]string, error) {
+	duel, err := c.repo.GetDuelByID(ctx, duelID)
+	if err != nil {
+		return nil, ErrDuelNotFound
+	}
+	if err = c.loadGiftPrices(ctx, duel); err != nil {
+		return nil, err
+	}
+
+	minEntryPrice, maxEntryPrice, err := duel.EntryPriceRange()
+	if err != nil {
+		c.log.Error("failed to get duel entry price range",
+			zap.String("duelID", duelID.String()),
+			zap.Error(err))
+		return nil, ErrJoinDuel
+	}
+
+	resp, err := c.giftPrivateClient.PrivateSuggestStake(ctx, &giftv1.PrivateSuggestStakeRequest{
+		TelegramUserId: &sharedv1.TelegramUserId{Value: telegramUserID},
+		EntryPriceRange: &duelv1.EntryPriceRange{
+			MinEntryPrice: &sharedv1.TonAmount{Value: minEntryPrice.String()},
+			MaxEntryPrice: &sharedv1.TonAmount{Value: maxEntryPrice.String()},
+		},
+		MaxGifts: googleproto.Int32(duel.Params.MaxGifts.Int32()),
+	})
+	if err != nil {
+		c.log.Error("failed to suggest stake",
+			zap.String("duelID", duelID.String()),
+			zap.Int64("telegramUserID", telegramUserID),
+			zap.Error(err))
+		return nil, ErrJoinDuel
+	}
+	if len(resp.GetGifts()) == 0 {
+		return nil, ErrNoSuitableStake
+	}
+
+	giftIDs := make([]string, len(resp.GetGifts()))
+	for i, g := range resp.GetGifts() {
+		giftIDs[i] = g.GetGiftId().GetValue()
+	}
+
+	if err = c.Execute(ctx, duelID, giftIDs, telegramUserID); err != nil {
+		return nil, err
+	}
+	return giftIDs, nil
+}
+
+func (c *DuelJoinCommand) returnStakedGifts(duelID dueldomain.ID, giftIDs []string) error {
 	for _, giftID := range giftIDs {
 		c.log.Info("returning gift from game due to error", zap.String("giftID", giftID))
 		msg := message.NewMessage(uuid.New().String(), []byte(giftID))
+		msg.Metadata.Set(duelevents.MetadataDuelID, duelID.String())
 		if err := c.publisher.Publish(duelevents.TopicDuelCreateFailed.String(), msg); err != nil {
 			c.log.Error(
 				"failed to return gift during cleanup",
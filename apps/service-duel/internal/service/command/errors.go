@@ -16,6 +16,7 @@ var (
 	ErrStakeOutOfRange   = errors.New("stake is out of allowed entry range")
 	ErrGiftStakingFailed = errors.New("failed to stake gift")
 	ErrInvalidGiftPrice  = errors.New("invalid gift price")
+	ErrNoSuitableStake   = errors.New("no owned gifts fit the duel entry price range")
 
 	// Auto roll errors.
 
@@ -72,6 +73,10 @@ func IsInvalidGiftPrice(err error) bool {
 	return errors.Is(err, ErrInvalidGiftPrice)
 }
 
+func IsNoSuitableStake(err error) bool {
+	return errors.Is(err, ErrNoSuitableStake)
+}
+
 // Auto roll error checkers.
 
 func IsAutoRoll(err error) bool {
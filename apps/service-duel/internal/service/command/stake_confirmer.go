@@ -0,0 +1,122 @@
+package command
+
+import (
+	"context"
+	"time"
+
+	dueldomain "github.com/peterparker2005/giftduels/apps/service-duel/internal/domain/duel"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+	giftv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/gift/v1"
+	sharedv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/shared/v1"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// confirmRetryMinDelay и confirmRetryMaxDelay ограничивают экспоненциальную
+	// задержку между повторами подтверждения ставок.
+	confirmRetryMinDelay = 500 * time.Millisecond
+	confirmRetryMaxDelay = 30 * time.Second
+	// confirmAttemptTimeout — таймаут одной попытки подтверждения в фоне.
+	confirmAttemptTimeout = 10 * time.Second
+)
+
+// stakeConfirmer подтверждает аренду гифтов уже сохраненной дуэли. Дуэль
+// закоммичена, поэтому подтверждение не бросаем: повторяем в фоне, пока gift
+// service его не примет. Если процесс упадет раньше, gift service при разборе
+// просроченных аренд сам подтвердит аренду существующей дуэли.
+type stakeConfirmer struct {
+	giftPrivateClient giftv1.GiftPrivateServiceClient
+	log               *logger.Logger
+}
+
+func newStakeConfirmer(
+	giftPrivateClient giftv1.GiftPrivateServiceClient,
+	log *logger.Logger,
+) *stakeConfirmer {
+	return &stakeConfirmer{
+		giftPrivateClient: giftPrivateClient,
+		log:               log,
+	}
+}
+
+// confirm делает первую попытку синхронно, а при временной ошибке
+// продолжает повторы в фоне.
+func (s *stakeConfirmer) confirm(ctx context.Context, duelID dueldomain.ID, giftIDs []string) {
+	if len(giftIDs) == 0 {
+		return
+	}
+	err := s.confirmOnce(ctx, duelID, giftIDs)
+	if err == nil {
+		return
+	}
+	if leaseLost(err) {
+		s.logLeaseLost(duelID, giftIDs, err)
+		return
+	}
+
+	s.log.Warn("failed to confirm staked gifts, retrying in background",
+		zap.String("duelID", duelID.String()),
+		zap.Strings("giftIDs", giftIDs),
+		zap.Error(err))
+	go s.retry(duelID, giftIDs)
+}
+
+func (s *stakeConfirmer) retry(duelID dueldomain.ID, giftIDs []string) {
+	delay := confirmRetryMinDelay
+	for {
+		time.Sleep(delay)
+
+		ctx, cancel := context.WithTimeout(context.Background(), confirmAttemptTimeout)
+		err := s.confirmOnce(ctx, duelID, giftIDs)
+		cancel()
+		switch {
+		case err == nil:
+			s.log.Info("confirmed staked gifts after retry",
+				zap.String("duelID", duelID.String()),
+				zap.Strings("giftIDs", giftIDs))
+			return
+		case leaseLost(err):
+			s.logLeaseLost(duelID, giftIDs, err)
+			return
+		}
+
+		s.log.Warn("failed to confirm staked gifts, will retry",
+			zap.String("duelID", duelID.String()),
+			zap.Duration("delay", delay),
+			zap.Error(err))
+		delay = min(delay*2, confirmRetryMaxDelay)
+	}
+}
+
+func (s *stakeConfirmer) confirmOnce(
+	ctx context.Context,
+	duelID dueldomain.ID,
+	giftIDs []string,
+) error {
+	req := &giftv1.ConfirmStakeRequest{
+		DuelId:  &sharedv1.DuelId{Value: duelID.String()},
+		GiftIds: make([]*sharedv1.GiftId, 0, len(giftIDs)),
+	}
+	for _, giftID := range giftIDs {
+		req.GiftIds = append(req.GiftIds, &sharedv1.GiftId{Value: giftID})
+	}
+	_, err := s.giftPrivateClient.ConfirmStake(ctx, req)
+	return err
+}
+
+// logLeaseLost — повтор бесполезен: аренда уже освобождена, и гифты
+// вернулись владельцам. Победитель их не получит, это нужно разбирать вручную.
+func (s *stakeConfirmer) logLeaseLost(duelID dueldomain.ID, giftIDs []string, err error) {
+	s.log.Error("staked gifts were released before the duel confirmed them",
+		zap.String("duelID", duelID.String()),
+		zap.Strings("giftIDs", giftIDs),
+		zap.Error(err))
+}
+
+// leaseLost сообщает, что gift service отказал в подтверждении, потому что
+// гифты уже не удерживаются дуэлью.
+func leaseLost(err error) bool {
+	return status.Code(err) == codes.FailedPrecondition
+}
@@ -194,3 +194,33 @@ func (s *DuelQueryService) FindDuelByGiftID(
 ) (dueldomain.ID, error) {
 	return s.repo.FindDuelByGiftID(ctx, giftID)
 }
+
+func (s *DuelQueryService) FindDuelsByGiftIDs(
+	ctx context.Context,
+	giftIDs []string,
+) (map[string]dueldomain.ID, error) {
+	if len(giftIDs) == 0 {
+		return map[string]dueldomain.ID{}, nil
+	}
+	duels, err := s.repo.FindDuelsByGiftIDs(ctx, giftIDs)
+	if err != nil {
+		s.log.Error("failed to find duels by gift ids", zap.Error(err))
+		return nil, ErrDatabase
+	}
+	return duels, nil
+}
+
+func (s *DuelQueryService) GetDuelSummaries(
+	ctx context.Context,
+	duelIDs []dueldomain.ID,
+) ([]*dueldomain.Duel, error) {
+	if len(duelIDs) == 0 {
+		return nil, nil
+	}
+	duels, err := s.repo.GetDuelSummariesByIDs(ctx, duelIDs)
+	if err != nil {
+		s.log.Error("failed to get duel summaries", zap.Error(err))
+		return nil, ErrDatabase
+	}
+	return duels, nil
+}
@@ -117,6 +117,13 @@ func MapError(ctx context.Context, err error) error {
 			pkgerrors.WithMessage("invalid gift price"),
 			pkgerrors.WithContext(ctx),
 		)
+	case command.IsNoSuitableStake(err):
+		return pkgerrors.NewError(
+			pkgerrors.WithGRPCCode(codes.FailedPrecondition),
+			pkgerrors.WithErrorCode(errorsv1.ErrorCode_ERROR_CODE_GIFT_NOT_AVAILABLE),
+			pkgerrors.WithMessage("no owned gifts fit the duel entry price range"),
+			pkgerrors.WithContext(ctx),
+		)
 	case command.IsAutoRoll(err):
 		return pkgerrors.NewError(
 			pkgerrors.WithGRPCCode(codes.Internal),
@@ -3,6 +3,8 @@ package grpchandlers
 import (
 	"context"
 
+	"github.com/peterparker2005/giftduels/apps/service-duel/internal/adapter/proto"
+	dueldomain "github.com/peterparker2005/giftduels/apps/service-duel/internal/domain/duel"
 	"github.com/peterparker2005/giftduels/apps/service-duel/internal/service/command"
 	"github.com/peterparker2005/giftduels/apps/service-duel/internal/service/query"
 	duelv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/duel/v1"
@@ -39,3 +41,52 @@ func (h *DuelPrivateHandler) FindDuelByGiftID(
 	}
 	return &duelv1.FindDuelByGiftIDResponse{DuelId: &sharedv1.DuelId{Value: duelID.String()}}, nil
 }
+
+func (h *DuelPrivateHandler) FindDuelsByGiftIDs(
+	ctx context.Context,
+	req *duelv1.FindDuelsByGiftIDsRequest,
+) (*duelv1.FindDuelsByGiftIDsResponse, error) {
+	giftIDs := make([]string, len(req.GetGiftIds()))
+	for i, id := range req.GetGiftIds() {
+		giftIDs[i] = id.GetValue()
+	}
+
+	duelIDs, err := h.duelQueryService.FindDuelsByGiftIDs(ctx, giftIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	giftDuels := make([]*duelv1.FindDuelsByGiftIDsResponse_GiftDuel, 0, len(duelIDs))
+	for giftID, duelID := range duelIDs {
+		giftDuels = append(giftDuels, &duelv1.FindDuelsByGiftIDsResponse_GiftDuel{
+			GiftId: &sharedv1.GiftId{Value: giftID},
+			DuelId: &sharedv1.DuelId{Value: duelID.String()},
+		})
+	}
+	return &duelv1.FindDuelsByGiftIDsResponse{GiftDuels: giftDuels}, nil
+}
+
+func (h *DuelPrivateHandler) GetDuelSummaries(
+	ctx context.Context,
+	req *duelv1.GetDuelSummariesRequest,
+) (*duelv1.GetDuelSummariesResponse, error) {
+	duelIDs := make([]dueldomain.ID, 0, len(req.GetDuelIds()))
+	for _, id := range req.GetDuelIds() {
+		duelID, err := dueldomain.NewID(id.GetValue())
+		if err != nil {
+			return nil, err
+		}
+		duelIDs = append(duelIDs, duelID)
+	}
+
+	duels, err := h.duelQueryService.GetDuelSummaries(ctx, duelIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]*duelv1.DuelSummary, len(duels))
+	for i, duel := range duels {
+		summaries[i] = proto.MapDuelSummary(duel)
+	}
+	return &duelv1.GetDuelSummariesResponse{Duels: summaries}, nil
+}
@@ -202,3 +202,35 @@ func (h *duelPublicHandler) JoinDuel(
 
 	return &duelv1.JoinDuelResponse{}, nil
 }
+
+func (h *duelPublicHandler) AutoJoinDuel(
+	ctx context.Context,
+	req *duelv1.AutoJoinDuelRequest,
+) (*duelv1.AutoJoinDuelResponse, error) {
+	telegramUserID, err := authctx.TelegramUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	duelIDStr := req.GetDuelId().GetValue()
+	duelID, err := duelDomain.NewID(duelIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	giftIDs, err := h.duelJoinCommand.ExecuteAutoPick(ctx, duelID, telegramUserID)
+	if err != nil {
+		h.logger.Error("failed to auto join duel",
+			zap.Int64("telegramUserID", telegramUserID),
+			zap.String("duelID", duelIDStr),
+			zap.Error(err))
+		return nil, err
+	}
+
+	stakes := make([]*duelv1.Stake, len(giftIDs))
+	for i, giftID := range giftIDs {
+		stakes[i] = &duelv1.Stake{GiftId: &sharedv1.GiftId{Value: giftID}}
+	}
+
+	return &duelv1.AutoJoinDuelResponse{Stakes: stakes}, nil
+}
@@ -8,6 +8,8 @@ import (
 	"github.com/ThreeDotsLabs/watermill/message"
 	"github.com/peterparker2005/giftduels/apps/service-duel/internal/adapter/amqp"
 	"github.com/peterparker2005/giftduels/apps/service-duel/internal/config"
+	"github.com/peterparker2005/giftduels/packages/events"
+	duelevents "github.com/peterparker2005/giftduels/packages/events/duel"
 	"github.com/peterparker2005/giftduels/packages/logger-go"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
@@ -20,10 +22,24 @@ var Module = fx.Module("worker",
 	// handlers
 	),
 
+	// poison-очередь нужна и без обработчиков: router уже настроен на неё
+	fx.Invoke(declarePoisonQueue),
+
 	// инициализируем router + forwarder
 	// fx.Invoke(registerHandlers),
 )
 
+// declarePoisonQueue объявляет poison-очередь заранее, иначе exchange
+// отбросит сообщения.
+func declarePoisonQueue(cfg *config.Config, subFac amqp.SubFactory) error {
+	duelCfg := duelevents.Config(cfg.ServiceName.String())
+	duelSub, err := subFac(duelCfg)
+	if err != nil {
+		return err
+	}
+	return events.DeclarePoisonQueue(duelSub, duelCfg)
+}
+
 func registerHandlers(
 	lc fx.Lifecycle,
 	cfg *config.Config,
@@ -0,0 +1,45 @@
+-- Migration: marketplace (DOWN)
+-- Created at: 2026-10-19 10:00:00
+-- Description: Rollback for marketplace
+
+DROP TRIGGER IF EXISTS trg_gift_listings_set_updated_at ON gift_listings;
+DROP TABLE IF EXISTS gift_listings;
+DROP TYPE IF EXISTS listing_status;
+
+-- Выставленные подарки возвращаем владельцам
+UPDATE gifts SET status = 'owned' WHERE status = 'listed';
+
+-- Postgres не умеет удалять значения из enum, поэтому пересоздаём типы
+DELETE FROM gift_events WHERE event_type IN ('list', 'unlist', 'sale');
+
+DROP INDEX IF EXISTS ux_active_tg_id;
+
+ALTER TYPE gift_status RENAME TO gift_status_old;
+CREATE TYPE gift_status AS ENUM (
+  'owned',
+  'in_game',
+  'withdraw_pending',
+  'withdrawn'
+);
+ALTER TABLE gifts ALTER COLUMN status DROP DEFAULT;
+ALTER TABLE gifts ALTER COLUMN status TYPE gift_status USING status::text::gift_status;
+ALTER TABLE gifts ALTER COLUMN status SET DEFAULT 'owned';
+DROP TYPE gift_status_old;
+
+CREATE UNIQUE INDEX ux_active_tg_id
+  ON gifts(telegram_gift_id)
+  WHERE status <> 'withdrawn';
+
+ALTER TYPE gift_event_type RENAME TO gift_event_type_old;
+CREATE TYPE gift_event_type AS ENUM (
+  'stake',
+  'return_from_game',
+  'deposit',
+  'withdraw_request',
+  'withdraw_complete',
+  'withdraw_fail'
+);
+ALTER TABLE gift_events ALTER COLUMN event_type DROP DEFAULT;
+ALTER TABLE gift_events ALTER COLUMN event_type TYPE gift_event_type USING event_type::text::gift_event_type;
+ALTER TABLE gift_events ALTER COLUMN event_type SET DEFAULT 'stake';
+DROP TYPE gift_event_type_old;
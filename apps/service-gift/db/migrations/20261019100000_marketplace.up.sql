@@ -0,0 +1,41 @@
+-- Migration: marketplace
+-- Created at: 2026-10-19 10:00:00
+-- Description: Add listed gift status and gift_listings table for the internal marketplace
+
+ALTER TYPE gift_status ADD VALUE IF NOT EXISTS 'listed';
+
+ALTER TYPE gift_event_type ADD VALUE IF NOT EXISTS 'list';
+ALTER TYPE gift_event_type ADD VALUE IF NOT EXISTS 'unlist';
+ALTER TYPE gift_event_type ADD VALUE IF NOT EXISTS 'sale';
+
+CREATE TYPE listing_status AS ENUM (
+  'active',
+  'sold',
+  'cancelled'
+);
+
+CREATE TABLE gift_listings (
+  id                  UUID           PRIMARY KEY DEFAULT gen_random_uuid(),
+  gift_id             UUID           NOT NULL REFERENCES gifts(id) ON DELETE CASCADE,
+  seller_telegram_id  BIGINT         NOT NULL,
+  buyer_telegram_id   BIGINT,
+  price               NUMERIC(20, 2) NOT NULL,
+  fee                 NUMERIC(20, 2),
+  status              listing_status NOT NULL DEFAULT 'active',
+  created_at          TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+  updated_at          TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+  sold_at             TIMESTAMPTZ
+);
+
+-- Only one active listing per gift
+CREATE UNIQUE INDEX ux_gift_listings_active_gift
+  ON gift_listings(gift_id)
+  WHERE status = 'active';
+
+CREATE INDEX ix_gift_listings_status_created_at
+  ON gift_listings(status, created_at DESC);
+
+CREATE TRIGGER trg_gift_listings_set_updated_at
+BEFORE UPDATE ON gift_listings
+FOR EACH ROW
+EXECUTE FUNCTION set_updated_at_timestamp();
@@ -0,0 +1,25 @@
+-- Migration: sell_back (DOWN)
+-- Created at: 2026-10-19 11:00:00
+-- Description: Rollback for sell_back
+
+DROP TABLE IF EXISTS gift_sell_back_quotes;
+
+-- Postgres не умеет удалять значения из enum, поэтому пересоздаём тип
+DELETE FROM gift_events WHERE event_type = 'sell_back';
+
+ALTER TYPE gift_event_type RENAME TO gift_event_type_old;
+CREATE TYPE gift_event_type AS ENUM (
+  'stake',
+  'return_from_game',
+  'deposit',
+  'withdraw_request',
+  'withdraw_complete',
+  'withdraw_fail',
+  'list',
+  'unlist',
+  'sale'
+);
+ALTER TABLE gift_events ALTER COLUMN event_type DROP DEFAULT;
+ALTER TABLE gift_events ALTER COLUMN event_type TYPE gift_event_type USING event_type::text::gift_event_type;
+ALTER TABLE gift_events ALTER COLUMN event_type SET DEFAULT 'stake';
+DROP TYPE gift_event_type_old;
@@ -0,0 +1,19 @@
+-- Migration: sell_back
+-- Created at: 2026-10-19 11:00:00
+-- Description: Add sell_back gift event type and gift_sell_back_quotes table for instant sell-back to the house
+
+ALTER TYPE gift_event_type ADD VALUE IF NOT EXISTS 'sell_back';
+
+CREATE TABLE gift_sell_back_quotes (
+  id                UUID           PRIMARY KEY DEFAULT gen_random_uuid(),
+  gift_id           UUID           NOT NULL REFERENCES gifts(id) ON DELETE CASCADE,
+  telegram_user_id  BIGINT         NOT NULL,
+  floor_price       NUMERIC(20, 2) NOT NULL,
+  amount            NUMERIC(20, 2) NOT NULL,
+  expires_at        TIMESTAMPTZ    NOT NULL,
+  executed_at       TIMESTAMPTZ,
+  created_at        TIMESTAMPTZ    NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX ix_gift_sell_back_quotes_gift_id
+  ON gift_sell_back_quotes(gift_id);
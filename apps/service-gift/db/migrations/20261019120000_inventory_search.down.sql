@@ -0,0 +1,9 @@
+-- Migration: inventory_search (DOWN)
+-- Created at: 2026-10-19 12:00:00
+-- Description: Rollback for inventory_search
+
+DROP INDEX IF EXISTS ix_gifts_slug_trgm;
+DROP INDEX IF EXISTS ix_gifts_title_trgm;
+DROP INDEX IF EXISTS ix_gifts_owner_status_created_at;
+
+DROP EXTENSION IF EXISTS pg_trgm;
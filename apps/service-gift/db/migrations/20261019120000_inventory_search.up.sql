@@ -0,0 +1,14 @@
+-- Migration: inventory_search
+-- Created at: 2026-10-19 12:00:00
+-- Description: Add indexes for inventory filtering, sorting and title/slug search
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX ix_gifts_owner_status_created_at
+  ON gifts (owner_telegram_id, status, created_at DESC);
+
+CREATE INDEX ix_gifts_title_trgm
+  ON gifts USING GIN (title gin_trgm_ops);
+
+CREATE INDEX ix_gifts_slug_trgm
+  ON gifts USING GIN (slug gin_trgm_ops);
@@ -0,0 +1,5 @@
+-- Migration: gift_related_duel (DOWN)
+-- Created at: 2026-10-19 13:00:00
+-- Description: Rollback for gift_related_duel
+
+ALTER TABLE gifts DROP COLUMN IF EXISTS related_duel_id;
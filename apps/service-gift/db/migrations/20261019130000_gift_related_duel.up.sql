@@ -0,0 +1,5 @@
+-- Migration: gift_related_duel
+-- Created at: 2026-10-19 13:00:00
+-- Description: Store the duel a gift is staked in to avoid cross-service lookups on reads
+
+ALTER TABLE gifts ADD COLUMN related_duel_id UUID NULL;
@@ -0,0 +1,12 @@
+-- Migration: processed_messages
+-- Created at: 2026-10-19 14:00:00
+-- Description: Ledger of consumed AMQP messages for idempotent event handlers
+
+CREATE TABLE processed_messages (
+    handler TEXT NOT NULL,
+    message_id TEXT NOT NULL,
+    processed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (handler, message_id)
+);
+
+CREATE INDEX ix_processed_messages_processed_at ON processed_messages (processed_at);
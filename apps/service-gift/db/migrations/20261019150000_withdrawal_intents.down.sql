@@ -0,0 +1,6 @@
+-- Migration: withdrawal_intents (DOWN)
+-- Created at: 2026-10-19 15:00:00
+-- Description: Rollback for withdrawal_intents
+
+DROP TABLE IF EXISTS withdrawal_intents;
+DROP TYPE IF EXISTS withdrawal_intent_status;
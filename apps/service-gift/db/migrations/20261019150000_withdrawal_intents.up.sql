@@ -0,0 +1,29 @@
+-- Migration: withdrawal_intents
+-- Created at: 2026-10-19 15:00:00
+-- Description: Track Stars withdrawal invoices so unpaid ones can expire and release held gifts
+
+CREATE TYPE withdrawal_intent_status AS ENUM (
+  'pending',
+  'completed',
+  'expired'
+);
+
+CREATE TABLE withdrawal_intents (
+  id                UUID                      PRIMARY KEY DEFAULT gen_random_uuid(),
+  telegram_user_id  BIGINT                    NOT NULL,
+  gift_ids          UUID[]                    NOT NULL,
+  stars_amount      BIGINT                    NOT NULL,
+  invoice_url       TEXT                      NOT NULL,
+  status            withdrawal_intent_status  NOT NULL DEFAULT 'pending',
+  expires_at        TIMESTAMPTZ               NOT NULL,
+  completed_at      TIMESTAMPTZ,
+  created_at        TIMESTAMPTZ               NOT NULL DEFAULT NOW(),
+  updated_at        TIMESTAMPTZ               NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX ix_withdrawal_intents_gift_ids
+  ON withdrawal_intents USING GIN (gift_ids);
+
+CREATE INDEX ix_withdrawal_intents_pending_expires_at
+  ON withdrawal_intents(expires_at)
+  WHERE status = 'pending';
@@ -0,0 +1,9 @@
+-- Migration: withdrawals (DOWN)
+-- Created at: 2026-10-19 16:00:00
+-- Description: Rollback for withdrawals
+
+DROP TABLE IF EXISTS withdrawal_gifts;
+DROP TABLE IF EXISTS withdrawals;
+DROP TYPE IF EXISTS withdrawal_gift_status;
+DROP TYPE IF EXISTS withdrawal_commission_currency;
+DROP TYPE IF EXISTS withdrawal_status;
@@ -0,0 +1,59 @@
+-- Migration: withdrawals
+-- Created at: 2026-10-19 16:00:00
+-- Description: Track gift withdrawal requests as an aggregate with a status lifecycle and per-gift progress
+
+CREATE TYPE withdrawal_status AS ENUM (
+  'requested',
+  'awaiting_payment',
+  'sent_to_bot',
+  'completed',
+  'failed',
+  'refunded'
+);
+
+CREATE TYPE withdrawal_commission_currency AS ENUM (
+  'ton',
+  'stars'
+);
+
+CREATE TYPE withdrawal_gift_status AS ENUM (
+  'pending',
+  'withdrawn',
+  'failed',
+  'refunded'
+);
+
+CREATE TABLE withdrawals (
+  id                   UUID                            PRIMARY KEY DEFAULT gen_random_uuid(),
+  telegram_user_id     BIGINT                          NOT NULL,
+  commission_currency  withdrawal_commission_currency  NOT NULL,
+  commission_amount    NUMERIC(20, 9)                  NOT NULL,
+  status               withdrawal_status               NOT NULL DEFAULT 'requested',
+  failure_reason       TEXT,
+  intent_id            UUID                            REFERENCES withdrawal_intents(id),
+  completed_at         TIMESTAMPTZ,
+  failed_at            TIMESTAMPTZ,
+  created_at           TIMESTAMPTZ                     NOT NULL DEFAULT NOW(),
+  updated_at           TIMESTAMPTZ                     NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX ix_withdrawals_telegram_user_id_created_at
+  ON withdrawals(telegram_user_id, created_at DESC);
+
+CREATE UNIQUE INDEX ux_withdrawals_intent_id
+  ON withdrawals(intent_id)
+  WHERE intent_id IS NOT NULL;
+
+CREATE TABLE withdrawal_gifts (
+  withdrawal_id      UUID                    NOT NULL REFERENCES withdrawals(id) ON DELETE CASCADE,
+  gift_id            UUID                    NOT NULL REFERENCES gifts(id) ON DELETE CASCADE,
+  commission_amount  NUMERIC(20, 9)          NOT NULL,
+  status             withdrawal_gift_status  NOT NULL DEFAULT 'pending',
+  failure_reason     TEXT,
+  updated_at         TIMESTAMPTZ             NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (withdrawal_id, gift_id)
+);
+
+CREATE INDEX ix_withdrawal_gifts_pending_gift_id
+  ON withdrawal_gifts(gift_id)
+  WHERE status = 'pending';
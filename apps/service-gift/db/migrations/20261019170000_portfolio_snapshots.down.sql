@@ -0,0 +1,6 @@
+-- Migration: portfolio_snapshots (DOWN)
+-- Created at: 2026-10-19 17:00:00
+-- Description: Rollback for portfolio_snapshots
+
+DROP INDEX IF EXISTS ix_gift_events_telegram_user_id_event_type;
+DROP TABLE IF EXISTS portfolio_snapshots;
@@ -0,0 +1,40 @@
+-- Migration: portfolio_snapshots
+-- Created at: 2026-10-19 17:00:00
+-- Description: Daily portfolio value snapshots and deposit events backfill
+
+CREATE TABLE portfolio_snapshots (
+  telegram_user_id  BIGINT         NOT NULL,
+  snapshot_date     DATE           NOT NULL,
+  owned_value       NUMERIC(20, 2) NOT NULL DEFAULT 0,
+  in_game_value     NUMERIC(20, 2) NOT NULL DEFAULT 0,
+  owned_count       INT            NOT NULL DEFAULT 0,
+  in_game_count     INT            NOT NULL DEFAULT 0,
+  created_at        TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+  updated_at        TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (telegram_user_id, snapshot_date)
+);
+
+CREATE INDEX ix_gift_events_telegram_user_id_event_type
+  ON gift_events(telegram_user_id, event_type);
+
+-- Депозиты раньше не попадали в gift_events: восстанавливаем их для уже
+-- существующих подарков. Депозитором считаем участника самого раннего события
+-- (или текущего владельца, если событий не было).
+INSERT INTO gift_events (gift_id, event_type, telegram_user_id, occurred_at)
+SELECT
+  g.id,
+  'deposit',
+  COALESCE(
+    (SELECT e.telegram_user_id
+       FROM gift_events e
+      WHERE e.gift_id = g.id AND e.telegram_user_id IS NOT NULL
+      ORDER BY e.occurred_at
+      LIMIT 1),
+    g.owner_telegram_id
+  ),
+  g.created_at
+FROM gifts g
+WHERE NOT EXISTS (
+  SELECT 1 FROM gift_events e
+   WHERE e.gift_id = g.id AND e.event_type = 'deposit'
+);
@@ -0,0 +1,7 @@
+-- Migration: custody_reconciliation (DOWN)
+-- Created at: 2026-10-19 18:00:00
+-- Description: Rollback for custody_reconciliation
+
+DROP TABLE IF EXISTS reconciliation_discrepancies;
+DROP TABLE IF EXISTS reconciliation_runs;
+DROP TYPE IF EXISTS reconciliation_discrepancy_kind;
@@ -0,0 +1,39 @@
+-- Migration: custody_reconciliation
+-- Created at: 2026-10-19 18:00:00
+-- Description: Store custody account reconciliation runs and the discrepancies they found
+
+CREATE TYPE reconciliation_discrepancy_kind AS ENUM (
+  -- подарок числится в gifts, но на custody-аккаунте его нет
+  'missing_in_custody',
+  -- подарок лежит на custody-аккаунте, но активной записи в gifts нет
+  'missing_in_ledger'
+);
+
+CREATE TABLE reconciliation_runs (
+  id                 UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+  custody_count      INT         NOT NULL,
+  ledger_count       INT         NOT NULL,
+  discrepancy_count  INT         NOT NULL,
+  started_at         TIMESTAMPTZ NOT NULL,
+  finished_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX ix_reconciliation_runs_started_at
+  ON reconciliation_runs(started_at DESC);
+
+CREATE TABLE reconciliation_discrepancies (
+  id                UUID                            PRIMARY KEY DEFAULT gen_random_uuid(),
+  run_id            UUID                            NOT NULL REFERENCES reconciliation_runs(id) ON DELETE CASCADE,
+  kind              reconciliation_discrepancy_kind NOT NULL,
+  telegram_gift_id  BIGINT                          NOT NULL,
+  collectible_id    INT                             NOT NULL,
+  title             TEXT                            NOT NULL,
+  slug              TEXT                            NOT NULL,
+  -- заполнены только для missing_in_custody
+  gift_id           UUID                            NULL REFERENCES gifts(id) ON DELETE SET NULL,
+  gift_status       gift_status                     NULL,
+  created_at        TIMESTAMPTZ                     NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX ix_reconciliation_discrepancies_run_id
+  ON reconciliation_discrepancies(run_id);
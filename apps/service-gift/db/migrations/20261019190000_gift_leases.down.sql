@@ -0,0 +1,10 @@
+-- Migration: gift_leases (DOWN)
+-- Created at: 2026-10-19 19:00:00
+-- Description: Rollback for gift_leases
+
+DROP INDEX IF EXISTS ix_gifts_lock_expires_at;
+ALTER TABLE gifts
+  DROP COLUMN IF EXISTS lock_expires_at,
+  DROP COLUMN IF EXISTS locked_by_id,
+  DROP COLUMN IF EXISTS locked_by_type;
+DROP TYPE IF EXISTS gift_lock_operation;
@@ -0,0 +1,22 @@
+-- Migration: gift_leases
+-- Created at: 2026-10-19 19:00:00
+-- Description: Lock staked gifts with an expiring lease until the staking operation confirms it
+
+CREATE TYPE gift_lock_operation AS ENUM (
+  'duel'
+);
+
+ALTER TABLE gifts
+  ADD COLUMN locked_by_type  gift_lock_operation NULL,
+  ADD COLUMN locked_by_id    UUID                NULL,
+  -- NULL при заполненном locked_by означает подтверждённую (постоянную) блокировку
+  ADD COLUMN lock_expires_at TIMESTAMPTZ         NULL;
+
+CREATE INDEX ix_gifts_lock_expires_at
+  ON gifts(lock_expires_at)
+  WHERE lock_expires_at IS NOT NULL;
+
+-- Подарки, уже стоящие в дуэлях, считаем подтверждёнными ставками
+UPDATE gifts
+SET locked_by_type = 'duel', locked_by_id = related_duel_id
+WHERE status = 'in_game' AND related_duel_id IS NOT NULL;
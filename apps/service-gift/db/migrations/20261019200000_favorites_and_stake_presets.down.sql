@@ -0,0 +1,7 @@
+-- Migration: favorites_and_stake_presets (DOWN)
+-- Created at: 2026-10-19 20:00:00
+-- Description: Rollback for favorites_and_stake_presets
+
+DROP TABLE IF EXISTS stake_preset_gifts;
+DROP TABLE IF EXISTS stake_presets;
+DROP TABLE IF EXISTS gift_favorites;
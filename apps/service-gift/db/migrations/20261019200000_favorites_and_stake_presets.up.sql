@@ -0,0 +1,29 @@
+-- Migration: favorites_and_stake_presets
+-- Created at: 2026-10-19 20:00:00
+-- Description: Per-user favorite gifts and named stake presets
+
+CREATE TABLE gift_favorites (
+  telegram_user_id  BIGINT      NOT NULL,
+  gift_id           UUID        NOT NULL REFERENCES gifts(id) ON DELETE CASCADE,
+  created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (telegram_user_id, gift_id)
+);
+
+CREATE TABLE stake_presets (
+  id                UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+  telegram_user_id  BIGINT      NOT NULL,
+  name              TEXT        NOT NULL,
+  created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX ux_stake_presets_telegram_user_id_name
+  ON stake_presets(telegram_user_id, name);
+
+CREATE TABLE stake_preset_gifts (
+  preset_id  UUID NOT NULL REFERENCES stake_presets(id) ON DELETE CASCADE,
+  gift_id    UUID NOT NULL REFERENCES gifts(id) ON DELETE CASCADE,
+  -- порядок подарков внутри пресета
+  position   INT  NOT NULL,
+  PRIMARY KEY (preset_id, gift_id)
+);
@@ -0,0 +1,38 @@
+-- Migration: withdrawal_paid_after_expiry (DOWN)
+-- Created at: 2026-10-19 21:00:00
+-- Description: Rollback for withdrawal_paid_after_expiry
+
+-- Postgres не умеет удалять значения из enum, поэтому пересоздаём типы
+UPDATE withdrawal_intents SET status = 'expired' WHERE status = 'paid_after_expiry';
+UPDATE withdrawals SET status = 'failed' WHERE status = 'refund_required';
+
+DROP INDEX IF EXISTS ix_withdrawal_intents_pending_expires_at;
+
+ALTER TYPE withdrawal_intent_status RENAME TO withdrawal_intent_status_old;
+CREATE TYPE withdrawal_intent_status AS ENUM (
+  'pending',
+  'completed',
+  'expired'
+);
+ALTER TABLE withdrawal_intents ALTER COLUMN status DROP DEFAULT;
+ALTER TABLE withdrawal_intents ALTER COLUMN status TYPE withdrawal_intent_status USING status::text::withdrawal_intent_status;
+ALTER TABLE withdrawal_intents ALTER COLUMN status SET DEFAULT 'pending';
+DROP TYPE withdrawal_intent_status_old;
+
+CREATE INDEX ix_withdrawal_intents_pending_expires_at
+  ON withdrawal_intents(expires_at)
+  WHERE status = 'pending';
+
+ALTER TYPE withdrawal_status RENAME TO withdrawal_status_old;
+CREATE TYPE withdrawal_status AS ENUM (
+  'requested',
+  'awaiting_payment',
+  'sent_to_bot',
+  'completed',
+  'failed',
+  'refunded'
+);
+ALTER TABLE withdrawals ALTER COLUMN status DROP DEFAULT;
+ALTER TABLE withdrawals ALTER COLUMN status TYPE withdrawal_status USING status::text::withdrawal_status;
+ALTER TABLE withdrawals ALTER COLUMN status SET DEFAULT 'requested';
+DROP TYPE withdrawal_status_old;
@@ -0,0 +1,6 @@
+-- Migration: withdrawal_paid_after_expiry
+-- Created at: 2026-10-19 21:00:00
+-- Description: Track Stars invoices paid after expiry whose gifts were no longer available and need a refund
+
+ALTER TYPE withdrawal_intent_status ADD VALUE IF NOT EXISTS 'paid_after_expiry';
+ALTER TYPE withdrawal_status ADD VALUE IF NOT EXISTS 'refund_required';
@@ -0,0 +1,5 @@
+-- Migration: failed_saga_compensations (DOWN)
+-- Created at: 2026-10-19 22:00:00
+-- Description: Rollback for failed_saga_compensations
+
+DROP TABLE IF EXISTS failed_saga_compensations;
@@ -0,0 +1,20 @@
+-- Migration: failed_saga_compensations
+-- Created at: 2026-10-19 22:00:00
+-- Description: Record saga compensations that failed so the balance can be fixed by replaying the payment call
+
+CREATE TABLE failed_saga_compensations (
+  id                UUID           PRIMARY KEY DEFAULT gen_random_uuid(),
+  saga              TEXT           NOT NULL,
+  step              TEXT           NOT NULL,
+  gift_id           UUID           NOT NULL,
+  telegram_user_id  BIGINT         NOT NULL,
+  amount            NUMERIC(20, 2) NOT NULL,
+  idempotency_key   TEXT           NOT NULL,
+  error             TEXT           NOT NULL,
+  resolved_at       TIMESTAMPTZ,
+  created_at        TIMESTAMPTZ    NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX ix_failed_saga_compensations_unresolved
+  ON failed_saga_compensations(created_at)
+  WHERE resolved_at IS NULL;
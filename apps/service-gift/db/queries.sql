@@ -21,33 +21,30 @@ SELECT COUNT(*)
 FROM gifts
 WHERE owner_telegram_id = $1;
 
--- name: GetUserActiveGifts :many
-SELECT *
-FROM gifts
-WHERE owner_telegram_id = $1
-  AND status IN ('owned', 'in_game')
-ORDER BY
-  updated_at DESC,
-  (status = 'owned') DESC,
-  price DESC
-LIMIT $2 OFFSET $3;
-
--- name: GetUserActiveGiftsCount :one
-SELECT COUNT(*)
-FROM gifts
-WHERE owner_telegram_id = $1
-  AND status IN ('owned', 'in_game');
-
 -- name: UpdateGiftStatus :one
 UPDATE gifts 
-SET status = $2, updated_at = NOW()
+SET status = $2,
+    related_duel_id = CASE WHEN $2 = 'in_game' THEN related_duel_id END,
+    locked_by_type = CASE WHEN $2 = 'in_game' THEN locked_by_type END,
+    locked_by_id = CASE WHEN $2 = 'in_game' THEN locked_by_id END,
+    lock_expires_at = CASE WHEN $2 = 'in_game' THEN lock_expires_at END,
+    updated_at = NOW()
 WHERE id = $1
 RETURNING *;
 
--- name: UpdateGiftOwner :one
-UPDATE gifts 
-SET owner_telegram_id = $2, updated_at = NOW()
-WHERE id = $1
+-- name: TransferGiftFromGame :one
+UPDATE gifts
+SET owner_telegram_id = sqlc.arg('owner_telegram_id'),
+    status = 'owned',
+    related_duel_id = NULL,
+    locked_by_type = NULL,
+    locked_by_id = NULL,
+    lock_expires_at = NULL,
+    updated_at = NOW()
+WHERE id = sqlc.arg('id')
+  AND status = 'in_game'
+  AND locked_by_type = 'duel'
+  AND locked_by_id = sqlc.arg('duel_id')
 RETURNING *;
 
 -- name: MarkGiftForWithdrawal :one
@@ -70,13 +67,23 @@ RETURNING *;
 
 -- name: StakeGiftForGame :one
 UPDATE gifts 
-SET status = 'in_game', updated_at = NOW()
-WHERE id = $1 AND status = 'owned'
+SET status = 'in_game',
+    related_duel_id = sqlc.narg('related_duel_id'),
+    locked_by_type = 'duel',
+    locked_by_id = sqlc.narg('related_duel_id'),
+    lock_expires_at = sqlc.arg('lock_expires_at'),
+    updated_at = NOW()
+WHERE id = sqlc.arg('id') AND status = 'owned'
 RETURNING *;
 
 -- name: ReturnGiftFromGame :one
 UPDATE gifts 
-SET status = 'owned', updated_at = NOW()
+SET status = 'owned',
+    related_duel_id = NULL,
+    locked_by_type = NULL,
+    locked_by_id = NULL,
+    lock_expires_at = NULL,
+    updated_at = NOW()
 WHERE id = $1 AND status = 'in_game'
 RETURNING *;
 
@@ -198,3 +205,559 @@ WHERE id = ANY($1::int[]);
 SELECT *
 FROM gift_symbols
 WHERE id = ANY($1::int[]);
+
+-- name: ListGift :one
+UPDATE gifts
+SET status = 'listed', updated_at = NOW()
+WHERE id = $1 AND status = 'owned'
+RETURNING *;
+
+-- name: UnlistGift :one
+UPDATE gifts
+SET status = 'owned', updated_at = NOW()
+WHERE id = $1 AND status = 'listed'
+RETURNING *;
+
+-- name: TransferListedGift :one
+UPDATE gifts
+SET owner_telegram_id = $2, status = 'owned', updated_at = NOW()
+WHERE id = $1 AND status = 'listed'
+RETURNING *;
+
+-- name: CreateListing :one
+INSERT INTO gift_listings (
+    gift_id,
+    seller_telegram_id,
+    price
+) VALUES (
+    $1, $2, $3
+)
+RETURNING *;
+
+-- name: GetListingByID :one
+SELECT *
+FROM gift_listings
+WHERE id = $1;
+
+-- name: GetListingByIDForUpdate :one
+SELECT *
+FROM gift_listings
+WHERE id = $1
+FOR UPDATE;
+
+-- name: CancelListing :one
+UPDATE gift_listings
+SET status = 'cancelled', updated_at = NOW()
+WHERE id = $1 AND status = 'active'
+RETURNING *;
+
+-- name: MarkListingSold :one
+UPDATE gift_listings
+SET status = 'sold',
+    buyer_telegram_id = $2,
+    fee = $3,
+    sold_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1 AND status = 'active'
+RETURNING *;
+
+-- name: GetActiveListings :many
+SELECT l.*
+FROM gift_listings l
+JOIN gifts g ON g.id = l.gift_id
+JOIN gift_collections c ON c.id = g.collection_id
+JOIN gift_models m ON m.id = g.model_id
+JOIN gift_backdrops b ON b.id = g.backdrop_id
+WHERE l.status = 'active'
+  AND (sqlc.narg('collection')::text IS NULL OR c.name = sqlc.narg('collection')::text)
+  AND (sqlc.narg('model')::text IS NULL OR m.name = sqlc.narg('model')::text)
+  AND (sqlc.narg('backdrop')::text IS NULL OR b.name = sqlc.narg('backdrop')::text)
+  AND (sqlc.narg('min_price')::numeric IS NULL OR l.price >= sqlc.narg('min_price')::numeric)
+  AND (sqlc.narg('max_price')::numeric IS NULL OR l.price <= sqlc.narg('max_price')::numeric)
+ORDER BY l.created_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: GetActiveListingsCount :one
+SELECT COUNT(*)
+FROM gift_listings l
+JOIN gifts g ON g.id = l.gift_id
+JOIN gift_collections c ON c.id = g.collection_id
+JOIN gift_models m ON m.id = g.model_id
+JOIN gift_backdrops b ON b.id = g.backdrop_id
+WHERE l.status = 'active'
+  AND (sqlc.narg('collection')::text IS NULL OR c.name = sqlc.narg('collection')::text)
+  AND (sqlc.narg('model')::text IS NULL OR m.name = sqlc.narg('model')::text)
+  AND (sqlc.narg('backdrop')::text IS NULL OR b.name = sqlc.narg('backdrop')::text)
+  AND (sqlc.narg('min_price')::numeric IS NULL OR l.price >= sqlc.narg('min_price')::numeric)
+  AND (sqlc.narg('max_price')::numeric IS NULL OR l.price <= sqlc.narg('max_price')::numeric);
+
+-- name: TransferOwnedGift :one
+UPDATE gifts
+SET owner_telegram_id = sqlc.arg('to_telegram_id'), updated_at = NOW()
+WHERE id = sqlc.arg('id')
+  AND owner_telegram_id = sqlc.arg('from_telegram_id')
+  AND status = 'owned'
+RETURNING *;
+
+-- name: CreateSellBackQuote :one
+INSERT INTO gift_sell_back_quotes (
+    gift_id,
+    telegram_user_id,
+    floor_price,
+    amount,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+RETURNING *;
+
+-- name: GetSellBackQuoteByIDForUpdate :one
+SELECT *
+FROM gift_sell_back_quotes
+WHERE id = $1
+FOR UPDATE;
+
+-- name: MarkSellBackQuoteExecuted :one
+UPDATE gift_sell_back_quotes
+SET executed_at = NOW()
+WHERE id = $1 AND executed_at IS NULL
+RETURNING *;
+
+-- name: GetGiftHistory :many
+SELECT * FROM gift_events
+WHERE gift_id = sqlc.arg('gift_id')
+  AND (cardinality(sqlc.arg('event_types')::text[]) = 0 OR event_type::text = ANY(sqlc.arg('event_types')::text[]))
+ORDER BY occurred_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: GetGiftHistoryCount :one
+SELECT COUNT(*) FROM gift_events
+WHERE gift_id = sqlc.arg('gift_id')
+  AND (cardinality(sqlc.arg('event_types')::text[]) = 0 OR event_type::text = ANY(sqlc.arg('event_types')::text[]));
+
+-- name: GiftHasUserEvent :one
+SELECT EXISTS (
+  SELECT 1 FROM gift_events
+  WHERE gift_id = sqlc.arg('gift_id') AND telegram_user_id = sqlc.arg('telegram_user_id')
+);
+
+-- name: GetUserGiftActivity :many
+SELECT * FROM gift_events
+WHERE telegram_user_id = sqlc.arg('telegram_user_id')
+  AND (cardinality(sqlc.arg('event_types')::text[]) = 0 OR event_type::text = ANY(sqlc.arg('event_types')::text[]))
+ORDER BY occurred_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: GetUserGiftActivityCount :one
+SELECT COUNT(*) FROM gift_events
+WHERE telegram_user_id = sqlc.arg('telegram_user_id')
+  AND (cardinality(sqlc.arg('event_types')::text[]) = 0 OR event_type::text = ANY(sqlc.arg('event_types')::text[]));
+
+-- name: SearchUserGifts :many
+SELECT g.*
+FROM gifts g
+JOIN gift_collections c ON c.id = g.collection_id
+JOIN gift_models m ON m.id = g.model_id
+JOIN gift_backdrops b ON b.id = g.backdrop_id
+JOIN gift_symbols s ON s.id = g.symbol_id
+WHERE g.owner_telegram_id = sqlc.arg('owner_telegram_id')
+  AND g.status::text = ANY(sqlc.arg('statuses')::text[])
+  AND (sqlc.narg('collection')::text IS NULL OR c.name = sqlc.narg('collection')::text)
+  AND (sqlc.narg('model')::text IS NULL OR m.name = sqlc.narg('model')::text)
+  AND (sqlc.narg('backdrop')::text IS NULL OR b.name = sqlc.narg('backdrop')::text)
+  AND (sqlc.narg('symbol')::text IS NULL OR s.name = sqlc.narg('symbol')::text)
+  AND (sqlc.narg('min_price')::numeric IS NULL OR g.price >= sqlc.narg('min_price')::numeric)
+  AND (sqlc.narg('max_price')::numeric IS NULL OR g.price <= sqlc.narg('max_price')::numeric)
+  AND (sqlc.narg('search')::text IS NULL OR g.title ILIKE sqlc.narg('search')::text OR g.slug ILIKE sqlc.narg('search')::text)
+ORDER BY
+  CASE WHEN sqlc.arg('sort_by')::text = 'price' AND sqlc.arg('sort_desc')::bool THEN g.price END DESC,
+  CASE WHEN sqlc.arg('sort_by')::text = 'price' AND NOT sqlc.arg('sort_desc')::bool THEN g.price END ASC,
+  CASE WHEN sqlc.arg('sort_by')::text = 'rarity' AND sqlc.arg('sort_desc')::bool THEN m.rarity_per_mille::bigint * b.rarity_per_mille * s.rarity_per_mille END ASC,
+  CASE WHEN sqlc.arg('sort_by')::text = 'rarity' AND NOT sqlc.arg('sort_desc')::bool THEN m.rarity_per_mille::bigint * b.rarity_per_mille * s.rarity_per_mille END DESC,
+  CASE WHEN sqlc.arg('sort_by')::text = 'deposit_date' AND sqlc.arg('sort_desc')::bool THEN g.created_at END DESC,
+  CASE WHEN sqlc.arg('sort_by')::text = 'deposit_date' AND NOT sqlc.arg('sort_desc')::bool THEN g.created_at END ASC,
+  g.updated_at DESC,
+  g.id
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: GetUserInventorySummary :many
+SELECT
+  c.name AS collection,
+  COUNT(*) AS gifts_count,
+  COALESCE(SUM(g.price), 0)::numeric AS total_value
+FROM gifts g
+JOIN gift_collections c ON c.id = g.collection_id
+JOIN gift_models m ON m.id = g.model_id
+JOIN gift_backdrops b ON b.id = g.backdrop_id
+JOIN gift_symbols s ON s.id = g.symbol_id
+WHERE g.owner_telegram_id = sqlc.arg('owner_telegram_id')
+  AND g.status::text = ANY(sqlc.arg('statuses')::text[])
+  AND (sqlc.narg('collection')::text IS NULL OR c.name = sqlc.narg('collection')::text)
+  AND (sqlc.narg('model')::text IS NULL OR m.name = sqlc.narg('model')::text)
+  AND (sqlc.narg('backdrop')::text IS NULL OR b.name = sqlc.narg('backdrop')::text)
+  AND (sqlc.narg('symbol')::text IS NULL OR s.name = sqlc.narg('symbol')::text)
+  AND (sqlc.narg('min_price')::numeric IS NULL OR g.price >= sqlc.narg('min_price')::numeric)
+  AND (sqlc.narg('max_price')::numeric IS NULL OR g.price <= sqlc.narg('max_price')::numeric)
+  AND (sqlc.narg('search')::text IS NULL OR g.title ILIKE sqlc.narg('search')::text OR g.slug ILIKE sqlc.narg('search')::text)
+GROUP BY c.name
+ORDER BY total_value DESC, c.name;
+
+-- name: CreateWithdrawalIntent :one
+INSERT INTO withdrawal_intents (
+    telegram_user_id,
+    gift_ids,
+    stars_amount,
+    invoice_url,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+RETURNING *;
+
+-- name: GetWithdrawalIntentByID :one
+SELECT * FROM withdrawal_intents
+WHERE id = $1;
+
+-- name: HasPendingWithdrawalIntentForGifts :one
+SELECT EXISTS (
+  SELECT 1 FROM withdrawal_intents
+  WHERE status = 'pending'
+    AND gift_ids && sqlc.arg('gift_ids')::uuid[]
+);
+
+-- name: GetLatestWithdrawalIntentByGiftsForUpdate :one
+SELECT * FROM withdrawal_intents
+WHERE telegram_user_id = sqlc.arg('telegram_user_id')
+  AND gift_ids @> sqlc.arg('gift_ids')::uuid[]
+ORDER BY created_at DESC
+LIMIT 1
+FOR UPDATE;
+
+-- name: GetExpiredWithdrawalIntentsForUpdate :many
+SELECT * FROM withdrawal_intents
+WHERE status = 'pending'
+  AND expires_at <= sqlc.arg('now')
+ORDER BY expires_at
+LIMIT sqlc.arg('limit')
+FOR UPDATE SKIP LOCKED;
+
+-- name: MarkWithdrawalIntentCompleted :one
+UPDATE withdrawal_intents
+SET status = 'completed', completed_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND status IN ('pending', 'expired')
+RETURNING *;
+
+-- name: MarkWithdrawalIntentExpired :one
+UPDATE withdrawal_intents
+SET status = 'expired', updated_at = NOW()
+WHERE id = $1 AND status = 'pending'
+RETURNING *;
+
+-- name: MarkWithdrawalIntentPaidAfterExpiry :one
+UPDATE withdrawal_intents
+SET status = 'paid_after_expiry', updated_at = NOW()
+WHERE id = $1 AND status = 'expired'
+RETURNING *;
+
+-- name: CreateWithdrawal :one
+INSERT INTO withdrawals (
+    telegram_user_id,
+    commission_currency,
+    commission_amount,
+    status,
+    intent_id
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+RETURNING *;
+
+-- name: CreateWithdrawalGift :one
+INSERT INTO withdrawal_gifts (
+    withdrawal_id,
+    gift_id,
+    commission_amount
+) VALUES (
+    $1, $2, $3
+)
+RETURNING *;
+
+-- name: GetWithdrawalByID :one
+SELECT * FROM withdrawals
+WHERE id = $1;
+
+-- name: GetWithdrawalByIntentIDForUpdate :one
+SELECT * FROM withdrawals
+WHERE intent_id = $1
+FOR UPDATE;
+
+-- name: GetActiveWithdrawalByGiftForUpdate :one
+SELECT w.* FROM withdrawals w
+JOIN withdrawal_gifts wg ON wg.withdrawal_id = w.id
+WHERE wg.gift_id = $1 AND wg.status = 'pending'
+ORDER BY w.created_at DESC
+LIMIT 1
+FOR UPDATE OF w;
+
+-- name: GetWithdrawalGiftsByWithdrawalIDs :many
+SELECT * FROM withdrawal_gifts
+WHERE withdrawal_id = ANY(sqlc.arg('withdrawal_ids')::uuid[]);
+
+-- name: GetUserWithdrawals :many
+SELECT * FROM withdrawals
+WHERE telegram_user_id = sqlc.arg('telegram_user_id')
+  AND (cardinality(sqlc.arg('statuses')::text[]) = 0 OR status::text = ANY(sqlc.arg('statuses')::text[]))
+ORDER BY created_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: GetUserWithdrawalsCount :one
+SELECT COUNT(*) FROM withdrawals
+WHERE telegram_user_id = sqlc.arg('telegram_user_id')
+  AND (cardinality(sqlc.arg('statuses')::text[]) = 0 OR status::text = ANY(sqlc.arg('statuses')::text[]));
+
+-- name: UpdateWithdrawalStatus :one
+UPDATE withdrawals
+SET status = sqlc.arg('status'),
+    failure_reason = sqlc.narg('failure_reason'),
+    completed_at = CASE WHEN sqlc.arg('status') = 'completed' THEN COALESCE(completed_at, NOW()) END,
+    failed_at = CASE WHEN sqlc.arg('status') IN ('failed', 'refunded') THEN COALESCE(failed_at, NOW()) END,
+    updated_at = NOW()
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: UpdateWithdrawalGiftStatus :one
+UPDATE withdrawal_gifts
+SET status = sqlc.arg('status'),
+    failure_reason = sqlc.narg('failure_reason'),
+    updated_at = NOW()
+WHERE withdrawal_id = sqlc.arg('withdrawal_id') AND gift_id = sqlc.arg('gift_id')
+RETURNING *;
+
+-- name: GetCatalogCollections :many
+SELECT c.id, c.name, c.short_name, f.floor_price
+FROM gift_collections c
+LEFT JOIN (
+  SELECT g.collection_id, MIN(l.price)::numeric AS floor_price
+  FROM gift_listings l
+  JOIN gifts g ON g.id = l.gift_id
+  WHERE l.status = 'active'
+  GROUP BY g.collection_id
+) f ON f.collection_id = c.id
+ORDER BY c.name;
+
+-- name: GetCatalogModels :many
+SELECT m.id, m.collection_id, m.name, m.short_name, m.rarity_per_mille,
+       c.name AS collection_name, f.floor_price
+FROM gift_models m
+JOIN gift_collections c ON c.id = m.collection_id
+LEFT JOIN (
+  SELECT g.model_id, MIN(l.price)::numeric AS floor_price
+  FROM gift_listings l
+  JOIN gifts g ON g.id = l.gift_id
+  WHERE l.status = 'active'
+  GROUP BY g.model_id
+) f ON f.model_id = m.id
+WHERE sqlc.narg('collection')::text IS NULL OR c.name = sqlc.narg('collection')::text
+ORDER BY c.name, m.rarity_per_mille, m.name;
+
+-- name: GetCatalogBackdrops :many
+SELECT b.id, b.name, b.short_name, b.rarity_per_mille,
+       b.center_color, b.edge_color, b.pattern_color, b.text_color, f.floor_price
+FROM gift_backdrops b
+LEFT JOIN (
+  SELECT g.backdrop_id, MIN(l.price)::numeric AS floor_price
+  FROM gift_listings l
+  JOIN gifts g ON g.id = l.gift_id
+  WHERE l.status = 'active'
+  GROUP BY g.backdrop_id
+) f ON f.backdrop_id = b.id
+ORDER BY b.rarity_per_mille, b.name;
+
+-- name: GetCatalogSymbols :many
+SELECT s.id, s.name, s.short_name, s.rarity_per_mille, f.floor_price
+FROM gift_symbols s
+LEFT JOIN (
+  SELECT g.symbol_id, MIN(l.price)::numeric AS floor_price
+  FROM gift_listings l
+  JOIN gifts g ON g.id = l.gift_id
+  WHERE l.status = 'active'
+  GROUP BY g.symbol_id
+) f ON f.symbol_id = s.id
+ORDER BY s.rarity_per_mille, s.name;
+
+-- name: GetUserPortfolioValuation :one
+SELECT
+  COALESCE(SUM(price) FILTER (WHERE status IN ('owned', 'listed', 'withdraw_pending')), 0)::numeric AS owned_value,
+  COALESCE(SUM(price) FILTER (WHERE status = 'in_game'), 0)::numeric AS in_game_value,
+  COUNT(*) FILTER (WHERE status IN ('owned', 'listed', 'withdraw_pending')) AS owned_count,
+  COUNT(*) FILTER (WHERE status = 'in_game') AS in_game_count
+FROM gifts
+WHERE owner_telegram_id = $1;
+
+-- name: GetUserPortfolioPerformance :one
+SELECT
+  COALESCE(SUM(g.price) FILTER (WHERE e.event_type = 'deposit'), 0)::numeric AS deposited_value,
+  COALESCE(SUM(g.price) FILTER (WHERE e.event_type = 'withdraw_complete'), 0)::numeric AS withdrawn_value,
+  COALESCE(SUM(g.price) FILTER (WHERE e.event_type = 'return_from_game'), 0)::numeric AS duel_won_value,
+  COALESCE(SUM(g.price) FILTER (
+    WHERE e.event_type = 'stake' AND EXISTS (
+      SELECT 1 FROM gift_events r
+      WHERE r.gift_id = e.gift_id
+        AND r.related_game_id = e.related_game_id
+        AND r.event_type = 'return_from_game'
+        AND r.telegram_user_id <> e.telegram_user_id
+    )
+  ), 0)::numeric AS duel_lost_value
+FROM gift_events e
+JOIN gifts g ON g.id = e.gift_id
+WHERE e.telegram_user_id = sqlc.arg('telegram_user_id')::bigint;
+
+-- name: UpsertPortfolioSnapshots :execrows
+INSERT INTO portfolio_snapshots (
+  telegram_user_id, snapshot_date, owned_value, in_game_value, owned_count, in_game_count
+)
+SELECT
+  owner_telegram_id,
+  sqlc.arg('snapshot_date')::date,
+  COALESCE(SUM(price) FILTER (WHERE status IN ('owned', 'listed', 'withdraw_pending')), 0),
+  COALESCE(SUM(price) FILTER (WHERE status = 'in_game'), 0),
+  COUNT(*) FILTER (WHERE status IN ('owned', 'listed', 'withdraw_pending')),
+  COUNT(*) FILTER (WHERE status = 'in_game')
+FROM gifts
+GROUP BY owner_telegram_id
+ON CONFLICT (telegram_user_id, snapshot_date) DO UPDATE SET
+  owned_value   = EXCLUDED.owned_value,
+  in_game_value = EXCLUDED.in_game_value,
+  owned_count   = EXCLUDED.owned_count,
+  in_game_count = EXCLUDED.in_game_count,
+  updated_at    = NOW();
+
+-- name: GetUserPortfolioSnapshots :many
+SELECT telegram_user_id, snapshot_date, owned_value, in_game_value, owned_count, in_game_count, created_at, updated_at
+FROM portfolio_snapshots
+WHERE telegram_user_id = sqlc.arg('telegram_user_id')
+  AND snapshot_date >= sqlc.arg('from_date')::date
+  AND snapshot_date <= sqlc.arg('to_date')::date
+ORDER BY snapshot_date;
+
+-- name: GetReconcilableGifts :many
+SELECT id, telegram_gift_id, collectible_id, status, title, slug
+FROM gifts
+WHERE status <> 'withdrawn';
+
+-- name: CreateReconciliationRun :one
+INSERT INTO reconciliation_runs (custody_count, ledger_count, discrepancy_count, started_at)
+VALUES ($1, $2, $3, $4)
+RETURNING id, custody_count, ledger_count, discrepancy_count, started_at, finished_at;
+
+-- name: CreateReconciliationDiscrepancy :exec
+INSERT INTO reconciliation_discrepancies (
+  run_id, kind, telegram_gift_id, collectible_id, title, slug, gift_id, gift_status
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- name: GetReconciliationRunByID :one
+SELECT id, custody_count, ledger_count, discrepancy_count, started_at, finished_at
+FROM reconciliation_runs
+WHERE id = $1;
+
+-- name: GetLatestReconciliationRun :one
+SELECT id, custody_count, ledger_count, discrepancy_count, started_at, finished_at
+FROM reconciliation_runs
+ORDER BY started_at DESC
+LIMIT 1;
+
+-- name: GetReconciliationDiscrepanciesByRunID :many
+SELECT id, run_id, kind, telegram_gift_id, collectible_id, title, slug, gift_id, gift_status, created_at
+FROM reconciliation_discrepancies
+WHERE run_id = $1
+ORDER BY kind, telegram_gift_id;
+
+-- name: ConfirmGiftLeases :many
+UPDATE gifts
+SET lock_expires_at = NULL, updated_at = NOW()
+WHERE id = ANY(sqlc.arg('gift_ids')::uuid[])
+  AND status = 'in_game'
+  AND locked_by_type = sqlc.arg('locked_by_type')
+  AND locked_by_id = sqlc.arg('locked_by_id')
+RETURNING id;
+
+-- name: GetExpiredGiftLeases :many
+SELECT *
+FROM gifts
+WHERE status = 'in_game' AND lock_expires_at <= sqlc.arg('now')
+ORDER BY lock_expires_at
+LIMIT sqlc.arg('limit');
+
+-- name: ReleaseGiftLeases :many
+UPDATE gifts
+SET status = 'owned',
+    related_duel_id = NULL,
+    locked_by_type = NULL,
+    locked_by_id = NULL,
+    lock_expires_at = NULL,
+    updated_at = NOW()
+WHERE id = ANY(sqlc.arg('gift_ids')::uuid[])
+  AND status = 'in_game'
+  AND lock_expires_at <= sqlc.arg('now')
+RETURNING *;
+
+-- name: AddGiftFavorite :exec
+INSERT INTO gift_favorites (telegram_user_id, gift_id)
+VALUES ($1, $2)
+ON CONFLICT (telegram_user_id, gift_id) DO NOTHING;
+
+-- name: RemoveGiftFavorite :execrows
+DELETE FROM gift_favorites
+WHERE telegram_user_id = $1 AND gift_id = $2;
+
+-- name: GetUserFavoriteGiftIDs :many
+SELECT f.gift_id
+FROM gift_favorites f
+JOIN gifts g ON g.id = f.gift_id
+WHERE f.telegram_user_id = $1
+  AND g.owner_telegram_id = f.telegram_user_id
+  AND g.status <> 'withdrawn'
+ORDER BY f.created_at DESC;
+
+-- name: UpsertStakePreset :one
+INSERT INTO stake_presets (telegram_user_id, name)
+VALUES ($1, $2)
+ON CONFLICT (telegram_user_id, name) DO UPDATE SET updated_at = NOW()
+RETURNING id, telegram_user_id, name, created_at, updated_at;
+
+-- name: DeleteStakePresetGifts :exec
+DELETE FROM stake_preset_gifts
+WHERE preset_id = $1;
+
+-- name: CreateStakePresetGift :exec
+INSERT INTO stake_preset_gifts (preset_id, gift_id, position)
+VALUES ($1, $2, $3);
+
+-- name: DeleteStakePreset :execrows
+DELETE FROM stake_presets
+WHERE id = $1 AND telegram_user_id = $2;
+
+-- name: GetUserStakePresets :many
+SELECT id, telegram_user_id, name, created_at, updated_at
+FROM stake_presets
+WHERE telegram_user_id = $1
+ORDER BY name;
+
+-- name: GetStakePresetGiftsByPresetIDs :many
+SELECT preset_id, gift_id, position
+FROM stake_preset_gifts
+WHERE preset_id = ANY($1::uuid[])
+ORDER BY preset_id, position;
+
+-- name: CreateFailedSagaCompensation :exec
+INSERT INTO failed_saga_compensations (
+    saga,
+    step,
+    gift_id,
+    telegram_user_id,
+    amount,
+    idempotency_key,
+    error
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+);
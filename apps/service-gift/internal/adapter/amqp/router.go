@@ -5,6 +5,10 @@ import (
 
 	"github.com/ThreeDotsLabs/watermill/message"
 	"github.com/ThreeDotsLabs/watermill/message/router/middleware"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/config"
+	giftevents "github.com/peterparker2005/giftduels/packages/events/gift"
+	"github.com/peterparker2005/giftduels/packages/inbox"
 	"github.com/peterparker2005/giftduels/packages/logger-go"
 )
 
@@ -15,10 +19,12 @@ const (
 	defaultMultiplier      = 2
 )
 
-// ProvideRouter configures retry + poison.
+// ProvideRouter configures retry + poison + inbox deduplication.
 func ProvideRouter(
 	log *logger.Logger,
 	pub message.Publisher,
+	pool *pgxpool.Pool,
+	cfg *config.Config,
 ) (*message.Router, error) {
 	r, err := message.NewRouter(message.RouterConfig{}, logger.NewWatermill(log))
 	if err != nil {
@@ -31,8 +37,8 @@ func ProvideRouter(
 		InitialInterval: defaultInitialInterval,
 		Multiplier:      defaultMultiplier,
 	}
-	// TODO: add poison key to config?
-	poison, err := middleware.PoisonQueue(pub, "gift.events.poison")
+	giftCfg := giftevents.Config(cfg.ServiceName.String())
+	poison, err := middleware.PoisonQueue(pub, giftCfg.PoisonTopic())
 	if err != nil {
 		return nil, err
 	}
@@ -43,6 +49,8 @@ func ProvideRouter(
 		middleware.Recoverer,
 		retry.Middleware,
 		poison,
+		// innermost: every retry attempt runs in its own inbox transaction
+		inbox.New(pool, log).Middleware,
 	)
 
 	return r, nil
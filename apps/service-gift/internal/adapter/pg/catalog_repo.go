@@ -0,0 +1,142 @@
+package pg
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/adapter/pg/sqlc"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/catalog"
+	"github.com/peterparker2005/giftduels/packages/inbox"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+	"github.com/peterparker2005/giftduels/packages/tonamount-go"
+)
+
+type CatalogRepository struct {
+	q      *sqlc.Queries
+	logger *logger.Logger
+}
+
+func NewCatalogRepo(pool *pgxpool.Pool, logger *logger.Logger) catalog.Repository {
+	return &CatalogRepository{q: sqlc.New(inbox.NewDB(pool)), logger: logger}
+}
+
+func (r *CatalogRepository) ListCollections(ctx context.Context) ([]*catalog.CollectionEntry, error) {
+	rows, err := r.q.GetCatalogCollections(ctx)
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+
+	out := make([]*catalog.CollectionEntry, len(rows))
+	for i, row := range rows {
+		floor, fErr := floorPriceToDomain(row.FloorPrice)
+		if fErr != nil {
+			return nil, fErr
+		}
+		out[i] = &catalog.CollectionEntry{
+			Collection: *CollectionToDomain(sqlc.GiftCollection{
+				ID:        row.ID,
+				Name:      row.Name,
+				ShortName: row.ShortName,
+			}),
+			FloorPrice: floor,
+		}
+	}
+	return out, nil
+}
+
+func (r *CatalogRepository) ListModels(
+	ctx context.Context,
+	collection *string,
+) ([]*catalog.ModelEntry, error) {
+	rows, err := r.q.GetCatalogModels(ctx, stringPtrToPgText(collection))
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+
+	out := make([]*catalog.ModelEntry, len(rows))
+	for i, row := range rows {
+		floor, fErr := floorPriceToDomain(row.FloorPrice)
+		if fErr != nil {
+			return nil, fErr
+		}
+		out[i] = &catalog.ModelEntry{
+			Model: *ModelToDomain(sqlc.GiftModel{
+				ID:             row.ID,
+				CollectionID:   row.CollectionID,
+				Name:           row.Name,
+				ShortName:      row.ShortName,
+				RarityPerMille: row.RarityPerMille,
+			}),
+			CollectionName: row.CollectionName,
+			FloorPrice:     floor,
+		}
+	}
+	return out, nil
+}
+
+func (r *CatalogRepository) ListBackdrops(ctx context.Context) ([]*catalog.BackdropEntry, error) {
+	rows, err := r.q.GetCatalogBackdrops(ctx)
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+
+	out := make([]*catalog.BackdropEntry, len(rows))
+	for i, row := range rows {
+		floor, fErr := floorPriceToDomain(row.FloorPrice)
+		if fErr != nil {
+			return nil, fErr
+		}
+		out[i] = &catalog.BackdropEntry{
+			Backdrop: *BackdropToDomain(sqlc.GiftBackdrop{
+				ID:             row.ID,
+				Name:           row.Name,
+				ShortName:      row.ShortName,
+				RarityPerMille: row.RarityPerMille,
+				CenterColor:    row.CenterColor,
+				EdgeColor:      row.EdgeColor,
+				PatternColor:   row.PatternColor,
+				TextColor:      row.TextColor,
+			}),
+			FloorPrice: floor,
+		}
+	}
+	return out, nil
+}
+
+func (r *CatalogRepository) ListSymbols(ctx context.Context) ([]*catalog.SymbolEntry, error) {
+	rows, err := r.q.GetCatalogSymbols(ctx)
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+
+	out := make([]*catalog.SymbolEntry, len(rows))
+	for i, row := range rows {
+		floor, fErr := floorPriceToDomain(row.FloorPrice)
+		if fErr != nil {
+			return nil, fErr
+		}
+		out[i] = &catalog.SymbolEntry{
+			Symbol: *SymbolToDomain(sqlc.GiftSymbol{
+				ID:             row.ID,
+				Name:           row.Name,
+				ShortName:      row.ShortName,
+				RarityPerMille: row.RarityPerMille,
+			}),
+			FloorPrice: floor,
+		}
+	}
+	return out, nil
+}
+
+// floorPriceToDomain converts optional floor price; NULL means nothing is listed.
+func floorPriceToDomain(n pgtype.Numeric) (*tonamount.TonAmount, error) {
+	if !n.Valid {
+		return nil, nil //nolint:nilnil // no active listings
+	}
+	price, err := fromPgNumeric(n)
+	if err != nil {
+		return nil, err
+	}
+	return tonamount.NewTonAmountFromString(price)
+}
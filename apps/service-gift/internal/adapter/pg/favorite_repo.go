@@ -0,0 +1,168 @@
+package pg
+
+import (
+	"context"
+
+	"github.com/ccoveille/go-safecast"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/adapter/pg/sqlc"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/favorite"
+	"github.com/peterparker2005/giftduels/packages/inbox"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+)
+
+type FavoriteRepository struct {
+	q      *sqlc.Queries
+	logger *logger.Logger
+}
+
+func NewFavoriteRepo(pool *pgxpool.Pool, logger *logger.Logger) favorite.Repository {
+	return &FavoriteRepository{q: sqlc.New(inbox.NewDB(pool)), logger: logger}
+}
+
+func (r *FavoriteRepository) WithTx(tx pgx.Tx) favorite.Repository {
+	return &FavoriteRepository{q: r.q.WithTx(tx), logger: r.logger}
+}
+
+func (r *FavoriteRepository) AddFavorite(
+	ctx context.Context,
+	telegramUserID int64,
+	giftID string,
+) error {
+	id, err := pgUUID(giftID)
+	if err != nil {
+		return err
+	}
+	err = r.q.AddGiftFavorite(ctx, sqlc.AddGiftFavoriteParams{
+		TelegramUserID: telegramUserID,
+		GiftID:         id,
+	})
+	return MapPGError(err)
+}
+
+func (r *FavoriteRepository) RemoveFavorite(
+	ctx context.Context,
+	telegramUserID int64,
+	giftID string,
+) (bool, error) {
+	id, err := pgUUID(giftID)
+	if err != nil {
+		return false, err
+	}
+	removed, err := r.q.RemoveGiftFavorite(ctx, sqlc.RemoveGiftFavoriteParams{
+		TelegramUserID: telegramUserID,
+		GiftID:         id,
+	})
+	if err != nil {
+		return false, MapPGError(err)
+	}
+	return removed > 0, nil
+}
+
+func (r *FavoriteRepository) GetFavoriteGiftIDs(
+	ctx context.Context,
+	telegramUserID int64,
+) ([]string, error) {
+	ids, err := r.q.GetUserFavoriteGiftIDs(ctx, telegramUserID)
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	out := make([]string, len(ids))
+	for i, id := range ids {
+		out[i] = pgUUIDToString(id)
+	}
+	return out, nil
+}
+
+func (r *FavoriteRepository) SavePreset(ctx context.Context, preset *favorite.Preset) error {
+	dbPreset, err := r.q.UpsertStakePreset(ctx, sqlc.UpsertStakePresetParams{
+		TelegramUserID: preset.TelegramUserID,
+		Name:           preset.Name,
+	})
+	if err != nil {
+		return MapPGError(err)
+	}
+
+	if err = r.q.DeleteStakePresetGifts(ctx, dbPreset.ID); err != nil {
+		return MapPGError(err)
+	}
+	for i, giftID := range preset.GiftIDs {
+		id, idErr := pgUUID(giftID)
+		if idErr != nil {
+			return idErr
+		}
+		position, castErr := safecast.ToInt32(i)
+		if castErr != nil {
+			return castErr
+		}
+		err = r.q.CreateStakePresetGift(ctx, sqlc.CreateStakePresetGiftParams{
+			PresetID: dbPreset.ID,
+			GiftID:   id,
+			Position: position,
+		})
+		if err != nil {
+			return MapPGError(err)
+		}
+	}
+
+	preset.ID = pgUUIDToString(dbPreset.ID)
+	preset.CreatedAt = dbPreset.CreatedAt.Time
+	preset.UpdatedAt = dbPreset.UpdatedAt.Time
+	return nil
+}
+
+func (r *FavoriteRepository) DeletePreset(
+	ctx context.Context,
+	telegramUserID int64,
+	presetID string,
+) error {
+	id, err := pgUUID(presetID)
+	if err != nil {
+		return favorite.ErrPresetNotFound
+	}
+	deleted, err := r.q.DeleteStakePreset(ctx, sqlc.DeleteStakePresetParams{
+		ID:             id,
+		TelegramUserID: telegramUserID,
+	})
+	if err != nil {
+		return MapPGError(err)
+	}
+	if deleted == 0 {
+		return favorite.ErrPresetNotFound
+	}
+	return nil
+}
+
+func (r *FavoriteRepository) GetUserPresets(
+	ctx context.Context,
+	telegramUserID int64,
+) ([]*favorite.Preset, error) {
+	dbPresets, err := r.q.GetUserStakePresets(ctx, telegramUserID)
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	if len(dbPresets) == 0 {
+		return []*favorite.Preset{}, nil
+	}
+
+	presets := make([]*favorite.Preset, len(dbPresets))
+	byID := make(map[string]*favorite.Preset, len(dbPresets))
+	presetIDs := make([]pgtype.UUID, len(dbPresets))
+	for i, p := range dbPresets {
+		presets[i] = StakePresetToDomain(p)
+		byID[presets[i].ID] = presets[i]
+		presetIDs[i] = p.ID
+	}
+
+	rows, err := r.q.GetStakePresetGiftsByPresetIDs(ctx, presetIDs)
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	for _, row := range rows {
+		p := byID[pgUUIDToString(row.PresetID)]
+		p.GiftIDs = append(p.GiftIDs, pgUUIDToString(row.GiftID))
+	}
+	return presets, nil
+}
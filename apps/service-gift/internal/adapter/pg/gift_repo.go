@@ -3,12 +3,15 @@ package pg
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/peterparker2005/giftduels/apps/service-gift/internal/adapter/pg/sqlc"
 	"github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/gift"
+	"github.com/peterparker2005/giftduels/packages/inbox"
 	"github.com/peterparker2005/giftduels/packages/logger-go"
 	"github.com/peterparker2005/giftduels/packages/tonamount-go"
 )
@@ -20,7 +23,7 @@ type GiftRepository struct {
 }
 
 func NewGiftRepo(pool *pgxpool.Pool, logger *logger.Logger) gift.Repository {
-	return &GiftRepository{pool: pool, q: sqlc.New(pool), logger: logger}
+	return &GiftRepository{pool: pool, q: sqlc.New(inbox.NewDB(pool)), logger: logger}
 }
 
 func (r *GiftRepository) WithTx(tx pgx.Tx) gift.Repository {
@@ -69,20 +72,33 @@ func (r *GiftRepository) GetUserGifts(
 	}, nil
 }
 
-func (r *GiftRepository) GetUserActiveGifts(
+func (r *GiftRepository) SearchUserGifts(
 	ctx context.Context,
-	limit, offset int32,
 	ownerTelegramID int64,
-) (*gift.GetUserGiftsResult, error) {
-	total, err := r.q.GetUserActiveGiftsCount(ctx, ownerTelegramID)
+	filter *gift.InventoryFilter,
+	sort gift.InventorySort,
+	limit int32,
+	offset int32,
+) ([]*gift.Gift, error) {
+	f, err := inventoryFilterToDB(filter)
 	if err != nil {
-		return nil, MapPGError(err)
+		return nil, err
 	}
 
-	rows, err := r.q.GetUserActiveGifts(ctx, sqlc.GetUserActiveGiftsParams{
+	rows, err := r.q.SearchUserGifts(ctx, sqlc.SearchUserGiftsParams{
+		OwnerTelegramID: ownerTelegramID,
+		Statuses:        f.Statuses,
+		Collection:      f.Collection,
+		Model:           f.Model,
+		Backdrop:        f.Backdrop,
+		Symbol:          f.Symbol,
+		MinPrice:        f.MinPrice,
+		MaxPrice:        f.MaxPrice,
+		Search:          f.Search,
+		SortBy:          string(sort.By),
+		SortDesc:        sort.Desc,
 		Limit:           limit,
 		Offset:          offset,
-		OwnerTelegramID: ownerTelegramID,
 	})
 	if err != nil {
 		return nil, MapPGError(err)
@@ -90,20 +106,133 @@ func (r *GiftRepository) GetUserActiveGifts(
 
 	out := make([]*gift.Gift, len(rows))
 	for i, row := range rows {
-		gift, err := GiftToDomainFromUserActiveGiftsRow(row)
+		g, err := GiftToDomain(row)
 		if err != nil {
 			return nil, MapPGError(err)
 		}
-		out[i] = gift
+		out[i] = g
 	}
-	return &gift.GetUserGiftsResult{
-		Gifts: out,
-		Total: total,
-	}, nil
+	return out, nil
+}
+
+func (r *GiftRepository) GetUserInventorySummary(
+	ctx context.Context,
+	ownerTelegramID int64,
+	filter *gift.InventoryFilter,
+) (*gift.InventorySummary, error) {
+	f, err := inventoryFilterToDB(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.q.GetUserInventorySummary(ctx, sqlc.GetUserInventorySummaryParams{
+		OwnerTelegramID: ownerTelegramID,
+		Statuses:        f.Statuses,
+		Collection:      f.Collection,
+		Model:           f.Model,
+		Backdrop:        f.Backdrop,
+		Symbol:          f.Symbol,
+		MinPrice:        f.MinPrice,
+		MaxPrice:        f.MaxPrice,
+		Search:          f.Search,
+	})
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+
+	summary := &gift.InventorySummary{
+		TotalValue:  tonamount.Zero(),
+		Collections: make([]gift.CollectionSummary, len(rows)),
+	}
+	for i, row := range rows {
+		value, err := fromPgNumeric(row.TotalValue)
+		if err != nil {
+			return nil, err
+		}
+		totalValue, err := tonamount.NewTonAmountFromString(value)
+		if err != nil {
+			return nil, err
+		}
+		summary.Collections[i] = gift.CollectionSummary{
+			Collection: row.Collection,
+			Count:      row.GiftsCount,
+			TotalValue: totalValue,
+		}
+		summary.TotalCount += row.GiftsCount
+		summary.TotalValue = summary.TotalValue.Add(totalValue)
+	}
+	return summary, nil
+}
+
+type inventoryFilterParams struct {
+	Statuses   []string
+	Collection pgtype.Text
+	Model      pgtype.Text
+	Backdrop   pgtype.Text
+	Symbol     pgtype.Text
+	MinPrice   pgtype.Numeric
+	MaxPrice   pgtype.Numeric
+	Search     pgtype.Text
 }
 
-func (r *GiftRepository) StakeGiftForGame(ctx context.Context, id string) (*gift.Gift, error) {
-	_, err := r.q.StakeGiftForGame(ctx, mustPgUUID(id))
+func inventoryFilterToDB(filter *gift.InventoryFilter) (*inventoryFilterParams, error) {
+	statuses := filter.StatusesOrDefault()
+	if filter == nil {
+		filter = &gift.InventoryFilter{}
+	}
+
+	out := &inventoryFilterParams{
+		Statuses:   make([]string, len(statuses)),
+		Collection: stringPtrToPgText(filter.Collection),
+		Model:      stringPtrToPgText(filter.Model),
+		Backdrop:   stringPtrToPgText(filter.Backdrop),
+		Symbol:     stringPtrToPgText(filter.Symbol),
+	}
+	for i, st := range statuses {
+		out.Statuses[i] = string(st)
+	}
+
+	var err error
+	if out.MinPrice, err = tonAmountPtrToPgNumeric(filter.MinPrice); err != nil {
+		return nil, err
+	}
+	if out.MaxPrice, err = tonAmountPtrToPgNumeric(filter.MaxPrice); err != nil {
+		return nil, err
+	}
+
+	if filter.Search != nil && strings.TrimSpace(*filter.Search) != "" {
+		out.Search = pgtype.Text{String: likeContainsPattern(*filter.Search), Valid: true}
+	}
+
+	return out, nil
+}
+
+// likeContainsPattern экранирует спецсимволы LIKE и оборачивает строку в %...%.
+func likeContainsPattern(s string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(strings.TrimSpace(s))
+	return "%" + escaped + "%"
+}
+
+func (r *GiftRepository) StakeGiftForGame(
+	ctx context.Context,
+	id string,
+	relatedDuelID string,
+	leaseExpiresAt time.Time,
+) (*gift.Gift, error) {
+	var pgRelatedDuelID pgtype.UUID
+	if relatedDuelID != "" {
+		var err error
+		pgRelatedDuelID, err = pgUUID(relatedDuelID)
+		if err != nil {
+			return nil, MapPGError(err)
+		}
+	}
+
+	_, err := r.q.StakeGiftForGame(ctx, sqlc.StakeGiftForGameParams{
+		RelatedDuelID: pgRelatedDuelID,
+		LockExpiresAt: timeToPgTimestamp(leaseExpiresAt),
+		ID:            mustPgUUID(id),
+	})
 	if err != nil {
 		return nil, MapPGError(err)
 	}
@@ -112,6 +241,91 @@ func (r *GiftRepository) StakeGiftForGame(ctx context.Context, id string) (*gift
 	return r.GetGiftByID(ctx, id)
 }
 
+func (r *GiftRepository) ConfirmGiftLeases(
+	ctx context.Context,
+	operation gift.LockOperation,
+	operationID string,
+	giftIDs []string,
+) ([]string, error) {
+	pgOperationID, err := pgUUID(operationID)
+	if err != nil {
+		return nil, err
+	}
+	pgGiftIDs := make([]pgtype.UUID, len(giftIDs))
+	for i, id := range giftIDs {
+		if pgGiftIDs[i], err = pgUUID(id); err != nil {
+			return nil, err
+		}
+	}
+
+	confirmed, err := r.q.ConfirmGiftLeases(ctx, sqlc.ConfirmGiftLeasesParams{
+		GiftIds: pgGiftIDs,
+		LockedByType: sqlc.NullGiftLockOperation{
+			GiftLockOperation: sqlc.GiftLockOperation(operation),
+			Valid:             true,
+		},
+		LockedByID: pgOperationID,
+	})
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+
+	out := make([]string, len(confirmed))
+	for i, id := range confirmed {
+		out[i] = pgUUIDToString(id)
+	}
+	return out, nil
+}
+
+func (r *GiftRepository) GetExpiredLeases(
+	ctx context.Context,
+	now time.Time,
+	limit int32,
+) ([]*gift.Gift, error) {
+	expired, err := r.q.GetExpiredGiftLeases(ctx, sqlc.GetExpiredGiftLeasesParams{
+		Now:   timeToPgTimestamp(now),
+		Limit: limit,
+	})
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	return giftsToDomain(expired)
+}
+
+func (r *GiftRepository) ReleaseLeases(
+	ctx context.Context,
+	giftIDs []string,
+	now time.Time,
+) ([]*gift.Gift, error) {
+	var err error
+	pgGiftIDs := make([]pgtype.UUID, len(giftIDs))
+	for i, id := range giftIDs {
+		if pgGiftIDs[i], err = pgUUID(id); err != nil {
+			return nil, err
+		}
+	}
+
+	released, err := r.q.ReleaseGiftLeases(ctx, sqlc.ReleaseGiftLeasesParams{
+		GiftIds: pgGiftIDs,
+		Now:     timeToPgTimestamp(now),
+	})
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	return giftsToDomain(released)
+}
+
+func giftsToDomain(gifts []sqlc.Gift) ([]*gift.Gift, error) {
+	out := make([]*gift.Gift, len(gifts))
+	for i, g := range gifts {
+		var err error
+		if out[i], err = GiftToDomain(g); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
 func (r *GiftRepository) ReturnGiftFromGame(ctx context.Context, id string) (*gift.Gift, error) {
 	_, err := r.q.ReturnGiftFromGame(ctx, mustPgUUID(id))
 	if err != nil {
@@ -122,25 +336,20 @@ func (r *GiftRepository) ReturnGiftFromGame(ctx context.Context, id string) (*gi
 	return r.GetGiftByID(ctx, id)
 }
 
-func (r *GiftRepository) UpdateGiftOwner(
+func (r *GiftRepository) TransferGiftFromGame(
 	ctx context.Context,
 	id string,
+	duelID string,
 	ownerTelegramID int64,
 ) error {
-	_, err := r.q.UpdateGiftOwner(ctx, sqlc.UpdateGiftOwnerParams{
-		ID:              mustPgUUID(id),
-		OwnerTelegramID: ownerTelegramID,
-	})
+	pgDuelID, err := pgUUID(duelID)
 	if err != nil {
-		return MapPGError(err)
+		return err
 	}
-	idUUID, err := pgUUID(id)
-	if err != nil {
-		return MapPGError(err)
-	}
-	_, err = r.q.UpdateGiftStatus(ctx, sqlc.UpdateGiftStatusParams{
-		ID:     idUUID,
-		Status: sqlc.GiftStatusOwned,
+	_, err = r.q.TransferGiftFromGame(ctx, sqlc.TransferGiftFromGameParams{
+		OwnerTelegramID: ownerTelegramID,
+		ID:              mustPgUUID(id),
+		DuelID:          pgDuelID,
 	})
 	if err != nil {
 		return MapPGError(err)
@@ -181,6 +390,62 @@ func (r *GiftRepository) CompleteGiftWithdrawal(
 	return r.GetGiftByID(ctx, id)
 }
 
+func (r *GiftRepository) ListGift(ctx context.Context, id string) (*gift.Gift, error) {
+	_, err := r.q.ListGift(ctx, mustPgUUID(id))
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+
+	// Get full gift details with joins
+	return r.GetGiftByID(ctx, id)
+}
+
+func (r *GiftRepository) UnlistGift(ctx context.Context, id string) (*gift.Gift, error) {
+	_, err := r.q.UnlistGift(ctx, mustPgUUID(id))
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+
+	// Get full gift details with joins
+	return r.GetGiftByID(ctx, id)
+}
+
+func (r *GiftRepository) TransferListedGift(
+	ctx context.Context,
+	id string,
+	ownerTelegramID int64,
+) (*gift.Gift, error) {
+	_, err := r.q.TransferListedGift(ctx, sqlc.TransferListedGiftParams{
+		ID:              mustPgUUID(id),
+		OwnerTelegramID: ownerTelegramID,
+	})
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+
+	// Get full gift details with joins
+	return r.GetGiftByID(ctx, id)
+}
+
+func (r *GiftRepository) TransferOwnedGift(
+	ctx context.Context,
+	id string,
+	fromTelegramID int64,
+	toTelegramID int64,
+) (*gift.Gift, error) {
+	_, err := r.q.TransferOwnedGift(ctx, sqlc.TransferOwnedGiftParams{
+		ID:             mustPgUUID(id),
+		FromTelegramID: fromTelegramID,
+		ToTelegramID:   toTelegramID,
+	})
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+
+	// Get full gift details with joins
+	return r.GetGiftByID(ctx, id)
+}
+
 func (r *GiftRepository) CreateGiftEvent(
 	ctx context.Context,
 	params gift.CreateGiftEventParams,
@@ -222,6 +487,117 @@ func (r *GiftRepository) GetGiftEvents(
 	return events, nil
 }
 
+func (r *GiftRepository) GetGiftHistory(
+	ctx context.Context,
+	giftID string,
+	filter gift.EventFilter,
+	limit int32,
+	offset int32,
+) (*gift.GetGiftEventsResult, error) {
+	pgGiftID := mustPgUUID(giftID)
+	eventTypes := eventTypesToDB(filter.EventTypes)
+
+	total, err := r.q.GetGiftHistoryCount(ctx, sqlc.GetGiftHistoryCountParams{
+		GiftID:     pgGiftID,
+		EventTypes: eventTypes,
+	})
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+
+	dbEvents, err := r.q.GetGiftHistory(ctx, sqlc.GetGiftHistoryParams{
+		GiftID:     pgGiftID,
+		EventTypes: eventTypes,
+		Limit:      limit,
+		Offset:     offset,
+	})
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+
+	events, err := giftEventsToDomain(dbEvents)
+	if err != nil {
+		return nil, err
+	}
+	return &gift.GetGiftEventsResult{
+		Events: events,
+		Total:  total,
+	}, nil
+}
+
+func (r *GiftRepository) GiftHasUserEvent(
+	ctx context.Context,
+	giftID string,
+	telegramUserID int64,
+) (bool, error) {
+	exists, err := r.q.GiftHasUserEvent(ctx, sqlc.GiftHasUserEventParams{
+		GiftID:         mustPgUUID(giftID),
+		TelegramUserID: pgtype.Int8{Int64: telegramUserID, Valid: true},
+	})
+	if err != nil {
+		return false, MapPGError(err)
+	}
+	return exists, nil
+}
+
+func (r *GiftRepository) GetUserGiftActivity(
+	ctx context.Context,
+	telegramUserID int64,
+	filter gift.EventFilter,
+	limit int32,
+	offset int32,
+) (*gift.GetGiftEventsResult, error) {
+	pgTelegramUserID := pgtype.Int8{Int64: telegramUserID, Valid: true}
+	eventTypes := eventTypesToDB(filter.EventTypes)
+
+	total, err := r.q.GetUserGiftActivityCount(ctx, sqlc.GetUserGiftActivityCountParams{
+		TelegramUserID: pgTelegramUserID,
+		EventTypes:     eventTypes,
+	})
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+
+	dbEvents, err := r.q.GetUserGiftActivity(ctx, sqlc.GetUserGiftActivityParams{
+		TelegramUserID: pgTelegramUserID,
+		EventTypes:     eventTypes,
+		Limit:          limit,
+		Offset:         offset,
+	})
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+
+	events, err := giftEventsToDomain(dbEvents)
+	if err != nil {
+		return nil, err
+	}
+	return &gift.GetGiftEventsResult{
+		Events: events,
+		Total:  total,
+	}, nil
+}
+
+func giftEventsToDomain(dbEvents []sqlc.GiftEvent) ([]*gift.Event, error) {
+	events := make([]*gift.Event, len(dbEvents))
+	for i, dbEvent := range dbEvents {
+		event, err := GiftEventToDomain(dbEvent)
+		if err != nil {
+			return nil, MapPGError(err)
+		}
+		events[i] = event
+	}
+	return events, nil
+}
+
+func eventTypesToDB(eventTypes []gift.EventType) []string {
+	out := make([]string, len(eventTypes))
+	for i, t := range eventTypes {
+		out[i] = string(t)
+	}
+	return out
+}
+
 func (r *GiftRepository) CreateGift(
 	ctx context.Context,
 	params *gift.CreateGiftParams,
@@ -416,3 +792,26 @@ func (r *GiftRepository) FindSymbolByName(ctx context.Context, name string) (*gi
 	}
 	return SymbolToDomain(dbSymbol), nil
 }
+
+func (r *GiftRepository) CreateFailedCompensation(
+	ctx context.Context,
+	params *gift.CreateFailedCompensationParams,
+) error {
+	amount, err := pgNumeric(params.Amount.String())
+	if err != nil {
+		return err
+	}
+	err = r.q.CreateFailedSagaCompensation(ctx, sqlc.CreateFailedSagaCompensationParams{
+		Saga:           params.Saga,
+		Step:           params.Step,
+		GiftID:         mustPgUUID(params.GiftID),
+		TelegramUserID: params.TelegramUserID,
+		Amount:         amount,
+		IdempotencyKey: params.IdempotencyKey,
+		Error:          params.Error,
+	})
+	if err != nil {
+		return MapPGError(err)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package pg
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/gift"
+	"github.com/peterparker2005/giftduels/packages/tonamount-go"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func mustTon(t *testing.T, s string) *tonamount.TonAmount {
+	t.Helper()
+	a, err := tonamount.NewTonAmountFromString(s)
+	if err != nil {
+		t.Fatalf("cannot parse TonAmount from %q: %v", s, err)
+	}
+	return a
+}
+
+// numericString возвращает значение numeric или "" для NULL.
+func numericString(t *testing.T, n pgtype.Numeric) string {
+	t.Helper()
+	if !n.Valid {
+		return ""
+	}
+	s, err := fromPgNumeric(n)
+	if err != nil {
+		t.Fatalf("numeric: %v", err)
+	}
+	return s
+}
+
+func TestInventoryFilterToDB(t *testing.T) {
+	tests := []struct {
+		name           string
+		filter         *gift.InventoryFilter
+		wantStatuses   []string
+		wantCollection pgtype.Text
+		wantSearch     pgtype.Text
+		wantMinPrice   string
+		wantMaxPrice   string
+	}{
+		{
+			name:         "nil filter shows active gifts",
+			wantStatuses: []string{"owned", "in_game"},
+		},
+		{
+			name:         "empty filter shows active gifts",
+			filter:       &gift.InventoryFilter{},
+			wantStatuses: []string{"owned", "in_game"},
+		},
+		{
+			name:         "explicit statuses",
+			filter:       &gift.InventoryFilter{Statuses: []gift.Status{gift.StatusListed, gift.StatusWithdrawn}},
+			wantStatuses: []string{"listed", "withdrawn"},
+		},
+		{
+			name:           "attributes are matched as is",
+			filter:         &gift.InventoryFilter{Collection: ptr("Plush Pepe")},
+			wantStatuses:   []string{"owned", "in_game"},
+			wantCollection: pgtype.Text{String: "Plush Pepe", Valid: true},
+		},
+		{
+			name:         "price range",
+			filter:       &gift.InventoryFilter{MinPrice: mustTon(t, "1.5"), MaxPrice: mustTon(t, "10")},
+			wantStatuses: []string{"owned", "in_game"},
+			wantMinPrice: "1.5",
+			wantMaxPrice: "10",
+		},
+		{
+			name:         "search is trimmed and wrapped",
+			filter:       &gift.InventoryFilter{Search: ptr("  pepe ")},
+			wantStatuses: []string{"owned", "in_game"},
+			wantSearch:   pgtype.Text{String: "%pepe%", Valid: true},
+		},
+		{
+			// спецсимволы LIKE ищутся буквально
+			name:         "search escapes like wildcards",
+			filter:       &gift.InventoryFilter{Search: ptr(`100%_off\`)},
+			wantStatuses: []string{"owned", "in_game"},
+			wantSearch:   pgtype.Text{String: `%100\%\_off\\%`, Valid: true},
+		},
+		{
+			name:         "blank search is ignored",
+			filter:       &gift.InventoryFilter{Search: ptr("   ")},
+			wantStatuses: []string{"owned", "in_game"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := inventoryFilterToDB(tt.filter)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(got.Statuses, tt.wantStatuses) {
+				t.Errorf("statuses %v, want %v", got.Statuses, tt.wantStatuses)
+			}
+			if got.Collection != tt.wantCollection {
+				t.Errorf("collection %+v, want %+v", got.Collection, tt.wantCollection)
+			}
+			if got.Model.Valid || got.Backdrop.Valid || got.Symbol.Valid {
+				t.Errorf("unset attributes must be NULL: %+v", got)
+			}
+			if got.Search != tt.wantSearch {
+				t.Errorf("search %+v, want %+v", got.Search, tt.wantSearch)
+			}
+			if s := numericString(t, got.MinPrice); s != tt.wantMinPrice {
+				t.Errorf("min price %q, want %q", s, tt.wantMinPrice)
+			}
+			if s := numericString(t, got.MaxPrice); s != tt.wantMaxPrice {
+				t.Errorf("max price %q, want %q", s, tt.wantMaxPrice)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package pg
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/adapter/pg/sqlc"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/listing"
+	"github.com/peterparker2005/giftduels/packages/inbox"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+	"github.com/peterparker2005/giftduels/packages/tonamount-go"
+)
+
+type ListingRepository struct {
+	pool   *pgxpool.Pool
+	q      *sqlc.Queries
+	logger *logger.Logger
+}
+
+func NewListingRepo(pool *pgxpool.Pool, logger *logger.Logger) listing.Repository {
+	return &ListingRepository{pool: pool, q: sqlc.New(inbox.NewDB(pool)), logger: logger}
+}
+
+func (r *ListingRepository) WithTx(tx pgx.Tx) listing.Repository {
+	return &ListingRepository{pool: r.pool, q: r.q.WithTx(tx), logger: r.logger}
+}
+
+func (r *ListingRepository) CreateListing(
+	ctx context.Context,
+	params *listing.CreateListingParams,
+) (*listing.Listing, error) {
+	price, err := pgNumeric(params.Price.String())
+	if err != nil {
+		return nil, err
+	}
+
+	dbListing, err := r.q.CreateListing(ctx, sqlc.CreateListingParams{
+		GiftID:           mustPgUUID(params.GiftID),
+		SellerTelegramID: params.SellerTelegramID,
+		Price:            price,
+	})
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	return ListingToDomain(dbListing)
+}
+
+func (r *ListingRepository) GetListingByID(
+	ctx context.Context,
+	id string,
+) (*listing.Listing, error) {
+	dbListing, err := r.q.GetListingByID(ctx, mustPgUUID(id))
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	return ListingToDomain(dbListing)
+}
+
+func (r *ListingRepository) GetListingByIDForUpdate(
+	ctx context.Context,
+	id string,
+) (*listing.Listing, error) {
+	dbListing, err := r.q.GetListingByIDForUpdate(ctx, mustPgUUID(id))
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	return ListingToDomain(dbListing)
+}
+
+func (r *ListingRepository) CancelListing(
+	ctx context.Context,
+	id string,
+) (*listing.Listing, error) {
+	dbListing, err := r.q.CancelListing(ctx, mustPgUUID(id))
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	return ListingToDomain(dbListing)
+}
+
+func (r *ListingRepository) MarkListingSold(
+	ctx context.Context,
+	params *listing.MarkListingSoldParams,
+) (*listing.Listing, error) {
+	fee, err := pgNumeric(params.Fee.String())
+	if err != nil {
+		return nil, err
+	}
+
+	dbListing, err := r.q.MarkListingSold(ctx, sqlc.MarkListingSoldParams{
+		ID:              mustPgUUID(params.ID),
+		BuyerTelegramID: int64PtrToPgInt8(&params.BuyerTelegramID),
+		Fee:             fee,
+	})
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	return ListingToDomain(dbListing)
+}
+
+func (r *ListingRepository) GetActiveListings(
+	ctx context.Context,
+	filter *listing.Filter,
+	limit, offset int32,
+) (*listing.GetActiveListingsResult, error) {
+	if filter == nil {
+		filter = &listing.Filter{}
+	}
+
+	minPrice, err := tonAmountPtrToPgNumeric(filter.MinPrice)
+	if err != nil {
+		return nil, err
+	}
+	maxPrice, err := tonAmountPtrToPgNumeric(filter.MaxPrice)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := r.q.GetActiveListingsCount(ctx, sqlc.GetActiveListingsCountParams{
+		Collection: stringPtrToPgText(filter.Collection),
+		Model:      stringPtrToPgText(filter.Model),
+		Backdrop:   stringPtrToPgText(filter.Backdrop),
+		MinPrice:   minPrice,
+		MaxPrice:   maxPrice,
+	})
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+
+	rows, err := r.q.GetActiveListings(ctx, sqlc.GetActiveListingsParams{
+		Collection: stringPtrToPgText(filter.Collection),
+		Model:      stringPtrToPgText(filter.Model),
+		Backdrop:   stringPtrToPgText(filter.Backdrop),
+		MinPrice:   minPrice,
+		MaxPrice:   maxPrice,
+		Limit:      limit,
+		Offset:     offset,
+	})
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+
+	out := make([]*listing.Listing, len(rows))
+	for i, row := range rows {
+		l, err := ListingToDomain(row)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = l
+	}
+	return &listing.GetActiveListingsResult{
+		Listings: out,
+		Total:    total,
+	}, nil
+}
+
+// tonAmountPtrToPgNumeric converts optional *tonamount.TonAmount to pgtype.Numeric.
+func tonAmountPtrToPgNumeric(amount *tonamount.TonAmount) (pgtype.Numeric, error) {
+	if amount == nil {
+		return pgtype.Numeric{Valid: false}, nil
+	}
+	return pgNumeric(amount.String())
+}
@@ -5,8 +5,14 @@ import (
 
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/peterparker2005/giftduels/apps/service-gift/internal/adapter/pg/sqlc"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/favorite"
 	"github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/gift"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/listing"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/reconciliation"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/sellback"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/withdrawal"
 	"github.com/peterparker2005/giftduels/packages/tonamount-go"
+	"github.com/shopspring/decimal"
 )
 
 // GiftToDomain converts sqlc.Gift to domain.Gift.
@@ -40,18 +46,26 @@ func GiftToDomain(dbGift sqlc.Gift) (*gift.Gift, error) {
 		Model:            gift.Model{ID: dbGift.ModelID},
 		Backdrop:         gift.Backdrop{ID: dbGift.BackdropID},
 		Symbol:           gift.Symbol{ID: dbGift.SymbolID},
+		RelatedDuelID:    pgUUIDToString(dbGift.RelatedDuelID),
+		Lock:             giftLockToDomain(dbGift),
 		// Note: Collection, Model, Backdrop, Symbol will be populated separately.
 		// since the basic GetGiftByID query doesn't include JOINs.
 	}, nil
 }
 
-// GiftToDomainFromUserGiftsRow converts sqlc.Gift to domain.Gift (same as GiftToDomain).
-func GiftToDomainFromUserGiftsRow(dbGift sqlc.Gift) (*gift.Gift, error) {
-	return GiftToDomain(dbGift)
+func giftLockToDomain(dbGift sqlc.Gift) *gift.Lock {
+	if !dbGift.LockedByType.Valid {
+		return nil
+	}
+	return &gift.Lock{
+		Operation:   gift.LockOperation(dbGift.LockedByType.GiftLockOperation),
+		OperationID: pgUUIDToString(dbGift.LockedByID),
+		ExpiresAt:   pgTimestampToTime(dbGift.LockExpiresAt),
+	}
 }
 
-// GiftToDomainFromUserActiveGiftsRow converts sqlc.Gift to domain.Gift (same as GiftToDomain).
-func GiftToDomainFromUserActiveGiftsRow(dbGift sqlc.Gift) (*gift.Gift, error) {
+// GiftToDomainFromUserGiftsRow converts sqlc.Gift to domain.Gift (same as GiftToDomain).
+func GiftToDomainFromUserGiftsRow(dbGift sqlc.Gift) (*gift.Gift, error) {
 	return GiftToDomain(dbGift)
 }
 
@@ -245,6 +259,8 @@ func giftStatusToDomain(status sqlc.GiftStatus) gift.Status {
 		return gift.StatusWithdrawPending
 	case sqlc.GiftStatusWithdrawn:
 		return gift.StatusWithdrawn
+	case sqlc.GiftStatusListed:
+		return gift.StatusListed
 	default:
 		// Log unknown status and return default
 		return gift.StatusOwned // fallback to safe default
@@ -262,8 +278,213 @@ func giftStatusToSQLC(status gift.Status) sqlc.GiftStatus {
 		return sqlc.GiftStatusWithdrawPending
 	case gift.StatusWithdrawn:
 		return sqlc.GiftStatusWithdrawn
+	case gift.StatusListed:
+		return sqlc.GiftStatusListed
 	default:
 		// Log unknown status and return default
 		return sqlc.GiftStatusOwned // fallback to safe default
 	}
 }
+
+// ListingToDomain converts sqlc.GiftListing to domain listing.Listing.
+func ListingToDomain(dbListing sqlc.GiftListing) (*listing.Listing, error) {
+	price, err := fromPgNumeric(dbListing.Price)
+	if err != nil {
+		return nil, err
+	}
+	priceAmount, err := tonamount.NewTonAmountFromString(price)
+	if err != nil {
+		return nil, err
+	}
+
+	var feeAmount *tonamount.TonAmount
+	if dbListing.Fee.Valid {
+		fee, err := fromPgNumeric(dbListing.Fee)
+		if err != nil {
+			return nil, err
+		}
+		feeAmount, err = tonamount.NewTonAmountFromString(fee)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var buyerTelegramID *int64
+	if dbListing.BuyerTelegramID.Valid {
+		buyerTelegramID = &dbListing.BuyerTelegramID.Int64
+	}
+
+	return &listing.Listing{
+		ID:               pgUUIDToString(dbListing.ID),
+		GiftID:           pgUUIDToString(dbListing.GiftID),
+		SellerTelegramID: dbListing.SellerTelegramID,
+		BuyerTelegramID:  buyerTelegramID,
+		Price:            priceAmount,
+		Fee:              feeAmount,
+		Status:           listing.Status(dbListing.Status),
+		CreatedAt:        pgTimestampToTimeRequired(dbListing.CreatedAt),
+		UpdatedAt:        pgTimestampToTimeRequired(dbListing.UpdatedAt),
+		SoldAt:           pgTimestampToTime(dbListing.SoldAt),
+	}, nil
+}
+
+// SellBackQuoteToDomain converts sqlc.GiftSellBackQuote to domain sellback.Quote.
+func SellBackQuoteToDomain(dbQuote sqlc.GiftSellBackQuote) (*sellback.Quote, error) {
+	floorPrice, err := fromPgNumeric(dbQuote.FloorPrice)
+	if err != nil {
+		return nil, err
+	}
+	floorPriceAmount, err := tonamount.NewTonAmountFromString(floorPrice)
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := fromPgNumeric(dbQuote.Amount)
+	if err != nil {
+		return nil, err
+	}
+	amountValue, err := tonamount.NewTonAmountFromString(amount)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sellback.Quote{
+		ID:             pgUUIDToString(dbQuote.ID),
+		GiftID:         pgUUIDToString(dbQuote.GiftID),
+		TelegramUserID: dbQuote.TelegramUserID,
+		FloorPrice:     floorPriceAmount,
+		Amount:         amountValue,
+		ExpiresAt:      pgTimestampToTimeRequired(dbQuote.ExpiresAt),
+		ExecutedAt:     pgTimestampToTime(dbQuote.ExecutedAt),
+		CreatedAt:      pgTimestampToTimeRequired(dbQuote.CreatedAt),
+	}, nil
+}
+
+// WithdrawalIntentToDomain converts sqlc.WithdrawalIntent to domain withdrawal.Intent.
+func WithdrawalIntentToDomain(dbIntent sqlc.WithdrawalIntent) *withdrawal.Intent {
+	giftIDs := make([]string, len(dbIntent.GiftIds))
+	for i, id := range dbIntent.GiftIds {
+		giftIDs[i] = pgUUIDToString(id)
+	}
+
+	return &withdrawal.Intent{
+		ID:             pgUUIDToString(dbIntent.ID),
+		TelegramUserID: dbIntent.TelegramUserID,
+		GiftIDs:        giftIDs,
+		StarsAmount:    dbIntent.StarsAmount,
+		InvoiceURL:     dbIntent.InvoiceUrl,
+		Status:         withdrawal.IntentStatus(dbIntent.Status),
+		ExpiresAt:      pgTimestampToTimeRequired(dbIntent.ExpiresAt),
+		CompletedAt:    pgTimestampToTime(dbIntent.CompletedAt),
+		CreatedAt:      pgTimestampToTimeRequired(dbIntent.CreatedAt),
+		UpdatedAt:      pgTimestampToTimeRequired(dbIntent.UpdatedAt),
+	}
+}
+
+// WithdrawalToDomain converts sqlc.Withdrawal with its gifts to domain withdrawal.Withdrawal.
+func WithdrawalToDomain(
+	dbWithdrawal sqlc.Withdrawal,
+	dbGifts []sqlc.WithdrawalGift,
+) (*withdrawal.Withdrawal, error) {
+	commission, err := withdrawalAmountFromPg(dbWithdrawal.CommissionAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	gifts := make([]*withdrawal.Gift, len(dbGifts))
+	for i, g := range dbGifts {
+		giftCommission, gErr := withdrawalAmountFromPg(g.CommissionAmount)
+		if gErr != nil {
+			return nil, gErr
+		}
+		gifts[i] = &withdrawal.Gift{
+			GiftID:           pgUUIDToString(g.GiftID),
+			CommissionAmount: giftCommission,
+			Status:           withdrawal.GiftStatus(g.Status),
+			FailureReason:    pgTextToString(g.FailureReason),
+		}
+	}
+
+	var intentID *string
+	if dbWithdrawal.IntentID.Valid {
+		id := pgUUIDToString(dbWithdrawal.IntentID)
+		intentID = &id
+	}
+
+	return &withdrawal.Withdrawal{
+		ID:                 pgUUIDToString(dbWithdrawal.ID),
+		TelegramUserID:     dbWithdrawal.TelegramUserID,
+		CommissionCurrency: withdrawal.CommissionCurrency(dbWithdrawal.CommissionCurrency),
+		CommissionAmount:   commission,
+		Status:             withdrawal.Status(dbWithdrawal.Status),
+		FailureReason:      pgTextToString(dbWithdrawal.FailureReason),
+		IntentID:           intentID,
+		Gifts:              gifts,
+		CompletedAt:        pgTimestampToTime(dbWithdrawal.CompletedAt),
+		FailedAt:           pgTimestampToTime(dbWithdrawal.FailedAt),
+		CreatedAt:          pgTimestampToTimeRequired(dbWithdrawal.CreatedAt),
+		UpdatedAt:          pgTimestampToTimeRequired(dbWithdrawal.UpdatedAt),
+	}, nil
+}
+
+// withdrawalAmountFromPg отбрасывает хвостовые нули NUMERIC(20, 9),
+// чтобы Stars оставались целыми.
+func withdrawalAmountFromPg(n pgtype.Numeric) (string, error) {
+	amount, err := fromPgNumeric(n)
+	if err != nil {
+		return "", err
+	}
+	d, err := decimal.NewFromString(amount)
+	if err != nil {
+		return "", err
+	}
+	return d.String(), nil
+}
+
+func ReconciliationRunToDomain(
+	dbRun sqlc.ReconciliationRun,
+	dbDiscrepancies []sqlc.ReconciliationDiscrepancy,
+) *reconciliation.Run {
+	discrepancies := make([]*reconciliation.Discrepancy, len(dbDiscrepancies))
+	for i, d := range dbDiscrepancies {
+		discrepancy := &reconciliation.Discrepancy{
+			Kind: reconciliation.DiscrepancyKind(d.Kind),
+			Key: reconciliation.Key{
+				TelegramGiftID: d.TelegramGiftID,
+				CollectibleID:  d.CollectibleID,
+			},
+			Title: d.Title,
+			Slug:  d.Slug,
+		}
+		if d.GiftID.Valid {
+			giftID := pgUUIDToString(d.GiftID)
+			discrepancy.GiftID = &giftID
+		}
+		if d.GiftStatus.Valid {
+			status := gift.Status(d.GiftStatus.GiftStatus)
+			discrepancy.GiftStatus = &status
+		}
+		discrepancies[i] = discrepancy
+	}
+
+	return &reconciliation.Run{
+		ID:            pgUUIDToString(dbRun.ID),
+		CustodyCount:  int(dbRun.CustodyCount),
+		LedgerCount:   int(dbRun.LedgerCount),
+		Discrepancies: discrepancies,
+		StartedAt:     dbRun.StartedAt.Time,
+		FinishedAt:    dbRun.FinishedAt.Time,
+	}
+}
+
+// StakePresetToDomain converts sqlc.StakePreset to favorite.Preset without gifts.
+func StakePresetToDomain(dbPreset sqlc.StakePreset) *favorite.Preset {
+	return &favorite.Preset{
+		ID:             pgUUIDToString(dbPreset.ID),
+		TelegramUserID: dbPreset.TelegramUserID,
+		Name:           dbPreset.Name,
+		GiftIDs:        []string{},
+		CreatedAt:      dbPreset.CreatedAt.Time,
+		UpdatedAt:      dbPreset.UpdatedAt.Time,
+	}
+}
@@ -12,6 +12,13 @@ import (
 var Module = fx.Module("pg",
 	fx.Provide(
 		NewGiftRepo,
+		NewListingRepo,
+		NewSellBackRepo,
+		NewWithdrawalRepo,
+		NewCatalogRepo,
+		NewPortfolioRepo,
+		NewReconciliationRepo,
+		NewFavoriteRepo,
 		NewPgxTxManager,
 		func(cfg *config.Config) (*pgxpool.Pool, error) {
 			return Connect(context.Background(), Config{
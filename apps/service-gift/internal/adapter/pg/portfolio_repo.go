@@ -0,0 +1,138 @@
+package pg
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/adapter/pg/sqlc"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/portfolio"
+	"github.com/peterparker2005/giftduels/packages/inbox"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+	"github.com/peterparker2005/giftduels/packages/tonamount-go"
+)
+
+type PortfolioRepository struct {
+	q      *sqlc.Queries
+	logger *logger.Logger
+}
+
+func NewPortfolioRepo(pool *pgxpool.Pool, logger *logger.Logger) portfolio.Repository {
+	return &PortfolioRepository{q: sqlc.New(inbox.NewDB(pool)), logger: logger}
+}
+
+func (r *PortfolioRepository) GetUserValuation(
+	ctx context.Context,
+	telegramUserID int64,
+) (*portfolio.Valuation, error) {
+	row, err := r.q.GetUserPortfolioValuation(ctx, telegramUserID)
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	return valuationToDomain(row.OwnedValue, row.InGameValue, row.OwnedCount, row.InGameCount)
+}
+
+func (r *PortfolioRepository) GetUserPerformance(
+	ctx context.Context,
+	telegramUserID int64,
+) (*portfolio.Performance, error) {
+	row, err := r.q.GetUserPortfolioPerformance(ctx, telegramUserID)
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+
+	amounts := make([]*tonamount.TonAmount, 0, 4)
+	for _, n := range []pgtype.Numeric{
+		row.DepositedValue,
+		row.WithdrawnValue,
+		row.DuelWonValue,
+		row.DuelLostValue,
+	} {
+		amount, aErr := portfolioAmountFromPg(n)
+		if aErr != nil {
+			return nil, aErr
+		}
+		amounts = append(amounts, amount)
+	}
+
+	return &portfolio.Performance{
+		DepositedValue: amounts[0],
+		WithdrawnValue: amounts[1],
+		DuelWonValue:   amounts[2],
+		DuelLostValue:  amounts[3],
+	}, nil
+}
+
+func (r *PortfolioRepository) GetUserSnapshots(
+	ctx context.Context,
+	telegramUserID int64,
+	from, to time.Time,
+) ([]*portfolio.Snapshot, error) {
+	rows, err := r.q.GetUserPortfolioSnapshots(ctx, sqlc.GetUserPortfolioSnapshotsParams{
+		TelegramUserID: telegramUserID,
+		FromDate:       timeToPgDate(from),
+		ToDate:         timeToPgDate(to),
+	})
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+
+	out := make([]*portfolio.Snapshot, len(rows))
+	for i, row := range rows {
+		valuation, vErr := valuationToDomain(
+			row.OwnedValue,
+			row.InGameValue,
+			int64(row.OwnedCount),
+			int64(row.InGameCount),
+		)
+		if vErr != nil {
+			return nil, vErr
+		}
+		out[i] = &portfolio.Snapshot{
+			Date:      row.SnapshotDate.Time,
+			Valuation: *valuation,
+		}
+	}
+	return out, nil
+}
+
+func (r *PortfolioRepository) RecordSnapshots(ctx context.Context, date time.Time) (int64, error) {
+	n, err := r.q.UpsertPortfolioSnapshots(ctx, timeToPgDate(date))
+	if err != nil {
+		return 0, MapPGError(err)
+	}
+	return n, nil
+}
+
+func valuationToDomain(
+	owned, inGame pgtype.Numeric,
+	ownedCount, inGameCount int64,
+) (*portfolio.Valuation, error) {
+	ownedValue, err := portfolioAmountFromPg(owned)
+	if err != nil {
+		return nil, err
+	}
+	inGameValue, err := portfolioAmountFromPg(inGame)
+	if err != nil {
+		return nil, err
+	}
+	return &portfolio.Valuation{
+		OwnedValue:  ownedValue,
+		InGameValue: inGameValue,
+		OwnedCount:  ownedCount,
+		InGameCount: inGameCount,
+	}, nil
+}
+
+func portfolioAmountFromPg(n pgtype.Numeric) (*tonamount.TonAmount, error) {
+	amount, err := fromPgNumeric(n)
+	if err != nil {
+		return nil, err
+	}
+	return tonamount.NewTonAmountFromString(amount)
+}
+
+func timeToPgDate(t time.Time) pgtype.Date {
+	return pgtype.Date{Time: portfolio.SnapshotDate(t), Valid: true}
+}
@@ -0,0 +1,134 @@
+package pg
+
+import (
+	"context"
+
+	"github.com/ccoveille/go-safecast"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/adapter/pg/sqlc"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/gift"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/reconciliation"
+	"github.com/peterparker2005/giftduels/packages/inbox"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+)
+
+type ReconciliationRepository struct {
+	q      *sqlc.Queries
+	logger *logger.Logger
+}
+
+func NewReconciliationRepo(pool *pgxpool.Pool, logger *logger.Logger) reconciliation.Repository {
+	return &ReconciliationRepository{q: sqlc.New(inbox.NewDB(pool)), logger: logger}
+}
+
+func (r *ReconciliationRepository) WithTx(tx pgx.Tx) reconciliation.Repository {
+	return &ReconciliationRepository{q: r.q.WithTx(tx), logger: r.logger}
+}
+
+func (r *ReconciliationRepository) ListLedgerGifts(
+	ctx context.Context,
+) ([]*reconciliation.LedgerGift, error) {
+	rows, err := r.q.GetReconcilableGifts(ctx)
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+
+	out := make([]*reconciliation.LedgerGift, len(rows))
+	for i, row := range rows {
+		out[i] = &reconciliation.LedgerGift{
+			Key: reconciliation.Key{
+				TelegramGiftID: row.TelegramGiftID,
+				CollectibleID:  row.CollectibleID,
+			},
+			GiftID: pgUUIDToString(row.ID),
+			Status: gift.Status(row.Status),
+			Title:  row.Title,
+			Slug:   row.Slug,
+		}
+	}
+	return out, nil
+}
+
+func (r *ReconciliationRepository) SaveRun(ctx context.Context, run *reconciliation.Run) error {
+	custodyCount, err := safecast.ToInt32(run.CustodyCount)
+	if err != nil {
+		return err
+	}
+	ledgerCount, err := safecast.ToInt32(run.LedgerCount)
+	if err != nil {
+		return err
+	}
+	discrepancyCount, err := safecast.ToInt32(len(run.Discrepancies))
+	if err != nil {
+		return err
+	}
+
+	dbRun, err := r.q.CreateReconciliationRun(ctx, sqlc.CreateReconciliationRunParams{
+		CustodyCount:     custodyCount,
+		LedgerCount:      ledgerCount,
+		DiscrepancyCount: discrepancyCount,
+		StartedAt:        timeToPgTimestamp(run.StartedAt),
+	})
+	if err != nil {
+		return MapPGError(err)
+	}
+
+	for _, d := range run.Discrepancies {
+		params := sqlc.CreateReconciliationDiscrepancyParams{
+			RunID:          dbRun.ID,
+			Kind:           sqlc.ReconciliationDiscrepancyKind(d.Kind),
+			TelegramGiftID: d.Key.TelegramGiftID,
+			CollectibleID:  d.Key.CollectibleID,
+			Title:          d.Title,
+			Slug:           d.Slug,
+		}
+		if d.GiftID != nil {
+			params.GiftID = mustPgUUID(*d.GiftID)
+		}
+		if d.GiftStatus != nil {
+			params.GiftStatus = sqlc.NullGiftStatus{
+				GiftStatus: sqlc.GiftStatus(*d.GiftStatus),
+				Valid:      true,
+			}
+		}
+		if err = r.q.CreateReconciliationDiscrepancy(ctx, params); err != nil {
+			return MapPGError(err)
+		}
+	}
+
+	run.ID = pgUUIDToString(dbRun.ID)
+	run.FinishedAt = dbRun.FinishedAt.Time
+	return nil
+}
+
+func (r *ReconciliationRepository) GetRun(ctx context.Context, id string) (*reconciliation.Run, error) {
+	runID, err := pgUUID(id)
+	if err != nil {
+		return nil, err
+	}
+	dbRun, err := r.q.GetReconciliationRunByID(ctx, runID)
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	return r.loadRun(ctx, dbRun)
+}
+
+func (r *ReconciliationRepository) GetLatestRun(ctx context.Context) (*reconciliation.Run, error) {
+	dbRun, err := r.q.GetLatestReconciliationRun(ctx)
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	return r.loadRun(ctx, dbRun)
+}
+
+func (r *ReconciliationRepository) loadRun(
+	ctx context.Context,
+	dbRun sqlc.ReconciliationRun,
+) (*reconciliation.Run, error) {
+	dbDiscrepancies, err := r.q.GetReconciliationDiscrepanciesByRunID(ctx, dbRun.ID)
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	return ReconciliationRunToDomain(dbRun, dbDiscrepancies), nil
+}
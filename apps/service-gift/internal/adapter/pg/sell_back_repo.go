@@ -0,0 +1,74 @@
+package pg
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/adapter/pg/sqlc"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/sellback"
+	"github.com/peterparker2005/giftduels/packages/inbox"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+)
+
+type SellBackRepository struct {
+	pool   *pgxpool.Pool
+	q      *sqlc.Queries
+	logger *logger.Logger
+}
+
+func NewSellBackRepo(pool *pgxpool.Pool, logger *logger.Logger) sellback.Repository {
+	return &SellBackRepository{pool: pool, q: sqlc.New(inbox.NewDB(pool)), logger: logger}
+}
+
+func (r *SellBackRepository) WithTx(tx pgx.Tx) sellback.Repository {
+	return &SellBackRepository{pool: r.pool, q: r.q.WithTx(tx), logger: r.logger}
+}
+
+func (r *SellBackRepository) CreateQuote(
+	ctx context.Context,
+	params *sellback.CreateQuoteParams,
+) (*sellback.Quote, error) {
+	floorPrice, err := pgNumeric(params.FloorPrice.String())
+	if err != nil {
+		return nil, err
+	}
+	amount, err := pgNumeric(params.Amount.String())
+	if err != nil {
+		return nil, err
+	}
+
+	dbQuote, err := r.q.CreateSellBackQuote(ctx, sqlc.CreateSellBackQuoteParams{
+		GiftID:         mustPgUUID(params.GiftID),
+		TelegramUserID: params.TelegramUserID,
+		FloorPrice:     floorPrice,
+		Amount:         amount,
+		ExpiresAt:      timeToPgTimestamp(params.ExpiresAt),
+	})
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	return SellBackQuoteToDomain(dbQuote)
+}
+
+func (r *SellBackRepository) GetQuoteByIDForUpdate(
+	ctx context.Context,
+	id string,
+) (*sellback.Quote, error) {
+	dbQuote, err := r.q.GetSellBackQuoteByIDForUpdate(ctx, mustPgUUID(id))
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	return SellBackQuoteToDomain(dbQuote)
+}
+
+func (r *SellBackRepository) MarkQuoteExecuted(
+	ctx context.Context,
+	id string,
+) (*sellback.Quote, error) {
+	dbQuote, err := r.q.MarkSellBackQuoteExecuted(ctx, mustPgUUID(id))
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	return SellBackQuoteToDomain(dbQuote)
+}
@@ -20,6 +20,10 @@ const (
 	GiftEventTypeWithdrawRequest  GiftEventType = "withdraw_request"
 	GiftEventTypeWithdrawComplete GiftEventType = "withdraw_complete"
 	GiftEventTypeWithdrawFail     GiftEventType = "withdraw_fail"
+	GiftEventTypeList             GiftEventType = "list"
+	GiftEventTypeUnlist           GiftEventType = "unlist"
+	GiftEventTypeSale             GiftEventType = "sale"
+	GiftEventTypeSellBack         GiftEventType = "sell_back"
 )
 
 func (e *GiftEventType) Scan(src interface{}) error {
@@ -57,6 +61,47 @@ func (ns NullGiftEventType) Value() (driver.Value, error) {
 	return string(ns.GiftEventType), nil
 }
 
+type GiftLockOperation string
+
+const (
+	GiftLockOperationDuel GiftLockOperation = "duel"
+)
+
+func (e *GiftLockOperation) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = GiftLockOperation(s)
+	case string:
+		*e = GiftLockOperation(s)
+	default:
+		return fmt.Errorf("unsupported scan type for GiftLockOperation: %T", src)
+	}
+	return nil
+}
+
+type NullGiftLockOperation struct {
+	GiftLockOperation GiftLockOperation
+	Valid             bool // Valid is true if GiftLockOperation is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullGiftLockOperation) Scan(value interface{}) error {
+	if value == nil {
+		ns.GiftLockOperation, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.GiftLockOperation.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullGiftLockOperation) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.GiftLockOperation), nil
+}
+
 type GiftStatus string
 
 const (
@@ -64,6 +109,7 @@ const (
 	GiftStatusInGame          GiftStatus = "in_game"
 	GiftStatusWithdrawPending GiftStatus = "withdraw_pending"
 	GiftStatusWithdrawn       GiftStatus = "withdrawn"
+	GiftStatusListed          GiftStatus = "listed"
 )
 
 func (e *GiftStatus) Scan(src interface{}) error {
@@ -101,6 +147,281 @@ func (ns NullGiftStatus) Value() (driver.Value, error) {
 	return string(ns.GiftStatus), nil
 }
 
+type ListingStatus string
+
+const (
+	ListingStatusActive    ListingStatus = "active"
+	ListingStatusSold      ListingStatus = "sold"
+	ListingStatusCancelled ListingStatus = "cancelled"
+)
+
+func (e *ListingStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ListingStatus(s)
+	case string:
+		*e = ListingStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ListingStatus: %T", src)
+	}
+	return nil
+}
+
+type NullListingStatus struct {
+	ListingStatus ListingStatus
+	Valid         bool // Valid is true if ListingStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullListingStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.ListingStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ListingStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullListingStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ListingStatus), nil
+}
+
+type ReconciliationDiscrepancyKind string
+
+const (
+	ReconciliationDiscrepancyKindMissingInCustody ReconciliationDiscrepancyKind = "missing_in_custody"
+	ReconciliationDiscrepancyKindMissingInLedger  ReconciliationDiscrepancyKind = "missing_in_ledger"
+)
+
+func (e *ReconciliationDiscrepancyKind) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ReconciliationDiscrepancyKind(s)
+	case string:
+		*e = ReconciliationDiscrepancyKind(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ReconciliationDiscrepancyKind: %T", src)
+	}
+	return nil
+}
+
+type NullReconciliationDiscrepancyKind struct {
+	ReconciliationDiscrepancyKind ReconciliationDiscrepancyKind
+	Valid                         bool // Valid is true if ReconciliationDiscrepancyKind is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullReconciliationDiscrepancyKind) Scan(value interface{}) error {
+	if value == nil {
+		ns.ReconciliationDiscrepancyKind, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ReconciliationDiscrepancyKind.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullReconciliationDiscrepancyKind) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ReconciliationDiscrepancyKind), nil
+}
+
+type WithdrawalCommissionCurrency string
+
+const (
+	WithdrawalCommissionCurrencyTon   WithdrawalCommissionCurrency = "ton"
+	WithdrawalCommissionCurrencyStars WithdrawalCommissionCurrency = "stars"
+)
+
+func (e *WithdrawalCommissionCurrency) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = WithdrawalCommissionCurrency(s)
+	case string:
+		*e = WithdrawalCommissionCurrency(s)
+	default:
+		return fmt.Errorf("unsupported scan type for WithdrawalCommissionCurrency: %T", src)
+	}
+	return nil
+}
+
+type NullWithdrawalCommissionCurrency struct {
+	WithdrawalCommissionCurrency WithdrawalCommissionCurrency
+	Valid                        bool // Valid is true if WithdrawalCommissionCurrency is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullWithdrawalCommissionCurrency) Scan(value interface{}) error {
+	if value == nil {
+		ns.WithdrawalCommissionCurrency, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.WithdrawalCommissionCurrency.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullWithdrawalCommissionCurrency) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.WithdrawalCommissionCurrency), nil
+}
+
+type WithdrawalGiftStatus string
+
+const (
+	WithdrawalGiftStatusPending   WithdrawalGiftStatus = "pending"
+	WithdrawalGiftStatusWithdrawn WithdrawalGiftStatus = "withdrawn"
+	WithdrawalGiftStatusFailed    WithdrawalGiftStatus = "failed"
+	WithdrawalGiftStatusRefunded  WithdrawalGiftStatus = "refunded"
+)
+
+func (e *WithdrawalGiftStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = WithdrawalGiftStatus(s)
+	case string:
+		*e = WithdrawalGiftStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for WithdrawalGiftStatus: %T", src)
+	}
+	return nil
+}
+
+type NullWithdrawalGiftStatus struct {
+	WithdrawalGiftStatus WithdrawalGiftStatus
+	Valid                bool // Valid is true if WithdrawalGiftStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullWithdrawalGiftStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.WithdrawalGiftStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.WithdrawalGiftStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullWithdrawalGiftStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.WithdrawalGiftStatus), nil
+}
+
+type WithdrawalIntentStatus string
+
+const (
+	WithdrawalIntentStatusPending         WithdrawalIntentStatus = "pending"
+	WithdrawalIntentStatusCompleted       WithdrawalIntentStatus = "completed"
+	WithdrawalIntentStatusExpired         WithdrawalIntentStatus = "expired"
+	WithdrawalIntentStatusPaidAfterExpiry WithdrawalIntentStatus = "paid_after_expiry"
+)
+
+func (e *WithdrawalIntentStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = WithdrawalIntentStatus(s)
+	case string:
+		*e = WithdrawalIntentStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for WithdrawalIntentStatus: %T", src)
+	}
+	return nil
+}
+
+type NullWithdrawalIntentStatus struct {
+	WithdrawalIntentStatus WithdrawalIntentStatus
+	Valid                  bool // Valid is true if WithdrawalIntentStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullWithdrawalIntentStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.WithdrawalIntentStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.WithdrawalIntentStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullWithdrawalIntentStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.WithdrawalIntentStatus), nil
+}
+
+type WithdrawalStatus string
+
+const (
+	WithdrawalStatusRequested       WithdrawalStatus = "requested"
+	WithdrawalStatusAwaitingPayment WithdrawalStatus = "awaiting_payment"
+	WithdrawalStatusSentToBot       WithdrawalStatus = "sent_to_bot"
+	WithdrawalStatusCompleted       WithdrawalStatus = "completed"
+	WithdrawalStatusFailed          WithdrawalStatus = "failed"
+	WithdrawalStatusRefunded        WithdrawalStatus = "refunded"
+	WithdrawalStatusRefundRequired  WithdrawalStatus = "refund_required"
+)
+
+func (e *WithdrawalStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = WithdrawalStatus(s)
+	case string:
+		*e = WithdrawalStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for WithdrawalStatus: %T", src)
+	}
+	return nil
+}
+
+type NullWithdrawalStatus struct {
+	WithdrawalStatus WithdrawalStatus
+	Valid            bool // Valid is true if WithdrawalStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullWithdrawalStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.WithdrawalStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.WithdrawalStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullWithdrawalStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.WithdrawalStatus), nil
+}
+
+type FailedSagaCompensation struct {
+	ID             pgtype.UUID
+	Saga           string
+	Step           string
+	GiftID         pgtype.UUID
+	TelegramUserID int64
+	Amount         pgtype.Numeric
+	IdempotencyKey string
+	Error          string
+	ResolvedAt     pgtype.Timestamptz
+	CreatedAt      pgtype.Timestamptz
+}
+
 type Gift struct {
 	ID               pgtype.UUID
 	TelegramGiftID   int64
@@ -118,6 +439,10 @@ type Gift struct {
 	CreatedAt        pgtype.Timestamptz
 	UpdatedAt        pgtype.Timestamptz
 	WithdrawnAt      pgtype.Timestamptz
+	RelatedDuelID    pgtype.UUID
+	LockedByType     NullGiftLockOperation
+	LockedByID       pgtype.UUID
+	LockExpiresAt    pgtype.Timestamptz
 }
 
 type GiftBackdrop struct {
@@ -146,6 +471,25 @@ type GiftEvent struct {
 	OccurredAt     pgtype.Timestamptz
 }
 
+type GiftFavorite struct {
+	TelegramUserID int64
+	GiftID         pgtype.UUID
+	CreatedAt      pgtype.Timestamptz
+}
+
+type GiftListing struct {
+	ID               pgtype.UUID
+	GiftID           pgtype.UUID
+	SellerTelegramID int64
+	BuyerTelegramID  pgtype.Int8
+	Price            pgtype.Numeric
+	Fee              pgtype.Numeric
+	Status           ListingStatus
+	CreatedAt        pgtype.Timestamptz
+	UpdatedAt        pgtype.Timestamptz
+	SoldAt           pgtype.Timestamptz
+}
+
 type GiftModel struct {
 	ID             int32
 	CollectionID   int32
@@ -154,9 +498,109 @@ type GiftModel struct {
 	RarityPerMille int32
 }
 
+type GiftSellBackQuote struct {
+	ID             pgtype.UUID
+	GiftID         pgtype.UUID
+	TelegramUserID int64
+	FloorPrice     pgtype.Numeric
+	Amount         pgtype.Numeric
+	ExpiresAt      pgtype.Timestamptz
+	ExecutedAt     pgtype.Timestamptz
+	CreatedAt      pgtype.Timestamptz
+}
+
 type GiftSymbol struct {
 	ID             int32
 	Name           string
 	ShortName      string
 	RarityPerMille int32
 }
+
+type PortfolioSnapshot struct {
+	TelegramUserID int64
+	SnapshotDate   pgtype.Date
+	OwnedValue     pgtype.Numeric
+	InGameValue    pgtype.Numeric
+	OwnedCount     int32
+	InGameCount    int32
+	CreatedAt      pgtype.Timestamptz
+	UpdatedAt      pgtype.Timestamptz
+}
+
+type ProcessedMessage struct {
+	Handler     string
+	MessageID   string
+	ProcessedAt pgtype.Timestamptz
+}
+
+type ReconciliationDiscrepancy struct {
+	ID             pgtype.UUID
+	RunID          pgtype.UUID
+	Kind           ReconciliationDiscrepancyKind
+	TelegramGiftID int64
+	CollectibleID  int32
+	Title          string
+	Slug           string
+	GiftID         pgtype.UUID
+	GiftStatus     NullGiftStatus
+	CreatedAt      pgtype.Timestamptz
+}
+
+type ReconciliationRun struct {
+	ID               pgtype.UUID
+	CustodyCount     int32
+	LedgerCount      int32
+	DiscrepancyCount int32
+	StartedAt        pgtype.Timestamptz
+	FinishedAt       pgtype.Timestamptz
+}
+
+type StakePreset struct {
+	ID             pgtype.UUID
+	TelegramUserID int64
+	Name           string
+	CreatedAt      pgtype.Timestamptz
+	UpdatedAt      pgtype.Timestamptz
+}
+
+type StakePresetGift struct {
+	PresetID pgtype.UUID
+	GiftID   pgtype.UUID
+	Position int32
+}
+
+type Withdrawal struct {
+	ID                 pgtype.UUID
+	TelegramUserID     int64
+	CommissionCurrency WithdrawalCommissionCurrency
+	CommissionAmount   pgtype.Numeric
+	Status             WithdrawalStatus
+	FailureReason      pgtype.Text
+	IntentID           pgtype.UUID
+	CompletedAt        pgtype.Timestamptz
+	FailedAt           pgtype.Timestamptz
+	CreatedAt          pgtype.Timestamptz
+	UpdatedAt          pgtype.Timestamptz
+}
+
+type WithdrawalGift struct {
+	WithdrawalID     pgtype.UUID
+	GiftID           pgtype.UUID
+	CommissionAmount pgtype.Numeric
+	Status           WithdrawalGiftStatus
+	FailureReason    pgtype.Text
+	UpdatedAt        pgtype.Timestamptz
+}
+
+type WithdrawalIntent struct {
+	ID             pgtype.UUID
+	TelegramUserID int64
+	GiftIds        []pgtype.UUID
+	StarsAmount    int64
+	InvoiceUrl     string
+	Status         WithdrawalIntentStatus
+	ExpiresAt      pgtype.Timestamptz
+	CompletedAt    pgtype.Timestamptz
+	CreatedAt      pgtype.Timestamptz
+	UpdatedAt      pgtype.Timestamptz
+}
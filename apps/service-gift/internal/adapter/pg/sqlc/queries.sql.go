@@ -11,11 +11,27 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const addGiftFavorite = `-- name: AddGiftFavorite :exec
+INSERT INTO gift_favorites (telegram_user_id, gift_id)
+VALUES ($1, $2)
+ON CONFLICT (telegram_user_id, gift_id) DO NOTHING
+`
+
+type AddGiftFavoriteParams struct {
+	TelegramUserID int64
+	GiftID         pgtype.UUID
+}
+
+func (q *Queries) AddGiftFavorite(ctx context.Context, arg AddGiftFavoriteParams) error {
+	_, err := q.db.Exec(ctx, addGiftFavorite, arg.TelegramUserID, arg.GiftID)
+	return err
+}
+
 const cancelGiftWithdrawal = `-- name: CancelGiftWithdrawal :one
 UPDATE gifts 
 SET status = 'owned', updated_at = NOW()
 WHERE id = $1 AND status = 'withdraw_pending'
-RETURNING id, telegram_gift_id, collectible_id, owner_telegram_id, upgrade_message_id, title, slug, price, collection_id, model_id, backdrop_id, symbol_id, status, created_at, updated_at, withdrawn_at
+RETURNING id, telegram_gift_id, collectible_id, owner_telegram_id, upgrade_message_id, title, slug, price, collection_id, model_id, backdrop_id, symbol_id, status, created_at, updated_at, withdrawn_at, related_duel_id, locked_by_type, locked_by_id, lock_expires_at
 `
 
 func (q *Queries) CancelGiftWithdrawal(ctx context.Context, id pgtype.UUID) (Gift, error) {
@@ -38,6 +54,35 @@ func (q *Queries) CancelGiftWithdrawal(ctx context.Context, id pgtype.UUID) (Gif
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.WithdrawnAt,
+		&i.RelatedDuelID,
+		&i.LockedByType,
+		&i.LockedByID,
+		&i.LockExpiresAt,
+	)
+	return i, err
+}
+
+const cancelListing = `-- name: CancelListing :one
+UPDATE gift_listings
+SET status = 'cancelled', updated_at = NOW()
+WHERE id = $1 AND status = 'active'
+RETURNING id, gift_id, seller_telegram_id, buyer_telegram_id, price, fee, status, created_at, updated_at, sold_at
+`
+
+func (q *Queries) CancelListing(ctx context.Context, id pgtype.UUID) (GiftListing, error) {
+	row := q.db.QueryRow(ctx, cancelListing, id)
+	var i GiftListing
+	err := row.Scan(
+		&i.ID,
+		&i.GiftID,
+		&i.SellerTelegramID,
+		&i.BuyerTelegramID,
+		&i.Price,
+		&i.Fee,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.SoldAt,
 	)
 	return i, err
 }
@@ -46,7 +91,7 @@ const completeGiftWithdrawal = `-- name: CompleteGiftWithdrawal :one
 UPDATE gifts 
 SET status = 'withdrawn', withdrawn_at = NOW(), updated_at = NOW()
 WHERE id = $1 AND status = 'withdraw_pending'
-RETURNING id, telegram_gift_id, collectible_id, owner_telegram_id, upgrade_message_id, title, slug, price, collection_id, model_id, backdrop_id, symbol_id, status, created_at, updated_at, withdrawn_at
+RETURNING id, telegram_gift_id, collectible_id, owner_telegram_id, upgrade_message_id, title, slug, price, collection_id, model_id, backdrop_id, symbol_id, status, created_at, updated_at, withdrawn_at, related_duel_id, locked_by_type, locked_by_id, lock_expires_at
 `
 
 func (q *Queries) CompleteGiftWithdrawal(ctx context.Context, id pgtype.UUID) (Gift, error) {
@@ -69,10 +114,50 @@ func (q *Queries) CompleteGiftWithdrawal(ctx context.Context, id pgtype.UUID) (G
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.WithdrawnAt,
+		&i.RelatedDuelID,
+		&i.LockedByType,
+		&i.LockedByID,
+		&i.LockExpiresAt,
 	)
 	return i, err
 }
 
+const confirmGiftLeases = `-- name: ConfirmGiftLeases :many
+UPDATE gifts
+SET lock_expires_at = NULL, updated_at = NOW()
+WHERE id = ANY($1::uuid[])
+  AND status = 'in_game'
+  AND locked_by_type = $2
+  AND locked_by_id = $3
+RETURNING id
+`
+
+type ConfirmGiftLeasesParams struct {
+	GiftIds      []pgtype.UUID
+	LockedByType NullGiftLockOperation
+	LockedByID   pgtype.UUID
+}
+
+func (q *Queries) ConfirmGiftLeases(ctx context.Context, arg ConfirmGiftLeasesParams) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, confirmGiftLeases, arg.GiftIds, arg.LockedByType, arg.LockedByID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []pgtype.UUID
+	for rows.Next() {
+		var id pgtype.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createBackdrop = `-- name: CreateBackdrop :one
 INSERT INTO gift_backdrops (name, short_name, rarity_per_mille, center_color, edge_color, pattern_color, text_color)
 VALUES ($1, $2, $3, $4, $5, $6, $7)
@@ -131,6 +216,43 @@ func (q *Queries) CreateCollection(ctx context.Context, arg CreateCollectionPara
 	return i, err
 }
 
+const createFailedSagaCompensation = `-- name: CreateFailedSagaCompensation :exec
+INSERT INTO failed_saga_compensations (
+    saga,
+    step,
+    gift_id,
+    telegram_user_id,
+    amount,
+    idempotency_key,
+    error
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+)
+`
+
+type CreateFailedSagaCompensationParams struct {
+	Saga           string
+	Step           string
+	GiftID         pgtype.UUID
+	TelegramUserID int64
+	Amount         pgtype.Numeric
+	IdempotencyKey string
+	Error          string
+}
+
+func (q *Queries) CreateFailedSagaCompensation(ctx context.Context, arg CreateFailedSagaCompensationParams) error {
+	_, err := q.db.Exec(ctx, createFailedSagaCompensation,
+		arg.Saga,
+		arg.Step,
+		arg.GiftID,
+		arg.TelegramUserID,
+		arg.Amount,
+		arg.IdempotencyKey,
+		arg.Error,
+	)
+	return err
+}
+
 const createGift = `-- name: CreateGift :one
 INSERT INTO gifts (
     id,
@@ -152,7 +274,7 @@ INSERT INTO gifts (
     $1, $2, $3, $4, $5, $6, $7, $8,
     $9, $10, $11, $12, $13, $14, $15
 )
-RETURNING id, telegram_gift_id, collectible_id, owner_telegram_id, upgrade_message_id, title, slug, price, collection_id, model_id, backdrop_id, symbol_id, status, created_at, updated_at, withdrawn_at
+RETURNING id, telegram_gift_id, collectible_id, owner_telegram_id, upgrade_message_id, title, slug, price, collection_id, model_id, backdrop_id, symbol_id, status, created_at, updated_at, withdrawn_at, related_duel_id, locked_by_type, locked_by_id, lock_expires_at
 `
 
 type CreateGiftParams struct {
@@ -209,6 +331,10 @@ func (q *Queries) CreateGift(ctx context.Context, arg CreateGiftParams) (Gift, e
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.WithdrawnAt,
+		&i.RelatedDuelID,
+		&i.LockedByType,
+		&i.LockedByID,
+		&i.LockExpiresAt,
 	)
 	return i, err
 }
@@ -251,6 +377,41 @@ func (q *Queries) CreateGiftEvent(ctx context.Context, arg CreateGiftEventParams
 	return i, err
 }
 
+const createListing = `-- name: CreateListing :one
+INSERT INTO gift_listings (
+    gift_id,
+    seller_telegram_id,
+    price
+) VALUES (
+    $1, $2, $3
+)
+RETURNING id, gift_id, seller_telegram_id, buyer_telegram_id, price, fee, status, created_at, updated_at, sold_at
+`
+
+type CreateListingParams struct {
+	GiftID           pgtype.UUID
+	SellerTelegramID int64
+	Price            pgtype.Numeric
+}
+
+func (q *Queries) CreateListing(ctx context.Context, arg CreateListingParams) (GiftListing, error) {
+	row := q.db.QueryRow(ctx, createListing, arg.GiftID, arg.SellerTelegramID, arg.Price)
+	var i GiftListing
+	err := row.Scan(
+		&i.ID,
+		&i.GiftID,
+		&i.SellerTelegramID,
+		&i.BuyerTelegramID,
+		&i.Price,
+		&i.Fee,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.SoldAt,
+	)
+	return i, err
+}
+
 const createModel = `-- name: CreateModel :one
 INSERT INTO gift_models (collection_id, name, short_name, rarity_per_mille)
 VALUES ($1, $2, $3, $4)
@@ -282,6 +443,129 @@ func (q *Queries) CreateModel(ctx context.Context, arg CreateModelParams) (GiftM
 	return i, err
 }
 
+const createReconciliationDiscrepancy = `-- name: CreateReconciliationDiscrepancy :exec
+INSERT INTO reconciliation_discrepancies (
+  run_id, kind, telegram_gift_id, collectible_id, title, slug, gift_id, gift_status
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+`
+
+type CreateReconciliationDiscrepancyParams struct {
+	RunID          pgtype.UUID
+	Kind           ReconciliationDiscrepancyKind
+	TelegramGiftID int64
+	CollectibleID  int32
+	Title          string
+	Slug           string
+	GiftID         pgtype.UUID
+	GiftStatus     NullGiftStatus
+}
+
+func (q *Queries) CreateReconciliationDiscrepancy(ctx context.Context, arg CreateReconciliationDiscrepancyParams) error {
+	_, err := q.db.Exec(ctx, createReconciliationDiscrepancy,
+		arg.RunID,
+		arg.Kind,
+		arg.TelegramGiftID,
+		arg.CollectibleID,
+		arg.Title,
+		arg.Slug,
+		arg.GiftID,
+		arg.GiftStatus,
+	)
+	return err
+}
+
+const createReconciliationRun = `-- name: CreateReconciliationRun :one
+INSERT INTO reconciliation_runs (custody_count, ledger_count, discrepancy_count, started_at)
+VALUES ($1, $2, $3, $4)
+RETURNING id, custody_count, ledger_count, discrepancy_count, started_at, finished_at
+`
+
+type CreateReconciliationRunParams struct {
+	CustodyCount     int32
+	LedgerCount      int32
+	DiscrepancyCount int32
+	StartedAt        pgtype.Timestamptz
+}
+
+func (q *Queries) CreateReconciliationRun(ctx context.Context, arg CreateReconciliationRunParams) (ReconciliationRun, error) {
+	row := q.db.QueryRow(ctx, createReconciliationRun,
+		arg.CustodyCount,
+		arg.LedgerCount,
+		arg.DiscrepancyCount,
+		arg.StartedAt,
+	)
+	var i ReconciliationRun
+	err := row.Scan(
+		&i.ID,
+		&i.CustodyCount,
+		&i.LedgerCount,
+		&i.DiscrepancyCount,
+		&i.StartedAt,
+		&i.FinishedAt,
+	)
+	return i, err
+}
+
+const createSellBackQuote = `-- name: CreateSellBackQuote :one
+INSERT INTO gift_sell_back_quotes (
+    gift_id,
+    telegram_user_id,
+    floor_price,
+    amount,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+RETURNING id, gift_id, telegram_user_id, floor_price, amount, expires_at, executed_at, created_at
+`
+
+type CreateSellBackQuoteParams struct {
+	GiftID         pgtype.UUID
+	TelegramUserID int64
+	FloorPrice     pgtype.Numeric
+	Amount         pgtype.Numeric
+	ExpiresAt      pgtype.Timestamptz
+}
+
+func (q *Queries) CreateSellBackQuote(ctx context.Context, arg CreateSellBackQuoteParams) (GiftSellBackQuote, error) {
+	row := q.db.QueryRow(ctx, createSellBackQuote,
+		arg.GiftID,
+		arg.TelegramUserID,
+		arg.FloorPrice,
+		arg.Amount,
+		arg.ExpiresAt,
+	)
+	var i GiftSellBackQuote
+	err := row.Scan(
+		&i.ID,
+		&i.GiftID,
+		&i.TelegramUserID,
+		&i.FloorPrice,
+		&i.Amount,
+		&i.ExpiresAt,
+		&i.ExecutedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createStakePresetGift = `-- name: CreateStakePresetGift :exec
+INSERT INTO stake_preset_gifts (preset_id, gift_id, position)
+VALUES ($1, $2, $3)
+`
+
+type CreateStakePresetGiftParams struct {
+	PresetID pgtype.UUID
+	GiftID   pgtype.UUID
+	Position int32
+}
+
+func (q *Queries) CreateStakePresetGift(ctx context.Context, arg CreateStakePresetGiftParams) error {
+	_, err := q.db.Exec(ctx, createStakePresetGift, arg.PresetID, arg.GiftID, arg.Position)
+	return err
+}
+
 const createSymbol = `-- name: CreateSymbol :one
 INSERT INTO gift_symbols (name, short_name, rarity_per_mille)
 VALUES ($1, $2, $3)
@@ -306,6 +590,160 @@ func (q *Queries) CreateSymbol(ctx context.Context, arg CreateSymbolParams) (Gif
 	return i, err
 }
 
+const createWithdrawal = `-- name: CreateWithdrawal :one
+INSERT INTO withdrawals (
+    telegram_user_id,
+    commission_currency,
+    commission_amount,
+    status,
+    intent_id
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+RETURNING id, telegram_user_id, commission_currency, commission_amount, status, failure_reason, intent_id, completed_at, failed_at, created_at, updated_at
+`
+
+type CreateWithdrawalParams struct {
+	TelegramUserID     int64
+	CommissionCurrency WithdrawalCommissionCurrency
+	CommissionAmount   pgtype.Numeric
+	Status             WithdrawalStatus
+	IntentID           pgtype.UUID
+}
+
+func (q *Queries) CreateWithdrawal(ctx context.Context, arg CreateWithdrawalParams) (Withdrawal, error) {
+	row := q.db.QueryRow(ctx, createWithdrawal,
+		arg.TelegramUserID,
+		arg.CommissionCurrency,
+		arg.CommissionAmount,
+		arg.Status,
+		arg.IntentID,
+	)
+	var i Withdrawal
+	err := row.Scan(
+		&i.ID,
+		&i.TelegramUserID,
+		&i.CommissionCurrency,
+		&i.CommissionAmount,
+		&i.Status,
+		&i.FailureReason,
+		&i.IntentID,
+		&i.CompletedAt,
+		&i.FailedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createWithdrawalGift = `-- name: CreateWithdrawalGift :one
+INSERT INTO withdrawal_gifts (
+    withdrawal_id,
+    gift_id,
+    commission_amount
+) VALUES (
+    $1, $2, $3
+)
+RETURNING withdrawal_id, gift_id, commission_amount, status, failure_reason, updated_at
+`
+
+type CreateWithdrawalGiftParams struct {
+	WithdrawalID     pgtype.UUID
+	GiftID           pgtype.UUID
+	CommissionAmount pgtype.Numeric
+}
+
+func (q *Queries) CreateWithdrawalGift(ctx context.Context, arg CreateWithdrawalGiftParams) (WithdrawalGift, error) {
+	row := q.db.QueryRow(ctx, createWithdrawalGift,
+		arg.WithdrawalID,
+		arg.GiftID,
+		arg.CommissionAmount,
+	)
+	var i WithdrawalGift
+	err := row.Scan(
+		&i.WithdrawalID,
+		&i.GiftID,
+		&i.CommissionAmount,
+		&i.Status,
+		&i.FailureReason,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createWithdrawalIntent = `-- name: CreateWithdrawalIntent :one
+INSERT INTO withdrawal_intents (
+    telegram_user_id,
+    gift_ids,
+    stars_amount,
+    invoice_url,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+RETURNING id, telegram_user_id, gift_ids, stars_amount, invoice_url, status, expires_at, completed_at, created_at, updated_at
+`
+
+type CreateWithdrawalIntentParams struct {
+	TelegramUserID int64
+	GiftIds        []pgtype.UUID
+	StarsAmount    int64
+	InvoiceUrl     string
+	ExpiresAt      pgtype.Timestamptz
+}
+
+func (q *Queries) CreateWithdrawalIntent(ctx context.Context, arg CreateWithdrawalIntentParams) (WithdrawalIntent, error) {
+	row := q.db.QueryRow(ctx, createWithdrawalIntent,
+		arg.TelegramUserID,
+		arg.GiftIds,
+		arg.StarsAmount,
+		arg.InvoiceUrl,
+		arg.ExpiresAt,
+	)
+	var i WithdrawalIntent
+	err := row.Scan(
+		&i.ID,
+		&i.TelegramUserID,
+		&i.GiftIds,
+		&i.StarsAmount,
+		&i.InvoiceUrl,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.CompletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const deleteStakePreset = `-- name: DeleteStakePreset :execrows
+DELETE FROM stake_presets
+WHERE id = $1 AND telegram_user_id = $2
+`
+
+type DeleteStakePresetParams struct {
+	ID             pgtype.UUID
+	TelegramUserID int64
+}
+
+func (q *Queries) DeleteStakePreset(ctx context.Context, arg DeleteStakePresetParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteStakePreset, arg.ID, arg.TelegramUserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteStakePresetGifts = `-- name: DeleteStakePresetGifts :exec
+DELETE FROM stake_preset_gifts
+WHERE preset_id = $1
+`
+
+func (q *Queries) DeleteStakePresetGifts(ctx context.Context, presetID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteStakePresetGifts, presetID)
+	return err
+}
+
 const findBackdropByName = `-- name: FindBackdropByName :one
 SELECT id, name, short_name, rarity_per_mille, center_color, edge_color, pattern_color, text_color FROM gift_backdrops
 WHERE name = $1
@@ -374,51 +812,61 @@ func (q *Queries) FindSymbolByName(ctx context.Context, name string) (GiftSymbol
 	return i, err
 }
 
-const getGiftBackdrop = `-- name: GetGiftBackdrop :one
-SELECT id, name, short_name, rarity_per_mille, center_color, edge_color, pattern_color, text_color FROM gift_backdrops
-WHERE id = $1
+const getActiveListings = `-- name: GetActiveListings :many
+SELECT l.id, l.gift_id, l.seller_telegram_id, l.buyer_telegram_id, l.price, l.fee, l.status, l.created_at, l.updated_at, l.sold_at
+FROM gift_listings l
+JOIN gifts g ON g.id = l.gift_id
+JOIN gift_collections c ON c.id = g.collection_id
+JOIN gift_models m ON m.id = g.model_id
+JOIN gift_backdrops b ON b.id = g.backdrop_id
+WHERE l.status = 'active'
+  AND ($1::text IS NULL OR c.name = $1::text)
+  AND ($2::text IS NULL OR m.name = $2::text)
+  AND ($3::text IS NULL OR b.name = $3::text)
+  AND ($4::numeric IS NULL OR l.price >= $4::numeric)
+  AND ($5::numeric IS NULL OR l.price <= $5::numeric)
+ORDER BY l.created_at DESC
+LIMIT $6 OFFSET $7
 `
 
-func (q *Queries) GetGiftBackdrop(ctx context.Context, id int32) (GiftBackdrop, error) {
-	row := q.db.QueryRow(ctx, getGiftBackdrop, id)
-	var i GiftBackdrop
-	err := row.Scan(
-		&i.ID,
-		&i.Name,
-		&i.ShortName,
-		&i.RarityPerMille,
-		&i.CenterColor,
-		&i.EdgeColor,
-		&i.PatternColor,
-		&i.TextColor,
-	)
-	return i, err
+type GetActiveListingsParams struct {
+	Collection pgtype.Text
+	Model      pgtype.Text
+	Backdrop   pgtype.Text
+	MinPrice   pgtype.Numeric
+	MaxPrice   pgtype.Numeric
+	Limit      int32
+	Offset     int32
 }
 
-const getGiftBackdropsByIDs = `-- name: GetGiftBackdropsByIDs :many
-SELECT id, name, short_name, rarity_per_mille, center_color, edge_color, pattern_color, text_color
-FROM gift_backdrops
-WHERE id = ANY($1::int[])
-`
-
-func (q *Queries) GetGiftBackdropsByIDs(ctx context.Context, dollar_1 []int32) ([]GiftBackdrop, error) {
-	rows, err := q.db.Query(ctx, getGiftBackdropsByIDs, dollar_1)
+func (q *Queries) GetActiveListings(ctx context.Context, arg GetActiveListingsParams) ([]GiftListing, error) {
+	rows, err := q.db.Query(ctx, getActiveListings,
+		arg.Collection,
+		arg.Model,
+		arg.Backdrop,
+		arg.MinPrice,
+		arg.MaxPrice,
+		arg.Limit,
+		arg.Offset,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GiftBackdrop
+	var items []GiftListing
 	for rows.Next() {
-		var i GiftBackdrop
+		var i GiftListing
 		if err := rows.Scan(
 			&i.ID,
-			&i.Name,
-			&i.ShortName,
-			&i.RarityPerMille,
-			&i.CenterColor,
-			&i.EdgeColor,
-			&i.PatternColor,
-			&i.TextColor,
+			&i.GiftID,
+			&i.SellerTelegramID,
+			&i.BuyerTelegramID,
+			&i.Price,
+			&i.Fee,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.SoldAt,
 		); err != nil {
 			return nil, err
 		}
@@ -430,64 +878,116 @@ func (q *Queries) GetGiftBackdropsByIDs(ctx context.Context, dollar_1 []int32) (
 	return items, nil
 }
 
-const getGiftByID = `-- name: GetGiftByID :one
-SELECT id, telegram_gift_id, collectible_id, owner_telegram_id, upgrade_message_id, title, slug, price, collection_id, model_id, backdrop_id, symbol_id, status, created_at, updated_at, withdrawn_at
-FROM gifts
-WHERE id = $1
+const getActiveListingsCount = `-- name: GetActiveListingsCount :one
+SELECT COUNT(*)
+FROM gift_listings l
+JOIN gifts g ON g.id = l.gift_id
+JOIN gift_collections c ON c.id = g.collection_id
+JOIN gift_models m ON m.id = g.model_id
+JOIN gift_backdrops b ON b.id = g.backdrop_id
+WHERE l.status = 'active'
+  AND ($1::text IS NULL OR c.name = $1::text)
+  AND ($2::text IS NULL OR m.name = $2::text)
+  AND ($3::text IS NULL OR b.name = $3::text)
+  AND ($4::numeric IS NULL OR l.price >= $4::numeric)
+  AND ($5::numeric IS NULL OR l.price <= $5::numeric)
 `
 
-func (q *Queries) GetGiftByID(ctx context.Context, id pgtype.UUID) (Gift, error) {
-	row := q.db.QueryRow(ctx, getGiftByID, id)
-	var i Gift
+type GetActiveListingsCountParams struct {
+	Collection pgtype.Text
+	Model      pgtype.Text
+	Backdrop   pgtype.Text
+	MinPrice   pgtype.Numeric
+	MaxPrice   pgtype.Numeric
+}
+
+func (q *Queries) GetActiveListingsCount(ctx context.Context, arg GetActiveListingsCountParams) (int64, error) {
+	row := q.db.QueryRow(ctx, getActiveListingsCount,
+		arg.Collection,
+		arg.Model,
+		arg.Backdrop,
+		arg.MinPrice,
+		arg.MaxPrice,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const getActiveWithdrawalByGiftForUpdate = `-- name: GetActiveWithdrawalByGiftForUpdate :one
+SELECT w.id, w.telegram_user_id, w.commission_currency, w.commission_amount, w.status, w.failure_reason, w.intent_id, w.completed_at, w.failed_at, w.created_at, w.updated_at FROM withdrawals w
+JOIN withdrawal_gifts wg ON wg.withdrawal_id = w.id
+WHERE wg.gift_id = $1 AND wg.status = 'pending'
+ORDER BY w.created_at DESC
+LIMIT 1
+FOR UPDATE OF w
+`
+
+func (q *Queries) GetActiveWithdrawalByGiftForUpdate(ctx context.Context, giftID pgtype.UUID) (Withdrawal, error) {
+	row := q.db.QueryRow(ctx, getActiveWithdrawalByGiftForUpdate, giftID)
+	var i Withdrawal
 	err := row.Scan(
 		&i.ID,
-		&i.TelegramGiftID,
-		&i.CollectibleID,
-		&i.OwnerTelegramID,
-		&i.UpgradeMessageID,
-		&i.Title,
-		&i.Slug,
-		&i.Price,
-		&i.CollectionID,
-		&i.ModelID,
-		&i.BackdropID,
-		&i.SymbolID,
+		&i.TelegramUserID,
+		&i.CommissionCurrency,
+		&i.CommissionAmount,
 		&i.Status,
+		&i.FailureReason,
+		&i.IntentID,
+		&i.CompletedAt,
+		&i.FailedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.WithdrawnAt,
 	)
 	return i, err
 }
 
-const getGiftCollection = `-- name: GetGiftCollection :one
-SELECT id, name, short_name FROM gift_collections
-WHERE id = $1
+const getCatalogBackdrops = `-- name: GetCatalogBackdrops :many
+SELECT b.id, b.name, b.short_name, b.rarity_per_mille,
+       b.center_color, b.edge_color, b.pattern_color, b.text_color, f.floor_price
+FROM gift_backdrops b
+LEFT JOIN (
+  SELECT g.backdrop_id, MIN(l.price)::numeric AS floor_price
+  FROM gift_listings l
+  JOIN gifts g ON g.id = l.gift_id
+  WHERE l.status = 'active'
+  GROUP BY g.backdrop_id
+) f ON f.backdrop_id = b.id
+ORDER BY b.rarity_per_mille, b.name
 `
 
-func (q *Queries) GetGiftCollection(ctx context.Context, id int32) (GiftCollection, error) {
-	row := q.db.QueryRow(ctx, getGiftCollection, id)
-	var i GiftCollection
-	err := row.Scan(&i.ID, &i.Name, &i.ShortName)
-	return i, err
+type GetCatalogBackdropsRow struct {
+	ID             int32
+	Name           string
+	ShortName      string
+	RarityPerMille int32
+	CenterColor    pgtype.Text
+	EdgeColor      pgtype.Text
+	PatternColor   pgtype.Text
+	TextColor      pgtype.Text
+	FloorPrice     pgtype.Numeric
 }
 
-const getGiftCollectionsByIDs = `-- name: GetGiftCollectionsByIDs :many
-SELECT id, name, short_name
-FROM gift_collections
-WHERE id = ANY($1::int[])
-`
-
-func (q *Queries) GetGiftCollectionsByIDs(ctx context.Context, dollar_1 []int32) ([]GiftCollection, error) {
-	rows, err := q.db.Query(ctx, getGiftCollectionsByIDs, dollar_1)
+func (q *Queries) GetCatalogBackdrops(ctx context.Context) ([]GetCatalogBackdropsRow, error) {
+	rows, err := q.db.Query(ctx, getCatalogBackdrops)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GiftCollection
+	var items []GetCatalogBackdropsRow
 	for rows.Next() {
-		var i GiftCollection
-		if err := rows.Scan(&i.ID, &i.Name, &i.ShortName); err != nil {
+		var i GetCatalogBackdropsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.ShortName,
+			&i.RarityPerMille,
+			&i.CenterColor,
+			&i.EdgeColor,
+			&i.PatternColor,
+			&i.TextColor,
+			&i.FloorPrice,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -498,36 +998,40 @@ func (q *Queries) GetGiftCollectionsByIDs(ctx context.Context, dollar_1 []int32)
 	return items, nil
 }
 
-const getGiftEvents = `-- name: GetGiftEvents :many
-SELECT id, gift_id, event_type, telegram_user_id, related_game_id, occurred_at FROM gift_events
-WHERE gift_id = $1
-ORDER BY occurred_at DESC
-LIMIT $2
-OFFSET $3
+const getCatalogCollections = `-- name: GetCatalogCollections :many
+SELECT c.id, c.name, c.short_name, f.floor_price
+FROM gift_collections c
+LEFT JOIN (
+  SELECT g.collection_id, MIN(l.price)::numeric AS floor_price
+  FROM gift_listings l
+  JOIN gifts g ON g.id = l.gift_id
+  WHERE l.status = 'active'
+  GROUP BY g.collection_id
+) f ON f.collection_id = c.id
+ORDER BY c.name
 `
 
-type GetGiftEventsParams struct {
-	GiftID pgtype.UUID
-	Limit  int32
-	Offset int32
+type GetCatalogCollectionsRow struct {
+	ID         int32
+	Name       string
+	ShortName  string
+	FloorPrice pgtype.Numeric
 }
 
-func (q *Queries) GetGiftEvents(ctx context.Context, arg GetGiftEventsParams) ([]GiftEvent, error) {
-	rows, err := q.db.Query(ctx, getGiftEvents, arg.GiftID, arg.Limit, arg.Offset)
+func (q *Queries) GetCatalogCollections(ctx context.Context) ([]GetCatalogCollectionsRow, error) {
+	rows, err := q.db.Query(ctx, getCatalogCollections)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GiftEvent
+	var items []GetCatalogCollectionsRow
 	for rows.Next() {
-		var i GiftEvent
+		var i GetCatalogCollectionsRow
 		if err := rows.Scan(
 			&i.ID,
-			&i.GiftID,
-			&i.EventType,
-			&i.TelegramUserID,
-			&i.RelatedGameID,
-			&i.OccurredAt,
+			&i.Name,
+			&i.ShortName,
+			&i.FloorPrice,
 		); err != nil {
 			return nil, err
 		}
@@ -539,45 +1043,96 @@ func (q *Queries) GetGiftEvents(ctx context.Context, arg GetGiftEventsParams) ([
 	return items, nil
 }
 
-const getGiftModel = `-- name: GetGiftModel :one
-SELECT id, collection_id, name, short_name, rarity_per_mille FROM gift_models
-WHERE id = $1
+const getCatalogModels = `-- name: GetCatalogModels :many
+SELECT m.id, m.collection_id, m.name, m.short_name, m.rarity_per_mille,
+       c.name AS collection_name, f.floor_price
+FROM gift_models m
+JOIN gift_collections c ON c.id = m.collection_id
+LEFT JOIN (
+  SELECT g.model_id, MIN(l.price)::numeric AS floor_price
+  FROM gift_listings l
+  JOIN gifts g ON g.id = l.gift_id
+  WHERE l.status = 'active'
+  GROUP BY g.model_id
+) f ON f.model_id = m.id
+WHERE $1::text IS NULL OR c.name = $1::text
+ORDER BY c.name, m.rarity_per_mille, m.name
 `
 
-func (q *Queries) GetGiftModel(ctx context.Context, id int32) (GiftModel, error) {
-	row := q.db.QueryRow(ctx, getGiftModel, id)
-	var i GiftModel
-	err := row.Scan(
-		&i.ID,
-		&i.CollectionID,
-		&i.Name,
-		&i.ShortName,
-		&i.RarityPerMille,
-	)
-	return i, err
+type GetCatalogModelsRow struct {
+	ID             int32
+	CollectionID   int32
+	Name           string
+	ShortName      string
+	RarityPerMille int32
+	CollectionName string
+	FloorPrice     pgtype.Numeric
 }
 
-const getGiftModelsByIDs = `-- name: GetGiftModelsByIDs :many
-SELECT id, collection_id, name, short_name, rarity_per_mille
-FROM gift_models
-WHERE id = ANY($1::int[])
+func (q *Queries) GetCatalogModels(ctx context.Context, collection pgtype.Text) ([]GetCatalogModelsRow, error) {
+	rows, err := q.db.Query(ctx, getCatalogModels, collection)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetCatalogModelsRow
+	for rows.Next() {
+		var i GetCatalogModelsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CollectionID,
+			&i.Name,
+			&i.ShortName,
+			&i.RarityPerMille,
+			&i.CollectionName,
+			&i.FloorPrice,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCatalogSymbols = `-- name: GetCatalogSymbols :many
+SELECT s.id, s.name, s.short_name, s.rarity_per_mille, f.floor_price
+FROM gift_symbols s
+LEFT JOIN (
+  SELECT g.symbol_id, MIN(l.price)::numeric AS floor_price
+  FROM gift_listings l
+  JOIN gifts g ON g.id = l.gift_id
+  WHERE l.status = 'active'
+  GROUP BY g.symbol_id
+) f ON f.symbol_id = s.id
+ORDER BY s.rarity_per_mille, s.name
 `
 
-func (q *Queries) GetGiftModelsByIDs(ctx context.Context, dollar_1 []int32) ([]GiftModel, error) {
-	rows, err := q.db.Query(ctx, getGiftModelsByIDs, dollar_1)
+type GetCatalogSymbolsRow struct {
+	ID             int32
+	Name           string
+	ShortName      string
+	RarityPerMille int32
+	FloorPrice     pgtype.Numeric
+}
+
+func (q *Queries) GetCatalogSymbols(ctx context.Context) ([]GetCatalogSymbolsRow, error) {
+	rows, err := q.db.Query(ctx, getCatalogSymbols)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GiftModel
+	var items []GetCatalogSymbolsRow
 	for rows.Next() {
-		var i GiftModel
+		var i GetCatalogSymbolsRow
 		if err := rows.Scan(
 			&i.ID,
-			&i.CollectionID,
 			&i.Name,
 			&i.ShortName,
 			&i.RarityPerMille,
+			&i.FloorPrice,
 		); err != nil {
 			return nil, err
 		}
@@ -589,43 +1144,150 @@ func (q *Queries) GetGiftModelsByIDs(ctx context.Context, dollar_1 []int32) ([]G
 	return items, nil
 }
 
-const getGiftSymbol = `-- name: GetGiftSymbol :one
-SELECT id, name, short_name, rarity_per_mille FROM gift_symbols
+const getExpiredGiftLeases = `-- name: GetExpiredGiftLeases :many
+SELECT id, telegram_gift_id, collectible_id, owner_telegram_id, upgrade_message_id, title, slug, price, collection_id, model_id, backdrop_id, symbol_id, status, created_at, updated_at, withdrawn_at, related_duel_id, locked_by_type, locked_by_id, lock_expires_at
+FROM gifts
+WHERE status = 'in_game' AND lock_expires_at <= $1
+ORDER BY lock_expires_at
+LIMIT $2
+`
+
+type GetExpiredGiftLeasesParams struct {
+	Now   pgtype.Timestamptz
+	Limit int32
+}
+
+func (q *Queries) GetExpiredGiftLeases(ctx context.Context, arg GetExpiredGiftLeasesParams) ([]Gift, error) {
+	rows, err := q.db.Query(ctx, getExpiredGiftLeases, arg.Now, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Gift
+	for rows.Next() {
+		var i Gift
+		if err := rows.Scan(
+			&i.ID,
+			&i.TelegramGiftID,
+			&i.CollectibleID,
+			&i.OwnerTelegramID,
+			&i.UpgradeMessageID,
+			&i.Title,
+			&i.Slug,
+			&i.Price,
+			&i.CollectionID,
+			&i.ModelID,
+			&i.BackdropID,
+			&i.SymbolID,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.WithdrawnAt,
+			&i.RelatedDuelID,
+			&i.LockedByType,
+			&i.LockedByID,
+			&i.LockExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getExpiredWithdrawalIntentsForUpdate = `-- name: GetExpiredWithdrawalIntentsForUpdate :many
+SELECT id, telegram_user_id, gift_ids, stars_amount, invoice_url, status, expires_at, completed_at, created_at, updated_at FROM withdrawal_intents
+WHERE status = 'pending'
+  AND expires_at <= $1
+ORDER BY expires_at
+LIMIT $2
+FOR UPDATE SKIP LOCKED
+`
+
+type GetExpiredWithdrawalIntentsForUpdateParams struct {
+	Now   pgtype.Timestamptz
+	Limit int32
+}
+
+func (q *Queries) GetExpiredWithdrawalIntentsForUpdate(ctx context.Context, arg GetExpiredWithdrawalIntentsForUpdateParams) ([]WithdrawalIntent, error) {
+	rows, err := q.db.Query(ctx, getExpiredWithdrawalIntentsForUpdate, arg.Now, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WithdrawalIntent
+	for rows.Next() {
+		var i WithdrawalIntent
+		if err := rows.Scan(
+			&i.ID,
+			&i.TelegramUserID,
+			&i.GiftIds,
+			&i.StarsAmount,
+			&i.InvoiceUrl,
+			&i.Status,
+			&i.ExpiresAt,
+			&i.CompletedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGiftBackdrop = `-- name: GetGiftBackdrop :one
+SELECT id, name, short_name, rarity_per_mille, center_color, edge_color, pattern_color, text_color FROM gift_backdrops
 WHERE id = $1
 `
 
-func (q *Queries) GetGiftSymbol(ctx context.Context, id int32) (GiftSymbol, error) {
-	row := q.db.QueryRow(ctx, getGiftSymbol, id)
-	var i GiftSymbol
+func (q *Queries) GetGiftBackdrop(ctx context.Context, id int32) (GiftBackdrop, error) {
+	row := q.db.QueryRow(ctx, getGiftBackdrop, id)
+	var i GiftBackdrop
 	err := row.Scan(
 		&i.ID,
 		&i.Name,
 		&i.ShortName,
 		&i.RarityPerMille,
+		&i.CenterColor,
+		&i.EdgeColor,
+		&i.PatternColor,
+		&i.TextColor,
 	)
 	return i, err
 }
 
-const getGiftSymbolsByIDs = `-- name: GetGiftSymbolsByIDs :many
-SELECT id, name, short_name, rarity_per_mille
-FROM gift_symbols
+const getGiftBackdropsByIDs = `-- name: GetGiftBackdropsByIDs :many
+SELECT id, name, short_name, rarity_per_mille, center_color, edge_color, pattern_color, text_color
+FROM gift_backdrops
 WHERE id = ANY($1::int[])
 `
 
-func (q *Queries) GetGiftSymbolsByIDs(ctx context.Context, dollar_1 []int32) ([]GiftSymbol, error) {
-	rows, err := q.db.Query(ctx, getGiftSymbolsByIDs, dollar_1)
+func (q *Queries) GetGiftBackdropsByIDs(ctx context.Context, dollar_1 []int32) ([]GiftBackdrop, error) {
+	rows, err := q.db.Query(ctx, getGiftBackdropsByIDs, dollar_1)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GiftSymbol
+	var items []GiftBackdrop
 	for rows.Next() {
-		var i GiftSymbol
+		var i GiftBackdrop
 		if err := rows.Scan(
 			&i.ID,
 			&i.Name,
 			&i.ShortName,
 			&i.RarityPerMille,
+			&i.CenterColor,
+			&i.EdgeColor,
+			&i.PatternColor,
+			&i.TextColor,
 		); err != nil {
 			return nil, err
 		}
@@ -637,15 +1299,1396 @@ func (q *Queries) GetGiftSymbolsByIDs(ctx context.Context, dollar_1 []int32) ([]
 	return items, nil
 }
 
-const getGiftsByIDs = `-- name: GetGiftsByIDs :many
-SELECT id, telegram_gift_id, collectible_id, owner_telegram_id, upgrade_message_id, title, slug, price, collection_id, model_id, backdrop_id, symbol_id, status, created_at, updated_at, withdrawn_at
-FROM gifts
+const getGiftByID = `-- name: GetGiftByID :one
+SELECT id, telegram_gift_id, collectible_id, owner_telegram_id, upgrade_message_id, title, slug, price, collection_id, model_id, backdrop_id, symbol_id, status, created_at, updated_at, withdrawn_at, related_duel_id, locked_by_type, locked_by_id, lock_expires_at
+FROM gifts
+WHERE id = $1
+`
+
+func (q *Queries) GetGiftByID(ctx context.Context, id pgtype.UUID) (Gift, error) {
+	row := q.db.QueryRow(ctx, getGiftByID, id)
+	var i Gift
+	err := row.Scan(
+		&i.ID,
+		&i.TelegramGiftID,
+		&i.CollectibleID,
+		&i.OwnerTelegramID,
+		&i.UpgradeMessageID,
+		&i.Title,
+		&i.Slug,
+		&i.Price,
+		&i.CollectionID,
+		&i.ModelID,
+		&i.BackdropID,
+		&i.SymbolID,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.WithdrawnAt,
+		&i.RelatedDuelID,
+		&i.LockedByType,
+		&i.LockedByID,
+		&i.LockExpiresAt,
+	)
+	return i, err
+}
+
+const getGiftCollection = `-- name: GetGiftCollection :one
+SELECT id, name, short_name FROM gift_collections
+WHERE id = $1
+`
+
+func (q *Queries) GetGiftCollection(ctx context.Context, id int32) (GiftCollection, error) {
+	row := q.db.QueryRow(ctx, getGiftCollection, id)
+	var i GiftCollection
+	err := row.Scan(&i.ID, &i.Name, &i.ShortName)
+	return i, err
+}
+
+const getGiftCollectionsByIDs = `-- name: GetGiftCollectionsByIDs :many
+SELECT id, name, short_name
+FROM gift_collections
+WHERE id = ANY($1::int[])
+`
+
+func (q *Queries) GetGiftCollectionsByIDs(ctx context.Context, dollar_1 []int32) ([]GiftCollection, error) {
+	rows, err := q.db.Query(ctx, getGiftCollectionsByIDs, dollar_1)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GiftCollection
+	for rows.Next() {
+		var i GiftCollection
+		if err := rows.Scan(&i.ID, &i.Name, &i.ShortName); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGiftEvents = `-- name: GetGiftEvents :many
+SELECT id, gift_id, event_type, telegram_user_id, related_game_id, occurred_at FROM gift_events
+WHERE gift_id = $1
+ORDER BY occurred_at DESC
+LIMIT $2
+OFFSET $3
+`
+
+type GetGiftEventsParams struct {
+	GiftID pgtype.UUID
+	Limit  int32
+	Offset int32
+}
+
+func (q *Queries) GetGiftEvents(ctx context.Context, arg GetGiftEventsParams) ([]GiftEvent, error) {
+	rows, err := q.db.Query(ctx, getGiftEvents, arg.GiftID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GiftEvent
+	for rows.Next() {
+		var i GiftEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.GiftID,
+			&i.EventType,
+			&i.TelegramUserID,
+			&i.RelatedGameID,
+			&i.OccurredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGiftHistory = `-- name: GetGiftHistory :many
+SELECT id, gift_id, event_type, telegram_user_id, related_game_id, occurred_at FROM gift_events
+WHERE gift_id = $1
+  AND (cardinality($2::text[]) = 0 OR event_type::text = ANY($2::text[]))
+ORDER BY occurred_at DESC
+LIMIT $3 OFFSET $4
+`
+
+type GetGiftHistoryParams struct {
+	GiftID     pgtype.UUID
+	EventTypes []string
+	Limit      int32
+	Offset     int32
+}
+
+func (q *Queries) GetGiftHistory(ctx context.Context, arg GetGiftHistoryParams) ([]GiftEvent, error) {
+	rows, err := q.db.Query(ctx, getGiftHistory,
+		arg.GiftID,
+		arg.EventTypes,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GiftEvent
+	for rows.Next() {
+		var i GiftEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.GiftID,
+			&i.EventType,
+			&i.TelegramUserID,
+			&i.RelatedGameID,
+			&i.OccurredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGiftHistoryCount = `-- name: GetGiftHistoryCount :one
+SELECT COUNT(*) FROM gift_events
+WHERE gift_id = $1
+  AND (cardinality($2::text[]) = 0 OR event_type::text = ANY($2::text[]))
+`
+
+type GetGiftHistoryCountParams struct {
+	GiftID     pgtype.UUID
+	EventTypes []string
+}
+
+func (q *Queries) GetGiftHistoryCount(ctx context.Context, arg GetGiftHistoryCountParams) (int64, error) {
+	row := q.db.QueryRow(ctx, getGiftHistoryCount, arg.GiftID, arg.EventTypes)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const getGiftModel = `-- name: GetGiftModel :one
+SELECT id, collection_id, name, short_name, rarity_per_mille FROM gift_models
+WHERE id = $1
+`
+
+func (q *Queries) GetGiftModel(ctx context.Context, id int32) (GiftModel, error) {
+	row := q.db.QueryRow(ctx, getGiftModel, id)
+	var i GiftModel
+	err := row.Scan(
+		&i.ID,
+		&i.CollectionID,
+		&i.Name,
+		&i.ShortName,
+		&i.RarityPerMille,
+	)
+	return i, err
+}
+
+const getGiftModelsByIDs = `-- name: GetGiftModelsByIDs :many
+SELECT id, collection_id, name, short_name, rarity_per_mille
+FROM gift_models
+WHERE id = ANY($1::int[])
+`
+
+func (q *Queries) GetGiftModelsByIDs(ctx context.Context, dollar_1 []int32) ([]GiftModel, error) {
+	rows, err := q.db.Query(ctx, getGiftModelsByIDs, dollar_1)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GiftModel
+	for rows.Next() {
+		var i GiftModel
+		if err := rows.Scan(
+			&i.ID,
+			&i.CollectionID,
+			&i.Name,
+			&i.ShortName,
+			&i.RarityPerMille,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGiftSymbol = `-- name: GetGiftSymbol :one
+SELECT id, name, short_name, rarity_per_mille FROM gift_symbols
+WHERE id = $1
+`
+
+func (q *Queries) GetGiftSymbol(ctx context.Context, id int32) (GiftSymbol, error) {
+	row := q.db.QueryRow(ctx, getGiftSymbol, id)
+	var i GiftSymbol
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.ShortName,
+		&i.RarityPerMille,
+	)
+	return i, err
+}
+
+const getGiftSymbolsByIDs = `-- name: GetGiftSymbolsByIDs :many
+SELECT id, name, short_name, rarity_per_mille
+FROM gift_symbols
+WHERE id = ANY($1::int[])
+`
+
+func (q *Queries) GetGiftSymbolsByIDs(ctx context.Context, dollar_1 []int32) ([]GiftSymbol, error) {
+	rows, err := q.db.Query(ctx, getGiftSymbolsByIDs, dollar_1)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GiftSymbol
+	for rows.Next() {
+		var i GiftSymbol
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.ShortName,
+			&i.RarityPerMille,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGiftsByIDs = `-- name: GetGiftsByIDs :many
+SELECT id, telegram_gift_id, collectible_id, owner_telegram_id, upgrade_message_id, title, slug, price, collection_id, model_id, backdrop_id, symbol_id, status, created_at, updated_at, withdrawn_at, related_duel_id, locked_by_type, locked_by_id, lock_expires_at
+FROM gifts
+WHERE id = ANY($1::uuid[])
+ORDER BY updated_at DESC
+`
+
+func (q *Queries) GetGiftsByIDs(ctx context.Context, dollar_1 []pgtype.UUID) ([]Gift, error) {
+	rows, err := q.db.Query(ctx, getGiftsByIDs, dollar_1)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Gift
+	for rows.Next() {
+		var i Gift
+		if err := rows.Scan(
+			&i.ID,
+			&i.TelegramGiftID,
+			&i.CollectibleID,
+			&i.OwnerTelegramID,
+			&i.UpgradeMessageID,
+			&i.Title,
+			&i.Slug,
+			&i.Price,
+			&i.CollectionID,
+			&i.ModelID,
+			&i.BackdropID,
+			&i.SymbolID,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.WithdrawnAt,
+			&i.RelatedDuelID,
+			&i.LockedByType,
+			&i.LockedByID,
+			&i.LockExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLatestReconciliationRun = `-- name: GetLatestReconciliationRun :one
+SELECT id, custody_count, ledger_count, discrepancy_count, started_at, finished_at
+FROM reconciliation_runs
+ORDER BY started_at DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLatestReconciliationRun(ctx context.Context) (ReconciliationRun, error) {
+	row := q.db.QueryRow(ctx, getLatestReconciliationRun)
+	var i ReconciliationRun
+	err := row.Scan(
+		&i.ID,
+		&i.CustodyCount,
+		&i.LedgerCount,
+		&i.DiscrepancyCount,
+		&i.StartedAt,
+		&i.FinishedAt,
+	)
+	return i, err
+}
+
+const getLatestWithdrawalIntentByGiftsForUpdate = `-- name: GetLatestWithdrawalIntentByGiftsForUpdate :one
+SELECT id, telegram_user_id, gift_ids, stars_amount, invoice_url, status, expires_at, completed_at, created_at, updated_at FROM withdrawal_intents
+WHERE telegram_user_id = $1
+  AND gift_ids @> $2::uuid[]
+ORDER BY created_at DESC
+LIMIT 1
+FOR UPDATE
+`
+
+type GetLatestWithdrawalIntentByGiftsForUpdateParams struct {
+	TelegramUserID int64
+	GiftIds        []pgtype.UUID
+}
+
+func (q *Queries) GetLatestWithdrawalIntentByGiftsForUpdate(ctx context.Context, arg GetLatestWithdrawalIntentByGiftsForUpdateParams) (WithdrawalIntent, error) {
+	row := q.db.QueryRow(ctx, getLatestWithdrawalIntentByGiftsForUpdate, arg.TelegramUserID, arg.GiftIds)
+	var i WithdrawalIntent
+	err := row.Scan(
+		&i.ID,
+		&i.TelegramUserID,
+		&i.GiftIds,
+		&i.StarsAmount,
+		&i.InvoiceUrl,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.CompletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getListingByID = `-- name: GetListingByID :one
+SELECT id, gift_id, seller_telegram_id, buyer_telegram_id, price, fee, status, created_at, updated_at, sold_at
+FROM gift_listings
+WHERE id = $1
+`
+
+func (q *Queries) GetListingByID(ctx context.Context, id pgtype.UUID) (GiftListing, error) {
+	row := q.db.QueryRow(ctx, getListingByID, id)
+	var i GiftListing
+	err := row.Scan(
+		&i.ID,
+		&i.GiftID,
+		&i.SellerTelegramID,
+		&i.BuyerTelegramID,
+		&i.Price,
+		&i.Fee,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.SoldAt,
+	)
+	return i, err
+}
+
+const getListingByIDForUpdate = `-- name: GetListingByIDForUpdate :one
+SELECT id, gift_id, seller_telegram_id, buyer_telegram_id, price, fee, status, created_at, updated_at, sold_at
+FROM gift_listings
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetListingByIDForUpdate(ctx context.Context, id pgtype.UUID) (GiftListing, error) {
+	row := q.db.QueryRow(ctx, getListingByIDForUpdate, id)
+	var i GiftListing
+	err := row.Scan(
+		&i.ID,
+		&i.GiftID,
+		&i.SellerTelegramID,
+		&i.BuyerTelegramID,
+		&i.Price,
+		&i.Fee,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.SoldAt,
+	)
+	return i, err
+}
+
+const getReconcilableGifts = `-- name: GetReconcilableGifts :many
+SELECT id, telegram_gift_id, collectible_id, status, title, slug
+FROM gifts
+WHERE status <> 'withdrawn'
+`
+
+type GetReconcilableGiftsRow struct {
+	ID             pgtype.UUID
+	TelegramGiftID int64
+	CollectibleID  int32
+	Status         GiftStatus
+	Title          string
+	Slug           string
+}
+
+func (q *Queries) GetReconcilableGifts(ctx context.Context) ([]GetReconcilableGiftsRow, error) {
+	rows, err := q.db.Query(ctx, getReconcilableGifts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetReconcilableGiftsRow
+	for rows.Next() {
+		var i GetReconcilableGiftsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TelegramGiftID,
+			&i.CollectibleID,
+			&i.Status,
+			&i.Title,
+			&i.Slug,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getReconciliationDiscrepanciesByRunID = `-- name: GetReconciliationDiscrepanciesByRunID :many
+SELECT id, run_id, kind, telegram_gift_id, collectible_id, title, slug, gift_id, gift_status, created_at
+FROM reconciliation_discrepancies
+WHERE run_id = $1
+ORDER BY kind, telegram_gift_id
+`
+
+func (q *Queries) GetReconciliationDiscrepanciesByRunID(ctx context.Context, runID pgtype.UUID) ([]ReconciliationDiscrepancy, error) {
+	rows, err := q.db.Query(ctx, getReconciliationDiscrepanciesByRunID, runID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ReconciliationDiscrepancy
+	for rows.Next() {
+		var i ReconciliationDiscrepancy
+		if err := rows.Scan(
+			&i.ID,
+			&i.RunID,
+			&i.Kind,
+			&i.TelegramGiftID,
+			&i.CollectibleID,
+			&i.Title,
+			&i.Slug,
+			&i.GiftID,
+			&i.GiftStatus,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getReconciliationRunByID = `-- name: GetReconciliationRunByID :one
+SELECT id, custody_count, ledger_count, discrepancy_count, started_at, finished_at
+FROM reconciliation_runs
+WHERE id = $1
+`
+
+func (q *Queries) GetReconciliationRunByID(ctx context.Context, id pgtype.UUID) (ReconciliationRun, error) {
+	row := q.db.QueryRow(ctx, getReconciliationRunByID, id)
+	var i ReconciliationRun
+	err := row.Scan(
+		&i.ID,
+		&i.CustodyCount,
+		&i.LedgerCount,
+		&i.DiscrepancyCount,
+		&i.StartedAt,
+		&i.FinishedAt,
+	)
+	return i, err
+}
+
+const getSellBackQuoteByIDForUpdate = `-- name: GetSellBackQuoteByIDForUpdate :one
+SELECT id, gift_id, telegram_user_id, floor_price, amount, expires_at, executed_at, created_at
+FROM gift_sell_back_quotes
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetSellBackQuoteByIDForUpdate(ctx context.Context, id pgtype.UUID) (GiftSellBackQuote, error) {
+	row := q.db.QueryRow(ctx, getSellBackQuoteByIDForUpdate, id)
+	var i GiftSellBackQuote
+	err := row.Scan(
+		&i.ID,
+		&i.GiftID,
+		&i.TelegramUserID,
+		&i.FloorPrice,
+		&i.Amount,
+		&i.ExpiresAt,
+		&i.ExecutedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getStakePresetGiftsByPresetIDs = `-- name: GetStakePresetGiftsByPresetIDs :many
+SELECT preset_id, gift_id, position
+FROM stake_preset_gifts
+WHERE preset_id = ANY($1::uuid[])
+ORDER BY preset_id, position
+`
+
+func (q *Queries) GetStakePresetGiftsByPresetIDs(ctx context.Context, dollar_1 []pgtype.UUID) ([]StakePresetGift, error) {
+	rows, err := q.db.Query(ctx, getStakePresetGiftsByPresetIDs, dollar_1)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []StakePresetGift
+	for rows.Next() {
+		var i StakePresetGift
+		if err := rows.Scan(&i.PresetID, &i.GiftID, &i.Position); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserFavoriteGiftIDs = `-- name: GetUserFavoriteGiftIDs :many
+SELECT f.gift_id
+FROM gift_favorites f
+JOIN gifts g ON g.id = f.gift_id
+WHERE f.telegram_user_id = $1
+  AND g.owner_telegram_id = f.telegram_user_id
+  AND g.status <> 'withdrawn'
+ORDER BY f.created_at DESC
+`
+
+func (q *Queries) GetUserFavoriteGiftIDs(ctx context.Context, telegramUserID int64) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, getUserFavoriteGiftIDs, telegramUserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []pgtype.UUID
+	for rows.Next() {
+		var gift_id pgtype.UUID
+		if err := rows.Scan(&gift_id); err != nil {
+			return nil, err
+		}
+		items = append(items, gift_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserGiftActivity = `-- name: GetUserGiftActivity :many
+SELECT id, gift_id, event_type, telegram_user_id, related_game_id, occurred_at FROM gift_events
+WHERE telegram_user_id = $1
+  AND (cardinality($2::text[]) = 0 OR event_type::text = ANY($2::text[]))
+ORDER BY occurred_at DESC
+LIMIT $3 OFFSET $4
+`
+
+type GetUserGiftActivityParams struct {
+	TelegramUserID pgtype.Int8
+	EventTypes     []string
+	Limit          int32
+	Offset         int32
+}
+
+func (q *Queries) GetUserGiftActivity(ctx context.Context, arg GetUserGiftActivityParams) ([]GiftEvent, error) {
+	rows, err := q.db.Query(ctx, getUserGiftActivity,
+		arg.TelegramUserID,
+		arg.EventTypes,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GiftEvent
+	for rows.Next() {
+		var i GiftEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.GiftID,
+			&i.EventType,
+			&i.TelegramUserID,
+			&i.RelatedGameID,
+			&i.OccurredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserGiftActivityCount = `-- name: GetUserGiftActivityCount :one
+SELECT COUNT(*) FROM gift_events
+WHERE telegram_user_id = $1
+  AND (cardinality($2::text[]) = 0 OR event_type::text = ANY($2::text[]))
+`
+
+type GetUserGiftActivityCountParams struct {
+	TelegramUserID pgtype.Int8
+	EventTypes     []string
+}
+
+func (q *Queries) GetUserGiftActivityCount(ctx context.Context, arg GetUserGiftActivityCountParams) (int64, error) {
+	row := q.db.QueryRow(ctx, getUserGiftActivityCount, arg.TelegramUserID, arg.EventTypes)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const getUserGifts = `-- name: GetUserGifts :many
+SELECT id, telegram_gift_id, collectible_id, owner_telegram_id, upgrade_message_id, title, slug, price, collection_id, model_id, backdrop_id, symbol_id, status, created_at, updated_at, withdrawn_at, related_duel_id, locked_by_type, locked_by_id, lock_expires_at
+FROM gifts
+WHERE owner_telegram_id = $1
+ORDER BY updated_at DESC
+LIMIT $2 OFFSET $3
+`
+
+type GetUserGiftsParams struct {
+	OwnerTelegramID int64
+	Limit           int32
+	Offset          int32
+}
+
+func (q *Queries) GetUserGifts(ctx context.Context, arg GetUserGiftsParams) ([]Gift, error) {
+	rows, err := q.db.Query(ctx, getUserGifts, arg.OwnerTelegramID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Gift
+	for rows.Next() {
+		var i Gift
+		if err := rows.Scan(
+			&i.ID,
+			&i.TelegramGiftID,
+			&i.CollectibleID,
+			&i.OwnerTelegramID,
+			&i.UpgradeMessageID,
+			&i.Title,
+			&i.Slug,
+			&i.Price,
+			&i.CollectionID,
+			&i.ModelID,
+			&i.BackdropID,
+			&i.SymbolID,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.WithdrawnAt,
+			&i.RelatedDuelID,
+			&i.LockedByType,
+			&i.LockedByID,
+			&i.LockExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserGiftsCount = `-- name: GetUserGiftsCount :one
+SELECT COUNT(*)
+FROM gifts
+WHERE owner_telegram_id = $1
+`
+
+func (q *Queries) GetUserGiftsCount(ctx context.Context, ownerTelegramID int64) (int64, error) {
+	row := q.db.QueryRow(ctx, getUserGiftsCount, ownerTelegramID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const getUserInventorySummary = `-- name: GetUserInventorySummary :many
+SELECT
+  c.name AS collection,
+  COUNT(*) AS gifts_count,
+  COALESCE(SUM(g.price), 0)::numeric AS total_value
+FROM gifts g
+JOIN gift_collections c ON c.id = g.collection_id
+JOIN gift_models m ON m.id = g.model_id
+JOIN gift_backdrops b ON b.id = g.backdrop_id
+JOIN gift_symbols s ON s.id = g.symbol_id
+WHERE g.owner_telegram_id = $1
+  AND g.status::text = ANY($2::text[])
+  AND ($3::text IS NULL OR c.name = $3::text)
+  AND ($4::text IS NULL OR m.name = $4::text)
+  AND ($5::text IS NULL OR b.name = $5::text)
+  AND ($6::text IS NULL OR s.name = $6::text)
+  AND ($7::numeric IS NULL OR g.price >= $7::numeric)
+  AND ($8::numeric IS NULL OR g.price <= $8::numeric)
+  AND ($9::text IS NULL OR g.title ILIKE $9::text OR g.slug ILIKE $9::text)
+GROUP BY c.name
+ORDER BY total_value DESC, c.name
+`
+
+type GetUserInventorySummaryParams struct {
+	OwnerTelegramID int64
+	Statuses        []string
+	Collection      pgtype.Text
+	Model           pgtype.Text
+	Backdrop        pgtype.Text
+	Symbol          pgtype.Text
+	MinPrice        pgtype.Numeric
+	MaxPrice        pgtype.Numeric
+	Search          pgtype.Text
+}
+
+type GetUserInventorySummaryRow struct {
+	Collection string
+	GiftsCount int64
+	TotalValue pgtype.Numeric
+}
+
+func (q *Queries) GetUserInventorySummary(ctx context.Context, arg GetUserInventorySummaryParams) ([]GetUserInventorySummaryRow, error) {
+	rows, err := q.db.Query(ctx, getUserInventorySummary,
+		arg.OwnerTelegramID,
+		arg.Statuses,
+		arg.Collection,
+		arg.Model,
+		arg.Backdrop,
+		arg.Symbol,
+		arg.MinPrice,
+		arg.MaxPrice,
+		arg.Search,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserInventorySummaryRow
+	for rows.Next() {
+		var i GetUserInventorySummaryRow
+		if err := rows.Scan(
+			&i.Collection,
+			&i.GiftsCount,
+			&i.TotalValue,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserPortfolioPerformance = `-- name: GetUserPortfolioPerformance :one
+SELECT
+  COALESCE(SUM(g.price) FILTER (WHERE e.event_type = 'deposit'), 0)::numeric AS deposited_value,
+  COALESCE(SUM(g.price) FILTER (WHERE e.event_type = 'withdraw_complete'), 0)::numeric AS withdrawn_value,
+  COALESCE(SUM(g.price) FILTER (WHERE e.event_type = 'return_from_game'), 0)::numeric AS duel_won_value,
+  COALESCE(SUM(g.price) FILTER (
+    WHERE e.event_type = 'stake' AND EXISTS (
+      SELECT 1 FROM gift_events r
+      WHERE r.gift_id = e.gift_id
+        AND r.related_game_id = e.related_game_id
+        AND r.event_type = 'return_from_game'
+        AND r.telegram_user_id <> e.telegram_user_id
+    )
+  ), 0)::numeric AS duel_lost_value
+FROM gift_events e
+JOIN gifts g ON g.id = e.gift_id
+WHERE e.telegram_user_id = $1::bigint
+`
+
+type GetUserPortfolioPerformanceRow struct {
+	DepositedValue pgtype.Numeric
+	WithdrawnValue pgtype.Numeric
+	DuelWonValue   pgtype.Numeric
+	DuelLostValue  pgtype.Numeric
+}
+
+func (q *Queries) GetUserPortfolioPerformance(ctx context.Context, telegramUserID int64) (GetUserPortfolioPerformanceRow, error) {
+	row := q.db.QueryRow(ctx, getUserPortfolioPerformance, telegramUserID)
+	var i GetUserPortfolioPerformanceRow
+	err := row.Scan(
+		&i.DepositedValue,
+		&i.WithdrawnValue,
+		&i.DuelWonValue,
+		&i.DuelLostValue,
+	)
+	return i, err
+}
+
+const getUserPortfolioSnapshots = `-- name: GetUserPortfolioSnapshots :many
+SELECT telegram_user_id, snapshot_date, owned_value, in_game_value, owned_count, in_game_count, created_at, updated_at
+FROM portfolio_snapshots
+WHERE telegram_user_id = $1
+  AND snapshot_date >= $2::date
+  AND snapshot_date <= $3::date
+ORDER BY snapshot_date
+`
+
+type GetUserPortfolioSnapshotsParams struct {
+	TelegramUserID int64
+	FromDate       pgtype.Date
+	ToDate         pgtype.Date
+}
+
+func (q *Queries) GetUserPortfolioSnapshots(ctx context.Context, arg GetUserPortfolioSnapshotsParams) ([]PortfolioSnapshot, error) {
+	rows, err := q.db.Query(ctx, getUserPortfolioSnapshots, arg.TelegramUserID, arg.FromDate, arg.ToDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PortfolioSnapshot
+	for rows.Next() {
+		var i PortfolioSnapshot
+		if err := rows.Scan(
+			&i.TelegramUserID,
+			&i.SnapshotDate,
+			&i.OwnedValue,
+			&i.InGameValue,
+			&i.OwnedCount,
+			&i.InGameCount,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserPortfolioValuation = `-- name: GetUserPortfolioValuation :one
+SELECT
+  COALESCE(SUM(price) FILTER (WHERE status IN ('owned', 'listed', 'withdraw_pending')), 0)::numeric AS owned_value,
+  COALESCE(SUM(price) FILTER (WHERE status = 'in_game'), 0)::numeric AS in_game_value,
+  COUNT(*) FILTER (WHERE status IN ('owned', 'listed', 'withdraw_pending')) AS owned_count,
+  COUNT(*) FILTER (WHERE status = 'in_game') AS in_game_count
+FROM gifts
+WHERE owner_telegram_id = $1
+`
+
+type GetUserPortfolioValuationRow struct {
+	OwnedValue  pgtype.Numeric
+	InGameValue pgtype.Numeric
+	OwnedCount  int64
+	InGameCount int64
+}
+
+func (q *Queries) GetUserPortfolioValuation(ctx context.Context, ownerTelegramID int64) (GetUserPortfolioValuationRow, error) {
+	row := q.db.QueryRow(ctx, getUserPortfolioValuation, ownerTelegramID)
+	var i GetUserPortfolioValuationRow
+	err := row.Scan(
+		&i.OwnedValue,
+		&i.InGameValue,
+		&i.OwnedCount,
+		&i.InGameCount,
+	)
+	return i, err
+}
+
+const getUserStakePresets = `-- name: GetUserStakePresets :many
+SELECT id, telegram_user_id, name, created_at, updated_at
+FROM stake_presets
+WHERE telegram_user_id = $1
+ORDER BY name
+`
+
+func (q *Queries) GetUserStakePresets(ctx context.Context, telegramUserID int64) ([]StakePreset, error) {
+	rows, err := q.db.Query(ctx, getUserStakePresets, telegramUserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []StakePreset
+	for rows.Next() {
+		var i StakePreset
+		if err := rows.Scan(
+			&i.ID,
+			&i.TelegramUserID,
+			&i.Name,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserWithdrawals = `-- name: GetUserWithdrawals :many
+SELECT id, telegram_user_id, commission_currency, commission_amount, status, failure_reason, intent_id, completed_at, failed_at, created_at, updated_at FROM withdrawals
+WHERE telegram_user_id = $1
+  AND (cardinality($2::text[]) = 0 OR status::text = ANY($2::text[]))
+ORDER BY created_at DESC
+LIMIT $3 OFFSET $4
+`
+
+type GetUserWithdrawalsParams struct {
+	TelegramUserID int64
+	Statuses       []string
+	Limit          int32
+	Offset         int32
+}
+
+func (q *Queries) GetUserWithdrawals(ctx context.Context, arg GetUserWithdrawalsParams) ([]Withdrawal, error) {
+	rows, err := q.db.Query(ctx, getUserWithdrawals,
+		arg.TelegramUserID,
+		arg.Statuses,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Withdrawal
+	for rows.Next() {
+		var i Withdrawal
+		if err := rows.Scan(
+			&i.ID,
+			&i.TelegramUserID,
+			&i.CommissionCurrency,
+			&i.CommissionAmount,
+			&i.Status,
+			&i.FailureReason,
+			&i.IntentID,
+			&i.CompletedAt,
+			&i.FailedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserWithdrawalsCount = `-- name: GetUserWithdrawalsCount :one
+SELECT COUNT(*) FROM withdrawals
+WHERE telegram_user_id = $1
+  AND (cardinality($2::text[]) = 0 OR status::text = ANY($2::text[]))
+`
+
+type GetUserWithdrawalsCountParams struct {
+	TelegramUserID int64
+	Statuses       []string
+}
+
+func (q *Queries) GetUserWithdrawalsCount(ctx context.Context, arg GetUserWithdrawalsCountParams) (int64, error) {
+	row := q.db.QueryRow(ctx, getUserWithdrawalsCount, arg.TelegramUserID, arg.Statuses)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const getWithdrawalByID = `-- name: GetWithdrawalByID :one
+SELECT id, telegram_user_id, commission_currency, commission_amount, status, failure_reason, intent_id, completed_at, failed_at, created_at, updated_at FROM withdrawals
+WHERE id = $1
+`
+
+func (q *Queries) GetWithdrawalByID(ctx context.Context, id pgtype.UUID) (Withdrawal, error) {
+	row := q.db.QueryRow(ctx, getWithdrawalByID, id)
+	var i Withdrawal
+	err := row.Scan(
+		&i.ID,
+		&i.TelegramUserID,
+		&i.CommissionCurrency,
+		&i.CommissionAmount,
+		&i.Status,
+		&i.FailureReason,
+		&i.IntentID,
+		&i.CompletedAt,
+		&i.FailedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getWithdrawalByIntentIDForUpdate = `-- name: GetWithdrawalByIntentIDForUpdate :one
+SELECT id, telegram_user_id, commission_currency, commission_amount, status, failure_reason, intent_id, completed_at, failed_at, created_at, updated_at FROM withdrawals
+WHERE intent_id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetWithdrawalByIntentIDForUpdate(ctx context.Context, intentID pgtype.UUID) (Withdrawal, error) {
+	row := q.db.QueryRow(ctx, getWithdrawalByIntentIDForUpdate, intentID)
+	var i Withdrawal
+	err := row.Scan(
+		&i.ID,
+		&i.TelegramUserID,
+		&i.CommissionCurrency,
+		&i.CommissionAmount,
+		&i.Status,
+		&i.FailureReason,
+		&i.IntentID,
+		&i.CompletedAt,
+		&i.FailedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getWithdrawalGiftsByWithdrawalIDs = `-- name: GetWithdrawalGiftsByWithdrawalIDs :many
+SELECT withdrawal_id, gift_id, commission_amount, status, failure_reason, updated_at FROM withdrawal_gifts
+WHERE withdrawal_id = ANY($1::uuid[])
+`
+
+func (q *Queries) GetWithdrawalGiftsByWithdrawalIDs(ctx context.Context, withdrawalIds []pgtype.UUID) ([]WithdrawalGift, error) {
+	rows, err := q.db.Query(ctx, getWithdrawalGiftsByWithdrawalIDs, withdrawalIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WithdrawalGift
+	for rows.Next() {
+		var i WithdrawalGift
+		if err := rows.Scan(
+			&i.WithdrawalID,
+			&i.GiftID,
+			&i.CommissionAmount,
+			&i.Status,
+			&i.FailureReason,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWithdrawalIntentByID = `-- name: GetWithdrawalIntentByID :one
+SELECT id, telegram_user_id, gift_ids, stars_amount, invoice_url, status, expires_at, completed_at, created_at, updated_at FROM withdrawal_intents
+WHERE id = $1
+`
+
+func (q *Queries) GetWithdrawalIntentByID(ctx context.Context, id pgtype.UUID) (WithdrawalIntent, error) {
+	row := q.db.QueryRow(ctx, getWithdrawalIntentByID, id)
+	var i WithdrawalIntent
+	err := row.Scan(
+		&i.ID,
+		&i.TelegramUserID,
+		&i.GiftIds,
+		&i.StarsAmount,
+		&i.InvoiceUrl,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.CompletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const giftHasUserEvent = `-- name: GiftHasUserEvent :one
+SELECT EXISTS (
+  SELECT 1 FROM gift_events
+  WHERE gift_id = $1 AND telegram_user_id = $2
+)
+`
+
+type GiftHasUserEventParams struct {
+	GiftID         pgtype.UUID
+	TelegramUserID pgtype.Int8
+}
+
+func (q *Queries) GiftHasUserEvent(ctx context.Context, arg GiftHasUserEventParams) (bool, error) {
+	row := q.db.QueryRow(ctx, giftHasUserEvent, arg.GiftID, arg.TelegramUserID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const hasPendingWithdrawalIntentForGifts = `-- name: HasPendingWithdrawalIntentForGifts :one
+SELECT EXISTS (
+  SELECT 1 FROM withdrawal_intents
+  WHERE status = 'pending'
+    AND gift_ids && $1::uuid[]
+)
+`
+
+func (q *Queries) HasPendingWithdrawalIntentForGifts(ctx context.Context, giftIds []pgtype.UUID) (bool, error) {
+	row := q.db.QueryRow(ctx, hasPendingWithdrawalIntentForGifts, giftIds)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const listGift = `-- name: ListGift :one
+UPDATE gifts
+SET status = 'listed', updated_at = NOW()
+WHERE id = $1 AND status = 'owned'
+RETURNING id, telegram_gift_id, collectible_id, owner_telegram_id, upgrade_message_id, title, slug, price, collection_id, model_id, backdrop_id, symbol_id, status, created_at, updated_at, withdrawn_at, related_duel_id, locked_by_type, locked_by_id, lock_expires_at
+`
+
+func (q *Queries) ListGift(ctx context.Context, id pgtype.UUID) (Gift, error) {
+	row := q.db.QueryRow(ctx, listGift, id)
+	var i Gift
+	err := row.Scan(
+		&i.ID,
+		&i.TelegramGiftID,
+		&i.CollectibleID,
+		&i.OwnerTelegramID,
+		&i.UpgradeMessageID,
+		&i.Title,
+		&i.Slug,
+		&i.Price,
+		&i.CollectionID,
+		&i.ModelID,
+		&i.BackdropID,
+		&i.SymbolID,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.WithdrawnAt,
+		&i.RelatedDuelID,
+		&i.LockedByType,
+		&i.LockedByID,
+		&i.LockExpiresAt,
+	)
+	return i, err
+}
+
+const markGiftForWithdrawal = `-- name: MarkGiftForWithdrawal :one
+UPDATE gifts 
+SET status = 'withdraw_pending', updated_at = NOW()
+WHERE id = $1 AND status = 'owned'
+RETURNING id, telegram_gift_id, collectible_id, owner_telegram_id, upgrade_message_id, title, slug, price, collection_id, model_id, backdrop_id, symbol_id, status, created_at, updated_at, withdrawn_at, related_duel_id, locked_by_type, locked_by_id, lock_expires_at
+`
+
+func (q *Queries) MarkGiftForWithdrawal(ctx context.Context, id pgtype.UUID) (Gift, error) {
+	row := q.db.QueryRow(ctx, markGiftForWithdrawal, id)
+	var i Gift
+	err := row.Scan(
+		&i.ID,
+		&i.TelegramGiftID,
+		&i.CollectibleID,
+		&i.OwnerTelegramID,
+		&i.UpgradeMessageID,
+		&i.Title,
+		&i.Slug,
+		&i.Price,
+		&i.CollectionID,
+		&i.ModelID,
+		&i.BackdropID,
+		&i.SymbolID,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.WithdrawnAt,
+		&i.RelatedDuelID,
+		&i.LockedByType,
+		&i.LockedByID,
+		&i.LockExpiresAt,
+	)
+	return i, err
+}
+
+const markListingSold = `-- name: MarkListingSold :one
+UPDATE gift_listings
+SET status = 'sold',
+    buyer_telegram_id = $2,
+    fee = $3,
+    sold_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1 AND status = 'active'
+RETURNING id, gift_id, seller_telegram_id, buyer_telegram_id, price, fee, status, created_at, updated_at, sold_at
+`
+
+type MarkListingSoldParams struct {
+	ID              pgtype.UUID
+	BuyerTelegramID pgtype.Int8
+	Fee             pgtype.Numeric
+}
+
+func (q *Queries) MarkListingSold(ctx context.Context, arg MarkListingSoldParams) (GiftListing, error) {
+	row := q.db.QueryRow(ctx, markListingSold, arg.ID, arg.BuyerTelegramID, arg.Fee)
+	var i GiftListing
+	err := row.Scan(
+		&i.ID,
+		&i.GiftID,
+		&i.SellerTelegramID,
+		&i.BuyerTelegramID,
+		&i.Price,
+		&i.Fee,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.SoldAt,
+	)
+	return i, err
+}
+
+const markSellBackQuoteExecuted = `-- name: MarkSellBackQuoteExecuted :one
+UPDATE gift_sell_back_quotes
+SET executed_at = NOW()
+WHERE id = $1 AND executed_at IS NULL
+RETURNING id, gift_id, telegram_user_id, floor_price, amount, expires_at, executed_at, created_at
+`
+
+func (q *Queries) MarkSellBackQuoteExecuted(ctx context.Context, id pgtype.UUID) (GiftSellBackQuote, error) {
+	row := q.db.QueryRow(ctx, markSellBackQuoteExecuted, id)
+	var i GiftSellBackQuote
+	err := row.Scan(
+		&i.ID,
+		&i.GiftID,
+		&i.TelegramUserID,
+		&i.FloorPrice,
+		&i.Amount,
+		&i.ExpiresAt,
+		&i.ExecutedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const markWithdrawalIntentCompleted = `-- name: MarkWithdrawalIntentCompleted :one
+UPDATE withdrawal_intents
+SET status = 'completed', completed_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND status IN ('pending', 'expired')
+RETURNING id, telegram_user_id, gift_ids, stars_amount, invoice_url, status, expires_at, completed_at, created_at, updated_at
+`
+
+func (q *Queries) MarkWithdrawalIntentCompleted(ctx context.Context, id pgtype.UUID) (WithdrawalIntent, error) {
+	row := q.db.QueryRow(ctx, markWithdrawalIntentCompleted, id)
+	var i WithdrawalIntent
+	err := row.Scan(
+		&i.ID,
+		&i.TelegramUserID,
+		&i.GiftIds,
+		&i.StarsAmount,
+		&i.InvoiceUrl,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.CompletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const markWithdrawalIntentExpired = `-- name: MarkWithdrawalIntentExpired :one
+UPDATE withdrawal_intents
+SET status = 'expired', updated_at = NOW()
+WHERE id = $1 AND status = 'pending'
+RETURNING id, telegram_user_id, gift_ids, stars_amount, invoice_url, status, expires_at, completed_at, created_at, updated_at
+`
+
+func (q *Queries) MarkWithdrawalIntentExpired(ctx context.Context, id pgtype.UUID) (WithdrawalIntent, error) {
+	row := q.db.QueryRow(ctx, markWithdrawalIntentExpired, id)
+	var i WithdrawalIntent
+	err := row.Scan(
+		&i.ID,
+		&i.TelegramUserID,
+		&i.GiftIds,
+		&i.StarsAmount,
+		&i.InvoiceUrl,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.CompletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const markWithdrawalIntentPaidAfterExpiry = `-- name: MarkWithdrawalIntentPaidAfterExpiry :one
+UPDATE withdrawal_intents
+SET status = 'paid_after_expiry', updated_at = NOW()
+WHERE id = $1 AND status = 'expired'
+RETURNING id, telegram_user_id, gift_ids, stars_amount, invoice_url, status, expires_at, completed_at, created_at, updated_at
+`
+
+func (q *Queries) MarkWithdrawalIntentPaidAfterExpiry(ctx context.Context, id pgtype.UUID) (WithdrawalIntent, error) {
+	row := q.db.QueryRow(ctx, markWithdrawalIntentPaidAfterExpiry, id)
+	var i WithdrawalIntent
+	err := row.Scan(
+		&i.ID,
+		&i.TelegramUserID,
+		&i.GiftIds,
+		&i.StarsAmount,
+		&i.InvoiceUrl,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.CompletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const releaseGiftLeases = `-- name: ReleaseGiftLeases :many
+UPDATE gifts
+SET status = 'owned',
+    related_duel_id = NULL,
+    locked_by_type = NULL,
+    locked_by_id = NULL,
+    lock_expires_at = NULL,
+    updated_at = NOW()
 WHERE id = ANY($1::uuid[])
-ORDER BY updated_at DESC
+  AND status = 'in_game'
+  AND lock_expires_at <= $2
+RETURNING id, telegram_gift_id, collectible_id, owner_telegram_id, upgrade_message_id, title, slug, price, collection_id, model_id, backdrop_id, symbol_id, status, created_at, updated_at, withdrawn_at, related_duel_id, locked_by_type, locked_by_id, lock_expires_at
 `
 
-func (q *Queries) GetGiftsByIDs(ctx context.Context, dollar_1 []pgtype.UUID) ([]Gift, error) {
-	rows, err := q.db.Query(ctx, getGiftsByIDs, dollar_1)
+type ReleaseGiftLeasesParams struct {
+	GiftIds []pgtype.UUID
+	Now     pgtype.Timestamptz
+}
+
+func (q *Queries) ReleaseGiftLeases(ctx context.Context, arg ReleaseGiftLeasesParams) ([]Gift, error) {
+	rows, err := q.db.Query(ctx, releaseGiftLeases, arg.GiftIds, arg.Now)
 	if err != nil {
 		return nil, err
 	}
@@ -670,6 +2713,10 @@ func (q *Queries) GetGiftsByIDs(ctx context.Context, dollar_1 []pgtype.UUID) ([]
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.WithdrawnAt,
+			&i.RelatedDuelID,
+			&i.LockedByType,
+			&i.LockedByID,
+			&i.LockExpiresAt,
 		); err != nil {
 			return nil, err
 		}
@@ -681,91 +2728,164 @@ func (q *Queries) GetGiftsByIDs(ctx context.Context, dollar_1 []pgtype.UUID) ([]
 	return items, nil
 }
 
-const getUserActiveGifts = `-- name: GetUserActiveGifts :many
-SELECT id, telegram_gift_id, collectible_id, owner_telegram_id, upgrade_message_id, title, slug, price, collection_id, model_id, backdrop_id, symbol_id, status, created_at, updated_at, withdrawn_at
-FROM gifts
-WHERE owner_telegram_id = $1
-  AND status IN ('owned', 'in_game')
-ORDER BY
-  updated_at DESC,
-  (status = 'owned') DESC,
-  price DESC
-LIMIT $2 OFFSET $3
+const removeGiftFavorite = `-- name: RemoveGiftFavorite :execrows
+DELETE FROM gift_favorites
+WHERE telegram_user_id = $1 AND gift_id = $2
 `
 
-type GetUserActiveGiftsParams struct {
-	OwnerTelegramID int64
-	Limit           int32
-	Offset          int32
+type RemoveGiftFavoriteParams struct {
+	TelegramUserID int64
+	GiftID         pgtype.UUID
 }
 
-func (q *Queries) GetUserActiveGifts(ctx context.Context, arg GetUserActiveGiftsParams) ([]Gift, error) {
-	rows, err := q.db.Query(ctx, getUserActiveGifts, arg.OwnerTelegramID, arg.Limit, arg.Offset)
+func (q *Queries) RemoveGiftFavorite(ctx context.Context, arg RemoveGiftFavoriteParams) (int64, error) {
+	result, err := q.db.Exec(ctx, removeGiftFavorite, arg.TelegramUserID, arg.GiftID)
 	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []Gift
-	for rows.Next() {
-		var i Gift
-		if err := rows.Scan(
-			&i.ID,
-			&i.TelegramGiftID,
-			&i.CollectibleID,
-			&i.OwnerTelegramID,
-			&i.UpgradeMessageID,
-			&i.Title,
-			&i.Slug,
-			&i.Price,
-			&i.CollectionID,
-			&i.ModelID,
-			&i.BackdropID,
-			&i.SymbolID,
-			&i.Status,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-			&i.WithdrawnAt,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
+		return 0, err
 	}
-	return items, nil
+	return result.RowsAffected(), nil
 }
 
-const getUserActiveGiftsCount = `-- name: GetUserActiveGiftsCount :one
-SELECT COUNT(*)
-FROM gifts
-WHERE owner_telegram_id = $1
-  AND status IN ('owned', 'in_game')
+const returnGiftFromGame = `-- name: ReturnGiftFromGame :one
+UPDATE gifts 
+SET status = 'owned',
+    related_duel_id = NULL,
+    locked_by_type = NULL,
+    locked_by_id = NULL,
+    lock_expires_at = NULL,
+    updated_at = NOW()
+WHERE id = $1 AND status = 'in_game'
+RETURNING id, telegram_gift_id, collectible_id, owner_telegram_id, upgrade_message_id, title, slug, price, collection_id, model_id, backdrop_id, symbol_id, status, created_at, updated_at, withdrawn_at, related_duel_id, locked_by_type, locked_by_id, lock_expires_at
 `
 
-func (q *Queries) GetUserActiveGiftsCount(ctx context.Context, ownerTelegramID int64) (int64, error) {
-	row := q.db.QueryRow(ctx, getUserActiveGiftsCount, ownerTelegramID)
-	var count int64
-	err := row.Scan(&count)
-	return count, err
+func (q *Queries) ReturnGiftFromGame(ctx context.Context, id pgtype.UUID) (Gift, error) {
+	row := q.db.QueryRow(ctx, returnGiftFromGame, id)
+	var i Gift
+	err := row.Scan(
+		&i.ID,
+		&i.TelegramGiftID,
+		&i.CollectibleID,
+		&i.OwnerTelegramID,
+		&i.UpgradeMessageID,
+		&i.Title,
+		&i.Slug,
+		&i.Price,
+		&i.CollectionID,
+		&i.ModelID,
+		&i.BackdropID,
+		&i.SymbolID,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.WithdrawnAt,
+		&i.RelatedDuelID,
+		&i.LockedByType,
+		&i.LockedByID,
+		&i.LockExpiresAt,
+	)
+	return i, err
 }
 
-const getUserGifts = `-- name: GetUserGifts :many
-SELECT id, telegram_gift_id, collectible_id, owner_telegram_id, upgrade_message_id, title, slug, price, collection_id, model_id, backdrop_id, symbol_id, status, created_at, updated_at, withdrawn_at
-FROM gifts
-WHERE owner_telegram_id = $1
-ORDER BY updated_at DESC
-LIMIT $2 OFFSET $3
+const saveGiftWithPrice = `-- name: SaveGiftWithPrice :one
+UPDATE gifts 
+SET price = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING id, telegram_gift_id, collectible_id, owner_telegram_id, upgrade_message_id, title, slug, price, collection_id, model_id, backdrop_id, symbol_id, status, created_at, updated_at, withdrawn_at, related_duel_id, locked_by_type, locked_by_id, lock_expires_at
 `
 
-type GetUserGiftsParams struct {
+type SaveGiftWithPriceParams struct {
+	ID    pgtype.UUID
+	Price pgtype.Numeric
+}
+
+func (q *Queries) SaveGiftWithPrice(ctx context.Context, arg SaveGiftWithPriceParams) (Gift, error) {
+	row := q.db.QueryRow(ctx, saveGiftWithPrice, arg.ID, arg.Price)
+	var i Gift
+	err := row.Scan(
+		&i.ID,
+		&i.TelegramGiftID,
+		&i.CollectibleID,
+		&i.OwnerTelegramID,
+		&i.UpgradeMessageID,
+		&i.Title,
+		&i.Slug,
+		&i.Price,
+		&i.CollectionID,
+		&i.ModelID,
+		&i.BackdropID,
+		&i.SymbolID,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.WithdrawnAt,
+		&i.RelatedDuelID,
+		&i.LockedByType,
+		&i.LockedByID,
+		&i.LockExpiresAt,
+	)
+	return i, err
+}
+
+const searchUserGifts = `-- name: SearchUserGifts :many
+SELECT g.id, g.telegram_gift_id, g.collectible_id, g.owner_telegram_id, g.upgrade_message_id, g.title, g.slug, g.price, g.collection_id, g.model_id, g.backdrop_id, g.symbol_id, g.status, g.created_at, g.updated_at, g.withdrawn_at, g.related_duel_id, g.locked_by_type, g.locked_by_id, g.lock_expires_at
+FROM gifts g
+JOIN gift_collections c ON c.id = g.collection_id
+JOIN gift_models m ON m.id = g.model_id
+JOIN gift_backdrops b ON b.id = g.backdrop_id
+JOIN gift_symbols s ON s.id = g.symbol_id
+WHERE g.owner_telegram_id = $1
+  AND g.status::text = ANY($2::text[])
+  AND ($3::text IS NULL OR c.name = $3::text)
+  AND ($4::text IS NULL OR m.name = $4::text)
+  AND ($5::text IS NULL OR b.name = $5::text)
+  AND ($6::text IS NULL OR s.name = $6::text)
+  AND ($7::numeric IS NULL OR g.price >= $7::numeric)
+  AND ($8::numeric IS NULL OR g.price <= $8::numeric)
+  AND ($9::text IS NULL OR g.title ILIKE $9::text OR g.slug ILIKE $9::text)
+ORDER BY
+  CASE WHEN $10::text = 'price' AND $11::bool THEN g.price END DESC,
+  CASE WHEN $10::text = 'price' AND NOT $11::bool THEN g.price END ASC,
+  CASE WHEN $10::text = 'rarity' AND $11::bool THEN m.rarity_per_mille::bigint * b.rarity_per_mille * s.rarity_per_mille END ASC,
+  CASE WHEN $10::text = 'rarity' AND NOT $11::bool THEN m.rarity_per_mille::bigint * b.rarity_per_mille * s.rarity_per_mille END DESC,
+  CASE WHEN $10::text = 'deposit_date' AND $11::bool THEN g.created_at END DESC,
+  CASE WHEN $10::text = 'deposit_date' AND NOT $11::bool THEN g.created_at END ASC,
+  g.updated_at DESC,
+  g.id
+LIMIT $12 OFFSET $13
+`
+
+type SearchUserGiftsParams struct {
 	OwnerTelegramID int64
+	Statuses        []string
+	Collection      pgtype.Text
+	Model           pgtype.Text
+	Backdrop        pgtype.Text
+	Symbol          pgtype.Text
+	MinPrice        pgtype.Numeric
+	MaxPrice        pgtype.Numeric
+	Search          pgtype.Text
+	SortBy          string
+	SortDesc        bool
 	Limit           int32
 	Offset          int32
 }
 
-func (q *Queries) GetUserGifts(ctx context.Context, arg GetUserGiftsParams) ([]Gift, error) {
-	rows, err := q.db.Query(ctx, getUserGifts, arg.OwnerTelegramID, arg.Limit, arg.Offset)
+func (q *Queries) SearchUserGifts(ctx context.Context, arg SearchUserGiftsParams) ([]Gift, error) {
+	rows, err := q.db.Query(ctx, searchUserGifts,
+		arg.OwnerTelegramID,
+		arg.Statuses,
+		arg.Collection,
+		arg.Model,
+		arg.Backdrop,
+		arg.Symbol,
+		arg.MinPrice,
+		arg.MaxPrice,
+		arg.Search,
+		arg.SortBy,
+		arg.SortDesc,
+		arg.Limit,
+		arg.Offset,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -790,6 +2910,10 @@ func (q *Queries) GetUserGifts(ctx context.Context, arg GetUserGiftsParams) ([]G
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.WithdrawnAt,
+			&i.RelatedDuelID,
+			&i.LockedByType,
+			&i.LockedByID,
+			&i.LockExpiresAt,
 		); err != nil {
 			return nil, err
 		}
@@ -801,28 +2925,26 @@ func (q *Queries) GetUserGifts(ctx context.Context, arg GetUserGiftsParams) ([]G
 	return items, nil
 }
 
-const getUserGiftsCount = `-- name: GetUserGiftsCount :one
-SELECT COUNT(*)
-FROM gifts
-WHERE owner_telegram_id = $1
+const stakeGiftForGame = `-- name: StakeGiftForGame :one
+UPDATE gifts 
+SET status = 'in_game',
+    related_duel_id = $1,
+    locked_by_type = 'duel',
+    locked_by_id = $1,
+    lock_expires_at = $2,
+    updated_at = NOW()
+WHERE id = $3 AND status = 'owned'
+RETURNING id, telegram_gift_id, collectible_id, owner_telegram_id, upgrade_message_id, title, slug, price, collection_id, model_id, backdrop_id, symbol_id, status, created_at, updated_at, withdrawn_at, related_duel_id, locked_by_type, locked_by_id, lock_expires_at
 `
 
-func (q *Queries) GetUserGiftsCount(ctx context.Context, ownerTelegramID int64) (int64, error) {
-	row := q.db.QueryRow(ctx, getUserGiftsCount, ownerTelegramID)
-	var count int64
-	err := row.Scan(&count)
-	return count, err
+type StakeGiftForGameParams struct {
+	RelatedDuelID pgtype.UUID
+	LockExpiresAt pgtype.Timestamptz
+	ID            pgtype.UUID
 }
 
-const markGiftForWithdrawal = `-- name: MarkGiftForWithdrawal :one
-UPDATE gifts 
-SET status = 'withdraw_pending', updated_at = NOW()
-WHERE id = $1 AND status = 'owned'
-RETURNING id, telegram_gift_id, collectible_id, owner_telegram_id, upgrade_message_id, title, slug, price, collection_id, model_id, backdrop_id, symbol_id, status, created_at, updated_at, withdrawn_at
-`
-
-func (q *Queries) MarkGiftForWithdrawal(ctx context.Context, id pgtype.UUID) (Gift, error) {
-	row := q.db.QueryRow(ctx, markGiftForWithdrawal, id)
+func (q *Queries) StakeGiftForGame(ctx context.Context, arg StakeGiftForGameParams) (Gift, error) {
+	row := q.db.QueryRow(ctx, stakeGiftForGame, arg.RelatedDuelID, arg.LockExpiresAt, arg.ID)
 	var i Gift
 	err := row.Scan(
 		&i.ID,
@@ -841,19 +2963,38 @@ func (q *Queries) MarkGiftForWithdrawal(ctx context.Context, id pgtype.UUID) (Gi
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.WithdrawnAt,
+		&i.RelatedDuelID,
+		&i.LockedByType,
+		&i.LockedByID,
+		&i.LockExpiresAt,
 	)
 	return i, err
 }
 
-const returnGiftFromGame = `-- name: ReturnGiftFromGame :one
-UPDATE gifts 
-SET status = 'owned', updated_at = NOW()
-WHERE id = $1 AND status = 'in_game'
-RETURNING id, telegram_gift_id, collectible_id, owner_telegram_id, upgrade_message_id, title, slug, price, collection_id, model_id, backdrop_id, symbol_id, status, created_at, updated_at, withdrawn_at
+const transferGiftFromGame = `-- name: TransferGiftFromGame :one
+UPDATE gifts
+SET owner_telegram_id = $1,
+    status = 'owned',
+    related_duel_id = NULL,
+    locked_by_type = NULL,
+    locked_by_id = NULL,
+    lock_expires_at = NULL,
+    updated_at = NOW()
+WHERE id = $2
+  AND status = 'in_game'
+  AND locked_by_type = 'duel'
+  AND locked_by_id = $3
+RETURNING id, telegram_gift_id, collectible_id, owner_telegram_id, upgrade_message_id, title, slug, price, collection_id, model_id, backdrop_id, symbol_id, status, created_at, updated_at, withdrawn_at, related_duel_id, locked_by_type, locked_by_id, lock_expires_at
 `
 
-func (q *Queries) ReturnGiftFromGame(ctx context.Context, id pgtype.UUID) (Gift, error) {
-	row := q.db.QueryRow(ctx, returnGiftFromGame, id)
+type TransferGiftFromGameParams struct {
+	OwnerTelegramID int64
+	ID              pgtype.UUID
+	DuelID          pgtype.UUID
+}
+
+func (q *Queries) TransferGiftFromGame(ctx context.Context, arg TransferGiftFromGameParams) (Gift, error) {
+	row := q.db.QueryRow(ctx, transferGiftFromGame, arg.OwnerTelegramID, arg.ID, arg.DuelID)
 	var i Gift
 	err := row.Scan(
 		&i.ID,
@@ -872,24 +3013,28 @@ func (q *Queries) ReturnGiftFromGame(ctx context.Context, id pgtype.UUID) (Gift,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.WithdrawnAt,
+		&i.RelatedDuelID,
+		&i.LockedByType,
+		&i.LockedByID,
+		&i.LockExpiresAt,
 	)
 	return i, err
 }
 
-const saveGiftWithPrice = `-- name: SaveGiftWithPrice :one
-UPDATE gifts 
-SET price = $2, updated_at = NOW()
-WHERE id = $1
-RETURNING id, telegram_gift_id, collectible_id, owner_telegram_id, upgrade_message_id, title, slug, price, collection_id, model_id, backdrop_id, symbol_id, status, created_at, updated_at, withdrawn_at
+const transferListedGift = `-- name: TransferListedGift :one
+UPDATE gifts
+SET owner_telegram_id = $2, status = 'owned', updated_at = NOW()
+WHERE id = $1 AND status = 'listed'
+RETURNING id, telegram_gift_id, collectible_id, owner_telegram_id, upgrade_message_id, title, slug, price, collection_id, model_id, backdrop_id, symbol_id, status, created_at, updated_at, withdrawn_at, related_duel_id, locked_by_type, locked_by_id, lock_expires_at
 `
 
-type SaveGiftWithPriceParams struct {
-	ID    pgtype.UUID
-	Price pgtype.Numeric
+type TransferListedGiftParams struct {
+	ID              pgtype.UUID
+	OwnerTelegramID int64
 }
 
-func (q *Queries) SaveGiftWithPrice(ctx context.Context, arg SaveGiftWithPriceParams) (Gift, error) {
-	row := q.db.QueryRow(ctx, saveGiftWithPrice, arg.ID, arg.Price)
+func (q *Queries) TransferListedGift(ctx context.Context, arg TransferListedGiftParams) (Gift, error) {
+	row := q.db.QueryRow(ctx, transferListedGift, arg.ID, arg.OwnerTelegramID)
 	var i Gift
 	err := row.Scan(
 		&i.ID,
@@ -908,19 +3053,31 @@ func (q *Queries) SaveGiftWithPrice(ctx context.Context, arg SaveGiftWithPricePa
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.WithdrawnAt,
+		&i.RelatedDuelID,
+		&i.LockedByType,
+		&i.LockedByID,
+		&i.LockExpiresAt,
 	)
 	return i, err
 }
 
-const stakeGiftForGame = `-- name: StakeGiftForGame :one
-UPDATE gifts 
-SET status = 'in_game', updated_at = NOW()
-WHERE id = $1 AND status = 'owned'
-RETURNING id, telegram_gift_id, collectible_id, owner_telegram_id, upgrade_message_id, title, slug, price, collection_id, model_id, backdrop_id, symbol_id, status, created_at, updated_at, withdrawn_at
+const transferOwnedGift = `-- name: TransferOwnedGift :one
+UPDATE gifts
+SET owner_telegram_id = $1, updated_at = NOW()
+WHERE id = $2
+  AND owner_telegram_id = $3
+  AND status = 'owned'
+RETURNING id, telegram_gift_id, collectible_id, owner_telegram_id, upgrade_message_id, title, slug, price, collection_id, model_id, backdrop_id, symbol_id, status, created_at, updated_at, withdrawn_at, related_duel_id, locked_by_type, locked_by_id, lock_expires_at
 `
 
-func (q *Queries) StakeGiftForGame(ctx context.Context, id pgtype.UUID) (Gift, error) {
-	row := q.db.QueryRow(ctx, stakeGiftForGame, id)
+type TransferOwnedGiftParams struct {
+	ToTelegramID   int64
+	ID             pgtype.UUID
+	FromTelegramID int64
+}
+
+func (q *Queries) TransferOwnedGift(ctx context.Context, arg TransferOwnedGiftParams) (Gift, error) {
+	row := q.db.QueryRow(ctx, transferOwnedGift, arg.ToTelegramID, arg.ID, arg.FromTelegramID)
 	var i Gift
 	err := row.Scan(
 		&i.ID,
@@ -939,24 +3096,23 @@ func (q *Queries) StakeGiftForGame(ctx context.Context, id pgtype.UUID) (Gift, e
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.WithdrawnAt,
+		&i.RelatedDuelID,
+		&i.LockedByType,
+		&i.LockedByID,
+		&i.LockExpiresAt,
 	)
 	return i, err
 }
 
-const updateGiftOwner = `-- name: UpdateGiftOwner :one
-UPDATE gifts 
-SET owner_telegram_id = $2, updated_at = NOW()
-WHERE id = $1
-RETURNING id, telegram_gift_id, collectible_id, owner_telegram_id, upgrade_message_id, title, slug, price, collection_id, model_id, backdrop_id, symbol_id, status, created_at, updated_at, withdrawn_at
+const unlistGift = `-- name: UnlistGift :one
+UPDATE gifts
+SET status = 'owned', updated_at = NOW()
+WHERE id = $1 AND status = 'listed'
+RETURNING id, telegram_gift_id, collectible_id, owner_telegram_id, upgrade_message_id, title, slug, price, collection_id, model_id, backdrop_id, symbol_id, status, created_at, updated_at, withdrawn_at, related_duel_id, locked_by_type, locked_by_id, lock_expires_at
 `
 
-type UpdateGiftOwnerParams struct {
-	ID              pgtype.UUID
-	OwnerTelegramID int64
-}
-
-func (q *Queries) UpdateGiftOwner(ctx context.Context, arg UpdateGiftOwnerParams) (Gift, error) {
-	row := q.db.QueryRow(ctx, updateGiftOwner, arg.ID, arg.OwnerTelegramID)
+func (q *Queries) UnlistGift(ctx context.Context, id pgtype.UUID) (Gift, error) {
+	row := q.db.QueryRow(ctx, unlistGift, id)
 	var i Gift
 	err := row.Scan(
 		&i.ID,
@@ -975,15 +3131,24 @@ func (q *Queries) UpdateGiftOwner(ctx context.Context, arg UpdateGiftOwnerParams
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.WithdrawnAt,
+		&i.RelatedDuelID,
+		&i.LockedByType,
+		&i.LockedByID,
+		&i.LockExpiresAt,
 	)
 	return i, err
 }
 
 const updateGiftStatus = `-- name: UpdateGiftStatus :one
 UPDATE gifts 
-SET status = $2, updated_at = NOW()
+SET status = $2,
+    related_duel_id = CASE WHEN $2 = 'in_game' THEN related_duel_id END,
+    locked_by_type = CASE WHEN $2 = 'in_game' THEN locked_by_type END,
+    locked_by_id = CASE WHEN $2 = 'in_game' THEN locked_by_id END,
+    lock_expires_at = CASE WHEN $2 = 'in_game' THEN lock_expires_at END,
+    updated_at = NOW()
 WHERE id = $1
-RETURNING id, telegram_gift_id, collectible_id, owner_telegram_id, upgrade_message_id, title, slug, price, collection_id, model_id, backdrop_id, symbol_id, status, created_at, updated_at, withdrawn_at
+RETURNING id, telegram_gift_id, collectible_id, owner_telegram_id, upgrade_message_id, title, slug, price, collection_id, model_id, backdrop_id, symbol_id, status, created_at, updated_at, withdrawn_at, related_duel_id, locked_by_type, locked_by_id, lock_expires_at
 `
 
 type UpdateGiftStatusParams struct {
@@ -1011,6 +3176,139 @@ func (q *Queries) UpdateGiftStatus(ctx context.Context, arg UpdateGiftStatusPara
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.WithdrawnAt,
+		&i.RelatedDuelID,
+		&i.LockedByType,
+		&i.LockedByID,
+		&i.LockExpiresAt,
+	)
+	return i, err
+}
+
+const updateWithdrawalGiftStatus = `-- name: UpdateWithdrawalGiftStatus :one
+UPDATE withdrawal_gifts
+SET status = $1,
+    failure_reason = $2,
+    updated_at = NOW()
+WHERE withdrawal_id = $3 AND gift_id = $4
+RETURNING withdrawal_id, gift_id, commission_amount, status, failure_reason, updated_at
+`
+
+type UpdateWithdrawalGiftStatusParams struct {
+	Status        WithdrawalGiftStatus
+	FailureReason pgtype.Text
+	WithdrawalID  pgtype.UUID
+	GiftID        pgtype.UUID
+}
+
+func (q *Queries) UpdateWithdrawalGiftStatus(ctx context.Context, arg UpdateWithdrawalGiftStatusParams) (WithdrawalGift, error) {
+	row := q.db.QueryRow(ctx, updateWithdrawalGiftStatus,
+		arg.Status,
+		arg.FailureReason,
+		arg.WithdrawalID,
+		arg.GiftID,
+	)
+	var i WithdrawalGift
+	err := row.Scan(
+		&i.WithdrawalID,
+		&i.GiftID,
+		&i.CommissionAmount,
+		&i.Status,
+		&i.FailureReason,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateWithdrawalStatus = `-- name: UpdateWithdrawalStatus :one
+UPDATE withdrawals
+SET status = $1,
+    failure_reason = $2,
+    completed_at = CASE WHEN $1 = 'completed' THEN COALESCE(completed_at, NOW()) END,
+    failed_at = CASE WHEN $1 IN ('failed', 'refunded') THEN COALESCE(failed_at, NOW()) END,
+    updated_at = NOW()
+WHERE id = $3
+RETURNING id, telegram_user_id, commission_currency, commission_amount, status, failure_reason, intent_id, completed_at, failed_at, created_at, updated_at
+`
+
+type UpdateWithdrawalStatusParams struct {
+	Status        WithdrawalStatus
+	FailureReason pgtype.Text
+	ID            pgtype.UUID
+}
+
+func (q *Queries) UpdateWithdrawalStatus(ctx context.Context, arg UpdateWithdrawalStatusParams) (Withdrawal, error) {
+	row := q.db.QueryRow(ctx, updateWithdrawalStatus,
+		arg.Status,
+		arg.FailureReason,
+		arg.ID,
+	)
+	var i Withdrawal
+	err := row.Scan(
+		&i.ID,
+		&i.TelegramUserID,
+		&i.CommissionCurrency,
+		&i.CommissionAmount,
+		&i.Status,
+		&i.FailureReason,
+		&i.IntentID,
+		&i.CompletedAt,
+		&i.FailedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertPortfolioSnapshots = `-- name: UpsertPortfolioSnapshots :execrows
+INSERT INTO portfolio_snapshots (
+  telegram_user_id, snapshot_date, owned_value, in_game_value, owned_count, in_game_count
+)
+SELECT
+  owner_telegram_id,
+  $1::date,
+  COALESCE(SUM(price) FILTER (WHERE status IN ('owned', 'listed', 'withdraw_pending')), 0),
+  COALESCE(SUM(price) FILTER (WHERE status = 'in_game'), 0),
+  COUNT(*) FILTER (WHERE status IN ('owned', 'listed', 'withdraw_pending')),
+  COUNT(*) FILTER (WHERE status = 'in_game')
+FROM gifts
+GROUP BY owner_telegram_id
+ON CONFLICT (telegram_user_id, snapshot_date) DO UPDATE SET
+  owned_value   = EXCLUDED.owned_value,
+  in_game_value = EXCLUDED.in_game_value,
+  owned_count   = EXCLUDED.owned_count,
+  in_game_count = EXCLUDED.in_game_count,
+  updated_at    = NOW()
+`
+
+func (q *Queries) UpsertPortfolioSnapshots(ctx context.Context, snapshotDate pgtype.Date) (int64, error) {
+	result, err := q.db.Exec(ctx, upsertPortfolioSnapshots, snapshotDate)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const upsertStakePreset = `-- name: UpsertStakePreset :one
+INSERT INTO stake_presets (telegram_user_id, name)
+VALUES ($1, $2)
+ON CONFLICT (telegram_user_id, name) DO UPDATE SET updated_at = NOW()
+RETURNING id, telegram_user_id, name, created_at, updated_at
+`
+
+type UpsertStakePresetParams struct {
+	TelegramUserID int64
+	Name           string
+}
+
+func (q *Queries) UpsertStakePreset(ctx context.Context, arg UpsertStakePresetParams) (StakePreset, error) {
+	row := q.db.QueryRow(ctx, upsertStakePreset, arg.TelegramUserID, arg.Name)
+	var i StakePreset
+	err := row.Scan(
+		&i.ID,
+		&i.TelegramUserID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
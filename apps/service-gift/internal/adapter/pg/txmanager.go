@@ -7,6 +7,7 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/peterparker2005/giftduels/packages/inbox"
 )
 
 type TxManager interface {
@@ -22,7 +23,13 @@ type pgxTxManager struct {
 	pool *pgxpool.Pool
 }
 
+// BeginTx открывает транзакцию. Внутри обработчика события транзакция
+// вкладывается в транзакцию inbox (savepoint), чтобы изменения фиксировались
+// вместе с отметкой об обработке сообщения.
 func (m *pgxTxManager) BeginTx(ctx context.Context) (pgx.Tx, error) {
+	if tx, ok := inbox.TxFromContext(ctx); ok {
+		return tx.Begin(ctx)
+	}
 	return m.pool.Begin(ctx)
 }
 
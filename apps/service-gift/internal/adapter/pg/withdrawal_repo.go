@@ -0,0 +1,328 @@
+package pg
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/adapter/pg/sqlc"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/withdrawal"
+	"github.com/peterparker2005/giftduels/packages/inbox"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+)
+
+type WithdrawalRepository struct {
+	pool   *pgxpool.Pool
+	q      *sqlc.Queries
+	logger *logger.Logger
+}
+
+func NewWithdrawalRepo(pool *pgxpool.Pool, logger *logger.Logger) withdrawal.Repository {
+	return &WithdrawalRepository{pool: pool, q: sqlc.New(inbox.NewDB(pool)), logger: logger}
+}
+
+func (r *WithdrawalRepository) WithTx(tx pgx.Tx) withdrawal.Repository {
+	return &WithdrawalRepository{pool: r.pool, q: r.q.WithTx(tx), logger: r.logger}
+}
+
+func (r *WithdrawalRepository) CreateIntent(
+	ctx context.Context,
+	params *withdrawal.CreateIntentParams,
+) (*withdrawal.Intent, error) {
+	dbIntent, err := r.q.CreateWithdrawalIntent(ctx, sqlc.CreateWithdrawalIntentParams{
+		TelegramUserID: params.TelegramUserID,
+		GiftIds:        mustPgUUIDs(params.GiftIDs),
+		StarsAmount:    params.StarsAmount,
+		InvoiceUrl:     params.InvoiceURL,
+		ExpiresAt:      timeToPgTimestamp(params.ExpiresAt),
+	})
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	return WithdrawalIntentToDomain(dbIntent), nil
+}
+
+func (r *WithdrawalRepository) GetIntentByID(
+	ctx context.Context,
+	id string,
+) (*withdrawal.Intent, error) {
+	dbIntent, err := r.q.GetWithdrawalIntentByID(ctx, mustPgUUID(id))
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	return WithdrawalIntentToDomain(dbIntent), nil
+}
+
+func (r *WithdrawalRepository) HasPendingIntentForGifts(
+	ctx context.Context,
+	giftIDs []string,
+) (bool, error) {
+	exists, err := r.q.HasPendingWithdrawalIntentForGifts(ctx, mustPgUUIDs(giftIDs))
+	if err != nil {
+		return false, MapPGError(err)
+	}
+	return exists, nil
+}
+
+func (r *WithdrawalRepository) GetLatestIntentByGiftsForUpdate(
+	ctx context.Context,
+	telegramUserID int64,
+	giftIDs []string,
+) (*withdrawal.Intent, error) {
+	dbIntent, err := r.q.GetLatestWithdrawalIntentByGiftsForUpdate(
+		ctx,
+		sqlc.GetLatestWithdrawalIntentByGiftsForUpdateParams{
+			TelegramUserID: telegramUserID,
+			GiftIds:        mustPgUUIDs(giftIDs),
+		},
+	)
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	return WithdrawalIntentToDomain(dbIntent), nil
+}
+
+func (r *WithdrawalRepository) GetExpiredIntentsForUpdate(
+	ctx context.Context,
+	now time.Time,
+	limit int32,
+) ([]*withdrawal.Intent, error) {
+	rows, err := r.q.GetExpiredWithdrawalIntentsForUpdate(
+		ctx,
+		sqlc.GetExpiredWithdrawalIntentsForUpdateParams{
+			Now:   timeToPgTimestamp(now),
+			Limit: limit,
+		},
+	)
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+
+	intents := make([]*withdrawal.Intent, len(rows))
+	for i, row := range rows {
+		intents[i] = WithdrawalIntentToDomain(row)
+	}
+	return intents, nil
+}
+
+func (r *WithdrawalRepository) MarkIntentCompleted(
+	ctx context.Context,
+	id string,
+) (*withdrawal.Intent, error) {
+	dbIntent, err := r.q.MarkWithdrawalIntentCompleted(ctx, mustPgUUID(id))
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	return WithdrawalIntentToDomain(dbIntent), nil
+}
+
+func (r *WithdrawalRepository) MarkIntentExpired(
+	ctx context.Context,
+	id string,
+) (*withdrawal.Intent, error) {
+	dbIntent, err := r.q.MarkWithdrawalIntentExpired(ctx, mustPgUUID(id))
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	return WithdrawalIntentToDomain(dbIntent), nil
+}
+
+func (r *WithdrawalRepository) MarkIntentPaidAfterExpiry(
+	ctx context.Context,
+	id string,
+) (*withdrawal.Intent, error) {
+	dbIntent, err := r.q.MarkWithdrawalIntentPaidAfterExpiry(ctx, mustPgUUID(id))
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	return WithdrawalIntentToDomain(dbIntent), nil
+}
+
+func mustPgUUIDs(ids []string) []pgtype.UUID {
+	pgUUIDs := make([]pgtype.UUID, len(ids))
+	for i, id := range ids {
+		pgUUIDs[i] = mustPgUUID(id)
+	}
+	return pgUUIDs
+}
+
+func (r *WithdrawalRepository) CreateWithdrawal(
+	ctx context.Context,
+	w *withdrawal.Withdrawal,
+) (*withdrawal.Withdrawal, error) {
+	commission, err := pgNumeric(w.CommissionAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	intentID := pgtype.UUID{}
+	if w.IntentID != nil {
+		intentID = mustPgUUID(*w.IntentID)
+	}
+
+	dbWithdrawal, err := r.q.CreateWithdrawal(ctx, sqlc.CreateWithdrawalParams{
+		TelegramUserID:     w.TelegramUserID,
+		CommissionCurrency: sqlc.WithdrawalCommissionCurrency(w.CommissionCurrency),
+		CommissionAmount:   commission,
+		Status:             sqlc.WithdrawalStatus(w.Status),
+		IntentID:           intentID,
+	})
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+
+	dbGifts := make([]sqlc.WithdrawalGift, len(w.Gifts))
+	for i, g := range w.Gifts {
+		giftCommission, pErr := pgNumeric(g.CommissionAmount)
+		if pErr != nil {
+			return nil, pErr
+		}
+		dbGifts[i], err = r.q.CreateWithdrawalGift(ctx, sqlc.CreateWithdrawalGiftParams{
+			WithdrawalID:     dbWithdrawal.ID,
+			GiftID:           mustPgUUID(g.GiftID),
+			CommissionAmount: giftCommission,
+		})
+		if err != nil {
+			return nil, MapPGError(err)
+		}
+	}
+
+	return WithdrawalToDomain(dbWithdrawal, dbGifts)
+}
+
+func (r *WithdrawalRepository) GetWithdrawalByID(
+	ctx context.Context,
+	id string,
+) (*withdrawal.Withdrawal, error) {
+	dbWithdrawal, err := r.q.GetWithdrawalByID(ctx, mustPgUUID(id))
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	return r.loadWithdrawal(ctx, dbWithdrawal)
+}
+
+func (r *WithdrawalRepository) GetWithdrawalByIntentIDForUpdate(
+	ctx context.Context,
+	intentID string,
+) (*withdrawal.Withdrawal, error) {
+	dbWithdrawal, err := r.q.GetWithdrawalByIntentIDForUpdate(ctx, mustPgUUID(intentID))
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	return r.loadWithdrawal(ctx, dbWithdrawal)
+}
+
+func (r *WithdrawalRepository) GetActiveWithdrawalByGiftForUpdate(
+	ctx context.Context,
+	giftID string,
+) (*withdrawal.Withdrawal, error) {
+	dbWithdrawal, err := r.q.GetActiveWithdrawalByGiftForUpdate(ctx, mustPgUUID(giftID))
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	return r.loadWithdrawal(ctx, dbWithdrawal)
+}
+
+func (r *WithdrawalRepository) GetUserWithdrawals(
+	ctx context.Context,
+	telegramUserID int64,
+	statuses []withdrawal.Status,
+	limit, offset int32,
+) (*withdrawal.GetUserWithdrawalsResult, error) {
+	statusValues := make([]string, len(statuses))
+	for i, s := range statuses {
+		statusValues[i] = string(s)
+	}
+
+	total, err := r.q.GetUserWithdrawalsCount(ctx, sqlc.GetUserWithdrawalsCountParams{
+		TelegramUserID: telegramUserID,
+		Statuses:       statusValues,
+	})
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+
+	rows, err := r.q.GetUserWithdrawals(ctx, sqlc.GetUserWithdrawalsParams{
+		TelegramUserID: telegramUserID,
+		Statuses:       statusValues,
+		Limit:          limit,
+		Offset:         offset,
+	})
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+
+	ids := make([]pgtype.UUID, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+	}
+	dbGifts, err := r.q.GetWithdrawalGiftsByWithdrawalIDs(ctx, ids)
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+
+	giftsByWithdrawal := make(map[string][]sqlc.WithdrawalGift, len(rows))
+	for _, g := range dbGifts {
+		key := pgUUIDToString(g.WithdrawalID)
+		giftsByWithdrawal[key] = append(giftsByWithdrawal[key], g)
+	}
+
+	out := make([]*withdrawal.Withdrawal, len(rows))
+	for i, row := range rows {
+		out[i], err = WithdrawalToDomain(row, giftsByWithdrawal[pgUUIDToString(row.ID)])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &withdrawal.GetUserWithdrawalsResult{
+		Withdrawals: out,
+		Total:       total,
+	}, nil
+}
+
+func (r *WithdrawalRepository) UpdateWithdrawalStatus(
+	ctx context.Context,
+	w *withdrawal.Withdrawal,
+) (*withdrawal.Withdrawal, error) {
+	dbWithdrawal, err := r.q.UpdateWithdrawalStatus(ctx, sqlc.UpdateWithdrawalStatusParams{
+		Status:        sqlc.WithdrawalStatus(w.Status),
+		FailureReason: stringPtrToPgText(w.FailureReason),
+		ID:            mustPgUUID(w.ID),
+	})
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	return r.loadWithdrawal(ctx, dbWithdrawal)
+}
+
+func (r *WithdrawalRepository) UpdateWithdrawalGiftStatus(
+	ctx context.Context,
+	withdrawalID string,
+	g *withdrawal.Gift,
+) error {
+	_, err := r.q.UpdateWithdrawalGiftStatus(ctx, sqlc.UpdateWithdrawalGiftStatusParams{
+		Status:        sqlc.WithdrawalGiftStatus(g.Status),
+		FailureReason: stringPtrToPgText(g.FailureReason),
+		WithdrawalID:  mustPgUUID(withdrawalID),
+		GiftID:        mustPgUUID(g.GiftID),
+	})
+	if err != nil {
+		return MapPGError(err)
+	}
+	return nil
+}
+
+// loadWithdrawal подгружает подарки вывода.
+func (r *WithdrawalRepository) loadWithdrawal(
+	ctx context.Context,
+	dbWithdrawal sqlc.Withdrawal,
+) (*withdrawal.Withdrawal, error) {
+	dbGifts, err := r.q.GetWithdrawalGiftsByWithdrawalIDs(ctx, []pgtype.UUID{dbWithdrawal.ID})
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	return WithdrawalToDomain(dbWithdrawal, dbGifts)
+}
@@ -1,9 +1,19 @@
 package proto
 
 import (
+	"fmt"
+
+	"github.com/ccoveille/go-safecast"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/catalog"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/favorite"
 	"github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/gift"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/listing"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/portfolio"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/withdrawal"
+	duelv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/duel/v1"
 	giftv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/gift/v1"
 	sharedv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/shared/v1"
+	"github.com/peterparker2005/giftduels/packages/tonamount-go"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -150,7 +160,592 @@ func DomainStatusToProto(domainStatus gift.Status) giftv1.GiftStatus {
 		return giftv1.GiftStatus_GIFT_STATUS_IN_GAME
 	case gift.StatusWithdrawPending:
 		return giftv1.GiftStatus_GIFT_STATUS_WITHDRAW_PENDING
+	case gift.StatusListed:
+		return giftv1.GiftStatus_GIFT_STATUS_LISTED
 	default:
 		return giftv1.GiftStatus_GIFT_STATUS_UNSPECIFIED
 	}
 }
+
+// DomainListingToProto преобразует domain Listing и его подарок в protobuf Listing.
+func DomainListingToProto(l *listing.Listing, g *gift.Gift) *giftv1.Listing {
+	protoListing := &giftv1.Listing{
+		ListingId:        &sharedv1.ListingId{Value: l.ID},
+		Gift:             DomainGiftToProtoView(g),
+		SellerTelegramId: &sharedv1.TelegramUserId{Value: l.SellerTelegramID},
+		Price:            &sharedv1.TonAmount{Value: l.Price.String()},
+		Status:           DomainListingStatusToProto(l.Status),
+		CreatedAt:        timestamppb.New(l.CreatedAt),
+	}
+
+	if l.SoldAt != nil {
+		protoListing.SoldAt = timestamppb.New(*l.SoldAt)
+	}
+
+	return protoListing
+}
+
+// DomainListingStatusToProto преобразует domain статус лота в protobuf статус.
+func DomainListingStatusToProto(status listing.Status) giftv1.ListingStatus {
+	switch status {
+	case listing.StatusActive:
+		return giftv1.ListingStatus_LISTING_STATUS_ACTIVE
+	case listing.StatusSold:
+		return giftv1.ListingStatus_LISTING_STATUS_SOLD
+	case listing.StatusCancelled:
+		return giftv1.ListingStatus_LISTING_STATUS_CANCELLED
+	default:
+		return giftv1.ListingStatus_LISTING_STATUS_UNSPECIFIED
+	}
+}
+
+// ProtoListingFilterToDomain преобразует protobuf фильтр лотов в domain Filter.
+func ProtoListingFilterToDomain(f *giftv1.GetListingsRequest_Filter) (*listing.Filter, error) {
+	if f == nil {
+		return &listing.Filter{}, nil
+	}
+
+	filter := &listing.Filter{
+		Collection: f.Collection,
+		Model:      f.Model,
+		Backdrop:   f.Backdrop,
+	}
+
+	if f.GetMinPrice() != nil {
+		minPrice, err := tonamount.NewTonAmountFromString(f.GetMinPrice().GetValue())
+		if err != nil {
+			return nil, err
+		}
+		filter.MinPrice = minPrice
+	}
+
+	if f.GetMaxPrice() != nil {
+		maxPrice, err := tonamount.NewTonAmountFromString(f.GetMaxPrice().GetValue())
+		if err != nil {
+			return nil, err
+		}
+		filter.MaxPrice = maxPrice
+	}
+
+	return filter, nil
+}
+
+// DomainEventToProto преобразует domain Event в protobuf GiftEvent.
+func DomainEventToProto(e *gift.Event) *giftv1.GiftEvent {
+	protoEvent := &giftv1.GiftEvent{
+		EventId:        e.ID,
+		GiftId:         &sharedv1.GiftId{Value: e.GiftID},
+		TelegramUserId: &sharedv1.TelegramUserId{Value: e.TelegramUserID},
+		OccurredAt:     timestamppb.New(e.OccurredAt),
+		Action:         DomainEventTypeToProto(e.EventType),
+	}
+
+	if e.RelatedGameID != nil {
+		protoEvent.RelatedGameId = *e.RelatedGameID
+		protoEvent.GameMode = sharedv1.GameMode_GAME_MODE_DUEL
+	}
+
+	return protoEvent
+}
+
+// DomainEventTypeToProto преобразует domain тип события в protobuf action.
+func DomainEventTypeToProto(t gift.EventType) giftv1.GiftEventAction {
+	switch t {
+	case gift.EventTypeDeposit:
+		return giftv1.GiftEventAction_GIFT_EVENT_ACTION_DEPOSIT
+	case gift.EventTypeStake:
+		return giftv1.GiftEventAction_GIFT_EVENT_ACTION_STAKE
+	case gift.EventTypeReturnFromGame:
+		return giftv1.GiftEventAction_GIFT_EVENT_ACTION_GAME_WIN
+	case gift.EventTypeWithdrawRequest:
+		return giftv1.GiftEventAction_GIFT_EVENT_ACTION_WITHDRAW_REQUEST
+	case gift.EventTypeWithdrawComplete:
+		return giftv1.GiftEventAction_GIFT_EVENT_ACTION_WITHDRAW
+	case gift.EventTypeWithdrawFail:
+		return giftv1.GiftEventAction_GIFT_EVENT_ACTION_WITHDRAW_FAIL
+	case gift.EventTypeList:
+		return giftv1.GiftEventAction_GIFT_EVENT_ACTION_LIST
+	case gift.EventTypeUnlist:
+		return giftv1.GiftEventAction_GIFT_EVENT_ACTION_UNLIST
+	case gift.EventTypeSale:
+		return giftv1.GiftEventAction_GIFT_EVENT_ACTION_SALE
+	case gift.EventTypeSellBack:
+		return giftv1.GiftEventAction_GIFT_EVENT_ACTION_SELL_BACK
+	default:
+		return giftv1.GiftEventAction_GIFT_EVENT_ACTION_UNSPECIFIED
+	}
+}
+
+// ProtoEventActionsToDomain преобразует protobuf фильтр по action в domain EventFilter.
+// Для action, которым не соответствует ни один тип события, возвращается ошибка.
+func ProtoEventActionsToDomain(actions []giftv1.GiftEventAction) (gift.EventFilter, error) {
+	filter := gift.EventFilter{
+		EventTypes: make([]gift.EventType, 0, len(actions)),
+	}
+	for _, a := range actions {
+		var t gift.EventType
+		switch a {
+		case giftv1.GiftEventAction_GIFT_EVENT_ACTION_DEPOSIT:
+			t = gift.EventTypeDeposit
+		case giftv1.GiftEventAction_GIFT_EVENT_ACTION_STAKE:
+			t = gift.EventTypeStake
+		case giftv1.GiftEventAction_GIFT_EVENT_ACTION_GAME_WIN:
+			t = gift.EventTypeReturnFromGame
+		case giftv1.GiftEventAction_GIFT_EVENT_ACTION_WITHDRAW_REQUEST:
+			t = gift.EventTypeWithdrawRequest
+		case giftv1.GiftEventAction_GIFT_EVENT_ACTION_WITHDRAW:
+			t = gift.EventTypeWithdrawComplete
+		case giftv1.GiftEventAction_GIFT_EVENT_ACTION_WITHDRAW_FAIL:
+			t = gift.EventTypeWithdrawFail
+		case giftv1.GiftEventAction_GIFT_EVENT_ACTION_LIST:
+			t = gift.EventTypeList
+		case giftv1.GiftEventAction_GIFT_EVENT_ACTION_UNLIST:
+			t = gift.EventTypeUnlist
+		case giftv1.GiftEventAction_GIFT_EVENT_ACTION_SALE:
+			t = gift.EventTypeSale
+		case giftv1.GiftEventAction_GIFT_EVENT_ACTION_SELL_BACK:
+			t = gift.EventTypeSellBack
+		case giftv1.GiftEventAction_GIFT_EVENT_ACTION_UNSPECIFIED,
+			giftv1.GiftEventAction_GIFT_EVENT_ACTION_PURCHASE,
+			giftv1.GiftEventAction_GIFT_EVENT_ACTION_GAME_LOSE,
+			giftv1.GiftEventAction_GIFT_EVENT_ACTION_REFUND:
+			return gift.EventFilter{}, fmt.Errorf("%w: %s", gift.ErrUnsupportedEventType, a)
+		default:
+			return gift.EventFilter{}, fmt.Errorf("%w: %s", gift.ErrUnsupportedEventType, a)
+		}
+		filter.EventTypes = append(filter.EventTypes, t)
+	}
+	return filter, nil
+}
+
+// ProtoStatusToDomain преобразует protobuf статус в domain статус.
+func ProtoStatusToDomain(status giftv1.GiftStatus) (gift.Status, error) {
+	switch status {
+	case giftv1.GiftStatus_GIFT_STATUS_OWNED:
+		return gift.StatusOwned, nil
+	case giftv1.GiftStatus_GIFT_STATUS_WITHDRAWN:
+		return gift.StatusWithdrawn, nil
+	case giftv1.GiftStatus_GIFT_STATUS_IN_GAME:
+		return gift.StatusInGame, nil
+	case giftv1.GiftStatus_GIFT_STATUS_WITHDRAW_PENDING:
+		return gift.StatusWithdrawPending, nil
+	case giftv1.GiftStatus_GIFT_STATUS_LISTED:
+		return gift.StatusListed, nil
+	case giftv1.GiftStatus_GIFT_STATUS_UNSPECIFIED:
+		return "", fmt.Errorf("unsupported gift status: %s", status)
+	default:
+		return "", fmt.Errorf("unsupported gift status: %s", status)
+	}
+}
+
+// ProtoInventoryFilterToDomain преобразует protobuf фильтр инвентаря в domain InventoryFilter.
+func ProtoInventoryFilterToDomain(f *giftv1.GetGiftsRequest_Filter) (*gift.InventoryFilter, error) {
+	if f == nil {
+		return &gift.InventoryFilter{}, nil
+	}
+
+	filter := &gift.InventoryFilter{
+		Statuses:   make([]gift.Status, 0, len(f.GetStatuses())),
+		Collection: f.Collection,
+		Model:      f.Model,
+		Backdrop:   f.Backdrop,
+		Symbol:     f.Symbol,
+		Search:     f.Search,
+	}
+
+	for _, st := range f.GetStatuses() {
+		status, err := ProtoStatusToDomain(st)
+		if err != nil {
+			return nil, err
+		}
+		filter.Statuses = append(filter.Statuses, status)
+	}
+
+	if f.GetMinPrice() != nil {
+		minPrice, err := tonamount.NewTonAmountFromString(f.GetMinPrice().GetValue())
+		if err != nil {
+			return nil, err
+		}
+		filter.MinPrice = minPrice
+	}
+
+	if f.GetMaxPrice() != nil {
+		maxPrice, err := tonamount.NewTonAmountFromString(f.GetMaxPrice().GetValue())
+		if err != nil {
+			return nil, err
+		}
+		filter.MaxPrice = maxPrice
+	}
+
+	return filter, nil
+}
+
+// ProtoInventorySortToDomain преобразует protobuf сортировку инвентаря в domain InventorySort.
+// По умолчанию сортировка идёт по убыванию.
+func ProtoInventorySortToDomain(
+	sortBy giftv1.GetGiftsRequest_SortBy,
+	order sharedv1.SortOrder,
+) gift.InventorySort {
+	sort := gift.InventorySort{
+		By:   gift.InventorySortByUpdatedAt,
+		Desc: order != sharedv1.SortOrder_SORT_ORDER_ASC,
+	}
+
+	switch sortBy {
+	case giftv1.GetGiftsRequest_SORT_BY_PRICE:
+		sort.By = gift.InventorySortByPrice
+	case giftv1.GetGiftsRequest_SORT_BY_RARITY:
+		sort.By = gift.InventorySortByRarity
+	case giftv1.GetGiftsRequest_SORT_BY_DEPOSIT_DATE:
+		sort.By = gift.InventorySortByDepositDate
+	case giftv1.GetGiftsRequest_SORT_BY_UNSPECIFIED:
+		sort.By = gift.InventorySortByUpdatedAt
+	}
+
+	return sort
+}
+
+// DomainInventorySummaryToProto преобразует domain InventorySummary в protobuf.
+func DomainInventorySummaryToProto(summary *gift.InventorySummary) (*giftv1.InventorySummary, error) {
+	totalCount, err := safecast.ToInt32(summary.TotalCount)
+	if err != nil {
+		return nil, err
+	}
+
+	protoSummary := &giftv1.InventorySummary{
+		TotalCount:  totalCount,
+		TotalValue:  &sharedv1.TonAmount{Value: summary.TotalValue.String()},
+		Collections: make([]*giftv1.InventorySummary_CollectionSummary, len(summary.Collections)),
+	}
+	for i, c := range summary.Collections {
+		count, err := safecast.ToInt32(c.Count)
+		if err != nil {
+			return nil, err
+		}
+		protoSummary.Collections[i] = &giftv1.InventorySummary_CollectionSummary{
+			Collection: c.Collection,
+			Count:      count,
+			TotalValue: &sharedv1.TonAmount{Value: c.TotalValue.String()},
+		}
+	}
+
+	return protoSummary, nil
+}
+
+// DomainWithdrawalIntentToProto преобразует domain Intent в protobuf StarsWithdrawalIntent.
+func DomainWithdrawalIntentToProto(
+	intent *withdrawal.Intent,
+) (*giftv1.StarsWithdrawalIntent, error) {
+	stars, err := safecast.ToUint32(intent.StarsAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	giftIDs := make([]*sharedv1.GiftId, len(intent.GiftIDs))
+	for i, id := range intent.GiftIDs {
+		giftIDs[i] = &sharedv1.GiftId{Value: id}
+	}
+
+	protoIntent := &giftv1.StarsWithdrawalIntent{
+		Id:          intent.ID,
+		Status:      DomainWithdrawalIntentStatusToProto(intent.Status),
+		GiftIds:     giftIDs,
+		StarsAmount: &sharedv1.StarsAmount{Value: stars},
+		InvoiceUrl:  intent.InvoiceURL,
+		ExpiresAt:   timestamppb.New(intent.ExpiresAt),
+		CreatedAt:   timestamppb.New(intent.CreatedAt),
+	}
+	if intent.CompletedAt != nil {
+		protoIntent.CompletedAt = timestamppb.New(*intent.CompletedAt)
+	}
+
+	return protoIntent, nil
+}
+
+// DomainWithdrawalIntentStatusToProto преобразует domain статус инвойса в protobuf статус.
+func DomainWithdrawalIntentStatusToProto(
+	status withdrawal.IntentStatus,
+) giftv1.StarsWithdrawalStatus {
+	switch status {
+	case withdrawal.IntentStatusPending:
+		return giftv1.StarsWithdrawalStatus_STARS_WITHDRAWAL_STATUS_PENDING
+	case withdrawal.IntentStatusCompleted:
+		return giftv1.StarsWithdrawalStatus_STARS_WITHDRAWAL_STATUS_COMPLETED
+	case withdrawal.IntentStatusExpired:
+		return giftv1.StarsWithdrawalStatus_STARS_WITHDRAWAL_STATUS_EXPIRED
+	case withdrawal.IntentStatusPaidAfterExpiry:
+		return giftv1.StarsWithdrawalStatus_STARS_WITHDRAWAL_STATUS_PAID_AFTER_EXPIRY
+	default:
+		return giftv1.StarsWithdrawalStatus_STARS_WITHDRAWAL_STATUS_UNSPECIFIED
+	}
+}
+
+// DomainWithdrawalToProto преобразует domain Withdrawal в protobuf Withdrawal.
+// giftsByID дополняет подарки вывода данными для отображения.
+func DomainWithdrawalToProto(
+	w *withdrawal.Withdrawal,
+	giftsByID map[string]*gift.Gift,
+) *giftv1.Withdrawal {
+	gifts := make([]*giftv1.WithdrawalGift, len(w.Gifts))
+	for i, g := range w.Gifts {
+		protoGift := &giftv1.WithdrawalGift{
+			GiftId:           &sharedv1.GiftId{Value: g.GiftID},
+			Status:           DomainWithdrawalGiftStatusToProto(g.Status),
+			CommissionAmount: g.CommissionAmount,
+			FailureReason:    g.FailureReason,
+		}
+		if domainGift, ok := giftsByID[g.GiftID]; ok {
+			protoGift.Gift = DomainGiftToProtoView(domainGift)
+		}
+		gifts[i] = protoGift
+	}
+
+	protoWithdrawal := &giftv1.Withdrawal{
+		Id:                 w.ID,
+		Status:             DomainWithdrawalStatusToProto(w.Status),
+		CommissionCurrency: DomainCommissionCurrencyToProto(w.CommissionCurrency),
+		CommissionAmount:   w.CommissionAmount,
+		Gifts:              gifts,
+		FailureReason:      w.FailureReason,
+		WithdrawalIntentId: w.IntentID,
+		CreatedAt:          timestamppb.New(w.CreatedAt),
+		UpdatedAt:          timestamppb.New(w.UpdatedAt),
+	}
+	if w.CompletedAt != nil {
+		protoWithdrawal.CompletedAt = timestamppb.New(*w.CompletedAt)
+	}
+	if w.FailedAt != nil {
+		protoWithdrawal.FailedAt = timestamppb.New(*w.FailedAt)
+	}
+
+	return protoWithdrawal
+}
+
+// DomainWithdrawalStatusToProto преобразует domain статус вывода в protobuf статус.
+func DomainWithdrawalStatusToProto(status withdrawal.Status) giftv1.WithdrawalStatus {
+	switch status {
+	case withdrawal.StatusRequested:
+		return giftv1.WithdrawalStatus_WITHDRAWAL_STATUS_REQUESTED
+	case withdrawal.StatusAwaitingPayment:
+		return giftv1.WithdrawalStatus_WITHDRAWAL_STATUS_AWAITING_PAYMENT
+	case withdrawal.StatusSentToBot:
+		return giftv1.WithdrawalStatus_WITHDRAWAL_STATUS_SENT_TO_BOT
+	case withdrawal.StatusCompleted:
+		return giftv1.WithdrawalStatus_WITHDRAWAL_STATUS_COMPLETED
+	case withdrawal.StatusFailed:
+		return giftv1.WithdrawalStatus_WITHDRAWAL_STATUS_FAILED
+	case withdrawal.StatusRefunded:
+		return giftv1.WithdrawalStatus_WITHDRAWAL_STATUS_REFUNDED
+	case withdrawal.StatusRefundRequired:
+		return giftv1.WithdrawalStatus_WITHDRAWAL_STATUS_REFUND_REQUIRED
+	default:
+		return giftv1.WithdrawalStatus_WITHDRAWAL_STATUS_UNSPECIFIED
+	}
+}
+
+// ProtoWithdrawalStatusesToDomain преобразует protobuf фильтр статусов в domain статусы.
+func ProtoWithdrawalStatusesToDomain(
+	statuses []giftv1.WithdrawalStatus,
+) ([]withdrawal.Status, error) {
+	out := make([]withdrawal.Status, 0, len(statuses))
+	for _, s := range statuses {
+		var status withdrawal.Status
+		switch s {
+		case giftv1.WithdrawalStatus_WITHDRAWAL_STATUS_REQUESTED:
+			status = withdrawal.StatusRequested
+		case giftv1.WithdrawalStatus_WITHDRAWAL_STATUS_AWAITING_PAYMENT:
+			status = withdrawal.StatusAwaitingPayment
+		case giftv1.WithdrawalStatus_WITHDRAWAL_STATUS_SENT_TO_BOT:
+			status = withdrawal.StatusSentToBot
+		case giftv1.WithdrawalStatus_WITHDRAWAL_STATUS_COMPLETED:
+			status = withdrawal.StatusCompleted
+		case giftv1.WithdrawalStatus_WITHDRAWAL_STATUS_FAILED:
+			status = withdrawal.StatusFailed
+		case giftv1.WithdrawalStatus_WITHDRAWAL_STATUS_REFUNDED:
+			status = withdrawal.StatusRefunded
+		case giftv1.WithdrawalStatus_WITHDRAWAL_STATUS_REFUND_REQUIRED:
+			status = withdrawal.StatusRefundRequired
+		case giftv1.WithdrawalStatus_WITHDRAWAL_STATUS_UNSPECIFIED:
+			return nil, fmt.Errorf("unsupported withdrawal status: %s", s)
+		default:
+			return nil, fmt.Errorf("unsupported withdrawal status: %s", s)
+		}
+		out = append(out, status)
+	}
+	return out, nil
+}
+
+// DomainWithdrawalGiftStatusToProto преобразует domain статус подарка в выводе в protobuf статус.
+func DomainWithdrawalGiftStatusToProto(status withdrawal.GiftStatus) giftv1.WithdrawalGiftStatus {
+	switch status {
+	case withdrawal.GiftStatusPending:
+		return giftv1.WithdrawalGiftStatus_WITHDRAWAL_GIFT_STATUS_PENDING
+	case withdrawal.GiftStatusWithdrawn:
+		return giftv1.WithdrawalGiftStatus_WITHDRAWAL_GIFT_STATUS_WITHDRAWN
+	case withdrawal.GiftStatusFailed:
+		return giftv1.WithdrawalGiftStatus_WITHDRAWAL_GIFT_STATUS_FAILED
+	case withdrawal.GiftStatusRefunded:
+		return giftv1.WithdrawalGiftStatus_WITHDRAWAL_GIFT_STATUS_REFUNDED
+	default:
+		return giftv1.WithdrawalGiftStatus_WITHDRAWAL_GIFT_STATUS_UNSPECIFIED
+	}
+}
+
+// DomainCommissionCurrencyToProto преобразует domain валюту комиссии в protobuf.
+func DomainCommissionCurrencyToProto(
+	currency withdrawal.CommissionCurrency,
+) giftv1.ExecuteWithdrawRequest_CommissionCurrency {
+	switch currency {
+	case withdrawal.CommissionCurrencyTON:
+		return giftv1.ExecuteWithdrawRequest_COMMISSION_CURRENCY_TON
+	case withdrawal.CommissionCurrencyStars:
+		return giftv1.ExecuteWithdrawRequest_COMMISSION_CURRENCY_STARS
+	default:
+		return giftv1.ExecuteWithdrawRequest_COMMISSION_CURRENCY_UNSPECIFIED
+	}
+}
+
+// DomainCatalogCollectionToProto преобразует запись каталога коллекций в protobuf.
+func DomainCatalogCollectionToProto(e *catalog.CollectionEntry) *giftv1.CatalogCollection {
+	return &giftv1.CatalogCollection{
+		Name:       e.Collection.Name,
+		ShortName:  e.Collection.ShortName,
+		FloorPrice: floorPriceToProto(e.FloorPrice),
+	}
+}
+
+// DomainCatalogModelToProto преобразует запись каталога моделей в protobuf.
+func DomainCatalogModelToProto(e *catalog.ModelEntry) *giftv1.CatalogModel {
+	return &giftv1.CatalogModel{
+		Model:          DomainModelToProto(e.Model),
+		ShortName:      e.Model.ShortName,
+		CollectionName: e.CollectionName,
+		FloorPrice:     floorPriceToProto(e.FloorPrice),
+	}
+}
+
+// DomainCatalogBackdropToProto преобразует запись каталога фонов в protobuf.
+func DomainCatalogBackdropToProto(e *catalog.BackdropEntry) *giftv1.CatalogBackdrop {
+	return &giftv1.CatalogBackdrop{
+		Backdrop:   DomainBackdropToProto(e.Backdrop),
+		ShortName:  e.Backdrop.ShortName,
+		FloorPrice: floorPriceToProto(e.FloorPrice),
+	}
+}
+
+// DomainCatalogSymbolToProto преобразует запись каталога узоров в protobuf.
+func DomainCatalogSymbolToProto(e *catalog.SymbolEntry) *giftv1.CatalogSymbol {
+	return &giftv1.CatalogSymbol{
+		Symbol:     DomainSymbolToProto(e.Symbol),
+		ShortName:  e.Symbol.ShortName,
+		FloorPrice: floorPriceToProto(e.FloorPrice),
+	}
+}
+
+func floorPriceToProto(price *tonamount.TonAmount) *sharedv1.TonAmount {
+	if price == nil {
+		return nil
+	}
+	return &sharedv1.TonAmount{Value: price.String()}
+}
+
+// DomainPortfolioValuationToProto преобразует domain Valuation в protobuf.
+func DomainPortfolioValuationToProto(v *portfolio.Valuation) (*giftv1.PortfolioValuation, error) {
+	ownedCount, err := safecast.ToInt32(v.OwnedCount)
+	if err != nil {
+		return nil, err
+	}
+	inGameCount, err := safecast.ToInt32(v.InGameCount)
+	if err != nil {
+		return nil, err
+	}
+
+	return &giftv1.PortfolioValuation{
+		OwnedValue:  &sharedv1.TonAmount{Value: v.OwnedValue.String()},
+		InGameValue: &sharedv1.TonAmount{Value: v.InGameValue.String()},
+		TotalValue:  &sharedv1.TonAmount{Value: v.TotalValue().String()},
+		OwnedCount:  ownedCount,
+		InGameCount: inGameCount,
+	}, nil
+}
+
+// DomainPortfolioPerformanceToProto преобразует domain Performance в protobuf.
+func DomainPortfolioPerformanceToProto(p *portfolio.Performance) *giftv1.PortfolioPerformance {
+	return &giftv1.PortfolioPerformance{
+		DepositedValue: &sharedv1.TonAmount{Value: p.DepositedValue.String()},
+		WithdrawnValue: &sharedv1.TonAmount{Value: p.WithdrawnValue.String()},
+		DuelWonValue:   &sharedv1.TonAmount{Value: p.DuelWonValue.String()},
+		DuelLostValue:  &sharedv1.TonAmount{Value: p.DuelLostValue.String()},
+		ProfitLoss:     &sharedv1.TonAmount{Value: p.ProfitLoss().String()},
+	}
+}
+
+// DomainPortfolioSnapshotToProto преобразует domain Snapshot в protobuf.
+func DomainPortfolioSnapshotToProto(s *portfolio.Snapshot) (*giftv1.PortfolioSnapshot, error) {
+	valuation, err := DomainPortfolioValuationToProto(&s.Valuation)
+	if err != nil {
+		return nil, err
+	}
+	return &giftv1.PortfolioSnapshot{
+		Date:      timestamppb.New(s.Date),
+		Valuation: valuation,
+	}, nil
+}
+
+// DomainStakePresetToProto преобразует domain Preset в protobuf.
+// Подарки, которых нет в giftsByID, отдаются без GiftView.
+func DomainStakePresetToProto(
+	p *favorite.Preset,
+	giftsByID map[string]*gift.Gift,
+) *giftv1.StakePreset {
+	items := make([]*giftv1.StakePreset_Item, len(p.GiftIDs))
+	for i, giftID := range p.GiftIDs {
+		item := &giftv1.StakePreset_Item{
+			GiftId: &sharedv1.GiftId{Value: giftID},
+		}
+		if g, ok := giftsByID[giftID]; ok {
+			item.Gift = DomainGiftToProtoView(g)
+		}
+		items[i] = item
+	}
+
+	return &giftv1.StakePreset{
+		Id:        p.ID,
+		Name:      p.Name,
+		Items:     items,
+		CreatedAt: timestamppb.New(p.CreatedAt),
+		UpdatedAt: timestamppb.New(p.UpdatedAt),
+	}
+}
+
+// ProtoEntryPriceRangeToStakeTarget преобразует диапазон входа в дуэль в цель подбора ставки.
+func ProtoEntryPriceRangeToStakeTarget(r *duelv1.EntryPriceRange) (gift.StakeTarget, error) {
+	if r.GetMinEntryPrice() == nil || r.GetMaxEntryPrice() == nil {
+		return gift.StakeTarget{}, gift.ErrInvalidStakeTarget
+	}
+	minPrice, err := tonamount.NewTonAmountFromString(r.GetMinEntryPrice().GetValue())
+	if err != nil {
+		return gift.StakeTarget{}, err
+	}
+	maxPrice, err := tonamount.NewTonAmountFromString(r.GetMaxEntryPrice().GetValue())
+	if err != nil {
+		return gift.StakeTarget{}, err
+	}
+	return gift.NewStakeTargetRange(minPrice, maxPrice)
+}
+
+// ProtoSuggestStakeTargetToDomain преобразует цель из SuggestStakeRequest.
+func ProtoSuggestStakeTargetToDomain(req *giftv1.SuggestStakeRequest) (gift.StakeTarget, error) {
+	switch {
+	case req.GetAmount() != nil:
+		amount, err := tonamount.NewTonAmountFromString(req.GetAmount().GetValue())
+		if err != nil {
+			return gift.StakeTarget{}, err
+		}
+		return gift.NewStakeTargetAmount(amount)
+	case req.GetEntryPriceRange() != nil:
+		return ProtoEntryPriceRangeToStakeTarget(req.GetEntryPriceRange())
+	default:
+		return gift.StakeTarget{}, gift.ErrInvalidStakeTarget
+	}
+}
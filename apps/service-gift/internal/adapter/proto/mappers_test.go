@@ -0,0 +1,275 @@
+package proto_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/adapter/proto"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/gift"
+	giftv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/gift/v1"
+	sharedv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/shared/v1"
+	"github.com/peterparker2005/giftduels/packages/tonamount-go"
+)
+
+func TestProtoEventActionsToDomain(t *testing.T) {
+	tests := []struct {
+		name    string
+		actions []giftv1.GiftEventAction
+		want    []gift.EventType
+		wantErr bool
+	}{
+		{
+			name: "no actions means no filter",
+			want: []gift.EventType{},
+		},
+		{
+			name: "several actions",
+			actions: []giftv1.GiftEventAction{
+				giftv1.GiftEventAction_GIFT_EVENT_ACTION_STAKE,
+				giftv1.GiftEventAction_GIFT_EVENT_ACTION_GAME_WIN,
+				giftv1.GiftEventAction_GIFT_EVENT_ACTION_WITHDRAW,
+			},
+			want: []gift.EventType{gift.EventTypeStake, gift.EventTypeReturnFromGame, gift.EventTypeWithdrawComplete},
+		},
+		{
+			name: "marketplace actions",
+			actions: []giftv1.GiftEventAction{
+				giftv1.GiftEventAction_GIFT_EVENT_ACTION_LIST,
+				giftv1.GiftEventAction_GIFT_EVENT_ACTION_UNLIST,
+				giftv1.GiftEventAction_GIFT_EVENT_ACTION_SALE,
+				giftv1.GiftEventAction_GIFT_EVENT_ACTION_SELL_BACK,
+			},
+			want: []gift.EventType{
+				gift.EventTypeList, gift.EventTypeUnlist, gift.EventTypeSale, gift.EventTypeSellBack,
+			},
+		},
+		{
+			// у этих action нет событий подарка; молча пропустить их значило
+			// бы вернуть историю без фильтра
+			name: "action without gift events",
+			actions: []giftv1.GiftEventAction{
+				giftv1.GiftEventAction_GIFT_EVENT_ACTION_STAKE,
+				giftv1.GiftEventAction_GIFT_EVENT_ACTION_PURCHASE,
+			},
+			wantErr: true,
+		},
+		{
+			name:    "unspecified action",
+			actions: []giftv1.GiftEventAction{giftv1.GiftEventAction_GIFT_EVENT_ACTION_UNSPECIFIED},
+			wantErr: true,
+		},
+		{
+			name:    "unknown enum value",
+			actions: []giftv1.GiftEventAction{giftv1.GiftEventAction(999)},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := proto.ProtoEventActionsToDomain(tt.actions)
+			if tt.wantErr {
+				if !errors.Is(err, gift.ErrUnsupportedEventType) {
+					t.Fatalf("expected ErrUnsupportedEventType, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(filter.EventTypes, tt.want) {
+				t.Errorf("event types %v, want %v", filter.EventTypes, tt.want)
+			}
+		})
+	}
+}
+
+// Каждый тип события переживает путь domain → proto → фильтр.
+func TestEventTypeRoundTrip(t *testing.T) {
+	for _, eventType := range []gift.EventType{
+		gift.EventTypeStake,
+		gift.EventTypeReturnFromGame,
+		gift.EventTypeDeposit,
+		gift.EventTypeWithdrawRequest,
+		gift.EventTypeWithdrawComplete,
+		gift.EventTypeWithdrawFail,
+		gift.EventTypeList,
+		gift.EventTypeUnlist,
+		gift.EventTypeSale,
+		gift.EventTypeSellBack,
+	} {
+		action := proto.DomainEventTypeToProto(eventType)
+		filter, err := proto.ProtoEventActionsToDomain([]giftv1.GiftEventAction{action})
+		if err != nil {
+			t.Errorf("%s -> %s: %v", eventType, action, err)
+			continue
+		}
+		if !slices.Equal(filter.EventTypes, []gift.EventType{eventType}) {
+			t.Errorf("%s -> %s -> %v", eventType, action, filter.EventTypes)
+		}
+	}
+}
+
+func TestProtoInventoryFilterToDomain(t *testing.T) {
+	collection := "Plush Pepe"
+
+	tests := []struct {
+		name         string
+		filter       *giftv1.GetGiftsRequest_Filter
+		wantStatuses []gift.Status
+		wantMin      string
+		wantMax      string
+		wantErr      bool
+	}{
+		{
+			name:         "nil filter",
+			wantStatuses: []gift.Status{gift.StatusOwned, gift.StatusInGame},
+		},
+		{
+			name: "statuses and prices",
+			filter: &giftv1.GetGiftsRequest_Filter{
+				Statuses: []giftv1.GiftStatus{
+					giftv1.GiftStatus_GIFT_STATUS_LISTED,
+					giftv1.GiftStatus_GIFT_STATUS_WITHDRAW_PENDING,
+				},
+				Collection: &collection,
+				MinPrice:   &sharedv1.TonAmount{Value: "1.5"},
+				MaxPrice:   &sharedv1.TonAmount{Value: "20"},
+			},
+			wantStatuses: []gift.Status{gift.StatusListed, gift.StatusWithdrawPending},
+			wantMin:      "1.5",
+			wantMax:      "20",
+		},
+		{
+			name: "unspecified status",
+			filter: &giftv1.GetGiftsRequest_Filter{
+				Statuses: []giftv1.GiftStatus{giftv1.GiftStatus_GIFT_STATUS_UNSPECIFIED},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "malformed price",
+			filter:  &giftv1.GetGiftsRequest_Filter{MinPrice: &sharedv1.TonAmount{Value: "cheap"}},
+			wantErr: true,
+		},
+		{
+			name:    "negative price",
+			filter:  &giftv1.GetGiftsRequest_Filter{MaxPrice: &sharedv1.TonAmount{Value: "-1"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := proto.ProtoInventoryFilterToDomain(tt.filter)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got filter %+v", filter)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := filter.StatusesOrDefault(); !slices.Equal(got, tt.wantStatuses) {
+				t.Errorf("statuses %v, want %v", got, tt.wantStatuses)
+			}
+			if got := amountString(filter.MinPrice); got != tt.wantMin {
+				t.Errorf("min price %q, want %q", got, tt.wantMin)
+			}
+			if got := amountString(filter.MaxPrice); got != tt.wantMax {
+				t.Errorf("max price %q, want %q", got, tt.wantMax)
+			}
+			if tt.filter.GetCollection() != "" && *filter.Collection != tt.filter.GetCollection() {
+				t.Errorf("collection %q, want %q", *filter.Collection, tt.filter.GetCollection())
+			}
+		})
+	}
+}
+
+func amountString(a *tonamount.TonAmount) string {
+	if a == nil {
+		return ""
+	}
+	return a.String()
+}
+
+func TestProtoInventorySortToDomain(t *testing.T) {
+	tests := []struct {
+		name   string
+		sortBy giftv1.GetGiftsRequest_SortBy
+		order  sharedv1.SortOrder
+		want   gift.InventorySort
+	}{
+		{
+			name: "defaults to recently updated first",
+			want: gift.InventorySort{By: gift.InventorySortByUpdatedAt, Desc: true},
+		},
+		{
+			name:   "price ascending",
+			sortBy: giftv1.GetGiftsRequest_SORT_BY_PRICE,
+			order:  sharedv1.SortOrder_SORT_ORDER_ASC,
+			want:   gift.InventorySort{By: gift.InventorySortByPrice},
+		},
+		{
+			name:   "rarity descending",
+			sortBy: giftv1.GetGiftsRequest_SORT_BY_RARITY,
+			order:  sharedv1.SortOrder_SORT_ORDER_DESC,
+			want:   gift.InventorySort{By: gift.InventorySortByRarity, Desc: true},
+		},
+		{
+			name:   "deposit date",
+			sortBy: giftv1.GetGiftsRequest_SORT_BY_DEPOSIT_DATE,
+			want:   gift.InventorySort{By: gift.InventorySortByDepositDate, Desc: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := proto.ProtoInventorySortToDomain(tt.sortBy, tt.order); got != tt.want {
+				t.Errorf("sort %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDomainInventorySummaryToProto(t *testing.T) {
+	summary := &gift.InventorySummary{
+		TotalCount: 3,
+		TotalValue: mustTon(t, "12.5"),
+		Collections: []gift.CollectionSummary{
+			{Collection: "Plush Pepe", Count: 2, TotalValue: mustTon(t, "10")},
+			{Collection: "Lol Pop", Count: 1, TotalValue: mustTon(t, "2.5")},
+		},
+	}
+
+	got, err := proto.DomainInventorySummaryToProto(summary)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.GetTotalCount() != 3 || got.GetTotalValue().GetValue() != "12.5" {
+		t.Errorf("totals %d / %s, want 3 / 12.5", got.GetTotalCount(), got.GetTotalValue().GetValue())
+	}
+	if len(got.GetCollections()) != 2 ||
+		got.GetCollections()[0].GetCollection() != "Plush Pepe" ||
+		got.GetCollections()[0].GetCount() != 2 ||
+		got.GetCollections()[1].GetTotalValue().GetValue() != "2.5" {
+		t.Errorf("collections %v", got.GetCollections())
+	}
+
+	// счётчики не молча обрезаются до int32
+	summary.TotalCount = 1 << 40
+	if _, err = proto.DomainInventorySummaryToProto(summary); err == nil {
+		t.Error("expected overflow error")
+	}
+}
+
+func mustTon(t *testing.T, s string) *tonamount.TonAmount {
+	t.Helper()
+	a, err := tonamount.NewTonAmountFromString(s)
+	if err != nil {
+		t.Fatalf("cannot parse TonAmount from %q: %v", s, err)
+	}
+	return a
+}
@@ -1,6 +1,11 @@
 package app
 
 import (
+	"context"
+
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/service/leasesweeper"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/service/portfoliosnapshotter"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/service/withdrawalsweeper"
 	"github.com/peterparker2005/giftduels/apps/service-gift/internal/transport/worker"
 	"go.uber.org/fx"
 )
@@ -11,5 +16,34 @@ func NewWorkerApp() *fx.App {
 			CommonModule,
 			worker.Module,
 		),
+		fx.Provide(
+			withdrawalsweeper.NewSweeper,
+			portfoliosnapshotter.NewSnapshotter,
+			leasesweeper.NewSweeper,
+		),
+		fx.Invoke(func(
+			sweeper *withdrawalsweeper.Sweeper,
+			snapshotter *portfoliosnapshotter.Snapshotter,
+			leaseSweeper *leasesweeper.Sweeper,
+			lc fx.Lifecycle,
+		) {
+			lc.Append(fx.Hook{
+				OnStart: func(_ context.Context) error {
+					sweeper.Start()
+					snapshotter.Start()
+					leaseSweeper.Start()
+					return nil
+				},
+				OnStop: func(ctx context.Context) error {
+					if err := leaseSweeper.Stop(ctx); err != nil {
+						return err
+					}
+					if err := snapshotter.Stop(ctx); err != nil {
+						return err
+					}
+					return sweeper.Stop(ctx)
+				},
+			})
+		}),
 	)
 }
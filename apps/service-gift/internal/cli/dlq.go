@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/config"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/transport/worker"
+	"github.com/peterparker2005/giftduels/packages/cli-go/command/dlq"
+	giftEvents "github.com/peterparker2005/giftduels/packages/events/gift"
+	duelv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/duel/v1"
+	giftv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/gift/v1"
+	telegrambotv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/telegrambot/v1"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/proto"
+)
+
+func newCmdDLQ() *cobra.Command {
+	return dlq.NewCmdDLQ(func() (*dlq.Setup, error) {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return nil, err
+		}
+
+		return &dlq.Setup{
+			AMQPURI: cfg.AMQP.Address(),
+			Poison:  giftEvents.Config(cfg.ServiceName.String()),
+			Routes:  dlqRoutes(),
+		}, nil
+	})
+}
+
+// dlqRoutes описывает payload каждого обработчика worker'а.
+func dlqRoutes() []dlq.Route {
+	return []dlq.Route{
+		{
+			Handler: worker.HandlerDuelCompleted,
+			Payload: func() proto.Message { return &duelv1.DuelCompletedEvent{} },
+		},
+		{
+			Handler: worker.HandlerTgGiftReceived,
+			Payload: func() proto.Message { return &giftv1.TelegramGiftReceivedEvent{} },
+		},
+		{
+			Handler: worker.HandlerGiftWithdrawFail,
+			Payload: func() proto.Message { return &giftv1.GiftWithdrawFailedEvent{} },
+		},
+		{
+			Handler: worker.HandlerInvoicePaid,
+			Payload: func() proto.Message { return &telegrambotv1.InvoicePaymentEvent{} },
+		},
+		{
+			Handler: worker.HandlerGiftWithdrawn,
+			Payload: func() proto.Message { return &giftv1.GiftWithdrawnEvent{} },
+		},
+		{
+			Handler: worker.HandlerGiftUserNotFound,
+			Payload: func() proto.Message { return &giftv1.GiftWithdrawUserNotFoundEvent{} },
+		},
+		// create_duel_fail получает голый gift_id
+		{Handler: worker.HandlerCreateDuelFail},
+	}
+}
@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/app"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/reconciliation"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/service/command"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/service/query"
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+)
+
+const reconcileTimeout = 5 * time.Minute
+
+func newCmdReconcile() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reconcile",
+		Short: "Reconcile gifts with the custody account inventory",
+		Long: `Compare gifts that are not withdrawn with the gifts actually held
+on the Telegram custody account:
+  run    - Fetch the custody inventory, store discrepancies and print the report
+  report - Print the report of the latest (or given) run`,
+	}
+
+	cmd.AddCommand(
+		newCmdReconcileRun(),
+		newCmdReconcileReport(),
+	)
+
+	return cmd
+}
+
+func newCmdReconcileRun() *cobra.Command {
+	return &cobra.Command{
+		Use:   "run",
+		Short: "Run reconciliation and print discrepancies",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			var reconciler *command.CustodyReconciler
+			return withReconcileApp(cmd.Context(), fx.Populate(&reconciler), func(ctx context.Context) error {
+				run, err := reconciler.Run(ctx)
+				if err != nil {
+					return err
+				}
+				return printReconciliationRun(cmd.OutOrStdout(), run)
+			})
+		},
+	}
+}
+
+func newCmdReconcileReport() *cobra.Command {
+	return &cobra.Command{
+		Use:   "report [RUN_ID]",
+		Short: "Print discrepancies of the latest (or given) run",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var runID string
+			if len(args) == 1 {
+				runID = args[0]
+			}
+
+			var readSvc *query.ReconciliationReadService
+			return withReconcileApp(cmd.Context(), fx.Populate(&readSvc), func(ctx context.Context) error {
+				run, err := readSvc.GetRun(ctx, runID)
+				if err != nil {
+					return err
+				}
+				return printReconciliationRun(cmd.OutOrStdout(), run)
+			})
+		},
+	}
+}
+
+// withReconcileApp поднимает общие зависимости сервиса на время одной команды.
+func withReconcileApp(parent context.Context, populate fx.Option, fn func(ctx context.Context) error) error {
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(parent, reconcileTimeout)
+	defer cancel()
+
+	fxApp := fx.New(app.CommonModule, populate)
+	if err := fxApp.Start(ctx); err != nil {
+		return err
+	}
+	defer func() { _ = fxApp.Stop(context.Background()) }()
+
+	return fn(ctx)
+}
+
+func printReconciliationRun(w io.Writer, run *reconciliation.Run) error {
+	fmt.Fprintf(w, "run:           %s\n", run.ID)
+	fmt.Fprintf(w, "started at:    %s\n", run.StartedAt.Format(time.RFC3339))
+	fmt.Fprintf(w, "custody gifts: %d\n", run.CustodyCount)
+	fmt.Fprintf(w, "ledger gifts:  %d\n", run.LedgerCount)
+	fmt.Fprintf(w, "discrepancies: %d\n", len(run.Discrepancies))
+	if len(run.Discrepancies) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KIND\tTELEGRAM GIFT ID\tCOLLECTIBLE\tSLUG\tGIFT ID\tSTATUS")
+	for _, d := range run.Discrepancies {
+		giftID, status := "-", "-"
+		if d.GiftID != nil {
+			giftID = *d.GiftID
+		}
+		if d.GiftStatus != nil {
+			status = string(*d.GiftStatus)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\t%s\n",
+			d.Kind, d.Key.TelegramGiftID, d.Key.CollectibleID, d.Slug, giftID, status)
+	}
+	return tw.Flush()
+}
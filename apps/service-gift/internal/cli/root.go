@@ -12,6 +12,8 @@ func rootCmd() *cobra.Command {
 		newCmdServe(),
 		newCmdMigrate(),
 		newCmdWorker(),
+		newCmdDLQ(),
+		newCmdReconcile(),
 	)
 
 	return cmd
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/peterparker2005/giftduels/packages/configs"
 	"go.uber.org/fx"
@@ -14,12 +16,61 @@ type TonnelConfig struct {
 	InitData string `yaml:"init_data" env:"TONNEL_API_INIT_DATA"`
 }
 
+type MarketplaceConfig struct {
+	// FeePercent — комиссия площадки с продажи, в процентах от цены лота.
+	// Строка, чтобы процент оставался десятичным без потерь float.
+	FeePercent string `yaml:"fee_percent" env:"MARKETPLACE_FEE_PERCENT" env-default:"5"`
+}
+
+type SellBackConfig struct {
+	// PayoutPercent — доля floor-цены в процентах, которую площадка платит за подарок.
+	// Строка, чтобы процент оставался десятичным без потерь float.
+	PayoutPercent string `yaml:"payout_percent" env:"SELL_BACK_PAYOUT_PERCENT" env-default:"80"`
+	// QuoteTTL — сколько действует котировка
+	QuoteTTL time.Duration `yaml:"quote_ttl" env:"SELL_BACK_QUOTE_TTL" env-default:"60s"`
+	// HouseTelegramID — аккаунт площадки, на который переходят выкупленные подарки;
+	// обязателен, без него сервис не запустится
+	HouseTelegramID int64 `yaml:"house_telegram_id" env:"SELL_BACK_HOUSE_TELEGRAM_ID"`
+}
+
+type WithdrawalConfig struct {
+	// StarsInvoiceTTL — сколько подарки держатся под неоплаченным Stars-инвойсом
+	StarsInvoiceTTL time.Duration `yaml:"stars_invoice_ttl" env:"STARS_WITHDRAWAL_INVOICE_TTL" env-default:"30m"`
+	// SweepInterval — как часто sweeper ищет просроченные инвойсы
+	SweepInterval time.Duration `yaml:"sweep_interval" env:"STARS_WITHDRAWAL_SWEEP_INTERVAL" env-default:"1m"`
+}
+
+type CatalogConfig struct {
+	// CacheTTL — сколько каталог атрибутов отдаётся из памяти без запроса в БД
+	CacheTTL time.Duration `yaml:"cache_ttl" env:"GIFT_CATALOG_CACHE_TTL" env-default:"1m"`
+}
+
+type StakeConfig struct {
+	// LeaseTTL — сколько подарок держится за дуэлью, пока service-duel не подтвердит ставку
+	LeaseTTL time.Duration `yaml:"lease_ttl" env:"GIFT_STAKE_LEASE_TTL" env-default:"1m"`
+	// SweepInterval — как часто освобождаются подарки с истёкшей арендой
+	SweepInterval time.Duration `yaml:"sweep_interval" env:"GIFT_STAKE_LEASE_SWEEP_INTERVAL" env-default:"15s"`
+}
+
+type PortfolioConfig struct {
+	// SnapshotInterval — как часто перезаписывается дневной снапшот стоимости портфелей
+	SnapshotInterval time.Duration `yaml:"snapshot_interval" env:"PORTFOLIO_SNAPSHOT_INTERVAL" env-default:"1h"`
+	// MaxHistoryDays — максимальная глубина запроса истории портфеля
+	MaxHistoryDays int `yaml:"max_history_days" env:"PORTFOLIO_MAX_HISTORY_DAYS" env-default:"365"`
+}
+
 type Config struct {
 	configs.ServiceBaseConfig
 
 	Logger       configs.LoggerConfig `yaml:"logger"`
 	Telegram     TelegramConfig       `yaml:"telegram"`
 	TonnelConfig TonnelConfig         `yaml:"tonnel"`
+	Marketplace  MarketplaceConfig    `yaml:"marketplace"`
+	SellBack     SellBackConfig       `yaml:"sell_back"`
+	Withdrawal   WithdrawalConfig     `yaml:"withdrawal"`
+	Catalog      CatalogConfig        `yaml:"catalog"`
+	Portfolio    PortfolioConfig      `yaml:"portfolio"`
+	Stake        StakeConfig          `yaml:"stake"`
 
 	// shared configs
 	Database configs.DatabaseConfig `yaml:"database"`
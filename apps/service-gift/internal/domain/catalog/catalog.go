@@ -0,0 +1,30 @@
+package catalog
+
+import (
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/gift"
+	"github.com/peterparker2005/giftduels/packages/tonamount-go"
+)
+
+// CollectionEntry — коллекция с floor-ценой. Во всех записях каталога
+// FloorPrice — минимальная цена среди активных лотов маркетплейса
+// с данным атрибутом; nil, если лотов нет.
+type CollectionEntry struct {
+	Collection gift.Collection
+	FloorPrice *tonamount.TonAmount
+}
+
+type ModelEntry struct {
+	Model          gift.Model
+	CollectionName string
+	FloorPrice     *tonamount.TonAmount
+}
+
+type BackdropEntry struct {
+	Backdrop   gift.Backdrop
+	FloorPrice *tonamount.TonAmount
+}
+
+type SymbolEntry struct {
+	Symbol     gift.Symbol
+	FloorPrice *tonamount.TonAmount
+}
@@ -0,0 +1,11 @@
+package catalog
+
+import "context"
+
+type Repository interface {
+	ListCollections(ctx context.Context) ([]*CollectionEntry, error)
+	// ListModels возвращает модели коллекции; nil collection — модели всех коллекций.
+	ListModels(ctx context.Context, collection *string) ([]*ModelEntry, error)
+	ListBackdrops(ctx context.Context) ([]*BackdropEntry, error)
+	ListSymbols(ctx context.Context) ([]*SymbolEntry, error)
+}
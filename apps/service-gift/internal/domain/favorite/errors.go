@@ -0,0 +1,36 @@
+package favorite
+
+import "errors"
+
+var (
+	ErrPresetNotFound      = errors.New("stake preset not found")
+	ErrPresetNameRequired  = errors.New("stake preset name is required")
+	ErrPresetNameTooLong   = errors.New("stake preset name is too long")
+	ErrPresetEmpty         = errors.New("stake preset must contain at least one gift")
+	ErrTooManyPresetGifts  = errors.New("stake preset contains too many gifts")
+	ErrDuplicatePresetGift = errors.New("stake preset contains duplicate gifts")
+)
+
+func IsPresetNotFound(err error) bool {
+	return errors.Is(err, ErrPresetNotFound)
+}
+
+func IsPresetNameRequired(err error) bool {
+	return errors.Is(err, ErrPresetNameRequired)
+}
+
+func IsPresetNameTooLong(err error) bool {
+	return errors.Is(err, ErrPresetNameTooLong)
+}
+
+func IsPresetEmpty(err error) bool {
+	return errors.Is(err, ErrPresetEmpty)
+}
+
+func IsTooManyPresetGifts(err error) bool {
+	return errors.Is(err, ErrTooManyPresetGifts)
+}
+
+func IsDuplicatePresetGift(err error) bool {
+	return errors.Is(err, ErrDuplicatePresetGift)
+}
@@ -0,0 +1,54 @@
+package favorite
+
+import (
+	"strings"
+	"time"
+)
+
+// MaxPresetGifts — предел подарков в пресете, совпадает с максимумом
+// подарков на участника дуэли.
+const MaxPresetGifts = 10
+
+// MaxPresetNameLength — предел длины названия пресета в символах.
+const MaxPresetNameLength = 64
+
+// Preset — именованный упорядоченный набор подарков для быстрой ставки.
+type Preset struct {
+	ID             string
+	TelegramUserID int64
+	Name           string
+	GiftIDs        []string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// NewPresetName нормализует название пресета и проверяет его длину.
+func NewPresetName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", ErrPresetNameRequired
+	}
+	if len([]rune(name)) > MaxPresetNameLength {
+		return "", ErrPresetNameTooLong
+	}
+	return name, nil
+}
+
+// ValidatePresetGifts проверяет состав пресета: непустой, без повторов
+// и не больше MaxPresetGifts подарков.
+func ValidatePresetGifts(giftIDs []string) error {
+	if len(giftIDs) == 0 {
+		return ErrPresetEmpty
+	}
+	if len(giftIDs) > MaxPresetGifts {
+		return ErrTooManyPresetGifts
+	}
+	seen := make(map[string]struct{}, len(giftIDs))
+	for _, id := range giftIDs {
+		if _, ok := seen[id]; ok {
+			return ErrDuplicatePresetGift
+		}
+		seen[id] = struct{}{}
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package favorite
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type Repository interface {
+	WithTx(tx pgx.Tx) Repository
+	// AddFavorite идемпотентно добавляет подарок в избранное пользователя.
+	AddFavorite(ctx context.Context, telegramUserID int64, giftID string) error
+	// RemoveFavorite возвращает false, если подарка в избранном не было.
+	RemoveFavorite(ctx context.Context, telegramUserID int64, giftID string) (bool, error)
+	// GetFavoriteGiftIDs возвращает избранные подарки, которыми пользователь
+	// всё ещё владеет, недавно добавленные первыми.
+	GetFavoriteGiftIDs(ctx context.Context, telegramUserID int64) ([]string, error)
+	// SavePreset создаёт пресет или заменяет состав пресета с тем же названием.
+	SavePreset(ctx context.Context, preset *Preset) error
+	DeletePreset(ctx context.Context, telegramUserID int64, presetID string) error
+	GetUserPresets(ctx context.Context, telegramUserID int64) ([]*Preset, error)
+}
@@ -28,6 +28,33 @@ type Gift struct {
 
 	// metadata for game
 	RelatedDuelID string
+
+	// Lock is set while the gift is held by an operation (e.g. staked in a duel).
+	Lock *Lock
+}
+
+type LockOperation string
+
+const (
+	LockOperationDuel LockOperation = "duel"
+)
+
+// Lock is a lease an operation holds on a gift. Until the operation confirms
+// it, the lease expires at ExpiresAt and the gift is released back to its
+// owner; a confirmed lease has no ExpiresAt.
+type Lock struct {
+	Operation   LockOperation
+	OperationID string
+	ExpiresAt   *time.Time
+}
+
+func (l *Lock) IsConfirmed() bool {
+	return l.ExpiresAt == nil
+}
+
+// IsLockedBy reports whether the gift is held by the given operation.
+func (g *Gift) IsLockedBy(operation LockOperation, operationID string) bool {
+	return g.Lock != nil && g.Lock.Operation == operation && g.Lock.OperationID == operationID
 }
 
 type Collection struct {
@@ -69,6 +96,7 @@ const (
 	StatusWithdrawn       Status = "withdrawn"
 	StatusWithdrawPending Status = "withdraw_pending"
 	StatusOwned           Status = "owned"
+	StatusListed          Status = "listed"
 )
 
 type Attribute struct {
@@ -178,11 +206,63 @@ func (g *Gift) CompleteWithdrawal(at time.Time) error {
 	return nil
 }
 
+// List puts the gift up for sale on the marketplace.
+func (g *Gift) List(telegramUserID int64) error {
+	if !g.IsOwnedBy(telegramUserID) {
+		return ErrGiftNotOwned
+	}
+	if g.Status != StatusOwned {
+		return ErrGiftCannotBeListed
+	}
+	g.Status = StatusListed
+	return nil
+}
+
+// Unlist removes the gift from the marketplace.
+func (g *Gift) Unlist() error {
+	if g.Status != StatusListed {
+		return ErrGiftNotListed
+	}
+	g.Status = StatusOwned
+	return nil
+}
+
+// Sell transfers a listed gift to the buyer.
+func (g *Gift) Sell(buyerTelegramID int64) error {
+	if g.Status != StatusListed {
+		return ErrGiftNotListed
+	}
+	if g.IsOwnedBy(buyerTelegramID) {
+		return ErrGiftAlreadyOwned
+	}
+	g.Status = StatusOwned
+	g.OwnerTelegramID = buyerTelegramID
+	return nil
+}
+
+// SellBack transfers an owned gift to the house account.
+func (g *Gift) SellBack(telegramUserID, houseTelegramID int64) error {
+	if !g.IsOwnedBy(telegramUserID) {
+		return ErrGiftNotOwned
+	}
+	if g.Status != StatusOwned {
+		return ErrGiftCannotBeSoldBack
+	}
+	g.OwnerTelegramID = houseTelegramID
+	return nil
+}
+
 // IsOwnedBy checks if the gift is owned by the specified user.
 func (g *Gift) IsOwnedBy(telegramUserID int64) bool {
 	return g.OwnerTelegramID == telegramUserID
 }
 
+// IsInInventoryOf checks if the gift is still in the user's inventory,
+// i.e. owned by the user and not withdrawn.
+func (g *Gift) IsInInventoryOf(telegramUserID int64) bool {
+	return g.IsOwnedBy(telegramUserID) && g.Status != StatusWithdrawn
+}
+
 // CanBeWithdrawn checks if the gift can be withdrawn.
 func (g *Gift) CanBeWithdrawn() bool {
 	return g.Status == StatusOwned
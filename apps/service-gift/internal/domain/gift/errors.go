@@ -13,6 +13,14 @@ var (
 	ErrGiftCannotBeWithdrawn     = errors.New("gift cannot be withdrawn")
 	ErrGiftCannotStake           = errors.New("gift cannot be staked")
 	ErrInvalidCommissionCurrency = errors.New("invalid commission currency")
+	ErrGiftCannotBeListed        = errors.New("gift cannot be listed")
+	ErrGiftNotListed             = errors.New("gift is not listed")
+	ErrGiftCannotBeSoldBack      = errors.New("gift cannot be sold back")
+	ErrUnsupportedEventType      = errors.New("unsupported gift event type")
+	ErrLeaseNotHeld              = errors.New("gift lease is not held by the operation")
+	ErrInvalidStakeTarget        = errors.New("invalid stake target")
+	ErrNoStakeSuggestion         = errors.New("no combination of owned gifts matches the stake target")
+	ErrFeeScheduleChanged        = errors.New("withdrawal fees changed since preview")
 )
 
 func IsInvalidCommissionCurrency(err error) bool {
@@ -46,3 +54,31 @@ func IsGiftCannotBeWithdrawn(err error) bool {
 func IsGiftCannotStake(err error) bool {
 	return errors.Is(err, ErrGiftCannotStake)
 }
+
+func IsGiftCannotBeListed(err error) bool {
+	return errors.Is(err, ErrGiftCannotBeListed)
+}
+
+func IsGiftNotListed(err error) bool {
+	return errors.Is(err, ErrGiftNotListed)
+}
+
+func IsGiftCannotBeSoldBack(err error) bool {
+	return errors.Is(err, ErrGiftCannotBeSoldBack)
+}
+
+func IsLeaseNotHeld(err error) bool {
+	return errors.Is(err, ErrLeaseNotHeld)
+}
+
+func IsInvalidStakeTarget(err error) bool {
+	return errors.Is(err, ErrInvalidStakeTarget)
+}
+
+func IsNoStakeSuggestion(err error) bool {
+	return errors.Is(err, ErrNoStakeSuggestion)
+}
+
+func IsFeeScheduleChanged(err error) bool {
+	return errors.Is(err, ErrFeeScheduleChanged)
+}
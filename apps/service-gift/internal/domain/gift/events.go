@@ -23,6 +23,10 @@ const (
 	EventTypeWithdrawRequest  EventType = "withdraw_request"
 	EventTypeWithdrawComplete EventType = "withdraw_complete"
 	EventTypeWithdrawFail     EventType = "withdraw_fail"
+	EventTypeList             EventType = "list"
+	EventTypeUnlist           EventType = "unlist"
+	EventTypeSale             EventType = "sale"
+	EventTypeSellBack         EventType = "sell_back"
 )
 
 func NewEvent(p *NewEventParams) (*Event, error) {
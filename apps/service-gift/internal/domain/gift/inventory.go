@@ -0,0 +1,55 @@
+package gift
+
+import "github.com/peterparker2005/giftduels/packages/tonamount-go"
+
+// InventorySortBy задаёт поле сортировки инвентаря.
+type InventorySortBy string
+
+const (
+	// InventorySortByUpdatedAt — сортировка по умолчанию, недавно изменённые первыми.
+	InventorySortByUpdatedAt   InventorySortBy = "updated_at"
+	InventorySortByPrice       InventorySortBy = "price"
+	InventorySortByRarity      InventorySortBy = "rarity"
+	InventorySortByDepositDate InventorySortBy = "deposit_date"
+)
+
+// InventorySort описывает сортировку инвентаря. Для редкости Desc означает
+// «самые редкие первыми».
+type InventorySort struct {
+	By   InventorySortBy
+	Desc bool
+}
+
+// InventoryFilter ограничивает выборку инвентаря пользователя.
+// Пустой Statuses означает подарки в статусах owned и in_game.
+type InventoryFilter struct {
+	Statuses   []Status
+	Collection *string
+	Model      *string
+	Backdrop   *string
+	Symbol     *string
+	MinPrice   *tonamount.TonAmount
+	MaxPrice   *tonamount.TonAmount
+	Search     *string
+}
+
+// StatusesOrDefault возвращает статусы фильтра или активные статусы по умолчанию.
+func (f *InventoryFilter) StatusesOrDefault() []Status {
+	if f == nil || len(f.Statuses) == 0 {
+		return []Status{StatusOwned, StatusInGame}
+	}
+	return f.Statuses
+}
+
+type CollectionSummary struct {
+	Collection string
+	Count      int64
+	TotalValue *tonamount.TonAmount
+}
+
+// InventorySummary — агрегаты по всем подаркам, подходящим под фильтр.
+type InventorySummary struct {
+	TotalCount  int64
+	TotalValue  *tonamount.TonAmount
+	Collections []CollectionSummary
+}
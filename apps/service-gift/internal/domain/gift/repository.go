@@ -55,6 +55,17 @@ type GetUserGiftsResult struct {
 	Total int64
 }
 
+// EventFilter ограничивает выборку событий подарков.
+// Пустой EventTypes означает отсутствие фильтра по типу.
+type EventFilter struct {
+	EventTypes []EventType
+}
+
+type GetGiftEventsResult struct {
+	Events []*Event
+	Total  int64
+}
+
 type CreateGiftEventParams struct {
 	GiftID         string
 	TelegramUserID int64
@@ -62,6 +73,19 @@ type CreateGiftEventParams struct {
 	RelatedGameID  *string
 }
 
+// CreateFailedCompensationParams описывает компенсацию саги, которая не
+// прошла. Баланс по такой записи правят вручную, повторяя платёж с тем же
+// ключом идемпотентности.
+type CreateFailedCompensationParams struct {
+	Saga           string
+	Step           string
+	GiftID         string
+	TelegramUserID int64
+	Amount         *tonamount.TonAmount
+	IdempotencyKey string
+	Error          string
+}
+
 type Repository interface {
 	WithTx(tx pgx.Tx) Repository
 	GetGiftByID(ctx context.Context, id string) (*Gift, error)
@@ -71,18 +95,55 @@ type Repository interface {
 		offset int32,
 		ownerTelegramID int64,
 	) (*GetUserGiftsResult, error)
-	GetUserActiveGifts(
+	SearchUserGifts(
 		ctx context.Context,
+		ownerTelegramID int64,
+		filter *InventoryFilter,
+		sort InventorySort,
 		limit int32,
 		offset int32,
+	) ([]*Gift, error)
+	GetUserInventorySummary(
+		ctx context.Context,
 		ownerTelegramID int64,
-	) (*GetUserGiftsResult, error)
-	StakeGiftForGame(ctx context.Context, id string) (*Gift, error)
+		filter *InventoryFilter,
+	) (*InventorySummary, error)
+	// StakeGiftForGame переводит подарок в in_game под аренду дуэли до leaseExpiresAt.
+	StakeGiftForGame(
+		ctx context.Context,
+		id string,
+		relatedDuelID string,
+		leaseExpiresAt time.Time,
+	) (*Gift, error)
+	// ConfirmGiftLeases делает аренду постоянной и возвращает ID подарков,
+	// которые всё ещё удерживаются операцией.
+	ConfirmGiftLeases(
+		ctx context.Context,
+		operation LockOperation,
+		operationID string,
+		giftIDs []string,
+	) ([]string, error)
+	// GetExpiredLeases возвращает подарки с истёкшей неподтверждённой арендой.
+	GetExpiredLeases(ctx context.Context, now time.Time, limit int32) ([]*Gift, error)
+	// ReleaseLeases возвращает владельцам подарки, аренда которых всё ещё
+	// не подтверждена и истекла к now.
+	ReleaseLeases(ctx context.Context, giftIDs []string, now time.Time) ([]*Gift, error)
 	ReturnGiftFromGame(ctx context.Context, id string) (*Gift, error)
-	UpdateGiftOwner(ctx context.Context, id string, ownerTelegramID int64) error
+	// TransferGiftFromGame передаёт подарок, удерживаемый дуэлью duelID,
+	// новому владельцу. Если дуэль подарок уже не держит — ErrNotFound.
+	TransferGiftFromGame(ctx context.Context, id string, duelID string, ownerTelegramID int64) error
 	MarkGiftForWithdrawal(ctx context.Context, id string) (*Gift, error)
 	CancelGiftWithdrawal(ctx context.Context, id string) (*Gift, error)
 	CompleteGiftWithdrawal(ctx context.Context, id string) (*Gift, error)
+	ListGift(ctx context.Context, id string) (*Gift, error)
+	UnlistGift(ctx context.Context, id string) (*Gift, error)
+	TransferListedGift(ctx context.Context, id string, ownerTelegramID int64) (*Gift, error)
+	TransferOwnedGift(
+		ctx context.Context,
+		id string,
+		fromTelegramID int64,
+		toTelegramID int64,
+	) (*Gift, error)
 	CreateGift(
 		ctx context.Context,
 		params *CreateGiftParams,
@@ -95,8 +156,25 @@ type Repository interface {
 		limit int32,
 		offset int32,
 	) ([]*Event, error)
+	GetGiftHistory(
+		ctx context.Context,
+		giftID string,
+		filter EventFilter,
+		limit int32,
+		offset int32,
+	) (*GetGiftEventsResult, error)
+	// GiftHasUserEvent сообщает, есть ли у подарка события пользователя.
+	GiftHasUserEvent(ctx context.Context, giftID string, telegramUserID int64) (bool, error)
+	GetUserGiftActivity(
+		ctx context.Context,
+		telegramUserID int64,
+		filter EventFilter,
+		limit int32,
+		offset int32,
+	) (*GetGiftEventsResult, error)
 	GetGiftsByIDs(ctx context.Context, ids []string) ([]*Gift, error)
 	SaveGiftWithPrice(ctx context.Context, id string, price *tonamount.TonAmount) (*Gift, error)
+	CreateFailedCompensation(ctx context.Context, params *CreateFailedCompensationParams) error
 
 	// Lookup table methods
 	GetGiftModel(ctx context.Context, id int32) (*Model, error)
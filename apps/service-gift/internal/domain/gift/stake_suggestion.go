@@ -0,0 +1,183 @@
+package gift
+
+import (
+	"math"
+
+	"github.com/peterparker2005/giftduels/packages/tonamount-go"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// suggestionBudget ограничивает размер таблицы подбора (подарки × шаги суммы),
+	// suggestionMaxSteps — число шагов суммы. При большем размере шаг укрупняется.
+	suggestionBudget   = 1 << 26
+	suggestionMaxSteps = 1 << 20
+)
+
+// unreachable — сумма не набирается ни одним подмножеством.
+const unreachable = math.MaxUint16
+
+// StakeTarget задаёт желаемую сумму ставки. Min и Max, если заданы,
+// ограничивают сумму включительно.
+type StakeTarget struct {
+	Amount *tonamount.TonAmount
+	Min    *tonamount.TonAmount
+	Max    *tonamount.TonAmount
+}
+
+// NewStakeTargetAmount — ставка как можно ближе к amount в любую сторону.
+func NewStakeTargetAmount(amount *tonamount.TonAmount) (StakeTarget, error) {
+	if amount == nil || !amount.Decimal().IsPositive() {
+		return StakeTarget{}, ErrInvalidStakeTarget
+	}
+	return StakeTarget{Amount: amount}, nil
+}
+
+// NewStakeTargetRange — ставка внутри [minAmount, maxAmount], как можно ближе
+// к середине диапазона.
+func NewStakeTargetRange(minAmount, maxAmount *tonamount.TonAmount) (StakeTarget, error) {
+	if minAmount == nil || maxAmount == nil ||
+		!maxAmount.Decimal().IsPositive() ||
+		minAmount.Decimal().GreaterThan(maxAmount.Decimal()) {
+		return StakeTarget{}, ErrInvalidStakeTarget
+	}
+	//nolint:mnd // середина диапазона
+	mid := minAmount.Decimal().Add(maxAmount.Decimal()).Div(decimal.NewFromInt(2))
+	amount, err := tonamount.NewTonAmountFromString(mid.String())
+	if err != nil {
+		return StakeTarget{}, err
+	}
+	return StakeTarget{Amount: amount, Min: minAmount, Max: maxAmount}, nil
+}
+
+// CandidatePriceCap возвращает верхнюю границу цены подарков, которые имеет
+// смысл рассматривать при подборе. Без Max это удвоенная цель: подарок дороже
+// отклоняется от цели сильнее, чем любой подарок не дороже 2×Amount.
+func (t StakeTarget) CandidatePriceCap() *tonamount.TonAmount {
+	if t.Max != nil {
+		return t.Max
+	}
+	return t.Amount.Add(t.Amount)
+}
+
+// SuggestStake подбирает не больше maxGifts подарков, сумма цен которых
+// ближе всего к target.Amount (задача о сумме подмножества). При равном
+// отклонении предпочитается сумма ниже цели; для выбранной суммы берётся
+// набор из наименьшего числа подарков.
+// Подарки без цены не участвуют в подборе.
+func SuggestStake(gifts []*Gift, target StakeTarget, maxGifts int) ([]*Gift, error) {
+	if target.Amount == nil || maxGifts <= 0 {
+		return nil, ErrInvalidStakeTarget
+	}
+
+	candidates := make([]*Gift, 0, len(gifts))
+	var maxPrice int64
+	for _, g := range gifts {
+		p := toCents(g.Price)
+		if p <= 0 {
+			continue
+		}
+		candidates = append(candidates, g)
+		maxPrice = max(maxPrice, p)
+	}
+	if len(candidates) == 0 {
+		return nil, ErrNoStakeSuggestion
+	}
+
+	// Лучшая сумма выше цели меньше target+maxPrice: иначе убранный
+	// подарок приблизил бы её к цели.
+	upper := toCents(target.Amount) + maxPrice
+	if target.Max != nil {
+		upper = min(upper, toCents(target.Max))
+	}
+	unit := max(
+		int64(1),
+		(upper*int64(len(candidates))+suggestionBudget-1)/suggestionBudget,
+		(upper+suggestionMaxSteps-1)/suggestionMaxSteps,
+	)
+	size := int(upper/unit) + 1
+
+	weights := make([]int, len(candidates))
+	for i, g := range candidates {
+		weights[i] = int((toCents(g.Price) + unit/2) / unit)
+	}
+
+	// counts[s] — минимальное число подарков с суммой s шагов;
+	// taken[i] отмечает суммы, для которых лучший набор включает подарок i.
+	counts := make([]uint16, size)
+	for s := 1; s < size; s++ {
+		counts[s] = unreachable
+	}
+	taken := make([][]uint64, len(candidates))
+	for i, w := range weights {
+		taken[i] = make([]uint64, (size+63)/64)
+		if w == 0 || w >= size {
+			continue
+		}
+		for s := size - 1; s >= w; s-- {
+			if prev := counts[s-w]; prev != unreachable && prev+1 < counts[s] {
+				counts[s] = prev + 1
+				taken[i][s/64] |= 1 << (s % 64)
+			}
+		}
+	}
+
+	best := bestStakeSum(counts, target, unit, maxGifts)
+	if best <= 0 {
+		return nil, ErrNoStakeSuggestion
+	}
+
+	picked := make([]*Gift, 0, counts[best])
+	for i := len(candidates) - 1; i >= 0 && best > 0; i-- {
+		if taken[i][best/64]&(1<<(best%64)) != 0 {
+			picked = append(picked, candidates[i])
+			best -= weights[i]
+		}
+	}
+
+	// Шаг мог быть укрупнён: проверяем границы по точным ценам
+	total := tonamount.Zero()
+	for _, g := range picked {
+		total = total.Add(g.Price)
+	}
+	if (target.Min != nil && total.Decimal().LessThan(target.Min.Decimal())) ||
+		(target.Max != nil && total.Decimal().GreaterThan(target.Max.Decimal())) {
+		return nil, ErrNoStakeSuggestion
+	}
+	return picked, nil
+}
+
+// bestStakeSum возвращает достижимую сумму (в шагах) ближе всего к цели
+// или -1, если подходящей суммы нет.
+func bestStakeSum(counts []uint16, target StakeTarget, unit int64, maxGifts int) int {
+	goal := int(toCents(target.Amount) / unit)
+	lo, hi := 1, len(counts)-1
+	if target.Min != nil {
+		lo = max(lo, int((toCents(target.Min)+unit-1)/unit))
+	}
+	if target.Max != nil {
+		hi = min(hi, int(toCents(target.Max)/unit))
+	}
+
+	fits := func(s int) bool {
+		return s >= lo && s <= hi && counts[s] != unreachable && int(counts[s]) <= maxGifts
+	}
+	for d := 0; goal-d >= lo || goal+d <= hi; d++ {
+		if fits(goal - d) {
+			return goal - d
+		}
+		if fits(goal + d) {
+			return goal + d
+		}
+	}
+	return -1
+}
+
+// toCents переводит сумму в сотые TON — точность цен подарков.
+func toCents(amount *tonamount.TonAmount) int64 {
+	if amount == nil {
+		return 0
+	}
+	//nolint:mnd // сотые доли TON
+	return amount.Decimal().Shift(2).IntPart()
+}
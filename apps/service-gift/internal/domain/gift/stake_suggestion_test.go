@@ -0,0 +1,256 @@
+package gift_test
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/gift"
+	"github.com/peterparker2005/giftduels/packages/tonamount-go"
+)
+
+func mustTon(t *testing.T, s string) *tonamount.TonAmount {
+	t.Helper()
+	a, err := tonamount.NewTonAmountFromString(s)
+	if err != nil {
+		t.Fatalf("cannot parse TonAmount from %q: %v", s, err)
+	}
+	return a
+}
+
+// Вспомогалка: подарки g0, g1, ... с заданными ценами; пустая цена — подарок без цены.
+func makeGifts(t *testing.T, prices ...string) []*gift.Gift {
+	t.Helper()
+	gifts := make([]*gift.Gift, len(prices))
+	for i, p := range prices {
+		gifts[i] = &gift.Gift{ID: "g" + strconv.Itoa(i)}
+		if p != "" {
+			gifts[i].Price = mustTon(t, p)
+		}
+	}
+	return gifts
+}
+
+func amountTarget(t *testing.T, amount string) gift.StakeTarget {
+	t.Helper()
+	target, err := gift.NewStakeTargetAmount(mustTon(t, amount))
+	if err != nil {
+		t.Fatalf("NewStakeTargetAmount(%s): %v", amount, err)
+	}
+	return target
+}
+
+func rangeTarget(t *testing.T, minAmount, maxAmount string) gift.StakeTarget {
+	t.Helper()
+	target, err := gift.NewStakeTargetRange(mustTon(t, minAmount), mustTon(t, maxAmount))
+	if err != nil {
+		t.Fatalf("NewStakeTargetRange(%s, %s): %v", minAmount, maxAmount, err)
+	}
+	return target
+}
+
+func TestSuggestStake(t *testing.T) {
+	tests := []struct {
+		name     string
+		prices   []string
+		target   gift.StakeTarget
+		maxGifts int
+		wantIDs  []string
+		wantErr  error
+	}{
+		{
+			name:     "exact hit",
+			prices:   []string{"1", "2", "3.5", "5"},
+			target:   amountTarget(t, "5.5"),
+			maxGifts: 10,
+			wantIDs:  []string{"g1", "g2"},
+		},
+		{
+			name:     "exact hit with fewest gifts",
+			prices:   []string{"1", "1", "1", "1", "4"},
+			target:   amountTarget(t, "4"),
+			maxGifts: 10,
+			wantIDs:  []string{"g4"},
+		},
+		{
+			name:     "best under target",
+			prices:   []string{"3", "5"},
+			target:   amountTarget(t, "3.8"),
+			maxGifts: 10,
+			wantIDs:  []string{"g0"},
+		},
+		{
+			name:     "tie prefers sum under target",
+			prices:   []string{"2", "3"},
+			target:   amountTarget(t, "2.5"),
+			maxGifts: 10,
+			wantIDs:  []string{"g0"},
+		},
+		{
+			name:     "single gift above target when nothing is closer",
+			prices:   []string{"7"},
+			target:   amountTarget(t, "5"),
+			maxGifts: 10,
+			wantIDs:  []string{"g0"},
+		},
+		{
+			name:     "range picks sum closest to midpoint",
+			prices:   []string{"1", "2", "6"},
+			target:   rangeTarget(t, "4", "7"),
+			maxGifts: 10,
+			wantIDs:  []string{"g2"},
+		},
+		{
+			name:     "range lower bound excludes closer sums",
+			prices:   []string{"2", "3"},
+			target:   rangeTarget(t, "5", "9"),
+			maxGifts: 10,
+			wantIDs:  []string{"g0", "g1"},
+		},
+		{
+			name:     "range upper bound excludes closer sums",
+			prices:   []string{"4", "6.5"},
+			target:   rangeTarget(t, "1", "6"),
+			maxGifts: 10,
+			wantIDs:  []string{"g0"},
+		},
+		{
+			name:     "max gifts limits the combination",
+			prices:   []string{"1", "1.5", "2", "3"},
+			target:   amountTarget(t, "6.5"),
+			maxGifts: 2,
+			wantIDs:  []string{"g2", "g3"},
+		},
+		{
+			name:     "max gifts of one picks the closest single gift",
+			prices:   []string{"1", "1.5", "2", "3"},
+			target:   amountTarget(t, "6.5"),
+			maxGifts: 1,
+			wantIDs:  []string{"g3"},
+		},
+		{
+			name:     "gifts without price are skipped",
+			prices:   []string{"", "2", "0"},
+			target:   amountTarget(t, "2"),
+			maxGifts: 10,
+			wantIDs:  []string{"g1"},
+		},
+		{
+			name:     "coarsened units keep exact prices",
+			prices:   []string{"30000.03", "20000.04", "10000"},
+			target:   amountTarget(t, "50000.07"),
+			maxGifts: 10,
+			wantIDs:  []string{"g0", "g1"},
+		},
+		{
+			name:     "coarsened units recheck range on exact prices",
+			prices:   []string{"30000.03", "20000.04"},
+			target:   rangeTarget(t, "50000.06", "50000.06"),
+			maxGifts: 10,
+			wantErr:  gift.ErrNoStakeSuggestion,
+		},
+		{
+			name:     "empty inventory",
+			prices:   nil,
+			target:   amountTarget(t, "5"),
+			maxGifts: 10,
+			wantErr:  gift.ErrNoStakeSuggestion,
+		},
+		{
+			name:     "only gifts without price",
+			prices:   []string{"", "0"},
+			target:   amountTarget(t, "5"),
+			maxGifts: 10,
+			wantErr:  gift.ErrNoStakeSuggestion,
+		},
+		{
+			name:     "inventory too small for range",
+			prices:   []string{"1", "1"},
+			target:   rangeTarget(t, "5", "6"),
+			maxGifts: 10,
+			wantErr:  gift.ErrNoStakeSuggestion,
+		},
+		{
+			name:     "range needs more gifts than allowed",
+			prices:   []string{"1", "1", "1"},
+			target:   rangeTarget(t, "3", "3"),
+			maxGifts: 2,
+			wantErr:  gift.ErrNoStakeSuggestion,
+		},
+		{
+			name:     "non-positive max gifts",
+			prices:   []string{"1"},
+			target:   amountTarget(t, "1"),
+			maxGifts: 0,
+			wantErr:  gift.ErrInvalidStakeTarget,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			picked, err := gift.SuggestStake(makeGifts(t, tt.prices...), tt.target, tt.maxGifts)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected error %v, got %v (picked %d gifts)", tt.wantErr, err, len(picked))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			ids := make([]string, len(picked))
+			for i, g := range picked {
+				ids[i] = g.ID
+			}
+			slices.Sort(ids)
+			if !slices.Equal(ids, tt.wantIDs) {
+				t.Errorf("picked %v, want %v", ids, tt.wantIDs)
+			}
+		})
+	}
+}
+
+func TestNewStakeTargetRange(t *testing.T) {
+	tests := []struct {
+		name    string
+		min     string
+		max     string
+		wantMid string
+		wantErr bool
+	}{
+		{name: "midpoint", min: "4", max: "7", wantMid: "5.5"},
+		{name: "single point", min: "3", max: "3", wantMid: "3"},
+		{name: "midpoint rounds to cents", min: "0.01", max: "0.02", wantMid: "0.02"},
+		{name: "min above max", min: "5", max: "4", wantErr: true},
+		{name: "zero max", min: "0", max: "0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := gift.NewStakeTargetRange(mustTon(t, tt.min), mustTon(t, tt.max))
+			if tt.wantErr {
+				if !errors.Is(err, gift.ErrInvalidStakeTarget) {
+					t.Fatalf("expected ErrInvalidStakeTarget, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if target.Amount.String() != tt.wantMid {
+				t.Errorf("midpoint = %s, want %s", target.Amount, tt.wantMid)
+			}
+		})
+	}
+}
+
+func TestStakeTargetCandidatePriceCap(t *testing.T) {
+	if got := amountTarget(t, "2.5").CandidatePriceCap().String(); got != "5" {
+		t.Errorf("amount target cap = %s, want 5", got)
+	}
+	if got := rangeTarget(t, "1", "3").CandidatePriceCap().String(); got != "3" {
+		t.Errorf("range target cap = %s, want 3", got)
+	}
+}
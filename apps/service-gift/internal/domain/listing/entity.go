@@ -0,0 +1,77 @@
+package listing
+
+import (
+	"time"
+
+	"github.com/peterparker2005/giftduels/packages/tonamount-go"
+	"github.com/shopspring/decimal"
+)
+
+type Listing struct {
+	ID               string
+	GiftID           string
+	SellerTelegramID int64
+	BuyerTelegramID  *int64
+	Price            *tonamount.TonAmount
+	Fee              *tonamount.TonAmount
+	Status           Status
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	SoldAt           *time.Time
+}
+
+type Status string
+
+const (
+	StatusActive    Status = "active"
+	StatusSold      Status = "sold"
+	StatusCancelled Status = "cancelled"
+)
+
+// IsActive checks if the listing can still be bought or cancelled.
+func (l *Listing) IsActive() bool {
+	return l.Status == StatusActive
+}
+
+// IsSoldBy checks if the listing belongs to the specified seller.
+func (l *Listing) IsSoldBy(telegramUserID int64) bool {
+	return l.SellerTelegramID == telegramUserID
+}
+
+// CanBeBoughtBy checks if the listing can be bought by the specified user.
+func (l *Listing) CanBeBoughtBy(telegramUserID int64) error {
+	if !l.IsActive() {
+		return ErrListingNotActive
+	}
+	if l.IsSoldBy(telegramUserID) {
+		return ErrCannotBuyOwnListing
+	}
+	return nil
+}
+
+// CanBeCancelledBy checks if the listing can be cancelled by the specified user.
+func (l *Listing) CanBeCancelledBy(telegramUserID int64) error {
+	if !l.IsActive() {
+		return ErrListingNotActive
+	}
+	if !l.IsSoldBy(telegramUserID) {
+		return ErrListingNotOwned
+	}
+	return nil
+}
+
+// CalculateFee returns the platform fee and the seller payout for the given price.
+func CalculateFee(
+	price *tonamount.TonAmount,
+	feePercent decimal.Decimal,
+) (*tonamount.TonAmount, *tonamount.TonAmount, error) {
+	fee, err := price.Percent(feePercent)
+	if err != nil {
+		return nil, nil, err
+	}
+	payout, err := tonamount.NewTonAmountFromString(price.Sub(fee).String())
+	if err != nil {
+		return nil, nil, err
+	}
+	return fee, payout, nil
+}
@@ -0,0 +1,31 @@
+package listing
+
+import "errors"
+
+var (
+	ErrListingNotFound     = errors.New("listing not found")
+	ErrListingNotActive    = errors.New("listing is not active")
+	ErrListingNotOwned     = errors.New("listing not owned")
+	ErrCannotBuyOwnListing = errors.New("cannot buy own listing")
+	ErrInvalidPrice        = errors.New("listing price must be positive")
+)
+
+func IsListingNotFound(err error) bool {
+	return errors.Is(err, ErrListingNotFound)
+}
+
+func IsListingNotActive(err error) bool {
+	return errors.Is(err, ErrListingNotActive)
+}
+
+func IsListingNotOwned(err error) bool {
+	return errors.Is(err, ErrListingNotOwned)
+}
+
+func IsCannotBuyOwnListing(err error) bool {
+	return errors.Is(err, ErrCannotBuyOwnListing)
+}
+
+func IsInvalidPrice(err error) bool {
+	return errors.Is(err, ErrInvalidPrice)
+}
@@ -0,0 +1,50 @@
+package listing
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/peterparker2005/giftduels/packages/tonamount-go"
+)
+
+type CreateListingParams struct {
+	GiftID           string
+	SellerTelegramID int64
+	Price            *tonamount.TonAmount
+}
+
+type MarkListingSoldParams struct {
+	ID              string
+	BuyerTelegramID int64
+	Fee             *tonamount.TonAmount
+}
+
+// Filter ограничивает выборку активных лотов. Пустые поля не фильтруют.
+type Filter struct {
+	Collection *string
+	Model      *string
+	Backdrop   *string
+	MinPrice   *tonamount.TonAmount
+	MaxPrice   *tonamount.TonAmount
+}
+
+type GetActiveListingsResult struct {
+	Listings []*Listing
+	Total    int64
+}
+
+type Repository interface {
+	WithTx(tx pgx.Tx) Repository
+	CreateListing(ctx context.Context, params *CreateListingParams) (*Listing, error)
+	GetListingByID(ctx context.Context, id string) (*Listing, error)
+	// GetListingByIDForUpdate блокирует строку лота до конца транзакции.
+	GetListingByIDForUpdate(ctx context.Context, id string) (*Listing, error)
+	CancelListing(ctx context.Context, id string) (*Listing, error)
+	MarkListingSold(ctx context.Context, params *MarkListingSoldParams) (*Listing, error)
+	GetActiveListings(
+		ctx context.Context,
+		filter *Filter,
+		limit int32,
+		offset int32,
+	) (*GetActiveListingsResult, error)
+}
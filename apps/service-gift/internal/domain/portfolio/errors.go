@@ -0,0 +1,9 @@
+package portfolio
+
+import "errors"
+
+var ErrInvalidHistoryRange = errors.New("portfolio history range start is after its end")
+
+func IsInvalidHistoryRange(err error) bool {
+	return errors.Is(err, ErrInvalidHistoryRange)
+}
@@ -0,0 +1,50 @@
+package portfolio
+
+import (
+	"time"
+
+	"github.com/peterparker2005/giftduels/packages/tonamount-go"
+)
+
+// Valuation — текущая стоимость подарков пользователя по gifts.price.
+// Owned включает подарки на руках, выставленные на маркетплейс и ожидающие
+// вывода; InGame — подарки, поставленные в незавершённые дуэли.
+type Valuation struct {
+	OwnedValue  *tonamount.TonAmount
+	InGameValue *tonamount.TonAmount
+	OwnedCount  int64
+	InGameCount int64
+}
+
+func (v *Valuation) TotalValue() *tonamount.TonAmount {
+	return v.OwnedValue.Add(v.InGameValue)
+}
+
+// Performance — обороты пользователя по истории gift_events. Журнал событий
+// не хранит цену на момент события, поэтому все суммы посчитаны по текущим
+// ценам подарков.
+type Performance struct {
+	DepositedValue *tonamount.TonAmount
+	WithdrawnValue *tonamount.TonAmount
+	// DuelWonValue — подарки соперников, полученные в выигранных дуэлях.
+	DuelWonValue *tonamount.TonAmount
+	// DuelLostValue — собственные ставки, ушедшие победителю.
+	DuelLostValue *tonamount.TonAmount
+}
+
+// ProfitLoss — результат дуэлей: депозиты и выводы лишь перемещают подарки
+// и на прибыль не влияют.
+func (p *Performance) ProfitLoss() *tonamount.TonAmount {
+	return p.DuelWonValue.Sub(p.DuelLostValue)
+}
+
+// Snapshot — стоимость портфеля пользователя на конец дня Date (UTC).
+type Snapshot struct {
+	Date      time.Time
+	Valuation Valuation
+}
+
+// SnapshotDate усекает момент времени до даты снапшота.
+func SnapshotDate(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
@@ -0,0 +1,90 @@
+package portfolio_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/portfolio"
+	"github.com/peterparker2005/giftduels/packages/tonamount-go"
+)
+
+func mustTon(t *testing.T, s string) *tonamount.TonAmount {
+	t.Helper()
+	a, err := tonamount.NewTonAmountFromString(s)
+	if err != nil {
+		t.Fatalf("cannot parse TonAmount from %q: %v", s, err)
+	}
+	return a
+}
+
+func TestProfitLoss(t *testing.T) {
+	tests := []struct {
+		name      string
+		deposited string
+		withdrawn string
+		won       string
+		lost      string
+		want      string
+	}{
+		{name: "no duels", deposited: "10", withdrawn: "0", won: "0", lost: "0", want: "0"},
+		{name: "net win", deposited: "10", withdrawn: "0", won: "7.5", lost: "2.25", want: "5.25"},
+		{name: "net loss", deposited: "10", withdrawn: "0", won: "1", lost: "4", want: "-3"},
+		// депозиты и выводы лишь перемещают подарки
+		{name: "transfers do not count", deposited: "100", withdrawn: "60", won: "3", lost: "3", want: "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &portfolio.Performance{
+				DepositedValue: mustTon(t, tt.deposited),
+				WithdrawnValue: mustTon(t, tt.withdrawn),
+				DuelWonValue:   mustTon(t, tt.won),
+				DuelLostValue:  mustTon(t, tt.lost),
+			}
+			if got := p.ProfitLoss().String(); got != tt.want {
+				t.Errorf("profit/loss %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValuationTotalValue(t *testing.T) {
+	v := &portfolio.Valuation{
+		OwnedValue:  mustTon(t, "12.5"),
+		InGameValue: mustTon(t, "3.75"),
+		OwnedCount:  4,
+		InGameCount: 1,
+	}
+	if got := v.TotalValue().String(); got != "16.25" {
+		t.Errorf("total %s, want 16.25", got)
+	}
+}
+
+func TestSnapshotDate(t *testing.T) {
+	msk := time.FixedZone("MSK", 3*60*60)
+	want := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want time.Time
+	}{
+		{name: "start of day", at: want, want: want},
+		{name: "end of day", at: time.Date(2026, 10, 19, 23, 59, 59, 0, time.UTC), want: want},
+		// дата снапшота считается в UTC, а не в поясе вызывающего
+		{name: "local time before utc midnight", at: time.Date(2026, 10, 20, 2, 0, 0, 0, msk), want: want},
+		{
+			name: "local time after utc midnight",
+			at:   time.Date(2026, 10, 20, 3, 0, 0, 0, msk),
+			want: want.Add(24 * time.Hour),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := portfolio.SnapshotDate(tt.at); !got.Equal(tt.want) || got.Location() != time.UTC {
+				t.Errorf("snapshot date %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
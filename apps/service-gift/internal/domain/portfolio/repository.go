@@ -0,0 +1,16 @@
+package portfolio
+
+import (
+	"context"
+	"time"
+)
+
+type Repository interface {
+	GetUserValuation(ctx context.Context, telegramUserID int64) (*Valuation, error)
+	GetUserPerformance(ctx context.Context, telegramUserID int64) (*Performance, error)
+	// GetUserSnapshots возвращает снапшоты за [from, to] включительно по возрастанию даты.
+	GetUserSnapshots(ctx context.Context, telegramUserID int64, from, to time.Time) ([]*Snapshot, error)
+	// RecordSnapshots записывает (или перезаписывает) снапшот за date для всех
+	// владельцев подарков и возвращает число затронутых строк.
+	RecordSnapshots(ctx context.Context, date time.Time) (int64, error)
+}
@@ -0,0 +1,12 @@
+package reconciliation
+
+import "errors"
+
+var (
+	ErrRunNotFound          = errors.New("reconciliation run not found")
+	ErrCustodyNotConfigured = errors.New("telegram custody service is not configured")
+)
+
+func IsRunNotFound(err error) bool {
+	return errors.Is(err, ErrRunNotFound)
+}
@@ -0,0 +1,98 @@
+package reconciliation
+
+import (
+	"time"
+
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/gift"
+)
+
+type DiscrepancyKind string
+
+const (
+	// KindMissingInCustody — подарок числится за площадкой, но на
+	// custody-аккаунте его нет (например, его уже передали наружу).
+	KindMissingInCustody DiscrepancyKind = "missing_in_custody"
+	// KindMissingInLedger — подарок лежит на custody-аккаунте, но записи
+	// в gifts нет (например, потерялось сообщение gift.received).
+	KindMissingInLedger DiscrepancyKind = "missing_in_ledger"
+)
+
+// Key однозначно идентифицирует NFT-подарок в Telegram.
+type Key struct {
+	TelegramGiftID int64
+	CollectibleID  int32
+}
+
+// CustodyGift — подарок из инвентаря custody-аккаунта.
+type CustodyGift struct {
+	Key
+	Title string
+	Slug  string
+}
+
+// LedgerGift — подарок из gifts в любом статусе, кроме withdrawn.
+type LedgerGift struct {
+	Key
+	GiftID string
+	Status gift.Status
+	Title  string
+	Slug   string
+}
+
+type Discrepancy struct {
+	Kind  DiscrepancyKind
+	Key   Key
+	Title string
+	Slug  string
+	// GiftID и GiftStatus заполнены только для KindMissingInCustody
+	GiftID     *string
+	GiftStatus *gift.Status
+}
+
+type Run struct {
+	ID            string
+	CustodyCount  int
+	LedgerCount   int
+	Discrepancies []*Discrepancy
+	StartedAt     time.Time
+	FinishedAt    time.Time
+}
+
+// Diff сопоставляет записи gifts с инвентарём custody-аккаунта по Key.
+func Diff(ledger []*LedgerGift, custody []*CustodyGift) []*Discrepancy {
+	inCustody := make(map[Key]struct{}, len(custody))
+	for _, c := range custody {
+		inCustody[c.Key] = struct{}{}
+	}
+	inLedger := make(map[Key]struct{}, len(ledger))
+	for _, l := range ledger {
+		inLedger[l.Key] = struct{}{}
+	}
+
+	var out []*Discrepancy
+	for _, l := range ledger {
+		if _, ok := inCustody[l.Key]; ok {
+			continue
+		}
+		out = append(out, &Discrepancy{
+			Kind:       KindMissingInCustody,
+			Key:        l.Key,
+			Title:      l.Title,
+			Slug:       l.Slug,
+			GiftID:     &l.GiftID,
+			GiftStatus: &l.Status,
+		})
+	}
+	for _, c := range custody {
+		if _, ok := inLedger[c.Key]; ok {
+			continue
+		}
+		out = append(out, &Discrepancy{
+			Kind:  KindMissingInLedger,
+			Key:   c.Key,
+			Title: c.Title,
+			Slug:  c.Slug,
+		})
+	}
+	return out
+}
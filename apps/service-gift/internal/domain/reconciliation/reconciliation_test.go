@@ -0,0 +1,124 @@
+package reconciliation_test
+
+import (
+	"testing"
+
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/gift"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/reconciliation"
+)
+
+func ledgerGift(id string, telegramGiftID int64, collectibleID int32) *reconciliation.LedgerGift {
+	return &reconciliation.LedgerGift{
+		Key:    reconciliation.Key{TelegramGiftID: telegramGiftID, CollectibleID: collectibleID},
+		GiftID: id,
+		Status: gift.StatusOwned,
+		Title:  "ledger " + id,
+	}
+}
+
+func custodyGift(telegramGiftID int64, collectibleID int32) *reconciliation.CustodyGift {
+	return &reconciliation.CustodyGift{
+		Key:   reconciliation.Key{TelegramGiftID: telegramGiftID, CollectibleID: collectibleID},
+		Title: "custody",
+	}
+}
+
+// want — ожидаемое расхождение: вид, ключ и ID подарка из gifts.
+type want struct {
+	kind           reconciliation.DiscrepancyKind
+	telegramGiftID int64
+	collectibleID  int32
+	giftID         string
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name    string
+		ledger  []*reconciliation.LedgerGift
+		custody []*reconciliation.CustodyGift
+		want    []want
+	}{
+		{
+			name: "both empty",
+		},
+		{
+			name:    "in sync",
+			ledger:  []*reconciliation.LedgerGift{ledgerGift("g1", 100, 1), ledgerGift("g2", 100, 2)},
+			custody: []*reconciliation.CustodyGift{custodyGift(100, 2), custodyGift(100, 1)},
+		},
+		{
+			name:   "gift left custody",
+			ledger: []*reconciliation.LedgerGift{ledgerGift("g1", 100, 1), ledgerGift("g2", 200, 7)},
+			custody: []*reconciliation.CustodyGift{
+				custodyGift(100, 1),
+			},
+			want: []want{
+				{kind: reconciliation.KindMissingInCustody, telegramGiftID: 200, collectibleID: 7, giftID: "g2"},
+			},
+		},
+		{
+			name:    "deposit never reached the ledger",
+			ledger:  []*reconciliation.LedgerGift{ledgerGift("g1", 100, 1)},
+			custody: []*reconciliation.CustodyGift{custodyGift(100, 1), custodyGift(300, 5)},
+			want:    []want{{kind: reconciliation.KindMissingInLedger, telegramGiftID: 300, collectibleID: 5}},
+		},
+		{
+			// подарки одной коллекции различаются только collectible_id
+			name:    "same telegram gift id, different collectible",
+			ledger:  []*reconciliation.LedgerGift{ledgerGift("g1", 100, 1)},
+			custody: []*reconciliation.CustodyGift{custodyGift(100, 2)},
+			want: []want{
+				{kind: reconciliation.KindMissingInCustody, telegramGiftID: 100, collectibleID: 1, giftID: "g1"},
+				{kind: reconciliation.KindMissingInLedger, telegramGiftID: 100, collectibleID: 2},
+			},
+		},
+		{
+			name:    "same collectible, different telegram gift id",
+			ledger:  []*reconciliation.LedgerGift{ledgerGift("g1", 100, 1)},
+			custody: []*reconciliation.CustodyGift{custodyGift(101, 1)},
+			want: []want{
+				{kind: reconciliation.KindMissingInCustody, telegramGiftID: 100, collectibleID: 1, giftID: "g1"},
+				{kind: reconciliation.KindMissingInLedger, telegramGiftID: 101, collectibleID: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := reconciliation.Diff(tt.ledger, tt.custody)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d discrepancies, want %d", len(got), len(tt.want))
+			}
+			for i, w := range tt.want {
+				d := got[i]
+				wantKey := reconciliation.Key{TelegramGiftID: w.telegramGiftID, CollectibleID: w.collectibleID}
+				if d.Kind != w.kind || d.Key != wantKey {
+					t.Errorf("discrepancy %d = %s %+v, want %s %d/%d",
+						i, d.Kind, d.Key, w.kind, w.telegramGiftID, w.collectibleID)
+				}
+				if w.kind == reconciliation.KindMissingInLedger {
+					// в gifts записи нет — нечего и показывать
+					if d.GiftID != nil || d.GiftStatus != nil || d.Title != "custody" {
+						t.Errorf("discrepancy %d = %+v, want custody data only", i, d)
+					}
+					continue
+				}
+				if d.GiftID == nil || *d.GiftID != w.giftID {
+					t.Errorf("discrepancy %d = %+v, want gift %s", i, d, w.giftID)
+				}
+				if d.GiftStatus == nil || *d.GiftStatus != gift.StatusOwned {
+					t.Errorf("discrepancy %d status = %v, want %s", i, d.GiftStatus, gift.StatusOwned)
+				}
+			}
+		})
+	}
+}
+
+// Расхождения ссылаются на свои подарки, а не на общую переменную цикла.
+func TestDiffKeepsGiftIDsDistinct(t *testing.T) {
+	ledger := []*reconciliation.LedgerGift{ledgerGift("g1", 1, 1), ledgerGift("g2", 2, 2)}
+	got := reconciliation.Diff(ledger, nil)
+	if len(got) != 2 || *got[0].GiftID != "g1" || *got[1].GiftID != "g2" {
+		t.Fatalf("discrepancies %+v", got)
+	}
+}
@@ -0,0 +1,16 @@
+package reconciliation
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type Repository interface {
+	WithTx(tx pgx.Tx) Repository
+	ListLedgerGifts(ctx context.Context) ([]*LedgerGift, error)
+	// SaveRun сохраняет прогон вместе с расхождениями и заполняет run.ID и run.FinishedAt.
+	SaveRun(ctx context.Context, run *Run) error
+	GetRun(ctx context.Context, id string) (*Run, error)
+	GetLatestRun(ctx context.Context) (*Run, error)
+}
@@ -0,0 +1,58 @@
+package sellback
+
+import (
+	"time"
+
+	"github.com/peterparker2005/giftduels/packages/tonamount-go"
+	"github.com/shopspring/decimal"
+)
+
+type Quote struct {
+	ID             string
+	GiftID         string
+	TelegramUserID int64
+	FloorPrice     *tonamount.TonAmount
+	Amount         *tonamount.TonAmount
+	ExpiresAt      time.Time
+	ExecutedAt     *time.Time
+	CreatedAt      time.Time
+}
+
+// IsExpired checks if the quote can no longer be executed at the given time.
+func (q *Quote) IsExpired(at time.Time) bool {
+	return !at.Before(q.ExpiresAt)
+}
+
+// IsExecuted checks if the quote has already been used.
+func (q *Quote) IsExecuted() bool {
+	return q.ExecutedAt != nil
+}
+
+// CanBeExecutedBy checks if the quote can be executed by the specified user.
+func (q *Quote) CanBeExecutedBy(telegramUserID int64, at time.Time) error {
+	if q.TelegramUserID != telegramUserID {
+		return ErrQuoteNotOwned
+	}
+	if q.IsExecuted() {
+		return ErrQuoteAlreadyExecuted
+	}
+	if q.IsExpired(at) {
+		return ErrQuoteExpired
+	}
+	return nil
+}
+
+// CalculateAmount returns the house offer as a percentage of the floor price.
+func CalculateAmount(
+	floorPrice *tonamount.TonAmount,
+	payoutPercent decimal.Decimal,
+) (*tonamount.TonAmount, error) {
+	amount, err := floorPrice.Percent(payoutPercent)
+	if err != nil {
+		return nil, err
+	}
+	if !amount.Decimal().IsPositive() {
+		return nil, ErrInvalidAmount
+	}
+	return amount, nil
+}
@@ -0,0 +1,31 @@
+package sellback
+
+import "errors"
+
+var (
+	ErrQuoteNotFound        = errors.New("sell-back quote not found")
+	ErrQuoteNotOwned        = errors.New("sell-back quote not owned")
+	ErrQuoteExpired         = errors.New("sell-back quote expired")
+	ErrQuoteAlreadyExecuted = errors.New("sell-back quote already executed")
+	ErrInvalidAmount        = errors.New("sell-back amount must be positive")
+)
+
+func IsQuoteNotFound(err error) bool {
+	return errors.Is(err, ErrQuoteNotFound)
+}
+
+func IsQuoteNotOwned(err error) bool {
+	return errors.Is(err, ErrQuoteNotOwned)
+}
+
+func IsQuoteExpired(err error) bool {
+	return errors.Is(err, ErrQuoteExpired)
+}
+
+func IsQuoteAlreadyExecuted(err error) bool {
+	return errors.Is(err, ErrQuoteAlreadyExecuted)
+}
+
+func IsInvalidAmount(err error) bool {
+	return errors.Is(err, ErrInvalidAmount)
+}
@@ -0,0 +1,25 @@
+package sellback
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/peterparker2005/giftduels/packages/tonamount-go"
+)
+
+type CreateQuoteParams struct {
+	GiftID         string
+	TelegramUserID int64
+	FloorPrice     *tonamount.TonAmount
+	Amount         *tonamount.TonAmount
+	ExpiresAt      time.Time
+}
+
+type Repository interface {
+	WithTx(tx pgx.Tx) Repository
+	CreateQuote(ctx context.Context, params *CreateQuoteParams) (*Quote, error)
+	// GetQuoteByIDForUpdate блокирует строку котировки до конца транзакции.
+	GetQuoteByIDForUpdate(ctx context.Context, id string) (*Quote, error)
+	MarkQuoteExecuted(ctx context.Context, id string) (*Quote, error)
+}
@@ -0,0 +1,27 @@
+package withdrawal
+
+import "errors"
+
+var (
+	ErrIntentNotFound          = errors.New("withdrawal intent not found")
+	ErrIntentAlreadyExists     = errors.New("gifts already have a pending withdrawal invoice")
+	ErrWithdrawalNotFound      = errors.New("withdrawal not found")
+	ErrWithdrawalGiftNotFound  = errors.New("gift is not part of the withdrawal")
+	ErrInvalidStatusTransition = errors.New("invalid withdrawal status transition")
+)
+
+func IsIntentNotFound(err error) bool {
+	return errors.Is(err, ErrIntentNotFound)
+}
+
+func IsIntentAlreadyExists(err error) bool {
+	return errors.Is(err, ErrIntentAlreadyExists)
+}
+
+func IsWithdrawalNotFound(err error) bool {
+	return errors.Is(err, ErrWithdrawalNotFound)
+}
+
+func IsInvalidStatusTransition(err error) bool {
+	return errors.Is(err, ErrInvalidStatusTransition)
+}
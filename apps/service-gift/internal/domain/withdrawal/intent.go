@@ -0,0 +1,42 @@
+package withdrawal
+
+import "time"
+
+// IntentStatus — состояние намерения вывода за Stars.
+type IntentStatus string
+
+const (
+	// IntentStatusPending — инвойс выставлен, подарки удерживаются до оплаты.
+	IntentStatusPending IntentStatus = "pending"
+	// IntentStatusCompleted — инвойс оплачен, подарки отправлены на вывод.
+	IntentStatusCompleted IntentStatus = "completed"
+	// IntentStatusExpired — инвойс не оплачен вовремя, подарки освобождены.
+	IntentStatusExpired IntentStatus = "expired"
+	// IntentStatusPaidAfterExpiry — инвойс оплачен после истечения, а подарки
+	// уже заняты; оплату нужно вернуть.
+	IntentStatusPaidAfterExpiry IntentStatus = "paid_after_expiry"
+)
+
+// Intent — выставленный Stars-инвойс на вывод набора подарков.
+type Intent struct {
+	ID             string
+	TelegramUserID int64
+	GiftIDs        []string
+	StarsAmount    int64
+	InvoiceURL     string
+	Status         IntentStatus
+	ExpiresAt      time.Time
+	CompletedAt    *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// IsPending checks if the invoice is still awaiting payment.
+func (i *Intent) IsPending() bool {
+	return i.Status == IntentStatusPending
+}
+
+// IsExpired checks if the invoice expired before it was paid.
+func (i *Intent) IsExpired() bool {
+	return i.Status == IntentStatusExpired
+}
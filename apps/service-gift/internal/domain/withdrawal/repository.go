@@ -0,0 +1,63 @@
+package withdrawal
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type CreateIntentParams struct {
+	TelegramUserID int64
+	GiftIDs        []string
+	StarsAmount    int64
+	InvoiceURL     string
+	ExpiresAt      time.Time
+}
+
+type GetUserWithdrawalsResult struct {
+	Withdrawals []*Withdrawal
+	Total       int64
+}
+
+type Repository interface {
+	WithTx(tx pgx.Tx) Repository
+	CreateIntent(ctx context.Context, params *CreateIntentParams) (*Intent, error)
+	GetIntentByID(ctx context.Context, id string) (*Intent, error)
+	// HasPendingIntentForGifts проверяет, не ждёт ли оплаты инвойс хотя бы на один из подарков.
+	HasPendingIntentForGifts(ctx context.Context, giftIDs []string) (bool, error)
+	// GetLatestIntentByGiftsForUpdate блокирует последний инвойс пользователя,
+	// покрывающий все переданные подарки.
+	GetLatestIntentByGiftsForUpdate(
+		ctx context.Context,
+		telegramUserID int64,
+		giftIDs []string,
+	) (*Intent, error)
+	// GetExpiredIntentsForUpdate блокирует просроченные pending-инвойсы,
+	// пропуская уже заблокированные другим sweeper'ом.
+	GetExpiredIntentsForUpdate(ctx context.Context, now time.Time, limit int32) ([]*Intent, error)
+	MarkIntentCompleted(ctx context.Context, id string) (*Intent, error)
+	MarkIntentExpired(ctx context.Context, id string) (*Intent, error)
+	// MarkIntentPaidAfterExpiry отмечает истёкший инвойс, оплата которого
+	// пришла, когда подарки были уже недоступны.
+	MarkIntentPaidAfterExpiry(ctx context.Context, id string) (*Intent, error)
+
+	// CreateWithdrawal сохраняет вывод вместе с подарками.
+	CreateWithdrawal(ctx context.Context, w *Withdrawal) (*Withdrawal, error)
+	GetWithdrawalByID(ctx context.Context, id string) (*Withdrawal, error)
+	GetWithdrawalByIntentIDForUpdate(ctx context.Context, intentID string) (*Withdrawal, error)
+	// GetActiveWithdrawalByGiftForUpdate блокирует вывод, в котором подарок ещё не доставлен.
+	GetActiveWithdrawalByGiftForUpdate(ctx context.Context, giftID string) (*Withdrawal, error)
+	// GetUserWithdrawals возвращает выводы пользователя, новые первыми.
+	// Пустой statuses не фильтрует.
+	GetUserWithdrawals(
+		ctx context.Context,
+		telegramUserID int64,
+		statuses []Status,
+		limit int32,
+		offset int32,
+	) (*GetUserWithdrawalsResult, error)
+	// UpdateWithdrawalStatus сохраняет статус и причину неудачи вывода.
+	UpdateWithdrawalStatus(ctx context.Context, w *Withdrawal) (*Withdrawal, error)
+	UpdateWithdrawalGiftStatus(ctx context.Context, withdrawalID string, g *Gift) error
+}
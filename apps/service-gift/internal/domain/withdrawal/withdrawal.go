@@ -0,0 +1,233 @@
+package withdrawal
+
+import "time"
+
+// Status — этап жизненного цикла запроса на вывод.
+type Status string
+
+const (
+	// StatusRequested — запрос создан, комиссия ещё не обработана.
+	StatusRequested Status = "requested"
+	// StatusAwaitingPayment — выставлен Stars-инвойс, ждём оплату.
+	StatusAwaitingPayment Status = "awaiting_payment"
+	// StatusSentToBot — комиссия получена, подарки переданы боту.
+	StatusSentToBot Status = "sent_to_bot"
+	// StatusCompleted — бот передал все подарки.
+	StatusCompleted Status = "completed"
+	// StatusFailed — вывод не удался, подарки возвращены в инвентарь.
+	StatusFailed Status = "failed"
+	// StatusRefunded — вывод отменён, комиссия возвращена на баланс.
+	StatusRefunded Status = "refunded"
+	// StatusRefundRequired — Stars-инвойс оплачен после истечения, подарки уже
+	// заняты; оплату нужно вернуть пользователю.
+	StatusRefundRequired Status = "refund_required"
+)
+
+// CommissionCurrency — валюта, в которой оплачена комиссия.
+type CommissionCurrency string
+
+const (
+	CommissionCurrencyTON   CommissionCurrency = "ton"
+	CommissionCurrencyStars CommissionCurrency = "stars"
+)
+
+// GiftStatus — состояние отдельного подарка внутри вывода.
+type GiftStatus string
+
+const (
+	GiftStatusPending   GiftStatus = "pending"
+	GiftStatusWithdrawn GiftStatus = "withdrawn"
+	GiftStatusFailed    GiftStatus = "failed"
+	GiftStatusRefunded  GiftStatus = "refunded"
+)
+
+// Причины неудачи, которые выставляет сам сервис.
+const (
+	ReasonInvoiceExpired  = "stars invoice expired"
+	ReasonUserNotFound    = "user has not started the bot"
+	ReasonPaidAfterExpiry = "stars invoice paid after expiry, gifts are no longer available"
+)
+
+// Gift — подарок в составе вывода.
+type Gift struct {
+	GiftID string
+	// CommissionAmount — комиссия за подарок в валюте вывода.
+	CommissionAmount string
+	Status           GiftStatus
+	FailureReason    *string
+}
+
+// Withdrawal — запрос пользователя на вывод набора подарков.
+type Withdrawal struct {
+	ID                 string
+	TelegramUserID     int64
+	CommissionCurrency CommissionCurrency
+	// CommissionAmount — суммарная комиссия: TON или целое число Stars.
+	CommissionAmount string
+	Status           Status
+	FailureReason    *string
+	// IntentID — Stars-инвойс, если комиссия оплачивается в Stars.
+	IntentID    *string
+	Gifts       []*Gift
+	CompletedAt *time.Time
+	FailedAt    *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// NewWithdrawal создаёт запрос на вывод в статусе requested.
+func NewWithdrawal(
+	telegramUserID int64,
+	currency CommissionCurrency,
+	commissionAmount string,
+	gifts []*Gift,
+) *Withdrawal {
+	for _, g := range gifts {
+		g.Status = GiftStatusPending
+	}
+	return &Withdrawal{
+		TelegramUserID:     telegramUserID,
+		CommissionCurrency: currency,
+		CommissionAmount:   commissionAmount,
+		Status:             StatusRequested,
+		Gifts:              gifts,
+	}
+}
+
+// IsOwnedBy checks if the withdrawal was requested by the specified user.
+func (w *Withdrawal) IsOwnedBy(telegramUserID int64) bool {
+	return w.TelegramUserID == telegramUserID
+}
+
+// Gift returns the withdrawal entry for the gift.
+func (w *Withdrawal) Gift(giftID string) (*Gift, error) {
+	for _, g := range w.Gifts {
+		if g.GiftID == giftID {
+			return g, nil
+		}
+	}
+	return nil, ErrWithdrawalGiftNotFound
+}
+
+// AwaitPayment ставит вывод в ожидание оплаты Stars-инвойса.
+func (w *Withdrawal) AwaitPayment(intentID string) error {
+	if w.Status != StatusRequested {
+		return ErrInvalidStatusTransition
+	}
+	w.Status = StatusAwaitingPayment
+	w.IntentID = &intentID
+	return nil
+}
+
+// SendToBot отмечает, что комиссия получена и подарки переданы боту.
+// Оплата истёкшего инвойса возобновляет вывод.
+func (w *Withdrawal) SendToBot() error {
+	switch w.Status {
+	case StatusRequested, StatusAwaitingPayment:
+	case StatusFailed:
+		if w.FailureReason == nil || *w.FailureReason != ReasonInvoiceExpired {
+			return ErrInvalidStatusTransition
+		}
+		for _, g := range w.Gifts {
+			g.Status = GiftStatusPending
+			g.FailureReason = nil
+		}
+	case StatusSentToBot, StatusCompleted, StatusRefunded, StatusRefundRequired:
+		return ErrInvalidStatusTransition
+	default:
+		return ErrInvalidStatusTransition
+	}
+	w.Status = StatusSentToBot
+	w.FailureReason = nil
+	return nil
+}
+
+// ExpirePayment отменяет вывод, инвойс которого не оплатили вовремя.
+func (w *Withdrawal) ExpirePayment() error {
+	if w.Status != StatusAwaitingPayment {
+		return ErrInvalidStatusTransition
+	}
+	reason := ReasonInvoiceExpired
+	for _, g := range w.Gifts {
+		g.Status = GiftStatusFailed
+		g.FailureReason = &reason
+	}
+	w.Status = StatusFailed
+	w.FailureReason = &reason
+	return nil
+}
+
+// RequireRefund отмечает, что истёкший инвойс всё же оплатили, но подарки
+// уже недоступны, и оплату нужно вернуть.
+func (w *Withdrawal) RequireRefund() error {
+	if w.Status != StatusFailed || w.FailureReason == nil || *w.FailureReason != ReasonInvoiceExpired {
+		return ErrInvalidStatusTransition
+	}
+	reason := ReasonPaidAfterExpiry
+	w.Status = StatusRefundRequired
+	w.FailureReason = &reason
+	return nil
+}
+
+// CompleteGift отмечает доставку подарка; после последнего вывод завершается.
+func (w *Withdrawal) CompleteGift(giftID string) (*Gift, error) {
+	return w.settleGift(giftID, GiftStatusWithdrawn, nil)
+}
+
+// FailGift отмечает, что бот не смог передать подарок.
+func (w *Withdrawal) FailGift(giftID string, reason string) (*Gift, error) {
+	return w.settleGift(giftID, GiftStatusFailed, &reason)
+}
+
+// RefundGift отмечает, что вывод подарка отменён с возвратом комиссии.
+func (w *Withdrawal) RefundGift(giftID string, reason string) (*Gift, error) {
+	return w.settleGift(giftID, GiftStatusRefunded, &reason)
+}
+
+func (w *Withdrawal) settleGift(giftID string, status GiftStatus, reason *string) (*Gift, error) {
+	g, err := w.Gift(giftID)
+	if err != nil {
+		return nil, err
+	}
+	if g.Status != GiftStatusPending {
+		return nil, ErrInvalidStatusTransition
+	}
+	g.Status = status
+	g.FailureReason = reason
+	w.resolveStatus()
+	return g, nil
+}
+
+// resolveStatus выводит статус вывода из состояний подарков. Пока есть
+// недоставленные подарки, вывод остаётся у бота, если ни один не сорвался.
+func (w *Withdrawal) resolveStatus() {
+	var pending, failed, refunded int
+	var reason *string
+	for _, g := range w.Gifts {
+		switch g.Status {
+		case GiftStatusPending:
+			pending++
+		case GiftStatusFailed:
+			failed++
+			reason = g.FailureReason
+		case GiftStatusRefunded:
+			refunded++
+			if reason == nil {
+				reason = g.FailureReason
+			}
+		case GiftStatusWithdrawn:
+		default:
+		}
+	}
+
+	switch {
+	case failed > 0:
+		w.Status = StatusFailed
+		w.FailureReason = reason
+	case refunded > 0:
+		w.Status = StatusRefunded
+		w.FailureReason = reason
+	case pending == 0:
+		w.Status = StatusCompleted
+	}
+}
@@ -0,0 +1,105 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/adapter/pg"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/reconciliation"
+	"github.com/peterparker2005/giftduels/packages/grpc-go/clients"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+	telegrambotv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/telegrambot/v1"
+	"go.uber.org/zap"
+)
+
+// CustodyReconciler сверяет gifts с инвентарём custody-аккаунта, на котором
+// userbot хранит задепоженные подарки, и сохраняет найденные расхождения.
+// Сами расхождения не исправляются: их разбирает оператор по отчёту.
+type CustodyReconciler struct {
+	repo    reconciliation.Repository
+	txMgr   pg.TxManager
+	custody telegrambotv1.TelegramCustodyPrivateServiceClient
+	log     *logger.Logger
+}
+
+func NewCustodyReconciler(
+	repo reconciliation.Repository,
+	txMgr pg.TxManager,
+	clients *clients.Clients,
+	log *logger.Logger,
+) *CustodyReconciler {
+	r := &CustodyReconciler{
+		repo:  repo,
+		txMgr: txMgr,
+		log:   log,
+	}
+	if clients.TelegramCustody != nil {
+		r.custody = clients.TelegramCustody.Private
+	}
+	return r
+}
+
+func (r *CustodyReconciler) Run(ctx context.Context) (*reconciliation.Run, error) {
+	if r.custody == nil {
+		return nil, reconciliation.ErrCustodyNotConfigured
+	}
+	startedAt := time.Now()
+
+	// Инвентарь берём до чтения gifts: подарок, пришедший между запросами,
+	// окажется только в custody и даст ложное missing_in_ledger, а не
+	// наоборот — такие записи разрешаются повторным прогоном.
+	inventory, err := r.custody.GetCustodyInventory(ctx, &telegrambotv1.GetCustodyInventoryRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("get custody inventory: %w", err)
+	}
+	custody := make([]*reconciliation.CustodyGift, len(inventory.GetGifts()))
+	for i, g := range inventory.GetGifts() {
+		custody[i] = &reconciliation.CustodyGift{
+			Key: reconciliation.Key{
+				TelegramGiftID: g.GetTelegramGiftId(),
+				CollectibleID:  g.GetCollectibleId(),
+			},
+			Title: g.GetTitle(),
+			Slug:  g.GetSlug(),
+		}
+	}
+
+	ledger, err := r.repo.ListLedgerGifts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list ledger gifts: %w", err)
+	}
+
+	run := &reconciliation.Run{
+		CustodyCount:  len(custody),
+		LedgerCount:   len(ledger),
+		Discrepancies: reconciliation.Diff(ledger, custody),
+		StartedAt:     startedAt,
+	}
+
+	tx, err := r.txMgr.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var commitErr error
+	defer func() {
+		if commitErr != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	if commitErr = r.repo.WithTx(tx).SaveRun(ctx, run); commitErr != nil {
+		return nil, fmt.Errorf("save reconciliation run: %w", commitErr)
+	}
+	if commitErr = tx.Commit(ctx); commitErr != nil {
+		return nil, commitErr
+	}
+
+	r.log.Info("custody reconciliation finished",
+		zap.String("runID", run.ID),
+		zap.Int("custodyCount", run.CustodyCount),
+		zap.Int("ledgerCount", run.LedgerCount),
+		zap.Int("discrepancies", len(run.Discrepancies)),
+	)
+	return run, nil
+}
@@ -0,0 +1,145 @@
+package command
+
+import (
+	"context"
+
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/adapter/pg"
+	favoriteDomain "github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/favorite"
+	giftDomain "github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/gift"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+	"go.uber.org/zap"
+)
+
+// FavoriteCommand управляет избранными подарками и пресетами ставок.
+type FavoriteCommand struct {
+	giftRepo     giftDomain.Repository
+	favoriteRepo favoriteDomain.Repository
+	txMgr        pg.TxManager
+	log          *logger.Logger
+}
+
+func NewFavoriteCommand(
+	giftRepo giftDomain.Repository,
+	favoriteRepo favoriteDomain.Repository,
+	txMgr pg.TxManager,
+	log *logger.Logger,
+) *FavoriteCommand {
+	return &FavoriteCommand{
+		giftRepo:     giftRepo,
+		favoriteRepo: favoriteRepo,
+		txMgr:        txMgr,
+		log:          log,
+	}
+}
+
+// AddFavorite добавляет подарок из инвентаря пользователя в избранное.
+func (c *FavoriteCommand) AddFavorite(
+	ctx context.Context,
+	telegramUserID int64,
+	giftID string,
+) error {
+	g, err := c.giftRepo.GetGiftByID(ctx, giftID)
+	if err != nil {
+		if pg.IsNotFound(err) {
+			return giftDomain.ErrGiftNotFound
+		}
+		return err
+	}
+	if !g.IsInInventoryOf(telegramUserID) {
+		return giftDomain.ErrGiftNotOwned
+	}
+
+	if err = c.favoriteRepo.AddFavorite(ctx, telegramUserID, giftID); err != nil {
+		c.log.Error("failed to add favorite gift",
+			zap.Int64("telegramUserID", telegramUserID),
+			zap.String("giftID", giftID),
+			zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// RemoveFavorite убирает подарок из избранного; отсутствие записи не ошибка.
+func (c *FavoriteCommand) RemoveFavorite(
+	ctx context.Context,
+	telegramUserID int64,
+	giftID string,
+) error {
+	_, err := c.favoriteRepo.RemoveFavorite(ctx, telegramUserID, giftID)
+	if err != nil {
+		c.log.Error("failed to remove favorite gift",
+			zap.Int64("telegramUserID", telegramUserID),
+			zap.String("giftID", giftID),
+			zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// SavePreset создаёт пресет или заменяет состав пресета с тем же названием.
+// Все подарки должны быть в инвентаре пользователя.
+func (c *FavoriteCommand) SavePreset(
+	ctx context.Context,
+	telegramUserID int64,
+	name string,
+	giftIDs []string,
+) (*favoriteDomain.Preset, error) {
+	name, err := favoriteDomain.NewPresetName(name)
+	if err != nil {
+		return nil, err
+	}
+	if err = favoriteDomain.ValidatePresetGifts(giftIDs); err != nil {
+		return nil, err
+	}
+
+	gifts, err := c.giftRepo.GetGiftsByIDs(ctx, giftIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(gifts) != len(giftIDs) {
+		return nil, giftDomain.ErrGiftNotFound
+	}
+	for _, g := range gifts {
+		if !g.IsInInventoryOf(telegramUserID) {
+			return nil, giftDomain.ErrGiftNotOwned
+		}
+	}
+
+	preset := &favoriteDomain.Preset{
+		TelegramUserID: telegramUserID,
+		Name:           name,
+		GiftIDs:        giftIDs,
+	}
+
+	tx, err := c.txMgr.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var commitErr error
+	defer func() {
+		if commitErr != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	if commitErr = c.favoriteRepo.WithTx(tx).SavePreset(ctx, preset); commitErr != nil {
+		c.log.Error("failed to save stake preset",
+			zap.Int64("telegramUserID", telegramUserID),
+			zap.String("name", name),
+			zap.Error(commitErr))
+		return nil, commitErr
+	}
+
+	if commitErr = tx.Commit(ctx); commitErr != nil {
+		return nil, commitErr
+	}
+	return preset, nil
+}
+
+func (c *FavoriteCommand) DeletePreset(
+	ctx context.Context,
+	telegramUserID int64,
+	presetID string,
+) error {
+	return c.favoriteRepo.DeletePreset(ctx, telegramUserID, presetID)
+}
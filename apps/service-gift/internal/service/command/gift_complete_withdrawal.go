@@ -10,17 +10,20 @@ import (
 )
 
 type GiftCompleteWithdrawalCommand struct {
-	repo giftDomain.Repository
-	log  *logger.Logger
+	repo              giftDomain.Repository
+	withdrawalTracker *WithdrawalTracker
+	log               *logger.Logger
 }
 
 func NewGiftCompleteWithdrawalCommand(
 	repo giftDomain.Repository,
+	withdrawalTracker *WithdrawalTracker,
 	log *logger.Logger,
 ) *GiftCompleteWithdrawalCommand {
 	return &GiftCompleteWithdrawalCommand{
-		repo: repo,
-		log:  log,
+		repo:              repo,
+		withdrawalTracker: withdrawalTracker,
+		log:               log,
 	}
 }
 
@@ -67,6 +70,11 @@ func (c *GiftCompleteWithdrawalCommand) Execute(
 		return nil, err
 	}
 
+	// Отмечаем доставку подарка в выводе
+	if err = c.withdrawalTracker.CompleteGift(ctx, params.GiftID); err != nil {
+		return nil, err
+	}
+
 	// Создаем событие о завершении вывода
 	_, err = c.repo.CreateGiftEvent(ctx, giftDomain.CreateGiftEventParams{
 		GiftID:         completedGift.ID,
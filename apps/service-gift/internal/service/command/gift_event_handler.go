@@ -121,7 +121,7 @@ func (h *GiftEventHandler) CreateGiftFromEvent(
 		TelegramGiftID:   ev.GetTelegramGiftId().GetValue(),
 	}
 
-	return h.repo.CreateGift(
+	created, err := h.repo.CreateGift(
 		ctx,
 		createParams,
 		attrs.CollectionID,
@@ -129,4 +129,19 @@ func (h *GiftEventHandler) CreateGiftFromEvent(
 		attrs.BackdropID,
 		attrs.SymbolID,
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Событие депозита нужно истории подарка и расчёту оборотов портфеля.
+	_, err = h.repo.CreateGiftEvent(ctx, giftDomain.CreateGiftEventParams{
+		GiftID:         created.ID,
+		TelegramUserID: created.OwnerTelegramID,
+		EventType:      giftDomain.EventTypeDeposit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create deposit event: %w", err)
+	}
+
+	return created, nil
 }
@@ -0,0 +1,188 @@
+package command
+
+import (
+	"context"
+
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/adapter/pg"
+	giftDomain "github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/gift"
+	listingDomain "github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/listing"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+	"github.com/peterparker2005/giftduels/packages/tonamount-go"
+	"go.uber.org/zap"
+)
+
+type GiftListingCommand struct {
+	repo        giftDomain.Repository
+	listingRepo listingDomain.Repository
+	txMgr       pg.TxManager
+	log         *logger.Logger
+}
+
+func NewGiftListingCommand(
+	repo giftDomain.Repository,
+	listingRepo listingDomain.Repository,
+	txMgr pg.TxManager,
+	log *logger.Logger,
+) *GiftListingCommand {
+	return &GiftListingCommand{
+		repo:        repo,
+		listingRepo: listingRepo,
+		txMgr:       txMgr,
+		log:         log,
+	}
+}
+
+type ListGiftParams struct {
+	GiftID         string
+	TelegramUserID int64
+	Price          *tonamount.TonAmount
+}
+
+// ListGift выставляет подарок на маркетплейс по указанной цене.
+func (c *GiftListingCommand) ListGift(
+	ctx context.Context,
+	params ListGiftParams,
+) (*listingDomain.Listing, error) {
+	log := c.log.With(
+		zap.String("giftID", params.GiftID),
+		zap.Int64("telegramUserID", params.TelegramUserID),
+	)
+
+	if params.Price == nil || !params.Price.Decimal().IsPositive() {
+		return nil, listingDomain.ErrInvalidPrice
+	}
+
+	tx, err := c.txMgr.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(ctx); rbErr != nil {
+				log.Error("rollback failed", zap.Error(rbErr))
+			}
+		}
+	}()
+
+	repo := c.repo.WithTx(tx)
+	listingRepo := c.listingRepo.WithTx(tx)
+
+	g, err := repo.GetGiftByID(ctx, params.GiftID)
+	if err != nil {
+		log.Error("failed to get gift for listing", zap.Error(err))
+		if pg.IsNotFound(err) {
+			err = giftDomain.ErrGiftNotFound
+		}
+		return nil, err
+	}
+
+	// Используем domain метод для валидации
+	if err = g.List(params.TelegramUserID); err != nil {
+		log.Error("gift cannot be listed",
+			zap.String("status", string(g.Status)),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	if _, err = repo.ListGift(ctx, params.GiftID); err != nil {
+		log.Error("failed to mark gift as listed", zap.Error(err))
+		return nil, err
+	}
+
+	l, err := listingRepo.CreateListing(ctx, &listingDomain.CreateListingParams{
+		GiftID:           params.GiftID,
+		SellerTelegramID: params.TelegramUserID,
+		Price:            params.Price,
+	})
+	if err != nil {
+		log.Error("failed to create listing", zap.Error(err))
+		return nil, err
+	}
+
+	_, err = repo.CreateGiftEvent(ctx, giftDomain.CreateGiftEventParams{
+		GiftID:         params.GiftID,
+		TelegramUserID: params.TelegramUserID,
+		EventType:      giftDomain.EventTypeList,
+	})
+	if err != nil {
+		log.Error("failed to create gift event", zap.Error(err))
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		log.Error("failed to commit tx", zap.Error(err))
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// UnlistGift снимает лот с маркетплейса и возвращает подарок владельцу.
+func (c *GiftListingCommand) UnlistGift(
+	ctx context.Context,
+	telegramUserID int64,
+	listingID string,
+) (*giftDomain.Gift, error) {
+	log := c.log.With(
+		zap.String("listingID", listingID),
+		zap.Int64("telegramUserID", telegramUserID),
+	)
+
+	tx, err := c.txMgr.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(ctx); rbErr != nil {
+				log.Error("rollback failed", zap.Error(rbErr))
+			}
+		}
+	}()
+
+	repo := c.repo.WithTx(tx)
+	listingRepo := c.listingRepo.WithTx(tx)
+
+	l, err := listingRepo.GetListingByIDForUpdate(ctx, listingID)
+	if err != nil {
+		log.Error("failed to get listing", zap.Error(err))
+		if pg.IsNotFound(err) {
+			err = listingDomain.ErrListingNotFound
+		}
+		return nil, err
+	}
+
+	if err = l.CanBeCancelledBy(telegramUserID); err != nil {
+		log.Error("listing cannot be cancelled", zap.Error(err))
+		return nil, err
+	}
+
+	if _, err = listingRepo.CancelListing(ctx, listingID); err != nil {
+		log.Error("failed to cancel listing", zap.Error(err))
+		return nil, err
+	}
+
+	g, err := repo.UnlistGift(ctx, l.GiftID)
+	if err != nil {
+		log.Error("failed to unlist gift", zap.Error(err))
+		return nil, err
+	}
+
+	_, err = repo.CreateGiftEvent(ctx, giftDomain.CreateGiftEventParams{
+		GiftID:         l.GiftID,
+		TelegramUserID: telegramUserID,
+		EventType:      giftDomain.EventTypeUnlist,
+	})
+	if err != nil {
+		log.Error("failed to create gift event", zap.Error(err))
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		log.Error("failed to commit tx", zap.Error(err))
+		return nil, err
+	}
+
+	return g, nil
+}
@@ -50,8 +50,18 @@ func (c *GiftReturnFromGameCommand) Execute(
 		}
 	}()
 	repo := c.repo.WithTx(tx)
-	if err = repo.UpdateGiftOwner(ctx, params.GiftID, params.OwnerID); err != nil {
-		c.logger.Error("failed to update gift owner", zap.Error(err))
+	// подарок передаётся, только пока его держит эта дуэль: после истёкшей
+	// аренды владелец мог снова выставить, продать или вывести его
+	err = repo.TransferGiftFromGame(ctx, params.GiftID, params.RelatedGameID, params.OwnerID)
+	if pg.IsNotFound(err) {
+		c.logger.Warn("gift is no longer held by the duel",
+			zap.String("giftID", params.GiftID),
+			zap.String("duelID", params.RelatedGameID))
+		err = giftdomain.ErrLeaseNotHeld
+		return err
+	}
+	if err != nil {
+		c.logger.Error("failed to transfer gift from game", zap.Error(err))
 		return err
 	}
 	_, err = repo.CreateGiftEvent(ctx, giftdomain.CreateGiftEventParams{
@@ -2,25 +2,40 @@ package command
 
 import (
 	"context"
+	"time"
 
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/adapter/pg"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/config"
 	giftDomain "github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/gift"
+	"github.com/peterparker2005/giftduels/packages/grpc-go/clients"
 	"github.com/peterparker2005/giftduels/packages/logger-go"
+	duelv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/duel/v1"
 	giftv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/gift/v1"
+	sharedv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/shared/v1"
 	"go.uber.org/zap"
 )
 
 type GiftStakeCommand struct {
-	repo giftDomain.Repository
-	log  *logger.Logger
+	repo              giftDomain.Repository
+	txMgr             pg.TxManager
+	duelPrivateClient duelv1.DuelPrivateServiceClient
+	leaseTTL          time.Duration
+	log               *logger.Logger
 }
 
 func NewGiftStakeCommand(
 	repo giftDomain.Repository,
+	txMgr pg.TxManager,
+	clients *clients.Clients,
+	cfg *config.Config,
 	log *logger.Logger,
 ) *GiftStakeCommand {
 	return &GiftStakeCommand{
-		repo: repo,
-		log:  log,
+		repo:              repo,
+		txMgr:             txMgr,
+		duelPrivateClient: clients.Duel.Private,
+		leaseTTL:          cfg.Stake.LeaseTTL,
+		log:               log,
 	}
 }
 
@@ -61,8 +76,12 @@ func (c *GiftStakeCommand) StakeGift(
 		return nil, giftDomain.ErrGiftNotOwned
 	}
 
-	// Now try to stake the gift
-	stakedGift, err := c.repo.StakeGiftForGame(ctx, params.GiftID)
+	duelID := params.GameMetadata.GetDuelId().GetValue()
+
+	// Now try to stake the gift under a lease: until the duel confirms the
+	// stake, the gift is released back to the owner once the lease expires
+	leaseExpiresAt := time.Now().Add(c.leaseTTL)
+	stakedGift, err := c.repo.StakeGiftForGame(ctx, params.GiftID, duelID, leaseExpiresAt)
 	if err != nil {
 		c.log.Error("failed to stake gift for game",
 			zap.String("giftID", params.GiftID),
@@ -72,7 +91,7 @@ func (c *GiftStakeCommand) StakeGift(
 
 	_, err = c.repo.CreateGiftEvent(ctx, giftDomain.CreateGiftEventParams{
 		GiftID:         stakedGift.ID,
-		RelatedGameID:  &params.GameMetadata.GetDuelId().Value,
+		RelatedGameID:  &duelID,
 		EventType:      giftDomain.EventTypeStake,
 		TelegramUserID: params.TelegramUserID,
 	})
@@ -86,10 +105,144 @@ func (c *GiftStakeCommand) StakeGift(
 	return stakedGift, nil
 }
 
+// ConfirmStakes makes the duel leases on the gifts permanent. It fails with
+// ErrLeaseNotHeld, confirming nothing, if any gift was already released.
+func (c *GiftStakeCommand) ConfirmStakes(
+	ctx context.Context,
+	duelID string,
+	giftIDs []string,
+) error {
+	tx, err := c.txMgr.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	var commitErr error
+	defer func() {
+		if commitErr != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	confirmed, commitErr := c.repo.WithTx(tx).ConfirmGiftLeases(
+		ctx,
+		giftDomain.LockOperationDuel,
+		duelID,
+		giftIDs,
+	)
+	if commitErr != nil {
+		c.log.Error("failed to confirm gift leases",
+			zap.String("duelID", duelID),
+			zap.Error(commitErr))
+		return commitErr
+	}
+	if len(confirmed) != len(uniqueStrings(giftIDs)) {
+		c.log.Warn("gift leases are no longer held by the duel",
+			zap.String("duelID", duelID),
+			zap.Strings("giftIDs", giftIDs),
+			zap.Strings("confirmed", confirmed))
+		commitErr = giftDomain.ErrLeaseNotHeld
+		return commitErr
+	}
+
+	commitErr = tx.Commit(ctx)
+	return commitErr
+}
+
+// ReleaseExpiredLeases settles expired stake leases and reports how many
+// were settled. A lease of a duel that exists in the duel service is
+// confirmed instead of released: the duel committed, only its confirmation
+// was lost. Other gifts go back to their owners. If the duel service can't
+// be asked, nothing is released.
+func (c *GiftStakeCommand) ReleaseExpiredLeases(
+	ctx context.Context,
+	now time.Time,
+	limit int32,
+) (int, error) {
+	expired, err := c.repo.GetExpiredLeases(ctx, now, limit)
+	if err != nil || len(expired) == 0 {
+		return 0, err
+	}
+
+	giftsByDuel := make(map[string][]string)
+	for _, g := range expired {
+		var duelID string
+		if g.Lock != nil && g.Lock.Operation == giftDomain.LockOperationDuel {
+			duelID = g.Lock.OperationID
+		}
+		giftsByDuel[duelID] = append(giftsByDuel[duelID], g.ID)
+	}
+
+	existing, err := c.existingDuels(ctx, giftsByDuel)
+	if err != nil {
+		return 0, err
+	}
+
+	var orphaned []string
+	for duelID, giftIDs := range giftsByDuel {
+		if _, ok := existing[duelID]; !ok {
+			orphaned = append(orphaned, giftIDs...)
+			continue
+		}
+		confirmed, confirmErr := c.repo.ConfirmGiftLeases(ctx, giftDomain.LockOperationDuel, duelID, giftIDs)
+		if confirmErr != nil {
+			return 0, confirmErr
+		}
+		c.log.Warn("confirmed expired stake lease of an existing duel",
+			zap.String("duelID", duelID),
+			zap.Strings("giftIDs", confirmed))
+	}
+
+	if len(orphaned) > 0 {
+		released, releaseErr := c.repo.ReleaseLeases(ctx, orphaned, now)
+		if releaseErr != nil {
+			return 0, releaseErr
+		}
+		for _, g := range released {
+			c.log.Warn("released gift with expired stake lease",
+				zap.String("giftID", g.ID),
+				zap.Int64("ownerTelegramID", g.OwnerTelegramID))
+		}
+	}
+	return len(expired), nil
+}
+
+// existingDuels returns the ids of the duels known to the duel service.
+func (c *GiftStakeCommand) existingDuels(
+	ctx context.Context,
+	giftsByDuel map[string][]string,
+) (map[string]struct{}, error) {
+	req := &duelv1.GetDuelSummariesRequest{
+		DuelIds: make([]*sharedv1.DuelId, 0, len(giftsByDuel)),
+	}
+	for duelID := range giftsByDuel {
+		if duelID != "" {
+			req.DuelIds = append(req.DuelIds, &sharedv1.DuelId{Value: duelID})
+		}
+	}
+	existing := make(map[string]struct{}, len(req.GetDuelIds()))
+	if len(req.GetDuelIds()) == 0 {
+		return existing, nil
+	}
+
+	resp, err := c.duelPrivateClient.GetDuelSummaries(ctx, req)
+	if err != nil {
+		c.log.Error("failed to look up duels of expired stake leases", zap.Error(err))
+		return nil, err
+	}
+	for _, d := range resp.GetDuels() {
+		existing[d.GetDuelId().GetValue()] = struct{}{}
+	}
+	return existing, nil
+}
+
 // ReturnGiftFromGame returns a gift from in_game status back to owned status.
+// duelID, when set, guards against returning a gift that has since been
+// staked in another duel. Gifts that are no longer in game are left as is,
+// since an expired lease may have released them already.
 func (c *GiftStakeCommand) ReturnGiftFromGame(
 	ctx context.Context,
 	giftID string,
+	duelID string,
 ) (*giftDomain.Gift, error) {
 	// First, try to get the gift to check if it exists and its current status
 	gift, err := c.repo.GetGiftByID(ctx, giftID)
@@ -100,13 +253,19 @@ func (c *GiftStakeCommand) ReturnGiftFromGame(
 		return nil, giftDomain.ErrGiftNotFound
 	}
 
-	// Check if the gift is in game status
+	// Check if the gift is still in game for this duel
 	if gift.Status != giftDomain.StatusInGame {
-		c.log.Error("gift cannot be returned from game - wrong status",
+		c.log.Info("gift is not in game, nothing to return",
 			zap.String("giftID", giftID),
-			zap.String("currentStatus", string(gift.Status)),
-			zap.String("requiredStatus", string(giftDomain.StatusInGame)))
-		return nil, giftDomain.ErrGiftNotOwned
+			zap.String("currentStatus", string(gift.Status)))
+		return gift, nil
+	}
+	if duelID != "" && gift.RelatedDuelID != duelID {
+		c.log.Info("gift is staked in another duel, nothing to return",
+			zap.String("giftID", giftID),
+			zap.String("duelID", duelID),
+			zap.String("relatedDuelID", gift.RelatedDuelID))
+		return gift, nil
 	}
 
 	// Return the gift to owned status
@@ -119,3 +278,11 @@ func (c *GiftStakeCommand) ReturnGiftFromGame(
 	}
 	return returnedGift, nil
 }
+
+func uniqueStrings(values []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		set[v] = struct{}{}
+	}
+	return set
+}
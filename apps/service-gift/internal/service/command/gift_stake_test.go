@@ -0,0 +1,219 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/adapter/pg"
+	giftDomain "github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/gift"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+	giftv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/gift/v1"
+	sharedv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/shared/v1"
+)
+
+const (
+	stakeGiftID  = "gift-1"
+	stakeOwnerID = int64(1)
+	stakeDuelID  = "duel-1"
+)
+
+// fakeStakeRepo хранит один подарок и записывает события.
+type fakeStakeRepo struct {
+	giftDomain.Repository
+
+	gift   *giftDomain.Gift
+	events []giftDomain.CreateGiftEventParams
+	// leaseExpiresAt — срок аренды из последней ставки
+	leaseExpiresAt time.Time
+	returned       bool
+}
+
+func (r *fakeStakeRepo) GetGiftByID(_ context.Context, id string) (*giftDomain.Gift, error) {
+	if r.gift == nil || r.gift.ID != id {
+		return nil, pg.ErrNotFound
+	}
+	g := *r.gift
+	return &g, nil
+}
+
+func (r *fakeStakeRepo) StakeGiftForGame(
+	_ context.Context,
+	_ string,
+	relatedDuelID string,
+	leaseExpiresAt time.Time,
+) (*giftDomain.Gift, error) {
+	r.gift.Status = giftDomain.StatusInGame
+	r.gift.RelatedDuelID = relatedDuelID
+	r.leaseExpiresAt = leaseExpiresAt
+	g := *r.gift
+	return &g, nil
+}
+
+func (r *fakeStakeRepo) ReturnGiftFromGame(_ context.Context, _ string) (*giftDomain.Gift, error) {
+	r.returned = true
+	r.gift.Status = giftDomain.StatusOwned
+	r.gift.RelatedDuelID = ""
+	g := *r.gift
+	return &g, nil
+}
+
+func (r *fakeStakeRepo) CreateGiftEvent(
+	_ context.Context,
+	params giftDomain.CreateGiftEventParams,
+) (*giftDomain.Event, error) {
+	r.events = append(r.events, params)
+	return &giftDomain.Event{GiftID: params.GiftID, EventType: params.EventType}, nil
+}
+
+func newStakeCommand(t *testing.T, g *giftDomain.Gift) (*GiftStakeCommand, *fakeStakeRepo) {
+	t.Helper()
+	log, err := logger.NewLogger(logger.Config{Service: "test", Level: "fatal"})
+	if err != nil {
+		t.Fatalf("logger: %v", err)
+	}
+	repo := &fakeStakeRepo{gift: g}
+	return &GiftStakeCommand{repo: repo, leaseTTL: time.Minute, log: log}, repo
+}
+
+func ownedGift() *giftDomain.Gift {
+	return &giftDomain.Gift{ID: stakeGiftID, OwnerTelegramID: stakeOwnerID, Status: giftDomain.StatusOwned}
+}
+
+func duelMetadata(duelID string) *giftv1.StakeGiftRequest_DuelMetadata {
+	return &giftv1.StakeGiftRequest_DuelMetadata{DuelId: &sharedv1.DuelId{Value: duelID}}
+}
+
+func TestStakeGift(t *testing.T) {
+	tests := []struct {
+		name           string
+		gift           *giftDomain.Gift
+		telegramUserID int64
+		wantErr        error
+	}{
+		{
+			name:           "owned gift",
+			gift:           ownedGift(),
+			telegramUserID: stakeOwnerID,
+		},
+		{
+			name:           "someone else's gift",
+			gift:           ownedGift(),
+			telegramUserID: stakeOwnerID + 1,
+			wantErr:        giftDomain.ErrGiftNotOwned,
+		},
+		{
+			name: "gift already in another duel",
+			gift: &giftDomain.Gift{
+				ID:              stakeGiftID,
+				OwnerTelegramID: stakeOwnerID,
+				Status:          giftDomain.StatusInGame,
+				RelatedDuelID:   "duel-0",
+			},
+			telegramUserID: stakeOwnerID,
+			wantErr:        giftDomain.ErrGiftNotOwned,
+		},
+		{
+			name:           "missing gift",
+			telegramUserID: stakeOwnerID,
+			wantErr:        giftDomain.ErrGiftNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, repo := newStakeCommand(t, tt.gift)
+
+			before := time.Now()
+			staked, err := c.StakeGift(context.Background(), StakeGiftParams{
+				GiftID:         stakeGiftID,
+				TelegramUserID: tt.telegramUserID,
+				GameMetadata:   duelMetadata(stakeDuelID),
+			})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				if len(repo.events) != 0 || (tt.gift != nil && repo.gift.RelatedDuelID == stakeDuelID) {
+					t.Errorf("rejected stake changed the gift: %+v, events %v", repo.gift, repo.events)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// дуэль сохраняется вместе со ставкой, а не ищется потом в duel-сервисе
+			if staked.Status != giftDomain.StatusInGame || staked.RelatedDuelID != stakeDuelID {
+				t.Errorf("staked gift %+v, want in game for %s", staked, stakeDuelID)
+			}
+			if repo.leaseExpiresAt.Before(before.Add(time.Minute)) {
+				t.Errorf("lease expires at %s, want at least a minute from now", repo.leaseExpiresAt)
+			}
+			if len(repo.events) != 1 || repo.events[0].EventType != giftDomain.EventTypeStake ||
+				repo.events[0].RelatedGameID == nil || *repo.events[0].RelatedGameID != stakeDuelID {
+				t.Errorf("events %+v, want one stake event for %s", repo.events, stakeDuelID)
+			}
+		})
+	}
+}
+
+// Подарок возвращается из игры, только если его держит та дуэль, которая
+// просит возврат.
+func TestReturnGiftFromGameChecksRelatedDuel(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       giftDomain.Status
+		relatedDuel  string
+		duelID       string
+		wantReturned bool
+	}{
+		{
+			name:         "same duel",
+			status:       giftDomain.StatusInGame,
+			relatedDuel:  stakeDuelID,
+			duelID:       stakeDuelID,
+			wantReturned: true,
+		},
+		{
+			name:         "no duel given",
+			status:       giftDomain.StatusInGame,
+			relatedDuel:  stakeDuelID,
+			wantReturned: true,
+		},
+		{
+			name:        "staked in another duel",
+			status:      giftDomain.StatusInGame,
+			relatedDuel: "duel-2",
+			duelID:      stakeDuelID,
+		},
+		{
+			name:   "already released",
+			status: giftDomain.StatusOwned,
+			duelID: stakeDuelID,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, repo := newStakeCommand(t, &giftDomain.Gift{
+				ID:              stakeGiftID,
+				OwnerTelegramID: stakeOwnerID,
+				Status:          tt.status,
+				RelatedDuelID:   tt.relatedDuel,
+			})
+
+			g, err := c.ReturnGiftFromGame(context.Background(), stakeGiftID, tt.duelID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if repo.returned != tt.wantReturned {
+				t.Errorf("returned = %v, want %v", repo.returned, tt.wantReturned)
+			}
+			if !tt.wantReturned && g.RelatedDuelID != tt.relatedDuel {
+				t.Errorf("related duel %q, want %q", g.RelatedDuelID, tt.relatedDuel)
+			}
+		})
+	}
+}
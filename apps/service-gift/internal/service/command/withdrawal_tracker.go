@@ -0,0 +1,179 @@
+package command
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/adapter/pg"
+	withdrawalDomain "github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/withdrawal"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+	"go.uber.org/zap"
+)
+
+// WithdrawalTracker ведёт агрегат вывода по шагам саги и событиям бота.
+// Подарки, отправленные на вывод до появления withdrawals, не отслеживаются —
+// для них методы ничего не делают.
+type WithdrawalTracker struct {
+	repo withdrawalDomain.Repository
+	log  *logger.Logger
+}
+
+func NewWithdrawalTracker(
+	repo withdrawalDomain.Repository,
+	log *logger.Logger,
+) *WithdrawalTracker {
+	return &WithdrawalTracker{
+		repo: repo,
+		log:  log,
+	}
+}
+
+func (t *WithdrawalTracker) WithTx(tx pgx.Tx) *WithdrawalTracker {
+	return &WithdrawalTracker{
+		repo: t.repo.WithTx(tx),
+		log:  t.log,
+	}
+}
+
+func (t *WithdrawalTracker) Create(
+	ctx context.Context,
+	w *withdrawalDomain.Withdrawal,
+) (*withdrawalDomain.Withdrawal, error) {
+	created, err := t.repo.CreateWithdrawal(ctx, w)
+	if err != nil {
+		t.log.Error("failed to create withdrawal", zap.Error(err))
+		return nil, err
+	}
+	return created, nil
+}
+
+// SendToBotByIntent отмечает вывод оплаченного Stars-инвойса переданным боту.
+func (t *WithdrawalTracker) SendToBotByIntent(ctx context.Context, intentID string) error {
+	return t.updateByIntent(ctx, intentID, (*withdrawalDomain.Withdrawal).SendToBot)
+}
+
+// ExpireByIntent отменяет вывод, Stars-инвойс которого истёк.
+func (t *WithdrawalTracker) ExpireByIntent(ctx context.Context, intentID string) error {
+	return t.updateByIntent(ctx, intentID, (*withdrawalDomain.Withdrawal).ExpirePayment)
+}
+
+// RequireRefundByIntent отмечает, что истёкший Stars-инвойс оплачен, но
+// подарки уже недоступны и оплату нужно вернуть.
+func (t *WithdrawalTracker) RequireRefundByIntent(ctx context.Context, intentID string) error {
+	return t.updateByIntent(ctx, intentID, (*withdrawalDomain.Withdrawal).RequireRefund)
+}
+
+// CompleteGift отмечает доставку подарка ботом.
+func (t *WithdrawalTracker) CompleteGift(ctx context.Context, giftID string) error {
+	return t.settleGift(ctx, giftID, func(w *withdrawalDomain.Withdrawal) (*withdrawalDomain.Gift, error) {
+		return w.CompleteGift(giftID)
+	})
+}
+
+// FailGift отмечает, что бот не смог передать подарок.
+func (t *WithdrawalTracker) FailGift(ctx context.Context, giftID string, reason string) error {
+	return t.settleGift(ctx, giftID, func(w *withdrawalDomain.Withdrawal) (*withdrawalDomain.Gift, error) {
+		return w.FailGift(giftID, reason)
+	})
+}
+
+// RefundGift отмечает отмену вывода подарка с возвратом комиссии.
+func (t *WithdrawalTracker) RefundGift(ctx context.Context, giftID string, reason string) error {
+	return t.settleGift(ctx, giftID, func(w *withdrawalDomain.Withdrawal) (*withdrawalDomain.Gift, error) {
+		return w.RefundGift(giftID, reason)
+	})
+}
+
+// GiftCommissionCurrency возвращает валюту комиссии активного вывода подарка.
+// tracked = false, если подарок выведен до появления withdrawals.
+func (t *WithdrawalTracker) GiftCommissionCurrency(
+	ctx context.Context,
+	giftID string,
+) (currency withdrawalDomain.CommissionCurrency, tracked bool, err error) {
+	w, err := t.repo.GetActiveWithdrawalByGiftForUpdate(ctx, giftID)
+	if err != nil {
+		if pg.IsNotFound(err) {
+			return "", false, nil
+		}
+		t.log.Error("failed to get active withdrawal for gift",
+			zap.String("giftID", giftID),
+			zap.Error(err),
+		)
+		return "", false, err
+	}
+	return w.CommissionCurrency, true, nil
+}
+
+func (t *WithdrawalTracker) updateByIntent(
+	ctx context.Context,
+	intentID string,
+	transition func(*withdrawalDomain.Withdrawal) error,
+) error {
+	log := t.log.With(zap.String("intentID", intentID))
+
+	w, err := t.repo.GetWithdrawalByIntentIDForUpdate(ctx, intentID)
+	if err != nil {
+		if pg.IsNotFound(err) {
+			return nil
+		}
+		log.Error("failed to get withdrawal by intent", zap.Error(err))
+		return err
+	}
+
+	if err = transition(w); err != nil {
+		log.Error("invalid withdrawal transition",
+			zap.String("withdrawalID", w.ID),
+			zap.String("status", string(w.Status)),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	for _, g := range w.Gifts {
+		if err = t.repo.UpdateWithdrawalGiftStatus(ctx, w.ID, g); err != nil {
+			log.Error("failed to update withdrawal gift", zap.Error(err))
+			return err
+		}
+	}
+	if _, err = t.repo.UpdateWithdrawalStatus(ctx, w); err != nil {
+		log.Error("failed to update withdrawal status", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (t *WithdrawalTracker) settleGift(
+	ctx context.Context,
+	giftID string,
+	settle func(*withdrawalDomain.Withdrawal) (*withdrawalDomain.Gift, error),
+) error {
+	log := t.log.With(zap.String("giftID", giftID))
+
+	w, err := t.repo.GetActiveWithdrawalByGiftForUpdate(ctx, giftID)
+	if err != nil {
+		if pg.IsNotFound(err) {
+			return nil
+		}
+		log.Error("failed to get active withdrawal for gift", zap.Error(err))
+		return err
+	}
+
+	g, err := settle(w)
+	if err != nil {
+		log.Error("invalid withdrawal gift transition",
+			zap.String("withdrawalID", w.ID),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	if err = t.repo.UpdateWithdrawalGiftStatus(ctx, w.ID, g); err != nil {
+		log.Error("failed to update withdrawal gift", zap.Error(err))
+		return err
+	}
+	if _, err = t.repo.UpdateWithdrawalStatus(ctx, w); err != nil {
+		log.Error("failed to update withdrawal status", zap.Error(err))
+		return err
+	}
+	return nil
+}
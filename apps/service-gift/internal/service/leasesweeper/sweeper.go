@@ -0,0 +1,83 @@
+package leasesweeper
+
+import (
+	"context"
+	"time"
+
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/config"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/service/command"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+	"go.uber.org/zap"
+)
+
+// batchSize — сколько просроченных аренд разбирается за один запрос.
+const batchSize = 100
+
+// Sweeper периодически разбирает аренды, которые дуэль не подтвердила вовремя:
+// подарки существующих дуэлей остаются в игре, остальные возвращаются владельцам.
+type Sweeper struct {
+	giftStakeCommand *command.GiftStakeCommand
+	interval         time.Duration
+	cancel           context.CancelFunc
+	logger           *logger.Logger
+}
+
+func NewSweeper(
+	giftStakeCommand *command.GiftStakeCommand,
+	cfg *config.Config,
+	logger *logger.Logger,
+) *Sweeper {
+	return &Sweeper{
+		giftStakeCommand: giftStakeCommand,
+		interval:         cfg.Stake.SweepInterval,
+		logger:           logger,
+	}
+}
+
+func (s *Sweeper) Start() {
+	ctx := context.Background()
+	ctx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+	go func() {
+		s.run(ctx)
+	}()
+}
+
+func (s *Sweeper) Stop(_ context.Context) error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	return nil
+}
+
+func (s *Sweeper) run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("lease sweeper stopping")
+			return
+		case <-ticker.C:
+			s.sweep(ctx)
+		}
+	}
+}
+
+// sweep разбирает просроченные аренды пачками, пока они не закончатся.
+func (s *Sweeper) sweep(ctx context.Context) {
+	for ctx.Err() == nil {
+		settled, err := s.giftStakeCommand.ReleaseExpiredLeases(ctx, time.Now(), batchSize)
+		if err != nil {
+			s.logger.Error("failed to settle expired gift leases", zap.Error(err))
+			return
+		}
+		if settled > 0 {
+			s.logger.Info("settled expired gift leases", zap.Int("count", settled))
+		}
+		if settled < batchSize {
+			return
+		}
+	}
+}
@@ -16,11 +16,25 @@ var Module = fx.Module("services",
 		command.NewGiftWithdrawCommand,
 		command.NewGiftCompleteWithdrawalCommand,
 		command.NewGiftReturnFromGameCommand,
+		command.NewGiftListingCommand,
+		command.NewWithdrawalTracker,
+		command.NewCustodyReconciler,
+		command.NewFavoriteCommand,
 
 		query.NewGiftReadService,
 		query.NewUserGiftsService,
+		query.NewListingReadService,
+		query.NewGiftHistoryService,
+		query.NewWithdrawalReadService,
+		query.NewCatalogReadService,
+		query.NewPortfolioService,
+		query.NewReconciliationReadService,
+		query.NewFavoriteReadService,
+		query.NewStakeSuggestionService,
 
 		saga.NewWithdrawalSaga,
+		saga.NewMarketplaceSaga,
+		saga.NewSellBackSaga,
 
 		portals.NewPortalsPriceService,
 	),
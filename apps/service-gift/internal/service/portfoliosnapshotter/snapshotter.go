@@ -0,0 +1,73 @@
+package portfoliosnapshotter
+
+import (
+	"context"
+	"time"
+
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/config"
+	portfolioDomain "github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/portfolio"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+	"go.uber.org/zap"
+)
+
+// Snapshotter периодически перезаписывает снапшот стоимости портфелей за текущий
+// день. Последняя запись за сутки и становится итоговой точкой истории.
+type Snapshotter struct {
+	repo     portfolioDomain.Repository
+	interval time.Duration
+	cancel   context.CancelFunc
+	logger   *logger.Logger
+}
+
+func NewSnapshotter(
+	repo portfolioDomain.Repository,
+	cfg *config.Config,
+	logger *logger.Logger,
+) *Snapshotter {
+	return &Snapshotter{
+		repo:     repo,
+		interval: cfg.Portfolio.SnapshotInterval,
+		logger:   logger,
+	}
+}
+
+func (s *Snapshotter) Start() {
+	ctx := context.Background()
+	ctx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+	go func() {
+		s.run(ctx)
+	}()
+}
+
+func (s *Snapshotter) Stop(_ context.Context) error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	return nil
+}
+
+func (s *Snapshotter) run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	s.snapshot(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("portfolio snapshotter stopping")
+			return
+		case <-ticker.C:
+			s.snapshot(ctx)
+		}
+	}
+}
+
+func (s *Snapshotter) snapshot(ctx context.Context) {
+	n, err := s.repo.RecordSnapshots(ctx, time.Now())
+	if err != nil {
+		s.logger.Error("failed to record portfolio snapshots", zap.Error(err))
+		return
+	}
+	s.logger.Info("recorded portfolio snapshots", zap.Int64("count", n))
+}
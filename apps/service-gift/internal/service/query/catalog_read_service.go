@@ -0,0 +1,135 @@
+package query
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/config"
+	catalogDomain "github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/catalog"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+	"go.uber.org/zap"
+)
+
+// CatalogSnapshot — срез каталога с версией. ETag меняется только вместе
+// с содержимым, поэтому клиент может кешировать ответ до смены ETag.
+type CatalogSnapshot[T any] struct {
+	Items []T
+	ETag  string
+}
+
+type catalogCacheEntry struct {
+	value     any
+	expiresAt time.Time
+}
+
+// CatalogReadService отдаёт справочники атрибутов с floor-ценами.
+// Ответы держатся в памяти CacheTTL, чтобы не пересчитывать агрегаты
+// по лотам на каждый запрос.
+type CatalogReadService struct {
+	repo catalogDomain.Repository
+	ttl  time.Duration
+	log  *logger.Logger
+
+	mu    sync.Mutex
+	cache map[string]catalogCacheEntry
+}
+
+func NewCatalogReadService(
+	repo catalogDomain.Repository,
+	cfg *config.Config,
+	log *logger.Logger,
+) *CatalogReadService {
+	return &CatalogReadService{
+		repo:  repo,
+		ttl:   cfg.Catalog.CacheTTL,
+		log:   log,
+		cache: make(map[string]catalogCacheEntry),
+	}
+}
+
+func (s *CatalogReadService) ListCollections(
+	ctx context.Context,
+) (*CatalogSnapshot[*catalogDomain.CollectionEntry], error) {
+	return cachedSnapshot(ctx, s, "collections", s.repo.ListCollections)
+}
+
+func (s *CatalogReadService) ListModels(
+	ctx context.Context,
+	collection *string,
+) (*CatalogSnapshot[*catalogDomain.ModelEntry], error) {
+	key := "models"
+	if collection != nil {
+		key += ":" + *collection
+	}
+	return cachedSnapshot(ctx, s, key, func(ctx context.Context) ([]*catalogDomain.ModelEntry, error) {
+		return s.repo.ListModels(ctx, collection)
+	})
+}
+
+func (s *CatalogReadService) ListBackdrops(
+	ctx context.Context,
+) (*CatalogSnapshot[*catalogDomain.BackdropEntry], error) {
+	return cachedSnapshot(ctx, s, "backdrops", s.repo.ListBackdrops)
+}
+
+func (s *CatalogReadService) ListSymbols(
+	ctx context.Context,
+) (*CatalogSnapshot[*catalogDomain.SymbolEntry], error) {
+	return cachedSnapshot(ctx, s, "symbols", s.repo.ListSymbols)
+}
+
+func cachedSnapshot[T any](
+	ctx context.Context,
+	s *CatalogReadService,
+	key string,
+	load func(ctx context.Context) ([]T, error),
+) (*CatalogSnapshot[T], error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	entry, ok := s.cache[key]
+	s.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		if snapshot, isSnapshot := entry.value.(*CatalogSnapshot[T]); isSnapshot {
+			return snapshot, nil
+		}
+	}
+
+	items, err := load(ctx)
+	if err != nil {
+		s.log.Error("Failed to load catalog", zap.String("key", key), zap.Error(err))
+		return nil, err
+	}
+
+	etag, err := catalogETag(items)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &CatalogSnapshot[T]{Items: items, ETag: etag}
+
+	// пустые ответы не кешируем, иначе запросы с произвольными
+	// именами коллекций раздували бы кеш
+	if len(items) == 0 {
+		return snapshot, nil
+	}
+
+	s.mu.Lock()
+	s.cache[key] = catalogCacheEntry{value: snapshot, expiresAt: now.Add(s.ttl)}
+	s.mu.Unlock()
+
+	return snapshot, nil
+}
+
+// catalogETag — хеш содержимого среза каталога.
+func catalogETag(items any) (string, error) {
+	payload, err := json.Marshal(items)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:16]), nil
+}
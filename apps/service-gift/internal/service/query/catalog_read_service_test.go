@@ -0,0 +1,210 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/config"
+	catalogDomain "github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/catalog"
+	giftDomain "github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/gift"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+	"github.com/peterparker2005/giftduels/packages/tonamount-go"
+)
+
+var errCatalogUnavailable = errors.New("catalog unavailable")
+
+// fakeCatalogRepo отдаёт текущее содержимое каталога и считает загрузки.
+type fakeCatalogRepo struct {
+	catalogDomain.Repository
+
+	collections []*catalogDomain.CollectionEntry
+	models      map[string][]*catalogDomain.ModelEntry
+	err         error
+	loads       int
+}
+
+func (r *fakeCatalogRepo) ListCollections(context.Context) ([]*catalogDomain.CollectionEntry, error) {
+	r.loads++
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.collections, nil
+}
+
+func (r *fakeCatalogRepo) ListModels(_ context.Context, collection *string) ([]*catalogDomain.ModelEntry, error) {
+	r.loads++
+	if r.err != nil {
+		return nil, r.err
+	}
+	if collection == nil {
+		var all []*catalogDomain.ModelEntry
+		for _, models := range r.models {
+			all = append(all, models...)
+		}
+		return all, nil
+	}
+	return r.models[*collection], nil
+}
+
+func mustTon(t *testing.T, s string) *tonamount.TonAmount {
+	t.Helper()
+	a, err := tonamount.NewTonAmountFromString(s)
+	if err != nil {
+		t.Fatalf("cannot parse TonAmount from %q: %v", s, err)
+	}
+	return a
+}
+
+func newCatalogService(t *testing.T, ttl time.Duration) (*CatalogReadService, *fakeCatalogRepo) {
+	t.Helper()
+	log, err := logger.NewLogger(logger.Config{Service: "test", Level: "fatal"})
+	if err != nil {
+		t.Fatalf("logger: %v", err)
+	}
+	repo := &fakeCatalogRepo{
+		collections: []*catalogDomain.CollectionEntry{
+			{Collection: giftDomain.Collection{ID: 1, Name: "Plush Pepe"}, FloorPrice: mustTon(t, "100")},
+			{Collection: giftDomain.Collection{ID: 2, Name: "Lol Pop"}},
+		},
+		models: map[string][]*catalogDomain.ModelEntry{
+			"Plush Pepe": {{Model: giftDomain.Model{ID: 10, Name: "Gold"}, CollectionName: "Plush Pepe"}},
+			"Lol Pop":    {{Model: giftDomain.Model{ID: 20, Name: "Candy"}, CollectionName: "Lol Pop"}},
+		},
+	}
+	cfg := &config.Config{}
+	cfg.Catalog.CacheTTL = ttl
+	return NewCatalogReadService(repo, cfg, log), repo
+}
+
+func TestCatalogETag(t *testing.T) {
+	tests := []struct {
+		name string
+		// change меняет каталог между двумя загрузками
+		change   func(t *testing.T, repo *fakeCatalogRepo)
+		wantSame bool
+	}{
+		{
+			name:     "same content keeps the etag",
+			change:   func(*testing.T, *fakeCatalogRepo) {},
+			wantSame: true,
+		},
+		{
+			name: "floor price change",
+			change: func(t *testing.T, repo *fakeCatalogRepo) {
+				repo.collections[0].FloorPrice = mustTon(t, "99.5")
+			},
+		},
+		{
+			name: "floor price appears",
+			change: func(t *testing.T, repo *fakeCatalogRepo) {
+				repo.collections[1].FloorPrice = mustTon(t, "3")
+			},
+		},
+		{
+			name: "new collection",
+			change: func(_ *testing.T, repo *fakeCatalogRepo) {
+				repo.collections = append(repo.collections, &catalogDomain.CollectionEntry{
+					Collection: giftDomain.Collection{ID: 3, Name: "Jelly Bunny"},
+				})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// нулевой TTL: каждый запрос читает каталог заново
+			s, repo := newCatalogService(t, 0)
+			ctx := context.Background()
+
+			first, err := s.ListCollections(ctx)
+			if err != nil {
+				t.Fatalf("first load: %v", err)
+			}
+			tt.change(t, repo)
+			second, err := s.ListCollections(ctx)
+			if err != nil {
+				t.Fatalf("second load: %v", err)
+			}
+
+			if repo.loads != 2 {
+				t.Errorf("loads %d, want 2", repo.loads)
+			}
+			if first.ETag == "" {
+				t.Error("empty etag")
+			}
+			if (first.ETag == second.ETag) != tt.wantSame {
+				t.Errorf("etags %s and %s, want same = %v", first.ETag, second.ETag, tt.wantSame)
+			}
+		})
+	}
+}
+
+func TestCatalogCache(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("served from cache within ttl", func(t *testing.T) {
+		s, repo := newCatalogService(t, time.Hour)
+		first, err := s.ListCollections(ctx)
+		if err != nil {
+			t.Fatalf("first load: %v", err)
+		}
+		repo.collections[0].FloorPrice = mustTon(t, "1")
+		second, err := s.ListCollections(ctx)
+		if err != nil {
+			t.Fatalf("second load: %v", err)
+		}
+		if repo.loads != 1 || first.ETag != second.ETag {
+			t.Errorf("loads %d, etags %s / %s; want one load and the cached etag", repo.loads, first.ETag, second.ETag)
+		}
+	})
+
+	t.Run("models are cached per collection", func(t *testing.T) {
+		s, repo := newCatalogService(t, time.Hour)
+		pepe, pop := "Plush Pepe", "Lol Pop"
+		for _, collection := range []*string{&pepe, &pop, nil, &pepe} {
+			if _, err := s.ListModels(ctx, collection); err != nil {
+				t.Fatalf("list models: %v", err)
+			}
+		}
+		if repo.loads != 3 {
+			t.Errorf("loads %d, want 3", repo.loads)
+		}
+		pepeModels, _ := s.ListModels(ctx, &pepe)
+		popModels, _ := s.ListModels(ctx, &pop)
+		if pepeModels.ETag == popModels.ETag || pepeModels.Items[0].Model.Name != "Gold" {
+			t.Errorf("collections share a cache entry: %+v / %+v", pepeModels, popModels)
+		}
+	})
+
+	t.Run("empty results are not cached", func(t *testing.T) {
+		s, repo := newCatalogService(t, time.Hour)
+		unknown := "No Such Collection"
+		for range 2 {
+			snapshot, err := s.ListModels(ctx, &unknown)
+			if err != nil {
+				t.Fatalf("list models: %v", err)
+			}
+			if len(snapshot.Items) != 0 || snapshot.ETag == "" {
+				t.Errorf("snapshot %+v, want empty with etag", snapshot)
+			}
+		}
+		if repo.loads != 2 {
+			t.Errorf("loads %d, want 2", repo.loads)
+		}
+	})
+
+	t.Run("errors are not cached", func(t *testing.T) {
+		s, repo := newCatalogService(t, time.Hour)
+		repo.err = errCatalogUnavailable
+		if _, err := s.ListCollections(ctx); !errors.Is(err, errCatalogUnavailable) {
+			t.Fatalf("expected errCatalogUnavailable, got %v", err)
+		}
+		repo.err = nil
+		snapshot, err := s.ListCollections(ctx)
+		if err != nil || len(snapshot.Items) != 2 {
+			t.Fatalf("after recovery got %+v, %v", snapshot, err)
+		}
+	})
+}
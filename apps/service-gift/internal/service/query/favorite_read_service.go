@@ -0,0 +1,108 @@
+package query
+
+import (
+	"context"
+
+	favoriteDomain "github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/favorite"
+	giftDomain "github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/gift"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+	"go.uber.org/zap"
+)
+
+type FavoriteReadService struct {
+	favoriteRepo    favoriteDomain.Repository
+	giftReadService *GiftReadService
+	log             *logger.Logger
+}
+
+func NewFavoriteReadService(
+	favoriteRepo favoriteDomain.Repository,
+	giftReadService *GiftReadService,
+	log *logger.Logger,
+) *FavoriteReadService {
+	return &FavoriteReadService{
+		favoriteRepo:    favoriteRepo,
+		giftReadService: giftReadService,
+		log:             log,
+	}
+}
+
+// GetFavoriteGifts возвращает избранные подарки из инвентаря пользователя,
+// недавно добавленные первыми.
+func (s *FavoriteReadService) GetFavoriteGifts(
+	ctx context.Context,
+	telegramUserID int64,
+) ([]*giftDomain.Gift, error) {
+	giftIDs, err := s.favoriteRepo.GetFavoriteGiftIDs(ctx, telegramUserID)
+	if err != nil {
+		s.log.Error("Failed to get favorite gift IDs", zap.Error(err))
+		return nil, err
+	}
+
+	giftsByID, err := s.loadGifts(ctx, giftIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	gifts := make([]*giftDomain.Gift, 0, len(giftIDs))
+	for _, id := range giftIDs {
+		if g, ok := giftsByID[id]; ok {
+			gifts = append(gifts, g)
+		}
+	}
+	return gifts, nil
+}
+
+type PresetWithGifts struct {
+	Preset *favoriteDomain.Preset
+	// GiftsByID — подарки пресета, которые всё ещё в инвентаре пользователя
+	GiftsByID map[string]*giftDomain.Gift
+}
+
+func (s *FavoriteReadService) GetPresets(
+	ctx context.Context,
+	telegramUserID int64,
+) ([]*PresetWithGifts, error) {
+	presets, err := s.favoriteRepo.GetUserPresets(ctx, telegramUserID)
+	if err != nil {
+		s.log.Error("Failed to get stake presets", zap.Error(err))
+		return nil, err
+	}
+
+	var giftIDs []string
+	for _, p := range presets {
+		giftIDs = append(giftIDs, p.GiftIDs...)
+	}
+	giftsByID, err := s.loadGifts(ctx, giftIDs)
+	if err != nil {
+		return nil, err
+	}
+	for id, g := range giftsByID {
+		if !g.IsInInventoryOf(telegramUserID) {
+			delete(giftsByID, id)
+		}
+	}
+
+	out := make([]*PresetWithGifts, len(presets))
+	for i, p := range presets {
+		out[i] = &PresetWithGifts{Preset: p, GiftsByID: giftsByID}
+	}
+	return out, nil
+}
+
+func (s *FavoriteReadService) loadGifts(
+	ctx context.Context,
+	giftIDs []string,
+) (map[string]*giftDomain.Gift, error) {
+	gifts, err := s.giftReadService.GetGiftsByIDs(ctx, giftIDs)
+	if err != nil {
+		s.log.Error("Failed to get gifts", zap.Error(err))
+		return nil, err
+	}
+
+	giftsByID := make(map[string]*giftDomain.Gift, len(gifts))
+	for _, g := range gifts {
+		giftsByID[g.ID] = g
+	}
+	return giftsByID, nil
+}
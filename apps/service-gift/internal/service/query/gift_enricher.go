@@ -0,0 +1,238 @@
+package query
+
+import (
+	"context"
+
+	giftDomain "github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/gift"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+	duelv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/duel/v1"
+	sharedv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/shared/v1"
+	"go.uber.org/zap"
+)
+
+// giftEnricher дозагружает атрибуты и связанные дуэли для подарков.
+// Общий для сервисов чтения, чтобы не дублировать загрузку.
+type giftEnricher struct {
+	repo              giftDomain.Repository
+	log               *logger.Logger
+	duelPrivateClient duelv1.DuelPrivateServiceClient
+}
+
+func newGiftEnricher(
+	repo giftDomain.Repository,
+	log *logger.Logger,
+	duelPrivateClient duelv1.DuelPrivateServiceClient,
+) *giftEnricher {
+	return &giftEnricher{
+		repo:              repo,
+		log:               log,
+		duelPrivateClient: duelPrivateClient,
+	}
+}
+
+// resolveRelatedDuels проставляет дуэль подаркам в игре. Обычно id дуэли
+// уже сохранён при ставке; для подарков, поставленных до появления
+// related_duel_id, делается один пакетный запрос в duel-сервис.
+func (e *giftEnricher) resolveRelatedDuels(
+	ctx context.Context,
+	gifts []*giftDomain.Gift,
+) error {
+	missing := make([]*sharedv1.GiftId, 0)
+	for _, g := range gifts {
+		if g.Status == giftDomain.StatusInGame && g.RelatedDuelID == "" {
+			missing = append(missing, &sharedv1.GiftId{Value: g.ID})
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	resp, err := e.duelPrivateClient.FindDuelsByGiftIDs(ctx, &duelv1.FindDuelsByGiftIDsRequest{
+		GiftIds: missing,
+	})
+	if err != nil {
+		e.log.Error("Failed to find duels by gift IDs", zap.Error(err))
+		return err
+	}
+
+	duelsByGiftID := make(map[string]string, len(resp.GetGiftDuels()))
+	for _, gd := range resp.GetGiftDuels() {
+		duelsByGiftID[gd.GetGiftId().GetValue()] = gd.GetDuelId().GetValue()
+	}
+
+	for _, g := range gifts {
+		if g.Status != giftDomain.StatusInGame || g.RelatedDuelID != "" {
+			continue
+		}
+		duelID, ok := duelsByGiftID[g.ID]
+		if !ok {
+			e.log.Error("Duel not found for gift", zap.String("giftID", g.ID))
+			continue
+		}
+		g.SetRelatedDuelID(duelID)
+	}
+	return nil
+}
+
+// populateGiftAttributes populates the Model, Backdrop, and Symbol attributes for a slice of gifts.
+func (e *giftEnricher) populateGiftAttributes(
+	ctx context.Context,
+	gifts []*giftDomain.Gift,
+) error {
+	// Collect all unique IDs
+	modelIDs, backdropIDs, symbolIDs := e.collectAttributeIDs(gifts)
+
+	// Fetch all models, backdrops, and symbols in parallel
+	models, backdrops, symbols, err := e.fetchAttributesInParallel(
+		ctx,
+		modelIDs,
+		backdropIDs,
+		symbolIDs,
+	)
+	if err != nil {
+		return err
+	}
+
+	// Populate the gifts with the fetched data
+	e.populateGiftsWithAttributes(gifts, models, backdrops, symbols)
+
+	return nil
+}
+
+func (e *giftEnricher) collectAttributeIDs(
+	gifts []*giftDomain.Gift,
+) (map[int32]bool, map[int32]bool, map[int32]bool) {
+	modelIDs := make(map[int32]bool)
+	backdropIDs := make(map[int32]bool)
+	symbolIDs := make(map[int32]bool)
+
+	for _, gift := range gifts {
+		modelIDs[gift.Model.ID] = true
+		backdropIDs[gift.Backdrop.ID] = true
+		symbolIDs[gift.Symbol.ID] = true
+	}
+
+	return modelIDs, backdropIDs, symbolIDs
+}
+
+func (e *giftEnricher) fetchAttributesInParallel(
+	ctx context.Context,
+	modelIDs, backdropIDs, symbolIDs map[int32]bool,
+) (map[int32]*giftDomain.Model, map[int32]*giftDomain.Backdrop, map[int32]*giftDomain.Symbol, error) {
+	//nolint:mnd // 3 is not a magic number
+	errorChan := make(chan error, 3)
+	modelChan := make(chan map[int32]*giftDomain.Model, 1)
+	backdropChan := make(chan map[int32]*giftDomain.Backdrop, 1)
+	symbolChan := make(chan map[int32]*giftDomain.Symbol, 1)
+
+	// Fetch models
+	go e.fetchModels(ctx, modelIDs, modelChan, errorChan)
+
+	// Fetch backdrops
+	go e.fetchBackdrops(ctx, backdropIDs, backdropChan, errorChan)
+
+	// Fetch symbols
+	go e.fetchSymbols(ctx, symbolIDs, symbolChan, errorChan)
+
+	// Wait for all goroutines to complete
+	models := <-modelChan
+	backdrops := <-backdropChan
+	symbols := <-symbolChan
+
+	// Check for errors
+	select {
+	case err := <-errorChan:
+		return nil, nil, nil, err
+	default:
+	}
+
+	return models, backdrops, symbols, nil
+}
+
+func (e *giftEnricher) fetchModels(
+	ctx context.Context,
+	modelIDs map[int32]bool,
+	modelChan chan<- map[int32]*giftDomain.Model,
+	errorChan chan<- error,
+) {
+	models := make(map[int32]*giftDomain.Model)
+	for modelID := range modelIDs {
+		model, err := e.repo.GetGiftModel(ctx, modelID)
+		if err != nil {
+			errorChan <- err
+			return
+		}
+		models[modelID] = model
+	}
+	modelChan <- models
+}
+
+func (e *giftEnricher) fetchBackdrops(
+	ctx context.Context,
+	backdropIDs map[int32]bool,
+	backdropChan chan<- map[int32]*giftDomain.Backdrop,
+	errorChan chan<- error,
+) {
+	backdrops := make(map[int32]*giftDomain.Backdrop)
+	for backdropID := range backdropIDs {
+		backdrop, err := e.repo.GetGiftBackdrop(ctx, backdropID)
+		if err != nil {
+			errorChan <- err
+			return
+		}
+		backdrops[backdropID] = backdrop
+	}
+	backdropChan <- backdrops
+}
+
+func (e *giftEnricher) fetchSymbols(
+	ctx context.Context,
+	symbolIDs map[int32]bool,
+	symbolChan chan<- map[int32]*giftDomain.Symbol,
+	errorChan chan<- error,
+) {
+	symbols := make(map[int32]*giftDomain.Symbol)
+	for symbolID := range symbolIDs {
+		symbol, err := e.repo.GetGiftSymbol(ctx, symbolID)
+		if err != nil {
+			errorChan <- err
+			return
+		}
+		symbols[symbolID] = symbol
+	}
+	symbolChan <- symbols
+}
+
+func (e *giftEnricher) populateGiftsWithAttributes(
+	gifts []*giftDomain.Gift,
+	models map[int32]*giftDomain.Model,
+	backdrops map[int32]*giftDomain.Backdrop,
+	symbols map[int32]*giftDomain.Symbol,
+) {
+	for _, gift := range gifts {
+		e.populateSingleGiftAttributes(gift, models, backdrops, symbols)
+	}
+}
+
+func (e *giftEnricher) populateSingleGiftAttributes(
+	gift *giftDomain.Gift,
+	models map[int32]*giftDomain.Model,
+	backdrops map[int32]*giftDomain.Backdrop,
+	symbols map[int32]*giftDomain.Symbol,
+) {
+	if model, exists := models[gift.Model.ID]; exists && model != nil {
+		gift.Model = *model
+	} else {
+		e.log.Warn("Model not found for gift", zap.String("giftID", gift.ID), zap.Int32("modelID", gift.Model.ID))
+	}
+	if backdrop, exists := backdrops[gift.Backdrop.ID]; exists && backdrop != nil {
+		gift.Backdrop = *backdrop
+	} else {
+		e.log.Warn("Backdrop not found for gift", zap.String("giftID", gift.ID), zap.Int32("backdropID", gift.Backdrop.ID))
+	}
+	if symbol, exists := symbols[gift.Symbol.ID]; exists && symbol != nil {
+		gift.Symbol = *symbol
+	} else {
+		e.log.Warn("Symbol not found for gift", zap.String("giftID", gift.ID), zap.Int32("symbolID", gift.Symbol.ID))
+	}
+}
@@ -0,0 +1,93 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	giftDomain "github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/gift"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+)
+
+func TestResolveRelatedDuels(t *testing.T) {
+	tests := []struct {
+		name  string
+		gifts []*giftDomain.Gift
+		err   error
+		// wantRequests — ID подарков в каждом запросе к duel-сервису
+		wantRequests [][]string
+		wantDuels    []string
+		wantErr      error
+	}{
+		{
+			name: "stored duel is used as is",
+			gifts: []*giftDomain.Gift{
+				{ID: "g1", Status: giftDomain.StatusInGame, RelatedDuelID: "duel-stored"},
+				{ID: "g2", Status: giftDomain.StatusOwned},
+			},
+			wantDuels: []string{"duel-stored", ""},
+		},
+		{
+			// подарки, поставленные до появления related_duel_id, ищутся
+			// одним запросом на всю страницу
+			name: "legacy stakes are looked up in one batch",
+			gifts: []*giftDomain.Gift{
+				{ID: "g1", Status: giftDomain.StatusInGame},
+				{ID: "g2", Status: giftDomain.StatusInGame, RelatedDuelID: "duel-stored"},
+				{ID: "g3", Status: giftDomain.StatusInGame},
+				{ID: "g4", Status: giftDomain.StatusOwned},
+			},
+			wantRequests: [][]string{{"g1", "g3"}},
+			wantDuels:    []string{"duel-g1", "duel-stored", "duel-g3", ""},
+		},
+		{
+			name:         "unknown duel leaves the gift as is",
+			gifts:        []*giftDomain.Gift{{ID: "g9", Status: giftDomain.StatusInGame}},
+			wantRequests: [][]string{{"g9"}},
+			wantDuels:    []string{""},
+		},
+		{
+			name:         "duel service error",
+			gifts:        []*giftDomain.Gift{{ID: "g1", Status: giftDomain.StatusInGame}},
+			err:          errDuelUnavailable,
+			wantRequests: [][]string{{"g1"}},
+			wantErr:      errDuelUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log, err := logger.NewLogger(logger.Config{Service: "test", Level: "fatal"})
+			if err != nil {
+				t.Fatalf("logger: %v", err)
+			}
+			duels := &fakeDuelClient{
+				giftDuels: map[string]string{"g1": "duel-g1", "g3": "duel-g3"},
+				err:       tt.err,
+			}
+			e := newGiftEnricher(nil, log, duels)
+
+			err = e.resolveRelatedDuels(context.Background(), tt.gifts)
+			if !slices.EqualFunc(duels.requests, tt.wantRequests, slices.Equal) {
+				t.Errorf("duel requests %v, want %v", duels.requests, tt.wantRequests)
+			}
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := make([]string, len(tt.gifts))
+			for i, g := range tt.gifts {
+				got[i] = g.RelatedDuelID
+			}
+			if !slices.Equal(got, tt.wantDuels) {
+				t.Errorf("related duels %v, want %v", got, tt.wantDuels)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package query
+
+import (
+	"context"
+
+	"github.com/ccoveille/go-safecast"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/adapter/pg"
+	giftDomain "github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/gift"
+	"github.com/peterparker2005/giftduels/packages/grpc-go/clients"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+	duelv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/duel/v1"
+	sharedv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/shared/v1"
+	"github.com/peterparker2005/giftduels/packages/shared"
+	"go.uber.org/zap"
+)
+
+type GiftHistoryService struct {
+	repo              giftDomain.Repository
+	duelPrivateClient duelv1.DuelPrivateServiceClient
+	log               *logger.Logger
+}
+
+func NewGiftHistoryService(
+	repo giftDomain.Repository,
+	clients *clients.Clients,
+	log *logger.Logger,
+) *GiftHistoryService {
+	return &GiftHistoryService{
+		repo:              repo,
+		duelPrivateClient: clients.Duel.Private,
+		log:               log,
+	}
+}
+
+type GiftActivityEntry struct {
+	Event *giftDomain.Event
+	// RelatedDuel заполняется, если событие связано с дуэлью
+	RelatedDuel *duelv1.DuelSummary
+}
+
+type GetGiftActivityResult struct {
+	Entries []*GiftActivityEntry
+	Total   int32
+}
+
+// GetGiftHistory возвращает события конкретного подарка, от новых к старым.
+// История содержит Telegram ID других владельцев и ссылки на дуэли, поэтому
+// доступна только текущему владельцу и участникам событий подарка; остальным
+// подарок не виден.
+func (s *GiftHistoryService) GetGiftHistory(
+	ctx context.Context,
+	telegramUserID int64,
+	giftID string,
+	filter giftDomain.EventFilter,
+	pagination *shared.PageRequest,
+) (*GetGiftActivityResult, error) {
+	g, err := s.repo.GetGiftByID(ctx, giftID)
+	if err != nil {
+		if pg.IsNotFound(err) {
+			return nil, giftDomain.ErrGiftNotFound
+		}
+		s.log.Error("Failed to get gift", zap.String("giftID", giftID), zap.Error(err))
+		return nil, err
+	}
+	if !g.IsOwnedBy(telegramUserID) {
+		involved, involvedErr := s.repo.GiftHasUserEvent(ctx, giftID, telegramUserID)
+		if involvedErr != nil {
+			s.log.Error("Failed to check gift history access",
+				zap.String("giftID", giftID),
+				zap.Int64("telegramUserID", telegramUserID),
+				zap.Error(involvedErr),
+			)
+			return nil, involvedErr
+		}
+		if !involved {
+			return nil, giftDomain.ErrGiftNotFound
+		}
+	}
+
+	res, err := s.repo.GetGiftHistory(
+		ctx,
+		giftID,
+		filter,
+		pagination.PageSize(),
+		pagination.Offset(),
+	)
+	if err != nil {
+		s.log.Error("Failed to get gift history", zap.String("giftID", giftID), zap.Error(err))
+		return nil, err
+	}
+
+	return s.withRelatedDuels(ctx, res)
+}
+
+// GetUserGiftActivity возвращает события пользователя по всем его подаркам.
+func (s *GiftHistoryService) GetUserGiftActivity(
+	ctx context.Context,
+	telegramUserID int64,
+	filter giftDomain.EventFilter,
+	pagination *shared.PageRequest,
+) (*GetGiftActivityResult, error) {
+	res, err := s.repo.GetUserGiftActivity(
+		ctx,
+		telegramUserID,
+		filter,
+		pagination.PageSize(),
+		pagination.Offset(),
+	)
+	if err != nil {
+		s.log.Error("Failed to get user gift activity",
+			zap.Int64("telegramUserID", telegramUserID),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	return s.withRelatedDuels(ctx, res)
+}
+
+// withRelatedDuels подтягивает сводки дуэлей одним запросом в duel-сервис.
+func (s *GiftHistoryService) withRelatedDuels(
+	ctx context.Context,
+	res *giftDomain.GetGiftEventsResult,
+) (*GetGiftActivityResult, error) {
+	total, err := safecast.ToInt32(res.Total)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]struct{})
+	duelIDs := make([]*sharedv1.DuelId, 0)
+	for _, e := range res.Events {
+		if e.RelatedGameID == nil {
+			continue
+		}
+		if _, ok := seen[*e.RelatedGameID]; ok {
+			continue
+		}
+		seen[*e.RelatedGameID] = struct{}{}
+		duelIDs = append(duelIDs, &sharedv1.DuelId{Value: *e.RelatedGameID})
+	}
+
+	duelsByID := make(map[string]*duelv1.DuelSummary, len(duelIDs))
+	if len(duelIDs) > 0 {
+		resp, dErr := s.duelPrivateClient.GetDuelSummaries(ctx, &duelv1.GetDuelSummariesRequest{
+			DuelIds: duelIDs,
+		})
+		if dErr != nil {
+			s.log.Error("Failed to get duel summaries", zap.Error(dErr))
+			return nil, dErr
+		}
+		for _, d := range resp.GetDuels() {
+			duelsByID[d.GetDuelId().GetValue()] = d
+		}
+	}
+
+	entries := make([]*GiftActivityEntry, len(res.Events))
+	for i, e := range res.Events {
+		entry := &GiftActivityEntry{Event: e}
+		if e.RelatedGameID != nil {
+			entry.RelatedDuel = duelsByID[*e.RelatedGameID]
+		}
+		entries[i] = entry
+	}
+
+	return &GetGiftActivityResult{
+		Entries: entries,
+		Total:   total,
+	}, nil
+}
@@ -0,0 +1,301 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/adapter/pg"
+	giftDomain "github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/gift"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+	duelv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/duel/v1"
+	sharedv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/shared/v1"
+	"github.com/peterparker2005/giftduels/packages/shared"
+	"google.golang.org/grpc"
+)
+
+const (
+	ownerID       int64 = 1
+	participantID int64 = 2
+	outsiderID    int64 = 3
+	historyGiftID       = "7f9c6a3e-2b1d-4c5e-9f80-1a2b3c4d5e6f"
+)
+
+var errDuelUnavailable = errors.New("duel service unavailable")
+
+type fakeHistoryRepo struct {
+	giftDomain.Repository
+
+	gifts        map[string]*giftDomain.Gift
+	participants map[int64]bool
+	events       []*giftDomain.Event
+	filters      []giftDomain.EventFilter
+}
+
+func (r *fakeHistoryRepo) GetGiftByID(_ context.Context, id string) (*giftDomain.Gift, error) {
+	g, ok := r.gifts[id]
+	if !ok {
+		return nil, pg.ErrNotFound
+	}
+	return g, nil
+}
+
+func (r *fakeHistoryRepo) GiftHasUserEvent(_ context.Context, _ string, telegramUserID int64) (bool, error) {
+	return r.participants[telegramUserID], nil
+}
+
+func (r *fakeHistoryRepo) GetGiftHistory(
+	_ context.Context,
+	_ string,
+	filter giftDomain.EventFilter,
+	_, _ int32,
+) (*giftDomain.GetGiftEventsResult, error) {
+	return r.result(filter), nil
+}
+
+func (r *fakeHistoryRepo) GetUserGiftActivity(
+	_ context.Context,
+	_ int64,
+	filter giftDomain.EventFilter,
+	_, _ int32,
+) (*giftDomain.GetGiftEventsResult, error) {
+	return r.result(filter), nil
+}
+
+// result, как и SQL-запрос, отбирает события по типам из фильтра.
+func (r *fakeHistoryRepo) result(filter giftDomain.EventFilter) *giftDomain.GetGiftEventsResult {
+	r.filters = append(r.filters, filter)
+	var events []*giftDomain.Event
+	for _, e := range r.events {
+		if len(filter.EventTypes) == 0 || slices.Contains(filter.EventTypes, e.EventType) {
+			events = append(events, e)
+		}
+	}
+	return &giftDomain.GetGiftEventsResult{Events: events, Total: int64(len(events))}
+}
+
+type fakeDuelClient struct {
+	duelv1.DuelPrivateServiceClient
+
+	duels map[string]*duelv1.DuelSummary
+	// giftDuels — последняя дуэль каждого подарка
+	giftDuels map[string]string
+	err       error
+	requests  [][]string
+}
+
+func (c *fakeDuelClient) GetDuelSummaries(
+	_ context.Context,
+	in *duelv1.GetDuelSummariesRequest,
+	_ ...grpc.CallOption,
+) (*duelv1.GetDuelSummariesResponse, error) {
+	ids := make([]string, len(in.GetDuelIds()))
+	for i, id := range in.GetDuelIds() {
+		ids[i] = id.GetValue()
+	}
+	c.requests = append(c.requests, ids)
+	if c.err != nil {
+		return nil, c.err
+	}
+	resp := &duelv1.GetDuelSummariesResponse{}
+	for _, id := range ids {
+		if d, ok := c.duels[id]; ok {
+			resp.Duels = append(resp.Duels, d)
+		}
+	}
+	return resp, nil
+}
+
+func (c *fakeDuelClient) FindDuelsByGiftIDs(
+	_ context.Context,
+	in *duelv1.FindDuelsByGiftIDsRequest,
+	_ ...grpc.CallOption,
+) (*duelv1.FindDuelsByGiftIDsResponse, error) {
+	ids := make([]string, len(in.GetGiftIds()))
+	for i, id := range in.GetGiftIds() {
+		ids[i] = id.GetValue()
+	}
+	c.requests = append(c.requests, ids)
+	if c.err != nil {
+		return nil, c.err
+	}
+	resp := &duelv1.FindDuelsByGiftIDsResponse{}
+	for _, id := range ids {
+		if duelID, ok := c.giftDuels[id]; ok {
+			resp.GiftDuels = append(resp.GiftDuels, &duelv1.FindDuelsByGiftIDsResponse_GiftDuel{
+				GiftId: &sharedv1.GiftId{Value: id},
+				DuelId: &sharedv1.DuelId{Value: duelID},
+			})
+		}
+	}
+	return resp, nil
+}
+
+func event(id string, eventType giftDomain.EventType, duelID string) *giftDomain.Event {
+	e := &giftDomain.Event{ID: id, GiftID: historyGiftID, TelegramUserID: ownerID, EventType: eventType}
+	if duelID != "" {
+		e.RelatedGameID = &duelID
+	}
+	return e
+}
+
+func newHistoryService(t *testing.T) (*GiftHistoryService, *fakeHistoryRepo, *fakeDuelClient) {
+	t.Helper()
+	log, err := logger.NewLogger(logger.Config{Service: "test", Level: "fatal"})
+	if err != nil {
+		t.Fatalf("logger: %v", err)
+	}
+	repo := &fakeHistoryRepo{
+		gifts:        map[string]*giftDomain.Gift{historyGiftID: {ID: historyGiftID, OwnerTelegramID: ownerID}},
+		participants: map[int64]bool{participantID: true},
+		events: []*giftDomain.Event{
+			event("e1", giftDomain.EventTypeDeposit, ""),
+			event("e2", giftDomain.EventTypeStake, "duel-1"),
+			event("e3", giftDomain.EventTypeReturnFromGame, "duel-1"),
+			event("e4", giftDomain.EventTypeStake, "duel-2"),
+		},
+	}
+	duels := &fakeDuelClient{duels: map[string]*duelv1.DuelSummary{
+		"duel-1": {DuelId: &sharedv1.DuelId{Value: "duel-1"}, DisplayNumber: 11},
+	}}
+	return &GiftHistoryService{repo: repo, duelPrivateClient: duels, log: log}, repo, duels
+}
+
+func TestGetGiftHistoryAccess(t *testing.T) {
+	tests := []struct {
+		name           string
+		telegramUserID int64
+		giftID         string
+		wantErr        error
+	}{
+		{name: "owner", telegramUserID: ownerID, giftID: historyGiftID},
+		{name: "former participant", telegramUserID: participantID, giftID: historyGiftID},
+		// чужой подарок неотличим от несуществующего
+		{
+			name:           "outsider",
+			telegramUserID: outsiderID,
+			giftID:         historyGiftID,
+			wantErr:        giftDomain.ErrGiftNotFound,
+		},
+		{
+			name:           "missing gift",
+			telegramUserID: ownerID,
+			giftID:         "00000000-0000-0000-0000-000000000000",
+			wantErr:        giftDomain.ErrGiftNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repo, _ := newHistoryService(t)
+
+			res, err := s.GetGiftHistory(
+				context.Background(),
+				tt.telegramUserID,
+				tt.giftID,
+				giftDomain.EventFilter{},
+				shared.NewPageRequest(1, 10),
+			)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				if len(repo.filters) != 0 {
+					t.Errorf("history was read for %d", tt.telegramUserID)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.Total != 4 || len(res.Entries) != 4 {
+				t.Errorf("got %d of %d entries, want 4", len(res.Entries), res.Total)
+			}
+		})
+	}
+}
+
+func TestGiftActivityRelatedDuels(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter giftDomain.EventFilter
+		setup  func(duels *fakeDuelClient)
+		// wantRequests — ID дуэлей в каждом запросе к duel-сервису
+		wantRequests [][]string
+		// wantDuels — номер дуэли для каждого события; 0 — дуэли нет
+		wantEvents []string
+		wantDuels  []int64
+		wantErr    error
+	}{
+		{
+			name:         "summaries are fetched once per duel",
+			wantRequests: [][]string{{"duel-1", "duel-2"}},
+			wantEvents:   []string{"e1", "e2", "e3", "e4"},
+			// duel-2 сервис не вернул — событие остаётся без сводки
+			wantDuels: []int64{0, 11, 11, 0},
+		},
+		{
+			name: "filter by event type",
+			filter: giftDomain.EventFilter{
+				EventTypes: []giftDomain.EventType{giftDomain.EventTypeReturnFromGame},
+			},
+			wantRequests: [][]string{{"duel-1"}},
+			wantEvents:   []string{"e3"},
+			wantDuels:    []int64{11},
+		},
+		{
+			name:       "no duel events skip the duel service",
+			filter:     giftDomain.EventFilter{EventTypes: []giftDomain.EventType{giftDomain.EventTypeDeposit}},
+			wantEvents: []string{"e1"},
+			wantDuels:  []int64{0},
+		},
+		{
+			name: "duel service error",
+			setup: func(duels *fakeDuelClient) {
+				duels.err = errDuelUnavailable
+			},
+			wantRequests: [][]string{{"duel-1", "duel-2"}},
+			wantErr:      errDuelUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repo, duels := newHistoryService(t)
+			if tt.setup != nil {
+				tt.setup(duels)
+			}
+
+			res, err := s.GetUserGiftActivity(context.Background(), ownerID, tt.filter, shared.NewPageRequest(1, 10))
+			if !slices.EqualFunc(duels.requests, tt.wantRequests, slices.Equal) {
+				t.Errorf("duel requests %v, want %v", duels.requests, tt.wantRequests)
+			}
+			if len(repo.filters) != 1 || !slices.Equal(repo.filters[0].EventTypes, tt.filter.EventTypes) {
+				t.Errorf("filters %v, want %v", repo.filters, tt.filter)
+			}
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var events []string
+			var numbers []int64
+			for _, e := range res.Entries {
+				events = append(events, e.Event.ID)
+				numbers = append(numbers, e.RelatedDuel.GetDisplayNumber())
+			}
+			if !slices.Equal(events, tt.wantEvents) || !slices.Equal(numbers, tt.wantDuels) {
+				t.Errorf("entries %v with duels %v, want %v with %v", events, numbers, tt.wantEvents, tt.wantDuels)
+			}
+			if int(res.Total) != len(tt.wantEvents) {
+				t.Errorf("total %d, want %d", res.Total, len(tt.wantEvents))
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package query
+
+import (
+	"context"
+
+	"github.com/ccoveille/go-safecast"
+	giftDomain "github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/gift"
+	listingDomain "github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/listing"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+	"github.com/peterparker2005/giftduels/packages/shared"
+	"go.uber.org/zap"
+)
+
+type ListingReadService struct {
+	listingRepo     listingDomain.Repository
+	giftReadService *GiftReadService
+	log             *logger.Logger
+}
+
+func NewListingReadService(
+	listingRepo listingDomain.Repository,
+	giftReadService *GiftReadService,
+	log *logger.Logger,
+) *ListingReadService {
+	return &ListingReadService{
+		listingRepo:     listingRepo,
+		giftReadService: giftReadService,
+		log:             log,
+	}
+}
+
+type ListingWithGift struct {
+	Listing *listingDomain.Listing
+	Gift    *giftDomain.Gift
+}
+
+type GetListingsResult struct {
+	Listings []*ListingWithGift
+	Total    int32
+}
+
+func (s *ListingReadService) GetListings(
+	ctx context.Context,
+	filter *listingDomain.Filter,
+	pagination *shared.PageRequest,
+) (*GetListingsResult, error) {
+	res, err := s.listingRepo.GetActiveListings(
+		ctx,
+		filter,
+		pagination.PageSize(),
+		pagination.Offset(),
+	)
+	if err != nil {
+		s.log.Error("Failed to get active listings", zap.Error(err))
+		return nil, err
+	}
+
+	giftIDs := make([]string, len(res.Listings))
+	for i, l := range res.Listings {
+		giftIDs[i] = l.GiftID
+	}
+
+	// Подарки с атрибутами одним запросом
+	gifts, err := s.giftReadService.GetGiftsByIDs(ctx, giftIDs)
+	if err != nil {
+		s.log.Error("Failed to get listed gifts", zap.Error(err))
+		return nil, err
+	}
+	giftsByID := make(map[string]*giftDomain.Gift, len(gifts))
+	for _, g := range gifts {
+		giftsByID[g.ID] = g
+	}
+
+	listings := make([]*ListingWithGift, 0, len(res.Listings))
+	for _, l := range res.Listings {
+		g, ok := giftsByID[l.GiftID]
+		if !ok {
+			s.log.Warn("Gift not found for listing",
+				zap.String("listingID", l.ID),
+				zap.String("giftID", l.GiftID),
+			)
+			continue
+		}
+		listings = append(listings, &ListingWithGift{Listing: l, Gift: g})
+	}
+
+	total, err := safecast.ToInt32(res.Total)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GetListingsResult{
+		Listings: listings,
+		Total:    total,
+	}, nil
+}
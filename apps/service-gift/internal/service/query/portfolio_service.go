@@ -0,0 +1,105 @@
+package query
+
+import (
+	"context"
+	"time"
+
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/config"
+	portfolioDomain "github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/portfolio"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+	"go.uber.org/zap"
+)
+
+// defaultHistoryDays — глубина истории, если клиент не указал начало периода.
+const defaultHistoryDays = 30
+
+const day = 24 * time.Hour
+
+type Portfolio struct {
+	Valuation   *portfolioDomain.Valuation
+	Performance *portfolioDomain.Performance
+}
+
+// PortfolioService считает стоимость подарков пользователя и результат его дуэлей.
+type PortfolioService struct {
+	repo           portfolioDomain.Repository
+	maxHistoryDays int
+	log            *logger.Logger
+}
+
+func NewPortfolioService(
+	repo portfolioDomain.Repository,
+	cfg *config.Config,
+	log *logger.Logger,
+) *PortfolioService {
+	return &PortfolioService{
+		repo:           repo,
+		maxHistoryDays: cfg.Portfolio.MaxHistoryDays,
+		log:            log,
+	}
+}
+
+func (s *PortfolioService) GetPortfolio(ctx context.Context, telegramUserID int64) (*Portfolio, error) {
+	valuation, err := s.repo.GetUserValuation(ctx, telegramUserID)
+	if err != nil {
+		s.log.Error("Failed to get portfolio valuation", zap.Error(err))
+		return nil, err
+	}
+
+	performance, err := s.repo.GetUserPerformance(ctx, telegramUserID)
+	if err != nil {
+		s.log.Error("Failed to get portfolio performance", zap.Error(err))
+		return nil, err
+	}
+
+	return &Portfolio{Valuation: valuation, Performance: performance}, nil
+}
+
+// GetPortfolioHistory возвращает дневные снапшоты за период. Нулевой to — сегодня,
+// нулевой from — defaultHistoryDays назад; период длиннее maxHistoryDays
+// обрезается с начала. Точка за сегодня всегда считается по текущим ценам,
+// а не берётся из последнего снапшота.
+func (s *PortfolioService) GetPortfolioHistory(
+	ctx context.Context,
+	telegramUserID int64,
+	from, to time.Time,
+) ([]*portfolioDomain.Snapshot, error) {
+	today := portfolioDomain.SnapshotDate(time.Now())
+	if to.IsZero() || to.After(today) {
+		to = today
+	}
+	to = portfolioDomain.SnapshotDate(to)
+	if from.IsZero() {
+		from = to.Add(-defaultHistoryDays * day)
+	}
+	from = portfolioDomain.SnapshotDate(from)
+	if from.After(to) {
+		return nil, portfolioDomain.ErrInvalidHistoryRange
+	}
+	if earliest := to.Add(-time.Duration(s.maxHistoryDays) * day); from.Before(earliest) {
+		from = earliest
+	}
+
+	snapshots, err := s.repo.GetUserSnapshots(ctx, telegramUserID, from, to)
+	if err != nil {
+		s.log.Error("Failed to get portfolio snapshots", zap.Error(err))
+		return nil, err
+	}
+
+	if !to.Equal(today) {
+		return snapshots, nil
+	}
+
+	valuation, err := s.repo.GetUserValuation(ctx, telegramUserID)
+	if err != nil {
+		s.log.Error("Failed to get portfolio valuation", zap.Error(err))
+		return nil, err
+	}
+	live := &portfolioDomain.Snapshot{Date: today, Valuation: *valuation}
+	if n := len(snapshots); n > 0 && snapshots[n-1].Date.Equal(today) {
+		snapshots[n-1] = live
+	} else {
+		snapshots = append(snapshots, live)
+	}
+	return snapshots, nil
+}
@@ -0,0 +1,169 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/config"
+	portfolioDomain "github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/portfolio"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+)
+
+// fakePortfolioRepo хранит снапшоты и текущую оценку портфеля.
+type fakePortfolioRepo struct {
+	portfolioDomain.Repository
+
+	snapshots []*portfolioDomain.Snapshot
+	live      *portfolioDomain.Valuation
+	// from и to — период последнего запроса снапшотов
+	from, to time.Time
+}
+
+func (r *fakePortfolioRepo) GetUserSnapshots(
+	_ context.Context,
+	_ int64,
+	from, to time.Time,
+) ([]*portfolioDomain.Snapshot, error) {
+	r.from, r.to = from, to
+	var out []*portfolioDomain.Snapshot
+	for _, s := range r.snapshots {
+		if !s.Date.Before(from) && !s.Date.After(to) {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakePortfolioRepo) GetUserValuation(context.Context, int64) (*portfolioDomain.Valuation, error) {
+	return r.live, nil
+}
+
+func valuation(t *testing.T, owned string) portfolioDomain.Valuation {
+	t.Helper()
+	return portfolioDomain.Valuation{OwnedValue: mustTon(t, owned), InGameValue: mustTon(t, "0")}
+}
+
+func TestGetPortfolioHistory(t *testing.T) {
+	today := portfolioDomain.SnapshotDate(time.Now())
+	daysAgo := func(n int) time.Time { return today.Add(-time.Duration(n) * day) }
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		// stored — дни назад, за которые есть снапшоты
+		stored   []int
+		wantFrom time.Time
+		wantTo   time.Time
+		// wantDays — дни назад в ответе; 0 — сегодняшняя точка по текущим ценам
+		wantDays []int
+		wantErr  error
+	}{
+		{
+			name:     "defaults to the last 30 days",
+			stored:   []int{40, 30, 2, 1},
+			wantFrom: daysAgo(30),
+			wantTo:   today,
+			wantDays: []int{30, 2, 1, 0},
+		},
+		{
+			// снапшот за сегодня мог устареть — его заменяет текущая оценка
+			name:     "today's snapshot is replaced by live valuation",
+			stored:   []int{1, 0},
+			wantFrom: daysAgo(30),
+			wantTo:   today,
+			wantDays: []int{1, 0},
+		},
+		{
+			name:     "past period has no live point",
+			from:     daysAgo(10),
+			to:       daysAgo(5),
+			stored:   []int{12, 9, 5, 1},
+			wantFrom: daysAgo(10),
+			wantTo:   daysAgo(5),
+			wantDays: []int{9, 5},
+		},
+		{
+			name:     "future end is today",
+			from:     daysAgo(3),
+			to:       today.Add(5 * day),
+			wantFrom: daysAgo(3),
+			wantTo:   today,
+			wantDays: []int{0},
+		},
+		{
+			name:     "long period is cut from the start",
+			from:     daysAgo(400),
+			wantFrom: daysAgo(90),
+			wantTo:   today,
+			wantDays: []int{0},
+		},
+		{
+			name:     "times are truncated to dates",
+			from:     daysAgo(2).Add(13 * time.Hour),
+			to:       daysAgo(1).Add(22 * time.Hour),
+			stored:   []int{2, 1},
+			wantFrom: daysAgo(2),
+			wantTo:   daysAgo(1),
+			wantDays: []int{2, 1},
+		},
+		{
+			name:    "start after end",
+			from:    daysAgo(1),
+			to:      daysAgo(2),
+			wantErr: portfolioDomain.ErrInvalidHistoryRange,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log, err := logger.NewLogger(logger.Config{Service: "test", Level: "fatal"})
+			if err != nil {
+				t.Fatalf("logger: %v", err)
+			}
+			live := valuation(t, "999")
+			repo := &fakePortfolioRepo{live: &live}
+			for _, n := range tt.stored {
+				repo.snapshots = append(repo.snapshots, &portfolioDomain.Snapshot{
+					Date:      daysAgo(n),
+					Valuation: valuation(t, "1"),
+				})
+			}
+			cfg := &config.Config{}
+			cfg.Portfolio.MaxHistoryDays = 90
+			s := NewPortfolioService(repo, cfg, log)
+
+			snapshots, err := s.GetPortfolioHistory(context.Background(), 1, tt.from, tt.to)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !repo.from.Equal(tt.wantFrom) || !repo.to.Equal(tt.wantTo) {
+				t.Errorf("period %s..%s, want %s..%s", repo.from, repo.to, tt.wantFrom, tt.wantTo)
+			}
+
+			if len(snapshots) != len(tt.wantDays) {
+				t.Fatalf("got %d snapshots, want %d", len(snapshots), len(tt.wantDays))
+			}
+			for i, n := range tt.wantDays {
+				got := snapshots[i]
+				if !got.Date.Equal(daysAgo(n)) {
+					t.Errorf("snapshot %d date %s, want %s", i, got.Date, daysAgo(n))
+				}
+				wantOwned := "1"
+				if n == 0 {
+					wantOwned = "999"
+				}
+				if got.Valuation.OwnedValue.String() != wantOwned {
+					t.Errorf("snapshot %d owned %s, want %s", i, got.Valuation.OwnedValue, wantOwned)
+				}
+			}
+		})
+	}
+}
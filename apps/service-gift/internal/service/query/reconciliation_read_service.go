@@ -0,0 +1,33 @@
+package query
+
+import (
+	"context"
+
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/adapter/pg"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/reconciliation"
+)
+
+type ReconciliationReadService struct {
+	repo reconciliation.Repository
+}
+
+func NewReconciliationReadService(repo reconciliation.Repository) *ReconciliationReadService {
+	return &ReconciliationReadService{repo: repo}
+}
+
+// GetRun возвращает прогон сверки; пустой runID — последний прогон.
+func (s *ReconciliationReadService) GetRun(ctx context.Context, runID string) (*reconciliation.Run, error) {
+	var (
+		run *reconciliation.Run
+		err error
+	)
+	if runID == "" {
+		run, err = s.repo.GetLatestRun(ctx)
+	} else {
+		run, err = s.repo.GetRun(ctx, runID)
+	}
+	if pg.IsNotFound(err) {
+		return nil, reconciliation.ErrRunNotFound
+	}
+	return run, err
+}
@@ -0,0 +1,155 @@
+package query
+
+import (
+	"context"
+
+	favoriteDomain "github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/favorite"
+	giftDomain "github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/gift"
+	"github.com/peterparker2005/giftduels/packages/grpc-go/clients"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+	"github.com/peterparker2005/giftduels/packages/tonamount-go"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxSuggestionCandidates — сколько подарков пользователя участвует в
+	// подборе ставки: самые дорогие из тех, что не дороже границы цели.
+	maxSuggestionCandidates = 500
+	// DefaultSuggestionMaxGifts — предел подарков в подобранной ставке,
+	// совпадает с максимумом подарков на участника дуэли.
+	DefaultSuggestionMaxGifts = 10
+)
+
+type StakeSuggestionService struct {
+	giftRepo     giftDomain.Repository
+	favoriteRepo favoriteDomain.Repository
+	enricher     *giftEnricher
+	log          *logger.Logger
+}
+
+func NewStakeSuggestionService(
+	giftRepo giftDomain.Repository,
+	favoriteRepo favoriteDomain.Repository,
+	log *logger.Logger,
+	clients *clients.Clients,
+) *StakeSuggestionService {
+	return &StakeSuggestionService{
+		giftRepo:     giftRepo,
+		favoriteRepo: favoriteRepo,
+		enricher:     newGiftEnricher(giftRepo, log, clients.Duel.Private),
+		log:          log,
+	}
+}
+
+type SuggestStakeParams struct {
+	TelegramUserID int64
+	Target         giftDomain.StakeTarget
+	// MaxGifts <= 0 или больше DefaultSuggestionMaxGifts заменяется на DefaultSuggestionMaxGifts
+	MaxGifts int
+	// FavoritesOnly ограничивает подбор избранными подарками
+	FavoritesOnly bool
+}
+
+type StakeSuggestion struct {
+	Gifts []*giftDomain.Gift
+	Total *tonamount.TonAmount
+}
+
+// SuggestStake подбирает из свободных подарков пользователя набор с суммой
+// цен ближе всего к цели.
+func (s *StakeSuggestionService) SuggestStake(
+	ctx context.Context,
+	params SuggestStakeParams,
+) (*StakeSuggestion, error) {
+	log := s.log.With(zap.Int64("telegramUserID", params.TelegramUserID))
+
+	maxGifts := params.MaxGifts
+	if maxGifts <= 0 || maxGifts > DefaultSuggestionMaxGifts {
+		maxGifts = DefaultSuggestionMaxGifts
+	}
+
+	candidates, err := s.stakeCandidates(ctx, params.TelegramUserID, params.Target)
+	if err != nil {
+		log.Error("Failed to get stake candidates", zap.Error(err))
+		return nil, err
+	}
+
+	if params.FavoritesOnly {
+		candidates, err = s.onlyFavorites(ctx, params.TelegramUserID, candidates)
+		if err != nil {
+			log.Error("Failed to get favorite gift IDs", zap.Error(err))
+			return nil, err
+		}
+	}
+
+	picked, err := giftDomain.SuggestStake(candidates, params.Target, maxGifts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.enricher.populateGiftAttributes(ctx, picked); err != nil {
+		log.Error("Failed to populate gift attributes", zap.Error(err))
+		return nil, err
+	}
+
+	total := tonamount.Zero()
+	for _, g := range picked {
+		total = total.Add(g.Price)
+	}
+	return &StakeSuggestion{Gifts: picked, Total: total}, nil
+}
+
+// stakeCandidates выбирает подарки вокруг цели: более дорогие не приблизят
+// сумму к цели, а среди оставшихся в подборе важнее всего крупные.
+func (s *StakeSuggestionService) stakeCandidates(
+	ctx context.Context,
+	telegramUserID int64,
+	target giftDomain.StakeTarget,
+) ([]*giftDomain.Gift, error) {
+	statuses := []giftDomain.Status{giftDomain.StatusOwned}
+
+	candidates, err := s.giftRepo.SearchUserGifts(
+		ctx,
+		telegramUserID,
+		&giftDomain.InventoryFilter{Statuses: statuses, MaxPrice: target.CandidatePriceCap()},
+		giftDomain.InventorySort{By: giftDomain.InventorySortByPrice, Desc: true},
+		maxSuggestionCandidates,
+		0,
+	)
+	if err != nil || len(candidates) > 0 || target.Max != nil {
+		return candidates, err
+	}
+
+	// все подарки дороже удвоенной цели — ближе всего к ней самый дешёвый
+	return s.giftRepo.SearchUserGifts(
+		ctx,
+		telegramUserID,
+		&giftDomain.InventoryFilter{Statuses: statuses},
+		giftDomain.InventorySort{By: giftDomain.InventorySortByPrice},
+		1,
+		0,
+	)
+}
+
+func (s *StakeSuggestionService) onlyFavorites(
+	ctx context.Context,
+	telegramUserID int64,
+	gifts []*giftDomain.Gift,
+) ([]*giftDomain.Gift, error) {
+	favoriteIDs, err := s.favoriteRepo.GetFavoriteGiftIDs(ctx, telegramUserID)
+	if err != nil {
+		return nil, err
+	}
+	favorites := make(map[string]struct{}, len(favoriteIDs))
+	for _, id := range favoriteIDs {
+		favorites[id] = struct{}{}
+	}
+
+	out := make([]*giftDomain.Gift, 0, len(favoriteIDs))
+	for _, g := range gifts {
+		if _, ok := favorites[g.ID]; ok {
+			out = append(out, g)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,133 @@
+package query
+
+import (
+	"context"
+
+	"github.com/ccoveille/go-safecast"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/adapter/pg"
+	giftDomain "github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/gift"
+	withdrawalDomain "github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/withdrawal"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+	"github.com/peterparker2005/giftduels/packages/shared"
+	"go.uber.org/zap"
+)
+
+type WithdrawalReadService struct {
+	withdrawalRepo  withdrawalDomain.Repository
+	giftReadService *GiftReadService
+	log             *logger.Logger
+}
+
+func NewWithdrawalReadService(
+	withdrawalRepo withdrawalDomain.Repository,
+	giftReadService *GiftReadService,
+	log *logger.Logger,
+) *WithdrawalReadService {
+	return &WithdrawalReadService{
+		withdrawalRepo:  withdrawalRepo,
+		giftReadService: giftReadService,
+		log:             log,
+	}
+}
+
+type WithdrawalWithGifts struct {
+	Withdrawal *withdrawalDomain.Withdrawal
+	// GiftsByID — подарки вывода с атрибутами
+	GiftsByID map[string]*giftDomain.Gift
+}
+
+type ListWithdrawalsResult struct {
+	Withdrawals []*WithdrawalWithGifts
+	Total       int32
+}
+
+func (s *WithdrawalReadService) ListUserWithdrawals(
+	ctx context.Context,
+	telegramUserID int64,
+	statuses []withdrawalDomain.Status,
+	pagination *shared.PageRequest,
+) (*ListWithdrawalsResult, error) {
+	res, err := s.withdrawalRepo.GetUserWithdrawals(
+		ctx,
+		telegramUserID,
+		statuses,
+		pagination.PageSize(),
+		pagination.Offset(),
+	)
+	if err != nil {
+		s.log.Error("Failed to get user withdrawals", zap.Error(err))
+		return nil, err
+	}
+
+	giftsByID, err := s.loadGifts(ctx, res.Withdrawals...)
+	if err != nil {
+		return nil, err
+	}
+
+	withdrawals := make([]*WithdrawalWithGifts, len(res.Withdrawals))
+	for i, w := range res.Withdrawals {
+		withdrawals[i] = &WithdrawalWithGifts{Withdrawal: w, GiftsByID: giftsByID}
+	}
+
+	total, err := safecast.ToInt32(res.Total)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListWithdrawalsResult{
+		Withdrawals: withdrawals,
+		Total:       total,
+	}, nil
+}
+
+// GetUserWithdrawal возвращает вывод, если он принадлежит пользователю.
+func (s *WithdrawalReadService) GetUserWithdrawal(
+	ctx context.Context,
+	telegramUserID int64,
+	withdrawalID string,
+) (*WithdrawalWithGifts, error) {
+	w, err := s.withdrawalRepo.GetWithdrawalByID(ctx, withdrawalID)
+	if err != nil {
+		if pg.IsNotFound(err) {
+			return nil, withdrawalDomain.ErrWithdrawalNotFound
+		}
+		s.log.Error("Failed to get withdrawal", zap.Error(err))
+		return nil, err
+	}
+	// чужие выводы не раскрываем
+	if !w.IsOwnedBy(telegramUserID) {
+		return nil, withdrawalDomain.ErrWithdrawalNotFound
+	}
+
+	giftsByID, err := s.loadGifts(ctx, w)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WithdrawalWithGifts{Withdrawal: w, GiftsByID: giftsByID}, nil
+}
+
+// loadGifts загружает подарки всех выводов одним запросом.
+func (s *WithdrawalReadService) loadGifts(
+	ctx context.Context,
+	withdrawals ...*withdrawalDomain.Withdrawal,
+) (map[string]*giftDomain.Gift, error) {
+	var giftIDs []string
+	for _, w := range withdrawals {
+		for _, g := range w.Gifts {
+			giftIDs = append(giftIDs, g.GiftID)
+		}
+	}
+
+	gifts, err := s.giftReadService.GetGiftsByIDs(ctx, giftIDs)
+	if err != nil {
+		s.log.Error("Failed to get withdrawal gifts", zap.Error(err))
+		return nil, err
+	}
+
+	giftsByID := make(map[string]*giftDomain.Gift, len(gifts))
+	for _, g := range gifts {
+		giftsByID[g.ID] = g
+	}
+	return giftsByID, nil
+}
@@ -0,0 +1,56 @@
+package saga
+
+import (
+	"context"
+
+	giftDomain "github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/gift"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+	"go.uber.org/zap"
+)
+
+// compensation откатывает уже выполненный внешний шаг саги.
+type compensation struct {
+	name string
+	fn   func(ctx context.Context) error
+	// failure сохраняется, если откатить шаг не удалось. Saga, Step и Error
+	// заполняет compensate.
+	failure giftDomain.CreateFailedCompensationParams
+}
+
+// compensate выполняет компенсации в обратном порядке. Контекст запроса
+// может быть уже отменён, поэтому компенсации работают без его отмены.
+// Непрошедшие компенсации записываются в failed_saga_compensations для
+// ручного разбора.
+func compensate(
+	ctx context.Context,
+	log *logger.Logger,
+	repo giftDomain.Repository,
+	saga string,
+	compensations []compensation,
+) {
+	ctx = context.WithoutCancel(ctx)
+	for i := len(compensations) - 1; i >= 0; i-- {
+		c := compensations[i]
+		err := c.fn(ctx)
+		if err == nil {
+			log.Info("saga compensation applied", zap.String("step", c.name))
+			continue
+		}
+		log.Error("saga compensation failed",
+			zap.String("step", c.name),
+			zap.Error(err),
+		)
+
+		failure := c.failure
+		failure.Saga = saga
+		failure.Step = c.name
+		failure.Error = err.Error()
+		if recErr := repo.CreateFailedCompensation(ctx, &failure); recErr != nil {
+			log.Error("failed to record failed saga compensation",
+				zap.String("step", c.name),
+				zap.String("idempotencyKey", failure.IdempotencyKey),
+				zap.Error(recErr),
+			)
+		}
+	}
+}
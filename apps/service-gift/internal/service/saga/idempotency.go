@@ -0,0 +1,36 @@
+package saga
+
+import (
+	"strconv"
+
+	giftDomain "github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/gift"
+)
+
+// Операции с балансом, для которых строятся ключи идемпотентности.
+const (
+	opWithdrawalCommission = "withdrawal-commission"
+	opWithdrawalRefund     = "withdrawal-refund"
+	opBuy                  = "buy"
+	opSale                 = "sale"
+	opBuyRefund            = "buy-refund"
+	opSaleRevert           = "sale-revert"
+	opSellBack             = "sell-back"
+	opSellBackRevert       = "sell-back-revert"
+)
+
+// giftIdempotencyKey строит ключ идемпотентности операции с балансом по
+// подарку. Повтор шага саги видит ту же версию подарка (updated_at) и
+// получает тот же ключ, а новый вывод того же подарка после отмены — уже
+// другой.
+func giftIdempotencyKey(operation string, gift *giftDomain.Gift) string {
+	return "gift:" + gift.ID + ":" +
+		strconv.FormatInt(gift.UpdatedAt.UnixMicro(), 10) + ":" + operation
+}
+
+// attemptIdempotencyKey дополняет ключ подарка идентификатором запуска саги.
+// Откат транзакции не меняет updated_at, поэтому без него повторная попытка
+// после компенсации получила бы ключи первой, и платёжный сервис вернул бы
+// старый результат вместо нового списания.
+func attemptIdempotencyKey(operation string, gift *giftDomain.Gift, attemptID string) string {
+	return giftIdempotencyKey(operation, gift) + ":" + attemptID
+}
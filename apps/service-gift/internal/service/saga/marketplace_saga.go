@@ -0,0 +1,286 @@
+package saga
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/adapter/pg"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/config"
+	giftDomain "github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/gift"
+	listingDomain "github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/listing"
+	"github.com/peterparker2005/giftduels/packages/grpc-go/clients"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+	paymentv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/payment/v1"
+	sharedv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/shared/v1"
+	"github.com/peterparker2005/giftduels/packages/tonamount-go"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// sagaMarketplace — имя саги в failed_saga_compensations.
+const sagaMarketplace = "marketplace"
+
+type MarketplaceSaga struct {
+	txMgr                pg.TxManager
+	repo                 giftDomain.Repository
+	listingRepo          listingDomain.Repository
+	log                  *logger.Logger
+	paymentPrivateClient paymentv1.PaymentPrivateServiceClient
+	feePercent           decimal.Decimal
+}
+
+func NewMarketplaceSaga(
+	repo giftDomain.Repository,
+	listingRepo listingDomain.Repository,
+	txMgr pg.TxManager,
+	log *logger.Logger,
+	clients *clients.Clients,
+	cfg *config.Config,
+) (*MarketplaceSaga, error) {
+	feePercent, err := decimal.NewFromString(cfg.Marketplace.FeePercent)
+	if err != nil {
+		return nil, fmt.Errorf("invalid marketplace fee percent %q: %w", cfg.Marketplace.FeePercent, err)
+	}
+	return &MarketplaceSaga{
+		repo:                 repo,
+		listingRepo:          listingRepo,
+		txMgr:                txMgr,
+		log:                  log,
+		paymentPrivateClient: clients.Payment.Private,
+		feePercent:           feePercent,
+	}, nil
+}
+
+type BuyGiftResult struct {
+	Gift    *giftDomain.Gift
+	Listing *listingDomain.Listing
+}
+
+// BuyGift покупает выставленный подарок за TON баланс покупателя.
+//
+// Шаги саги:
+//  1. списание цены лота с баланса покупателя;
+//  2. начисление продавцу цены за вычетом комиссии площадки;
+//  3. передача подарка и закрытие лота в транзакции БД.
+//
+// Если любой шаг после списания падает, уже выполненные платежи компенсируются
+// в обратном порядке. Каждый платёж идёт с ключом идемпотентности, а
+// непрошедшие компенсации сохраняются для ручного разбора.
+func (s *MarketplaceSaga) BuyGift(
+	ctx context.Context,
+	buyerTelegramID int64,
+	listingID string,
+) (*BuyGiftResult, error) {
+	log := s.log.With(
+		zap.String("listingID", listingID),
+		zap.Int64("buyerTelegramID", buyerTelegramID),
+	)
+
+	// Блокируем лот, чтобы его не купили дважды
+	tx, err := s.txMgr.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var commitErr error
+	var compensations []compensation
+	defer func() {
+		if commitErr != nil {
+			if rbErr := tx.Rollback(ctx); rbErr != nil {
+				log.Error("rollback failed", zap.Error(rbErr))
+			}
+			compensate(ctx, log, s.repo, sagaMarketplace, compensations)
+		}
+	}()
+
+	repo := s.repo.WithTx(tx)
+	listingRepo := s.listingRepo.WithTx(tx)
+
+	l, g, err := s.loadAndValidate(ctx, repo, listingRepo, buyerTelegramID, listingID)
+	if err != nil {
+		commitErr = err
+		return nil, err
+	}
+
+	fee, payout, err := listingDomain.CalculateFee(l.Price, s.feePercent)
+	if err != nil {
+		commitErr = err
+		log.Error("failed to calculate marketplace fee", zap.Error(err))
+		return nil, err
+	}
+
+	attemptID := uuid.NewString()
+	metadata := &paymentv1.TransactionMetadata{
+		Data: &paymentv1.TransactionMetadata_Gift{
+			Gift: &paymentv1.TransactionMetadata_GiftDetails{
+				GiftId: g.ID,
+				Title:  g.Title,
+				Slug:   g.Slug,
+			},
+		},
+	}
+
+	// Шаг 1: списываем цену с покупателя
+	_, err = s.paymentPrivateClient.SpendUserBalance(ctx, &paymentv1.SpendUserBalanceRequest{
+		TelegramUserId: &sharedv1.TelegramUserId{Value: buyerTelegramID},
+		TonAmount:      &sharedv1.TonAmount{Value: l.Price.String()},
+		Reason:         paymentv1.TransactionReason_TRANSACTION_REASON_PURCHASE,
+		Metadata:       metadata,
+		IdempotencyKey: attemptIdempotencyKey(opBuy, g, attemptID),
+	})
+	if err != nil {
+		commitErr = err
+		log.Error("failed to spend buyer balance", zap.Error(err))
+		return nil, err
+	}
+	refundKey := attemptIdempotencyKey(opBuyRefund, g, attemptID)
+	compensations = append(compensations, compensation{
+		name: "refund buyer",
+		fn: func(ctx context.Context) error {
+			_, cErr := s.paymentPrivateClient.AddUserBalance(ctx, &paymentv1.AddUserBalanceRequest{
+				TelegramUserId: &sharedv1.TelegramUserId{Value: buyerTelegramID},
+				TonAmount:      &sharedv1.TonAmount{Value: l.Price.String()},
+				Reason:         paymentv1.TransactionReason_TRANSACTION_REASON_REFUND,
+				Metadata:       metadata,
+				IdempotencyKey: refundKey,
+			})
+			return cErr
+		},
+		failure: giftDomain.CreateFailedCompensationParams{
+			GiftID:         g.ID,
+			TelegramUserID: buyerTelegramID,
+			Amount:         l.Price,
+			IdempotencyKey: refundKey,
+		},
+	})
+
+	// Шаг 2: начисляем продавцу выручку за вычетом комиссии
+	saleKey := attemptIdempotencyKey(opSale, g, attemptID)
+	if err = s.creditSeller(ctx, l.SellerTelegramID, payout, metadata, saleKey); err != nil {
+		commitErr = err
+		log.Error("failed to credit seller", zap.Error(err))
+		return nil, err
+	}
+	if !payout.IsZero() {
+		revertKey := attemptIdempotencyKey(opSaleRevert, g, attemptID)
+		compensations = append(compensations, compensation{
+			name: "revert seller payout",
+			fn: func(ctx context.Context) error {
+				_, cErr := s.paymentPrivateClient.SpendUserBalance(
+					ctx,
+					&paymentv1.SpendUserBalanceRequest{
+						TelegramUserId: &sharedv1.TelegramUserId{Value: l.SellerTelegramID},
+						TonAmount:      &sharedv1.TonAmount{Value: payout.String()},
+						Reason:         paymentv1.TransactionReason_TRANSACTION_REASON_REFUND,
+						Metadata:       metadata,
+						IdempotencyKey: revertKey,
+					},
+				)
+				return cErr
+			},
+			failure: giftDomain.CreateFailedCompensationParams{
+				GiftID:         g.ID,
+				TelegramUserID: l.SellerTelegramID,
+				Amount:         payout,
+				IdempotencyKey: revertKey,
+			},
+		})
+	}
+
+	// Шаг 3: передаём подарок и закрываем лот
+	soldListing, err := listingRepo.MarkListingSold(ctx, &listingDomain.MarkListingSoldParams{
+		ID:              l.ID,
+		BuyerTelegramID: buyerTelegramID,
+		Fee:             fee,
+	})
+	if err != nil {
+		commitErr = err
+		log.Error("failed to mark listing sold", zap.Error(err))
+		return nil, err
+	}
+
+	boughtGift, err := repo.TransferListedGift(ctx, g.ID, buyerTelegramID)
+	if err != nil {
+		commitErr = err
+		log.Error("failed to transfer listed gift", zap.Error(err))
+		return nil, err
+	}
+
+	_, err = repo.CreateGiftEvent(ctx, giftDomain.CreateGiftEventParams{
+		GiftID:         g.ID,
+		TelegramUserID: buyerTelegramID,
+		EventType:      giftDomain.EventTypeSale,
+	})
+	if err != nil {
+		commitErr = err
+		log.Error("failed to create gift event", zap.Error(err))
+		return nil, err
+	}
+
+	commitErr = tx.Commit(ctx)
+	if commitErr != nil {
+		log.Error("transaction commit failed", zap.Error(commitErr))
+		return nil, commitErr
+	}
+
+	return &BuyGiftResult{
+		Gift:    boughtGift,
+		Listing: soldListing,
+	}, nil
+}
+
+func (s *MarketplaceSaga) loadAndValidate(
+	ctx context.Context,
+	repo giftDomain.Repository,
+	listingRepo listingDomain.Repository,
+	buyerTelegramID int64,
+	listingID string,
+) (*listingDomain.Listing, *giftDomain.Gift, error) {
+	l, err := listingRepo.GetListingByIDForUpdate(ctx, listingID)
+	if err != nil {
+		s.log.Error("failed to get listing", zap.String("listingID", listingID), zap.Error(err))
+		if pg.IsNotFound(err) {
+			return nil, nil, listingDomain.ErrListingNotFound
+		}
+		return nil, nil, err
+	}
+
+	if err = l.CanBeBoughtBy(buyerTelegramID); err != nil {
+		return nil, nil, err
+	}
+
+	g, err := repo.GetGiftByID(ctx, l.GiftID)
+	if err != nil {
+		s.log.Error("failed to get listed gift", zap.String("giftID", l.GiftID), zap.Error(err))
+		return nil, nil, err
+	}
+
+	// Используем domain метод для валидации, сам подарок обновим через репозиторий
+	if err = g.Sell(buyerTelegramID); err != nil {
+		return nil, nil, err
+	}
+
+	return l, g, nil
+}
+
+func (s *MarketplaceSaga) creditSeller(
+	ctx context.Context,
+	sellerTelegramID int64,
+	payout *tonamount.TonAmount,
+	metadata *paymentv1.TransactionMetadata,
+	idempotencyKey string,
+) error {
+	if payout.IsZero() {
+		return nil
+	}
+	_, err := s.paymentPrivateClient.AddUserBalance(ctx, &paymentv1.AddUserBalanceRequest{
+		TelegramUserId: &sharedv1.TelegramUserId{Value: sellerTelegramID},
+		TonAmount:      &sharedv1.TonAmount{Value: payout.String()},
+		Reason:         paymentv1.TransactionReason_TRANSACTION_REASON_SALE,
+		Metadata:       metadata,
+		IdempotencyKey: idempotencyKey,
+	})
+	return err
+}
@@ -0,0 +1,305 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/adapter/pg"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/config"
+	giftDomain "github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/gift"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/pricing"
+	sellbackDomain "github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/sellback"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/service/query"
+	"github.com/peterparker2005/giftduels/packages/grpc-go/clients"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+	paymentv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/payment/v1"
+	sharedv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/shared/v1"
+	"github.com/peterparker2005/giftduels/packages/tonamount-go"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// sagaSellBack — имя саги в failed_saga_compensations.
+const sagaSellBack = "sell-back"
+
+type SellBackSaga struct {
+	txMgr                pg.TxManager
+	repo                 giftDomain.Repository
+	sellBackRepo         sellbackDomain.Repository
+	giftReadService      *query.GiftReadService
+	priceService         pricing.PriceService
+	log                  *logger.Logger
+	paymentPrivateClient paymentv1.PaymentPrivateServiceClient
+	cfg                  config.SellBackConfig
+	payoutPercent        decimal.Decimal
+}
+
+func NewSellBackSaga(
+	repo giftDomain.Repository,
+	sellBackRepo sellbackDomain.Repository,
+	giftReadService *query.GiftReadService,
+	priceService pricing.PriceService,
+	txMgr pg.TxManager,
+	log *logger.Logger,
+	clients *clients.Clients,
+	cfg *config.Config,
+) (*SellBackSaga, error) {
+	payoutPercent, err := decimal.NewFromString(cfg.SellBack.PayoutPercent)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sell-back payout percent %q: %w", cfg.SellBack.PayoutPercent, err)
+	}
+	// без аккаунта площадки выкупленные подарки ушли бы владельцу 0
+	if cfg.SellBack.HouseTelegramID == 0 {
+		return nil, errors.New("sell-back house telegram id is not set")
+	}
+	return &SellBackSaga{
+		repo:                 repo,
+		sellBackRepo:         sellBackRepo,
+		giftReadService:      giftReadService,
+		priceService:         priceService,
+		txMgr:                txMgr,
+		log:                  log,
+		paymentPrivateClient: clients.Payment.Private,
+		cfg:                  cfg.SellBack,
+		payoutPercent:        payoutPercent,
+	}, nil
+}
+
+// QuoteSellBack рассчитывает предложение площадки по актуальной floor-цене
+// и сохраняет его на короткое время.
+func (s *SellBackSaga) QuoteSellBack(
+	ctx context.Context,
+	telegramUserID int64,
+	giftID string,
+) (*sellbackDomain.Quote, error) {
+	log := s.log.With(
+		zap.String("giftID", giftID),
+		zap.Int64("telegramUserID", telegramUserID),
+	)
+
+	// Нужны названия атрибутов для поиска floor-цены
+	g, err := s.giftReadService.GetGiftByID(ctx, giftID)
+	if err != nil {
+		log.Error("failed to get gift for sell-back quote", zap.Error(err))
+		if pg.IsNotFound(err) {
+			return nil, giftDomain.ErrGiftNotFound
+		}
+		return nil, err
+	}
+
+	if !g.IsOwnedBy(telegramUserID) {
+		return nil, giftDomain.ErrGiftNotOwned
+	}
+	if g.Status != giftDomain.StatusOwned {
+		return nil, giftDomain.ErrGiftCannotBeSoldBack
+	}
+
+	priceResult, err := s.priceService.GetFloorPrice(ctx, &pricing.PriceServiceParams{
+		Collection: g.Title,
+		Model:      g.Model.Name,
+		Backdrop:   g.Backdrop.Name,
+		Symbol:     g.Symbol.Name,
+	})
+	if err != nil {
+		log.Error("failed to get floor price", zap.Error(err))
+		return nil, err
+	}
+
+	floorPrice, err := tonamount.NewTonAmountFromString(priceResult.FloorPrice)
+	if err != nil {
+		log.Error("failed to parse floor price", zap.Error(err))
+		return nil, err
+	}
+
+	amount, err := sellbackDomain.CalculateAmount(floorPrice, s.payoutPercent)
+	if err != nil {
+		log.Error("failed to calculate sell-back amount", zap.Error(err))
+		return nil, err
+	}
+
+	quote, err := s.sellBackRepo.CreateQuote(ctx, &sellbackDomain.CreateQuoteParams{
+		GiftID:         g.ID,
+		TelegramUserID: telegramUserID,
+		FloorPrice:     floorPrice,
+		Amount:         amount,
+		ExpiresAt:      time.Now().Add(s.cfg.QuoteTTL),
+	})
+	if err != nil {
+		log.Error("failed to create sell-back quote", zap.Error(err))
+		return nil, err
+	}
+
+	return quote, nil
+}
+
+type ExecuteSellBackResult struct {
+	Gift  *giftDomain.Gift
+	Quote *sellbackDomain.Quote
+}
+
+// ExecuteSellBack передаёт подарок на аккаунт площадки и начисляет
+// пользователю сумму котировки.
+//
+// Шаги саги:
+//  1. передача подарка и закрытие котировки в транзакции БД;
+//  2. начисление суммы на баланс пользователя;
+//  3. коммит транзакции.
+//
+// Если коммит падает после начисления, начисление компенсируется.
+func (s *SellBackSaga) ExecuteSellBack(
+	ctx context.Context,
+	telegramUserID int64,
+	quoteID string,
+) (*ExecuteSellBackResult, error) {
+	log := s.log.With(
+		zap.String("quoteID", quoteID),
+		zap.Int64("telegramUserID", telegramUserID),
+	)
+
+	tx, err := s.txMgr.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var commitErr error
+	var compensations []compensation
+	defer func() {
+		if commitErr != nil {
+			if rbErr := tx.Rollback(ctx); rbErr != nil {
+				log.Error("rollback failed", zap.Error(rbErr))
+			}
+			compensate(ctx, log, s.repo, sagaSellBack, compensations)
+		}
+	}()
+
+	repo := s.repo.WithTx(tx)
+	sellBackRepo := s.sellBackRepo.WithTx(tx)
+
+	quote, g, err := s.loadAndValidate(ctx, repo, sellBackRepo, telegramUserID, quoteID)
+	if err != nil {
+		commitErr = err
+		return nil, err
+	}
+
+	// Шаг 1: передаём подарок площадке и закрываем котировку
+	soldGift, err := repo.TransferOwnedGift(ctx, g.ID, telegramUserID, s.cfg.HouseTelegramID)
+	if err != nil {
+		commitErr = err
+		log.Error("failed to transfer gift to house", zap.Error(err))
+		return nil, err
+	}
+
+	executedQuote, err := sellBackRepo.MarkQuoteExecuted(ctx, quote.ID)
+	if err != nil {
+		commitErr = err
+		log.Error("failed to mark quote executed", zap.Error(err))
+		return nil, err
+	}
+
+	_, err = repo.CreateGiftEvent(ctx, giftDomain.CreateGiftEventParams{
+		GiftID:         g.ID,
+		TelegramUserID: telegramUserID,
+		EventType:      giftDomain.EventTypeSellBack,
+	})
+	if err != nil {
+		commitErr = err
+		log.Error("failed to create gift event", zap.Error(err))
+		return nil, err
+	}
+
+	metadata := &paymentv1.TransactionMetadata{
+		Data: &paymentv1.TransactionMetadata_Gift{
+			Gift: &paymentv1.TransactionMetadata_GiftDetails{
+				GiftId: g.ID,
+				Title:  g.Title,
+				Slug:   g.Slug,
+			},
+		},
+	}
+
+	// Шаг 2: начисляем пользователю сумму котировки. Котировка исполняется
+	// один раз, поэтому её ID различает попытки
+	_, err = s.paymentPrivateClient.AddUserBalance(ctx, &paymentv1.AddUserBalanceRequest{
+		TelegramUserId: &sharedv1.TelegramUserId{Value: telegramUserID},
+		TonAmount:      &sharedv1.TonAmount{Value: quote.Amount.String()},
+		Reason:         paymentv1.TransactionReason_TRANSACTION_REASON_SELL_BACK,
+		Metadata:       metadata,
+		IdempotencyKey: attemptIdempotencyKey(opSellBack, g, quote.ID),
+	})
+	if err != nil {
+		commitErr = err
+		log.Error("failed to credit sell-back amount", zap.Error(err))
+		return nil, err
+	}
+	revertKey := attemptIdempotencyKey(opSellBackRevert, g, quote.ID)
+	compensations = append(compensations, compensation{
+		name: "revert sell-back payout",
+		fn: func(ctx context.Context) error {
+			_, cErr := s.paymentPrivateClient.SpendUserBalance(
+				ctx,
+				&paymentv1.SpendUserBalanceRequest{
+					TelegramUserId: &sharedv1.TelegramUserId{Value: telegramUserID},
+					TonAmount:      &sharedv1.TonAmount{Value: quote.Amount.String()},
+					Reason:         paymentv1.TransactionReason_TRANSACTION_REASON_REFUND,
+					Metadata:       metadata,
+					IdempotencyKey: revertKey,
+				},
+			)
+			return cErr
+		},
+		failure: giftDomain.CreateFailedCompensationParams{
+			GiftID:         g.ID,
+			TelegramUserID: telegramUserID,
+			Amount:         quote.Amount,
+			IdempotencyKey: revertKey,
+		},
+	})
+
+	// Шаг 3: коммитим
+	commitErr = tx.Commit(ctx)
+	if commitErr != nil {
+		log.Error("transaction commit failed", zap.Error(commitErr))
+		return nil, commitErr
+	}
+
+	return &ExecuteSellBackResult{
+		Gift:  soldGift,
+		Quote: executedQuote,
+	}, nil
+}
+
+func (s *SellBackSaga) loadAndValidate(
+	ctx context.Context,
+	repo giftDomain.Repository,
+	sellBackRepo sellbackDomain.Repository,
+	telegramUserID int64,
+	quoteID string,
+) (*sellbackDomain.Quote, *giftDomain.Gift, error) {
+	quote, err := sellBackRepo.GetQuoteByIDForUpdate(ctx, quoteID)
+	if err != nil {
+		s.log.Error("failed to get sell-back quote", zap.String("quoteID", quoteID), zap.Error(err))
+		if pg.IsNotFound(err) {
+			return nil, nil, sellbackDomain.ErrQuoteNotFound
+		}
+		return nil, nil, err
+	}
+
+	if err = quote.CanBeExecutedBy(telegramUserID, time.Now()); err != nil {
+		return nil, nil, err
+	}
+
+	g, err := repo.GetGiftByID(ctx, quote.GiftID)
+	if err != nil {
+		s.log.Error("failed to get gift", zap.String("giftID", quote.GiftID), zap.Error(err))
+		return nil, nil, err
+	}
+
+	// Используем domain метод для валидации, сам подарок обновим через репозиторий
+	if err = g.SellBack(telegramUserID, s.cfg.HouseTelegramID); err != nil {
+		return nil, nil, err
+	}
+
+	return quote, g, nil
+}
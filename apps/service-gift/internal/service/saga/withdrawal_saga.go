@@ -3,14 +3,20 @@ package saga
 import (
 	"context"
 	"encoding/base64"
+	"strconv"
 	"time"
 
 	"github.com/ThreeDotsLabs/watermill"
 	"github.com/ThreeDotsLabs/watermill-sql/v3/pkg/sql"
 	"github.com/ThreeDotsLabs/watermill/components/forwarder"
 	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/ccoveille/go-safecast"
+	"github.com/jackc/pgx/v5"
 	"github.com/peterparker2005/giftduels/apps/service-gift/internal/adapter/pg"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/config"
 	giftDomain "github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/gift"
+	withdrawalDomain "github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/withdrawal"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/service/command"
 	giftEvents "github.com/peterparker2005/giftduels/packages/events/gift"
 	"github.com/peterparker2005/giftduels/packages/grpc-go/clients"
 	"github.com/peterparker2005/giftduels/packages/logger-go"
@@ -18,6 +24,7 @@ import (
 	paymentv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/payment/v1"
 	sharedv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/shared/v1"
 	telegrambotv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/telegrambot/v1"
+	"github.com/peterparker2005/giftduels/packages/tonamount-go"
 	"go.uber.org/zap"
 	"google.golang.org/protobuf/proto"
 )
@@ -25,29 +32,40 @@ import (
 type WithdrawalSaga struct {
 	txMgr                    pg.TxManager
 	repo                     giftDomain.Repository
+	withdrawalRepo           withdrawalDomain.Repository
+	withdrawalTracker        *command.WithdrawalTracker
 	log                      *logger.Logger
 	paymentPrivateClient     paymentv1.PaymentPrivateServiceClient
 	telegramBotPrivateClient telegrambotv1.TelegramBotPrivateServiceClient
+	cfg                      config.WithdrawalConfig
 }
 
 func NewWithdrawalSaga(
 	repo giftDomain.Repository,
+	withdrawalRepo withdrawalDomain.Repository,
+	withdrawalTracker *command.WithdrawalTracker,
 	txMgr pg.TxManager,
 	log *logger.Logger,
 	clients *clients.Clients,
+	cfg *config.Config,
 ) *WithdrawalSaga {
 	return &WithdrawalSaga{
 		repo:                     repo,
+		withdrawalRepo:           withdrawalRepo,
+		withdrawalTracker:        withdrawalTracker,
 		txMgr:                    txMgr,
 		log:                      log,
 		paymentPrivateClient:     clients.Payment.Private,
 		telegramBotPrivateClient: clients.TelegramBot.Private,
+		cfg:                      cfg.Withdrawal,
 	}
 }
 
 type ExecuteWithdrawResult struct {
 	Gifts             []*giftDomain.Gift
+	WithdrawalID      string
 	StarsInvoiceURL   string // только для Stars валюты
+	IntentID          string // только для Stars валюты
 	IsStarsCommission bool   // указывает, что это Stars комиссия
 }
 
@@ -56,12 +74,13 @@ func (s *WithdrawalSaga) ExecuteWithdraw(
 	telegramUserID int64,
 	giftIDs []string,
 	commissionCurrency giftv1.ExecuteWithdrawRequest_CommissionCurrency,
+	feeScheduleVersion int64,
 ) (*ExecuteWithdrawResult, error) {
 	switch commissionCurrency {
 	case giftv1.ExecuteWithdrawRequest_COMMISSION_CURRENCY_TON:
-		return s.executeWithdrawTON(ctx, telegramUserID, giftIDs)
+		return s.executeWithdrawTON(ctx, telegramUserID, giftIDs, feeScheduleVersion)
 	case giftv1.ExecuteWithdrawRequest_COMMISSION_CURRENCY_STARS:
-		return s.executeWithdrawStars(ctx, telegramUserID, giftIDs)
+		return s.executeWithdrawStars(ctx, telegramUserID, giftIDs, feeScheduleVersion)
 	case giftv1.ExecuteWithdrawRequest_COMMISSION_CURRENCY_UNSPECIFIED:
 		return nil, giftDomain.ErrInvalidCommissionCurrency
 	default:
@@ -73,6 +92,7 @@ func (s *WithdrawalSaga) executeWithdrawTON(
 	ctx context.Context,
 	telegramUserID int64,
 	giftIDs []string,
+	feeScheduleVersion int64,
 ) (*ExecuteWithdrawResult, error) {
 	// Начинаем транзакцию, чтобы сохранить пометки и публиковать события
 	tx, err := s.txMgr.BeginTx(ctx)
@@ -111,7 +131,39 @@ func (s *WithdrawalSaga) executeWithdrawTON(
 		return nil, err
 	}
 
-	result, eventsToPublish, err := s.processGiftsForWithdrawal(ctx, repo, gifts, telegramUserID)
+	result, withdrawalGifts, eventsToPublish, err := s.processGiftsForWithdrawal(
+		ctx,
+		repo,
+		gifts,
+		telegramUserID,
+		feeScheduleVersion,
+	)
+	if err != nil {
+		commitErr = err
+		return nil, err
+	}
+
+	// комиссия уже списана — вывод сразу уходит боту
+	totalCommission := tonamount.Zero()
+	for _, g := range withdrawalGifts {
+		commission, pErr := tonamount.NewTonAmountFromString(g.CommissionAmount)
+		if pErr != nil {
+			commitErr = pErr
+			return nil, pErr
+		}
+		totalCommission = totalCommission.Add(commission)
+	}
+	w := withdrawalDomain.NewWithdrawal(
+		telegramUserID,
+		withdrawalDomain.CommissionCurrencyTON,
+		totalCommission.String(),
+		withdrawalGifts,
+	)
+	if err = w.SendToBot(); err != nil {
+		commitErr = err
+		return nil, err
+	}
+	w, err = s.withdrawalTracker.WithTx(tx).Create(ctx, w)
 	if err != nil {
 		commitErr = err
 		return nil, err
@@ -132,6 +184,7 @@ func (s *WithdrawalSaga) executeWithdrawTON(
 
 	return &ExecuteWithdrawResult{
 		Gifts:             result,
+		WithdrawalID:      w.ID,
 		IsStarsCommission: false,
 	}, nil
 }
@@ -174,34 +227,26 @@ func (s *WithdrawalSaga) processGiftsForWithdrawal(
 	repo giftDomain.Repository,
 	gifts []*giftDomain.Gift,
 	telegramUserID int64,
-) ([]*giftDomain.Gift, []*message.Message, error) {
+	feeScheduleVersion int64,
+) ([]*giftDomain.Gift, []*withdrawalDomain.Gift, []*message.Message, error) {
 	var result []*giftDomain.Gift
+	var withdrawalGifts []*withdrawalDomain.Gift
 	var eventsToPublish []*message.Message
 
 	for _, gift := range gifts {
 		// Используем domain метод для валидации
 		if err := gift.MarkForWithdrawal(); err != nil {
 			s.log.Error("failed to mark gift for withdrawal in domain", zap.Error(err))
-			return nil, nil, err
+			return nil, nil, nil, err
 		}
 
-		previewResp, err := s.paymentPrivateClient.PreviewWithdraw(
-			ctx,
-			&paymentv1.PreviewWithdrawRequest{
-				Gifts: []*paymentv1.GiftWithdrawRequest{
-					{
-						GiftId: &sharedv1.GiftId{Value: gift.ID},
-						Price:  &sharedv1.TonAmount{Value: gift.Price.String()},
-					},
-				},
-			},
-		)
+		previewResp, err := s.previewGiftFee(ctx, repo, gift, feeScheduleVersion)
 		if err != nil {
-			s.log.Error("failed to preview withdraw", zap.Error(err))
-			return nil, nil, err
+			return nil, nil, nil, err
 		}
 
-		// списываем комиссию и получаем её величину
+		// списываем комиссию и получаем её величину; ключ защищает от
+		// двойного списания при повторе после таймаута
 		_, err = s.paymentPrivateClient.SpendUserBalance(ctx, &paymentv1.SpendUserBalanceRequest{
 			TelegramUserId: &sharedv1.TelegramUserId{Value: telegramUserID},
 			TonAmount:      &sharedv1.TonAmount{Value: previewResp.GetTotalTonFee().GetValue()},
@@ -209,25 +254,31 @@ func (s *WithdrawalSaga) processGiftsForWithdrawal(
 			Metadata: &paymentv1.TransactionMetadata{
 				Data: &paymentv1.TransactionMetadata_Gift{
 					Gift: &paymentv1.TransactionMetadata_GiftDetails{
-						GiftId: gift.ID,
-						Title:  gift.Title,
-						Slug:   gift.Slug,
+						GiftId:               gift.ID,
+						Title:                gift.Title,
+						Slug:                 gift.Slug,
+						WithdrawalCommission: true,
 					},
 				},
 			},
+			IdempotencyKey: giftIdempotencyKey(opWithdrawalCommission, gift),
 		})
 		if err != nil {
 			s.log.Error("failed to spend withdrawal commission", zap.Error(err))
-			return nil, nil, err
+			return nil, nil, nil, err
 		}
 
 		// помечаем подарок на вывод в репозитории
 		markedGift, err := repo.MarkGiftForWithdrawal(ctx, gift.ID)
 		if err != nil {
 			s.log.Error("failed to mark gift for withdrawal", zap.Error(err))
-			return nil, nil, err
+			return nil, nil, nil, err
 		}
 		result = append(result, markedGift)
+		withdrawalGifts = append(withdrawalGifts, &withdrawalDomain.Gift{
+			GiftID:           markedGift.ID,
+			CommissionAmount: previewResp.GetTotalTonFee().GetValue(),
+		})
 
 		// готовим событие для публикации после коммита
 		ev := &giftv1.GiftWithdrawRequestedEvent{
@@ -244,13 +295,13 @@ func (s *WithdrawalSaga) processGiftsForWithdrawal(
 		payload, err := proto.Marshal(ev)
 		if err != nil {
 			s.log.Error("marshal event failed", zap.Error(err))
-			return nil, nil, err
+			return nil, nil, nil, err
 		}
 		msg := message.NewMessage(watermill.NewUUID(), payload)
 		eventsToPublish = append(eventsToPublish, msg)
 	}
 
-	return result, eventsToPublish, nil
+	return result, withdrawalGifts, eventsToPublish, nil
 }
 
 func (s *WithdrawalSaga) publishEvents(
@@ -266,30 +317,67 @@ func (s *WithdrawalSaga) publishEvents(
 	return nil
 }
 
+// previewGiftFee запрашивает у платёжного сервиса комиссию за вывод подарка.
+// Если передана версия расписания из предпросмотра, а комиссия посчитана по
+// другой, вывод отклоняется: пользователь соглашался на другую сумму.
+func (s *WithdrawalSaga) previewGiftFee(
+	ctx context.Context,
+	repo giftDomain.Repository,
+	g *giftDomain.Gift,
+	feeScheduleVersion int64,
+) (*paymentv1.PreviewWithdrawResponse, error) {
+	collection, err := repo.GetGiftCollection(ctx, g.Collection.ID)
+	if err != nil {
+		s.log.Error("failed to get gift collection",
+			zap.String("giftID", g.ID),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	preview, err := s.paymentPrivateClient.PreviewWithdraw(
+		ctx,
+		&paymentv1.PreviewWithdrawRequest{
+			Gifts: []*paymentv1.GiftWithdrawRequest{
+				{
+					GiftId:     &sharedv1.GiftId{Value: g.ID},
+					Price:      &sharedv1.TonAmount{Value: g.Price.String()},
+					Collection: collection.Name,
+				},
+			},
+		},
+	)
+	if err != nil {
+		s.log.Error("failed to preview withdraw",
+			zap.Error(err),
+			zap.String("giftID", g.ID),
+		)
+		return nil, err
+	}
+
+	if feeScheduleVersion != 0 && preview.GetFeeScheduleVersion() != feeScheduleVersion {
+		s.log.Warn("fee schedule changed since preview",
+			zap.String("giftID", g.ID),
+			zap.Int64("expectedVersion", feeScheduleVersion),
+			zap.Int64("currentVersion", preview.GetFeeScheduleVersion()),
+		)
+		return nil, giftDomain.ErrFeeScheduleChanged
+	}
+	return preview, nil
+}
+
 func (s *WithdrawalSaga) calculateStarsCommissions(
 	ctx context.Context,
+	repo giftDomain.Repository,
 	gifts []*giftDomain.Gift,
+	feeScheduleVersion int64,
 ) ([]*telegrambotv1.GiftCommission, uint32, error) {
 	var totalStars uint32
 	commissions := make([]*telegrambotv1.GiftCommission, len(gifts))
 
 	for i, g := range gifts {
-		preview, err := s.paymentPrivateClient.PreviewWithdraw(
-			ctx,
-			&paymentv1.PreviewWithdrawRequest{
-				Gifts: []*paymentv1.GiftWithdrawRequest{
-					{
-						GiftId: &sharedv1.GiftId{Value: g.ID},
-						Price:  &sharedv1.TonAmount{Value: g.Price.String()},
-					},
-				},
-			},
-		)
+		preview, err := s.previewGiftFee(ctx, repo, g, feeScheduleVersion)
 		if err != nil {
-			s.log.Error("failed to preview withdraw",
-				zap.Error(err),
-				zap.String("giftID", g.ID),
-			)
 			return nil, 0, err
 		}
 		stars := preview.GetTotalStarsFee().GetValue()
@@ -348,6 +436,7 @@ func (s *WithdrawalSaga) executeWithdrawStars(
 	ctx context.Context,
 	telegramUserID int64,
 	giftIDs []string,
+	feeScheduleVersion int64,
 ) (*ExecuteWithdrawResult, error) {
 	// Начинаем транзакцию для блокировки подарков
 	tx, err := s.txMgr.BeginTx(ctx)
@@ -362,6 +451,19 @@ func (s *WithdrawalSaga) executeWithdrawStars(
 	}()
 
 	repo := s.repo.WithTx(tx)
+	withdrawalRepo := s.withdrawalRepo.WithTx(tx)
+
+	// Не даём выставить второй инвойс на те же подарки
+	hasPending, err := withdrawalRepo.HasPendingIntentForGifts(ctx, giftIDs)
+	if err != nil {
+		commitErr = err
+		s.log.Error("failed to check pending withdrawal intents", zap.Error(err))
+		return nil, err
+	}
+	if hasPending {
+		commitErr = withdrawalDomain.ErrIntentAlreadyExists
+		return nil, withdrawalDomain.ErrIntentAlreadyExists
+	}
 
 	// Получаем и валидируем подарки
 	gifts, err := s.getAndValidateGiftsForStarsWithdrawal(ctx, repo, giftIDs, telegramUserID)
@@ -371,12 +473,26 @@ func (s *WithdrawalSaga) executeWithdrawStars(
 	}
 
 	// Рассчитываем комиссии и создаем инвойс
-	commissions, totalStars, err := s.calculateStarsCommissions(ctx, gifts)
+	commissions, totalStars, err := s.calculateStarsCommissions(ctx, repo, gifts, feeScheduleVersion)
 	if err != nil {
 		commitErr = err
 		return nil, err
 	}
 
+	// Удерживаем подарки на время жизни инвойса
+	held := make([]*giftDomain.Gift, len(gifts))
+	for i, g := range gifts {
+		held[i], err = repo.MarkGiftForWithdrawal(ctx, g.ID)
+		if err != nil {
+			commitErr = err
+			s.log.Error("failed to hold gift for stars withdrawal",
+				zap.String("giftID", g.ID),
+				zap.Error(err),
+			)
+			return nil, err
+		}
+	}
+
 	// Создаем инвойс
 	invoiceURL, err := s.createStarsInvoice(ctx, telegramUserID, totalStars, commissions)
 	if err != nil {
@@ -384,7 +500,43 @@ func (s *WithdrawalSaga) executeWithdrawStars(
 		return nil, err
 	}
 
-	// Коммитим транзакцию — подарки заблокированы до завершения оплаты
+	intent, err := withdrawalRepo.CreateIntent(ctx, &withdrawalDomain.CreateIntentParams{
+		TelegramUserID: telegramUserID,
+		GiftIDs:        giftIDs,
+		StarsAmount:    int64(totalStars),
+		InvoiceURL:     invoiceURL,
+		ExpiresAt:      time.Now().Add(s.cfg.StarsInvoiceTTL),
+	})
+	if err != nil {
+		commitErr = err
+		s.log.Error("failed to create withdrawal intent", zap.Error(err))
+		return nil, err
+	}
+
+	withdrawalGifts := make([]*withdrawalDomain.Gift, len(commissions))
+	for i, c := range commissions {
+		withdrawalGifts[i] = &withdrawalDomain.Gift{
+			GiftID:           c.GetGiftId().GetValue(),
+			CommissionAmount: strconv.FormatUint(uint64(c.GetStars().GetValue()), 10),
+		}
+	}
+	w := withdrawalDomain.NewWithdrawal(
+		telegramUserID,
+		withdrawalDomain.CommissionCurrencyStars,
+		strconv.FormatUint(uint64(totalStars), 10),
+		withdrawalGifts,
+	)
+	if err = w.AwaitPayment(intent.ID); err != nil {
+		commitErr = err
+		return nil, err
+	}
+	w, err = s.withdrawalTracker.WithTx(tx).Create(ctx, w)
+	if err != nil {
+		commitErr = err
+		return nil, err
+	}
+
+	// Коммитим транзакцию — подарки заблокированы до оплаты или истечения инвойса
 	commitErr = tx.Commit(ctx)
 	if commitErr != nil {
 		s.log.Error("transaction commit failed", zap.Error(commitErr))
@@ -392,8 +544,10 @@ func (s *WithdrawalSaga) executeWithdrawStars(
 	}
 
 	return &ExecuteWithdrawResult{
-		Gifts:             gifts,
+		Gifts:             held,
+		WithdrawalID:      w.ID,
 		StarsInvoiceURL:   invoiceURL,
+		IntentID:          intent.ID,
 		IsStarsCommission: true,
 	}, nil
 }
@@ -426,8 +580,13 @@ func (s *WithdrawalSaga) getAndValidateGiftsForStarsWithdrawal(
 	return gifts, nil
 }
 
+// CompleteStarsWithdrawal отправляет на вывод подарки из оплаченного инвойса.
+// Если инвойс успел истечь, подарки удерживаются заново — оплата уже получена.
+// Если же подарки за это время заняли (ставка, лот, продажа), вывод не
+// выполняется: оплата помечается к возврату, а пользователь получает уведомление.
 func (s *WithdrawalSaga) CompleteStarsWithdrawal(
 	ctx context.Context,
+	telegramUserID int64,
 	giftIDs []string,
 ) ([]*giftDomain.Gift, error) {
 	tx, err := s.txMgr.BeginTx(ctx)
@@ -452,16 +611,34 @@ func (s *WithdrawalSaga) CompleteStarsWithdrawal(
 		return nil, err
 	}
 
-	result, eventsToPublish, err := s.processGiftsForStarsWithdrawal(ctx, repo, giftIDs)
+	payment, err := s.completeStarsIntent(ctx, tx, repo, telegramUserID, giftIDs)
 	if err != nil {
 		commitErr = err
 		return nil, err
 	}
 
-	// публикуем все события
-	if err = s.publishEvents(fwdPub, eventsToPublish); err != nil {
-		commitErr = err
-		return nil, err
+	var result []*giftDomain.Gift
+	switch {
+	case payment.refundIntent != nil:
+		if err = s.publishPaidAfterExpiry(fwdPub, payment.refundIntent); err != nil {
+			commitErr = err
+			return nil, err
+		}
+	case payment.settled:
+		// оплата истёкшего инвойса уже помечена к возврату при прошлой доставке
+	default:
+		var eventsToPublish []*message.Message
+		result, eventsToPublish, err = s.processGiftsForStarsWithdrawal(ctx, repo, giftIDs, payment.held)
+		if err != nil {
+			commitErr = err
+			return nil, err
+		}
+
+		// публикуем все события
+		if err = s.publishEvents(fwdPub, eventsToPublish); err != nil {
+			commitErr = err
+			return nil, err
+		}
 	}
 
 	// коммитим всё
@@ -474,16 +651,171 @@ func (s *WithdrawalSaga) CompleteStarsWithdrawal(
 	return result, nil
 }
 
+// starsPayment — итог закрытия оплаченного Stars-инвойса.
+type starsPayment struct {
+	// held — подарки удерживаются с момента выставления инвойса.
+	held bool
+	// refundIntent — инвойс истёк, подарки заняты, и оплату нужно вернуть.
+	refundIntent *withdrawalDomain.Intent
+	// settled — оплата уже обработана, делать ничего не нужно.
+	settled bool
+}
+
+// completeStarsIntent закрывает инвойс, покрывающий подарки, и сообщает,
+// удерживаются ли они ещё с момента выставления. Оплату истёкшего инвойса,
+// подарки которого уже недоступны, помечает к возврату.
+func (s *WithdrawalSaga) completeStarsIntent(
+	ctx context.Context,
+	tx pgx.Tx,
+	repo giftDomain.Repository,
+	telegramUserID int64,
+	giftIDs []string,
+) (starsPayment, error) {
+	withdrawalRepo := s.withdrawalRepo.WithTx(tx)
+
+	intent, err := withdrawalRepo.GetLatestIntentByGiftsForUpdate(ctx, telegramUserID, giftIDs)
+	if err != nil {
+		if pg.IsNotFound(err) {
+			// инвойс выставлен до появления intents — подарки не удерживались
+			return starsPayment{}, nil
+		}
+		s.log.Error("failed to get withdrawal intent", zap.Error(err))
+		return starsPayment{}, err
+	}
+
+	switch intent.Status {
+	case withdrawalDomain.IntentStatusCompleted:
+		// повторная оплата уже закрытого инвойса — идём обычным путём
+		return starsPayment{}, nil
+	case withdrawalDomain.IntentStatusPaidAfterExpiry:
+		return starsPayment{settled: true}, nil
+	case withdrawalDomain.IntentStatusPending, withdrawalDomain.IntentStatusExpired:
+	default:
+	}
+
+	if intent.IsExpired() {
+		available, aErr := s.giftsAvailableForWithdrawal(ctx, repo, telegramUserID, giftIDs)
+		if aErr != nil {
+			return starsPayment{}, aErr
+		}
+		if !available {
+			refundIntent, rErr := s.requireStarsRefund(ctx, tx, intent)
+			if rErr != nil {
+				return starsPayment{}, rErr
+			}
+			return starsPayment{refundIntent: refundIntent}, nil
+		}
+	}
+
+	held := intent.IsPending()
+	if _, err = withdrawalRepo.MarkIntentCompleted(ctx, intent.ID); err != nil {
+		s.log.Error("failed to complete withdrawal intent",
+			zap.String("intentID", intent.ID),
+			zap.Error(err),
+		)
+		return starsPayment{}, err
+	}
+
+	if err = s.withdrawalTracker.WithTx(tx).SendToBotByIntent(ctx, intent.ID); err != nil {
+		return starsPayment{}, err
+	}
+
+	return starsPayment{held: held}, nil
+}
+
+// giftsAvailableForWithdrawal проверяет, что подарки истёкшего инвойса всё
+// ещё лежат в инвентаре пользователя и их можно удержать заново.
+func (s *WithdrawalSaga) giftsAvailableForWithdrawal(
+	ctx context.Context,
+	repo giftDomain.Repository,
+	telegramUserID int64,
+	giftIDs []string,
+) (bool, error) {
+	for _, giftID := range giftIDs {
+		g, err := repo.GetGiftByID(ctx, giftID)
+		if err != nil {
+			if pg.IsNotFound(err) {
+				return false, nil
+			}
+			s.log.Error("failed to get gift", zap.String("giftID", giftID), zap.Error(err))
+			return false, err
+		}
+		if !g.CanBeWithdrawnBy(telegramUserID) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// requireStarsRefund помечает истёкший инвойс и его вывод к возврату оплаты.
+func (s *WithdrawalSaga) requireStarsRefund(
+	ctx context.Context,
+	tx pgx.Tx,
+	intent *withdrawalDomain.Intent,
+) (*withdrawalDomain.Intent, error) {
+	log := s.log.With(zap.String("intentID", intent.ID))
+	log.Warn("stars invoice paid after expiry, gifts are no longer available")
+
+	refundIntent, err := s.withdrawalRepo.WithTx(tx).MarkIntentPaidAfterExpiry(ctx, intent.ID)
+	if err != nil {
+		log.Error("failed to mark withdrawal intent paid after expiry", zap.Error(err))
+		return nil, err
+	}
+
+	if err = s.withdrawalTracker.WithTx(tx).RequireRefundByIntent(ctx, intent.ID); err != nil {
+		return nil, err
+	}
+
+	return refundIntent, nil
+}
+
+// publishPaidAfterExpiry уведомляет пользователя, что оплата пришла после
+// истечения инвойса и будет возвращена.
+func (s *WithdrawalSaga) publishPaidAfterExpiry(
+	fwdPub *forwarder.Publisher,
+	intent *withdrawalDomain.Intent,
+) error {
+	stars, err := safecast.ToUint32(intent.StarsAmount)
+	if err != nil {
+		return err
+	}
+
+	giftIDs := make([]*sharedv1.GiftId, len(intent.GiftIDs))
+	for i, id := range intent.GiftIDs {
+		giftIDs[i] = &sharedv1.GiftId{Value: id}
+	}
+
+	ev := &giftv1.GiftWithdrawPaidAfterExpiryEvent{
+		WithdrawalIntentId: intent.ID,
+		OwnerTelegramId:    &sharedv1.TelegramUserId{Value: intent.TelegramUserID},
+		GiftIds:            giftIDs,
+		StarsAmount:        &sharedv1.StarsAmount{Value: stars},
+	}
+	payload, err := proto.Marshal(ev)
+	if err != nil {
+		s.log.Error("marshal event failed", zap.Error(err))
+		return err
+	}
+
+	msg := message.NewMessage(watermill.NewUUID(), payload)
+	if err = fwdPub.Publish(giftEvents.TopicGiftWithdrawPaidAfterExpiry.String(), msg); err != nil {
+		s.log.Error("publish event failed", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
 func (s *WithdrawalSaga) processGiftsForStarsWithdrawal(
 	ctx context.Context,
 	repo giftDomain.Repository,
 	giftIDs []string,
+	held bool,
 ) ([]*giftDomain.Gift, []*message.Message, error) {
 	var result []*giftDomain.Gift
 	var eventsToPublish []*message.Message
 
 	for _, giftID := range giftIDs {
-		gift, event, err := s.processSingleGiftForStarsWithdrawal(ctx, repo, giftID)
+		gift, event, err := s.processSingleGiftForStarsWithdrawal(ctx, repo, giftID, held)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -498,11 +830,20 @@ func (s *WithdrawalSaga) processSingleGiftForStarsWithdrawal(
 	ctx context.Context,
 	repo giftDomain.Repository,
 	giftID string,
+	held bool,
 ) (*giftDomain.Gift, *message.Message, error) {
-	g, markErr := repo.MarkGiftForWithdrawal(ctx, giftID)
-	if markErr != nil {
-		s.log.Error("failed to mark gift for withdrawal", zap.Error(markErr))
-		return nil, nil, markErr
+	var (
+		g      *giftDomain.Gift
+		getErr error
+	)
+	if held {
+		g, getErr = repo.GetGiftByID(ctx, giftID)
+	} else {
+		g, getErr = repo.MarkGiftForWithdrawal(ctx, giftID)
+	}
+	if getErr != nil {
+		s.log.Error("failed to mark gift for withdrawal", zap.Error(getErr))
+		return nil, nil, getErr
 	}
 
 	// Используем domain метод для валидации
@@ -514,28 +855,9 @@ func (s *WithdrawalSaga) processSingleGiftForStarsWithdrawal(
 		return nil, nil, err
 	}
 
-	// Получаем правильную TON комиссию через PreviewWithdraw
-	previewResp, previewErr := s.paymentPrivateClient.PreviewWithdraw(
-		ctx,
-		&paymentv1.PreviewWithdrawRequest{
-			Gifts: []*paymentv1.GiftWithdrawRequest{
-				{
-					GiftId: &sharedv1.GiftId{Value: g.ID},
-					Price:  &sharedv1.TonAmount{Value: g.Price.String()},
-				},
-			},
-		},
-	)
-	if previewErr != nil {
-		s.log.Error(
-			"failed to preview withdraw for commission calculation",
-			zap.Error(previewErr),
-		)
-		return nil, nil, previewErr
-	}
-
-	// готовим событие для публикации
-	event, err := s.createWithdrawEvent(g, previewResp.GetTotalTonFee().GetValue())
+	// комиссия оплачена в Stars, поэтому TON-комиссия в событии нулевая —
+	// иначе откат вывода вернул бы на TON-баланс то, что пользователь не платил
+	event, err := s.createWithdrawEvent(g, tonamount.Zero().String())
 	if err != nil {
 		return nil, nil, err
 	}
@@ -568,9 +890,233 @@ func (s *WithdrawalSaga) createWithdrawEvent(
 	return message.NewMessage(watermill.NewUUID(), payload), nil
 }
 
+// CancelGiftWithdrawal возвращает подарок в инвентарь после неудачной
+// передачи ботом и отмечает вывод неудавшимся.
 func (s *WithdrawalSaga) CancelGiftWithdrawal(
 	ctx context.Context,
 	giftID string,
+	reason string,
 ) (*giftDomain.Gift, error) {
-	return s.repo.CancelGiftWithdrawal(ctx, giftID)
+	tx, err := s.txMgr.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var commitErr error
+	defer func() {
+		if commitErr != nil {
+			if rbErr := tx.Rollback(ctx); rbErr != nil {
+				s.log.Error("rollback failed", zap.Error(rbErr))
+			}
+		}
+	}()
+
+	gift, err := s.repo.WithTx(tx).CancelGiftWithdrawal(ctx, giftID)
+	if err != nil {
+		commitErr = err
+		return nil, err
+	}
+
+	if err = s.withdrawalTracker.WithTx(tx).FailGift(ctx, giftID, reason); err != nil {
+		commitErr = err
+		return nil, err
+	}
+
+	commitErr = tx.Commit(ctx)
+	if commitErr != nil {
+		s.log.Error("transaction commit failed", zap.Error(commitErr))
+		return nil, commitErr
+	}
+
+	return gift, nil
+}
+
+// RevertUndeliveredWithdrawal откатывает вывод, который бот не смог доставить
+// (пользователь не начинал диалог), и возвращает TON-комиссию через refund.
+// Комиссия, оплаченная в Stars, на TON-баланс не возвращается.
+// Статус откатывается в той же транзакции, что и возврат, — при ошибке
+// refund'а подарок остаётся в withdraw_pending до повторной доставки события.
+func (s *WithdrawalSaga) RevertUndeliveredWithdrawal(
+	ctx context.Context,
+	giftID string,
+	commissionAmount string,
+) (*giftDomain.Gift, error) {
+	log := s.log.With(zap.String("giftID", giftID), zap.String("commission", commissionAmount))
+
+	tx, err := s.txMgr.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var commitErr error
+	defer func() {
+		if commitErr != nil {
+			if rbErr := tx.Rollback(ctx); rbErr != nil {
+				log.Error("rollback failed", zap.Error(rbErr))
+			}
+		}
+	}()
+
+	repo := s.repo.WithTx(tx)
+
+	// ключ возврата строится по версии подарка до отмены, которая не
+	// меняется между повторными доставками события
+	pending, err := repo.GetGiftByID(ctx, giftID)
+	if err != nil {
+		commitErr = err
+		log.Error("failed to get gift", zap.Error(err))
+		return nil, err
+	}
+
+	gift, err := repo.CancelGiftWithdrawal(ctx, giftID)
+	if err != nil {
+		commitErr = err
+		log.Error("failed to cancel gift withdrawal", zap.Error(err))
+		return nil, err
+	}
+
+	tracker := s.withdrawalTracker.WithTx(tx)
+	currency, tracked, err := tracker.GiftCommissionCurrency(ctx, giftID)
+	if err != nil {
+		commitErr = err
+		return nil, err
+	}
+	paidInTON := !tracked || currency == withdrawalDomain.CommissionCurrencyTON
+
+	if paidInTON && commissionAmount != "" {
+		commission, pErr := tonamount.NewTonAmountFromString(commissionAmount)
+		if pErr != nil {
+			commitErr = pErr
+			log.Error("failed to parse commission amount", zap.Error(pErr))
+			return nil, pErr
+		}
+
+		if !commission.IsZero() {
+			_, err = s.paymentPrivateClient.AddUserBalance(ctx, &paymentv1.AddUserBalanceRequest{
+				TelegramUserId: &sharedv1.TelegramUserId{Value: gift.OwnerTelegramID},
+				TonAmount:      &sharedv1.TonAmount{Value: commission.String()},
+				Reason:         paymentv1.TransactionReason_TRANSACTION_REASON_REFUND,
+				Metadata: &paymentv1.TransactionMetadata{
+					Data: &paymentv1.TransactionMetadata_Gift{
+						Gift: &paymentv1.TransactionMetadata_GiftDetails{
+							GiftId:               gift.ID,
+							Title:                gift.Title,
+							Slug:                 gift.Slug,
+							WithdrawalCommission: true,
+						},
+					},
+				},
+				IdempotencyKey: giftIdempotencyKey(opWithdrawalRefund, pending),
+			})
+			if err != nil {
+				commitErr = err
+				log.Error("failed to refund withdrawal commission", zap.Error(err))
+				return nil, err
+			}
+		}
+	}
+
+	if paidInTON {
+		err = tracker.RefundGift(ctx, giftID, withdrawalDomain.ReasonUserNotFound)
+	} else {
+		err = tracker.FailGift(ctx, giftID, withdrawalDomain.ReasonUserNotFound)
+	}
+	if err != nil {
+		commitErr = err
+		return nil, err
+	}
+
+	commitErr = tx.Commit(ctx)
+	if commitErr != nil {
+		log.Error("transaction commit failed", zap.Error(commitErr))
+		return nil, commitErr
+	}
+
+	return gift, nil
+}
+
+// ExpireStarsWithdrawals освобождает подарки из неоплаченных инвойсов,
+// у которых вышел срок, и помечает инвойсы истёкшими. Возвращает число
+// обработанных инвойсов.
+func (s *WithdrawalSaga) ExpireStarsWithdrawals(
+	ctx context.Context,
+	now time.Time,
+	limit int32,
+) (int, error) {
+	tx, err := s.txMgr.BeginTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var commitErr error
+	defer func() {
+		if commitErr != nil {
+			if rbErr := tx.Rollback(ctx); rbErr != nil {
+				s.log.Error("rollback failed", zap.Error(rbErr))
+			}
+		}
+	}()
+
+	repo := s.repo.WithTx(tx)
+	withdrawalRepo := s.withdrawalRepo.WithTx(tx)
+	tracker := s.withdrawalTracker.WithTx(tx)
+
+	intents, err := withdrawalRepo.GetExpiredIntentsForUpdate(ctx, now, limit)
+	if err != nil {
+		commitErr = err
+		s.log.Error("failed to get expired withdrawal intents", zap.Error(err))
+		return 0, err
+	}
+
+	for _, intent := range intents {
+		log := s.log.With(zap.String("intentID", intent.ID))
+
+		for _, giftID := range intent.GiftIDs {
+			// подарок мог уже уйти в другой статус — тогда освобождать нечего
+			if _, err = repo.CancelGiftWithdrawal(ctx, giftID); err != nil && !pg.IsNotFound(err) {
+				commitErr = err
+				log.Error("failed to release gift", zap.String("giftID", giftID), zap.Error(err))
+				return 0, err
+			}
+		}
+
+		if _, err = withdrawalRepo.MarkIntentExpired(ctx, intent.ID); err != nil {
+			commitErr = err
+			log.Error("failed to expire withdrawal intent", zap.Error(err))
+			return 0, err
+		}
+
+		if err = tracker.ExpireByIntent(ctx, intent.ID); err != nil {
+			commitErr = err
+			return 0, err
+		}
+	}
+
+	commitErr = tx.Commit(ctx)
+	if commitErr != nil {
+		s.log.Error("transaction commit failed", zap.Error(commitErr))
+		return 0, commitErr
+	}
+
+	return len(intents), nil
+}
+
+// GetStarsWithdrawalIntent возвращает инвойс на вывод, если он принадлежит пользователю.
+func (s *WithdrawalSaga) GetStarsWithdrawalIntent(
+	ctx context.Context,
+	telegramUserID int64,
+	intentID string,
+) (*withdrawalDomain.Intent, error) {
+	intent, err := s.withdrawalRepo.GetIntentByID(ctx, intentID)
+	if err != nil {
+		if pg.IsNotFound(err) {
+			return nil, withdrawalDomain.ErrIntentNotFound
+		}
+		return nil, err
+	}
+	// чужие инвойсы не раскрываем
+	if intent.TelegramUserID != telegramUserID {
+		return nil, withdrawalDomain.ErrIntentNotFound
+	}
+	return intent, nil
 }
@@ -0,0 +1,82 @@
+package withdrawalsweeper
+
+import (
+	"context"
+	"time"
+
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/config"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/service/saga"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+	"go.uber.org/zap"
+)
+
+// batchSize — сколько просроченных инвойсов обрабатывается в одной транзакции.
+const batchSize = 100
+
+// Sweeper периодически освобождает подарки из неоплаченных Stars-инвойсов.
+type Sweeper struct {
+	withdrawalSaga *saga.WithdrawalSaga
+	interval       time.Duration
+	cancel         context.CancelFunc
+	logger         *logger.Logger
+}
+
+func NewSweeper(
+	withdrawalSaga *saga.WithdrawalSaga,
+	cfg *config.Config,
+	logger *logger.Logger,
+) *Sweeper {
+	return &Sweeper{
+		withdrawalSaga: withdrawalSaga,
+		interval:       cfg.Withdrawal.SweepInterval,
+		logger:         logger,
+	}
+}
+
+func (s *Sweeper) Start() {
+	ctx := context.Background()
+	ctx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+	go func() {
+		s.run(ctx)
+	}()
+}
+
+func (s *Sweeper) Stop(_ context.Context) error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	return nil
+}
+
+func (s *Sweeper) run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("withdrawal sweeper stopping")
+			return
+		case <-ticker.C:
+			s.sweep(ctx)
+		}
+	}
+}
+
+// sweep разбирает просроченные инвойсы пачками, пока они не закончатся.
+func (s *Sweeper) sweep(ctx context.Context) {
+	for ctx.Err() == nil {
+		expired, err := s.withdrawalSaga.ExpireStarsWithdrawals(ctx, time.Now(), batchSize)
+		if err != nil {
+			s.logger.Error("failed to expire stars withdrawals", zap.Error(err))
+			return
+		}
+		if expired > 0 {
+			s.logger.Info("expired stars withdrawals", zap.Int("count", expired))
+		}
+		if expired < batchSize {
+			return
+		}
+	}
+}
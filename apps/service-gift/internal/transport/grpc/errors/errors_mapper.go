@@ -3,11 +3,39 @@ package grpcerrors
 import (
 	"context"
 
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/gift"
 	"github.com/peterparker2005/giftduels/packages/errors"
+	errorsv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/errors/v1"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 )
 
 func MapError(ctx context.Context, err error) error {
+	if gift.IsFeeScheduleChanged(err) {
+		return errors.NewError(
+			errors.WithGRPCCode(codes.FailedPrecondition),
+			errors.WithErrorCode(errorsv1.ErrorCode_ERROR_CODE_VALIDATION_GENERAL),
+			errors.WithMessage(err.Error()),
+			errors.WithContext(ctx),
+		)
+	}
+	if gift.IsGiftNotFound(err) {
+		return errors.NewError(
+			errors.WithGRPCCode(codes.NotFound),
+			errors.WithErrorCode(errorsv1.ErrorCode_ERROR_CODE_GIFT_NOT_FOUND),
+			errors.WithMessage(err.Error()),
+			errors.WithContext(ctx),
+		)
+	}
+	if gift.IsLeaseNotHeld(err) {
+		// service-duel по этому коду прекращает повторять подтверждение ставки
+		return errors.NewError(
+			errors.WithGRPCCode(codes.FailedPrecondition),
+			errors.WithErrorCode(errorsv1.ErrorCode_ERROR_CODE_VALIDATION_GENERAL),
+			errors.WithMessage(err.Error()),
+			errors.WithContext(ctx),
+		)
+	}
 	return errors.Wrap(ctx, err)
 }
 
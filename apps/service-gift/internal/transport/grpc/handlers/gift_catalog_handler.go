@@ -0,0 +1,122 @@
+package grpchandlers
+
+import (
+	"context"
+
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/adapter/proto"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/service/query"
+	giftv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/gift/v1"
+)
+
+type giftCatalogHandler struct {
+	giftv1.GiftCatalogServiceServer
+
+	catalogReadService *query.CatalogReadService
+}
+
+// NewGiftCatalogHandler создает GRPC handler каталога атрибутов.
+func NewGiftCatalogHandler(
+	catalogReadService *query.CatalogReadService,
+) giftv1.GiftCatalogServiceServer {
+	return &giftCatalogHandler{
+		catalogReadService: catalogReadService,
+	}
+}
+
+func (h *giftCatalogHandler) ListCollections(
+	ctx context.Context,
+	req *giftv1.ListCollectionsRequest,
+) (*giftv1.ListCollectionsResponse, error) {
+	snapshot, err := h.catalogReadService.ListCollections(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetIfNoneMatch() == snapshot.ETag {
+		return &giftv1.ListCollectionsResponse{Etag: snapshot.ETag, NotModified: true}, nil
+	}
+
+	collections := make([]*giftv1.CatalogCollection, len(snapshot.Items))
+	for i, e := range snapshot.Items {
+		collections[i] = proto.DomainCatalogCollectionToProto(e)
+	}
+
+	return &giftv1.ListCollectionsResponse{
+		Collections: collections,
+		Etag:        snapshot.ETag,
+	}, nil
+}
+
+func (h *giftCatalogHandler) ListModels(
+	ctx context.Context,
+	req *giftv1.ListModelsRequest,
+) (*giftv1.ListModelsResponse, error) {
+	var collection *string
+	if req.GetCollection() != "" {
+		c := req.GetCollection()
+		collection = &c
+	}
+
+	snapshot, err := h.catalogReadService.ListModels(ctx, collection)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetIfNoneMatch() == snapshot.ETag {
+		return &giftv1.ListModelsResponse{Etag: snapshot.ETag, NotModified: true}, nil
+	}
+
+	models := make([]*giftv1.CatalogModel, len(snapshot.Items))
+	for i, e := range snapshot.Items {
+		models[i] = proto.DomainCatalogModelToProto(e)
+	}
+
+	return &giftv1.ListModelsResponse{
+		Models: models,
+		Etag:   snapshot.ETag,
+	}, nil
+}
+
+func (h *giftCatalogHandler) ListBackdrops(
+	ctx context.Context,
+	req *giftv1.ListBackdropsRequest,
+) (*giftv1.ListBackdropsResponse, error) {
+	snapshot, err := h.catalogReadService.ListBackdrops(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetIfNoneMatch() == snapshot.ETag {
+		return &giftv1.ListBackdropsResponse{Etag: snapshot.ETag, NotModified: true}, nil
+	}
+
+	backdrops := make([]*giftv1.CatalogBackdrop, len(snapshot.Items))
+	for i, e := range snapshot.Items {
+		backdrops[i] = proto.DomainCatalogBackdropToProto(e)
+	}
+
+	return &giftv1.ListBackdropsResponse{
+		Backdrops: backdrops,
+		Etag:      snapshot.ETag,
+	}, nil
+}
+
+func (h *giftCatalogHandler) ListSymbols(
+	ctx context.Context,
+	req *giftv1.ListSymbolsRequest,
+) (*giftv1.ListSymbolsResponse, error) {
+	snapshot, err := h.catalogReadService.ListSymbols(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetIfNoneMatch() == snapshot.ETag {
+		return &giftv1.ListSymbolsResponse{Etag: snapshot.ETag, NotModified: true}, nil
+	}
+
+	symbols := make([]*giftv1.CatalogSymbol, len(snapshot.Items))
+	for i, e := range snapshot.Items {
+		symbols[i] = proto.DomainCatalogSymbolToProto(e)
+	}
+
+	return &giftv1.ListSymbolsResponse{
+		Symbols: symbols,
+		Etag:    snapshot.ETag,
+	}, nil
+}
@@ -0,0 +1,69 @@
+package grpchandlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/config"
+	catalogDomain "github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/catalog"
+	giftDomain "github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/gift"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/service/query"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+	giftv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/gift/v1"
+)
+
+type fakeCatalogRepo struct {
+	catalogDomain.Repository
+}
+
+func (fakeCatalogRepo) ListCollections(context.Context) ([]*catalogDomain.CollectionEntry, error) {
+	return []*catalogDomain.CollectionEntry{
+		{Collection: giftDomain.Collection{ID: 1, Name: "Plush Pepe", ShortName: "plushpepe"}},
+	}, nil
+}
+
+func TestListCollectionsIfNoneMatch(t *testing.T) {
+	log, err := logger.NewLogger(logger.Config{Service: "test", Level: "fatal"})
+	if err != nil {
+		t.Fatalf("logger: %v", err)
+	}
+	h := NewGiftCatalogHandler(query.NewCatalogReadService(fakeCatalogRepo{}, &config.Config{}, log))
+	ctx := context.Background()
+
+	full, err := h.ListCollections(ctx, &giftv1.ListCollectionsRequest{})
+	if err != nil {
+		t.Fatalf("list collections: %v", err)
+	}
+	if full.GetNotModified() || len(full.GetCollections()) != 1 || full.GetEtag() == "" {
+		t.Fatalf("first response %v, want the collection with an etag", full)
+	}
+
+	tests := []struct {
+		name            string
+		ifNoneMatch     string
+		wantNotModified bool
+	}{
+		{name: "current etag", ifNoneMatch: full.GetEtag(), wantNotModified: true},
+		{name: "stale etag", ifNoneMatch: "stale"},
+		{name: "no etag"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := h.ListCollections(ctx, &giftv1.ListCollectionsRequest{IfNoneMatch: tt.ifNoneMatch})
+			if err != nil {
+				t.Fatalf("list collections: %v", err)
+			}
+			if resp.GetNotModified() != tt.wantNotModified {
+				t.Errorf("not modified = %v, want %v", resp.GetNotModified(), tt.wantNotModified)
+			}
+			// ETag отдаётся всегда, тело — только если версия клиента устарела
+			if resp.GetEtag() != full.GetEtag() {
+				t.Errorf("etag %s, want %s", resp.GetEtag(), full.GetEtag())
+			}
+			if wantItems := !tt.wantNotModified; (len(resp.GetCollections()) == 1) != wantItems {
+				t.Errorf("collections %v, want body = %v", resp.GetCollections(), wantItems)
+			}
+		})
+	}
+}
@@ -4,12 +4,14 @@ import (
 	"context"
 
 	"github.com/peterparker2005/giftduels/apps/service-gift/internal/adapter/proto"
+	giftDomain "github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/gift"
 	"github.com/peterparker2005/giftduels/apps/service-gift/internal/service/command"
 	"github.com/peterparker2005/giftduels/apps/service-gift/internal/service/query"
 	"github.com/peterparker2005/giftduels/apps/service-gift/internal/service/saga"
 	giftv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/gift/v1"
 	sharedv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/shared/v1"
 	"github.com/peterparker2005/giftduels/packages/shared"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type giftPrivateHandler struct {
@@ -20,6 +22,7 @@ type giftPrivateHandler struct {
 	giftWithdrawCommand *command.GiftWithdrawCommand
 	giftReadService     *query.GiftReadService
 	userGiftsService    *query.UserGiftsService
+	stakeSuggestionSvc  *query.StakeSuggestionService
 }
 
 func NewGiftPrivateHandler(
@@ -28,6 +31,7 @@ func NewGiftPrivateHandler(
 	giftWithdrawCommand *command.GiftWithdrawCommand,
 	giftReadService *query.GiftReadService,
 	userGiftsService *query.UserGiftsService,
+	stakeSuggestionSvc *query.StakeSuggestionService,
 ) giftv1.GiftPrivateServiceServer {
 	return &giftPrivateHandler{
 		withdrawalSaga:      withdrawalSaga,
@@ -35,6 +39,7 @@ func NewGiftPrivateHandler(
 		giftWithdrawCommand: giftWithdrawCommand,
 		giftReadService:     giftReadService,
 		userGiftsService:    userGiftsService,
+		stakeSuggestionSvc:  stakeSuggestionSvc,
 	}
 }
 
@@ -109,9 +114,30 @@ func (h *giftPrivateHandler) StakeGift(
 		return nil, err
 	}
 
-	return &giftv1.StakeGiftResponse{
+	resp := &giftv1.StakeGiftResponse{
 		Gift: proto.DomainGiftToProto(g),
-	}, nil
+	}
+	if g.Lock != nil && g.Lock.ExpiresAt != nil {
+		resp.LeaseExpiresAt = timestamppb.New(*g.Lock.ExpiresAt)
+	}
+	return resp, nil
+}
+
+func (h *giftPrivateHandler) ConfirmStake(
+	ctx context.Context,
+	req *giftv1.ConfirmStakeRequest,
+) (*giftv1.ConfirmStakeResponse, error) {
+	giftIDs := make([]string, 0, len(req.GetGiftIds()))
+	for _, id := range req.GetGiftIds() {
+		giftIDs = append(giftIDs, id.GetValue())
+	}
+
+	err := h.giftStakeCommand.ConfirmStakes(ctx, req.GetDuelId().GetValue(), giftIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &giftv1.ConfirmStakeResponse{}, nil
 }
 
 // func (h *giftPrivateHandler) TransferGiftToUser(ctx context.Context, req *giftv1.TransferGiftToUserRequest) (*giftv1.TransferGiftToUserResponse, error) {
@@ -138,3 +164,35 @@ func (h *giftPrivateHandler) PrivateGetGift(
 		Gift: proto.DomainGiftToProto(g),
 	}, nil
 }
+
+func (h *giftPrivateHandler) PrivateSuggestStake(
+	ctx context.Context,
+	req *giftv1.PrivateSuggestStakeRequest,
+) (*giftv1.PrivateSuggestStakeResponse, error) {
+	target, err := proto.ProtoEntryPriceRangeToStakeTarget(req.GetEntryPriceRange())
+	if err != nil {
+		return nil, err
+	}
+
+	suggestion, err := h.stakeSuggestionSvc.SuggestStake(ctx, query.SuggestStakeParams{
+		TelegramUserID: req.GetTelegramUserId().GetValue(),
+		Target:         target,
+		MaxGifts:       int(req.GetMaxGifts()),
+	})
+	if err != nil {
+		if giftDomain.IsNoStakeSuggestion(err) {
+			return &giftv1.PrivateSuggestStakeResponse{}, nil
+		}
+		return nil, err
+	}
+
+	giftProtos := make([]*giftv1.Gift, len(suggestion.Gifts))
+	for i, g := range suggestion.Gifts {
+		giftProtos[i] = proto.DomainGiftToProto(g)
+	}
+
+	return &giftv1.PrivateSuggestStakeResponse{
+		Gifts:      giftProtos,
+		TotalValue: &sharedv1.TonAmount{Value: suggestion.Total.String()},
+	}, nil
+}
@@ -2,8 +2,11 @@ package grpchandlers
 
 import (
 	"context"
+	"time"
 
 	"github.com/peterparker2005/giftduels/apps/service-gift/internal/adapter/proto"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/gift"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/service/command"
 	"github.com/peterparker2005/giftduels/apps/service-gift/internal/service/query"
 	"github.com/peterparker2005/giftduels/apps/service-gift/internal/service/saga"
 	"github.com/peterparker2005/giftduels/packages/grpc-go/authctx"
@@ -11,31 +14,63 @@ import (
 	giftv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/gift/v1"
 	sharedv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/shared/v1"
 	"github.com/peterparker2005/giftduels/packages/shared"
+	"github.com/peterparker2005/giftduels/packages/tonamount-go"
 	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type giftPublicHandler struct {
 	giftv1.GiftPublicServiceServer
 
 	// зависимость от сервисного слоя
-	withdrawalSaga   *saga.WithdrawalSaga
-	giftReadService  *query.GiftReadService
-	userGiftsService *query.UserGiftsService
-	logger           *logger.Logger
+	withdrawalSaga     *saga.WithdrawalSaga
+	marketplaceSaga    *saga.MarketplaceSaga
+	sellBackSaga       *saga.SellBackSaga
+	giftListingCommand *command.GiftListingCommand
+	giftReadService    *query.GiftReadService
+	userGiftsService   *query.UserGiftsService
+	listingReadService *query.ListingReadService
+	giftHistoryService *query.GiftHistoryService
+	withdrawalReadSvc  *query.WithdrawalReadService
+	portfolioService   *query.PortfolioService
+	favoriteCommand    *command.FavoriteCommand
+	favoriteReadSvc    *query.FavoriteReadService
+	stakeSuggestionSvc *query.StakeSuggestionService
+	logger             *logger.Logger
 }
 
 // NewGiftPublicHandler создает новый GRPC handler.
 func NewGiftPublicHandler(
 	withdrawalSaga *saga.WithdrawalSaga,
+	marketplaceSaga *saga.MarketplaceSaga,
+	sellBackSaga *saga.SellBackSaga,
+	giftListingCommand *command.GiftListingCommand,
 	giftReadService *query.GiftReadService,
 	userGiftsService *query.UserGiftsService,
+	listingReadService *query.ListingReadService,
+	giftHistoryService *query.GiftHistoryService,
+	withdrawalReadSvc *query.WithdrawalReadService,
+	portfolioService *query.PortfolioService,
+	favoriteCommand *command.FavoriteCommand,
+	favoriteReadSvc *query.FavoriteReadService,
+	stakeSuggestionSvc *query.StakeSuggestionService,
 	logger *logger.Logger,
 ) giftv1.GiftPublicServiceServer {
 	return &giftPublicHandler{
-		withdrawalSaga:   withdrawalSaga,
-		giftReadService:  giftReadService,
-		userGiftsService: userGiftsService,
-		logger:           logger,
+		withdrawalSaga:     withdrawalSaga,
+		marketplaceSaga:    marketplaceSaga,
+		sellBackSaga:       sellBackSaga,
+		giftListingCommand: giftListingCommand,
+		giftReadService:    giftReadService,
+		userGiftsService:   userGiftsService,
+		listingReadService: listingReadService,
+		giftHistoryService: giftHistoryService,
+		withdrawalReadSvc:  withdrawalReadSvc,
+		portfolioService:   portfolioService,
+		favoriteCommand:    favoriteCommand,
+		favoriteReadSvc:    favoriteReadSvc,
+		stakeSuggestionSvc: stakeSuggestionSvc,
+		logger:             logger,
 	}
 }
 
@@ -64,31 +99,41 @@ func (h *giftPublicHandler) GetGifts(
 
 	log := h.logger.With(zap.Int64("telegramUserID", telegramUserID))
 
+	filter, err := proto.ProtoInventoryFilterToDomain(req.GetFilter())
+	if err != nil {
+		return nil, err
+	}
+	sort := proto.ProtoInventorySortToDomain(req.GetSortBy(), req.GetSortOrder())
+
 	pagination := shared.NewPageRequest(
 		req.GetPagination().GetPage(),
 		req.GetPagination().GetPageSize(),
 	)
-	domainGifts, err := h.userGiftsService.GetUserActiveGifts(ctx, telegramUserID, pagination)
+	result, err := h.userGiftsService.SearchUserGifts(ctx, telegramUserID, filter, sort, pagination)
 	if err != nil {
-		log.Error("Failed to get user active gifts", zap.Error(err))
+		log.Error("Failed to search user gifts", zap.Error(err))
 		return nil, err
 	}
 
-	giftViews := make([]*giftv1.GiftView, len(domainGifts.Gifts))
-	for i, g := range domainGifts.Gifts {
+	giftViews := make([]*giftv1.GiftView, len(result.Gifts))
+	for i, g := range result.Gifts {
 		giftViews[i] = proto.DomainGiftToProtoView(g)
 	}
 
+	summary, err := proto.DomainInventorySummaryToProto(result.Summary)
+	if err != nil {
+		return nil, err
+	}
+
 	return &giftv1.GetGiftsResponse{
-		Gifts: giftViews,
-		TotalValue: &sharedv1.TonAmount{
-			Value: domainGifts.TotalValue.String(),
-		},
+		Gifts:      giftViews,
+		TotalValue: summary.GetTotalValue(),
+		Summary:    summary,
 		Pagination: &sharedv1.PageResponse{
 			Page:       pagination.Page(),
 			PageSize:   pagination.PageSize(),
-			Total:      domainGifts.Total,
-			TotalPages: pagination.TotalPages(domainGifts.Total),
+			Total:      result.Total,
+			TotalPages: pagination.TotalPages(result.Total),
 		},
 	}, nil
 }
@@ -112,6 +157,7 @@ func (h *giftPublicHandler) ExecuteWithdraw(
 		telegramUserID,
 		ids,
 		req.GetCommissionCurrency(),
+		req.GetFeeScheduleVersion(),
 	)
 	if err != nil {
 		return nil, err
@@ -123,6 +169,8 @@ func (h *giftPublicHandler) ExecuteWithdraw(
 			Response: &giftv1.ExecuteWithdrawResponse_StarsInvoiceUrl{
 				StarsInvoiceUrl: result.StarsInvoiceURL,
 			},
+			WithdrawalIntentId: result.IntentID,
+			WithdrawalId:       result.WithdrawalID,
 		}, nil
 	}
 	// Возвращаем успешный ответ для TON
@@ -133,5 +181,553 @@ func (h *giftPublicHandler) ExecuteWithdraw(
 				Message: "Withdrawal request received",
 			},
 		},
+		WithdrawalId: result.WithdrawalID,
+	}, nil
+}
+
+func (h *giftPublicHandler) GetWithdrawalStatus(
+	ctx context.Context,
+	req *giftv1.GetWithdrawalStatusRequest,
+) (*giftv1.GetWithdrawalStatusResponse, error) {
+	telegramUserID, err := authctx.TelegramUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	intent, err := h.withdrawalSaga.GetStarsWithdrawalIntent(
+		ctx,
+		telegramUserID,
+		req.GetWithdrawalIntentId(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	protoIntent, err := proto.DomainWithdrawalIntentToProto(intent)
+	if err != nil {
+		return nil, err
+	}
+
+	return &giftv1.GetWithdrawalStatusResponse{
+		Intent: protoIntent,
+	}, nil
+}
+
+func (h *giftPublicHandler) ListMyWithdrawals(
+	ctx context.Context,
+	req *giftv1.ListMyWithdrawalsRequest,
+) (*giftv1.ListMyWithdrawalsResponse, error) {
+	telegramUserID, err := authctx.TelegramUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses, err := proto.ProtoWithdrawalStatusesToDomain(req.GetStatuses())
+	if err != nil {
+		return nil, err
+	}
+
+	pagination := shared.NewPageRequest(
+		req.GetPagination().GetPage(),
+		req.GetPagination().GetPageSize(),
+	)
+	result, err := h.withdrawalReadSvc.ListUserWithdrawals(ctx, telegramUserID, statuses, pagination)
+	if err != nil {
+		h.logger.Error("Failed to list withdrawals", zap.Error(err))
+		return nil, err
+	}
+
+	withdrawals := make([]*giftv1.Withdrawal, len(result.Withdrawals))
+	for i, w := range result.Withdrawals {
+		withdrawals[i] = proto.DomainWithdrawalToProto(w.Withdrawal, w.GiftsByID)
+	}
+
+	return &giftv1.ListMyWithdrawalsResponse{
+		Withdrawals: withdrawals,
+		Pagination: &sharedv1.PageResponse{
+			Page:       pagination.Page(),
+			PageSize:   pagination.PageSize(),
+			Total:      result.Total,
+			TotalPages: pagination.TotalPages(result.Total),
+		},
+	}, nil
+}
+
+func (h *giftPublicHandler) GetWithdrawal(
+	ctx context.Context,
+	req *giftv1.GetWithdrawalRequest,
+) (*giftv1.GetWithdrawalResponse, error) {
+	telegramUserID, err := authctx.TelegramUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := h.withdrawalReadSvc.GetUserWithdrawal(ctx, telegramUserID, req.GetWithdrawalId())
+	if err != nil {
+		return nil, err
+	}
+
+	return &giftv1.GetWithdrawalResponse{
+		Withdrawal: proto.DomainWithdrawalToProto(result.Withdrawal, result.GiftsByID),
+	}, nil
+}
+
+func (h *giftPublicHandler) ListGift(
+	ctx context.Context,
+	req *giftv1.ListGiftRequest,
+) (*giftv1.ListGiftResponse, error) {
+	telegramUserID, err := authctx.TelegramUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	price, err := tonamount.NewTonAmountFromString(req.GetPrice().GetValue())
+	if err != nil {
+		return nil, err
+	}
+
+	l, err := h.giftListingCommand.ListGift(ctx, command.ListGiftParams{
+		GiftID:         req.GetGiftId().GetValue(),
+		TelegramUserID: telegramUserID,
+		Price:          price,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	g, err := h.giftReadService.GetGiftByID(ctx, l.GiftID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &giftv1.ListGiftResponse{
+		Listing: proto.DomainListingToProto(l, g),
+	}, nil
+}
+
+func (h *giftPublicHandler) UnlistGift(
+	ctx context.Context,
+	req *giftv1.UnlistGiftRequest,
+) (*giftv1.UnlistGiftResponse, error) {
+	telegramUserID, err := authctx.TelegramUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	g, err := h.giftListingCommand.UnlistGift(ctx, telegramUserID, req.GetListingId().GetValue())
+	if err != nil {
+		return nil, err
+	}
+
+	return &giftv1.UnlistGiftResponse{
+		Gift: proto.DomainGiftToProtoView(g),
+	}, nil
+}
+
+func (h *giftPublicHandler) BuyGift(
+	ctx context.Context,
+	req *giftv1.BuyGiftRequest,
+) (*giftv1.BuyGiftResponse, error) {
+	telegramUserID, err := authctx.TelegramUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := h.marketplaceSaga.BuyGift(ctx, telegramUserID, req.GetListingId().GetValue())
+	if err != nil {
+		return nil, err
+	}
+
+	return &giftv1.BuyGiftResponse{
+		Gift:  proto.DomainGiftToProtoView(result.Gift),
+		Price: &sharedv1.TonAmount{Value: result.Listing.Price.String()},
+	}, nil
+}
+
+func (h *giftPublicHandler) GetListings(
+	ctx context.Context,
+	req *giftv1.GetListingsRequest,
+) (*giftv1.GetListingsResponse, error) {
+	filter, err := proto.ProtoListingFilterToDomain(req.GetFilter())
+	if err != nil {
+		return nil, err
+	}
+
+	pagination := shared.NewPageRequest(
+		req.GetPagination().GetPage(),
+		req.GetPagination().GetPageSize(),
+	)
+	result, err := h.listingReadService.GetListings(ctx, filter, pagination)
+	if err != nil {
+		h.logger.Error("Failed to get listings", zap.Error(err))
+		return nil, err
+	}
+
+	listings := make([]*giftv1.Listing, len(result.Listings))
+	for i, l := range result.Listings {
+		listings[i] = proto.DomainListingToProto(l.Listing, l.Gift)
+	}
+
+	return &giftv1.GetListingsResponse{
+		Listings: listings,
+		Pagination: &sharedv1.PageResponse{
+			Page:       pagination.Page(),
+			PageSize:   pagination.PageSize(),
+			Total:      result.Total,
+			TotalPages: pagination.TotalPages(result.Total),
+		},
+	}, nil
+}
+
+func (h *giftPublicHandler) QuoteSellBack(
+	ctx context.Context,
+	req *giftv1.QuoteSellBackRequest,
+) (*giftv1.QuoteSellBackResponse, error) {
+	telegramUserID, err := authctx.TelegramUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	quote, err := h.sellBackSaga.QuoteSellBack(ctx, telegramUserID, req.GetGiftId().GetValue())
+	if err != nil {
+		return nil, err
+	}
+
+	return &giftv1.QuoteSellBackResponse{
+		QuoteId:    &sharedv1.SellBackQuoteId{Value: quote.ID},
+		GiftId:     &sharedv1.GiftId{Value: quote.GiftID},
+		FloorPrice: &sharedv1.TonAmount{Value: quote.FloorPrice.String()},
+		Amount:     &sharedv1.TonAmount{Value: quote.Amount.String()},
+		ExpiresAt:  timestamppb.New(quote.ExpiresAt),
+	}, nil
+}
+
+func (h *giftPublicHandler) ExecuteSellBack(
+	ctx context.Context,
+	req *giftv1.ExecuteSellBackRequest,
+) (*giftv1.ExecuteSellBackResponse, error) {
+	telegramUserID, err := authctx.TelegramUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := h.sellBackSaga.ExecuteSellBack(ctx, telegramUserID, req.GetQuoteId().GetValue())
+	if err != nil {
+		return nil, err
+	}
+
+	return &giftv1.ExecuteSellBackResponse{
+		Gift:   proto.DomainGiftToProtoView(result.Gift),
+		Amount: &sharedv1.TonAmount{Value: result.Quote.Amount.String()},
+	}, nil
+}
+
+func (h *giftPublicHandler) GetGiftHistory(
+	ctx context.Context,
+	req *giftv1.GetGiftHistoryRequest,
+) (*giftv1.GetGiftHistoryResponse, error) {
+	telegramUserID, err := authctx.TelegramUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := proto.ProtoEventActionsToDomain(req.GetActions())
+	if err != nil {
+		return nil, err
+	}
+
+	pagination := shared.NewPageRequest(
+		req.GetPagination().GetPage(),
+		req.GetPagination().GetPageSize(),
+	)
+	result, err := h.giftHistoryService.GetGiftHistory(
+		ctx,
+		telegramUserID,
+		req.GetGiftId().GetValue(),
+		filter,
+		pagination,
+	)
+	if err != nil {
+		h.logger.Error("Failed to get gift history", zap.Error(err))
+		return nil, err
+	}
+
+	return &giftv1.GetGiftHistoryResponse{
+		Entries: mapGiftActivityEntries(result.Entries),
+		Pagination: &sharedv1.PageResponse{
+			Page:       pagination.Page(),
+			PageSize:   pagination.PageSize(),
+			Total:      result.Total,
+			TotalPages: pagination.TotalPages(result.Total),
+		},
+	}, nil
+}
+
+func (h *giftPublicHandler) GetMyGiftActivity(
+	ctx context.Context,
+	req *giftv1.GetMyGiftActivityRequest,
+) (*giftv1.GetMyGiftActivityResponse, error) {
+	telegramUserID, err := authctx.TelegramUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := proto.ProtoEventActionsToDomain(req.GetActions())
+	if err != nil {
+		return nil, err
+	}
+
+	pagination := shared.NewPageRequest(
+		req.GetPagination().GetPage(),
+		req.GetPagination().GetPageSize(),
+	)
+	result, err := h.giftHistoryService.GetUserGiftActivity(
+		ctx,
+		telegramUserID,
+		filter,
+		pagination,
+	)
+	if err != nil {
+		h.logger.Error("Failed to get user gift activity", zap.Error(err))
+		return nil, err
+	}
+
+	return &giftv1.GetMyGiftActivityResponse{
+		Entries: mapGiftActivityEntries(result.Entries),
+		Pagination: &sharedv1.PageResponse{
+			Page:       pagination.Page(),
+			PageSize:   pagination.PageSize(),
+			Total:      result.Total,
+			TotalPages: pagination.TotalPages(result.Total),
+		},
+	}, nil
+}
+
+func mapGiftActivityEntries(entries []*query.GiftActivityEntry) []*giftv1.GiftActivityEntry {
+	out := make([]*giftv1.GiftActivityEntry, len(entries))
+	for i, e := range entries {
+		out[i] = &giftv1.GiftActivityEntry{
+			Event:       proto.DomainEventToProto(e.Event),
+			RelatedDuel: e.RelatedDuel,
+		}
+	}
+	return out
+}
+
+func (h *giftPublicHandler) GetPortfolio(
+	ctx context.Context,
+	_ *giftv1.GetPortfolioRequest,
+) (*giftv1.GetPortfolioResponse, error) {
+	telegramUserID, err := authctx.TelegramUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := h.portfolioService.GetPortfolio(ctx, telegramUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	valuation, err := proto.DomainPortfolioValuationToProto(result.Valuation)
+	if err != nil {
+		return nil, err
+	}
+
+	return &giftv1.GetPortfolioResponse{
+		Valuation:   valuation,
+		Performance: proto.DomainPortfolioPerformanceToProto(result.Performance),
+	}, nil
+}
+
+func (h *giftPublicHandler) GetPortfolioHistory(
+	ctx context.Context,
+	req *giftv1.GetPortfolioHistoryRequest,
+) (*giftv1.GetPortfolioHistoryResponse, error) {
+	telegramUserID, err := authctx.TelegramUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var from, to time.Time
+	if req.GetFrom() != nil {
+		from = req.GetFrom().AsTime()
+	}
+	if req.GetTo() != nil {
+		to = req.GetTo().AsTime()
+	}
+
+	snapshots, err := h.portfolioService.GetPortfolioHistory(ctx, telegramUserID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	protoSnapshots := make([]*giftv1.PortfolioSnapshot, len(snapshots))
+	for i, snapshot := range snapshots {
+		protoSnapshots[i], err = proto.DomainPortfolioSnapshotToProto(snapshot)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &giftv1.GetPortfolioHistoryResponse{Snapshots: protoSnapshots}, nil
+}
+
+func (h *giftPublicHandler) AddFavoriteGift(
+	ctx context.Context,
+	req *giftv1.AddFavoriteGiftRequest,
+) (*giftv1.AddFavoriteGiftResponse, error) {
+	telegramUserID, err := authctx.TelegramUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = h.favoriteCommand.AddFavorite(ctx, telegramUserID, req.GetGiftId().GetValue()); err != nil {
+		return nil, err
+	}
+
+	return &giftv1.AddFavoriteGiftResponse{}, nil
+}
+
+func (h *giftPublicHandler) RemoveFavoriteGift(
+	ctx context.Context,
+	req *giftv1.RemoveFavoriteGiftRequest,
+) (*giftv1.RemoveFavoriteGiftResponse, error) {
+	telegramUserID, err := authctx.TelegramUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = h.favoriteCommand.RemoveFavorite(ctx, telegramUserID, req.GetGiftId().GetValue()); err != nil {
+		return nil, err
+	}
+
+	return &giftv1.RemoveFavoriteGiftResponse{}, nil
+}
+
+func (h *giftPublicHandler) GetFavoriteGifts(
+	ctx context.Context,
+	_ *giftv1.GetFavoriteGiftsRequest,
+) (*giftv1.GetFavoriteGiftsResponse, error) {
+	telegramUserID, err := authctx.TelegramUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	gifts, err := h.favoriteReadSvc.GetFavoriteGifts(ctx, telegramUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	giftViews := make([]*giftv1.GiftView, len(gifts))
+	for i, g := range gifts {
+		giftViews[i] = proto.DomainGiftToProtoView(g)
+	}
+
+	return &giftv1.GetFavoriteGiftsResponse{Gifts: giftViews}, nil
+}
+
+func (h *giftPublicHandler) SaveStakePreset(
+	ctx context.Context,
+	req *giftv1.SaveStakePresetRequest,
+) (*giftv1.SaveStakePresetResponse, error) {
+	telegramUserID, err := authctx.TelegramUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	giftIDs := make([]string, len(req.GetGiftIds()))
+	for i, id := range req.GetGiftIds() {
+		giftIDs[i] = id.GetValue()
+	}
+
+	preset, err := h.favoriteCommand.SavePreset(ctx, telegramUserID, req.GetName(), giftIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	gifts, err := h.giftReadService.GetGiftsByIDs(ctx, preset.GiftIDs)
+	if err != nil {
+		return nil, err
+	}
+	giftsByID := make(map[string]*gift.Gift, len(gifts))
+	for _, g := range gifts {
+		giftsByID[g.ID] = g
+	}
+
+	return &giftv1.SaveStakePresetResponse{
+		Preset: proto.DomainStakePresetToProto(preset, giftsByID),
+	}, nil
+}
+
+func (h *giftPublicHandler) DeleteStakePreset(
+	ctx context.Context,
+	req *giftv1.DeleteStakePresetRequest,
+) (*giftv1.DeleteStakePresetResponse, error) {
+	telegramUserID, err := authctx.TelegramUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = h.favoriteCommand.DeletePreset(ctx, telegramUserID, req.GetPresetId()); err != nil {
+		return nil, err
+	}
+
+	return &giftv1.DeleteStakePresetResponse{}, nil
+}
+
+func (h *giftPublicHandler) GetStakePresets(
+	ctx context.Context,
+	_ *giftv1.GetStakePresetsRequest,
+) (*giftv1.GetStakePresetsResponse, error) {
+	telegramUserID, err := authctx.TelegramUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	presets, err := h.favoriteReadSvc.GetPresets(ctx, telegramUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	protoPresets := make([]*giftv1.StakePreset, len(presets))
+	for i, p := range presets {
+		protoPresets[i] = proto.DomainStakePresetToProto(p.Preset, p.GiftsByID)
+	}
+
+	return &giftv1.GetStakePresetsResponse{Presets: protoPresets}, nil
+}
+
+func (h *giftPublicHandler) SuggestStake(
+	ctx context.Context,
+	req *giftv1.SuggestStakeRequest,
+) (*giftv1.SuggestStakeResponse, error) {
+	telegramUserID, err := authctx.TelegramUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := proto.ProtoSuggestStakeTargetToDomain(req)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestion, err := h.stakeSuggestionSvc.SuggestStake(ctx, query.SuggestStakeParams{
+		TelegramUserID: telegramUserID,
+		Target:         target,
+		MaxGifts:       int(req.GetMaxGifts()),
+		FavoritesOnly:  req.GetFavoritesOnly(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	giftViews := make([]*giftv1.GiftView, len(suggestion.Gifts))
+	for i, g := range suggestion.Gifts {
+		giftViews[i] = proto.DomainGiftToProtoView(g)
+	}
+
+	return &giftv1.SuggestStakeResponse{
+		Gifts:      giftViews,
+		TotalValue: &sharedv1.TonAmount{Value: suggestion.Total.String()},
 	}, nil
 }
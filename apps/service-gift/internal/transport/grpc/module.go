@@ -17,6 +17,7 @@ var Module = fx.Options(
 		},
 		grpchandlers.NewGiftPublicHandler,
 		grpchandlers.NewGiftPrivateHandler,
+		grpchandlers.NewGiftCatalogHandler,
 		NewGRPCServer,
 	),
 
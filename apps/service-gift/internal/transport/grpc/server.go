@@ -28,6 +28,7 @@ func NewGRPCServer(
 
 	publicHandler giftv1.GiftPublicServiceServer,
 	privateHandler giftv1.GiftPrivateServiceServer,
+	catalogHandler giftv1.GiftCatalogServiceServer,
 	log *logger.Logger,
 ) *Server {
 	opts := []grpc.ServerOption{
@@ -48,6 +49,7 @@ func NewGRPCServer(
 
 	giftv1.RegisterGiftPublicServiceServer(s, publicHandler)
 	giftv1.RegisterGiftPrivateServiceServer(s, privateHandler)
+	giftv1.RegisterGiftCatalogServiceServer(s, catalogHandler)
 
 	hs := health.NewServer()
 	hs.SetServingStatus("gift", healthpb.HealthCheckResponse_SERVING)
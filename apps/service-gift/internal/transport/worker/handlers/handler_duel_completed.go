@@ -1,10 +1,10 @@
 package workerhandlers
 
 import (
-	"context"
 	"fmt"
 
 	"github.com/ThreeDotsLabs/watermill/message"
+	giftdomain "github.com/peterparker2005/giftduels/apps/service-gift/internal/domain/gift"
 	"github.com/peterparker2005/giftduels/apps/service-gift/internal/service/command"
 	"github.com/peterparker2005/giftduels/packages/logger-go"
 	duelv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/duel/v1"
@@ -28,7 +28,7 @@ func NewDuelCompletedHandler(
 }
 
 func (h *DuelCompletedHandler) Handle(msg *message.Message) error {
-	ctx := context.Background()
+	ctx := msg.Context()
 
 	var event duelv1.DuelCompletedEvent
 	if err := proto.Unmarshal(msg.Payload, &event); err != nil {
@@ -71,6 +71,15 @@ func (h *DuelCompletedHandler) Handle(msg *message.Message) error {
 			OwnerID:       winnerID,
 			RelatedGameID: duelID,
 		})
+		if giftdomain.IsLeaseNotHeld(err) {
+			// аренда истекла до подтверждения, подарок уже у владельца —
+			// повторная доставка этого не изменит
+			h.logger.Error("gift is no longer staked in the duel, skipping transfer",
+				zap.String("giftID", giftID),
+				zap.Int64("winnerID", winnerID),
+				zap.String("duelID", duelID))
+			continue
+		}
 		if err != nil {
 			h.logger.Error("failed to transfer gift to winner",
 				zap.String("giftID", giftID),
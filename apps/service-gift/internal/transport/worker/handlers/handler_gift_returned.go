@@ -1,11 +1,11 @@
 package workerhandlers
 
 import (
-	"context"
 	"fmt"
 
 	"github.com/ThreeDotsLabs/watermill/message"
 	"github.com/peterparker2005/giftduels/apps/service-gift/internal/service/command"
+	duelevents "github.com/peterparker2005/giftduels/packages/events/duel"
 	"github.com/peterparker2005/giftduels/packages/logger-go"
 	"go.uber.org/zap"
 )
@@ -26,15 +26,18 @@ func NewGiftReturnedHandler(
 }
 
 func (h *GiftReturnedHandler) Handle(msg *message.Message) error {
-	ctx := context.Background()
+	ctx := msg.Context()
 	giftID := string(msg.Payload)
+	// Старые сообщения приходят без duel_id — тогда гифт возвращается без проверки дуэли
+	duelID := msg.Metadata.Get(duelevents.MetadataDuelID)
 
 	h.logger.Info("Processing gift returned event",
 		zap.String("message_id", msg.UUID),
-		zap.String("gift_id", giftID))
+		zap.String("gift_id", giftID),
+		zap.String("duel_id", duelID))
 
 	// Return the gift from game status back to owned status
-	_, err := h.giftStakeCommand.ReturnGiftFromGame(ctx, giftID)
+	_, err := h.giftStakeCommand.ReturnGiftFromGame(ctx, giftID, duelID)
 	if err != nil {
 		h.logger.Error("Failed to return gift from game",
 			zap.String("message_id", msg.UUID),
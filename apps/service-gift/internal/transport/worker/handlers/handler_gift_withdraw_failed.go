@@ -1,7 +1,6 @@
 package workerhandlers
 
 import (
-	"context"
 	"errors"
 	"fmt"
 
@@ -29,7 +28,7 @@ func NewGiftWithdrawFailedHandler(
 }
 
 func (h *GiftWithdrawFailedHandler) Handle(msg *message.Message) error {
-	ctx := context.Background()
+	ctx := msg.Context()
 
 	var ev giftv1.GiftWithdrawFailedEvent
 	if err := proto.Unmarshal(msg.Payload, &ev); err != nil {
@@ -63,7 +62,7 @@ func (h *GiftWithdrawFailedHandler) Handle(msg *message.Message) error {
 	log.Info("Processing gift withdrawal failure, rolling back status")
 
 	// Отменяем вывод подарка - возвращаем статус с withdraw_pending на owned
-	gift, err := h.saga.CancelGiftWithdrawal(ctx, ev.GetGiftId().GetValue())
+	gift, err := h.saga.CancelGiftWithdrawal(ctx, ev.GetGiftId().GetValue(), ev.GetErrorReason())
 	if err != nil {
 		log.Error("Failed to cancel gift withdrawal", zap.Error(err))
 
@@ -0,0 +1,80 @@
+package workerhandlers
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/adapter/pg"
+	"github.com/peterparker2005/giftduels/apps/service-gift/internal/service/saga"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+	giftv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/gift/v1"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+)
+
+// GiftWithdrawUserNotFoundHandler откатывает вывод, если бот не смог найти
+// получателя: подарок возвращается в owned, TON-комиссия — на баланс.
+// Уведомление с инструкцией отправляет giftduels-bot по тому же событию.
+type GiftWithdrawUserNotFoundHandler struct {
+	saga   *saga.WithdrawalSaga
+	logger *logger.Logger
+}
+
+func NewGiftWithdrawUserNotFoundHandler(
+	saga *saga.WithdrawalSaga,
+	logger *logger.Logger,
+) *GiftWithdrawUserNotFoundHandler {
+	return &GiftWithdrawUserNotFoundHandler{
+		saga:   saga,
+		logger: logger,
+	}
+}
+
+func (h *GiftWithdrawUserNotFoundHandler) Handle(msg *message.Message) error {
+	ctx := msg.Context()
+
+	var ev giftv1.GiftWithdrawUserNotFoundEvent
+	if err := proto.Unmarshal(msg.Payload, &ev); err != nil {
+		h.logger.Error(
+			"Failed to unmarshal GiftWithdrawUserNotFoundEvent",
+			zap.Error(err),
+			zap.String("message_id", msg.UUID),
+		)
+		return fmt.Errorf("unmarshal event: %w", err)
+	}
+
+	if ev.GetGiftId().GetValue() == "" {
+		h.logger.Error("Missing GiftId in event", zap.String("message_id", msg.UUID))
+		return errors.New("missing GiftId in event")
+	}
+
+	log := h.logger.With(
+		zap.String("gift_id", ev.GetGiftId().GetValue()),
+		zap.Int64("owner_telegram_id", ev.GetOwnerTelegramId().GetValue()),
+		zap.String("commission_amount", ev.GetCommissionAmount().GetValue()),
+		zap.String("message_id", msg.UUID),
+	)
+
+	log.Info("Owner not reachable by bot, reverting gift withdrawal")
+
+	gift, err := h.saga.RevertUndeliveredWithdrawal(
+		ctx,
+		ev.GetGiftId().GetValue(),
+		ev.GetCommissionAmount().GetValue(),
+	)
+	if err != nil {
+		// подарок уже не в withdraw_pending — откат выполнен другим путём
+		if pg.IsNotFound(err) {
+			log.Warn("Gift is not pending withdrawal, nothing to revert")
+			return nil
+		}
+		log.Error("Failed to revert gift withdrawal", zap.Error(err))
+		return fmt.Errorf("revert gift withdrawal: %w", err)
+	}
+
+	log.Info("Gift withdrawal reverted",
+		zap.String("new_status", string(gift.Status)))
+
+	return nil
+}
@@ -1,8 +1,6 @@
 package workerhandlers
 
 import (
-	"context"
-
 	"github.com/ThreeDotsLabs/watermill/message"
 	"github.com/peterparker2005/giftduels/apps/service-gift/internal/service/command"
 	"github.com/peterparker2005/giftduels/packages/logger-go"
@@ -27,7 +25,7 @@ func NewGiftWithdrawnHandler(
 }
 
 func (h *GiftWithdrawnHandler) Handle(msg *message.Message) error {
-	ctx := context.Background()
+	ctx := msg.Context()
 
 	var event giftv1.GiftWithdrawnEvent
 	if err := proto.Unmarshal(msg.Payload, &event); err != nil {
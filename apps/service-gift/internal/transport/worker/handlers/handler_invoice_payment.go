@@ -1,7 +1,6 @@
 package workerhandlers
 
 import (
-	"context"
 	"errors"
 	"fmt"
 
@@ -29,7 +28,7 @@ func NewInvoicePaymentHandler(
 }
 
 func (h *InvoicePaymentHandler) Handle(msg *message.Message) error {
-	ctx := context.Background()
+	ctx := msg.Context()
 
 	var event telegrambotv1.InvoicePaymentEvent
 	if err := proto.Unmarshal(msg.Payload, &event); err != nil {
@@ -89,7 +88,7 @@ func (h *InvoicePaymentHandler) Handle(msg *message.Message) error {
 	log.Info("completing stars withdrawal", zap.Strings("gift_ids", giftIDs))
 
 	// Завершаем Stars withdrawal
-	gifts, err := h.withdrawalSaga.CompleteStarsWithdrawal(ctx, giftIDs)
+	gifts, err := h.withdrawalSaga.CompleteStarsWithdrawal(ctx, telegramUserID, giftIDs)
 	if err != nil {
 		log.Error("failed to complete stars withdrawal", zap.Error(err))
 		return fmt.Errorf("complete stars withdrawal: %w", err)
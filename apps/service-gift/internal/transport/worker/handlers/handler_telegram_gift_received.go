@@ -46,7 +46,7 @@ func NewTelegramGiftReceivedHandler(
 }
 
 func (h *TelegramGiftReceivedHandler) Handle(msg *message.Message) error {
-	ctx := context.Background()
+	ctx := msg.Context()
 	h.logger.Info("Processing Telegram gift received event", zap.String("message_id", msg.UUID))
 
 	// Parse event
@@ -9,7 +9,9 @@ import (
 	"github.com/peterparker2005/giftduels/apps/service-gift/internal/adapter/amqp"
 	"github.com/peterparker2005/giftduels/apps/service-gift/internal/config"
 	workerhandlers "github.com/peterparker2005/giftduels/apps/service-gift/internal/transport/worker/handlers"
+	"github.com/peterparker2005/giftduels/packages/events"
 	duelevents "github.com/peterparker2005/giftduels/packages/events/duel"
+	giftEvents "github.com/peterparker2005/giftduels/packages/events/gift"
 	telegramEvents "github.com/peterparker2005/giftduels/packages/events/telegram"
 	telegrambotEvents "github.com/peterparker2005/giftduels/packages/events/telegrambot"
 	"github.com/peterparker2005/giftduels/packages/logger-go"
@@ -17,6 +19,18 @@ import (
 	"go.uber.org/zap"
 )
 
+// Имена обработчиков router. Они попадают в заголовки poison-сообщений
+// и используются командой dlq.
+const (
+	HandlerDuelCompleted    = "duel_completed"
+	HandlerTgGiftReceived   = "tg_gift_recv"
+	HandlerGiftWithdrawFail = "gift_withdraw_fail"
+	HandlerInvoicePaid      = "invoice_paid"
+	HandlerGiftWithdrawn    = "gift_withdrawn"
+	HandlerCreateDuelFail   = "create_duel_fail"
+	HandlerGiftUserNotFound = "gift_withdraw_user_not_found"
+)
+
 //nolint:gochecknoglobals // fx module pattern
 var Module = fx.Options(
 	// провайдим инфраструктуру
@@ -30,6 +44,7 @@ var Module = fx.Options(
 		workerhandlers.NewGiftWithdrawnHandler,
 		workerhandlers.NewGiftReturnedHandler,
 		workerhandlers.NewDuelCompletedHandler,
+		workerhandlers.NewGiftWithdrawUserNotFoundHandler,
 	),
 
 	// инициализируем router + forwarder
@@ -47,6 +62,7 @@ func registerHandlers(
 	withdrawnHandler *workerhandlers.GiftWithdrawnHandler,
 	returnedHandler *workerhandlers.GiftReturnedHandler,
 	duelCompletedHandler *workerhandlers.DuelCompletedHandler,
+	userNotFoundHandler *workerhandlers.GiftWithdrawUserNotFoundHandler,
 	router *message.Router,
 	log *logger.Logger,
 ) error {
@@ -66,8 +82,17 @@ func registerHandlers(
 		return err
 	}
 
+	// poison-очередь объявляем заранее, иначе exchange отбросит сообщения
+	giftSub, err := subFac(giftEvents.Config(cfg.ServiceName.String()))
+	if err != nil {
+		return err
+	}
+	if err = events.DeclarePoisonQueue(giftSub, giftEvents.Config(cfg.ServiceName.String())); err != nil {
+		return err
+	}
+
 	router.AddNoPublisherHandler(
-		"duel_completed",
+		HandlerDuelCompleted,
 		duelevents.TopicDuelCompleted.String(),
 		duelSub,
 		duelCompletedHandler.Handle,
@@ -75,31 +100,37 @@ func registerHandlers(
 
 	// регистрируем каждый
 	router.AddNoPublisherHandler(
-		"tg_gift_recv",
+		HandlerTgGiftReceived,
 		telegramEvents.TopicTelegramGiftReceived.String(),
 		telegramSub,
 		tgHandler.Handle,
 	)
 	router.AddNoPublisherHandler(
-		"gift_withdraw_fail",
+		HandlerGiftWithdrawFail,
 		telegramEvents.TopicTelegramGiftWithdrawFailed.String(),
 		telegramSub,
 		failHandler.Handle,
 	)
 	router.AddNoPublisherHandler(
-		"invoice_paid",
+		HandlerGiftUserNotFound,
+		telegramEvents.TopicTelegramGiftWithdrawUserNotFound.String(),
+		telegramSub,
+		userNotFoundHandler.Handle,
+	)
+	router.AddNoPublisherHandler(
+		HandlerInvoicePaid,
 		telegrambotEvents.TopicInvoicePaymentCompleted.String(),
 		telegrambotSub,
 		invHandler.Handle,
 	)
 	router.AddNoPublisherHandler(
-		"gift_withdrawn",
+		HandlerGiftWithdrawn,
 		telegramEvents.TopicTelegramGiftWithdrawn.String(),
 		telegramSub,
 		withdrawnHandler.Handle,
 	)
 	router.AddNoPublisherHandler(
-		"create_duel_fail",
+		HandlerCreateDuelFail,
 		duelevents.TopicDuelCreateFailed.String(),
 		duelSub,
 		returnedHandler.Handle,
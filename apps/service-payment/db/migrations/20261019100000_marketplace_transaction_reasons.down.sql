@@ -0,0 +1,18 @@
+-- Migration: marketplace_transaction_reasons (DOWN)
+-- Created at: 2026-10-19 10:00:00
+-- Description: Rollback for marketplace_transaction_reasons
+
+-- Postgres не умеет удалять значения из enum, поэтому пересоздаём тип
+UPDATE user_transactions SET reason = 'withdraw' WHERE reason = 'purchase';
+UPDATE user_transactions SET reason = 'deposit' WHERE reason = 'sale';
+
+ALTER TYPE transaction_reason RENAME TO transaction_reason_old;
+
+CREATE TYPE transaction_reason AS ENUM (
+	'withdraw', 'refund', 'deposit'
+);
+
+ALTER TABLE user_transactions
+ALTER COLUMN reason TYPE transaction_reason USING reason::text::transaction_reason;
+
+DROP TYPE transaction_reason_old;
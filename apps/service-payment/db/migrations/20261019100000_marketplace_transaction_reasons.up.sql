@@ -0,0 +1,6 @@
+-- Migration: marketplace_transaction_reasons
+-- Created at: 2026-10-19 10:00:00
+-- Description: Add purchase/sale transaction reasons for the gift marketplace
+
+ALTER TYPE transaction_reason ADD VALUE IF NOT EXISTS 'purchase';
+ALTER TYPE transaction_reason ADD VALUE IF NOT EXISTS 'sale';
@@ -0,0 +1,17 @@
+-- Migration: sell_back_transaction_reason (DOWN)
+-- Created at: 2026-10-19 11:00:00
+-- Description: Rollback for sell_back_transaction_reason
+
+-- Postgres не умеет удалять значения из enum, поэтому пересоздаём тип
+UPDATE user_transactions SET reason = 'deposit' WHERE reason = 'sell_back';
+
+ALTER TYPE transaction_reason RENAME TO transaction_reason_old;
+
+CREATE TYPE transaction_reason AS ENUM (
+	'withdraw', 'refund', 'deposit', 'purchase', 'sale'
+);
+
+ALTER TABLE user_transactions
+ALTER COLUMN reason TYPE transaction_reason USING reason::text::transaction_reason;
+
+DROP TYPE transaction_reason_old;
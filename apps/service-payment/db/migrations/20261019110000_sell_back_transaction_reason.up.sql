@@ -0,0 +1,5 @@
+-- Migration: sell_back_transaction_reason
+-- Created at: 2026-10-19 11:00:00
+-- Description: Add sell_back transaction reason for instant gift sell-back
+
+ALTER TYPE transaction_reason ADD VALUE IF NOT EXISTS 'sell_back';
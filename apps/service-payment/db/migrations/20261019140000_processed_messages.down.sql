@@ -0,0 +1,5 @@
+-- Migration: processed_messages (DOWN)
+-- Created at: 2026-10-19 14:00:00
+-- Description: Rollback for processed_messages
+
+DROP TABLE IF EXISTS processed_messages;
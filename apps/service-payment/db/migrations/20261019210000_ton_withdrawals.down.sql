@@ -0,0 +1,20 @@
+-- Migration: ton_withdrawals (DOWN)
+-- Created at: 2026-10-19 21:00:00
+-- Description: Rollback for ton_withdrawals
+
+DROP TABLE IF EXISTS ton_withdrawals;
+DROP TYPE IF EXISTS ton_withdrawal_status;
+
+-- Postgres не умеет удалять значения из enum, поэтому пересоздаём тип
+UPDATE user_transactions SET reason = 'withdraw' WHERE reason = 'ton_withdrawal';
+
+ALTER TYPE transaction_reason RENAME TO transaction_reason_old;
+
+CREATE TYPE transaction_reason AS ENUM (
+	'withdraw', 'refund', 'deposit', 'purchase', 'sale', 'sell_back'
+);
+
+ALTER TABLE user_transactions
+ALTER COLUMN reason TYPE transaction_reason USING reason::text::transaction_reason;
+
+DROP TYPE transaction_reason_old;
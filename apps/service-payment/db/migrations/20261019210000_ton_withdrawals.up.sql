@@ -0,0 +1,26 @@
+-- Migration: ton_withdrawals
+-- Created at: 2026-10-19 21:00:00
+-- Description: TON withdrawals from user balance to external wallets
+
+ALTER TYPE transaction_reason ADD VALUE IF NOT EXISTS 'ton_withdrawal';
+
+CREATE TYPE ton_withdrawal_status AS ENUM ('pending', 'sent', 'confirmed', 'failed');
+
+CREATE TABLE ton_withdrawals (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    telegram_user_id BIGINT NOT NULL,
+    destination TEXT NOT NULL,
+    amount_nano BIGINT NOT NULL CHECK (amount_nano > 0),
+    status ton_withdrawal_status NOT NULL DEFAULT 'pending',
+    msg_hash TEXT,
+    wallet_seqno BIGINT,
+    valid_until TIMESTAMPTZ,
+    tx_hash TEXT,
+    tx_lt BIGINT,
+    failure_reason TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX ix_ton_withdrawals_status_created_at ON ton_withdrawals (status, created_at);
+CREATE INDEX ix_ton_withdrawals_telegram_user_id ON ton_withdrawals (telegram_user_id);
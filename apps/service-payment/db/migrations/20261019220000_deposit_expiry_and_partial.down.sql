@@ -0,0 +1,25 @@
+-- Migration: deposit_expiry_and_partial (DOWN)
+-- Created at: 2026-10-19 22:00:00
+-- Description: Rollback for deposit_expiry_and_partial
+
+DROP INDEX IF EXISTS ix_deposits_status_expires_at;
+
+ALTER TABLE deposits
+    DROP COLUMN IF EXISTS received_amount_nano,
+    DROP COLUMN IF EXISTS sender_address,
+    DROP COLUMN IF EXISTS received_at,
+    DROP COLUMN IF EXISTS expired_at;
+
+-- Postgres не умеет удалять значения из enum, поэтому пересоздаём тип
+UPDATE deposits SET status = 'received' WHERE status = 'partial';
+
+ALTER TYPE deposit_status RENAME TO deposit_status_old;
+
+CREATE TYPE deposit_status AS ENUM ('pending', 'received', 'confirmed', 'expired');
+
+ALTER TABLE deposits ALTER COLUMN status DROP DEFAULT;
+ALTER TABLE deposits
+ALTER COLUMN status TYPE deposit_status USING status::text::deposit_status;
+ALTER TABLE deposits ALTER COLUMN status SET DEFAULT 'pending';
+
+DROP TYPE deposit_status_old;
@@ -0,0 +1,13 @@
+-- Migration: deposit_expiry_and_partial
+-- Created at: 2026-10-19 22:00:00
+-- Description: Partial deposit status, received amount audit fields and expiry index
+
+ALTER TYPE deposit_status ADD VALUE IF NOT EXISTS 'partial';
+
+ALTER TABLE deposits
+    ADD COLUMN received_amount_nano BIGINT,
+    ADD COLUMN sender_address TEXT,
+    ADD COLUMN received_at TIMESTAMPTZ,
+    ADD COLUMN expired_at TIMESTAMPTZ;
+
+CREATE INDEX ix_deposits_status_expires_at ON deposits (status, expires_at);
@@ -0,0 +1,9 @@
+-- Migration: deposit_confirmations (DOWN)
+-- Created at: 2026-10-19 23:00:00
+-- Description: Rollback for deposit_confirmations
+
+DROP INDEX IF EXISTS ix_deposits_received_mc_seqno;
+
+ALTER TABLE deposits
+    DROP COLUMN IF EXISTS received_mc_seqno,
+    DROP COLUMN IF EXISTS confirmed_at;
@@ -0,0 +1,10 @@
+-- Migration: deposit_confirmations
+-- Created at: 2026-10-19 23:00:00
+-- Description: Track masterchain seqno of received deposits to credit them after confirmation depth
+
+ALTER TABLE deposits
+    ADD COLUMN received_mc_seqno BIGINT,
+    ADD COLUMN confirmed_at TIMESTAMPTZ;
+
+CREATE INDEX ix_deposits_received_mc_seqno ON deposits (received_mc_seqno)
+WHERE status = 'received';
@@ -0,0 +1,20 @@
+-- Migration: multi_currency (DOWN)
+-- Created at: 2026-10-19 23:30:00
+-- Description: Rollback for multi_currency
+
+-- Балансы и история в других валютах не переносятся в TON
+DELETE FROM user_balances WHERE currency <> 'TON';
+DELETE FROM user_transactions WHERE currency <> 'TON';
+DELETE FROM deposits WHERE currency <> 'TON';
+
+ALTER TABLE user_balances
+    DROP CONSTRAINT IF EXISTS user_balances_telegram_user_id_currency_unique;
+
+ALTER TABLE user_balances
+    ADD CONSTRAINT user_balances_telegram_user_id_unique UNIQUE (telegram_user_id);
+
+ALTER TABLE user_balances DROP COLUMN IF EXISTS currency;
+ALTER TABLE user_transactions DROP COLUMN IF EXISTS currency;
+ALTER TABLE deposits DROP COLUMN IF EXISTS currency;
+
+DROP TYPE IF EXISTS currency;
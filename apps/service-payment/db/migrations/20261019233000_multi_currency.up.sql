@@ -0,0 +1,21 @@
+-- Migration: multi_currency
+-- Created at: 2026-10-19 23:30:00
+-- Description: Per-currency user balances, transactions and deposits for jetton (USDT) support
+
+CREATE TYPE currency AS ENUM ('TON', 'USDT');
+
+ALTER TABLE user_balances
+    ADD COLUMN currency currency NOT NULL DEFAULT 'TON';
+
+ALTER TABLE user_balances
+    DROP CONSTRAINT user_balances_telegram_user_id_unique;
+
+ALTER TABLE user_balances
+    ADD CONSTRAINT user_balances_telegram_user_id_currency_unique UNIQUE (telegram_user_id, currency);
+
+ALTER TABLE user_transactions
+    ADD COLUMN currency currency NOT NULL DEFAULT 'TON';
+
+-- amount_nano депозита хранится в минимальных единицах его валюты
+ALTER TABLE deposits
+    ADD COLUMN currency currency NOT NULL DEFAULT 'TON';
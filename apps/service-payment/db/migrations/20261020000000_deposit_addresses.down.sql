@@ -0,0 +1,11 @@
+-- Migration: deposit_addresses (DOWN)
+-- Created at: 2026-10-20 00:00:00
+-- Description: Rollback for deposit_addresses
+
+DROP TABLE IF EXISTS unattributed_deposits;
+DROP TYPE IF EXISTS unattributed_deposit_status;
+
+ALTER TABLE deposits DROP COLUMN IF EXISTS deposit_address;
+
+DROP TABLE IF EXISTS deposit_addresses;
+DROP SEQUENCE IF EXISTS deposit_address_subwallet_seq;
@@ -0,0 +1,50 @@
+-- Migration: deposit_addresses
+-- Created at: 2026-10-20 00:00:00
+-- Description: Per-user derived deposit addresses and unattributed treasury deposits
+
+CREATE SEQUENCE deposit_address_subwallet_seq START WITH 1;
+
+CREATE TABLE deposit_addresses (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    telegram_user_id BIGINT NOT NULL,
+    subwallet_id BIGINT NOT NULL,
+    -- user-friendly non-bounceable адрес, который видит пользователь
+    address TEXT NOT NULL,
+    -- raw-адрес (workchain:hex) для сопоставления отправителей
+    raw_address TEXT NOT NULL,
+    last_lt BIGINT NOT NULL DEFAULT 0,
+    sweep_pending BOOLEAN NOT NULL DEFAULT false,
+    last_swept_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT ux_deposit_addresses_telegram_user_id UNIQUE (telegram_user_id),
+    CONSTRAINT ux_deposit_addresses_subwallet_id UNIQUE (subwallet_id),
+    CONSTRAINT ux_deposit_addresses_raw_address UNIQUE (raw_address)
+);
+
+CREATE INDEX ix_deposit_addresses_sweep_pending ON deposit_addresses (updated_at)
+WHERE sweep_pending;
+
+ALTER TABLE deposits
+    ADD COLUMN deposit_address TEXT;
+
+CREATE TYPE unattributed_deposit_status AS ENUM ('pending', 'assigned');
+
+CREATE TABLE unattributed_deposits (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tx_hash TEXT NOT NULL,
+    tx_lt BIGINT NOT NULL,
+    sender_address TEXT NOT NULL,
+    currency currency NOT NULL,
+    amount_nano BIGINT NOT NULL,
+    -- комментарий платежа, если он был, но не совпал ни с одним депозитом
+    comment TEXT,
+    status unattributed_deposit_status NOT NULL DEFAULT 'pending',
+    telegram_user_id BIGINT,
+    assigned_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT ux_unattributed_deposits_tx_hash UNIQUE (tx_hash)
+);
+
+CREATE INDEX ix_unattributed_deposits_status_created_at ON unattributed_deposits (status, created_at);
@@ -0,0 +1,16 @@
+-- Migration: opening_balance_transaction_reason (DOWN)
+-- Created at: 2026-10-21 00:00:00
+-- Description: Rollback for opening_balance_transaction_reason
+
+-- Postgres не умеет удалять значения из enum, поэтому пересоздаём тип.
+-- Записи opening_balance есть только в журнале, который к этому моменту удалён.
+ALTER TYPE transaction_reason RENAME TO transaction_reason_old;
+
+CREATE TYPE transaction_reason AS ENUM (
+	'withdraw', 'refund', 'deposit', 'purchase', 'sale', 'sell_back', 'ton_withdrawal'
+);
+
+ALTER TABLE user_transactions
+ALTER COLUMN reason TYPE transaction_reason USING reason::text::transaction_reason;
+
+DROP TYPE transaction_reason_old;
@@ -0,0 +1,5 @@
+-- Migration: opening_balance_transaction_reason
+-- Created at: 2026-10-21 00:00:00
+-- Description: Add opening_balance reason for ledger entries migrated from cached balances
+
+ALTER TYPE transaction_reason ADD VALUE IF NOT EXISTS 'opening_balance';
@@ -0,0 +1,13 @@
+-- Migration: ledger (DOWN)
+-- Created at: 2026-10-21 00:01:00
+-- Description: Rollback for ledger
+
+DROP TRIGGER IF EXISTS tr_user_transactions_immutable ON user_transactions;
+
+ALTER TABLE user_transactions DROP COLUMN IF EXISTS entry_id;
+
+DROP TABLE IF EXISTS ledger_postings;
+DROP TABLE IF EXISTS ledger_entries;
+DROP TABLE IF EXISTS ledger_accounts;
+DROP FUNCTION IF EXISTS forbid_ledger_mutation();
+DROP TYPE IF EXISTS ledger_account_type;
@@ -0,0 +1,97 @@
+-- Migration: ledger
+-- Created at: 2026-10-21 00:01:00
+-- Description: Immutable double-entry ledger backing user balances
+
+CREATE TYPE ledger_account_type AS ENUM ('user', 'treasury', 'fees', 'house');
+
+CREATE TABLE ledger_accounts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    type ledger_account_type NOT NULL,
+    -- 0 для системных счетов
+    telegram_user_id BIGINT NOT NULL DEFAULT 0,
+    currency currency NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT ux_ledger_accounts_type_telegram_user_id_currency UNIQUE (type, telegram_user_id, currency)
+);
+
+CREATE TABLE ledger_entries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    reason transaction_reason NOT NULL,
+    currency currency NOT NULL,
+    -- пользователь, чей баланс затронут проводкой
+    telegram_user_id BIGINT,
+    metadata JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX ix_ledger_entries_telegram_user_id ON ledger_entries (telegram_user_id);
+
+-- Сумма проводок каждой записи равна нулю: плюс — рост обязательств
+-- перед владельцем счёта, минус — его уменьшение.
+CREATE TABLE ledger_postings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    entry_id UUID NOT NULL REFERENCES ledger_entries (id),
+    account_id UUID NOT NULL REFERENCES ledger_accounts (id),
+    amount NUMERIC NOT NULL CHECK (amount <> 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX ix_ledger_postings_entry_id ON ledger_postings (entry_id);
+CREATE INDEX ix_ledger_postings_account_id ON ledger_postings (account_id);
+
+ALTER TABLE user_transactions
+    ADD COLUMN entry_id UUID REFERENCES ledger_entries (id);
+
+-- Журнал неизменяем: исправления делаются новыми записями
+CREATE FUNCTION forbid_ledger_mutation() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION '% is append-only', TG_TABLE_NAME;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER tr_ledger_entries_immutable
+    BEFORE UPDATE OR DELETE ON ledger_entries
+    FOR EACH ROW EXECUTE FUNCTION forbid_ledger_mutation();
+
+CREATE TRIGGER tr_ledger_postings_immutable
+    BEFORE UPDATE OR DELETE ON ledger_postings
+    FOR EACH ROW EXECUTE FUNCTION forbid_ledger_mutation();
+
+CREATE TRIGGER tr_user_transactions_immutable
+    BEFORE UPDATE OR DELETE ON user_transactions
+    FOR EACH ROW EXECUTE FUNCTION forbid_ledger_mutation();
+
+-- Системные счета во всех валютах
+INSERT INTO ledger_accounts (type, currency)
+SELECT t, c
+FROM unnest(enum_range(NULL::ledger_account_type)) AS t,
+     unnest(enum_range(NULL::currency)) AS c
+WHERE t <> 'user';
+
+INSERT INTO ledger_accounts (type, telegram_user_id, currency)
+SELECT 'user', telegram_user_id, currency
+FROM user_balances;
+
+-- Текущие балансы переносятся в журнал входящими остатками за счёт house
+INSERT INTO ledger_entries (reason, currency, telegram_user_id)
+SELECT 'opening_balance', currency, telegram_user_id
+FROM user_balances
+WHERE ton_amount <> 0;
+
+INSERT INTO ledger_postings (entry_id, account_id, amount)
+SELECT e.id, a.id, b.ton_amount
+FROM ledger_entries e
+JOIN user_balances b
+  ON b.telegram_user_id = e.telegram_user_id AND b.currency = e.currency
+JOIN ledger_accounts a
+  ON a.type = 'user' AND a.telegram_user_id = e.telegram_user_id AND a.currency = e.currency
+WHERE e.reason = 'opening_balance';
+
+INSERT INTO ledger_postings (entry_id, account_id, amount)
+SELECT e.id, a.id, -b.ton_amount
+FROM ledger_entries e
+JOIN user_balances b
+  ON b.telegram_user_id = e.telegram_user_id AND b.currency = e.currency
+JOIN ledger_accounts a
+  ON a.type = 'house' AND a.telegram_user_id = 0 AND a.currency = e.currency
+WHERE e.reason = 'opening_balance';
@@ -0,0 +1,5 @@
+-- Migration: idempotency_keys (DOWN)
+-- Created at: 2026-10-22 00:00:00
+-- Description: Rollback for idempotency_keys
+
+DROP TABLE IF EXISTS idempotency_keys;
@@ -0,0 +1,17 @@
+-- Migration: idempotency_keys
+-- Created at: 2026-10-22 00:00:00
+-- Description: Idempotency keys with stored results for balance-mutating RPCs
+
+CREATE TABLE idempotency_keys (
+    key TEXT PRIMARY KEY,
+    -- операция, для которой выдан ключ: spend_user_balance, add_user_balance
+    operation TEXT NOT NULL,
+    -- отпечаток параметров исходного запроса
+    request_hash TEXT NOT NULL,
+    telegram_user_id BIGINT NOT NULL,
+    -- баланс после исходной операции, возвращается при повторе
+    result_amount NUMERIC NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX ix_idempotency_keys_created_at ON idempotency_keys (created_at);
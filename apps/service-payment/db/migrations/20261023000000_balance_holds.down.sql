@@ -0,0 +1,11 @@
+-- Migration: balance_holds (DOWN)
+-- Created at: 2026-10-23 00:00:00
+-- Description: Rollback for balance_holds
+
+DROP TABLE IF EXISTS balance_holds;
+
+DROP TYPE IF EXISTS balance_hold_status;
+
+ALTER TABLE user_balances
+    DROP CONSTRAINT IF EXISTS ck_user_balances_held_amount,
+    DROP COLUMN IF EXISTS held_amount;
@@ -0,0 +1,28 @@
+-- Migration: balance_holds
+-- Created at: 2026-10-23 00:00:00
+-- Description: Balance holds reserving funds until capture, release or expiry
+
+-- сумма активных холдов; доступно к списанию ton_amount - held_amount
+ALTER TABLE user_balances
+    ADD COLUMN held_amount NUMERIC(20, 2) NOT NULL DEFAULT 0,
+    ADD CONSTRAINT ck_user_balances_held_amount CHECK (held_amount >= 0 AND held_amount <= ton_amount);
+
+CREATE TYPE balance_hold_status AS ENUM ('active', 'captured', 'released', 'expired');
+
+CREATE TABLE balance_holds (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    telegram_user_id BIGINT NOT NULL,
+    currency currency NOT NULL,
+    amount NUMERIC(20, 2) NOT NULL CHECK (amount > 0),
+    -- причина транзакции, с которой холд будет списан
+    reason transaction_reason NOT NULL,
+    metadata JSONB,
+    status balance_hold_status NOT NULL DEFAULT 'active',
+    expires_at TIMESTAMPTZ NOT NULL,
+    settled_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX ix_balance_holds_telegram_user_id ON balance_holds (telegram_user_id);
+CREATE INDEX ix_balance_holds_active_expires_at ON balance_holds (expires_at) WHERE status = 'active';
@@ -0,0 +1,5 @@
+-- Migration: user_transactions_history_index (DOWN)
+-- Created at: 2026-10-24 00:00:00
+-- Description: Rollback for user_transactions_history_index
+
+DROP INDEX IF EXISTS ix_user_transactions_telegram_user_id_created_at;
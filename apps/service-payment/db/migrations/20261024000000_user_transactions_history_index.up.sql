@@ -0,0 +1,6 @@
+-- Migration: user_transactions_history_index
+-- Created at: 2026-10-24 00:00:00
+-- Description: Index for filtered transaction history ordered by time
+
+CREATE INDEX ix_user_transactions_telegram_user_id_created_at
+    ON user_transactions (telegram_user_id, created_at DESC);
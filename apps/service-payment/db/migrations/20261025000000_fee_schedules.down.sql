@@ -0,0 +1,9 @@
+-- Migration: fee_schedules (DOWN)
+-- Created at: 2026-10-25 00:00:00
+-- Description: Rollback for fee_schedules
+
+DROP TABLE IF EXISTS fee_schedule_promotions;
+
+DROP TABLE IF EXISTS fee_schedule_tiers;
+
+DROP TABLE IF EXISTS fee_schedules;
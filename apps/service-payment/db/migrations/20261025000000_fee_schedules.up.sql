@@ -0,0 +1,62 @@
+-- Migration: fee_schedules
+-- Created at: 2026-10-25 00:00:00
+-- Description: Versioned withdrawal commission schedules with tiers and zero-fee promotions
+
+-- расписания не изменяются: новые правила публикуются следующей версией
+CREATE TABLE fee_schedules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    version BIGINT NOT NULL,
+    -- стоимость одной звезды в TON
+    ton_per_star NUMERIC(20, 9) NOT NULL,
+    effective_from TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    comment TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT ux_fee_schedules_version UNIQUE (version),
+    CONSTRAINT ck_fee_schedules_ton_per_star CHECK (ton_per_star > 0)
+);
+
+CREATE INDEX ix_fee_schedules_effective_from ON fee_schedules (effective_from);
+
+-- ступени проверяются по возрастанию priority, применяется первая подходящая
+CREATE TABLE fee_schedule_tiers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    schedule_id UUID NOT NULL REFERENCES fee_schedules (id) ON DELETE CASCADE,
+    priority INTEGER NOT NULL,
+    -- NULL — любая коллекция
+    collection TEXT,
+    -- полуинтервал [min_price, max_price) по цене подарка, NULL — без границы
+    min_price NUMERIC(20, 2),
+    max_price NUMERIC(20, 2),
+    -- доля стоимости подарка, взимаемая в звёздах
+    rate NUMERIC(10, 6) NOT NULL,
+    min_stars INTEGER NOT NULL,
+    max_stars INTEGER NOT NULL,
+    CONSTRAINT ux_fee_schedule_tiers_schedule_id_priority UNIQUE (schedule_id, priority),
+    CONSTRAINT ck_fee_schedule_tiers_rate CHECK (rate >= 0),
+    CONSTRAINT ck_fee_schedule_tiers_stars CHECK (min_stars >= 0 AND min_stars <= max_stars),
+    CONSTRAINT ck_fee_schedule_tiers_price CHECK (min_price IS NULL OR max_price IS NULL OR min_price < max_price)
+);
+
+-- окна, в которые вывод бесплатен
+CREATE TABLE fee_schedule_promotions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    schedule_id UUID NOT NULL REFERENCES fee_schedules (id) ON DELETE CASCADE,
+    -- NULL — любая коллекция
+    collection TEXT,
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    CONSTRAINT ck_fee_schedule_promotions_window CHECK (starts_at < ends_at)
+);
+
+CREATE INDEX ix_fee_schedule_tiers_schedule_id ON fee_schedule_tiers (schedule_id);
+CREATE INDEX ix_fee_schedule_promotions_schedule_id ON fee_schedule_promotions (schedule_id);
+
+-- первая версия повторяет прежние константы сервиса: 0.2678 TON за 50 звёзд,
+-- 15% от стоимости; верхняя граница в 1 звезду перекрывала минимум в 25
+WITH schedule AS (
+    INSERT INTO fee_schedules (version, ton_per_star, effective_from, comment)
+    VALUES (1, 0.005356, '1970-01-01T00:00:00Z', 'initial schedule')
+    RETURNING id
+)
+INSERT INTO fee_schedule_tiers (schedule_id, priority, rate, min_stars, max_stars)
+SELECT id, 0, 0.15, 1, 1 FROM schedule;
@@ -13,21 +13,39 @@ SELECT
   telegram_user_id,
   ton_amount,
   created_at,
-  updated_at
+  updated_at,
+  currency,
+  held_amount
 FROM user_balances
-WHERE telegram_user_id = $1;
+WHERE telegram_user_id = $1
+  AND currency = $2;
+
+-- name: GetUserBalances :many
+SELECT
+  id,
+  telegram_user_id,
+  ton_amount,
+  created_at,
+  updated_at,
+  currency,
+  held_amount
+FROM user_balances
+WHERE telegram_user_id = $1
+ORDER BY currency;
 
 -- name: SpendUserBalance :one
 UPDATE user_balances AS b
    SET ton_amount = b.ton_amount - $2
  WHERE b.telegram_user_id = $1
-   AND b.ton_amount      >= $2
+   AND b.currency        = $3
+   -- зарезервированное холдами списать нельзя
+   AND b.ton_amount - b.held_amount >= $2
 RETURNING *;
 
 -- name: UpsertUserBalance :one
-INSERT INTO user_balances (telegram_user_id, ton_amount)
-VALUES ($1, $2)
-ON CONFLICT (telegram_user_id)
+INSERT INTO user_balances (telegram_user_id, ton_amount, currency)
+VALUES ($1, $2, $3)
+ON CONFLICT (telegram_user_id, currency)
 DO UPDATE
   SET ton_amount = user_balances.ton_amount + EXCLUDED.ton_amount
 RETURNING *;
@@ -37,18 +55,17 @@ INSERT INTO user_transactions (
     telegram_user_id,
     amount,
     reason,
-    metadata
+    metadata,
+    currency,
+    entry_id
 ) VALUES (
-    $1, $2, $3, $4
+    $1, $2, $3, $4, $5, $6
 )
 RETURNING *;
 
--- name: DeleteTransaction :exec
-DELETE FROM user_transactions WHERE id = $1;
-
 -- name: CreateDeposit :one
-INSERT INTO deposits (telegram_user_id, amount_nano, payload, expires_at)
-VALUES ($1, $2, $3, $4)
+INSERT INTO deposits (telegram_user_id, amount_nano, payload, expires_at, currency)
+VALUES ($1, $2, $3, $4, $5)
 RETURNING *;
 
 -- name: GetDepositByPayload :one
@@ -61,10 +78,47 @@ SET
     status = 'received',
     tx_hash = $2,
     tx_lt = $3,
+    received_amount_nano = $4,
+    sender_address = $5,
+    received_mc_seqno = $6,
+    received_at = now(),
+    updated_at = now()
+WHERE id = $1
+  AND status IN ('pending', 'expired')
+RETURNING *;
+
+-- name: GetDepositsToConfirm :many
+SELECT * FROM deposits
+WHERE status = 'received'
+  AND received_mc_seqno <= $1
+ORDER BY received_at
+LIMIT $2;
+
+-- name: ConfirmDeposit :one
+UPDATE deposits
+SET
+    status = $2,
+    confirmed_at = now(),
     updated_at = now()
 WHERE id = $1
+  AND status = 'received'
 RETURNING *;
 
+-- name: ExpireDeposits :execrows
+UPDATE deposits
+SET
+    status = 'expired',
+    expired_at = now(),
+    updated_at = now()
+WHERE id IN (
+    SELECT d.id FROM deposits d
+    WHERE d.status = 'pending'
+      AND d.expires_at < $1
+    ORDER BY d.expires_at
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+);
+
 -- name: GetTonCursor :one
 SELECT last_lt
 FROM ton_cursors
@@ -80,10 +134,344 @@ ON CONFLICT (network, wallet_address) DO UPDATE
 
 -- name: GetUserTransactions :many
 SELECT * FROM user_transactions
-WHERE telegram_user_id = $1
-ORDER BY created_at DESC
-LIMIT $2 OFFSET $3;
+WHERE telegram_user_id = sqlc.arg(telegram_user_id)
+  AND (cardinality(sqlc.arg(reasons)::text[]) = 0 OR reason::text = ANY(sqlc.arg(reasons)::text[]))
+  AND (sqlc.narg(created_from)::timestamp IS NULL OR created_at >= sqlc.narg(created_from)::timestamp)
+  AND (sqlc.narg(created_to)::timestamp IS NULL OR created_at < sqlc.narg(created_to)::timestamp)
+  AND (sqlc.narg(min_amount)::numeric IS NULL OR ABS(amount) >= sqlc.narg(min_amount)::numeric)
+  AND (sqlc.narg(max_amount)::numeric IS NULL OR ABS(amount) <= sqlc.narg(max_amount)::numeric)
+  AND (
+    sqlc.narg(query)::text IS NULL
+    OR metadata->'gift'->>'gift_id' = sqlc.narg(query)::text
+    OR metadata->'gift'->>'title' ILIKE '%' || sqlc.narg(query)::text || '%'
+    OR metadata->'gift'->>'slug' ILIKE '%' || sqlc.narg(query)::text || '%'
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(limit_count) OFFSET sqlc.arg(offset_count);
 
 -- name: GetUserTransactionsCount :one
 SELECT COUNT(*) FROM user_transactions
-WHERE telegram_user_id = $1;
\ No newline at end of file
+WHERE telegram_user_id = sqlc.arg(telegram_user_id)
+  AND (cardinality(sqlc.arg(reasons)::text[]) = 0 OR reason::text = ANY(sqlc.arg(reasons)::text[]))
+  AND (sqlc.narg(created_from)::timestamp IS NULL OR created_at >= sqlc.narg(created_from)::timestamp)
+  AND (sqlc.narg(created_to)::timestamp IS NULL OR created_at < sqlc.narg(created_to)::timestamp)
+  AND (sqlc.narg(min_amount)::numeric IS NULL OR ABS(amount) >= sqlc.narg(min_amount)::numeric)
+  AND (sqlc.narg(max_amount)::numeric IS NULL OR ABS(amount) <= sqlc.narg(max_amount)::numeric)
+  AND (
+    sqlc.narg(query)::text IS NULL
+    OR metadata->'gift'->>'gift_id' = sqlc.narg(query)::text
+    OR metadata->'gift'->>'title' ILIKE '%' || sqlc.narg(query)::text || '%'
+    OR metadata->'gift'->>'slug' ILIKE '%' || sqlc.narg(query)::text || '%'
+  );
+
+-- name: GetUserTransactionTotals :many
+SELECT
+    reason,
+    currency,
+    SUM(amount)::numeric AS amount,
+    COUNT(*) AS transactions_count
+FROM user_transactions
+WHERE telegram_user_id = sqlc.arg(telegram_user_id)
+  AND (cardinality(sqlc.arg(reasons)::text[]) = 0 OR reason::text = ANY(sqlc.arg(reasons)::text[]))
+  AND (sqlc.narg(created_from)::timestamp IS NULL OR created_at >= sqlc.narg(created_from)::timestamp)
+  AND (sqlc.narg(created_to)::timestamp IS NULL OR created_at < sqlc.narg(created_to)::timestamp)
+  AND (sqlc.narg(min_amount)::numeric IS NULL OR ABS(amount) >= sqlc.narg(min_amount)::numeric)
+  AND (sqlc.narg(max_amount)::numeric IS NULL OR ABS(amount) <= sqlc.narg(max_amount)::numeric)
+  AND (
+    sqlc.narg(query)::text IS NULL
+    OR metadata->'gift'->>'gift_id' = sqlc.narg(query)::text
+    OR metadata->'gift'->>'title' ILIKE '%' || sqlc.narg(query)::text || '%'
+    OR metadata->'gift'->>'slug' ILIKE '%' || sqlc.narg(query)::text || '%'
+  )
+GROUP BY reason, currency
+ORDER BY reason, currency;
+
+-- name: CreateTonWithdrawal :one
+INSERT INTO ton_withdrawals (telegram_user_id, destination, amount_nano)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetTonWithdrawalsByStatus :many
+SELECT * FROM ton_withdrawals
+WHERE status = $1
+ORDER BY created_at
+LIMIT $2;
+
+-- name: MarkTonWithdrawalsSent :execrows
+UPDATE ton_withdrawals
+SET
+    status = 'sent',
+    msg_hash = sqlc.arg(msg_hash),
+    wallet_seqno = sqlc.arg(wallet_seqno),
+    valid_until = sqlc.arg(valid_until),
+    updated_at = now()
+WHERE id = ANY(sqlc.arg(ids)::uuid[])
+  AND status = 'pending';
+
+-- name: ConfirmTonWithdrawal :execrows
+UPDATE ton_withdrawals
+SET
+    status = 'confirmed',
+    tx_hash = $2,
+    tx_lt = $3,
+    updated_at = now()
+WHERE id = $1
+  AND status = 'sent';
+
+-- name: FailTonWithdrawal :one
+UPDATE ton_withdrawals
+SET
+    status = 'failed',
+    failure_reason = $2,
+    updated_at = now()
+WHERE id = $1
+  AND status IN ('pending', 'sent')
+RETURNING *;
+
+-- name: NextDepositSubwalletID :one
+SELECT nextval('deposit_address_subwallet_seq')::bigint;
+
+-- name: CreateDepositAddress :one
+INSERT INTO deposit_addresses (telegram_user_id, subwallet_id, address, raw_address)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (telegram_user_id) DO NOTHING
+RETURNING *;
+
+-- name: GetDepositAddressByUser :one
+SELECT * FROM deposit_addresses
+WHERE telegram_user_id = $1;
+
+-- name: GetDepositAddressByRawAddress :one
+SELECT * FROM deposit_addresses
+WHERE raw_address = $1;
+
+-- name: ListDepositAddresses :many
+SELECT * FROM deposit_addresses
+ORDER BY created_at, id
+LIMIT $1 OFFSET $2;
+
+-- name: UpdateDepositAddressCursor :exec
+UPDATE deposit_addresses
+SET
+    last_lt = sqlc.arg(last_lt),
+    sweep_pending = sweep_pending OR sqlc.arg(sweep_pending)::boolean,
+    updated_at = now()
+WHERE id = sqlc.arg(id);
+
+-- name: GetDepositAddressesToSweep :many
+SELECT * FROM deposit_addresses
+WHERE sweep_pending
+ORDER BY updated_at
+LIMIT $1;
+
+-- name: MarkDepositAddressSwept :exec
+UPDATE deposit_addresses
+SET
+    sweep_pending = false,
+    last_swept_at = now(),
+    updated_at = now()
+WHERE id = $1;
+
+-- name: CreateReceivedDeposit :one
+INSERT INTO deposits (
+    telegram_user_id,
+    status,
+    amount_nano,
+    payload,
+    expires_at,
+    tx_hash,
+    tx_lt,
+    received_amount_nano,
+    sender_address,
+    received_mc_seqno,
+    received_at,
+    currency,
+    deposit_address
+) VALUES (
+    sqlc.arg(telegram_user_id),
+    'received',
+    sqlc.arg(amount_nano),
+    sqlc.arg(payload),
+    now(),
+    sqlc.arg(tx_hash),
+    sqlc.arg(tx_lt),
+    sqlc.arg(amount_nano),
+    sqlc.arg(sender_address),
+    sqlc.arg(received_mc_seqno),
+    now(),
+    sqlc.arg(currency),
+    sqlc.arg(deposit_address)
+)
+ON CONFLICT (payload) DO NOTHING
+RETURNING *;
+
+-- name: CreateUnattributedDeposit :execrows
+INSERT INTO unattributed_deposits (tx_hash, tx_lt, sender_address, currency, amount_nano, comment)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (tx_hash) DO NOTHING;
+
+-- name: ListUnattributedDeposits :many
+SELECT * FROM unattributed_deposits
+WHERE status = $1
+ORDER BY created_at
+LIMIT $2 OFFSET $3;
+
+-- name: AssignUnattributedDeposit :one
+UPDATE unattributed_deposits
+SET
+    status = 'assigned',
+    telegram_user_id = $2,
+    assigned_at = now(),
+    updated_at = now()
+WHERE id = $1
+  AND status = 'pending'
+RETURNING *;
+
+-- name: GetOrCreateLedgerAccount :one
+INSERT INTO ledger_accounts (type, telegram_user_id, currency)
+VALUES ($1, $2, $3)
+ON CONFLICT (type, telegram_user_id, currency)
+DO UPDATE SET type = EXCLUDED.type
+RETURNING id;
+
+-- name: CreateLedgerEntry :one
+INSERT INTO ledger_entries (reason, currency, telegram_user_id, metadata)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: CreateLedgerPosting :exec
+INSERT INTO ledger_postings (entry_id, account_id, amount)
+VALUES ($1, $2, $3);
+
+-- name: GetUnbalancedLedgerEntries :many
+SELECT
+    e.id,
+    e.reason,
+    e.currency,
+    e.created_at,
+    COUNT(p.id) AS postings_count,
+    COALESCE(SUM(p.amount), 0)::numeric AS total
+FROM ledger_entries e
+LEFT JOIN ledger_postings p ON p.entry_id = e.id
+GROUP BY e.id
+HAVING COUNT(p.id) < 2 OR COALESCE(SUM(p.amount), 0) <> 0
+ORDER BY e.created_at
+LIMIT $1;
+
+-- name: GetLedgerBalanceMismatches :many
+SELECT
+    COALESCE(b.telegram_user_id, l.telegram_user_id)::bigint AS telegram_user_id,
+    COALESCE(b.currency, l.currency)::currency AS currency,
+    COALESCE(b.ton_amount, 0)::numeric AS cached_amount,
+    COALESCE(l.amount, 0)::numeric AS ledger_amount
+FROM user_balances b
+FULL OUTER JOIN (
+    SELECT a.telegram_user_id, a.currency, COALESCE(SUM(p.amount), 0) AS amount
+    FROM ledger_accounts a
+    LEFT JOIN ledger_postings p ON p.account_id = a.id
+    WHERE a.type = 'user'
+    GROUP BY a.telegram_user_id, a.currency
+) l ON l.telegram_user_id = b.telegram_user_id AND l.currency = b.currency
+WHERE COALESCE(b.ton_amount, 0) <> COALESCE(l.amount, 0)
+ORDER BY 1, 2
+LIMIT $1;
+
+-- name: GetSystemLedgerBalances :many
+SELECT
+    a.type,
+    a.currency,
+    COALESCE(SUM(p.amount), 0)::numeric AS amount
+FROM ledger_accounts a
+LEFT JOIN ledger_postings p ON p.account_id = a.id
+WHERE a.type <> 'user'
+GROUP BY a.type, a.currency
+ORDER BY a.currency, a.type;
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys
+WHERE key = $1;
+
+-- name: CreateIdempotencyKey :execrows
+INSERT INTO idempotency_keys (key, operation, request_hash, telegram_user_id, result_amount)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (key) DO NOTHING;
+
+-- name: HoldUserBalance :one
+UPDATE user_balances AS b
+   SET held_amount = b.held_amount + $2
+ WHERE b.telegram_user_id = $1
+   AND b.currency        = $3
+   AND b.ton_amount - b.held_amount >= $2
+RETURNING *;
+
+-- name: ReleaseUserBalanceHold :one
+UPDATE user_balances AS b
+   SET held_amount = b.held_amount - $2
+ WHERE b.telegram_user_id = $1
+   AND b.currency        = $3
+RETURNING *;
+
+-- name: CreateBalanceHold :one
+INSERT INTO balance_holds (telegram_user_id, currency, amount, reason, metadata, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetBalanceHoldForUpdate :one
+SELECT * FROM balance_holds
+WHERE id = $1
+FOR UPDATE;
+
+-- name: SettleBalanceHold :one
+UPDATE balance_holds
+SET
+    status = $2,
+    settled_at = now(),
+    updated_at = now()
+WHERE id = $1
+  AND status = 'active'
+RETURNING *;
+
+-- name: GetExpiredBalanceHolds :many
+SELECT * FROM balance_holds
+WHERE status = 'active'
+  AND expires_at <= $1
+ORDER BY expires_at
+LIMIT $2
+FOR UPDATE SKIP LOCKED;
+
+-- name: GetActiveFeeSchedule :one
+SELECT * FROM fee_schedules
+WHERE effective_from <= $1
+ORDER BY version DESC
+LIMIT 1;
+
+-- name: GetFeeScheduleByVersion :one
+SELECT * FROM fee_schedules
+WHERE version = $1;
+
+-- name: GetFeeScheduleTiers :many
+SELECT * FROM fee_schedule_tiers
+WHERE schedule_id = $1
+ORDER BY priority;
+
+-- name: GetFeeSchedulePromotions :many
+SELECT * FROM fee_schedule_promotions
+WHERE schedule_id = $1
+ORDER BY starts_at;
+
+-- name: CreateFeeSchedule :one
+INSERT INTO fee_schedules (version, ton_per_star, effective_from, comment)
+VALUES (
+    (SELECT COALESCE(MAX(version), 0) + 1 FROM fee_schedules),
+    $1, $2, $3
+)
+RETURNING *;
+
+-- name: CreateFeeScheduleTier :exec
+INSERT INTO fee_schedule_tiers (
+    schedule_id, priority, collection, min_price, max_price, rate, min_stars, max_stars
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- name: CreateFeeSchedulePromotion :exec
+INSERT INTO fee_schedule_promotions (schedule_id, collection, starts_at, ends_at)
+VALUES ($1, $2, $3, $4);
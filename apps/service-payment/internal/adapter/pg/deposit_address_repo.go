@@ -0,0 +1,148 @@
+package pg
+
+import (
+	"context"
+
+	"github.com/ccoveille/go-safecast"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/adapter/pg/sqlc"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/ton"
+	"github.com/peterparker2005/giftduels/packages/inbox"
+)
+
+type DepositAddressRepository struct {
+	pool *pgxpool.Pool
+	q    *sqlc.Queries
+}
+
+func NewDepositAddressRepository(pool *pgxpool.Pool) ton.DepositAddressRepository {
+	return &DepositAddressRepository{
+		q:    sqlc.New(inbox.NewDB(pool)),
+		pool: pool,
+	}
+}
+
+func (r *DepositAddressRepository) WithTx(tx pgx.Tx) ton.DepositAddressRepository {
+	return &DepositAddressRepository{
+		q:    r.q.WithTx(tx),
+		pool: r.pool,
+	}
+}
+
+func (r *DepositAddressRepository) NextSubwalletID(ctx context.Context) (uint64, error) {
+	id, err := r.q.NextDepositSubwalletID(ctx)
+	if err != nil {
+		return 0, MapPGError(err)
+	}
+	return safecast.ToUint64(id)
+}
+
+func (r *DepositAddressRepository) CreateDepositAddress(
+	ctx context.Context,
+	params *ton.CreateDepositAddressParams,
+) (*ton.DepositAddress, error) {
+	addr, err := r.q.CreateDepositAddress(ctx, sqlc.CreateDepositAddressParams{
+		TelegramUserID: params.TelegramUserID,
+		SubwalletID:    int64(params.SubwalletID),
+		Address:        params.Address,
+		RawAddress:     params.RawAddress,
+	})
+	if err != nil {
+		if IsNotFound(MapPGError(err)) {
+			return nil, ton.ErrDepositAddressExists
+		}
+		return nil, MapPGError(err)
+	}
+	return ToDepositAddressDomain(addr), nil
+}
+
+func (r *DepositAddressRepository) GetDepositAddressByUser(
+	ctx context.Context,
+	telegramUserID int64,
+) (*ton.DepositAddress, error) {
+	addr, err := r.q.GetDepositAddressByUser(ctx, telegramUserID)
+	if err != nil {
+		if IsNotFound(MapPGError(err)) {
+			return nil, ton.ErrDepositAddressNotFound
+		}
+		return nil, MapPGError(err)
+	}
+	return ToDepositAddressDomain(addr), nil
+}
+
+func (r *DepositAddressRepository) GetDepositAddressByRawAddress(
+	ctx context.Context,
+	rawAddress string,
+) (*ton.DepositAddress, error) {
+	addr, err := r.q.GetDepositAddressByRawAddress(ctx, rawAddress)
+	if err != nil {
+		if IsNotFound(MapPGError(err)) {
+			return nil, ton.ErrDepositAddressNotFound
+		}
+		return nil, MapPGError(err)
+	}
+	return ToDepositAddressDomain(addr), nil
+}
+
+func (r *DepositAddressRepository) ListDepositAddresses(
+	ctx context.Context,
+	limit, offset int32,
+) ([]*ton.DepositAddress, error) {
+	rows, err := r.q.ListDepositAddresses(ctx, sqlc.ListDepositAddressesParams{
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	return toDepositAddressesDomain(rows), nil
+}
+
+func (r *DepositAddressRepository) UpdateCursor(
+	ctx context.Context,
+	id string,
+	lastLT uint64,
+	sweepPending bool,
+) error {
+	pgID, err := pgUUID(id)
+	if err != nil {
+		return err
+	}
+	lastLtInt, err := safecast.ToInt64(lastLT)
+	if err != nil {
+		return err
+	}
+	return MapPGError(r.q.UpdateDepositAddressCursor(ctx, sqlc.UpdateDepositAddressCursorParams{
+		LastLt:       lastLtInt,
+		SweepPending: sweepPending,
+		ID:           pgID,
+	}))
+}
+
+func (r *DepositAddressRepository) GetDepositAddressesToSweep(
+	ctx context.Context,
+	limit int32,
+) ([]*ton.DepositAddress, error) {
+	rows, err := r.q.GetDepositAddressesToSweep(ctx, limit)
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	return toDepositAddressesDomain(rows), nil
+}
+
+func (r *DepositAddressRepository) MarkSwept(ctx context.Context, id string) error {
+	pgID, err := pgUUID(id)
+	if err != nil {
+		return err
+	}
+	return MapPGError(r.q.MarkDepositAddressSwept(ctx, pgID))
+}
+
+func toDepositAddressesDomain(rows []sqlc.DepositAddress) []*ton.DepositAddress {
+	addrs := make([]*ton.DepositAddress, len(rows))
+	for i, a := range rows {
+		addrs[i] = ToDepositAddressDomain(a)
+	}
+	return addrs
+}
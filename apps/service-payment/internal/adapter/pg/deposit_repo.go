@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/ccoveille/go-safecast"
 	"github.com/jackc/pgx/v5"
@@ -11,6 +12,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/peterparker2005/giftduels/apps/service-payment/internal/adapter/pg/sqlc"
 	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/ton"
+	"github.com/peterparker2005/giftduels/packages/inbox"
 	"github.com/peterparker2005/giftduels/packages/logger-go"
 )
 
@@ -22,7 +24,7 @@ type DepositRepository struct {
 
 func NewDepositRepository(pool *pgxpool.Pool, logger *logger.Logger) ton.DepositRepository {
 	return &DepositRepository{
-		q:      sqlc.New(pool),
+		q:      sqlc.New(inbox.NewDB(pool)),
 		pool:   pool,
 		logger: logger,
 	}
@@ -49,6 +51,7 @@ func (r *DepositRepository) CreateDeposit(
 		AmountNano:     amountNano,
 		Payload:        params.Payload,
 		ExpiresAt:      pgtype.Timestamptz{Time: params.ExpiresAt, Valid: true},
+		Currency:       sqlc.Currency(params.Currency),
 	})
 	if err != nil {
 		return nil, err
@@ -62,6 +65,41 @@ func (r *DepositRepository) GetDepositByPayload(
 ) (*ton.Deposit, error) {
 	deposit, err := r.q.GetDepositByPayload(ctx, payload)
 	if err != nil {
+		if IsNotFound(MapPGError(err)) {
+			return nil, ton.ErrDepositNotFound
+		}
+		return nil, err
+	}
+	return ToDepositDomain(deposit), nil
+}
+
+func (r *DepositRepository) CreateReceivedDeposit(
+	ctx context.Context,
+	params *ton.CreateReceivedDepositParams,
+) (*ton.Deposit, error) {
+	amountNano, err := safecast.ToInt64(params.AmountNano)
+	if err != nil {
+		return nil, err
+	}
+	txLtInt, err := safecast.ToInt64(params.TxLt)
+	if err != nil {
+		return nil, err
+	}
+	deposit, err := r.q.CreateReceivedDeposit(ctx, sqlc.CreateReceivedDepositParams{
+		TelegramUserID:  params.TelegramUserID,
+		AmountNano:      amountNano,
+		Payload:         params.Payload(),
+		TxHash:          pgtype.Text{String: params.TxHash, Valid: true},
+		TxLt:            pgtype.Int8{Int64: txLtInt, Valid: true},
+		SenderAddress:   pgtype.Text{String: params.SenderAddress, Valid: params.SenderAddress != ""},
+		ReceivedMcSeqno: pgtype.Int8{Int64: int64(params.ReceivedMcSeqno), Valid: true},
+		Currency:        sqlc.Currency(params.Currency),
+		DepositAddress:  pgtype.Text{String: params.DepositAddress, Valid: true},
+	})
+	if err != nil {
+		if IsNotFound(MapPGError(err)) {
+			return nil, ton.ErrDepositAlreadyProcessed
+		}
 		return nil, err
 	}
 	return ToDepositDomain(deposit), nil
@@ -80,17 +118,42 @@ func (r *DepositRepository) SetDepositTransaction(
 	if err != nil {
 		return nil, err
 	}
+	receivedNano, err := safecast.ToInt64(params.ReceivedAmountNano)
+	if err != nil {
+		return nil, err
+	}
 	deposit, err := r.q.SetDepositTransaction(ctx, sqlc.SetDepositTransactionParams{
-		ID:     id,
-		TxHash: pgtype.Text{String: params.TxHash, Valid: true},
-		TxLt:   pgtype.Int8{Int64: txLtInt, Valid: true},
+		ID:                 id,
+		TxHash:             pgtype.Text{String: params.TxHash, Valid: params.TxHash != ""},
+		TxLt:               pgtype.Int8{Int64: txLtInt, Valid: true},
+		ReceivedAmountNano: pgtype.Int8{Int64: receivedNano, Valid: true},
+		SenderAddress:      pgtype.Text{String: params.SenderAddress, Valid: params.SenderAddress != ""},
+		ReceivedMcSeqno:    pgtype.Int8{Int64: int64(params.ReceivedMcSeqno), Valid: true},
 	})
 	if err != nil {
+		if IsNotFound(MapPGError(err)) {
+			return nil, ton.ErrDepositAlreadyProcessed
+		}
 		return nil, err
 	}
 	return ToDepositDomain(deposit), nil
 }
 
+func (r *DepositRepository) ExpireDeposits(
+	ctx context.Context,
+	now time.Time,
+	limit int32,
+) (int64, error) {
+	expired, err := r.q.ExpireDeposits(ctx, sqlc.ExpireDepositsParams{
+		ExpiresAt: pgtype.Timestamptz{Time: now, Valid: true},
+		Limit:     limit,
+	})
+	if err != nil {
+		return 0, MapPGError(err)
+	}
+	return expired, nil
+}
+
 func (r *DepositRepository) GetCursor(
 	ctx context.Context,
 	network, walletAddress string,
@@ -135,3 +198,112 @@ func (r *DepositRepository) UpsertCursor(
 		LastLt:        lastLtInt,
 	})
 }
+
+func (r *DepositRepository) GetDepositsToConfirm(
+	ctx context.Context,
+	maxMcSeqno uint32,
+	limit int32,
+) ([]*ton.Deposit, error) {
+	rows, err := r.q.GetDepositsToConfirm(ctx, sqlc.GetDepositsToConfirmParams{
+		ReceivedMcSeqno: pgtype.Int8{Int64: int64(maxMcSeqno), Valid: true},
+		Limit:           limit,
+	})
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	deposits := make([]*ton.Deposit, len(rows))
+	for i, d := range rows {
+		deposits[i] = ToDepositDomain(d)
+	}
+	return deposits, nil
+}
+
+func (r *DepositRepository) ConfirmDeposit(
+	ctx context.Context,
+	id string,
+	status ton.DepositStatus,
+) (*ton.Deposit, error) {
+	pgID, err := pgUUID(id)
+	if err != nil {
+		return nil, err
+	}
+	deposit, err := r.q.ConfirmDeposit(ctx, sqlc.ConfirmDepositParams{
+		ID:     pgID,
+		Status: sqlc.DepositStatus(status),
+	})
+	if err != nil {
+		if IsNotFound(MapPGError(err)) {
+			return nil, ton.ErrDepositAlreadyProcessed
+		}
+		return nil, MapPGError(err)
+	}
+	return ToDepositDomain(deposit), nil
+}
+
+func (r *DepositRepository) CreateUnattributedDeposit(
+	ctx context.Context,
+	params *ton.CreateUnattributedDepositParams,
+) (bool, error) {
+	txLtInt, err := safecast.ToInt64(params.TxLt)
+	if err != nil {
+		return false, err
+	}
+	amountNano, err := safecast.ToInt64(params.AmountNano)
+	if err != nil {
+		return false, err
+	}
+	created, err := r.q.CreateUnattributedDeposit(ctx, sqlc.CreateUnattributedDepositParams{
+		TxHash:        params.TxHash,
+		TxLt:          txLtInt,
+		SenderAddress: params.SenderAddress,
+		Currency:      sqlc.Currency(params.Currency),
+		AmountNano:    amountNano,
+		Comment:       pgtype.Text{String: params.Comment, Valid: params.Comment != ""},
+	})
+	if err != nil {
+		return false, MapPGError(err)
+	}
+	return created > 0, nil
+}
+
+func (r *DepositRepository) ListUnattributedDeposits(
+	ctx context.Context,
+	status ton.UnattributedDepositStatus,
+	limit, offset int32,
+) ([]*ton.UnattributedDeposit, error) {
+	rows, err := r.q.ListUnattributedDeposits(ctx, sqlc.ListUnattributedDepositsParams{
+		Status: sqlc.UnattributedDepositStatus(status),
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	deposits := make([]*ton.UnattributedDeposit, len(rows))
+	for i, d := range rows {
+		deposits[i] = ToUnattributedDepositDomain(d)
+	}
+	return deposits, nil
+}
+
+func (r *DepositRepository) AssignUnattributedDeposit(
+	ctx context.Context,
+	id string,
+	telegramUserID int64,
+) (*ton.UnattributedDeposit, error) {
+	pgID, err := pgUUID(id)
+	if err != nil {
+		return nil, ton.ErrUnattributedDepositNotFound
+	}
+	deposit, err := r.q.AssignUnattributedDeposit(ctx, sqlc.AssignUnattributedDepositParams{
+		ID:             pgID,
+		TelegramUserID: pgtype.Int8{Int64: telegramUserID, Valid: true},
+	})
+	if err != nil {
+		if IsNotFound(MapPGError(err)) {
+			return nil, ton.ErrUnattributedDepositNotFound
+		}
+		return nil, MapPGError(err)
+	}
+	return ToUnattributedDepositDomain(deposit), nil
+}
@@ -0,0 +1,147 @@
+package pg
+
+import (
+	"context"
+	"time"
+
+	"github.com/ccoveille/go-safecast"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/adapter/pg/sqlc"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/payment"
+	"github.com/peterparker2005/giftduels/packages/inbox"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+	"github.com/peterparker2005/giftduels/packages/tonamount-go"
+)
+
+type FeeScheduleRepository struct {
+	q      *sqlc.Queries
+	logger *logger.Logger
+}
+
+func NewFeeScheduleRepository(pool *pgxpool.Pool, logger *logger.Logger) payment.FeeScheduleRepository {
+	return &FeeScheduleRepository{
+		q:      sqlc.New(inbox.NewDB(pool)),
+		logger: logger,
+	}
+}
+
+func (r *FeeScheduleRepository) WithTx(tx pgx.Tx) payment.FeeScheduleRepository {
+	return &FeeScheduleRepository{
+		q:      r.q.WithTx(tx),
+		logger: r.logger,
+	}
+}
+
+func (r *FeeScheduleRepository) GetActiveFeeSchedule(
+	ctx context.Context,
+	at time.Time,
+) (*payment.FeeSchedule, error) {
+	s, err := r.q.GetActiveFeeSchedule(ctx, pgtype.Timestamptz{Time: at, Valid: true})
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	return r.withRules(ctx, s)
+}
+
+func (r *FeeScheduleRepository) GetFeeScheduleByVersion(
+	ctx context.Context,
+	version int64,
+) (*payment.FeeSchedule, error) {
+	s, err := r.q.GetFeeScheduleByVersion(ctx, version)
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	return r.withRules(ctx, s)
+}
+
+// withRules дочитывает ступени и промо-окна расписания.
+func (r *FeeScheduleRepository) withRules(
+	ctx context.Context,
+	s sqlc.FeeSchedule,
+) (*payment.FeeSchedule, error) {
+	tiers, err := r.q.GetFeeScheduleTiers(ctx, s.ID)
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	promotions, err := r.q.GetFeeSchedulePromotions(ctx, s.ID)
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	return ToFeeScheduleDomain(s, tiers, promotions), nil
+}
+
+func (r *FeeScheduleRepository) CreateFeeSchedule(
+	ctx context.Context,
+	schedule *payment.FeeSchedule,
+) (*payment.FeeSchedule, error) {
+	tonPerStar, err := pgNumeric(schedule.TonPerStar.String())
+	if err != nil {
+		return nil, err
+	}
+	s, err := r.q.CreateFeeSchedule(ctx, sqlc.CreateFeeScheduleParams{
+		TonPerStar:    tonPerStar,
+		EffectiveFrom: pgtype.Timestamptz{Time: schedule.EffectiveFrom, Valid: true},
+		Comment:       pgtype.Text{String: schedule.Comment, Valid: schedule.Comment != ""},
+	})
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+
+	for _, t := range schedule.Tiers {
+		params, paramsErr := feeTierParams(s.ID, t)
+		if paramsErr != nil {
+			return nil, paramsErr
+		}
+		if err = r.q.CreateFeeScheduleTier(ctx, params); err != nil {
+			return nil, MapPGError(err)
+		}
+	}
+	for _, p := range schedule.Promotions {
+		if err = r.q.CreateFeeSchedulePromotion(ctx, sqlc.CreateFeeSchedulePromotionParams{
+			ScheduleID: s.ID,
+			Collection: pgtype.Text{String: p.Collection, Valid: p.Collection != ""},
+			StartsAt:   pgtype.Timestamptz{Time: p.StartsAt, Valid: true},
+			EndsAt:     pgtype.Timestamptz{Time: p.EndsAt, Valid: true},
+		}); err != nil {
+			return nil, MapPGError(err)
+		}
+	}
+
+	return r.withRules(ctx, s)
+}
+
+func feeTierParams(scheduleID pgtype.UUID, t payment.FeeTier) (sqlc.CreateFeeScheduleTierParams, error) {
+	params := sqlc.CreateFeeScheduleTierParams{
+		ScheduleID: scheduleID,
+		Priority:   t.Priority,
+		Collection: pgtype.Text{String: t.Collection, Valid: t.Collection != ""},
+	}
+	var err error
+	if params.MinPrice, err = pgNumeric(optionalAmount(t.MinPrice)); err != nil {
+		return params, err
+	}
+	if params.MaxPrice, err = pgNumeric(optionalAmount(t.MaxPrice)); err != nil {
+		return params, err
+	}
+	if params.Rate, err = pgNumeric(t.Rate.String()); err != nil {
+		return params, err
+	}
+	if params.MinStars, err = safecast.ToInt32(t.MinStars); err != nil {
+		return params, err
+	}
+	if params.MaxStars, err = safecast.ToInt32(t.MaxStars); err != nil {
+		return params, err
+	}
+	return params, nil
+}
+
+// optionalAmount возвращает пустую строку для nil, что pgNumeric
+// превращает в NULL.
+func optionalAmount(a *tonamount.TonAmount) string {
+	if a == nil {
+		return ""
+	}
+	return a.String()
+}
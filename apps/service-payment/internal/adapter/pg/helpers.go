@@ -3,9 +3,13 @@ package pg
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/adapter/pg/sqlc"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/payment"
+	"github.com/shopspring/decimal"
 )
 
 func pgUUID(id string) (pgtype.UUID, error) {
@@ -50,3 +54,56 @@ func fromPgNumeric(n pgtype.Numeric) (string, error) {
 		return fmt.Sprint(v), nil
 	}
 }
+
+func decimalFromPgNumeric(n pgtype.Numeric) (decimal.Decimal, error) {
+	s, err := fromPgNumeric(n)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return decimal.NewFromString(s)
+}
+
+//nolint:gochecknoglobals // stateless replacer
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// transactionFilterArgs переводит фильтр истории транзакций в параметры
+// запросов. Запросы списка, количества и итогов используют одни условия.
+func transactionFilterArgs(
+	telegramUserID int64,
+	filter *payment.TransactionFilter,
+) (sqlc.GetUserTransactionsCountParams, error) {
+	args := sqlc.GetUserTransactionsCountParams{
+		TelegramUserID: telegramUserID,
+		Reasons:        []string{},
+	}
+	if filter == nil {
+		return args, nil
+	}
+	for _, reason := range filter.Reasons {
+		args.Reasons = append(args.Reasons, string(reason))
+	}
+	if filter.From != nil {
+		args.CreatedFrom = pgtype.Timestamp{Time: filter.From.UTC(), Valid: true}
+	}
+	if filter.To != nil {
+		args.CreatedTo = pgtype.Timestamp{Time: filter.To.UTC(), Valid: true}
+	}
+	if filter.MinAmount != nil {
+		n, err := pgNumeric(filter.MinAmount.String())
+		if err != nil {
+			return args, err
+		}
+		args.MinAmount = n
+	}
+	if filter.MaxAmount != nil {
+		n, err := pgNumeric(filter.MaxAmount.String())
+		if err != nil {
+			return args, err
+		}
+		args.MaxAmount = n
+	}
+	if filter.Query != "" {
+		args.Query = pgtype.Text{String: likeEscaper.Replace(filter.Query), Valid: true}
+	}
+	return args, nil
+}
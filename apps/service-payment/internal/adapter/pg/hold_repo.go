@@ -0,0 +1,138 @@
+package pg
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/adapter/pg/sqlc"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/payment"
+	"github.com/peterparker2005/giftduels/packages/inbox"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+	"github.com/peterparker2005/giftduels/packages/tonamount-go"
+)
+
+type HoldRepository struct {
+	q      *sqlc.Queries
+	logger *logger.Logger
+}
+
+func NewHoldRepository(pool *pgxpool.Pool, logger *logger.Logger) payment.HoldRepository {
+	return &HoldRepository{
+		q:      sqlc.New(inbox.NewDB(pool)),
+		logger: logger,
+	}
+}
+
+func (r *HoldRepository) WithTx(tx pgx.Tx) payment.HoldRepository {
+	return &HoldRepository{
+		q:      r.q.WithTx(tx),
+		logger: r.logger,
+	}
+}
+
+func (r *HoldRepository) ReserveBalance(
+	ctx context.Context,
+	telegramUserID int64,
+	currency payment.Currency,
+	amount *tonamount.TonAmount,
+) (*payment.Balance, error) {
+	held, err := pgNumeric(amount.String())
+	if err != nil {
+		return nil, err
+	}
+	b, err := r.q.HoldUserBalance(ctx, sqlc.HoldUserBalanceParams{
+		TelegramUserID: telegramUserID,
+		HeldAmount:     held,
+		Currency:       sqlc.Currency(currency),
+	})
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	return ToBalanceDomain(b), nil
+}
+
+func (r *HoldRepository) UnreserveBalance(
+	ctx context.Context,
+	telegramUserID int64,
+	currency payment.Currency,
+	amount *tonamount.TonAmount,
+) (*payment.Balance, error) {
+	held, err := pgNumeric(amount.String())
+	if err != nil {
+		return nil, err
+	}
+	b, err := r.q.ReleaseUserBalanceHold(ctx, sqlc.ReleaseUserBalanceHoldParams{
+		TelegramUserID: telegramUserID,
+		HeldAmount:     held,
+		Currency:       sqlc.Currency(currency),
+	})
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	return ToBalanceDomain(b), nil
+}
+
+func (r *HoldRepository) CreateHold(ctx context.Context, params *payment.CreateHoldParams) (*payment.Hold, error) {
+	amount, err := pgNumeric(params.Amount.String())
+	if err != nil {
+		return nil, err
+	}
+	h, err := r.q.CreateBalanceHold(ctx, sqlc.CreateBalanceHoldParams{
+		TelegramUserID: params.TelegramUserID,
+		Currency:       sqlc.Currency(params.Currency),
+		Amount:         amount,
+		Reason:         sqlc.TransactionReason(params.Reason),
+		Metadata:       params.Metadata,
+		ExpiresAt:      pgtype.Timestamptz{Time: params.ExpiresAt, Valid: true},
+	})
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	return ToHoldDomain(h), nil
+}
+
+func (r *HoldRepository) GetHoldForUpdate(ctx context.Context, id uuid.UUID) (*payment.Hold, error) {
+	h, err := r.q.GetBalanceHoldForUpdate(ctx, pgtype.UUID{Bytes: id, Valid: true})
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	return ToHoldDomain(h), nil
+}
+
+func (r *HoldRepository) SettleHold(
+	ctx context.Context,
+	id uuid.UUID,
+	status payment.HoldStatus,
+) (*payment.Hold, error) {
+	h, err := r.q.SettleBalanceHold(ctx, sqlc.SettleBalanceHoldParams{
+		ID:     pgtype.UUID{Bytes: id, Valid: true},
+		Status: sqlc.BalanceHoldStatus(status),
+	})
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	return ToHoldDomain(h), nil
+}
+
+func (r *HoldRepository) GetExpiredHolds(
+	ctx context.Context,
+	now time.Time,
+	limit int32,
+) ([]*payment.Hold, error) {
+	rows, err := r.q.GetExpiredBalanceHolds(ctx, sqlc.GetExpiredBalanceHoldsParams{
+		ExpiresAt: pgtype.Timestamptz{Time: now, Valid: true},
+		Limit:     limit,
+	})
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	holds := make([]*payment.Hold, 0, len(rows))
+	for _, h := range rows {
+		holds = append(holds, ToHoldDomain(h))
+	}
+	return holds, nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/ccoveille/go-safecast"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/peterparker2005/giftduels/apps/service-payment/internal/adapter/pg/sqlc"
 	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/payment"
 	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/ton"
@@ -22,10 +23,22 @@ func ToBalanceDomain(b sqlc.UserBalance) *payment.Balance {
 		panic(err)
 	}
 
+	heldAmountStr, err := fromPgNumeric(b.HeldAmount)
+	if err != nil {
+		panic(err)
+	}
+
+	heldAmount, err := tonamount.NewTonAmountFromString(heldAmountStr)
+	if err != nil {
+		panic(err)
+	}
+
 	return &payment.Balance{
 		ID:             b.ID.String(),
 		TelegramUserID: b.TelegramUserID,
+		Currency:       payment.Currency(b.Currency),
 		TonAmount:      tonAmount,
+		HeldAmount:     heldAmount,
 		CreatedAt:      b.CreatedAt.Time,
 		UpdatedAt:      b.UpdatedAt.Time,
 	}
@@ -51,10 +64,11 @@ func ToDepositDomain(d sqlc.Deposit) *ton.Deposit {
 		panic(err)
 	}
 
-	return &ton.Deposit{
+	deposit := &ton.Deposit{
 		ID:             d.ID.Bytes,
 		TelegramUserID: d.TelegramUserID,
 		Status:         ton.DepositStatus(d.Status),
+		Currency:       payment.Currency(d.Currency),
 		AmountNano:     amountNano,
 		Payload:        d.Payload,
 		ExpiresAt:      d.ExpiresAt.Time,
@@ -63,6 +77,268 @@ func ToDepositDomain(d sqlc.Deposit) *ton.Deposit {
 		CreatedAt:      d.CreatedAt.Time,
 		UpdatedAt:      d.UpdatedAt.Time,
 	}
+	if d.ReceivedAmountNano.Valid {
+		received, castErr := safecast.ToUint64(d.ReceivedAmountNano.Int64)
+		if castErr != nil {
+			panic(castErr)
+		}
+		deposit.ReceivedAmountNano = &received
+	}
+	if d.SenderAddress.Valid {
+		deposit.SenderAddress = &d.SenderAddress.String
+	}
+	if d.ReceivedMcSeqno.Valid {
+		seqno, castErr := safecast.ToUint32(d.ReceivedMcSeqno.Int64)
+		if castErr != nil {
+			panic(castErr)
+		}
+		deposit.ReceivedMcSeqno = &seqno
+	}
+	if d.ReceivedAt.Valid {
+		deposit.ReceivedAt = &d.ReceivedAt.Time
+	}
+	if d.ConfirmedAt.Valid {
+		deposit.ConfirmedAt = &d.ConfirmedAt.Time
+	}
+	if d.ExpiredAt.Valid {
+		deposit.ExpiredAt = &d.ExpiredAt.Time
+	}
+	if d.DepositAddress.Valid {
+		deposit.DepositAddress = &d.DepositAddress.String
+	}
+	return deposit
+}
+
+func ToDepositAddressDomain(a sqlc.DepositAddress) *ton.DepositAddress {
+	subwalletID, err := safecast.ToUint32(a.SubwalletID)
+	if err != nil {
+		panic(err)
+	}
+	lastLT, err := safecast.ToUint64(a.LastLt)
+	if err != nil {
+		panic(err)
+	}
+
+	addr := &ton.DepositAddress{
+		ID:             a.ID.Bytes,
+		TelegramUserID: a.TelegramUserID,
+		SubwalletID:    subwalletID,
+		Address:        a.Address,
+		RawAddress:     a.RawAddress,
+		LastLT:         lastLT,
+		SweepPending:   a.SweepPending,
+		CreatedAt:      a.CreatedAt.Time,
+		UpdatedAt:      a.UpdatedAt.Time,
+	}
+	if a.LastSweptAt.Valid {
+		addr.LastSweptAt = &a.LastSweptAt.Time
+	}
+	return addr
+}
+
+func ToUnattributedDepositDomain(u sqlc.UnattributedDeposit) *ton.UnattributedDeposit {
+	txLt, err := safecast.ToUint64(u.TxLt)
+	if err != nil {
+		panic(err)
+	}
+	amountNano, err := safecast.ToUint64(u.AmountNano)
+	if err != nil {
+		panic(err)
+	}
+
+	deposit := &ton.UnattributedDeposit{
+		ID:            u.ID.Bytes,
+		TxHash:        u.TxHash,
+		TxLt:          txLt,
+		SenderAddress: u.SenderAddress,
+		Currency:      payment.Currency(u.Currency),
+		AmountNano:    amountNano,
+		Status:        ton.UnattributedDepositStatus(u.Status),
+		CreatedAt:     u.CreatedAt.Time,
+		UpdatedAt:     u.UpdatedAt.Time,
+	}
+	if u.Comment.Valid {
+		deposit.Comment = &u.Comment.String
+	}
+	if u.TelegramUserID.Valid {
+		deposit.TelegramUserID = &u.TelegramUserID.Int64
+	}
+	if u.AssignedAt.Valid {
+		deposit.AssignedAt = &u.AssignedAt.Time
+	}
+	return deposit
+}
+
+func ToWithdrawalDomain(w sqlc.TonWithdrawal) *ton.Withdrawal {
+	amountNano, err := safecast.ToUint64(w.AmountNano)
+	if err != nil {
+		panic(err)
+	}
+
+	withdrawal := &ton.Withdrawal{
+		ID:             w.ID.Bytes,
+		TelegramUserID: w.TelegramUserID,
+		Destination:    w.Destination,
+		AmountNano:     amountNano,
+		Status:         ton.WithdrawalStatus(w.Status),
+		CreatedAt:      w.CreatedAt.Time,
+		UpdatedAt:      w.UpdatedAt.Time,
+	}
+	if w.MsgHash.Valid {
+		withdrawal.MsgHash = &w.MsgHash.String
+	}
+	if w.WalletSeqno.Valid {
+		seqno, castErr := safecast.ToUint32(w.WalletSeqno.Int64)
+		if castErr != nil {
+			panic(castErr)
+		}
+		withdrawal.WalletSeqno = &seqno
+	}
+	if w.ValidUntil.Valid {
+		withdrawal.ValidUntil = &w.ValidUntil.Time
+	}
+	if w.TxHash.Valid {
+		withdrawal.TxHash = &w.TxHash.String
+	}
+	if w.TxLt.Valid {
+		txLt, castErr := safecast.ToUint64(w.TxLt.Int64)
+		if castErr != nil {
+			panic(castErr)
+		}
+		withdrawal.TxLt = &txLt
+	}
+	if w.FailureReason.Valid {
+		withdrawal.FailureReason = &w.FailureReason.String
+	}
+	return withdrawal
+}
+
+func ToHoldDomain(h sqlc.BalanceHold) *payment.Hold {
+	amountStr, err := fromPgNumeric(h.Amount)
+	if err != nil {
+		panic(err)
+	}
+	amount, err := tonamount.NewTonAmountFromString(amountStr)
+	if err != nil {
+		panic(err)
+	}
+
+	hold := &payment.Hold{
+		ID:             h.ID.Bytes,
+		TelegramUserID: h.TelegramUserID,
+		Currency:       payment.Currency(h.Currency),
+		Amount:         amount,
+		Reason:         payment.TransactionReason(h.Reason),
+		Status:         payment.HoldStatus(h.Status),
+		ExpiresAt:      h.ExpiresAt.Time,
+		CreatedAt:      h.CreatedAt.Time,
+		UpdatedAt:      h.UpdatedAt.Time,
+	}
+	if h.Metadata != nil {
+		m := &payment.TransactionMetadata{}
+		if err = json.Unmarshal(h.Metadata, m); err == nil {
+			hold.Metadata = m
+		}
+	}
+	if h.SettledAt.Valid {
+		hold.SettledAt = &h.SettledAt.Time
+	}
+	return hold
+}
+
+func ToIdempotencyKeyDomain(k sqlc.IdempotencyKey) *payment.IdempotencyKey {
+	amountStr, err := fromPgNumeric(k.ResultAmount)
+	if err != nil {
+		panic(err)
+	}
+	amount, err := tonamount.NewTonAmountFromString(amountStr)
+	if err != nil {
+		panic(err)
+	}
+
+	return &payment.IdempotencyKey{
+		Key:            k.Key,
+		Operation:      payment.IdempotencyOperation(k.Operation),
+		RequestHash:    k.RequestHash,
+		TelegramUserID: k.TelegramUserID,
+		ResultAmount:   amount,
+		CreatedAt:      k.CreatedAt.Time,
+	}
+}
+
+func ToFeeScheduleDomain(
+	s sqlc.FeeSchedule,
+	tiers []sqlc.FeeScheduleTier,
+	promotions []sqlc.FeeSchedulePromotion,
+) *payment.FeeSchedule {
+	tonPerStar, err := decimalFromPgNumeric(s.TonPerStar)
+	if err != nil {
+		panic(err)
+	}
+
+	schedule := &payment.FeeSchedule{
+		ID:            s.ID.Bytes,
+		Version:       s.Version,
+		TonPerStar:    tonPerStar,
+		EffectiveFrom: s.EffectiveFrom.Time,
+		Comment:       s.Comment.String,
+		Tiers:         make([]payment.FeeTier, 0, len(tiers)),
+		Promotions:    make([]payment.FeePromotion, 0, len(promotions)),
+		CreatedAt:     s.CreatedAt.Time,
+	}
+	for _, t := range tiers {
+		schedule.Tiers = append(schedule.Tiers, toFeeTierDomain(t))
+	}
+	for _, p := range promotions {
+		schedule.Promotions = append(schedule.Promotions, payment.FeePromotion{
+			Collection: p.Collection.String,
+			StartsAt:   p.StartsAt.Time,
+			EndsAt:     p.EndsAt.Time,
+		})
+	}
+	return schedule
+}
+
+func toFeeTierDomain(t sqlc.FeeScheduleTier) payment.FeeTier {
+	rate, err := decimalFromPgNumeric(t.Rate)
+	if err != nil {
+		panic(err)
+	}
+	minStars, err := safecast.ToUint32(t.MinStars)
+	if err != nil {
+		panic(err)
+	}
+	maxStars, err := safecast.ToUint32(t.MaxStars)
+	if err != nil {
+		panic(err)
+	}
+
+	tier := payment.FeeTier{
+		Priority:   t.Priority,
+		Collection: t.Collection.String,
+		Rate:       rate,
+		MinStars:   minStars,
+		MaxStars:   maxStars,
+	}
+	if t.MinPrice.Valid {
+		tier.MinPrice = amountFromPgNumeric(t.MinPrice)
+	}
+	if t.MaxPrice.Valid {
+		tier.MaxPrice = amountFromPgNumeric(t.MaxPrice)
+	}
+	return tier
+}
+
+func amountFromPgNumeric(n pgtype.Numeric) *tonamount.TonAmount {
+	s, err := fromPgNumeric(n)
+	if err != nil {
+		panic(err)
+	}
+	amount, err := tonamount.NewTonAmountFromString(s)
+	if err != nil {
+		panic(err)
+	}
+	return amount
 }
 
 func ToDBTonNetwork(n string) (sqlc.TonNetwork, error) {
@@ -86,19 +362,25 @@ func ToTransactionDomain(t sqlc.UserTransaction) *payment.Transaction {
 		}
 	}
 
-	amountStr, err := fromPgNumeric(t.Amount)
+	signed, err := decimalFromPgNumeric(t.Amount)
 	if err != nil {
 		panic(err)
 	}
 
-	amount, err := tonamount.NewTonAmountFromString(amountStr)
+	// списания хранятся со знаком минус, а TonAmount не разбирает
+	// отрицательные строки
+	amount, err := tonamount.NewTonAmountFromString(signed.Abs().String())
 	if err != nil {
 		panic(err)
 	}
+	if signed.IsNegative() {
+		amount = amount.Negate()
+	}
 
 	return &payment.Transaction{
 		ID:             t.ID.String(),
 		TelegramUserID: t.TelegramUserID,
+		Currency:       payment.Currency(t.Currency),
 		Amount:         amount,
 		Reason:         payment.TransactionReason(t.Reason),
 		CreatedAt:      t.CreatedAt.Time,
@@ -21,5 +21,9 @@ var Module = fx.Module("pg",
 		NewPgxTxManager,
 		NewPaymentRepository,
 		NewDepositRepository,
+		NewWithdrawalRepository,
+		NewDepositAddressRepository,
+		NewHoldRepository,
+		NewFeeScheduleRepository,
 	),
 )
@@ -5,10 +5,12 @@ import (
 	"errors"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/peterparker2005/giftduels/apps/service-payment/internal/adapter/pg/sqlc"
 	payment "github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/payment"
+	"github.com/peterparker2005/giftduels/packages/inbox"
 	"github.com/peterparker2005/giftduels/packages/logger-go"
 	"github.com/peterparker2005/giftduels/packages/shared"
 	"github.com/peterparker2005/giftduels/packages/tonamount-go"
@@ -22,7 +24,7 @@ type repo struct {
 
 func NewPaymentRepository(pool *pgxpool.Pool, logger *logger.Logger) payment.Repository {
 	return &repo{
-		q:      sqlc.New(pool),
+		q:      sqlc.New(inbox.NewDB(pool)),
 		pool:   pool,
 		logger: logger,
 	}
@@ -47,13 +49,21 @@ func (r *repo) Create(ctx context.Context, params *payment.CreateBalanceParams)
 	return nil
 }
 
-func (r *repo) GetUserBalance(ctx context.Context, telegramUserID int64) (*payment.Balance, error) {
-	b, err := r.q.GetUserBalance(ctx, telegramUserID)
+func (r *repo) GetUserBalance(
+	ctx context.Context,
+	telegramUserID int64,
+	currency payment.Currency,
+) (*payment.Balance, error) {
+	b, err := r.q.GetUserBalance(ctx, sqlc.GetUserBalanceParams{
+		TelegramUserID: telegramUserID,
+		Currency:       sqlc.Currency(currency),
+	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			zero := tonamount.Zero()
 			return &payment.Balance{
 				TelegramUserID: telegramUserID,
+				Currency:       currency,
 				TonAmount:      zero,
 			}, nil
 		}
@@ -67,97 +77,176 @@ func (r *repo) GetUserBalance(ctx context.Context, telegramUserID int64) (*payme
 	if err != nil {
 		return nil, MapPGError(err)
 	}
+	heldStr, err := fromPgNumeric(b.HeldAmount)
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	held, err := tonamount.NewTonAmountFromString(heldStr)
+	if err != nil {
+		return nil, MapPGError(err)
+	}
 	return &payment.Balance{
 		ID:             b.ID.String(),
 		TelegramUserID: b.TelegramUserID,
+		Currency:       payment.Currency(b.Currency),
 		TonAmount:      ta,
+		HeldAmount:     held,
 		CreatedAt:      b.CreatedAt.Time,
 		UpdatedAt:      b.UpdatedAt.Time,
 	}, nil
 }
 
-func (r *repo) CreateTransaction(
-	ctx context.Context,
-	params *payment.CreateTransactionParams,
-) error {
-	amount, err := pgNumeric(params.Amount.String())
+func (r *repo) GetUserBalances(ctx context.Context, telegramUserID int64) ([]*payment.Balance, error) {
+	balances, err := r.q.GetUserBalances(ctx, telegramUserID)
 	if err != nil {
-		return MapPGError(err)
+		return nil, MapPGError(err)
 	}
 
-	_, err = r.q.CreateTransaction(ctx, sqlc.CreateTransactionParams{
-		TelegramUserID: params.TelegramUserID,
-		Amount:         amount,
-		Reason:         sqlc.TransactionReason(params.Reason),
-		Metadata:       params.Metadata,
-	})
-	if err != nil {
-		return MapPGError(err)
+	balancesDomain := make([]*payment.Balance, 0, len(balances))
+	for _, b := range balances {
+		balancesDomain = append(balancesDomain, ToBalanceDomain(b))
 	}
-	return nil
+	return balancesDomain, nil
 }
 
-func (r *repo) AddUserBalance(
+func (r *repo) PostEntry(
 	ctx context.Context,
-	params *payment.AddUserBalanceParams,
-) (*payment.Balance, error) {
-	amount, err := pgNumeric(params.Amount.String())
-	if err != nil {
-		return nil, MapPGError(err)
+	entry *payment.JournalEntry,
+) ([]*payment.Balance, error) {
+	if err := entry.Validate(); err != nil {
+		return nil, err
 	}
-	b, err := r.q.UpsertUserBalance(ctx, sqlc.UpsertUserBalanceParams{
-		TelegramUserID: params.TelegramUserID,
-		TonAmount:      amount,
+
+	userID := entry.TelegramUserID()
+	e, err := r.q.CreateLedgerEntry(ctx, sqlc.CreateLedgerEntryParams{
+		Reason:         sqlc.TransactionReason(entry.Reason),
+		Currency:       sqlc.Currency(entry.Currency),
+		TelegramUserID: pgtype.Int8{Int64: userID, Valid: userID != 0},
+		Metadata:       entry.Metadata,
 	})
 	if err != nil {
 		return nil, MapPGError(err)
 	}
-	return ToBalanceDomain(b), nil
+
+	var balances []*payment.Balance
+	for _, p := range entry.Postings {
+		accountID, accErr := r.q.GetOrCreateLedgerAccount(ctx, sqlc.GetOrCreateLedgerAccountParams{
+			Type:           sqlc.LedgerAccountType(p.Account.Type),
+			TelegramUserID: p.Account.TelegramUserID,
+			Currency:       sqlc.Currency(p.Account.Currency),
+		})
+		if accErr != nil {
+			return nil, MapPGError(accErr)
+		}
+		amount, numErr := pgNumeric(p.Amount.String())
+		if numErr != nil {
+			return nil, numErr
+		}
+		if err = r.q.CreateLedgerPosting(ctx, sqlc.CreateLedgerPostingParams{
+			EntryID:   e.ID,
+			AccountID: accountID,
+			Amount:    amount,
+		}); err != nil {
+			return nil, MapPGError(err)
+		}
+
+		if p.Account.Type != payment.AccountTypeUser {
+			continue
+		}
+		balance, balErr := r.applyUserPosting(ctx, p)
+		if balErr != nil {
+			return nil, balErr
+		}
+		if _, err = r.q.CreateTransaction(ctx, sqlc.CreateTransactionParams{
+			TelegramUserID: p.Account.TelegramUserID,
+			Amount:         amount,
+			Reason:         sqlc.TransactionReason(entry.Reason),
+			Metadata:       entry.Metadata,
+			Currency:       sqlc.Currency(p.Account.Currency),
+			EntryID:        e.ID,
+		}); err != nil {
+			return nil, MapPGError(err)
+		}
+		balances = append(balances, balance)
+	}
+	return balances, nil
 }
 
-func (r *repo) SpendUserBalance(
-	ctx context.Context,
-	params *payment.SpendUserBalanceParams,
-) (*payment.Balance, error) {
-	amount, err := pgNumeric(params.Amount.String())
+// applyUserPosting переносит проводку по счёту пользователя в кэш балансов.
+// Списание не уводит баланс в минус: без средств вернётся ErrNotFound.
+func (r *repo) applyUserPosting(ctx context.Context, p payment.Posting) (*payment.Balance, error) {
+	amount, err := pgNumeric(p.Amount.Abs().String())
 	if err != nil {
-		return nil, MapPGError(err)
+		return nil, err
 	}
-
-	// Списываем средства
-	balance, err := r.q.SpendUserBalance(ctx, sqlc.SpendUserBalanceParams{
-		TelegramUserID: params.TelegramUserID,
+	if p.Amount.IsPositive() {
+		b, upsertErr := r.q.UpsertUserBalance(ctx, sqlc.UpsertUserBalanceParams{
+			TelegramUserID: p.Account.TelegramUserID,
+			TonAmount:      amount,
+			Currency:       sqlc.Currency(p.Account.Currency),
+		})
+		if upsertErr != nil {
+			return nil, MapPGError(upsertErr)
+		}
+		return ToBalanceDomain(b), nil
+	}
+	b, err := r.q.SpendUserBalance(ctx, sqlc.SpendUserBalanceParams{
+		TelegramUserID: p.Account.TelegramUserID,
 		TonAmount:      amount,
+		Currency:       sqlc.Currency(p.Account.Currency),
 	})
 	if err != nil {
 		return nil, MapPGError(err)
 	}
-
-	return ToBalanceDomain(balance), nil
+	return ToBalanceDomain(b), nil
 }
 
-func (r *repo) DeleteTransaction(ctx context.Context, id string) error {
-	idPg, err := pgUUID(id)
+func (r *repo) GetIdempotencyKey(ctx context.Context, key string) (*payment.IdempotencyKey, error) {
+	k, err := r.q.GetIdempotencyKey(ctx, key)
 	if err != nil {
-		return MapPGError(err)
+		return nil, MapPGError(err)
 	}
+	return ToIdempotencyKeyDomain(k), nil
+}
 
-	err = r.q.DeleteTransaction(ctx, idPg)
+func (r *repo) SaveIdempotencyKey(ctx context.Context, key *payment.IdempotencyKey) (bool, error) {
+	amount, err := pgNumeric(key.ResultAmount.String())
 	if err != nil {
-		return MapPGError(err)
+		return false, err
 	}
-	return nil
+	n, err := r.q.CreateIdempotencyKey(ctx, sqlc.CreateIdempotencyKeyParams{
+		Key:            key.Key,
+		Operation:      string(key.Operation),
+		RequestHash:    key.RequestHash,
+		TelegramUserID: key.TelegramUserID,
+		ResultAmount:   amount,
+	})
+	if err != nil {
+		return false, MapPGError(err)
+	}
+	return n > 0, nil
 }
 
 func (r *repo) GetUserTransactions(
 	ctx context.Context,
 	telegramUserID int64,
+	filter *payment.TransactionFilter,
 	pagination *shared.PageRequest,
 ) ([]*payment.Transaction, error) {
+	args, err := transactionFilterArgs(telegramUserID, filter)
+	if err != nil {
+		return nil, err
+	}
 	transactions, err := r.q.GetUserTransactions(ctx, sqlc.GetUserTransactionsParams{
-		TelegramUserID: telegramUserID,
-		Limit:          pagination.PageSize(),
-		Offset:         pagination.Offset(),
+		TelegramUserID: args.TelegramUserID,
+		Reasons:        args.Reasons,
+		CreatedFrom:    args.CreatedFrom,
+		CreatedTo:      args.CreatedTo,
+		MinAmount:      args.MinAmount,
+		MaxAmount:      args.MaxAmount,
+		Query:          args.Query,
+		LimitCount:     pagination.PageSize(),
+		OffsetCount:    pagination.Offset(),
 	})
 	if err != nil {
 		return nil, MapPGError(err)
@@ -170,10 +259,115 @@ func (r *repo) GetUserTransactions(
 	return transactionsDomain, nil
 }
 
-func (r *repo) GetUserTransactionsCount(ctx context.Context, telegramUserID int64) (int64, error) {
-	count, err := r.q.GetUserTransactionsCount(ctx, telegramUserID)
+func (r *repo) GetUserTransactionsCount(
+	ctx context.Context,
+	telegramUserID int64,
+	filter *payment.TransactionFilter,
+) (int64, error) {
+	args, err := transactionFilterArgs(telegramUserID, filter)
+	if err != nil {
+		return 0, err
+	}
+	count, err := r.q.GetUserTransactionsCount(ctx, args)
 	if err != nil {
 		return 0, err
 	}
 	return count, nil
 }
+
+func (r *repo) GetUserTransactionTotals(
+	ctx context.Context,
+	telegramUserID int64,
+	filter *payment.TransactionFilter,
+) ([]*payment.TransactionTotal, error) {
+	args, err := transactionFilterArgs(telegramUserID, filter)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.q.GetUserTransactionTotals(ctx, sqlc.GetUserTransactionTotalsParams(args))
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	totals := make([]*payment.TransactionTotal, 0, len(rows))
+	for _, row := range rows {
+		amount, decErr := decimalFromPgNumeric(row.Amount)
+		if decErr != nil {
+			return nil, decErr
+		}
+		totals = append(totals, &payment.TransactionTotal{
+			Reason:   payment.TransactionReason(row.Reason),
+			Currency: payment.Currency(row.Currency),
+			Amount:   amount,
+			Count:    row.TransactionsCount,
+		})
+	}
+	return totals, nil
+}
+
+func (r *repo) GetUnbalancedEntries(ctx context.Context, limit int32) ([]*payment.UnbalancedEntry, error) {
+	rows, err := r.q.GetUnbalancedLedgerEntries(ctx, limit)
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	entries := make([]*payment.UnbalancedEntry, 0, len(rows))
+	for _, row := range rows {
+		total, decErr := decimalFromPgNumeric(row.Total)
+		if decErr != nil {
+			return nil, decErr
+		}
+		entries = append(entries, &payment.UnbalancedEntry{
+			ID:            row.ID.String(),
+			Reason:        payment.TransactionReason(row.Reason),
+			Currency:      payment.Currency(row.Currency),
+			PostingsCount: row.PostingsCount,
+			Total:         total,
+			CreatedAt:     row.CreatedAt.Time,
+		})
+	}
+	return entries, nil
+}
+
+func (r *repo) GetBalanceMismatches(ctx context.Context, limit int32) ([]*payment.BalanceMismatch, error) {
+	rows, err := r.q.GetLedgerBalanceMismatches(ctx, limit)
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	mismatches := make([]*payment.BalanceMismatch, 0, len(rows))
+	for _, row := range rows {
+		cached, decErr := decimalFromPgNumeric(row.CachedAmount)
+		if decErr != nil {
+			return nil, decErr
+		}
+		ledger, decErr := decimalFromPgNumeric(row.LedgerAmount)
+		if decErr != nil {
+			return nil, decErr
+		}
+		mismatches = append(mismatches, &payment.BalanceMismatch{
+			TelegramUserID: row.TelegramUserID,
+			Currency:       payment.Currency(row.Currency),
+			Cached:         cached,
+			Ledger:         ledger,
+		})
+	}
+	return mismatches, nil
+}
+
+func (r *repo) GetSystemBalances(ctx context.Context) ([]*payment.SystemBalance, error) {
+	rows, err := r.q.GetSystemLedgerBalances(ctx)
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	balances := make([]*payment.SystemBalance, 0, len(rows))
+	for _, row := range rows {
+		amount, decErr := decimalFromPgNumeric(row.Amount)
+		if decErr != nil {
+			return nil, decErr
+		}
+		balances = append(balances, &payment.SystemBalance{
+			Type:     payment.AccountType(row.Type),
+			Currency: payment.Currency(row.Currency),
+			Amount:   amount,
+		})
+	}
+	return balances, nil
+}
@@ -11,6 +11,92 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type BalanceHoldStatus string
+
+const (
+	BalanceHoldStatusActive   BalanceHoldStatus = "active"
+	BalanceHoldStatusCaptured BalanceHoldStatus = "captured"
+	BalanceHoldStatusReleased BalanceHoldStatus = "released"
+	BalanceHoldStatusExpired  BalanceHoldStatus = "expired"
+)
+
+func (e *BalanceHoldStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = BalanceHoldStatus(s)
+	case string:
+		*e = BalanceHoldStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for BalanceHoldStatus: %T", src)
+	}
+	return nil
+}
+
+type NullBalanceHoldStatus struct {
+	BalanceHoldStatus BalanceHoldStatus
+	Valid             bool // Valid is true if BalanceHoldStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullBalanceHoldStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.BalanceHoldStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.BalanceHoldStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullBalanceHoldStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.BalanceHoldStatus), nil
+}
+
+type Currency string
+
+const (
+	CurrencyTON  Currency = "TON"
+	CurrencyUSDT Currency = "USDT"
+)
+
+func (e *Currency) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = Currency(s)
+	case string:
+		*e = Currency(s)
+	default:
+		return fmt.Errorf("unsupported scan type for Currency: %T", src)
+	}
+	return nil
+}
+
+type NullCurrency struct {
+	Currency Currency
+	Valid    bool // Valid is true if Currency is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullCurrency) Scan(value interface{}) error {
+	if value == nil {
+		ns.Currency, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.Currency.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullCurrency) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.Currency), nil
+}
+
 type DepositStatus string
 
 const (
@@ -18,6 +104,7 @@ const (
 	DepositStatusReceived  DepositStatus = "received"
 	DepositStatusConfirmed DepositStatus = "confirmed"
 	DepositStatusExpired   DepositStatus = "expired"
+	DepositStatusPartial   DepositStatus = "partial"
 )
 
 func (e *DepositStatus) Scan(src interface{}) error {
@@ -55,6 +142,50 @@ func (ns NullDepositStatus) Value() (driver.Value, error) {
 	return string(ns.DepositStatus), nil
 }
 
+type LedgerAccountType string
+
+const (
+	LedgerAccountTypeUser     LedgerAccountType = "user"
+	LedgerAccountTypeTreasury LedgerAccountType = "treasury"
+	LedgerAccountTypeFees     LedgerAccountType = "fees"
+	LedgerAccountTypeHouse    LedgerAccountType = "house"
+)
+
+func (e *LedgerAccountType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = LedgerAccountType(s)
+	case string:
+		*e = LedgerAccountType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for LedgerAccountType: %T", src)
+	}
+	return nil
+}
+
+type NullLedgerAccountType struct {
+	LedgerAccountType LedgerAccountType
+	Valid             bool // Valid is true if LedgerAccountType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullLedgerAccountType) Scan(value interface{}) error {
+	if value == nil {
+		ns.LedgerAccountType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.LedgerAccountType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullLedgerAccountType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.LedgerAccountType), nil
+}
+
 type TonNetwork string
 
 const (
@@ -97,12 +228,61 @@ func (ns NullTonNetwork) Value() (driver.Value, error) {
 	return string(ns.TonNetwork), nil
 }
 
+type TonWithdrawalStatus string
+
+const (
+	TonWithdrawalStatusPending   TonWithdrawalStatus = "pending"
+	TonWithdrawalStatusSent      TonWithdrawalStatus = "sent"
+	TonWithdrawalStatusConfirmed TonWithdrawalStatus = "confirmed"
+	TonWithdrawalStatusFailed    TonWithdrawalStatus = "failed"
+)
+
+func (e *TonWithdrawalStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = TonWithdrawalStatus(s)
+	case string:
+		*e = TonWithdrawalStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for TonWithdrawalStatus: %T", src)
+	}
+	return nil
+}
+
+type NullTonWithdrawalStatus struct {
+	TonWithdrawalStatus TonWithdrawalStatus
+	Valid               bool // Valid is true if TonWithdrawalStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullTonWithdrawalStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.TonWithdrawalStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.TonWithdrawalStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullTonWithdrawalStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.TonWithdrawalStatus), nil
+}
+
 type TransactionReason string
 
 const (
-	TransactionReasonWithdraw TransactionReason = "withdraw"
-	TransactionReasonRefund   TransactionReason = "refund"
-	TransactionReasonDeposit  TransactionReason = "deposit"
+	TransactionReasonWithdraw       TransactionReason = "withdraw"
+	TransactionReasonRefund         TransactionReason = "refund"
+	TransactionReasonDeposit        TransactionReason = "deposit"
+	TransactionReasonPurchase       TransactionReason = "purchase"
+	TransactionReasonSale           TransactionReason = "sale"
+	TransactionReasonSellBack       TransactionReason = "sell_back"
+	TransactionReasonTonWithdrawal  TransactionReason = "ton_withdrawal"
+	TransactionReasonOpeningBalance TransactionReason = "opening_balance"
 )
 
 func (e *TransactionReason) Scan(src interface{}) error {
@@ -140,19 +320,165 @@ func (ns NullTransactionReason) Value() (driver.Value, error) {
 	return string(ns.TransactionReason), nil
 }
 
-type Deposit struct {
+type UnattributedDepositStatus string
+
+const (
+	UnattributedDepositStatusPending  UnattributedDepositStatus = "pending"
+	UnattributedDepositStatusAssigned UnattributedDepositStatus = "assigned"
+)
+
+func (e *UnattributedDepositStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = UnattributedDepositStatus(s)
+	case string:
+		*e = UnattributedDepositStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for UnattributedDepositStatus: %T", src)
+	}
+	return nil
+}
+
+type NullUnattributedDepositStatus struct {
+	UnattributedDepositStatus UnattributedDepositStatus
+	Valid                     bool // Valid is true if UnattributedDepositStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullUnattributedDepositStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.UnattributedDepositStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.UnattributedDepositStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullUnattributedDepositStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.UnattributedDepositStatus), nil
+}
+
+type BalanceHold struct {
 	ID             pgtype.UUID
 	TelegramUserID int64
-	Status         DepositStatus
-	AmountNano     int64
-	Payload        string
+	Currency       Currency
+	Amount         pgtype.Numeric
+	Reason         TransactionReason
+	Metadata       []byte
+	Status         BalanceHoldStatus
 	ExpiresAt      pgtype.Timestamptz
-	TxHash         pgtype.Text
-	TxLt           pgtype.Int8
+	SettledAt      pgtype.Timestamptz
 	CreatedAt      pgtype.Timestamptz
 	UpdatedAt      pgtype.Timestamptz
 }
 
+type Deposit struct {
+	ID                 pgtype.UUID
+	TelegramUserID     int64
+	Status             DepositStatus
+	AmountNano         int64
+	Payload            string
+	ExpiresAt          pgtype.Timestamptz
+	TxHash             pgtype.Text
+	TxLt               pgtype.Int8
+	CreatedAt          pgtype.Timestamptz
+	UpdatedAt          pgtype.Timestamptz
+	ReceivedAmountNano pgtype.Int8
+	SenderAddress      pgtype.Text
+	ReceivedAt         pgtype.Timestamptz
+	ExpiredAt          pgtype.Timestamptz
+	ReceivedMcSeqno    pgtype.Int8
+	ConfirmedAt        pgtype.Timestamptz
+	Currency           Currency
+	DepositAddress     pgtype.Text
+}
+
+type DepositAddress struct {
+	ID             pgtype.UUID
+	TelegramUserID int64
+	SubwalletID    int64
+	Address        string
+	RawAddress     string
+	LastLt         int64
+	SweepPending   bool
+	LastSweptAt    pgtype.Timestamptz
+	CreatedAt      pgtype.Timestamptz
+	UpdatedAt      pgtype.Timestamptz
+}
+
+type FeeSchedule struct {
+	ID            pgtype.UUID
+	Version       int64
+	TonPerStar    pgtype.Numeric
+	EffectiveFrom pgtype.Timestamptz
+	Comment       pgtype.Text
+	CreatedAt     pgtype.Timestamptz
+}
+
+type FeeSchedulePromotion struct {
+	ID         pgtype.UUID
+	ScheduleID pgtype.UUID
+	Collection pgtype.Text
+	StartsAt   pgtype.Timestamptz
+	EndsAt     pgtype.Timestamptz
+}
+
+type FeeScheduleTier struct {
+	ID         pgtype.UUID
+	ScheduleID pgtype.UUID
+	Priority   int32
+	Collection pgtype.Text
+	MinPrice   pgtype.Numeric
+	MaxPrice   pgtype.Numeric
+	Rate       pgtype.Numeric
+	MinStars   int32
+	MaxStars   int32
+}
+
+type IdempotencyKey struct {
+	Key            string
+	Operation      string
+	RequestHash    string
+	TelegramUserID int64
+	ResultAmount   pgtype.Numeric
+	CreatedAt      pgtype.Timestamptz
+}
+
+type LedgerAccount struct {
+	ID             pgtype.UUID
+	Type           LedgerAccountType
+	TelegramUserID int64
+	Currency       Currency
+	CreatedAt      pgtype.Timestamptz
+}
+
+type LedgerEntry struct {
+	ID             pgtype.UUID
+	Reason         TransactionReason
+	Currency       Currency
+	TelegramUserID pgtype.Int8
+	Metadata       []byte
+	CreatedAt      pgtype.Timestamptz
+}
+
+type LedgerPosting struct {
+	ID        pgtype.UUID
+	EntryID   pgtype.UUID
+	AccountID pgtype.UUID
+	Amount    pgtype.Numeric
+	CreatedAt pgtype.Timestamptz
+}
+
+type ProcessedMessage struct {
+	Handler     string
+	MessageID   string
+	ProcessedAt pgtype.Timestamptz
+}
+
 type TonCursor struct {
 	Network       TonNetwork
 	WalletAddress string
@@ -160,12 +486,45 @@ type TonCursor struct {
 	UpdatedAt     pgtype.Timestamptz
 }
 
+type TonWithdrawal struct {
+	ID             pgtype.UUID
+	TelegramUserID int64
+	Destination    string
+	AmountNano     int64
+	Status         TonWithdrawalStatus
+	MsgHash        pgtype.Text
+	WalletSeqno    pgtype.Int8
+	ValidUntil     pgtype.Timestamptz
+	TxHash         pgtype.Text
+	TxLt           pgtype.Int8
+	FailureReason  pgtype.Text
+	CreatedAt      pgtype.Timestamptz
+	UpdatedAt      pgtype.Timestamptz
+}
+
+type UnattributedDeposit struct {
+	ID             pgtype.UUID
+	TxHash         string
+	TxLt           int64
+	SenderAddress  string
+	Currency       Currency
+	AmountNano     int64
+	Comment        pgtype.Text
+	Status         UnattributedDepositStatus
+	TelegramUserID pgtype.Int8
+	AssignedAt     pgtype.Timestamptz
+	CreatedAt      pgtype.Timestamptz
+	UpdatedAt      pgtype.Timestamptz
+}
+
 type UserBalance struct {
 	ID             pgtype.UUID
 	TelegramUserID int64
 	TonAmount      pgtype.Numeric
 	CreatedAt      pgtype.Timestamp
 	UpdatedAt      pgtype.Timestamp
+	Currency       Currency
+	HeldAmount     pgtype.Numeric
 }
 
 type UserTransaction struct {
@@ -175,4 +534,6 @@ type UserTransaction struct {
 	Reason         TransactionReason
 	CreatedAt      pgtype.Timestamp
 	Metadata       []byte
+	Currency       Currency
+	EntryID        pgtype.UUID
 }
@@ -11,10 +11,155 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const assignUnattributedDeposit = `-- name: AssignUnattributedDeposit :one
+UPDATE unattributed_deposits
+SET
+    status = 'assigned',
+    telegram_user_id = $2,
+    assigned_at = now(),
+    updated_at = now()
+WHERE id = $1
+  AND status = 'pending'
+RETURNING id, tx_hash, tx_lt, sender_address, currency, amount_nano, comment, status, telegram_user_id, assigned_at, created_at, updated_at
+`
+
+type AssignUnattributedDepositParams struct {
+	ID             pgtype.UUID
+	TelegramUserID pgtype.Int8
+}
+
+func (q *Queries) AssignUnattributedDeposit(ctx context.Context, arg AssignUnattributedDepositParams) (UnattributedDeposit, error) {
+	row := q.db.QueryRow(ctx, assignUnattributedDeposit, arg.ID, arg.TelegramUserID)
+	var i UnattributedDeposit
+	err := row.Scan(
+		&i.ID,
+		&i.TxHash,
+		&i.TxLt,
+		&i.SenderAddress,
+		&i.Currency,
+		&i.AmountNano,
+		&i.Comment,
+		&i.Status,
+		&i.TelegramUserID,
+		&i.AssignedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const confirmDeposit = `-- name: ConfirmDeposit :one
+UPDATE deposits
+SET
+    status = $2,
+    confirmed_at = now(),
+    updated_at = now()
+WHERE id = $1
+  AND status = 'received'
+RETURNING id, telegram_user_id, status, amount_nano, payload, expires_at, tx_hash, tx_lt, created_at, updated_at, received_amount_nano, sender_address, received_at, expired_at, received_mc_seqno, confirmed_at, currency, deposit_address
+`
+
+type ConfirmDepositParams struct {
+	ID     pgtype.UUID
+	Status DepositStatus
+}
+
+func (q *Queries) ConfirmDeposit(ctx context.Context, arg ConfirmDepositParams) (Deposit, error) {
+	row := q.db.QueryRow(ctx, confirmDeposit, arg.ID, arg.Status)
+	var i Deposit
+	err := row.Scan(
+		&i.ID,
+		&i.TelegramUserID,
+		&i.Status,
+		&i.AmountNano,
+		&i.Payload,
+		&i.ExpiresAt,
+		&i.TxHash,
+		&i.TxLt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ReceivedAmountNano,
+		&i.SenderAddress,
+		&i.ReceivedAt,
+		&i.ExpiredAt,
+		&i.ReceivedMcSeqno,
+		&i.ConfirmedAt,
+		&i.Currency,
+		&i.DepositAddress,
+	)
+	return i, err
+}
+
+const confirmTonWithdrawal = `-- name: ConfirmTonWithdrawal :execrows
+UPDATE ton_withdrawals
+SET
+    status = 'confirmed',
+    tx_hash = $2,
+    tx_lt = $3,
+    updated_at = now()
+WHERE id = $1
+  AND status = 'sent'
+`
+
+type ConfirmTonWithdrawalParams struct {
+	ID     pgtype.UUID
+	TxHash pgtype.Text
+	TxLt   pgtype.Int8
+}
+
+func (q *Queries) ConfirmTonWithdrawal(ctx context.Context, arg ConfirmTonWithdrawalParams) (int64, error) {
+	result, err := q.db.Exec(ctx, confirmTonWithdrawal, arg.ID, arg.TxHash, arg.TxLt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const createBalanceHold = `-- name: CreateBalanceHold :one
+INSERT INTO balance_holds (telegram_user_id, currency, amount, reason, metadata, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, telegram_user_id, currency, amount, reason, metadata, status, expires_at, settled_at, created_at, updated_at
+`
+
+type CreateBalanceHoldParams struct {
+	TelegramUserID int64
+	Currency       Currency
+	Amount         pgtype.Numeric
+	Reason         TransactionReason
+	Metadata       []byte
+	ExpiresAt      pgtype.Timestamptz
+}
+
+func (q *Queries) CreateBalanceHold(ctx context.Context, arg CreateBalanceHoldParams) (BalanceHold, error) {
+	row := q.db.QueryRow(ctx, createBalanceHold,
+		arg.TelegramUserID,
+		arg.Currency,
+		arg.Amount,
+		arg.Reason,
+		arg.Metadata,
+		arg.ExpiresAt,
+	)
+	var i BalanceHold
+	err := row.Scan(
+		&i.ID,
+		&i.TelegramUserID,
+		&i.Currency,
+		&i.Amount,
+		&i.Reason,
+		&i.Metadata,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.SettledAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const createDeposit = `-- name: CreateDeposit :one
-INSERT INTO deposits (telegram_user_id, amount_nano, payload, expires_at)
-VALUES ($1, $2, $3, $4)
-RETURNING id, telegram_user_id, status, amount_nano, payload, expires_at, tx_hash, tx_lt, created_at, updated_at
+INSERT INTO deposits (telegram_user_id, amount_nano, payload, expires_at, currency)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, telegram_user_id, status, amount_nano, payload, expires_at, tx_hash, tx_lt, created_at, updated_at, received_amount_nano, sender_address, received_at, expired_at, received_mc_seqno, confirmed_at, currency, deposit_address
 `
 
 type CreateDepositParams struct {
@@ -22,6 +167,7 @@ type CreateDepositParams struct {
 	AmountNano     int64
 	Payload        string
 	ExpiresAt      pgtype.Timestamptz
+	Currency       Currency
 }
 
 func (q *Queries) CreateDeposit(ctx context.Context, arg CreateDepositParams) (Deposit, error) {
@@ -30,6 +176,284 @@ func (q *Queries) CreateDeposit(ctx context.Context, arg CreateDepositParams) (D
 		arg.AmountNano,
 		arg.Payload,
 		arg.ExpiresAt,
+		arg.Currency,
+	)
+	var i Deposit
+	err := row.Scan(
+		&i.ID,
+		&i.TelegramUserID,
+		&i.Status,
+		&i.AmountNano,
+		&i.Payload,
+		&i.ExpiresAt,
+		&i.TxHash,
+		&i.TxLt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ReceivedAmountNano,
+		&i.SenderAddress,
+		&i.ReceivedAt,
+		&i.ExpiredAt,
+		&i.ReceivedMcSeqno,
+		&i.ConfirmedAt,
+		&i.Currency,
+		&i.DepositAddress,
+	)
+	return i, err
+}
+
+const createDepositAddress = `-- name: CreateDepositAddress :one
+INSERT INTO deposit_addresses (telegram_user_id, subwallet_id, address, raw_address)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (telegram_user_id) DO NOTHING
+RETURNING id, telegram_user_id, subwallet_id, address, raw_address, last_lt, sweep_pending, last_swept_at, created_at, updated_at
+`
+
+type CreateDepositAddressParams struct {
+	TelegramUserID int64
+	SubwalletID    int64
+	Address        string
+	RawAddress     string
+}
+
+func (q *Queries) CreateDepositAddress(ctx context.Context, arg CreateDepositAddressParams) (DepositAddress, error) {
+	row := q.db.QueryRow(ctx, createDepositAddress,
+		arg.TelegramUserID,
+		arg.SubwalletID,
+		arg.Address,
+		arg.RawAddress,
+	)
+	var i DepositAddress
+	err := row.Scan(
+		&i.ID,
+		&i.TelegramUserID,
+		&i.SubwalletID,
+		&i.Address,
+		&i.RawAddress,
+		&i.LastLt,
+		&i.SweepPending,
+		&i.LastSweptAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createFeeSchedule = `-- name: CreateFeeSchedule :one
+INSERT INTO fee_schedules (version, ton_per_star, effective_from, comment)
+VALUES (
+    (SELECT COALESCE(MAX(version), 0) + 1 FROM fee_schedules),
+    $1, $2, $3
+)
+RETURNING id, version, ton_per_star, effective_from, comment, created_at
+`
+
+type CreateFeeScheduleParams struct {
+	TonPerStar    pgtype.Numeric
+	EffectiveFrom pgtype.Timestamptz
+	Comment       pgtype.Text
+}
+
+func (q *Queries) CreateFeeSchedule(ctx context.Context, arg CreateFeeScheduleParams) (FeeSchedule, error) {
+	row := q.db.QueryRow(ctx, createFeeSchedule, arg.TonPerStar, arg.EffectiveFrom, arg.Comment)
+	var i FeeSchedule
+	err := row.Scan(
+		&i.ID,
+		&i.Version,
+		&i.TonPerStar,
+		&i.EffectiveFrom,
+		&i.Comment,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createFeeSchedulePromotion = `-- name: CreateFeeSchedulePromotion :exec
+INSERT INTO fee_schedule_promotions (schedule_id, collection, starts_at, ends_at)
+VALUES ($1, $2, $3, $4)
+`
+
+type CreateFeeSchedulePromotionParams struct {
+	ScheduleID pgtype.UUID
+	Collection pgtype.Text
+	StartsAt   pgtype.Timestamptz
+	EndsAt     pgtype.Timestamptz
+}
+
+func (q *Queries) CreateFeeSchedulePromotion(ctx context.Context, arg CreateFeeSchedulePromotionParams) error {
+	_, err := q.db.Exec(ctx, createFeeSchedulePromotion,
+		arg.ScheduleID,
+		arg.Collection,
+		arg.StartsAt,
+		arg.EndsAt,
+	)
+	return err
+}
+
+const createFeeScheduleTier = `-- name: CreateFeeScheduleTier :exec
+INSERT INTO fee_schedule_tiers (
+    schedule_id, priority, collection, min_price, max_price, rate, min_stars, max_stars
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+`
+
+type CreateFeeScheduleTierParams struct {
+	ScheduleID pgtype.UUID
+	Priority   int32
+	Collection pgtype.Text
+	MinPrice   pgtype.Numeric
+	MaxPrice   pgtype.Numeric
+	Rate       pgtype.Numeric
+	MinStars   int32
+	MaxStars   int32
+}
+
+func (q *Queries) CreateFeeScheduleTier(ctx context.Context, arg CreateFeeScheduleTierParams) error {
+	_, err := q.db.Exec(ctx, createFeeScheduleTier,
+		arg.ScheduleID,
+		arg.Priority,
+		arg.Collection,
+		arg.MinPrice,
+		arg.MaxPrice,
+		arg.Rate,
+		arg.MinStars,
+		arg.MaxStars,
+	)
+	return err
+}
+
+const createIdempotencyKey = `-- name: CreateIdempotencyKey :execrows
+INSERT INTO idempotency_keys (key, operation, request_hash, telegram_user_id, result_amount)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (key) DO NOTHING
+`
+
+type CreateIdempotencyKeyParams struct {
+	Key            string
+	Operation      string
+	RequestHash    string
+	TelegramUserID int64
+	ResultAmount   pgtype.Numeric
+}
+
+func (q *Queries) CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (int64, error) {
+	result, err := q.db.Exec(ctx, createIdempotencyKey,
+		arg.Key,
+		arg.Operation,
+		arg.RequestHash,
+		arg.TelegramUserID,
+		arg.ResultAmount,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const createLedgerEntry = `-- name: CreateLedgerEntry :one
+INSERT INTO ledger_entries (reason, currency, telegram_user_id, metadata)
+VALUES ($1, $2, $3, $4)
+RETURNING id, reason, currency, telegram_user_id, metadata, created_at
+`
+
+type CreateLedgerEntryParams struct {
+	Reason         TransactionReason
+	Currency       Currency
+	TelegramUserID pgtype.Int8
+	Metadata       []byte
+}
+
+func (q *Queries) CreateLedgerEntry(ctx context.Context, arg CreateLedgerEntryParams) (LedgerEntry, error) {
+	row := q.db.QueryRow(ctx, createLedgerEntry,
+		arg.Reason,
+		arg.Currency,
+		arg.TelegramUserID,
+		arg.Metadata,
+	)
+	var i LedgerEntry
+	err := row.Scan(
+		&i.ID,
+		&i.Reason,
+		&i.Currency,
+		&i.TelegramUserID,
+		&i.Metadata,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createLedgerPosting = `-- name: CreateLedgerPosting :exec
+INSERT INTO ledger_postings (entry_id, account_id, amount)
+VALUES ($1, $2, $3)
+`
+
+type CreateLedgerPostingParams struct {
+	EntryID   pgtype.UUID
+	AccountID pgtype.UUID
+	Amount    pgtype.Numeric
+}
+
+func (q *Queries) CreateLedgerPosting(ctx context.Context, arg CreateLedgerPostingParams) error {
+	_, err := q.db.Exec(ctx, createLedgerPosting, arg.EntryID, arg.AccountID, arg.Amount)
+	return err
+}
+
+const createReceivedDeposit = `-- name: CreateReceivedDeposit :one
+INSERT INTO deposits (
+    telegram_user_id,
+    status,
+    amount_nano,
+    payload,
+    expires_at,
+    tx_hash,
+    tx_lt,
+    received_amount_nano,
+    sender_address,
+    received_mc_seqno,
+    received_at,
+    currency,
+    deposit_address
+) VALUES (
+    $1,
+    'received',
+    $2,
+    $3,
+    now(),
+    $4,
+    $5,
+    $2,
+    $6,
+    $7,
+    now(),
+    $8,
+    $9
+)
+ON CONFLICT (payload) DO NOTHING
+RETURNING id, telegram_user_id, status, amount_nano, payload, expires_at, tx_hash, tx_lt, created_at, updated_at, received_amount_nano, sender_address, received_at, expired_at, received_mc_seqno, confirmed_at, currency, deposit_address
+`
+
+type CreateReceivedDepositParams struct {
+	TelegramUserID  int64
+	AmountNano      int64
+	Payload         string
+	TxHash          pgtype.Text
+	TxLt            pgtype.Int8
+	SenderAddress   pgtype.Text
+	ReceivedMcSeqno pgtype.Int8
+	Currency        Currency
+	DepositAddress  pgtype.Text
+}
+
+func (q *Queries) CreateReceivedDeposit(ctx context.Context, arg CreateReceivedDepositParams) (Deposit, error) {
+	row := q.db.QueryRow(ctx, createReceivedDeposit,
+		arg.TelegramUserID,
+		arg.AmountNano,
+		arg.Payload,
+		arg.TxHash,
+		arg.TxLt,
+		arg.SenderAddress,
+		arg.ReceivedMcSeqno,
+		arg.Currency,
+		arg.DepositAddress,
 	)
 	var i Deposit
 	err := row.Scan(
@@ -43,6 +467,47 @@ func (q *Queries) CreateDeposit(ctx context.Context, arg CreateDepositParams) (D
 		&i.TxLt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ReceivedAmountNano,
+		&i.SenderAddress,
+		&i.ReceivedAt,
+		&i.ExpiredAt,
+		&i.ReceivedMcSeqno,
+		&i.ConfirmedAt,
+		&i.Currency,
+		&i.DepositAddress,
+	)
+	return i, err
+}
+
+const createTonWithdrawal = `-- name: CreateTonWithdrawal :one
+INSERT INTO ton_withdrawals (telegram_user_id, destination, amount_nano)
+VALUES ($1, $2, $3)
+RETURNING id, telegram_user_id, destination, amount_nano, status, msg_hash, wallet_seqno, valid_until, tx_hash, tx_lt, failure_reason, created_at, updated_at
+`
+
+type CreateTonWithdrawalParams struct {
+	TelegramUserID int64
+	Destination    string
+	AmountNano     int64
+}
+
+func (q *Queries) CreateTonWithdrawal(ctx context.Context, arg CreateTonWithdrawalParams) (TonWithdrawal, error) {
+	row := q.db.QueryRow(ctx, createTonWithdrawal, arg.TelegramUserID, arg.Destination, arg.AmountNano)
+	var i TonWithdrawal
+	err := row.Scan(
+		&i.ID,
+		&i.TelegramUserID,
+		&i.Destination,
+		&i.AmountNano,
+		&i.Status,
+		&i.MsgHash,
+		&i.WalletSeqno,
+		&i.ValidUntil,
+		&i.TxHash,
+		&i.TxLt,
+		&i.FailureReason,
+		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
@@ -52,11 +517,13 @@ INSERT INTO user_transactions (
     telegram_user_id,
     amount,
     reason,
-    metadata
+    metadata,
+    currency,
+    entry_id
 ) VALUES (
-    $1, $2, $3, $4
+    $1, $2, $3, $4, $5, $6
 )
-RETURNING id, telegram_user_id, amount, reason, created_at, metadata
+RETURNING id, telegram_user_id, amount, reason, created_at, metadata, currency, entry_id
 `
 
 type CreateTransactionParams struct {
@@ -64,6 +531,8 @@ type CreateTransactionParams struct {
 	Amount         pgtype.Numeric
 	Reason         TransactionReason
 	Metadata       []byte
+	Currency       Currency
+	EntryID        pgtype.UUID
 }
 
 func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionParams) (UserTransaction, error) {
@@ -72,6 +541,8 @@ func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionPa
 		arg.Amount,
 		arg.Reason,
 		arg.Metadata,
+		arg.Currency,
+		arg.EntryID,
 	)
 	var i UserTransaction
 	err := row.Scan(
@@ -81,10 +552,42 @@ func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionPa
 		&i.Reason,
 		&i.CreatedAt,
 		&i.Metadata,
+		&i.Currency,
+		&i.EntryID,
 	)
 	return i, err
 }
 
+const createUnattributedDeposit = `-- name: CreateUnattributedDeposit :execrows
+INSERT INTO unattributed_deposits (tx_hash, tx_lt, sender_address, currency, amount_nano, comment)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (tx_hash) DO NOTHING
+`
+
+type CreateUnattributedDepositParams struct {
+	TxHash        string
+	TxLt          int64
+	SenderAddress string
+	Currency      Currency
+	AmountNano    int64
+	Comment       pgtype.Text
+}
+
+func (q *Queries) CreateUnattributedDeposit(ctx context.Context, arg CreateUnattributedDepositParams) (int64, error) {
+	result, err := q.db.Exec(ctx, createUnattributedDeposit,
+		arg.TxHash,
+		arg.TxLt,
+		arg.SenderAddress,
+		arg.Currency,
+		arg.AmountNano,
+		arg.Comment,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const createUserBalance = `-- name: CreateUserBalance :one
 INSERT INTO user_balances (
     telegram_user_id,
@@ -92,16 +595,708 @@ INSERT INTO user_balances (
 ) VALUES (
     $1, $2
 )
-RETURNING id, telegram_user_id, ton_amount, created_at, updated_at
+RETURNING id, telegram_user_id, ton_amount, created_at, updated_at, currency, held_amount
+`
+
+type CreateUserBalanceParams struct {
+	TelegramUserID int64
+	TonAmount      pgtype.Numeric
+}
+
+func (q *Queries) CreateUserBalance(ctx context.Context, arg CreateUserBalanceParams) (UserBalance, error) {
+	row := q.db.QueryRow(ctx, createUserBalance, arg.TelegramUserID, arg.TonAmount)
+	var i UserBalance
+	err := row.Scan(
+		&i.ID,
+		&i.TelegramUserID,
+		&i.TonAmount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Currency,
+		&i.HeldAmount,
+	)
+	return i, err
+}
+
+const expireDeposits = `-- name: ExpireDeposits :execrows
+UPDATE deposits
+SET
+    status = 'expired',
+    expired_at = now(),
+    updated_at = now()
+WHERE id IN (
+    SELECT d.id FROM deposits d
+    WHERE d.status = 'pending'
+      AND d.expires_at < $1
+    ORDER BY d.expires_at
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+`
+
+type ExpireDepositsParams struct {
+	ExpiresAt pgtype.Timestamptz
+	Limit     int32
+}
+
+func (q *Queries) ExpireDeposits(ctx context.Context, arg ExpireDepositsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, expireDeposits, arg.ExpiresAt, arg.Limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const failTonWithdrawal = `-- name: FailTonWithdrawal :one
+UPDATE ton_withdrawals
+SET
+    status = 'failed',
+    failure_reason = $2,
+    updated_at = now()
+WHERE id = $1
+  AND status IN ('pending', 'sent')
+RETURNING id, telegram_user_id, destination, amount_nano, status, msg_hash, wallet_seqno, valid_until, tx_hash, tx_lt, failure_reason, created_at, updated_at
+`
+
+type FailTonWithdrawalParams struct {
+	ID            pgtype.UUID
+	FailureReason pgtype.Text
+}
+
+func (q *Queries) FailTonWithdrawal(ctx context.Context, arg FailTonWithdrawalParams) (TonWithdrawal, error) {
+	row := q.db.QueryRow(ctx, failTonWithdrawal, arg.ID, arg.FailureReason)
+	var i TonWithdrawal
+	err := row.Scan(
+		&i.ID,
+		&i.TelegramUserID,
+		&i.Destination,
+		&i.AmountNano,
+		&i.Status,
+		&i.MsgHash,
+		&i.WalletSeqno,
+		&i.ValidUntil,
+		&i.TxHash,
+		&i.TxLt,
+		&i.FailureReason,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getActiveFeeSchedule = `-- name: GetActiveFeeSchedule :one
+SELECT id, version, ton_per_star, effective_from, comment, created_at FROM fee_schedules
+WHERE effective_from <= $1
+ORDER BY version DESC
+LIMIT 1
+`
+
+func (q *Queries) GetActiveFeeSchedule(ctx context.Context, effectiveFrom pgtype.Timestamptz) (FeeSchedule, error) {
+	row := q.db.QueryRow(ctx, getActiveFeeSchedule, effectiveFrom)
+	var i FeeSchedule
+	err := row.Scan(
+		&i.ID,
+		&i.Version,
+		&i.TonPerStar,
+		&i.EffectiveFrom,
+		&i.Comment,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getBalanceHoldForUpdate = `-- name: GetBalanceHoldForUpdate :one
+SELECT id, telegram_user_id, currency, amount, reason, metadata, status, expires_at, settled_at, created_at, updated_at FROM balance_holds
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetBalanceHoldForUpdate(ctx context.Context, id pgtype.UUID) (BalanceHold, error) {
+	row := q.db.QueryRow(ctx, getBalanceHoldForUpdate, id)
+	var i BalanceHold
+	err := row.Scan(
+		&i.ID,
+		&i.TelegramUserID,
+		&i.Currency,
+		&i.Amount,
+		&i.Reason,
+		&i.Metadata,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.SettledAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getDepositAddressByRawAddress = `-- name: GetDepositAddressByRawAddress :one
+SELECT id, telegram_user_id, subwallet_id, address, raw_address, last_lt, sweep_pending, last_swept_at, created_at, updated_at FROM deposit_addresses
+WHERE raw_address = $1
+`
+
+func (q *Queries) GetDepositAddressByRawAddress(ctx context.Context, rawAddress string) (DepositAddress, error) {
+	row := q.db.QueryRow(ctx, getDepositAddressByRawAddress, rawAddress)
+	var i DepositAddress
+	err := row.Scan(
+		&i.ID,
+		&i.TelegramUserID,
+		&i.SubwalletID,
+		&i.Address,
+		&i.RawAddress,
+		&i.LastLt,
+		&i.SweepPending,
+		&i.LastSweptAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getDepositAddressByUser = `-- name: GetDepositAddressByUser :one
+SELECT id, telegram_user_id, subwallet_id, address, raw_address, last_lt, sweep_pending, last_swept_at, created_at, updated_at FROM deposit_addresses
+WHERE telegram_user_id = $1
+`
+
+func (q *Queries) GetDepositAddressByUser(ctx context.Context, telegramUserID int64) (DepositAddress, error) {
+	row := q.db.QueryRow(ctx, getDepositAddressByUser, telegramUserID)
+	var i DepositAddress
+	err := row.Scan(
+		&i.ID,
+		&i.TelegramUserID,
+		&i.SubwalletID,
+		&i.Address,
+		&i.RawAddress,
+		&i.LastLt,
+		&i.SweepPending,
+		&i.LastSweptAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getDepositAddressesToSweep = `-- name: GetDepositAddressesToSweep :many
+SELECT id, telegram_user_id, subwallet_id, address, raw_address, last_lt, sweep_pending, last_swept_at, created_at, updated_at FROM deposit_addresses
+WHERE sweep_pending
+ORDER BY updated_at
+LIMIT $1
+`
+
+func (q *Queries) GetDepositAddressesToSweep(ctx context.Context, limit int32) ([]DepositAddress, error) {
+	rows, err := q.db.Query(ctx, getDepositAddressesToSweep, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DepositAddress
+	for rows.Next() {
+		var i DepositAddress
+		if err := rows.Scan(
+			&i.ID,
+			&i.TelegramUserID,
+			&i.SubwalletID,
+			&i.Address,
+			&i.RawAddress,
+			&i.LastLt,
+			&i.SweepPending,
+			&i.LastSweptAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDepositByPayload = `-- name: GetDepositByPayload :one
+SELECT id, telegram_user_id, status, amount_nano, payload, expires_at, tx_hash, tx_lt, created_at, updated_at, received_amount_nano, sender_address, received_at, expired_at, received_mc_seqno, confirmed_at, currency, deposit_address FROM deposits
+WHERE payload = $1
+`
+
+func (q *Queries) GetDepositByPayload(ctx context.Context, payload string) (Deposit, error) {
+	row := q.db.QueryRow(ctx, getDepositByPayload, payload)
+	var i Deposit
+	err := row.Scan(
+		&i.ID,
+		&i.TelegramUserID,
+		&i.Status,
+		&i.AmountNano,
+		&i.Payload,
+		&i.ExpiresAt,
+		&i.TxHash,
+		&i.TxLt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ReceivedAmountNano,
+		&i.SenderAddress,
+		&i.ReceivedAt,
+		&i.ExpiredAt,
+		&i.ReceivedMcSeqno,
+		&i.ConfirmedAt,
+		&i.Currency,
+		&i.DepositAddress,
+	)
+	return i, err
+}
+
+const getDepositsToConfirm = `-- name: GetDepositsToConfirm :many
+SELECT id, telegram_user_id, status, amount_nano, payload, expires_at, tx_hash, tx_lt, created_at, updated_at, received_amount_nano, sender_address, received_at, expired_at, received_mc_seqno, confirmed_at, currency, deposit_address FROM deposits
+WHERE status = 'received'
+  AND received_mc_seqno <= $1
+ORDER BY received_at
+LIMIT $2
+`
+
+type GetDepositsToConfirmParams struct {
+	ReceivedMcSeqno pgtype.Int8
+	Limit           int32
+}
+
+func (q *Queries) GetDepositsToConfirm(ctx context.Context, arg GetDepositsToConfirmParams) ([]Deposit, error) {
+	rows, err := q.db.Query(ctx, getDepositsToConfirm, arg.ReceivedMcSeqno, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Deposit
+	for rows.Next() {
+		var i Deposit
+		if err := rows.Scan(
+			&i.ID,
+			&i.TelegramUserID,
+			&i.Status,
+			&i.AmountNano,
+			&i.Payload,
+			&i.ExpiresAt,
+			&i.TxHash,
+			&i.TxLt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ReceivedAmountNano,
+			&i.SenderAddress,
+			&i.ReceivedAt,
+			&i.ExpiredAt,
+			&i.ReceivedMcSeqno,
+			&i.ConfirmedAt,
+			&i.Currency,
+			&i.DepositAddress,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getExpiredBalanceHolds = `-- name: GetExpiredBalanceHolds :many
+SELECT id, telegram_user_id, currency, amount, reason, metadata, status, expires_at, settled_at, created_at, updated_at FROM balance_holds
+WHERE status = 'active'
+  AND expires_at <= $1
+ORDER BY expires_at
+LIMIT $2
+FOR UPDATE SKIP LOCKED
+`
+
+type GetExpiredBalanceHoldsParams struct {
+	ExpiresAt pgtype.Timestamptz
+	Limit     int32
+}
+
+func (q *Queries) GetExpiredBalanceHolds(ctx context.Context, arg GetExpiredBalanceHoldsParams) ([]BalanceHold, error) {
+	rows, err := q.db.Query(ctx, getExpiredBalanceHolds, arg.ExpiresAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BalanceHold
+	for rows.Next() {
+		var i BalanceHold
+		if err := rows.Scan(
+			&i.ID,
+			&i.TelegramUserID,
+			&i.Currency,
+			&i.Amount,
+			&i.Reason,
+			&i.Metadata,
+			&i.Status,
+			&i.ExpiresAt,
+			&i.SettledAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFeeScheduleByVersion = `-- name: GetFeeScheduleByVersion :one
+SELECT id, version, ton_per_star, effective_from, comment, created_at FROM fee_schedules
+WHERE version = $1
+`
+
+func (q *Queries) GetFeeScheduleByVersion(ctx context.Context, version int64) (FeeSchedule, error) {
+	row := q.db.QueryRow(ctx, getFeeScheduleByVersion, version)
+	var i FeeSchedule
+	err := row.Scan(
+		&i.ID,
+		&i.Version,
+		&i.TonPerStar,
+		&i.EffectiveFrom,
+		&i.Comment,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getFeeSchedulePromotions = `-- name: GetFeeSchedulePromotions :many
+SELECT id, schedule_id, collection, starts_at, ends_at FROM fee_schedule_promotions
+WHERE schedule_id = $1
+ORDER BY starts_at
+`
+
+func (q *Queries) GetFeeSchedulePromotions(ctx context.Context, scheduleID pgtype.UUID) ([]FeeSchedulePromotion, error) {
+	rows, err := q.db.Query(ctx, getFeeSchedulePromotions, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FeeSchedulePromotion
+	for rows.Next() {
+		var i FeeSchedulePromotion
+		if err := rows.Scan(
+			&i.ID,
+			&i.ScheduleID,
+			&i.Collection,
+			&i.StartsAt,
+			&i.EndsAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFeeScheduleTiers = `-- name: GetFeeScheduleTiers :many
+SELECT id, schedule_id, priority, collection, min_price, max_price, rate, min_stars, max_stars FROM fee_schedule_tiers
+WHERE schedule_id = $1
+ORDER BY priority
+`
+
+func (q *Queries) GetFeeScheduleTiers(ctx context.Context, scheduleID pgtype.UUID) ([]FeeScheduleTier, error) {
+	rows, err := q.db.Query(ctx, getFeeScheduleTiers, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FeeScheduleTier
+	for rows.Next() {
+		var i FeeScheduleTier
+		if err := rows.Scan(
+			&i.ID,
+			&i.ScheduleID,
+			&i.Priority,
+			&i.Collection,
+			&i.MinPrice,
+			&i.MaxPrice,
+			&i.Rate,
+			&i.MinStars,
+			&i.MaxStars,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getIdempotencyKey = `-- name: GetIdempotencyKey :one
+SELECT key, operation, request_hash, telegram_user_id, result_amount, created_at FROM idempotency_keys
+WHERE key = $1
+`
+
+func (q *Queries) GetIdempotencyKey(ctx context.Context, key string) (IdempotencyKey, error) {
+	row := q.db.QueryRow(ctx, getIdempotencyKey, key)
+	var i IdempotencyKey
+	err := row.Scan(
+		&i.Key,
+		&i.Operation,
+		&i.RequestHash,
+		&i.TelegramUserID,
+		&i.ResultAmount,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getLedgerBalanceMismatches = `-- name: GetLedgerBalanceMismatches :many
+SELECT
+    COALESCE(b.telegram_user_id, l.telegram_user_id)::bigint AS telegram_user_id,
+    COALESCE(b.currency, l.currency)::currency AS currency,
+    COALESCE(b.ton_amount, 0)::numeric AS cached_amount,
+    COALESCE(l.amount, 0)::numeric AS ledger_amount
+FROM user_balances b
+FULL OUTER JOIN (
+    SELECT a.telegram_user_id, a.currency, COALESCE(SUM(p.amount), 0) AS amount
+    FROM ledger_accounts a
+    LEFT JOIN ledger_postings p ON p.account_id = a.id
+    WHERE a.type = 'user'
+    GROUP BY a.telegram_user_id, a.currency
+) l ON l.telegram_user_id = b.telegram_user_id AND l.currency = b.currency
+WHERE COALESCE(b.ton_amount, 0) <> COALESCE(l.amount, 0)
+ORDER BY 1, 2
+LIMIT $1
+`
+
+type GetLedgerBalanceMismatchesRow struct {
+	TelegramUserID int64
+	Currency       Currency
+	CachedAmount   pgtype.Numeric
+	LedgerAmount   pgtype.Numeric
+}
+
+func (q *Queries) GetLedgerBalanceMismatches(ctx context.Context, limit int32) ([]GetLedgerBalanceMismatchesRow, error) {
+	rows, err := q.db.Query(ctx, getLedgerBalanceMismatches, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetLedgerBalanceMismatchesRow
+	for rows.Next() {
+		var i GetLedgerBalanceMismatchesRow
+		if err := rows.Scan(
+			&i.TelegramUserID,
+			&i.Currency,
+			&i.CachedAmount,
+			&i.LedgerAmount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOrCreateLedgerAccount = `-- name: GetOrCreateLedgerAccount :one
+INSERT INTO ledger_accounts (type, telegram_user_id, currency)
+VALUES ($1, $2, $3)
+ON CONFLICT (type, telegram_user_id, currency)
+DO UPDATE SET type = EXCLUDED.type
+RETURNING id
+`
+
+type GetOrCreateLedgerAccountParams struct {
+	Type           LedgerAccountType
+	TelegramUserID int64
+	Currency       Currency
+}
+
+func (q *Queries) GetOrCreateLedgerAccount(ctx context.Context, arg GetOrCreateLedgerAccountParams) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, getOrCreateLedgerAccount, arg.Type, arg.TelegramUserID, arg.Currency)
+	var id pgtype.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
+const getSystemLedgerBalances = `-- name: GetSystemLedgerBalances :many
+SELECT
+    a.type,
+    a.currency,
+    COALESCE(SUM(p.amount), 0)::numeric AS amount
+FROM ledger_accounts a
+LEFT JOIN ledger_postings p ON p.account_id = a.id
+WHERE a.type <> 'user'
+GROUP BY a.type, a.currency
+ORDER BY a.currency, a.type
+`
+
+type GetSystemLedgerBalancesRow struct {
+	Type     LedgerAccountType
+	Currency Currency
+	Amount   pgtype.Numeric
+}
+
+func (q *Queries) GetSystemLedgerBalances(ctx context.Context) ([]GetSystemLedgerBalancesRow, error) {
+	rows, err := q.db.Query(ctx, getSystemLedgerBalances)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetSystemLedgerBalancesRow
+	for rows.Next() {
+		var i GetSystemLedgerBalancesRow
+		if err := rows.Scan(&i.Type, &i.Currency, &i.Amount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTonCursor = `-- name: GetTonCursor :one
+SELECT last_lt
+FROM ton_cursors
+WHERE network = $1
+  AND wallet_address = $2
+`
+
+type GetTonCursorParams struct {
+	Network       TonNetwork
+	WalletAddress string
+}
+
+func (q *Queries) GetTonCursor(ctx context.Context, arg GetTonCursorParams) (int64, error) {
+	row := q.db.QueryRow(ctx, getTonCursor, arg.Network, arg.WalletAddress)
+	var last_lt int64
+	err := row.Scan(&last_lt)
+	return last_lt, err
+}
+
+const getTonWithdrawalsByStatus = `-- name: GetTonWithdrawalsByStatus :many
+SELECT id, telegram_user_id, destination, amount_nano, status, msg_hash, wallet_seqno, valid_until, tx_hash, tx_lt, failure_reason, created_at, updated_at FROM ton_withdrawals
+WHERE status = $1
+ORDER BY created_at
+LIMIT $2
+`
+
+type GetTonWithdrawalsByStatusParams struct {
+	Status TonWithdrawalStatus
+	Limit  int32
+}
+
+func (q *Queries) GetTonWithdrawalsByStatus(ctx context.Context, arg GetTonWithdrawalsByStatusParams) ([]TonWithdrawal, error) {
+	rows, err := q.db.Query(ctx, getTonWithdrawalsByStatus, arg.Status, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TonWithdrawal
+	for rows.Next() {
+		var i TonWithdrawal
+		if err := rows.Scan(
+			&i.ID,
+			&i.TelegramUserID,
+			&i.Destination,
+			&i.AmountNano,
+			&i.Status,
+			&i.MsgHash,
+			&i.WalletSeqno,
+			&i.ValidUntil,
+			&i.TxHash,
+			&i.TxLt,
+			&i.FailureReason,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUnbalancedLedgerEntries = `-- name: GetUnbalancedLedgerEntries :many
+SELECT
+    e.id,
+    e.reason,
+    e.currency,
+    e.created_at,
+    COUNT(p.id) AS postings_count,
+    COALESCE(SUM(p.amount), 0)::numeric AS total
+FROM ledger_entries e
+LEFT JOIN ledger_postings p ON p.entry_id = e.id
+GROUP BY e.id
+HAVING COUNT(p.id) < 2 OR COALESCE(SUM(p.amount), 0) <> 0
+ORDER BY e.created_at
+LIMIT $1
+`
+
+type GetUnbalancedLedgerEntriesRow struct {
+	ID            pgtype.UUID
+	Reason        TransactionReason
+	Currency      Currency
+	CreatedAt     pgtype.Timestamptz
+	PostingsCount int64
+	Total         pgtype.Numeric
+}
+
+func (q *Queries) GetUnbalancedLedgerEntries(ctx context.Context, limit int32) ([]GetUnbalancedLedgerEntriesRow, error) {
+	rows, err := q.db.Query(ctx, getUnbalancedLedgerEntries, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUnbalancedLedgerEntriesRow
+	for rows.Next() {
+		var i GetUnbalancedLedgerEntriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Reason,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.PostingsCount,
+			&i.Total,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserBalance = `-- name: GetUserBalance :one
+SELECT
+  id,
+  telegram_user_id,
+  ton_amount,
+  created_at,
+  updated_at,
+  currency,
+  held_amount
+FROM user_balances
+WHERE telegram_user_id = $1
+  AND currency = $2
 `
 
-type CreateUserBalanceParams struct {
+type GetUserBalanceParams struct {
 	TelegramUserID int64
-	TonAmount      pgtype.Numeric
+	Currency       Currency
 }
 
-func (q *Queries) CreateUserBalance(ctx context.Context, arg CreateUserBalanceParams) (UserBalance, error) {
-	row := q.db.QueryRow(ctx, createUserBalance, arg.TelegramUserID, arg.TonAmount)
+func (q *Queries) GetUserBalance(ctx context.Context, arg GetUserBalanceParams) (UserBalance, error) {
+	row := q.db.QueryRow(ctx, getUserBalance, arg.TelegramUserID, arg.Currency)
 	var i UserBalance
 	err := row.Scan(
 		&i.ID,
@@ -109,74 +1304,254 @@ func (q *Queries) CreateUserBalance(ctx context.Context, arg CreateUserBalancePa
 		&i.TonAmount,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Currency,
+		&i.HeldAmount,
 	)
 	return i, err
 }
 
-const deleteTransaction = `-- name: DeleteTransaction :exec
-DELETE FROM user_transactions WHERE id = $1
+const getUserBalances = `-- name: GetUserBalances :many
+SELECT
+  id,
+  telegram_user_id,
+  ton_amount,
+  created_at,
+  updated_at,
+  currency,
+  held_amount
+FROM user_balances
+WHERE telegram_user_id = $1
+ORDER BY currency
 `
 
-func (q *Queries) DeleteTransaction(ctx context.Context, id pgtype.UUID) error {
-	_, err := q.db.Exec(ctx, deleteTransaction, id)
-	return err
+func (q *Queries) GetUserBalances(ctx context.Context, telegramUserID int64) ([]UserBalance, error) {
+	rows, err := q.db.Query(ctx, getUserBalances, telegramUserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []UserBalance
+	for rows.Next() {
+		var i UserBalance
+		if err := rows.Scan(
+			&i.ID,
+			&i.TelegramUserID,
+			&i.TonAmount,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Currency,
+			&i.HeldAmount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const getDepositByPayload = `-- name: GetDepositByPayload :one
-SELECT id, telegram_user_id, status, amount_nano, payload, expires_at, tx_hash, tx_lt, created_at, updated_at FROM deposits
-WHERE payload = $1
+const getUserTransactionTotals = `-- name: GetUserTransactionTotals :many
+SELECT
+    reason,
+    currency,
+    SUM(amount)::numeric AS amount,
+    COUNT(*) AS transactions_count
+FROM user_transactions
+WHERE telegram_user_id = $1
+  AND (cardinality($2::text[]) = 0 OR reason::text = ANY($2::text[]))
+  AND ($3::timestamp IS NULL OR created_at >= $3::timestamp)
+  AND ($4::timestamp IS NULL OR created_at < $4::timestamp)
+  AND ($5::numeric IS NULL OR ABS(amount) >= $5::numeric)
+  AND ($6::numeric IS NULL OR ABS(amount) <= $6::numeric)
+  AND (
+    $7::text IS NULL
+    OR metadata->'gift'->>'gift_id' = $7::text
+    OR metadata->'gift'->>'title' ILIKE '%' || $7::text || '%'
+    OR metadata->'gift'->>'slug' ILIKE '%' || $7::text || '%'
+  )
+GROUP BY reason, currency
+ORDER BY reason, currency
 `
 
-func (q *Queries) GetDepositByPayload(ctx context.Context, payload string) (Deposit, error) {
-	row := q.db.QueryRow(ctx, getDepositByPayload, payload)
-	var i Deposit
-	err := row.Scan(
-		&i.ID,
-		&i.TelegramUserID,
-		&i.Status,
-		&i.AmountNano,
-		&i.Payload,
-		&i.ExpiresAt,
-		&i.TxHash,
-		&i.TxLt,
-		&i.CreatedAt,
-		&i.UpdatedAt,
+type GetUserTransactionTotalsParams struct {
+	TelegramUserID int64
+	Reasons        []string
+	CreatedFrom    pgtype.Timestamp
+	CreatedTo      pgtype.Timestamp
+	MinAmount      pgtype.Numeric
+	MaxAmount      pgtype.Numeric
+	Query          pgtype.Text
+}
+
+type GetUserTransactionTotalsRow struct {
+	Reason            TransactionReason
+	Currency          Currency
+	Amount            pgtype.Numeric
+	TransactionsCount int64
+}
+
+func (q *Queries) GetUserTransactionTotals(ctx context.Context, arg GetUserTransactionTotalsParams) ([]GetUserTransactionTotalsRow, error) {
+	rows, err := q.db.Query(ctx, getUserTransactionTotals,
+		arg.TelegramUserID,
+		arg.Reasons,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Query,
 	)
-	return i, err
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserTransactionTotalsRow
+	for rows.Next() {
+		var i GetUserTransactionTotalsRow
+		if err := rows.Scan(
+			&i.Reason,
+			&i.Currency,
+			&i.Amount,
+			&i.TransactionsCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const getTonCursor = `-- name: GetTonCursor :one
-SELECT last_lt
-FROM ton_cursors
-WHERE network = $1
-  AND wallet_address = $2
+const getUserTransactions = `-- name: GetUserTransactions :many
+SELECT id, telegram_user_id, amount, reason, created_at, metadata, currency, entry_id FROM user_transactions
+WHERE telegram_user_id = $1
+  AND (cardinality($2::text[]) = 0 OR reason::text = ANY($2::text[]))
+  AND ($3::timestamp IS NULL OR created_at >= $3::timestamp)
+  AND ($4::timestamp IS NULL OR created_at < $4::timestamp)
+  AND ($5::numeric IS NULL OR ABS(amount) >= $5::numeric)
+  AND ($6::numeric IS NULL OR ABS(amount) <= $6::numeric)
+  AND (
+    $7::text IS NULL
+    OR metadata->'gift'->>'gift_id' = $7::text
+    OR metadata->'gift'->>'title' ILIKE '%' || $7::text || '%'
+    OR metadata->'gift'->>'slug' ILIKE '%' || $7::text || '%'
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT $8 OFFSET $9
 `
 
-type GetTonCursorParams struct {
-	Network       TonNetwork
-	WalletAddress string
+type GetUserTransactionsParams struct {
+	TelegramUserID int64
+	Reasons        []string
+	CreatedFrom    pgtype.Timestamp
+	CreatedTo      pgtype.Timestamp
+	MinAmount      pgtype.Numeric
+	MaxAmount      pgtype.Numeric
+	Query          pgtype.Text
+	LimitCount     int32
+	OffsetCount    int32
 }
 
-func (q *Queries) GetTonCursor(ctx context.Context, arg GetTonCursorParams) (int64, error) {
-	row := q.db.QueryRow(ctx, getTonCursor, arg.Network, arg.WalletAddress)
-	var last_lt int64
-	err := row.Scan(&last_lt)
-	return last_lt, err
+func (q *Queries) GetUserTransactions(ctx context.Context, arg GetUserTransactionsParams) ([]UserTransaction, error) {
+	rows, err := q.db.Query(ctx, getUserTransactions,
+		arg.TelegramUserID,
+		arg.Reasons,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Query,
+		arg.LimitCount,
+		arg.OffsetCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []UserTransaction
+	for rows.Next() {
+		var i UserTransaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.TelegramUserID,
+			&i.Amount,
+			&i.Reason,
+			&i.CreatedAt,
+			&i.Metadata,
+			&i.Currency,
+			&i.EntryID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const getUserBalance = `-- name: GetUserBalance :one
-SELECT
-  id,
-  telegram_user_id,
-  ton_amount,
-  created_at,
-  updated_at
-FROM user_balances
+const getUserTransactionsCount = `-- name: GetUserTransactionsCount :one
+SELECT COUNT(*) FROM user_transactions
 WHERE telegram_user_id = $1
+  AND (cardinality($2::text[]) = 0 OR reason::text = ANY($2::text[]))
+  AND ($3::timestamp IS NULL OR created_at >= $3::timestamp)
+  AND ($4::timestamp IS NULL OR created_at < $4::timestamp)
+  AND ($5::numeric IS NULL OR ABS(amount) >= $5::numeric)
+  AND ($6::numeric IS NULL OR ABS(amount) <= $6::numeric)
+  AND (
+    $7::text IS NULL
+    OR metadata->'gift'->>'gift_id' = $7::text
+    OR metadata->'gift'->>'title' ILIKE '%' || $7::text || '%'
+    OR metadata->'gift'->>'slug' ILIKE '%' || $7::text || '%'
+  )
+`
+
+type GetUserTransactionsCountParams struct {
+	TelegramUserID int64
+	Reasons        []string
+	CreatedFrom    pgtype.Timestamp
+	CreatedTo      pgtype.Timestamp
+	MinAmount      pgtype.Numeric
+	MaxAmount      pgtype.Numeric
+	Query          pgtype.Text
+}
+
+func (q *Queries) GetUserTransactionsCount(ctx context.Context, arg GetUserTransactionsCountParams) (int64, error) {
+	row := q.db.QueryRow(ctx, getUserTransactionsCount,
+		arg.TelegramUserID,
+		arg.Reasons,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Query,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const holdUserBalance = `-- name: HoldUserBalance :one
+UPDATE user_balances AS b
+   SET held_amount = b.held_amount + $2
+ WHERE b.telegram_user_id = $1
+   AND b.currency        = $3
+   AND b.ton_amount - b.held_amount >= $2
+RETURNING id, telegram_user_id, ton_amount, created_at, updated_at, currency, held_amount
 `
 
-func (q *Queries) GetUserBalance(ctx context.Context, telegramUserID int64) (UserBalance, error) {
-	row := q.db.QueryRow(ctx, getUserBalance, telegramUserID)
+type HoldUserBalanceParams struct {
+	TelegramUserID int64
+	HeldAmount     pgtype.Numeric
+	Currency       Currency
+}
+
+func (q *Queries) HoldUserBalance(ctx context.Context, arg HoldUserBalanceParams) (UserBalance, error) {
+	row := q.db.QueryRow(ctx, holdUserBalance, arg.TelegramUserID, arg.HeldAmount, arg.Currency)
 	var i UserBalance
 	err := row.Scan(
 		&i.ID,
@@ -184,39 +1559,89 @@ func (q *Queries) GetUserBalance(ctx context.Context, telegramUserID int64) (Use
 		&i.TonAmount,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Currency,
+		&i.HeldAmount,
 	)
 	return i, err
 }
 
-const getUserTransactions = `-- name: GetUserTransactions :many
-SELECT id, telegram_user_id, amount, reason, created_at, metadata FROM user_transactions
-WHERE telegram_user_id = $1
-ORDER BY created_at DESC
+const listDepositAddresses = `-- name: ListDepositAddresses :many
+SELECT id, telegram_user_id, subwallet_id, address, raw_address, last_lt, sweep_pending, last_swept_at, created_at, updated_at FROM deposit_addresses
+ORDER BY created_at, id
+LIMIT $1 OFFSET $2
+`
+
+type ListDepositAddressesParams struct {
+	Limit  int32
+	Offset int32
+}
+
+func (q *Queries) ListDepositAddresses(ctx context.Context, arg ListDepositAddressesParams) ([]DepositAddress, error) {
+	rows, err := q.db.Query(ctx, listDepositAddresses, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DepositAddress
+	for rows.Next() {
+		var i DepositAddress
+		if err := rows.Scan(
+			&i.ID,
+			&i.TelegramUserID,
+			&i.SubwalletID,
+			&i.Address,
+			&i.RawAddress,
+			&i.LastLt,
+			&i.SweepPending,
+			&i.LastSweptAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUnattributedDeposits = `-- name: ListUnattributedDeposits :many
+SELECT id, tx_hash, tx_lt, sender_address, currency, amount_nano, comment, status, telegram_user_id, assigned_at, created_at, updated_at FROM unattributed_deposits
+WHERE status = $1
+ORDER BY created_at
 LIMIT $2 OFFSET $3
 `
 
-type GetUserTransactionsParams struct {
-	TelegramUserID int64
-	Limit          int32
-	Offset         int32
+type ListUnattributedDepositsParams struct {
+	Status UnattributedDepositStatus
+	Limit  int32
+	Offset int32
 }
 
-func (q *Queries) GetUserTransactions(ctx context.Context, arg GetUserTransactionsParams) ([]UserTransaction, error) {
-	rows, err := q.db.Query(ctx, getUserTransactions, arg.TelegramUserID, arg.Limit, arg.Offset)
+func (q *Queries) ListUnattributedDeposits(ctx context.Context, arg ListUnattributedDepositsParams) ([]UnattributedDeposit, error) {
+	rows, err := q.db.Query(ctx, listUnattributedDeposits, arg.Status, arg.Limit, arg.Offset)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []UserTransaction
+	var items []UnattributedDeposit
 	for rows.Next() {
-		var i UserTransaction
+		var i UnattributedDeposit
 		if err := rows.Scan(
 			&i.ID,
+			&i.TxHash,
+			&i.TxLt,
+			&i.SenderAddress,
+			&i.Currency,
+			&i.AmountNano,
+			&i.Comment,
+			&i.Status,
 			&i.TelegramUserID,
-			&i.Amount,
-			&i.Reason,
+			&i.AssignedAt,
 			&i.CreatedAt,
-			&i.Metadata,
+			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -228,16 +1653,90 @@ func (q *Queries) GetUserTransactions(ctx context.Context, arg GetUserTransactio
 	return items, nil
 }
 
-const getUserTransactionsCount = `-- name: GetUserTransactionsCount :one
-SELECT COUNT(*) FROM user_transactions
-WHERE telegram_user_id = $1
+const markDepositAddressSwept = `-- name: MarkDepositAddressSwept :exec
+UPDATE deposit_addresses
+SET
+    sweep_pending = false,
+    last_swept_at = now(),
+    updated_at = now()
+WHERE id = $1
 `
 
-func (q *Queries) GetUserTransactionsCount(ctx context.Context, telegramUserID int64) (int64, error) {
-	row := q.db.QueryRow(ctx, getUserTransactionsCount, telegramUserID)
-	var count int64
-	err := row.Scan(&count)
-	return count, err
+func (q *Queries) MarkDepositAddressSwept(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, markDepositAddressSwept, id)
+	return err
+}
+
+const markTonWithdrawalsSent = `-- name: MarkTonWithdrawalsSent :execrows
+UPDATE ton_withdrawals
+SET
+    status = 'sent',
+    msg_hash = $1,
+    wallet_seqno = $2,
+    valid_until = $3,
+    updated_at = now()
+WHERE id = ANY($4::uuid[])
+  AND status = 'pending'
+`
+
+type MarkTonWithdrawalsSentParams struct {
+	MsgHash     pgtype.Text
+	WalletSeqno pgtype.Int8
+	ValidUntil  pgtype.Timestamptz
+	Ids         []pgtype.UUID
+}
+
+func (q *Queries) MarkTonWithdrawalsSent(ctx context.Context, arg MarkTonWithdrawalsSentParams) (int64, error) {
+	result, err := q.db.Exec(ctx, markTonWithdrawalsSent,
+		arg.MsgHash,
+		arg.WalletSeqno,
+		arg.ValidUntil,
+		arg.Ids,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const nextDepositSubwalletID = `-- name: NextDepositSubwalletID :one
+SELECT nextval('deposit_address_subwallet_seq')::bigint
+`
+
+func (q *Queries) NextDepositSubwalletID(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, nextDepositSubwalletID)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const releaseUserBalanceHold = `-- name: ReleaseUserBalanceHold :one
+UPDATE user_balances AS b
+   SET held_amount = b.held_amount - $2
+ WHERE b.telegram_user_id = $1
+   AND b.currency        = $3
+RETURNING id, telegram_user_id, ton_amount, created_at, updated_at, currency, held_amount
+`
+
+type ReleaseUserBalanceHoldParams struct {
+	TelegramUserID int64
+	HeldAmount     pgtype.Numeric
+	Currency       Currency
+}
+
+func (q *Queries) ReleaseUserBalanceHold(ctx context.Context, arg ReleaseUserBalanceHoldParams) (UserBalance, error) {
+	row := q.db.QueryRow(ctx, releaseUserBalanceHold, arg.TelegramUserID, arg.HeldAmount, arg.Currency)
+	var i UserBalance
+	err := row.Scan(
+		&i.ID,
+		&i.TelegramUserID,
+		&i.TonAmount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Currency,
+		&i.HeldAmount,
+	)
+	return i, err
 }
 
 const setDepositTransaction = `-- name: SetDepositTransaction :one
@@ -246,19 +1745,34 @@ SET
     status = 'received',
     tx_hash = $2,
     tx_lt = $3,
+    received_amount_nano = $4,
+    sender_address = $5,
+    received_mc_seqno = $6,
+    received_at = now(),
     updated_at = now()
 WHERE id = $1
-RETURNING id, telegram_user_id, status, amount_nano, payload, expires_at, tx_hash, tx_lt, created_at, updated_at
+  AND status IN ('pending', 'expired')
+RETURNING id, telegram_user_id, status, amount_nano, payload, expires_at, tx_hash, tx_lt, created_at, updated_at, received_amount_nano, sender_address, received_at, expired_at, received_mc_seqno, confirmed_at, currency, deposit_address
 `
 
 type SetDepositTransactionParams struct {
-	ID     pgtype.UUID
-	TxHash pgtype.Text
-	TxLt   pgtype.Int8
+	ID                 pgtype.UUID
+	TxHash             pgtype.Text
+	TxLt               pgtype.Int8
+	ReceivedAmountNano pgtype.Int8
+	SenderAddress      pgtype.Text
+	ReceivedMcSeqno    pgtype.Int8
 }
 
 func (q *Queries) SetDepositTransaction(ctx context.Context, arg SetDepositTransactionParams) (Deposit, error) {
-	row := q.db.QueryRow(ctx, setDepositTransaction, arg.ID, arg.TxHash, arg.TxLt)
+	row := q.db.QueryRow(ctx, setDepositTransaction,
+		arg.ID,
+		arg.TxHash,
+		arg.TxLt,
+		arg.ReceivedAmountNano,
+		arg.SenderAddress,
+		arg.ReceivedMcSeqno,
+	)
 	var i Deposit
 	err := row.Scan(
 		&i.ID,
@@ -271,6 +1785,49 @@ func (q *Queries) SetDepositTransaction(ctx context.Context, arg SetDepositTrans
 		&i.TxLt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ReceivedAmountNano,
+		&i.SenderAddress,
+		&i.ReceivedAt,
+		&i.ExpiredAt,
+		&i.ReceivedMcSeqno,
+		&i.ConfirmedAt,
+		&i.Currency,
+		&i.DepositAddress,
+	)
+	return i, err
+}
+
+const settleBalanceHold = `-- name: SettleBalanceHold :one
+UPDATE balance_holds
+SET
+    status = $2,
+    settled_at = now(),
+    updated_at = now()
+WHERE id = $1
+  AND status = 'active'
+RETURNING id, telegram_user_id, currency, amount, reason, metadata, status, expires_at, settled_at, created_at, updated_at
+`
+
+type SettleBalanceHoldParams struct {
+	ID     pgtype.UUID
+	Status BalanceHoldStatus
+}
+
+func (q *Queries) SettleBalanceHold(ctx context.Context, arg SettleBalanceHoldParams) (BalanceHold, error) {
+	row := q.db.QueryRow(ctx, settleBalanceHold, arg.ID, arg.Status)
+	var i BalanceHold
+	err := row.Scan(
+		&i.ID,
+		&i.TelegramUserID,
+		&i.Currency,
+		&i.Amount,
+		&i.Reason,
+		&i.Metadata,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.SettledAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
@@ -279,17 +1836,20 @@ const spendUserBalance = `-- name: SpendUserBalance :one
 UPDATE user_balances AS b
    SET ton_amount = b.ton_amount - $2
  WHERE b.telegram_user_id = $1
-   AND b.ton_amount      >= $2
-RETURNING id, telegram_user_id, ton_amount, created_at, updated_at
+   AND b.currency        = $3
+   -- зарезервированное холдами списать нельзя
+   AND b.ton_amount - b.held_amount >= $2
+RETURNING id, telegram_user_id, ton_amount, created_at, updated_at, currency, held_amount
 `
 
 type SpendUserBalanceParams struct {
 	TelegramUserID int64
 	TonAmount      pgtype.Numeric
+	Currency       Currency
 }
 
 func (q *Queries) SpendUserBalance(ctx context.Context, arg SpendUserBalanceParams) (UserBalance, error) {
-	row := q.db.QueryRow(ctx, spendUserBalance, arg.TelegramUserID, arg.TonAmount)
+	row := q.db.QueryRow(ctx, spendUserBalance, arg.TelegramUserID, arg.TonAmount, arg.Currency)
 	var i UserBalance
 	err := row.Scan(
 		&i.ID,
@@ -297,10 +1857,32 @@ func (q *Queries) SpendUserBalance(ctx context.Context, arg SpendUserBalancePara
 		&i.TonAmount,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Currency,
+		&i.HeldAmount,
 	)
 	return i, err
 }
 
+const updateDepositAddressCursor = `-- name: UpdateDepositAddressCursor :exec
+UPDATE deposit_addresses
+SET
+    last_lt = $1,
+    sweep_pending = sweep_pending OR $2::boolean,
+    updated_at = now()
+WHERE id = $3
+`
+
+type UpdateDepositAddressCursorParams struct {
+	LastLt       int64
+	SweepPending bool
+	ID           pgtype.UUID
+}
+
+func (q *Queries) UpdateDepositAddressCursor(ctx context.Context, arg UpdateDepositAddressCursorParams) error {
+	_, err := q.db.Exec(ctx, updateDepositAddressCursor, arg.LastLt, arg.SweepPending, arg.ID)
+	return err
+}
+
 const upsertTonCursor = `-- name: UpsertTonCursor :exec
 INSERT INTO ton_cursors (network, wallet_address, last_lt)
 VALUES ($1, $2, $3)
@@ -321,21 +1903,22 @@ func (q *Queries) UpsertTonCursor(ctx context.Context, arg UpsertTonCursorParams
 }
 
 const upsertUserBalance = `-- name: UpsertUserBalance :one
-INSERT INTO user_balances (telegram_user_id, ton_amount)
-VALUES ($1, $2)
-ON CONFLICT (telegram_user_id)
+INSERT INTO user_balances (telegram_user_id, ton_amount, currency)
+VALUES ($1, $2, $3)
+ON CONFLICT (telegram_user_id, currency)
 DO UPDATE
   SET ton_amount = user_balances.ton_amount + EXCLUDED.ton_amount
-RETURNING id, telegram_user_id, ton_amount, created_at, updated_at
+RETURNING id, telegram_user_id, ton_amount, created_at, updated_at, currency, held_amount
 `
 
 type UpsertUserBalanceParams struct {
 	TelegramUserID int64
 	TonAmount      pgtype.Numeric
+	Currency       Currency
 }
 
 func (q *Queries) UpsertUserBalance(ctx context.Context, arg UpsertUserBalanceParams) (UserBalance, error) {
-	row := q.db.QueryRow(ctx, upsertUserBalance, arg.TelegramUserID, arg.TonAmount)
+	row := q.db.QueryRow(ctx, upsertUserBalance, arg.TelegramUserID, arg.TonAmount, arg.Currency)
 	var i UserBalance
 	err := row.Scan(
 		&i.ID,
@@ -343,6 +1926,8 @@ func (q *Queries) UpsertUserBalance(ctx context.Context, arg UpsertUserBalancePa
 		&i.TonAmount,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Currency,
+		&i.HeldAmount,
 	)
 	return i, err
 }
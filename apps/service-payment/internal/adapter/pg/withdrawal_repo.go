@@ -0,0 +1,139 @@
+package pg
+
+import (
+	"context"
+
+	"github.com/ccoveille/go-safecast"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/adapter/pg/sqlc"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/ton"
+	"github.com/peterparker2005/giftduels/packages/inbox"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+)
+
+type WithdrawalRepository struct {
+	q      *sqlc.Queries
+	logger *logger.Logger
+}
+
+func NewWithdrawalRepository(pool *pgxpool.Pool, logger *logger.Logger) ton.WithdrawalRepository {
+	return &WithdrawalRepository{
+		q:      sqlc.New(inbox.NewDB(pool)),
+		logger: logger,
+	}
+}
+
+func (r *WithdrawalRepository) WithTx(tx pgx.Tx) ton.WithdrawalRepository {
+	return &WithdrawalRepository{
+		q:      r.q.WithTx(tx),
+		logger: r.logger,
+	}
+}
+
+func (r *WithdrawalRepository) CreateWithdrawal(
+	ctx context.Context,
+	params *ton.CreateWithdrawalParams,
+) (*ton.Withdrawal, error) {
+	amountNano, err := safecast.ToInt64(params.AmountNano)
+	if err != nil {
+		return nil, err
+	}
+	w, err := r.q.CreateTonWithdrawal(ctx, sqlc.CreateTonWithdrawalParams{
+		TelegramUserID: params.TelegramUserID,
+		Destination:    params.Destination,
+		AmountNano:     amountNano,
+	})
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	return ToWithdrawalDomain(w), nil
+}
+
+func (r *WithdrawalRepository) GetWithdrawalsByStatus(
+	ctx context.Context,
+	status ton.WithdrawalStatus,
+	limit int32,
+) ([]*ton.Withdrawal, error) {
+	rows, err := r.q.GetTonWithdrawalsByStatus(ctx, sqlc.GetTonWithdrawalsByStatusParams{
+		Status: sqlc.TonWithdrawalStatus(status),
+		Limit:  limit,
+	})
+	if err != nil {
+		return nil, MapPGError(err)
+	}
+	withdrawals := make([]*ton.Withdrawal, len(rows))
+	for i, w := range rows {
+		withdrawals[i] = ToWithdrawalDomain(w)
+	}
+	return withdrawals, nil
+}
+
+func (r *WithdrawalRepository) MarkWithdrawalsSent(
+	ctx context.Context,
+	params *ton.MarkWithdrawalsSentParams,
+) error {
+	ids := make([]pgtype.UUID, len(params.IDs))
+	for i, id := range params.IDs {
+		pgID, err := pgUUID(id)
+		if err != nil {
+			return err
+		}
+		ids[i] = pgID
+	}
+	_, err := r.q.MarkTonWithdrawalsSent(ctx, sqlc.MarkTonWithdrawalsSentParams{
+		MsgHash:     pgtype.Text{String: params.MsgHash, Valid: true},
+		WalletSeqno: pgtype.Int8{Int64: int64(params.WalletSeqno), Valid: true},
+		ValidUntil:  pgtype.Timestamptz{Time: params.ValidUntil, Valid: true},
+		Ids:         ids,
+	})
+	return MapPGError(err)
+}
+
+func (r *WithdrawalRepository) ConfirmWithdrawal(
+	ctx context.Context,
+	params *ton.ConfirmWithdrawalParams,
+) error {
+	id, err := pgUUID(params.ID)
+	if err != nil {
+		return err
+	}
+	txLt, err := safecast.ToInt64(params.TxLt)
+	if err != nil {
+		return err
+	}
+	updated, err := r.q.ConfirmTonWithdrawal(ctx, sqlc.ConfirmTonWithdrawalParams{
+		ID:     id,
+		TxHash: pgtype.Text{String: params.TxHash, Valid: true},
+		TxLt:   pgtype.Int8{Int64: txLt, Valid: true},
+	})
+	if err != nil {
+		return MapPGError(err)
+	}
+	if updated == 0 {
+		return ton.ErrWithdrawalNotFound
+	}
+	return nil
+}
+
+func (r *WithdrawalRepository) FailWithdrawal(
+	ctx context.Context,
+	id, reason string,
+) (*ton.Withdrawal, error) {
+	pgID, err := pgUUID(id)
+	if err != nil {
+		return nil, err
+	}
+	w, err := r.q.FailTonWithdrawal(ctx, sqlc.FailTonWithdrawalParams{
+		ID:            pgID,
+		FailureReason: pgtype.Text{String: reason, Valid: true},
+	})
+	if err != nil {
+		if IsNotFound(MapPGError(err)) {
+			return nil, ton.ErrWithdrawalNotFound
+		}
+		return nil, MapPGError(err)
+	}
+	return ToWithdrawalDomain(w), nil
+}
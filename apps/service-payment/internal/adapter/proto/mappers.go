@@ -7,6 +7,7 @@ import (
 	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/payment"
 	paymentv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/payment/v1"
 	sharedv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/shared/v1"
+	"github.com/peterparker2005/giftduels/packages/tonamount-go"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -20,6 +21,16 @@ func TransactionReasonToDomain(
 		return payment.TransactionReasonRefund, nil
 	case paymentv1.TransactionReason_TRANSACTION_REASON_DEPOSIT:
 		return payment.TransactionReasonDeposit, nil
+	case paymentv1.TransactionReason_TRANSACTION_REASON_PURCHASE:
+		return payment.TransactionReasonPurchase, nil
+	case paymentv1.TransactionReason_TRANSACTION_REASON_SALE:
+		return payment.TransactionReasonSale, nil
+	case paymentv1.TransactionReason_TRANSACTION_REASON_SELL_BACK:
+		return payment.TransactionReasonSellBack, nil
+	case paymentv1.TransactionReason_TRANSACTION_REASON_TON_WITHDRAWAL:
+		return payment.TransactionReasonTonWithdrawal, nil
+	case paymentv1.TransactionReason_TRANSACTION_REASON_OPENING_BALANCE:
+		return payment.TransactionReasonOpeningBalance, nil
 	case paymentv1.TransactionReason_TRANSACTION_REASON_UNSPECIFIED:
 		return "", errors.New("transaction reason is unspecified")
 	default:
@@ -37,6 +48,16 @@ func TransactionReasonToProto(
 		return paymentv1.TransactionReason_TRANSACTION_REASON_REFUND, nil
 	case payment.TransactionReasonDeposit:
 		return paymentv1.TransactionReason_TRANSACTION_REASON_DEPOSIT, nil
+	case payment.TransactionReasonPurchase:
+		return paymentv1.TransactionReason_TRANSACTION_REASON_PURCHASE, nil
+	case payment.TransactionReasonSale:
+		return paymentv1.TransactionReason_TRANSACTION_REASON_SALE, nil
+	case payment.TransactionReasonSellBack:
+		return paymentv1.TransactionReason_TRANSACTION_REASON_SELL_BACK, nil
+	case payment.TransactionReasonTonWithdrawal:
+		return paymentv1.TransactionReason_TRANSACTION_REASON_TON_WITHDRAWAL, nil
+	case payment.TransactionReasonOpeningBalance:
+		return paymentv1.TransactionReason_TRANSACTION_REASON_OPENING_BALANCE, nil
 	default:
 		return paymentv1.TransactionReason_TRANSACTION_REASON_UNSPECIFIED, fmt.Errorf(
 			"unknown transaction reason: %v",
@@ -45,6 +66,139 @@ func TransactionReasonToProto(
 	}
 }
 
+func TransactionFilterToDomain(f *paymentv1.TransactionFilter) (*payment.TransactionFilter, error) {
+	filter := &payment.TransactionFilter{Query: f.GetQuery()}
+	for _, r := range f.GetReasons() {
+		reason, err := TransactionReasonToDomain(r)
+		if err != nil {
+			return nil, err
+		}
+		filter.Reasons = append(filter.Reasons, reason)
+	}
+	if from := f.GetCreatedAt().GetFrom(); from != nil {
+		t := from.AsTime()
+		filter.From = &t
+	}
+	if to := f.GetCreatedAt().GetTo(); to != nil {
+		t := to.AsTime()
+		filter.To = &t
+	}
+	if v := f.GetMinAmount().GetValue(); v != "" {
+		amount, err := tonamount.NewTonAmountFromString(v)
+		if err != nil {
+			return nil, err
+		}
+		filter.MinAmount = amount
+	}
+	if v := f.GetMaxAmount().GetValue(); v != "" {
+		amount, err := tonamount.NewTonAmountFromString(v)
+		if err != nil {
+			return nil, err
+		}
+		filter.MaxAmount = amount
+	}
+	return filter, nil
+}
+
+func TransactionTotalToProto(t *payment.TransactionTotal) (*paymentv1.TransactionReasonTotal, error) {
+	reason, err := TransactionReasonToProto(t.Reason)
+	if err != nil {
+		return nil, err
+	}
+	return &paymentv1.TransactionReasonTotal{
+		Reason:   reason,
+		Currency: CurrencyToProto(t.Currency),
+		Amount:   t.Amount.String(),
+		Count:    t.Count,
+	}, nil
+}
+
+func ExportFormatToDomain(format paymentv1.TransactionExportFormat) (payment.ExportFormat, error) {
+	switch format {
+	case paymentv1.TransactionExportFormat_TRANSACTION_EXPORT_FORMAT_CSV,
+		paymentv1.TransactionExportFormat_TRANSACTION_EXPORT_FORMAT_UNSPECIFIED:
+		return payment.ExportFormatCSV, nil
+	case paymentv1.TransactionExportFormat_TRANSACTION_EXPORT_FORMAT_JSON:
+		return payment.ExportFormatJSON, nil
+	default:
+		return "", fmt.Errorf("unknown export format: %v", format)
+	}
+}
+
+func CurrencyToDomain(currency paymentv1.Currency) (payment.Currency, error) {
+	switch currency {
+	case paymentv1.Currency_CURRENCY_TON, paymentv1.Currency_CURRENCY_UNSPECIFIED:
+		return payment.CurrencyTON, nil
+	case paymentv1.Currency_CURRENCY_USDT:
+		return payment.CurrencyUSDT, nil
+	default:
+		return "", fmt.Errorf("%w: %v", payment.ErrUnknownCurrency, currency)
+	}
+}
+
+func CurrencyToProto(currency payment.Currency) paymentv1.Currency {
+	switch currency {
+	case payment.CurrencyTON:
+		return paymentv1.Currency_CURRENCY_TON
+	case payment.CurrencyUSDT:
+		return paymentv1.Currency_CURRENCY_USDT
+	default:
+		return paymentv1.Currency_CURRENCY_UNSPECIFIED
+	}
+}
+
+func BalancesToProto(balances []*payment.Balance) []*paymentv1.CurrencyBalanceView {
+	views := make([]*paymentv1.CurrencyBalanceView, 0, len(balances))
+	for _, b := range balances {
+		views = append(views, &paymentv1.CurrencyBalanceView{
+			Currency:  CurrencyToProto(b.Currency),
+			Amount:    b.TonAmount.String(),
+			Available: b.Available().String(),
+			Held:      b.Held().String(),
+		})
+	}
+	return views
+}
+
+func BalanceViewToProto(b *payment.Balance) *paymentv1.UserBalanceView {
+	return &paymentv1.UserBalanceView{
+		TonAmount: &sharedv1.TonAmount{Value: b.TonAmount.String()},
+		Available: &sharedv1.TonAmount{Value: b.Available().String()},
+		Held:      &sharedv1.TonAmount{Value: b.Held().String()},
+	}
+}
+
+func HoldStatusToProto(status payment.HoldStatus) paymentv1.BalanceHoldStatus {
+	switch status {
+	case payment.HoldStatusActive:
+		return paymentv1.BalanceHoldStatus_BALANCE_HOLD_STATUS_ACTIVE
+	case payment.HoldStatusCaptured:
+		return paymentv1.BalanceHoldStatus_BALANCE_HOLD_STATUS_CAPTURED
+	case payment.HoldStatusReleased:
+		return paymentv1.BalanceHoldStatus_BALANCE_HOLD_STATUS_RELEASED
+	case payment.HoldStatusExpired:
+		return paymentv1.BalanceHoldStatus_BALANCE_HOLD_STATUS_EXPIRED
+	default:
+		return paymentv1.BalanceHoldStatus_BALANCE_HOLD_STATUS_UNSPECIFIED
+	}
+}
+
+func HoldToProto(h *payment.Hold) (*paymentv1.BalanceHold, error) {
+	reason, err := TransactionReasonToProto(h.Reason)
+	if err != nil {
+		return nil, err
+	}
+	return &paymentv1.BalanceHold{
+		HoldId:         h.ID.String(),
+		TelegramUserId: &sharedv1.TelegramUserId{Value: h.TelegramUserID},
+		TonAmount:      &sharedv1.TonAmount{Value: h.Amount.String()},
+		Reason:         reason,
+		Status:         HoldStatusToProto(h.Status),
+		ExpiresAt:      timestamppb.New(h.ExpiresAt),
+		CreatedAt:      timestamppb.New(h.CreatedAt),
+	}, nil
+}
+
 func TransactionToProto(t *payment.Transaction) (*paymentv1.TransactionView, error) {
 	reason, err := TransactionReasonToProto(t.Reason)
 	if err != nil {
@@ -61,6 +215,7 @@ func TransactionToProto(t *payment.Transaction) (*paymentv1.TransactionView, err
 		},
 		Reason:    reason,
 		Metadata:  metadata,
+		Currency:  CurrencyToProto(t.Currency),
 		CreatedAt: timestamppb.New(t.CreatedAt),
 	}, nil
 }
@@ -68,15 +223,41 @@ func TransactionToProto(t *payment.Transaction) (*paymentv1.TransactionView, err
 func TransactionMetadataToProto(
 	m *payment.TransactionMetadata,
 ) (*paymentv1.TransactionMetadata, error) {
-	if m == nil || m.Gift == nil {
-		return nil, errors.New("transaction metadata is nil or gift is nil")
+	if m == nil {
+		return nil, errors.New("transaction metadata is nil")
+	}
+	if m.TonWithdrawal != nil {
+		return &paymentv1.TransactionMetadata{
+			Data: &paymentv1.TransactionMetadata_TonWithdrawal{
+				TonWithdrawal: &paymentv1.TransactionMetadata_TonWithdrawalDetails{
+					WithdrawalId: m.TonWithdrawal.WithdrawalID,
+					Destination:  m.TonWithdrawal.Destination,
+				},
+			},
+		}, nil
+	}
+	if m.Deposit != nil {
+		return &paymentv1.TransactionMetadata{
+			Data: &paymentv1.TransactionMetadata_Deposit{
+				Deposit: &paymentv1.TransactionMetadata_DepositDetails{
+					DepositId: m.Deposit.DepositID,
+					Currency:  CurrencyToProto(m.Deposit.Currency),
+					Amount:    m.Deposit.Amount,
+					Rate:      m.Deposit.Rate,
+				},
+			},
+		}, nil
+	}
+	if m.Gift == nil {
+		return nil, errors.New("transaction metadata gift is nil")
 	}
 	return &paymentv1.TransactionMetadata{
 		Data: &paymentv1.TransactionMetadata_Gift{
 			Gift: &paymentv1.TransactionMetadata_GiftDetails{
-				GiftId: m.Gift.GiftID,
-				Title:  m.Gift.Title,
-				Slug:   m.Gift.Slug,
+				GiftId:               m.Gift.GiftID,
+				Title:                m.Gift.Title,
+				Slug:                 m.Gift.Slug,
+				WithdrawalCommission: m.Gift.WithdrawalCommission,
 			},
 		},
 	}, nil
@@ -95,9 +276,34 @@ func TransactionMetadataToDomain(
 		}
 		return &payment.TransactionMetadata{
 			Gift: &payment.TransactionMetadataGiftDetails{
-				GiftID: metadata.Gift.GetGiftId(),
-				Title:  metadata.Gift.GetTitle(),
-				Slug:   metadata.Gift.GetSlug(),
+				GiftID:               metadata.Gift.GetGiftId(),
+				Title:                metadata.Gift.GetTitle(),
+				Slug:                 metadata.Gift.GetSlug(),
+				WithdrawalCommission: metadata.Gift.GetWithdrawalCommission(),
+			},
+		}, nil
+	case *paymentv1.TransactionMetadata_TonWithdrawal:
+		if metadata.TonWithdrawal == nil {
+			return nil, errors.New("transaction metadata ton withdrawal is nil")
+		}
+		return payment.NewTonWithdrawalMetadata(
+			metadata.TonWithdrawal.GetWithdrawalId(),
+			metadata.TonWithdrawal.GetDestination(),
+		), nil
+	case *paymentv1.TransactionMetadata_Deposit:
+		if metadata.Deposit == nil {
+			return nil, errors.New("transaction metadata deposit is nil")
+		}
+		currency, err := CurrencyToDomain(metadata.Deposit.GetCurrency())
+		if err != nil {
+			return nil, err
+		}
+		return &payment.TransactionMetadata{
+			Deposit: &payment.TransactionMetadataDepositDetails{
+				DepositID: metadata.Deposit.GetDepositId(),
+				Currency:  currency,
+				Amount:    metadata.Deposit.GetAmount(),
+				Rate:      metadata.Deposit.GetRate(),
 			},
 		}, nil
 	default:
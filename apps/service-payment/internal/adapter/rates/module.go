@@ -0,0 +1,10 @@
+package rates
+
+import "go.uber.org/fx"
+
+//nolint:gochecknoglobals // fx module pattern
+var Module = fx.Module("rates",
+	fx.Provide(
+		NewStaticProvider,
+	),
+)
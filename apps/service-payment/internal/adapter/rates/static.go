@@ -0,0 +1,48 @@
+package rates
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/config"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/payment"
+	"github.com/shopspring/decimal"
+)
+
+type pair struct {
+	from, to payment.Currency
+}
+
+// StaticProvider отдаёт курсы, заданные в конфигурации.
+type StaticProvider struct {
+	rates map[pair]decimal.Decimal
+}
+
+func NewStaticProvider(cfg *config.Config) (payment.RateProvider, error) {
+	p := &StaticProvider{rates: map[pair]decimal.Decimal{}}
+
+	if cfg.Ton.USDT.TonRate != "" {
+		rate, err := decimal.NewFromString(cfg.Ton.USDT.TonRate)
+		if err != nil || !rate.IsPositive() {
+			return nil, fmt.Errorf("invalid usdt ton rate %q", cfg.Ton.USDT.TonRate)
+		}
+		p.rates[pair{payment.CurrencyUSDT, payment.CurrencyTON}] = rate
+	}
+	if cfg.Ton.USDT.ConvertToTon {
+		if _, ok := p.rates[pair{payment.CurrencyUSDT, payment.CurrencyTON}]; !ok {
+			return nil, fmt.Errorf("usdt conversion to ton requires a rate")
+		}
+	}
+	return p, nil
+}
+
+func (p *StaticProvider) Rate(_ context.Context, from, to payment.Currency) (decimal.Decimal, error) {
+	if from == to {
+		return decimal.NewFromInt(1), nil
+	}
+	rate, ok := p.rates[pair{from, to}]
+	if !ok {
+		return decimal.Decimal{}, fmt.Errorf("%w: %s/%s", payment.ErrRateUnavailable, from, to)
+	}
+	return rate, nil
+}
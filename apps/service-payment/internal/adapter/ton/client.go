@@ -2,7 +2,10 @@ package ton
 
 import (
 	"context"
+	"encoding/hex"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/peterparker2005/giftduels/apps/service-payment/internal/config"
 	domain "github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/ton"
@@ -13,6 +16,7 @@ import (
 	"github.com/xssnick/tonutils-go/liteclient"
 	"github.com/xssnick/tonutils-go/tlb"
 	"github.com/xssnick/tonutils-go/ton"
+	"github.com/xssnick/tonutils-go/ton/wallet"
 	"go.uber.org/zap"
 )
 
@@ -24,6 +28,11 @@ type adapter struct {
 	api    ton.APIClientWrapped
 	cfg    *liteclient.GlobalConfig
 	logger *logger.Logger
+
+	// wallet — казначейский кошелёк для вывода; nil, если seed не задан.
+	wallet     *wallet.Wallet
+	messageTTL time.Duration
+	signMu     sync.Mutex
 }
 
 const (
@@ -57,7 +66,13 @@ func NewTonAPI(appCfg *config.Config, logger *logger.Logger) (domain.API, error)
 	if err != nil {
 		return nil, fmt.Errorf("get masterchain info: %w", err)
 	}
-	return &adapter{api: api, cfg: cfg, logger: logger}, nil
+	a := &adapter{api: api, cfg: cfg, logger: logger, messageTTL: appCfg.Ton.Withdrawal.MessageTTL}
+	if appCfg.Ton.WalletSeed != "" {
+		if a.wallet, err = newTreasuryWallet(api, appCfg); err != nil {
+			return nil, fmt.Errorf("treasury wallet: %w", err)
+		}
+	}
+	return a, nil
 }
 
 func (a *adapter) CurrentMasterchainInfo(ctx context.Context) (domain.MasterchainInfo, error) {
@@ -84,9 +99,14 @@ func (a *adapter) SubscribeTransactions(
 	ctx context.Context,
 	addrStr string,
 	fromLT uint64,
+	jettonWallets map[string]domain.Jetton,
 	out chan<- domain.Transaction,
 ) error {
 	addr := address.MustParseAddr(addrStr)
+	jettons, err := jettonWalletIndex(jettonWallets)
+	if err != nil {
+		return err
+	}
 	// запускаем в фоне
 	// rawCh – канал для низкоуровневых tlb.Transaction
 	rawCh := make(chan *tlb.Transaction)
@@ -106,46 +126,66 @@ func (a *adapter) SubscribeTransactions(
 	// 2) параллельно читать из rawCh, конвертить и форвардить в out
 	go func() {
 		for raw := range rawCh {
-			if raw.IO.In == nil || raw.IO.In.MsgType != tlb.MsgTypeInternal {
-				continue
-			}
-			ti := raw.IO.In.AsInternal()
-			sender := ti.SrcAddr.String()
-			amount, err := tonamount.NewTonAmountFromNano(ti.Amount.Nano().Uint64())
-			if err != nil {
-				a.logger.Warn(
-					"invalid amount",
-					zap.String("amount", ti.Amount.Nano().String()),
-					zap.Error(err),
-				)
-				continue
-			}
-			currency := "TON"
-
-			// Extract payload from transaction body
-			payload := ""
-			if ti.Body != nil {
-				// Convert entire body to BOC base64 (this is what tonworker expects)
-				bocBytes := ti.Body.ToBOC()
-				if len(
-					bocBytes,
-				) > bocMinDocBytes { // Skip empty BOC (usually 2 bytes for empty cell)
-					payload = boc.EncodeBOCAsBase64(bocBytes)
-					a.logger.Debug("📦 Extracted BOC payload",
-						zap.String("payload", payload),
-						zap.Int("bocLength", len(bocBytes)))
-				}
-			}
-
-			out <- domain.Transaction{
-				Sender:   sender,
-				Amount:   amount,
-				Currency: currency,
-				Payload:  payload,
-				LastLT:   raw.LT,
+			if tx, ok := a.toDomainTransaction(raw, jettons); ok {
+				out <- tx
 			}
 		}
 		a.logger.Info("✅ Forwarding loop ended, out channel will not get more messages")
 	}()
 	return nil
 }
+
+func (a *adapter) toDomainTransaction(
+	raw *tlb.Transaction,
+	jettons map[string]domain.Jetton,
+) (domain.Transaction, bool) {
+	if raw.IO.In == nil || raw.IO.In.MsgType != tlb.MsgTypeInternal {
+		return domain.Transaction{}, false
+	}
+	ti := raw.IO.In.AsInternal()
+	// возврат нашего же перевода — не пополнение
+	if ti.Bounced {
+		return domain.Transaction{}, false
+	}
+	if j, ok := jettons[ti.SrcAddr.StringRaw()]; ok {
+		return a.jettonTransaction(raw, ti, j)
+	}
+
+	sender := ti.SrcAddr.String()
+	nano := ti.Amount.Nano().Uint64()
+	amount, err := tonamount.NewTonAmountFromNano(nano)
+	if err != nil {
+		a.logger.Warn(
+			"invalid amount",
+			zap.String("amount", ti.Amount.Nano().String()),
+			zap.Error(err),
+		)
+		return domain.Transaction{}, false
+	}
+	currency := "TON"
+
+	// Extract payload from transaction body
+	payload := ""
+	if ti.Body != nil {
+		// Convert entire body to BOC base64 (this is what tonworker expects)
+		bocBytes := ti.Body.ToBOC()
+		if len(
+			bocBytes,
+		) > bocMinDocBytes { // Skip empty BOC (usually 2 bytes for empty cell)
+			payload = boc.EncodeBOCAsBase64(bocBytes)
+			a.logger.Debug("📦 Extracted BOC payload",
+				zap.String("payload", payload),
+				zap.Int("bocLength", len(bocBytes)))
+		}
+	}
+
+	return domain.Transaction{
+		Sender:   sender,
+		Amount:   amount,
+		Units:    nano,
+		Currency: currency,
+		Payload:  payload,
+		Hash:     hex.EncodeToString(raw.Hash),
+		LastLT:   raw.LT,
+	}, true
+}
@@ -0,0 +1,182 @@
+package ton
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/config"
+	domain "github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/ton"
+	"github.com/xssnick/tonutils-go/address"
+	"github.com/xssnick/tonutils-go/tlb"
+	"github.com/xssnick/tonutils-go/ton"
+	"github.com/xssnick/tonutils-go/ton/wallet"
+	"go.uber.org/zap"
+)
+
+// sweepMode — отдать весь баланс подкошелька и удалить пустой аккаунт:
+// хранить состояние адреса без средств незачем, при следующем платеже
+// он развернётся заново.
+const sweepMode = wallet.CarryAllRemainingBalance + wallet.DestroyAccountIfZero + wallet.IgnoreErrors
+
+type addressDeriver struct {
+	// root — казначейский кошелёк без подключения к сети; nil, если seed не задан.
+	root    *wallet.Wallet
+	testnet bool
+}
+
+// NewAddressDeriver создаёт вычислитель адресов подкошельков. Сеть не
+// нужна, поэтому адреса может выдавать и gRPC-сервер.
+func NewAddressDeriver(cfg *config.Config) (domain.AddressDeriver, error) {
+	d := &addressDeriver{testnet: cfg.Ton.Network != config.TonNetworkMainnet}
+	if cfg.Ton.WalletSeed == "" {
+		return d, nil
+	}
+	root, err := wallet.FromSeed(nil, strings.Fields(cfg.Ton.WalletSeed), wallet.V4R2)
+	if err != nil {
+		return nil, fmt.Errorf("treasury seed: %w", err)
+	}
+	d.root = root
+	return d, nil
+}
+
+func (d *addressDeriver) DeriveAddress(subwalletID uint32) (string, string, error) {
+	if d.root == nil {
+		return "", "", ErrTreasuryWalletNotConfigured
+	}
+	sub, err := d.root.GetSubwallet(subwalletID)
+	if err != nil {
+		return "", "", fmt.Errorf("derive subwallet %d: %w", subwalletID, err)
+	}
+	// Неразвёрнутый кошелёк вернул бы bounceable-перевод отправителю.
+	addr := sub.WalletAddress().Bounce(false).Testnet(d.testnet)
+	return addr.String(), addr.StringRaw(), nil
+}
+
+func (a *adapter) GetIncomingTransactions(
+	ctx context.Context,
+	addrStr string,
+	afterLT uint64,
+) ([]domain.Transaction, uint64, error) {
+	addr, err := address.ParseAddr(addrStr)
+	if err != nil {
+		return nil, 0, fmt.Errorf("parse address %q: %w", addrStr, err)
+	}
+	block, err := a.api.CurrentMasterchainInfo(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	acc, err := a.api.WaitForBlock(block.SeqNo).GetAccount(ctx, block, addr)
+	if err != nil {
+		return nil, 0, fmt.Errorf("get account: %w", err)
+	}
+	if acc.LastTxLT <= afterLT {
+		return nil, afterLT, nil
+	}
+
+	// Транзакции читаются от последней к более старым, пока не дойдём
+	// до курсора. Если новых транзакций больше txScanLimit, за проход
+	// отдаются самые старые из них, а курсор встаёт на последнюю отданную:
+	// более новые дочитаются следующим проходом.
+	var raws []*tlb.Transaction
+	truncated := false
+	lt, hash := acc.LastTxLT, acc.LastTxHash
+	for lt > afterLT {
+		page, listErr := a.api.ListTransactions(ctx, addr, 16, lt, hash)
+		if errors.Is(listErr, ton.ErrNoTransactionsWereFound) {
+			break
+		}
+		if listErr != nil {
+			return nil, 0, fmt.Errorf("list transactions: %w", listErr)
+		}
+		// страница упорядочена от старых к новым
+		for i := len(page) - 1; i >= 0; i-- {
+			if page[i].LT <= afterLT {
+				lt = 0
+				break
+			}
+			raws = append(raws, page[i])
+			if len(raws) > txScanLimit {
+				raws = raws[1:]
+				truncated = true
+			}
+		}
+		if lt == 0 || len(page) == 0 {
+			break
+		}
+		oldest := page[0]
+		lt, hash = oldest.PrevTxLT, oldest.PrevTxHash
+	}
+
+	nextLT := acc.LastTxLT
+	if truncated {
+		nextLT = raws[0].LT
+		a.logger.Warn("deposit address scan limit reached, newer transactions left for the next pass",
+			zap.String("addr", addrStr),
+			zap.Uint64("afterLT", afterLT),
+			zap.Uint64("nextLT", nextLT),
+		)
+	}
+
+	txs := make([]domain.Transaction, 0, len(raws))
+	for i := len(raws) - 1; i >= 0; i-- {
+		if tx, ok := a.toDomainTransaction(raws[i], nil); ok {
+			txs = append(txs, tx)
+		}
+	}
+	return txs, nextLT, nil
+}
+
+func (a *adapter) GetAccountBalance(ctx context.Context, addrStr string) (uint64, error) {
+	addr, err := address.ParseAddr(addrStr)
+	if err != nil {
+		return 0, fmt.Errorf("parse address %q: %w", addrStr, err)
+	}
+	block, err := a.api.CurrentMasterchainInfo(ctx)
+	if err != nil {
+		return 0, err
+	}
+	acc, err := a.api.WaitForBlock(block.SeqNo).GetAccount(ctx, block, addr)
+	if err != nil {
+		return 0, fmt.Errorf("get account: %w", err)
+	}
+	if !acc.IsActive || acc.State == nil {
+		return 0, nil
+	}
+	return acc.State.Balance.Nano().Uint64(), nil
+}
+
+func (a *adapter) SweepDepositAddress(
+	ctx context.Context,
+	subwalletID uint32,
+	to string,
+	comment string,
+) error {
+	if a.wallet == nil {
+		return ErrTreasuryWalletNotConfigured
+	}
+	sub, err := a.wallet.GetSubwallet(subwalletID)
+	if err != nil {
+		return fmt.Errorf("derive subwallet %d: %w", subwalletID, err)
+	}
+	dst, err := address.ParseAddr(to)
+	if err != nil {
+		return fmt.Errorf("parse destination %q: %w", to, err)
+	}
+	msg, err := sub.BuildTransfer(dst, tlb.ZeroCoins, false, comment)
+	if err != nil {
+		return fmt.Errorf("build sweep transfer: %w", err)
+	}
+	msg.Mode = sweepMode
+	// Ждать подтверждения не нужно: адрес снова попадёт в очередь
+	// при следующем платеже, а повтор сметания безопасен.
+	if err = sub.Send(ctx, msg); err != nil {
+		return fmt.Errorf("send sweep: %w", err)
+	}
+	a.logger.Info("🧹 TON adapter: deposit address swept",
+		zap.String("from", sub.WalletAddress().String()),
+		zap.Uint32("subwallet", subwalletID),
+	)
+	return nil
+}
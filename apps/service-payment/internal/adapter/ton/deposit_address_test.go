@@ -0,0 +1,75 @@
+package ton
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/config"
+	domain "github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/ton"
+	"github.com/xssnick/tonutils-go/address"
+	"github.com/xssnick/tonutils-go/ton/wallet"
+)
+
+func TestDeriveAddress(t *testing.T) {
+	seed := strings.Join(wallet.NewSeed(), " ")
+
+	tests := []struct {
+		name    string
+		network config.TonNetwork
+	}{
+		{name: "mainnet", network: config.TonNetworkMainnet},
+		{name: "testnet", network: config.TonNetworkTestnet},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.Ton.Network = tt.network
+			cfg.Ton.WalletSeed = seed
+			d, err := NewAddressDeriver(cfg)
+			if err != nil {
+				t.Fatalf("deriver: %v", err)
+			}
+
+			friendly, raw, err := d.DeriveAddress(1_000_001)
+			if err != nil {
+				t.Fatalf("derive: %v", err)
+			}
+			addr, err := address.ParseAddr(friendly)
+			if err != nil {
+				t.Fatalf("parse %s: %v", friendly, err)
+			}
+			// неразвёрнутый подкошелёк должен принимать переводы, а не отбивать их
+			if addr.IsBounceable() {
+				t.Errorf("address %s is bounceable", friendly)
+			}
+			if addr.IsTestnetOnly() != (tt.network == config.TonNetworkTestnet) {
+				t.Errorf("address %s testnet flag = %v on %s", friendly, addr.IsTestnetOnly(), tt.network)
+			}
+			if rawFromFriendly, _ := domain.RawAddress(friendly); rawFromFriendly != raw {
+				t.Errorf("raw %s does not match friendly %s", raw, friendly)
+			}
+
+			// адрес вычисляется из сида детерминированно
+			again, _, err := d.DeriveAddress(1_000_001)
+			if err != nil || again != friendly {
+				t.Errorf("second derivation %s (%v), want %s", again, err, friendly)
+			}
+			other, _, err := d.DeriveAddress(1_000_002)
+			if err != nil || other == friendly {
+				t.Errorf("another subwallet got %s (%v), want a distinct address", other, err)
+			}
+		})
+	}
+}
+
+func TestDeriveAddressWithoutSeed(t *testing.T) {
+	d, err := NewAddressDeriver(&config.Config{})
+	if err != nil {
+		t.Fatalf("deriver: %v", err)
+	}
+	if _, _, err = d.DeriveAddress(1); !errors.Is(err, ErrTreasuryWalletNotConfigured) {
+		t.Fatalf("expected ErrTreasuryWalletNotConfigured, got %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package ton
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+
+	domain "github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/ton"
+	"github.com/peterparker2005/giftduels/apps/service-payment/pkg/boc"
+	"github.com/peterparker2005/giftduels/packages/tonamount-go"
+	"github.com/shopspring/decimal"
+	"github.com/xssnick/tonutils-go/address"
+	"github.com/xssnick/tonutils-go/tlb"
+	"github.com/xssnick/tonutils-go/ton/jetton"
+	"go.uber.org/zap"
+)
+
+func (a *adapter) JettonWalletAddress(
+	ctx context.Context,
+	j domain.Jetton,
+	owner string,
+) (string, error) {
+	master, err := address.ParseAddr(j.MasterAddress)
+	if err != nil {
+		return "", fmt.Errorf("parse jetton master: %w", err)
+	}
+	ownerAddr, err := address.ParseAddr(owner)
+	if err != nil {
+		return "", fmt.Errorf("parse owner: %w", err)
+	}
+	w, err := jetton.NewJettonMasterClient(a.api, master).GetJettonWallet(ctx, ownerAddr)
+	if err != nil {
+		return "", err
+	}
+	return w.Address().String(), nil
+}
+
+// jettonWalletIndex индексирует джеттон-кошельки по raw-адресу, чтобы
+// сравнение не зависело от флагов bounceable/testnet.
+func jettonWalletIndex(wallets map[string]domain.Jetton) (map[string]domain.Jetton, error) {
+	index := make(map[string]domain.Jetton, len(wallets))
+	for addr, j := range wallets {
+		parsed, err := address.ParseAddr(addr)
+		if err != nil {
+			return nil, fmt.Errorf("parse jetton wallet %s: %w", addr, err)
+		}
+		index[parsed.StringRaw()] = j
+	}
+	return index, nil
+}
+
+// jettonTransaction разбирает transfer_notification от джеттон-кошелька
+// казначейства. Комментарий берётся из forward_payload.
+func (a *adapter) jettonTransaction(
+	raw *tlb.Transaction,
+	ti *tlb.InternalMessage,
+	j domain.Jetton,
+) (domain.Transaction, bool) {
+	if ti.Body == nil {
+		return domain.Transaction{}, false
+	}
+	var notification jetton.TransferNotification
+	if err := tlb.LoadFromCell(&notification, ti.Body.BeginParse()); err != nil {
+		// джеттон-кошелёк шлёт и другие сообщения, например excesses
+		return domain.Transaction{}, false
+	}
+
+	units := notification.Amount.Nano()
+	if !units.IsUint64() {
+		a.logger.Warn("jetton amount out of range", zap.String("amount", units.String()))
+		return domain.Transaction{}, false
+	}
+	amount, err := tonamount.NewTonAmountFromString(
+		decimal.NewFromBigInt(units, -j.Decimals).String(),
+	)
+	if err != nil {
+		a.logger.Warn("invalid jetton amount", zap.String("amount", units.String()), zap.Error(err))
+		return domain.Transaction{}, false
+	}
+
+	payload := ""
+	if notification.ForwardPayload != nil {
+		if bocBytes := notification.ForwardPayload.ToBOC(); len(bocBytes) > bocMinDocBytes {
+			payload = boc.EncodeBOCAsBase64(bocBytes)
+		}
+	}
+
+	sender := ""
+	if notification.Sender != nil {
+		sender = notification.Sender.String()
+	}
+
+	return domain.Transaction{
+		Sender:   sender,
+		Amount:   amount,
+		Units:    units.Uint64(),
+		Currency: j.Currency,
+		Payload:  payload,
+		Hash:     hex.EncodeToString(raw.Hash),
+		LastLT:   raw.LT,
+	}, true
+}
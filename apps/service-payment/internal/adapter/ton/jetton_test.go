@@ -0,0 +1,168 @@
+package ton
+
+import (
+	"math/big"
+	"testing"
+
+	domain "github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/ton"
+	"github.com/peterparker2005/giftduels/apps/service-payment/pkg/boc"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+	"github.com/xssnick/tonutils-go/address"
+	"github.com/xssnick/tonutils-go/tlb"
+	"github.com/xssnick/tonutils-go/ton/jetton"
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+const (
+	jettonWallet = "EQDtFpEwcFAEcRe5mLVh2N6C0x-_hJEM7W61_JLnSF74p4q2"
+	payer        = "EQCD39VS5jcptHL8vMjEXrzGaRcCVYto7HUn4bpAOg8xqB2N"
+)
+
+func newTestAdapter(t *testing.T) *adapter {
+	t.Helper()
+	log, err := logger.NewLogger(logger.Config{Service: "test", Level: "fatal"})
+	if err != nil {
+		t.Fatalf("logger: %v", err)
+	}
+	return &adapter{logger: log}
+}
+
+// incoming собирает транзакцию с входящим внутренним сообщением от src.
+func incoming(src string, amount tlb.Coins, body *cell.Cell, bounced bool) *tlb.Transaction {
+	raw := &tlb.Transaction{LT: 77, Hash: []byte{0xab, 0xcd}}
+	raw.IO.In = &tlb.Message{
+		MsgType: tlb.MsgTypeInternal,
+		Msg: &tlb.InternalMessage{
+			Bounced: bounced,
+			SrcAddr: address.MustParseAddr(src),
+			DstAddr: address.MustParseAddr(payer),
+			Amount:  amount,
+			Body:    body,
+		},
+	}
+	return raw
+}
+
+func notificationBody(t *testing.T, units int64, comment string) *cell.Cell {
+	t.Helper()
+	n := jetton.TransferNotification{
+		QueryID: 1,
+		Amount:  tlb.MustFromNano(big.NewInt(units), 0),
+		Sender:  address.MustParseAddr(payer),
+	}
+	if comment != "" {
+		n.ForwardPayload = cell.BeginCell().MustStoreStringSnake(comment).EndCell()
+	}
+	body, err := tlb.ToCell(n)
+	if err != nil {
+		t.Fatalf("build notification: %v", err)
+	}
+	return body
+}
+
+func TestToDomainTransactionJetton(t *testing.T) {
+	usdt := domain.Jetton{Currency: "USDT", MasterAddress: "master", Decimals: 6}
+	jettons, err := jettonWalletIndex(map[string]domain.Jetton{jettonWallet: usdt})
+	if err != nil {
+		t.Fatalf("index: %v", err)
+	}
+	// кошелёк в индексе ищется по raw-адресу, флаги user-friendly формата не важны
+	nonBounceable := address.MustParseAddr(jettonWallet).Bounce(false).String()
+	// TON, приложенные джеттон-кошельком к уведомлению, не зачисляются
+	fwdFee := tlb.MustFromTON("0.05")
+	excesses := cell.BeginCell().MustStoreUInt(0xd53276db, 32).MustStoreUInt(1, 64).EndCell()
+
+	tests := []struct {
+		name         string
+		raw          *tlb.Transaction
+		wantOK       bool
+		wantCurrency string
+		wantAmount   string
+		wantUnits    uint64
+		wantSender   string
+		wantComment  string
+	}{
+		{
+			name:         "usdt with comment",
+			raw:          incoming(jettonWallet, fwdFee, notificationBody(t, 1_500_000, "dep-1"), false),
+			wantOK:       true,
+			wantCurrency: "USDT",
+			wantAmount:   "1.5",
+			wantUnits:    1_500_000,
+			wantSender:   address.MustParseAddr(payer).String(),
+			wantComment:  "dep-1",
+		},
+		{
+			// 6 знаков USDT, а не 9 как у TON; сумма округляется до центов,
+			// точное значение остаётся в Units
+			name:         "usdt amount uses jetton decimals",
+			raw:          incoming(nonBounceable, fwdFee, notificationBody(t, 1_234_567, "dep-2"), false),
+			wantOK:       true,
+			wantCurrency: "USDT",
+			wantAmount:   "1.23",
+			wantUnits:    1_234_567,
+			wantSender:   address.MustParseAddr(payer).String(),
+			wantComment:  "dep-2",
+		},
+		{
+			name:         "usdt without forward payload",
+			raw:          incoming(jettonWallet, fwdFee, notificationBody(t, 2_000_000, ""), false),
+			wantOK:       true,
+			wantCurrency: "USDT",
+			wantAmount:   "2",
+			wantUnits:    2_000_000,
+			wantSender:   address.MustParseAddr(payer).String(),
+		},
+		{
+			name: "other jetton wallet message is skipped",
+			raw:  incoming(jettonWallet, fwdFee, excesses, false),
+		},
+		{
+			name: "bounced message is skipped",
+			raw:  incoming(jettonWallet, fwdFee, notificationBody(t, 1_000_000, "dep-3"), true),
+		},
+		{
+			name:         "plain TON transfer",
+			raw:          incoming(payer, tlb.MustFromTON("1.25"), nil, false),
+			wantOK:       true,
+			wantCurrency: "TON",
+			wantAmount:   "1.25",
+			wantUnits:    1_250_000_000,
+			wantSender:   address.MustParseAddr(payer).String(),
+		},
+	}
+
+	a := newTestAdapter(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, ok := a.toDomainTransaction(tt.raw, jettons)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if tx.Currency != tt.wantCurrency || tx.Amount.String() != tt.wantAmount || tx.Units != tt.wantUnits {
+				t.Errorf("got %s %s (%d units), want %s %s (%d units)",
+					tx.Amount, tx.Currency, tx.Units, tt.wantAmount, tt.wantCurrency, tt.wantUnits)
+			}
+			if tx.Sender != tt.wantSender {
+				t.Errorf("sender %s, want %s", tx.Sender, tt.wantSender)
+			}
+			if tx.Hash != "abcd" || tx.LastLT != 77 {
+				t.Errorf("hash %s lt %d, want abcd 77", tx.Hash, tx.LastLT)
+			}
+
+			comment := ""
+			if tx.Payload != "" {
+				var decodeErr error
+				if comment, decodeErr = boc.DecodeStringFromBOC(tx.Payload); decodeErr != nil {
+					t.Fatalf("decode payload: %v", decodeErr)
+				}
+			}
+			if comment != tt.wantComment {
+				t.Errorf("comment %q, want %q", comment, tt.wantComment)
+			}
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package ton
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ccoveille/go-safecast"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/config"
+	domain "github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/ton"
+	"github.com/xssnick/tonutils-go/address"
+	"github.com/xssnick/tonutils-go/tlb"
+	"github.com/xssnick/tonutils-go/ton"
+	"github.com/xssnick/tonutils-go/ton/wallet"
+	"github.com/xssnick/tonutils-go/tvm/cell"
+	"go.uber.org/zap"
+)
+
+// txScanLimit — сколько последних транзакций аккаунта просматривается
+// при поиске перевода.
+const txScanLimit = 200
+
+var ErrTreasuryWalletNotConfigured = errors.New("treasury wallet seed is not configured")
+
+func newTreasuryWallet(api ton.APIClientWrapped, appCfg *config.Config) (*wallet.Wallet, error) {
+	w, err := wallet.FromSeed(api, strings.Fields(appCfg.Ton.WalletSeed), wallet.V4R2)
+	if err != nil {
+		return nil, err
+	}
+	ttl, err := safecast.ToUint32(int64(appCfg.Ton.Withdrawal.MessageTTL.Seconds()))
+	if err != nil {
+		return nil, err
+	}
+	if spec, ok := w.GetSpec().(*wallet.SpecV4R2); ok {
+		spec.SetMessagesTTL(ttl)
+	}
+	if appCfg.Ton.WalletAddress != "" {
+		treasury, parseErr := address.ParseAddr(appCfg.Ton.WalletAddress)
+		if parseErr != nil {
+			return nil, fmt.Errorf("parse treasury address: %w", parseErr)
+		}
+		if !treasury.Equals(w.WalletAddress()) {
+			return nil, fmt.Errorf(
+				"seed wallet %s does not match TON_WALLET_ADDRESS %s",
+				w.WalletAddress().String(), appCfg.Ton.WalletAddress,
+			)
+		}
+	}
+	return w, nil
+}
+
+func (a *adapter) SignTransfers(
+	ctx context.Context,
+	transfers []domain.Transfer,
+) (domain.SignedTransfers, error) {
+	if a.wallet == nil {
+		return domain.SignedTransfers{}, ErrTreasuryWalletNotConfigured
+	}
+
+	messages := make([]*wallet.Message, 0, len(transfers))
+	for _, t := range transfers {
+		to, err := address.ParseAddr(t.Destination)
+		if err != nil {
+			return domain.SignedTransfers{}, fmt.Errorf("parse destination %q: %w", t.Destination, err)
+		}
+		// Неинициализированным кошелькам уходит non-bounceable адрес,
+		// иначе перевод вернулся бы обратно.
+		msg, err := a.wallet.BuildTransfer(to, tlb.FromNanoTONU(t.AmountNano), to.IsBounceable(), t.Comment)
+		if err != nil {
+			return domain.SignedTransfers{}, fmt.Errorf("build transfer: %w", err)
+		}
+		messages = append(messages, msg)
+	}
+
+	a.signMu.Lock()
+	defer a.signMu.Unlock()
+
+	// Фиксируем seqno, которым подписано сообщение: по нему воркер
+	// понимает, исполнено ли сообщение.
+	seqno, err := a.GetWalletSeqno(ctx)
+	if err != nil {
+		return domain.SignedTransfers{}, err
+	}
+	spec, ok := a.wallet.GetSpec().(*wallet.SpecV4R2)
+	if !ok {
+		return domain.SignedTransfers{}, fmt.Errorf("unexpected wallet spec %T", a.wallet.GetSpec())
+	}
+	spec.SetSeqnoFetcher(func(context.Context, uint32) (uint32, error) {
+		return seqno, nil
+	})
+
+	ext, err := a.wallet.BuildExternalMessageForMany(ctx, messages)
+	if err != nil {
+		return domain.SignedTransfers{}, fmt.Errorf("build external message: %w", err)
+	}
+	// Берём время после подписи: реальный срок действия сообщения не позже.
+	validUntil := time.Now().Add(a.messageTTL)
+
+	c, err := tlb.ToCell(ext)
+	if err != nil {
+		return domain.SignedTransfers{}, fmt.Errorf("serialize external message: %w", err)
+	}
+	return domain.SignedTransfers{
+		MsgHash:     hex.EncodeToString(ext.Body.Hash()),
+		WalletSeqno: seqno,
+		ValidUntil:  validUntil,
+		BOC:         c.ToBOCWithFlags(false),
+	}, nil
+}
+
+func (a *adapter) GetWalletSeqno(ctx context.Context) (uint32, error) {
+	if a.wallet == nil {
+		return 0, ErrTreasuryWalletNotConfigured
+	}
+	block, err := a.api.CurrentMasterchainInfo(ctx)
+	if err != nil {
+		return 0, err
+	}
+	res, err := a.api.WaitForBlock(block.SeqNo).RunGetMethod(ctx, block, a.wallet.WalletAddress(), "seqno")
+	if err != nil {
+		var execErr ton.ContractExecError
+		if errors.As(err, &execErr) && execErr.Code == ton.ErrCodeContractNotInitialized {
+			// кошелёк ещё не развёрнут: первое сообщение пойдёт с seqno 0
+			return 0, nil
+		}
+		return 0, fmt.Errorf("get seqno: %w", err)
+	}
+	seqno, err := res.Int(0)
+	if err != nil {
+		return 0, fmt.Errorf("parse seqno: %w", err)
+	}
+	return safecast.ToUint32(seqno.Uint64())
+}
+
+func (a *adapter) SendTransfers(ctx context.Context, signed domain.SignedTransfers) error {
+	c, err := cell.FromBOC(signed.BOC)
+	if err != nil {
+		return fmt.Errorf("parse external message: %w", err)
+	}
+	var ext tlb.ExternalMessage
+	if err = tlb.LoadFromCell(&ext, c.BeginParse()); err != nil {
+		return fmt.Errorf("load external message: %w", err)
+	}
+	if err = a.api.SendExternalMessage(ctx, &ext); err != nil {
+		return fmt.Errorf("send external message: %w", err)
+	}
+	a.logger.Info("📤 TON adapter: transfers sent", zap.String("msgHash", signed.MsgHash))
+	return nil
+}
+
+func (a *adapter) GetTransferStatus(
+	ctx context.Context,
+	msgHash string,
+	transfer domain.Transfer,
+) (domain.TransferStatus, error) {
+	if a.wallet == nil {
+		return domain.TransferStatus{}, ErrTreasuryWalletNotConfigured
+	}
+	hash, err := hex.DecodeString(msgHash)
+	if err != nil {
+		return domain.TransferStatus{}, fmt.Errorf("decode msg hash: %w", err)
+	}
+	to, err := address.ParseAddr(transfer.Destination)
+	if err != nil {
+		return domain.TransferStatus{}, fmt.Errorf("parse destination %q: %w", transfer.Destination, err)
+	}
+	comment, err := wallet.CreateCommentCell(transfer.Comment)
+	if err != nil {
+		return domain.TransferStatus{}, err
+	}
+
+	// 1) транзакция кошелька, исполнившая внешнее сообщение
+	walletTx, err := a.api.FindLastTransactionByInMsgHash(
+		ctx, a.wallet.WalletAddress(), hash, txScanLimit,
+	)
+	if errors.Is(err, ton.ErrTxWasNotFound) {
+		return domain.TransferStatus{State: domain.TransferStateUnknown}, nil
+	}
+	if err != nil {
+		return domain.TransferStatus{}, fmt.Errorf("find wallet transaction: %w", err)
+	}
+
+	// 2) среди исходящих сообщений должен быть наш перевод
+	sent, err := hasOutgoingTransfer(walletTx, to, comment.Hash())
+	if err != nil {
+		return domain.TransferStatus{}, err
+	}
+	if !sent {
+		return domain.TransferStatus{State: domain.TransferStateFailed}, nil
+	}
+
+	// 3) транзакция получателя
+	destTx, err := a.api.FindLastTransactionByInMsgHash(ctx, to, comment.Hash(), txScanLimit)
+	if errors.Is(err, ton.ErrTxWasNotFound) {
+		return domain.TransferStatus{State: domain.TransferStateInFlight}, nil
+	}
+	if err != nil {
+		return domain.TransferStatus{}, fmt.Errorf("find destination transaction: %w", err)
+	}
+
+	state := domain.TransferStateDelivered
+	if isBounced(destTx) {
+		state = domain.TransferStateBounced
+	}
+	return domain.TransferStatus{
+		State:  state,
+		TxHash: hex.EncodeToString(destTx.Hash),
+		TxLT:   destTx.LT,
+	}, nil
+}
+
+func hasOutgoingTransfer(tx *tlb.Transaction, to *address.Address, payloadHash []byte) (bool, error) {
+	if tx.IO.Out == nil {
+		return false, nil
+	}
+	list, err := tx.IO.Out.ToSlice()
+	if err != nil {
+		return false, fmt.Errorf("list out messages: %w", err)
+	}
+	for _, m := range list {
+		if m.MsgType != tlb.MsgTypeInternal {
+			continue
+		}
+		in := m.AsInternal()
+		if in.DstAddr.Equals(to) && in.Body != nil && bytes.Equal(in.Body.Hash(), payloadHash) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// isBounced — транзакция получателя прервалась и вернула средства отправителю.
+func isBounced(tx *tlb.Transaction) bool {
+	switch d := tx.Description.(type) {
+	case tlb.TransactionDescriptionOrdinary:
+		return d.Aborted && d.BouncePhase != nil
+	case *tlb.TransactionDescriptionOrdinary:
+		return d.Aborted && d.BouncePhase != nil
+	}
+	return false
+}
@@ -0,0 +1,17 @@
+package app
+
+import (
+	"go.uber.org/fx"
+
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/service"
+)
+
+// NewCLIApp собирает зависимости сервиса для разовой команды CLI;
+// populate забирает из контейнера нужные команде объекты.
+func NewCLIApp(populate fx.Option) *fx.App {
+	return fx.New(
+		moduleCommon,
+		service.Module,
+		populate,
+	)
+}
@@ -1,8 +1,11 @@
 package app
 
 import (
+	"context"
+
 	"github.com/peterparker2005/giftduels/apps/service-payment/internal/service"
 	"github.com/peterparker2005/giftduels/apps/service-payment/internal/service/eventhandler"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/service/holdsweeper"
 	"go.uber.org/fx"
 )
 
@@ -11,5 +14,15 @@ func NewWorkerApp() *fx.App {
 		moduleCommon,
 		service.Module,
 		eventhandler.Module,
+		fx.Provide(holdsweeper.NewSweeper),
+		fx.Invoke(func(holdSweeper *holdsweeper.Sweeper, lc fx.Lifecycle) {
+			lc.Append(fx.Hook{
+				OnStart: func(_ context.Context) error {
+					holdSweeper.Start()
+					return nil
+				},
+				OnStop: holdSweeper.Stop,
+			})
+		}),
 	)
 }
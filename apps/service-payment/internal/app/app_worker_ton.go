@@ -4,7 +4,12 @@ import (
 	"context"
 
 	"github.com/peterparker2005/giftduels/apps/service-payment/internal/adapter/ton"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/config"
 	"github.com/peterparker2005/giftduels/apps/service-payment/internal/service"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/service/depositaddress"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/service/depositconfirmer"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/service/depositsweeper"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/service/tonwithdrawal"
 	"github.com/peterparker2005/giftduels/apps/service-payment/internal/service/tonworker"
 	"go.uber.org/fx"
 )
@@ -16,17 +21,51 @@ func NewWorkerTonApp() *fx.App {
 		fx.Provide(
 			ton.NewTonAPI,
 			tonworker.NewProcessor,
+			tonwithdrawal.NewProcessor,
+			depositsweeper.NewSweeper,
+			depositconfirmer.NewConfirmer,
+			depositaddress.NewWatcher,
+			depositaddress.NewSweeper,
 		),
 		fx.Invoke(func(
 			processor *tonworker.Processor,
+			withdrawalProcessor *tonwithdrawal.Processor,
+			depositSweeper *depositsweeper.Sweeper,
+			depositConfirmer *depositconfirmer.Confirmer,
+			addressWatcher *depositaddress.Watcher,
+			addressSweeper *depositaddress.Sweeper,
+			cfg *config.Config,
 			lc fx.Lifecycle,
 		) {
+			addressesEnabled := cfg.Ton.Deposit.Addresses.Enabled
 			lc.Append(fx.Hook{
 				OnStart: func(_ context.Context) error {
 					processor.Start()
+					withdrawalProcessor.Start()
+					depositSweeper.Start()
+					depositConfirmer.Start()
+					if addressesEnabled {
+						addressWatcher.Start()
+						addressSweeper.Start()
+					}
 					return nil
 				},
 				OnStop: func(ctx context.Context) error {
+					if err := addressSweeper.Stop(ctx); err != nil {
+						return err
+					}
+					if err := addressWatcher.Stop(ctx); err != nil {
+						return err
+					}
+					if err := depositConfirmer.Stop(ctx); err != nil {
+						return err
+					}
+					if err := depositSweeper.Stop(ctx); err != nil {
+						return err
+					}
+					if err := withdrawalProcessor.Stop(ctx); err != nil {
+						return err
+					}
 					return processor.Stop(ctx)
 				},
 			})
@@ -4,6 +4,8 @@ import (
 	"context"
 
 	"github.com/peterparker2005/giftduels/apps/service-payment/internal/adapter/pg"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/adapter/rates"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/adapter/ton"
 	"github.com/peterparker2005/giftduels/apps/service-payment/internal/config"
 	"github.com/peterparker2005/giftduels/packages/grpc-go/clients"
 	"github.com/peterparker2005/giftduels/packages/logger-go"
@@ -17,9 +19,12 @@ var moduleCommon = fx.Options(
 	fx.Provide(func(cfg *config.Config) (*clients.Clients, error) {
 		return clients.NewClients(context.Background(), cfg.GRPC)
 	}),
+	// адреса пополнения вычисляются без сети, поэтому доступны и gRPC-серверу
+	fx.Provide(ton.NewAddressDeriver),
 	fx.WithLogger(func(log *logger.Logger) fxevent.Logger {
 		return log.ToFxLogger()
 	}),
 	config.Module,
 	pg.Module,
+	rates.Module,
 )
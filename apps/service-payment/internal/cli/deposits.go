@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/ton"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/service/payment"
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+)
+
+func newCmdDeposits() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "deposits",
+		Short: "Manage deposits that need manual handling",
+	}
+
+	cmd.AddCommand(newCmdDepositsUnattributed())
+
+	return cmd
+}
+
+func newCmdDepositsUnattributed() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "unattributed",
+		Short: "Treasury payments that did not match any deposit",
+		Long: `Payments to the treasury wallet without a comment, with an unreadable
+comment or with an unknown deposit payload:
+  list   - Print payments waiting for manual review
+  assign - Credit a payment to a user`,
+	}
+
+	cmd.AddCommand(
+		newCmdDepositsUnattributedList(),
+		newCmdDepositsUnattributedAssign(),
+	)
+
+	return cmd
+}
+
+func newCmdDepositsUnattributedList() *cobra.Command {
+	var (
+		status string
+		limit  int32
+		offset int32
+	)
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "Print unattributed payments",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			var svc *payment.Service
+			return withServiceApp(cmd.Context(), fx.Populate(&svc), func(ctx context.Context) error {
+				deposits, err := svc.ListUnattributedDeposits(
+					ctx, ton.UnattributedDepositStatus(status), limit, offset,
+				)
+				if err != nil {
+					return err
+				}
+				return printUnattributedDeposits(cmd.OutOrStdout(), deposits)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&status, "status", string(ton.UnattributedDepositStatusPending), "pending or assigned")
+	cmd.Flags().Int32Var(&limit, "limit", 50, "max payments to print")
+	cmd.Flags().Int32Var(&offset, "offset", 0, "payments to skip")
+	return cmd
+}
+
+func newCmdDepositsUnattributedAssign() *cobra.Command {
+	return &cobra.Command{
+		Use:   "assign ID TELEGRAM_USER_ID",
+		Short: "Credit an unattributed payment to a user",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			telegramUserID, err := strconv.ParseInt(args[1], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid telegram user id %q: %w", args[1], err)
+			}
+
+			var svc *payment.Service
+			return withServiceApp(cmd.Context(), fx.Populate(&svc), func(ctx context.Context) error {
+				deposit, assignErr := svc.AssignUnattributedDeposit(ctx, args[0], telegramUserID)
+				if assignErr != nil {
+					return assignErr
+				}
+				return printUnattributedDeposits(cmd.OutOrStdout(), []*ton.UnattributedDeposit{deposit})
+			})
+		},
+	}
+}
+
+func printUnattributedDeposits(w io.Writer, deposits []*ton.UnattributedDeposit) error {
+	if len(deposits) == 0 {
+		fmt.Fprintln(w, "no payments")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tRECEIVED AT\tAMOUNT\tCURRENCY\tSENDER\tCOMMENT\tSTATUS\tUSER")
+	for _, d := range deposits {
+		amount := strconv.FormatUint(d.AmountNano, 10)
+		if a, err := d.Currency.AmountFromUnits(d.AmountNano); err == nil {
+			amount = a.String()
+		}
+		comment, user := "-", "-"
+		if d.Comment != nil {
+			comment = *d.Comment
+		}
+		if d.TelegramUserID != nil {
+			user = strconv.FormatInt(*d.TelegramUserID, 10)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			d.ID, d.CreatedAt.Format(time.RFC3339), amount, d.Currency,
+			d.SenderAddress, comment, d.Status, user)
+	}
+	return tw.Flush()
+}
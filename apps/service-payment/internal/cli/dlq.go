@@ -0,0 +1,31 @@
+package cli
+
+import (
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/config"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/service/eventhandler"
+	"github.com/peterparker2005/giftduels/packages/cli-go/command/dlq"
+	paymentEvents "github.com/peterparker2005/giftduels/packages/events/payment"
+	giftv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/gift/v1"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/proto"
+)
+
+func newCmdDLQ() *cobra.Command {
+	return dlq.NewCmdDLQ(func() (*dlq.Setup, error) {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return nil, err
+		}
+
+		return &dlq.Setup{
+			AMQPURI: cfg.AMQP.Address(),
+			Poison:  paymentEvents.Config(cfg.ServiceName.String()),
+			Routes: []dlq.Route{
+				{
+					Handler: eventhandler.HandlerTelegramGiftWithdrawFail,
+					Payload: func() proto.Message { return &giftv1.GiftWithdrawFailedEvent{} },
+				},
+			},
+		}, nil
+	})
+}
@@ -0,0 +1,195 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	paymentdomain "github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/payment"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/service/payment"
+	"github.com/peterparker2005/giftduels/packages/tonamount-go"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+)
+
+func newCmdFees() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "fees",
+		Short: "Manage withdrawal commission schedules",
+		Long: `Withdrawal commissions are calculated from a versioned fee schedule.
+Published versions never change; publish a new version to change fees:
+  show    - Print the active or a specific schedule version
+  publish - Publish a schedule from a JSON file as the next version`,
+	}
+
+	cmd.AddCommand(
+		newCmdFeesShow(),
+		newCmdFeesPublish(),
+	)
+
+	return cmd
+}
+
+func newCmdFeesShow() *cobra.Command {
+	var version int64
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Print a fee schedule",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			var svc *payment.Service
+			return withServiceApp(cmd.Context(), fx.Populate(&svc), func(ctx context.Context) error {
+				schedule, err := svc.GetFeeSchedule(ctx, version)
+				if err != nil {
+					return err
+				}
+				return printFeeSchedule(cmd.OutOrStdout(), schedule)
+			})
+		},
+	}
+	cmd.Flags().Int64Var(&version, "version", 0, "schedule version, the active one if 0")
+	return cmd
+}
+
+func newCmdFeesPublish() *cobra.Command {
+	return &cobra.Command{
+		Use:   "publish FILE",
+		Short: "Publish a fee schedule from a JSON file",
+		Long: `Publish a fee schedule from a JSON file as the next version:
+
+  {
+    "ton_per_star": "0.005356",
+    "effective_from": "2026-11-01T00:00:00Z",
+    "comment": "holiday fees",
+    "tiers": [
+      {"priority": 0, "collection": "Plush Pepe", "rate": "0.10", "min_stars": 25, "max_stars": 500},
+      {"priority": 1, "min_price": "100", "rate": "0.05", "min_stars": 25, "max_stars": 250},
+      {"priority": 2, "rate": "0.15", "min_stars": 25, "max_stars": 250}
+    ],
+    "promotions": [
+      {"starts_at": "2026-12-31T00:00:00Z", "ends_at": "2027-01-02T00:00:00Z"}
+    ]
+  }
+
+Tiers are matched by ascending priority; one tier must match every gift.
+Without effective_from the schedule applies immediately.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			schedule, err := readFeeScheduleFile(args[0])
+			if err != nil {
+				return err
+			}
+
+			var svc *payment.Service
+			return withServiceApp(cmd.Context(), fx.Populate(&svc), func(ctx context.Context) error {
+				published, publishErr := svc.PublishFeeSchedule(ctx, schedule)
+				if publishErr != nil {
+					return publishErr
+				}
+				return printFeeSchedule(cmd.OutOrStdout(), published)
+			})
+		},
+	}
+}
+
+type feeScheduleFile struct {
+	TonPerStar    decimal.Decimal `json:"ton_per_star"`
+	EffectiveFrom time.Time       `json:"effective_from"`
+	Comment       string          `json:"comment"`
+	Tiers         []struct {
+		Priority   int32                `json:"priority"`
+		Collection string               `json:"collection"`
+		MinPrice   *tonamount.TonAmount `json:"min_price"`
+		MaxPrice   *tonamount.TonAmount `json:"max_price"`
+		Rate       decimal.Decimal      `json:"rate"`
+		MinStars   uint32               `json:"min_stars"`
+		MaxStars   uint32               `json:"max_stars"`
+	} `json:"tiers"`
+	Promotions []struct {
+		Collection string    `json:"collection"`
+		StartsAt   time.Time `json:"starts_at"`
+		EndsAt     time.Time `json:"ends_at"`
+	} `json:"promotions"`
+}
+
+func readFeeScheduleFile(filename string) (*paymentdomain.FeeSchedule, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var f feeScheduleFile
+	if err = json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid fee schedule file %q: %w", filename, err)
+	}
+
+	schedule := &paymentdomain.FeeSchedule{
+		TonPerStar:    f.TonPerStar,
+		EffectiveFrom: f.EffectiveFrom,
+		Comment:       f.Comment,
+	}
+	for _, t := range f.Tiers {
+		schedule.Tiers = append(schedule.Tiers, paymentdomain.FeeTier{
+			Priority:   t.Priority,
+			Collection: t.Collection,
+			MinPrice:   t.MinPrice,
+			MaxPrice:   t.MaxPrice,
+			Rate:       t.Rate,
+			MinStars:   t.MinStars,
+			MaxStars:   t.MaxStars,
+		})
+	}
+	for _, p := range f.Promotions {
+		schedule.Promotions = append(schedule.Promotions, paymentdomain.FeePromotion{
+			Collection: p.Collection,
+			StartsAt:   p.StartsAt,
+			EndsAt:     p.EndsAt,
+		})
+	}
+	return schedule, nil
+}
+
+func printFeeSchedule(w io.Writer, s *paymentdomain.FeeSchedule) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "version:\t%d\n", s.Version)
+	fmt.Fprintf(tw, "effective from:\t%s\n", s.EffectiveFrom.Format(time.RFC3339))
+	fmt.Fprintf(tw, "ton per star:\t%s\n", s.TonPerStar)
+	if s.Comment != "" {
+		fmt.Fprintf(tw, "comment:\t%s\n", s.Comment)
+	}
+
+	fmt.Fprintln(tw, "\nPRIORITY\tCOLLECTION\tMIN PRICE\tMAX PRICE\tRATE\tMIN STARS\tMAX STARS")
+	for _, t := range s.Tiers {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%d\t%d\n",
+			t.Priority, orAny(t.Collection), amountOrDash(t.MinPrice), amountOrDash(t.MaxPrice),
+			t.Rate, t.MinStars, t.MaxStars)
+	}
+
+	if len(s.Promotions) > 0 {
+		fmt.Fprintln(tw, "\nPROMOTION\tCOLLECTION\tSTARTS AT\tENDS AT")
+		for i, p := range s.Promotions {
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", i+1, orAny(p.Collection),
+				p.StartsAt.Format(time.RFC3339), p.EndsAt.Format(time.RFC3339))
+		}
+	}
+	return tw.Flush()
+}
+
+func orAny(collection string) string {
+	if collection == "" {
+		return "*"
+	}
+	return collection
+}
+
+func amountOrDash(a *tonamount.TonAmount) string {
+	if a == nil {
+		return "-"
+	}
+	return a.String()
+}
@@ -2,14 +2,21 @@ package cli
 
 import (
 	"bufio"
+	"context"
 	"log/slog"
 	"os"
 	"strings"
+	"time"
 
 	migratepg "github.com/peterparker2005/giftduels/apps/service-payment/internal/adapter/pg/migrate"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/app"
 	"github.com/peterparker2005/giftduels/apps/service-payment/internal/config"
+	"go.uber.org/fx"
 )
 
+// commandTimeout — сколько может выполняться разовая команда CLI.
+const commandTimeout = time.Minute
+
 func newRunner() (*migratepg.Runner, error) {
 	cfg, err := config.LoadConfig()
 	if err != nil {
@@ -28,3 +35,20 @@ func confirm(prompt, want string) bool {
 func writeFile(filename, content string) error {
 	return os.WriteFile(filename, []byte(content), 0o600)
 }
+
+// withServiceApp поднимает общие зависимости сервиса на время одной команды.
+func withServiceApp(parent context.Context, populate fx.Option, fn func(ctx context.Context) error) error {
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(parent, commandTimeout)
+	defer cancel()
+
+	fxApp := app.NewCLIApp(populate)
+	if err := fxApp.Start(ctx); err != nil {
+		return err
+	}
+	defer func() { _ = fxApp.Stop(context.Background()) }()
+
+	return fn(ctx)
+}
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/service/payment"
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+)
+
+var errLedgerInvariantsViolated = errors.New("ledger invariants violated")
+
+func newCmdLedger() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ledger",
+		Short: "Inspect the double-entry ledger",
+	}
+
+	cmd.AddCommand(newCmdLedgerCheck())
+
+	return cmd
+}
+
+func newCmdLedgerCheck() *cobra.Command {
+	var limit int32
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Verify ledger invariants",
+		Long: `Verify that postings of every journal entry sum to zero and that cached
+user balances match the sum of postings on user accounts.
+Exits with an error if any violation is found.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			var svc *payment.Service
+			return withServiceApp(cmd.Context(), fx.Populate(&svc), func(ctx context.Context) error {
+				report, err := svc.CheckLedger(ctx, limit)
+				if err != nil {
+					return err
+				}
+				if err = printLedgerReport(cmd.OutOrStdout(), report); err != nil {
+					return err
+				}
+				if !report.OK() {
+					return errLedgerInvariantsViolated
+				}
+				return nil
+			})
+		},
+	}
+	cmd.Flags().Int32Var(&limit, "limit", 100, "max violations of each kind to print")
+	return cmd
+}
+
+func printLedgerReport(w io.Writer, report *payment.LedgerReport) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "SYSTEM ACCOUNT\tCURRENCY\tBALANCE")
+	for _, b := range report.SystemBalances {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", b.Type, b.Currency, b.Amount)
+	}
+
+	fmt.Fprintf(tw, "\nunbalanced entries: %d\n", len(report.UnbalancedEntries))
+	if len(report.UnbalancedEntries) > 0 {
+		fmt.Fprintln(tw, "ENTRY ID\tCREATED AT\tREASON\tCURRENCY\tPOSTINGS\tSUM")
+		for _, e := range report.UnbalancedEntries {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%s\n",
+				e.ID, e.CreatedAt.Format(time.RFC3339), e.Reason, e.Currency, e.PostingsCount, e.Total)
+		}
+	}
+
+	fmt.Fprintf(tw, "\nbalance mismatches: %d\n", len(report.BalanceMismatches))
+	if len(report.BalanceMismatches) > 0 {
+		fmt.Fprintln(tw, "TELEGRAM USER ID\tCURRENCY\tCACHED\tLEDGER")
+		for _, m := range report.BalanceMismatches {
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", m.TelegramUserID, m.Currency, m.Cached, m.Ledger)
+		}
+	}
+	return tw.Flush()
+}
@@ -12,6 +12,10 @@ func rootCmd() *cobra.Command {
 		newCmdServe(),
 		newCmdMigrate(),
 		newCmdWorker(),
+		newCmdDLQ(),
+		newCmdDeposits(),
+		newCmdLedger(),
+		newCmdFees(),
 	)
 
 	return cmd
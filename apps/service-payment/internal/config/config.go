@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/peterparker2005/giftduels/packages/configs"
 	"go.uber.org/fx"
@@ -20,6 +22,78 @@ func (n TonNetwork) String() string {
 type TonConfig struct {
 	Network       TonNetwork `env:"TON_NETWORK"        default:"testnet"`
 	WalletAddress string     `env:"TON_WALLET_ADDRESS"`
+	// WalletSeed — мнемоника казначейского кошелька (v4r2), слова через пробел.
+	// Без неё вывод TON недоступен.
+	WalletSeed string `env:"TON_WALLET_SEED"`
+
+	Deposit    TonDepositConfig    `yaml:"deposit"`
+	Withdrawal TonWithdrawalConfig `yaml:"withdrawal"`
+	USDT       TonJettonConfig     `yaml:"usdt"`
+}
+
+// TonJettonConfig — приём джеттона на казначейский кошелёк.
+type TonJettonConfig struct {
+	// MasterAddress — адрес мастер-контракта джеттона. Пустой — приём выключен.
+	MasterAddress string `yaml:"master_address" env:"TON_USDT_MASTER_ADDRESS"`
+	// ConvertToTon — зачислять депозиты на TON-баланс по курсу TonRate
+	// вместо отдельного баланса в валюте джеттона.
+	ConvertToTon bool `yaml:"convert_to_ton" env:"TON_USDT_CONVERT_TO_TON" env-default:"false"`
+	// TonRate — сколько TON стоит одна единица джеттона.
+	TonRate string `yaml:"ton_rate" env:"TON_USDT_TON_RATE"`
+}
+
+// Enabled сообщает, принимаются ли депозиты в джеттоне.
+func (c TonJettonConfig) Enabled() bool {
+	return c.MasterAddress != ""
+}
+
+type TonDepositConfig struct {
+	// TTL — сколько депозит ждёт оплаты, прежде чем истечь.
+	TTL time.Duration `yaml:"ttl" env:"TON_DEPOSIT_TTL" env-default:"1h"`
+	// SweepInterval — период пометки просроченных депозитов.
+	SweepInterval time.Duration `yaml:"sweep_interval" env:"TON_DEPOSIT_SWEEP_INTERVAL" env-default:"1m"`
+	// Confirmations — через сколько мастерблоков платёж считается подтверждённым.
+	Confirmations uint32 `yaml:"confirmations" env:"TON_DEPOSIT_CONFIRMATIONS" env-default:"5"`
+	// ConfirmInterval — период проверки принятых депозитов.
+	ConfirmInterval time.Duration `yaml:"confirm_interval" env:"TON_DEPOSIT_CONFIRM_INTERVAL" env-default:"5s"`
+
+	Addresses TonDepositAddressesConfig `yaml:"addresses"`
+}
+
+// TonDepositAddressesConfig — персональные адреса пополнения: подкошельки
+// казначейского сида, с которых средства периодически сметаются.
+type TonDepositAddressesConfig struct {
+	Enabled bool `yaml:"enabled" env:"TON_DEPOSIT_ADDRESSES_ENABLED" env-default:"false"`
+	// SubwalletBase — смещение номеров подкошельков, чтобы не пересекаться
+	// с основным кошельком и его стандартным subwallet id.
+	SubwalletBase uint32 `yaml:"subwallet_base" env:"TON_DEPOSIT_ADDRESSES_SUBWALLET_BASE" env-default:"1000000"`
+	// PollInterval — период проверки входящих платежей на адреса.
+	PollInterval time.Duration `yaml:"poll_interval" env:"TON_DEPOSIT_ADDRESSES_POLL_INTERVAL" env-default:"30s"`
+	// SweepInterval — период сметания средств в казначейство.
+	SweepInterval time.Duration `yaml:"sweep_interval" env:"TON_DEPOSIT_ADDRESSES_SWEEP_INTERVAL" env-default:"10m"`
+	// SweepMinAmount — минимальный баланс адреса в TON, который стоит сметать.
+	SweepMinAmount string `yaml:"sweep_min_amount" env:"TON_DEPOSIT_ADDRESSES_SWEEP_MIN_AMOUNT" env-default:"0.5"`
+}
+
+type TonWithdrawalConfig struct {
+	// MinAmount — минимальная сумма вывода в TON.
+	MinAmount string `yaml:"min_amount" env:"TON_WITHDRAWAL_MIN_AMOUNT" env-default:"0.1"`
+	// BatchSize — сколько переводов отправляется одним внешним сообщением (v4r2 — до 4).
+	BatchSize int32 `yaml:"batch_size" env:"TON_WITHDRAWAL_BATCH_SIZE" env-default:"4"`
+	// PollInterval — период отправки новых и проверки отправленных переводов.
+	PollInterval time.Duration `yaml:"poll_interval" env:"TON_WITHDRAWAL_POLL_INTERVAL" env-default:"10s"`
+	// MessageTTL — срок действия подписанного сообщения кошелька.
+	MessageTTL time.Duration `yaml:"message_ttl" env:"TON_WITHDRAWAL_MESSAGE_TTL" env-default:"3m"`
+}
+
+// HoldsConfig — резервирование средств на балансе до списания или отмены.
+type HoldsConfig struct {
+	// DefaultTTL — срок холда, если вызывающий его не указал.
+	DefaultTTL time.Duration `yaml:"default_ttl" env:"HOLDS_DEFAULT_TTL" env-default:"15m"`
+	// MaxTTL — максимальный срок холда.
+	MaxTTL time.Duration `yaml:"max_ttl" env:"HOLDS_MAX_TTL" env-default:"24h"`
+	// SweepInterval — период снятия истёкших холдов.
+	SweepInterval time.Duration `yaml:"sweep_interval" env:"HOLDS_SWEEP_INTERVAL" env-default:"1m"`
 }
 
 type Config struct {
@@ -30,6 +104,7 @@ type Config struct {
 	AMQP     configs.AMQPConfig     `yaml:"amqp"`
 	GRPC     configs.GRPCConfig     `yaml:"grpc"`
 	Ton      TonConfig              `yaml:"ton"`
+	Holds    HoldsConfig            `yaml:"holds"`
 }
 
 func LoadConfig() (*Config, error) {
@@ -0,0 +1,61 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/peterparker2005/giftduels/packages/tonamount-go"
+	"github.com/shopspring/decimal"
+)
+
+// Currency — валюта баланса. TON — игровой баланс, остальные валюты
+// пополняются джеттонами и хранятся отдельно.
+type Currency string
+
+const (
+	CurrencyTON  Currency = "TON"
+	CurrencyUSDT Currency = "USDT"
+)
+
+const (
+	tonDecimals  = 9
+	usdtDecimals = 6
+)
+
+func ParseCurrency(s string) (Currency, error) {
+	switch c := Currency(s); c {
+	case CurrencyTON, CurrencyUSDT:
+		return c, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnknownCurrency, s)
+	}
+}
+
+// Decimals — число знаков после запятой у минимальной единицы валюты в сети.
+func (c Currency) Decimals() int32 {
+	if c == CurrencyUSDT {
+		return usdtDecimals
+	}
+	return tonDecimals
+}
+
+// AmountFromUnits переводит сумму из минимальных единиц валюты (нано для TON).
+func (c Currency) AmountFromUnits(units uint64) (*tonamount.TonAmount, error) {
+	d := decimal.NewFromBigInt(new(big.Int).SetUint64(units), -c.Decimals())
+	return tonamount.NewTonAmountFromString(d.String())
+}
+
+// UnitsFromAmount переводит сумму в минимальные единицы валюты.
+func (c Currency) UnitsFromAmount(amount *tonamount.TonAmount) (uint64, error) {
+	d := amount.Decimal().Shift(c.Decimals())
+	if d.IsNegative() || !d.BigInt().IsUint64() {
+		return 0, fmt.Errorf("amount %s out of range", amount)
+	}
+	return d.BigInt().Uint64(), nil
+}
+
+// RateProvider отдаёт курс: сколько единиц to стоит одна единица from.
+type RateProvider interface {
+	Rate(ctx context.Context, from, to Currency) (decimal.Decimal, error)
+}
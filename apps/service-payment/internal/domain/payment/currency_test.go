@@ -0,0 +1,78 @@
+package payment_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/payment"
+)
+
+func TestCurrencyUnits(t *testing.T) {
+	tests := []struct {
+		name       string
+		currency   payment.Currency
+		units      uint64
+		wantAmount string
+		// wantUnits — обратный перевод; суммы хранятся с точностью до центов
+		wantUnits uint64
+	}{
+		{
+			name:       "ton nano",
+			currency:   payment.CurrencyTON,
+			units:      1_500_000_000,
+			wantAmount: "1.5",
+			wantUnits:  1_500_000_000,
+		},
+		{
+			name:       "usdt micro",
+			currency:   payment.CurrencyUSDT,
+			units:      1_500_000,
+			wantAmount: "1.5",
+			wantUnits:  1_500_000,
+		},
+		{
+			name:       "usdt rounds to cents",
+			currency:   payment.CurrencyUSDT,
+			units:      1_234_567,
+			wantAmount: "1.23",
+			wantUnits:  1_230_000,
+		},
+		{
+			name:       "usdt dust",
+			currency:   payment.CurrencyUSDT,
+			units:      4_999,
+			wantAmount: "0",
+			wantUnits:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount, err := tt.currency.AmountFromUnits(tt.units)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if amount.String() != tt.wantAmount {
+				t.Errorf("amount %s, want %s", amount, tt.wantAmount)
+			}
+			units, err := tt.currency.UnitsFromAmount(amount)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if units != tt.wantUnits {
+				t.Errorf("units %d, want %d", units, tt.wantUnits)
+			}
+		})
+	}
+}
+
+func TestParseCurrency(t *testing.T) {
+	for _, s := range []string{"TON", "USDT"} {
+		if c, err := payment.ParseCurrency(s); err != nil || string(c) != s {
+			t.Errorf("ParseCurrency(%q) = %q, %v", s, c, err)
+		}
+	}
+	if _, err := payment.ParseCurrency("usdt"); !errors.Is(err, payment.ErrUnknownCurrency) {
+		t.Errorf("expected ErrUnknownCurrency, got %v", err)
+	}
+}
@@ -6,17 +6,35 @@ import (
 	"github.com/peterparker2005/giftduels/packages/tonamount-go"
 )
 
+// Balance — баланс пользователя в одной валюте. TonAmount хранит всю сумму
+// в единицах Currency, включая зарезервированную холдами HeldAmount.
 type Balance struct {
 	ID             string
 	TelegramUserID int64
+	Currency       Currency
 	TonAmount      *tonamount.TonAmount
+	HeldAmount     *tonamount.TonAmount
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
 }
 
+// Held возвращает сумму, зарезервированную активными холдами.
+func (b *Balance) Held() *tonamount.TonAmount {
+	if b.HeldAmount == nil {
+		return tonamount.Zero()
+	}
+	return b.HeldAmount
+}
+
+// Available возвращает сумму, доступную для списаний и новых холдов.
+func (b *Balance) Available() *tonamount.TonAmount {
+	return b.TonAmount.Sub(b.Held())
+}
+
 type Transaction struct {
 	ID             string
 	TelegramUserID int64
+	Currency       Currency
 	Amount         *tonamount.TonAmount
 	Reason         TransactionReason
 	Metadata       *TransactionMetadata
@@ -26,12 +44,16 @@ type Transaction struct {
 type GiftWithdrawRequest struct {
 	GiftID string
 	Price  *tonamount.TonAmount
+	// Collection — название коллекции подарка для ступеней комиссии.
+	Collection string
 }
 
 type WithdrawOptions struct {
 	GiftFees      []*GiftFee
 	TotalStarsFee uint32
 	TotalTonFee   *tonamount.TonAmount
+	// FeeScheduleVersion — версия расписания, по которой посчитаны комиссии.
+	FeeScheduleVersion int64
 }
 
 type GiftFee struct {
@@ -46,4 +68,12 @@ const (
 	TransactionReasonDeposit  TransactionReason = "deposit"
 	TransactionReasonWithdraw TransactionReason = "withdraw"
 	TransactionReasonRefund   TransactionReason = "refund"
+	TransactionReasonPurchase TransactionReason = "purchase"
+	TransactionReasonSale     TransactionReason = "sale"
+	TransactionReasonSellBack TransactionReason = "sell_back"
+	// TransactionReasonTonWithdrawal — вывод TON на внешний кошелёк.
+	TransactionReasonTonWithdrawal TransactionReason = "ton_withdrawal"
+	// TransactionReasonOpeningBalance — входящий остаток, перенесённый
+	// в журнал из баланса, накопленного до его появления.
+	TransactionReasonOpeningBalance TransactionReason = "opening_balance"
 )
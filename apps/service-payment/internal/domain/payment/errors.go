@@ -2,4 +2,14 @@ package payment
 
 import "errors"
 
-var ErrTonAmountNegative = errors.New("ton amount cannot be negative")
+var (
+	ErrTonAmountNegative = errors.New("ton amount cannot be negative")
+	ErrUnknownCurrency   = errors.New("unknown currency")
+	ErrRateUnavailable   = errors.New("exchange rate is unavailable")
+	// ErrUnbalancedEntry — проводки записи журнала не сходятся в ноль.
+	ErrUnbalancedEntry = errors.New("unbalanced journal entry")
+	// ErrInvalidFeeSchedule — расписание комиссий нельзя опубликовать.
+	ErrInvalidFeeSchedule = errors.New("invalid fee schedule")
+	// ErrNoFeeTier — ни одна ступень расписания не подошла подарку.
+	ErrNoFeeTier = errors.New("no fee tier matches the gift")
+)
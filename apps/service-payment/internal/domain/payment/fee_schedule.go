@@ -0,0 +1,179 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ccoveille/go-safecast"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/peterparker2005/giftduels/packages/tonamount-go"
+	"github.com/shopspring/decimal"
+)
+
+// FeeSchedule — версия правил комиссии за вывод подарков. Опубликованная
+// версия не меняется: новые правила публикуются следующей версией.
+type FeeSchedule struct {
+	ID      uuid.UUID
+	Version int64
+	// TonPerStar — стоимость одной звезды в TON.
+	TonPerStar    decimal.Decimal
+	EffectiveFrom time.Time
+	Comment       string
+	// Из подходящих ступеней применяется ступень с наименьшим Priority,
+	// независимо от порядка в срезе.
+	Tiers      []FeeTier
+	Promotions []FeePromotion
+	CreatedAt  time.Time
+}
+
+// FeeTier — ставка комиссии для подарков коллекции и диапазона цен.
+type FeeTier struct {
+	Priority int32
+	// Collection — название коллекции; пустая строка — любая коллекция.
+	Collection string
+	// MinPrice и MaxPrice — полуинтервал [MinPrice, MaxPrice) по цене
+	// подарка; nil — без границы.
+	MinPrice *tonamount.TonAmount
+	MaxPrice *tonamount.TonAmount
+	// Rate — доля стоимости подарка, взимаемая в звёздах.
+	Rate     decimal.Decimal
+	MinStars uint32
+	MaxStars uint32
+}
+
+// FeePromotion — окно [StartsAt, EndsAt), в которое вывод бесплатен.
+type FeePromotion struct {
+	// Collection — название коллекции; пустая строка — любая коллекция.
+	Collection string
+	StartsAt   time.Time
+	EndsAt     time.Time
+}
+
+// Commission — комиссия за вывод одного подарка.
+type Commission struct {
+	Stars uint32
+	Ton   *tonamount.TonAmount
+}
+
+func (t *FeeTier) matches(gift *GiftWithdrawRequest) bool {
+	if t.Collection != "" && t.Collection != gift.Collection {
+		return false
+	}
+	price := gift.Price.Decimal()
+	if t.MinPrice != nil && price.LessThan(t.MinPrice.Decimal()) {
+		return false
+	}
+	if t.MaxPrice != nil && !price.LessThan(t.MaxPrice.Decimal()) {
+		return false
+	}
+	return true
+}
+
+// isFallback сообщает, что ступень подходит любому подарку.
+func (t *FeeTier) isFallback() bool {
+	return t.Collection == "" && t.MinPrice == nil && t.MaxPrice == nil
+}
+
+func (p *FeePromotion) covers(gift *GiftWithdrawRequest, at time.Time) bool {
+	if p.Collection != "" && p.Collection != gift.Collection {
+		return false
+	}
+	return !at.Before(p.StartsAt) && at.Before(p.EndsAt)
+}
+
+// Commission считает комиссию за вывод подарка в момент at. Звёзды
+// округляются вверх после ограничения ступени, TON — до точности TonAmount.
+func (s *FeeSchedule) Commission(gift *GiftWithdrawRequest, at time.Time) (*Commission, error) {
+	for i := range s.Promotions {
+		if s.Promotions[i].covers(gift, at) {
+			return &Commission{Stars: 0, Ton: tonamount.Zero()}, nil
+		}
+	}
+
+	var tier *FeeTier
+	for i := range s.Tiers {
+		if !s.Tiers[i].matches(gift) {
+			continue
+		}
+		if tier == nil || s.Tiers[i].Priority < tier.Priority {
+			tier = &s.Tiers[i]
+		}
+	}
+	if tier == nil {
+		return nil, fmt.Errorf("%w: schedule %d, gift %s", ErrNoFeeTier, s.Version, gift.GiftID)
+	}
+
+	raw := gift.Price.Decimal().Div(s.TonPerStar).Mul(tier.Rate)
+	if minStars := decimal.NewFromInt(int64(tier.MinStars)); raw.LessThan(minStars) {
+		raw = minStars
+	}
+	if maxStars := decimal.NewFromInt(int64(tier.MaxStars)); raw.GreaterThan(maxStars) {
+		raw = maxStars
+	}
+	stars, err := safecast.ToUint32(raw.Ceil().IntPart())
+	if err != nil {
+		return nil, err
+	}
+
+	ton, err := tonamount.NewTonAmountFromString(
+		decimal.NewFromInt(int64(stars)).Mul(s.TonPerStar).String(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &Commission{Stars: stars, Ton: ton}, nil
+}
+
+// Validate проверяет расписание перед публикацией. Обязательна ступень
+// без коллекции и границ цены, чтобы комиссия считалась для любого подарка.
+func (s *FeeSchedule) Validate() error {
+	if !s.TonPerStar.IsPositive() {
+		return fmt.Errorf("%w: ton per star must be positive", ErrInvalidFeeSchedule)
+	}
+	if len(s.Tiers) == 0 {
+		return fmt.Errorf("%w: no tiers", ErrInvalidFeeSchedule)
+	}
+
+	priorities := make(map[int32]struct{}, len(s.Tiers))
+	hasFallback := false
+	for _, t := range s.Tiers {
+		if _, ok := priorities[t.Priority]; ok {
+			return fmt.Errorf("%w: duplicate tier priority %d", ErrInvalidFeeSchedule, t.Priority)
+		}
+		priorities[t.Priority] = struct{}{}
+
+		if t.Rate.IsNegative() {
+			return fmt.Errorf("%w: tier %d: negative rate", ErrInvalidFeeSchedule, t.Priority)
+		}
+		if t.MinStars > t.MaxStars {
+			return fmt.Errorf("%w: tier %d: min stars exceed max stars", ErrInvalidFeeSchedule, t.Priority)
+		}
+		if t.MinPrice != nil && t.MaxPrice != nil &&
+			!t.MinPrice.Decimal().LessThan(t.MaxPrice.Decimal()) {
+			return fmt.Errorf("%w: tier %d: empty price range", ErrInvalidFeeSchedule, t.Priority)
+		}
+		hasFallback = hasFallback || t.isFallback()
+	}
+	if !hasFallback {
+		return fmt.Errorf("%w: no tier matching every gift", ErrInvalidFeeSchedule)
+	}
+
+	for _, p := range s.Promotions {
+		if !p.StartsAt.Before(p.EndsAt) {
+			return fmt.Errorf("%w: promotion window is empty", ErrInvalidFeeSchedule)
+		}
+	}
+	return nil
+}
+
+type FeeScheduleRepository interface {
+	WithTx(tx pgx.Tx) FeeScheduleRepository
+
+	// GetActiveFeeSchedule возвращает последнюю версию, вступившую в силу к at.
+	GetActiveFeeSchedule(ctx context.Context, at time.Time) (*FeeSchedule, error)
+	GetFeeScheduleByVersion(ctx context.Context, version int64) (*FeeSchedule, error)
+	// CreateFeeSchedule сохраняет расписание следующей по порядку версией.
+	CreateFeeSchedule(ctx context.Context, schedule *FeeSchedule) (*FeeSchedule, error)
+}
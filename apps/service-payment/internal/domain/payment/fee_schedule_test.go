@@ -0,0 +1,322 @@
+package payment_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/payment"
+	"github.com/peterparker2005/giftduels/packages/tonamount-go"
+	"github.com/shopspring/decimal"
+)
+
+func mustTon(t *testing.T, s string) *tonamount.TonAmount {
+	t.Helper()
+	a, err := tonamount.NewTonAmountFromString(s)
+	if err != nil {
+		t.Fatalf("cannot parse TonAmount from %q: %v", s, err)
+	}
+	return a
+}
+
+func withdrawRequest(t *testing.T, price, collection string) *payment.GiftWithdrawRequest {
+	t.Helper()
+	return &payment.GiftWithdrawRequest{GiftID: "g1", Price: mustTon(t, price), Collection: collection}
+}
+
+// Вспомогалка: ступень с фиксированной комиссией в stars звёзд.
+func flatTier(priority int32, stars uint32) payment.FeeTier {
+	return payment.FeeTier{Priority: priority, Rate: decimal.Zero, MinStars: stars, MaxStars: stars}
+}
+
+func TestFeeScheduleCommissionTiers(t *testing.T) {
+	pepeTier := flatTier(1, 10)
+	pepeTier.Collection = "Plush Pepe"
+	cheapTier := flatTier(2, 20)
+	cheapTier.MaxPrice = mustTon(t, "10")
+	midTier := flatTier(3, 30)
+	midTier.MinPrice = mustTon(t, "10")
+	midTier.MaxPrice = mustTon(t, "100")
+
+	// ступени намеренно перемешаны: порядок в срезе не должен влиять на выбор
+	schedule := &payment.FeeSchedule{
+		Version:    2,
+		TonPerStar: decimal.RequireFromString("0.01"),
+		Tiers:      []payment.FeeTier{flatTier(100, 40), midTier, cheapTier, pepeTier},
+	}
+
+	tests := []struct {
+		name       string
+		price      string
+		collection string
+		wantStars  uint32
+		wantTon    string
+	}{
+		{name: "collection tier beats price tier", price: "5", collection: "Plush Pepe", wantStars: 10, wantTon: "0.1"},
+		{name: "collection tier ignores price", price: "500", collection: "Plush Pepe", wantStars: 10, wantTon: "0.1"},
+		{name: "price tier below max", price: "9.99", collection: "Other", wantStars: 20, wantTon: "0.2"},
+		{name: "max price is exclusive", price: "10", wantStars: 30, wantTon: "0.3"},
+		{name: "min price is inclusive", price: "99.99", wantStars: 30, wantTon: "0.3"},
+		{name: "fallback above every range", price: "100", wantStars: 40, wantTon: "0.4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := schedule.Commission(withdrawRequest(t, tt.price, tt.collection), time.Now())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Stars != tt.wantStars || got.Ton.String() != tt.wantTon {
+				t.Errorf("commission = %d stars / %s TON, want %d / %s",
+					got.Stars, got.Ton, tt.wantStars, tt.wantTon)
+			}
+		})
+	}
+}
+
+func TestFeeScheduleCommissionNoTier(t *testing.T) {
+	tier := flatTier(1, 5)
+	tier.Collection = "Plush Pepe"
+	schedule := &payment.FeeSchedule{
+		TonPerStar: decimal.RequireFromString("0.01"),
+		Tiers:      []payment.FeeTier{tier},
+	}
+
+	_, err := schedule.Commission(withdrawRequest(t, "1", "Other"), time.Now())
+	if !errors.Is(err, payment.ErrNoFeeTier) {
+		t.Fatalf("expected ErrNoFeeTier, got %v", err)
+	}
+}
+
+func TestFeeScheduleCommissionPromotions(t *testing.T) {
+	start := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+	schedule := &payment.FeeSchedule{
+		TonPerStar: decimal.RequireFromString("0.01"),
+		Tiers:      []payment.FeeTier{flatTier(1, 25)},
+		Promotions: []payment.FeePromotion{
+			{StartsAt: start, EndsAt: end},
+			{Collection: "Plush Pepe", StartsAt: end, EndsAt: end.Add(time.Hour)},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		at         time.Time
+		collection string
+		wantFree   bool
+	}{
+		{name: "before window", at: start.Add(-time.Nanosecond)},
+		{name: "window start is inclusive", at: start, wantFree: true},
+		{name: "inside window", at: start.Add(12 * time.Hour), wantFree: true},
+		{name: "window end is exclusive", at: end},
+		{name: "collection promotion", at: end, collection: "Plush Pepe", wantFree: true},
+		{name: "collection promotion skips other collections", at: end.Add(time.Minute), collection: "Other"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := schedule.Commission(withdrawRequest(t, "1", tt.collection), tt.at)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			wantStars, wantTon := uint32(25), "0.25"
+			if tt.wantFree {
+				wantStars, wantTon = 0, "0"
+			}
+			if got.Stars != wantStars || got.Ton.String() != wantTon {
+				t.Errorf("commission = %d stars / %s TON, want %d / %s",
+					got.Stars, got.Ton, wantStars, wantTon)
+			}
+		})
+	}
+}
+
+func TestFeeScheduleCommissionClamp(t *testing.T) {
+	// 1 звезда = 0.01 TON, ставка 10%: цена 1 TON даёт 10 звёзд
+	schedule := &payment.FeeSchedule{
+		TonPerStar: decimal.RequireFromString("0.01"),
+		Tiers: []payment.FeeTier{{
+			Priority: 1,
+			Rate:     decimal.RequireFromString("0.1"),
+			MinStars: 5,
+			MaxStars: 50,
+		}},
+	}
+
+	tests := []struct {
+		name      string
+		price     string
+		wantStars uint32
+		wantTon   string
+	}{
+		{name: "below min is raised", price: "0.2", wantStars: 5, wantTon: "0.05"},
+		{name: "within range", price: "1", wantStars: 10, wantTon: "0.1"},
+		{name: "fraction rounds up", price: "1.01", wantStars: 11, wantTon: "0.11"},
+		{name: "above max is cut", price: "100", wantStars: 50, wantTon: "0.5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := schedule.Commission(withdrawRequest(t, tt.price, ""), time.Now())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Stars != tt.wantStars || got.Ton.String() != tt.wantTon {
+				t.Errorf("commission = %d stars / %s TON, want %d / %s",
+					got.Stars, got.Ton, tt.wantStars, tt.wantTon)
+			}
+		})
+	}
+}
+
+// Прежний расчёт комиссии на константах до появления расписаний.
+func legacyCommission(price decimal.Decimal) (uint32, string) {
+	const (
+		minStars = 25
+		maxStars = 1
+	)
+	tonPerStar := decimal.RequireFromString("0.005356")
+	raw := price.Div(tonPerStar).Mul(decimal.RequireFromString("0.15"))
+	if raw.LessThan(decimal.NewFromInt(minStars)) {
+		raw = decimal.NewFromInt(minStars)
+	}
+	if raw.GreaterThan(decimal.NewFromInt(maxStars)) {
+		raw = decimal.NewFromInt(maxStars)
+	}
+	stars := raw.Ceil()
+	return uint32(stars.IntPart()), stars.Mul(tonPerStar).Round(2).String()
+}
+
+// Версия 1, которую сеет миграция, обязана считать ровно как старые константы.
+func TestSeededFeeScheduleMatchesLegacyCommission(t *testing.T) {
+	schedule := &payment.FeeSchedule{
+		Version:    1,
+		TonPerStar: decimal.RequireFromString("0.005356"),
+		Tiers: []payment.FeeTier{{
+			Priority: 1,
+			Rate:     decimal.RequireFromString("0.15"),
+			MinStars: 1,
+			MaxStars: 1,
+		}},
+	}
+	if err := schedule.Validate(); err != nil {
+		t.Fatalf("seeded schedule is invalid: %v", err)
+	}
+
+	for _, price := range []string{"0.01", "0.5", "1", "3.7", "10", "1000", "100000"} {
+		t.Run(price, func(t *testing.T) {
+			got, err := schedule.Commission(withdrawRequest(t, price, "Any"), time.Now())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			wantStars, wantTon := legacyCommission(decimal.RequireFromString(price))
+			if got.Stars != wantStars || got.Ton.String() != wantTon {
+				t.Errorf("commission = %d stars / %s TON, legacy %d / %s",
+					got.Stars, got.Ton, wantStars, wantTon)
+			}
+		})
+	}
+}
+
+func TestFeeScheduleValidate(t *testing.T) {
+	tonPerStar := decimal.RequireFromString("0.01")
+	start := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	pricedTier := func(priority int32, minPrice, maxPrice string) payment.FeeTier {
+		tier := flatTier(priority, 1)
+		tier.MinPrice = mustTon(t, minPrice)
+		tier.MaxPrice = mustTon(t, maxPrice)
+		return tier
+	}
+
+	tests := []struct {
+		name     string
+		schedule *payment.FeeSchedule
+		wantErr  bool
+	}{
+		{
+			name: "valid",
+			schedule: &payment.FeeSchedule{
+				TonPerStar: tonPerStar,
+				Tiers:      []payment.FeeTier{pricedTier(1, "0", "10"), flatTier(2, 1)},
+				Promotions: []payment.FeePromotion{{StartsAt: start, EndsAt: start.Add(time.Hour)}},
+			},
+		},
+		{
+			name:     "zero ton per star",
+			schedule: &payment.FeeSchedule{Tiers: []payment.FeeTier{flatTier(1, 1)}},
+			wantErr:  true,
+		},
+		{
+			name:     "no tiers",
+			schedule: &payment.FeeSchedule{TonPerStar: tonPerStar},
+			wantErr:  true,
+		},
+		{
+			name: "duplicate priority",
+			schedule: &payment.FeeSchedule{
+				TonPerStar: tonPerStar,
+				Tiers:      []payment.FeeTier{pricedTier(1, "0", "10"), flatTier(1, 1)},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative rate",
+			schedule: &payment.FeeSchedule{
+				TonPerStar: tonPerStar,
+				Tiers: []payment.FeeTier{
+					{Priority: 1, Rate: decimal.NewFromInt(-1), MinStars: 1, MaxStars: 1},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "min stars above max stars",
+			schedule: &payment.FeeSchedule{
+				TonPerStar: tonPerStar,
+				Tiers:      []payment.FeeTier{{Priority: 1, MinStars: 2, MaxStars: 1}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "empty price range",
+			schedule: &payment.FeeSchedule{
+				TonPerStar: tonPerStar,
+				Tiers:      []payment.FeeTier{pricedTier(1, "10", "10"), flatTier(2, 1)},
+			},
+			wantErr: true,
+		},
+		{
+			name: "no fallback tier",
+			schedule: &payment.FeeSchedule{
+				TonPerStar: tonPerStar,
+				Tiers:      []payment.FeeTier{pricedTier(1, "0", "10")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "empty promotion window",
+			schedule: &payment.FeeSchedule{
+				TonPerStar: tonPerStar,
+				Tiers:      []payment.FeeTier{flatTier(1, 1)},
+				Promotions: []payment.FeePromotion{{StartsAt: start, EndsAt: start}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.schedule.Validate()
+			if tt.wantErr {
+				if !errors.Is(err, payment.ErrInvalidFeeSchedule) {
+					t.Fatalf("expected ErrInvalidFeeSchedule, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
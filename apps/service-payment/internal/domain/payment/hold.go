@@ -0,0 +1,86 @@
+package payment
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/peterparker2005/giftduels/packages/tonamount-go"
+)
+
+type HoldStatus string
+
+const (
+	// HoldStatusActive — сумма зарезервирована на балансе.
+	HoldStatusActive HoldStatus = "active"
+	// HoldStatusCaptured — резерв списан с баланса.
+	HoldStatusCaptured HoldStatus = "captured"
+	// HoldStatusReleased — резерв отменён, сумма снова доступна.
+	HoldStatusReleased HoldStatus = "released"
+	// HoldStatusExpired — резерв снят по истечении срока.
+	HoldStatusExpired HoldStatus = "expired"
+)
+
+// Hold — резерв суммы на балансе пользователя. Пока холд активен, эта
+// сумма недоступна для списаний и других холдов.
+type Hold struct {
+	ID             uuid.UUID
+	TelegramUserID int64
+	Currency       Currency
+	Amount         *tonamount.TonAmount
+	// Reason — причина транзакции, с которой холд будет списан.
+	Reason    TransactionReason
+	Metadata  *TransactionMetadata
+	Status    HoldStatus
+	ExpiresAt time.Time
+	SettledAt *time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (h *Hold) IsActive() bool {
+	return h.Status == HoldStatusActive
+}
+
+// IsExpired сообщает, истёк ли срок холда к моменту now.
+func (h *Hold) IsExpired(now time.Time) bool {
+	return !now.Before(h.ExpiresAt)
+}
+
+type CreateHoldParams struct {
+	TelegramUserID int64
+	Currency       Currency
+	Amount         *tonamount.TonAmount
+	Reason         TransactionReason
+	Metadata       []byte
+	ExpiresAt      time.Time
+}
+
+type HoldRepository interface {
+	WithTx(tx pgx.Tx) HoldRepository
+
+	// ReserveBalance увеличивает зарезервированную часть баланса на amount,
+	// если доступной суммы хватает.
+	ReserveBalance(
+		ctx context.Context,
+		telegramUserID int64,
+		currency Currency,
+		amount *tonamount.TonAmount,
+	) (*Balance, error)
+	// UnreserveBalance уменьшает зарезервированную часть баланса на amount.
+	UnreserveBalance(
+		ctx context.Context,
+		telegramUserID int64,
+		currency Currency,
+		amount *tonamount.TonAmount,
+	) (*Balance, error)
+
+	CreateHold(ctx context.Context, params *CreateHoldParams) (*Hold, error)
+	GetHoldForUpdate(ctx context.Context, id uuid.UUID) (*Hold, error)
+	// SettleHold переводит активный холд в конечный статус.
+	SettleHold(ctx context.Context, id uuid.UUID, status HoldStatus) (*Hold, error)
+	// GetExpiredHolds блокирует до limit активных холдов, истёкших к now,
+	// пропуская уже заблокированные.
+	GetExpiredHolds(ctx context.Context, now time.Time, limit int32) ([]*Hold, error)
+}
@@ -0,0 +1,82 @@
+package payment
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/peterparker2005/giftduels/packages/tonamount-go"
+)
+
+// IdempotencyOperation — операция с балансом, повторы которой отсекаются
+// по ключу идемпотентности.
+type IdempotencyOperation string
+
+const (
+	IdempotencyOperationSpend IdempotencyOperation = "spend_user_balance"
+	IdempotencyOperationAdd   IdempotencyOperation = "add_user_balance"
+)
+
+// BalanceRequest — параметры запроса на изменение баланса. Повтор с тем же
+// ключом идемпотентности должен совпадать с исходным запросом по всем полям.
+type BalanceRequest struct {
+	Operation      IdempotencyOperation
+	TelegramUserID int64
+	Amount         *tonamount.TonAmount
+	Reason         TransactionReason
+	Metadata       []byte
+}
+
+// Hash возвращает отпечаток параметров запроса.
+func (r *BalanceRequest) Hash() string {
+	h := sha256.New()
+	for _, part := range []string{
+		string(r.Operation),
+		strconv.FormatInt(r.TelegramUserID, 10),
+		r.Amount.String(),
+		string(r.Reason),
+		string(r.Metadata),
+	} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// IdempotencyKey — ключ идемпотентности вместе с результатом исходного
+// запроса.
+type IdempotencyKey struct {
+	Key            string
+	Operation      IdempotencyOperation
+	RequestHash    string
+	TelegramUserID int64
+	// ResultAmount — баланс после исходной операции.
+	ResultAmount *tonamount.TonAmount
+	CreatedAt    time.Time
+}
+
+// NewIdempotencyKey запоминает результат запроса req под ключом key.
+func NewIdempotencyKey(key string, req *BalanceRequest, result *Balance) *IdempotencyKey {
+	return &IdempotencyKey{
+		Key:            key,
+		Operation:      req.Operation,
+		RequestHash:    req.Hash(),
+		TelegramUserID: req.TelegramUserID,
+		ResultAmount:   result.TonAmount,
+	}
+}
+
+// Matches сообщает, что req повторяет запрос, для которого выдан ключ.
+func (k *IdempotencyKey) Matches(req *BalanceRequest) bool {
+	return k.Operation == req.Operation && k.RequestHash == req.Hash()
+}
+
+// Balance восстанавливает результат исходного запроса.
+func (k *IdempotencyKey) Balance() *Balance {
+	return &Balance{
+		TelegramUserID: k.TelegramUserID,
+		Currency:       CurrencyTON,
+		TonAmount:      k.ResultAmount,
+	}
+}
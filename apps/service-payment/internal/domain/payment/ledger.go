@@ -0,0 +1,150 @@
+package payment
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// AccountType — вид счёта журнала.
+type AccountType string
+
+const (
+	// AccountTypeUser — баланс пользователя.
+	AccountTypeUser AccountType = "user"
+	// AccountTypeTreasury — средства на кошельках сервиса: пополнения и выводы.
+	AccountTypeTreasury AccountType = "treasury"
+	// AccountTypeFees — комиссии за вывод подарков.
+	AccountTypeFees AccountType = "fees"
+	// AccountTypeHouse — расчёты с игрой: покупки, продажи, выкуп подарков.
+	AccountTypeHouse AccountType = "house"
+)
+
+// LedgerAccount — счёт журнала. У системных счетов TelegramUserID = 0.
+type LedgerAccount struct {
+	Type           AccountType
+	TelegramUserID int64
+	Currency       Currency
+}
+
+func UserAccount(telegramUserID int64, currency Currency) LedgerAccount {
+	return LedgerAccount{Type: AccountTypeUser, TelegramUserID: telegramUserID, Currency: currency}
+}
+
+func SystemAccount(accountType AccountType, currency Currency) LedgerAccount {
+	return LedgerAccount{Type: accountType, Currency: currency}
+}
+
+// Posting — изменение одного счёта. Плюс увеличивает обязательства перед
+// владельцем счёта (для пользователя — его баланс), минус уменьшает.
+type Posting struct {
+	Account LedgerAccount
+	Amount  decimal.Decimal
+}
+
+// JournalEntry — неизменяемая запись журнала. Сумма проводок записи
+// всегда равна нулю: деньги только перемещаются между счетами.
+type JournalEntry struct {
+	Reason   TransactionReason
+	Currency Currency
+	Metadata []byte
+	Postings []Posting
+}
+
+// NewTransferEntry — запись о перемещении amount со счёта from на счёт to.
+func NewTransferEntry(
+	reason TransactionReason,
+	from, to LedgerAccount,
+	amount decimal.Decimal,
+	metadata []byte,
+) *JournalEntry {
+	return &JournalEntry{
+		Reason:   reason,
+		Currency: to.Currency,
+		Metadata: metadata,
+		Postings: []Posting{
+			{Account: from, Amount: amount.Neg()},
+			{Account: to, Amount: amount},
+		},
+	}
+}
+
+// Validate проверяет, что запись сбалансирована: не меньше двух ненулевых
+// проводок в валюте записи с нулевой суммой.
+func (e *JournalEntry) Validate() error {
+	if len(e.Postings) < 2 { //nolint:mnd // двойная запись
+		return ErrUnbalancedEntry
+	}
+	total := decimal.Zero
+	for _, p := range e.Postings {
+		if p.Amount.IsZero() || p.Account.Currency != e.Currency {
+			return ErrUnbalancedEntry
+		}
+		total = total.Add(p.Amount)
+	}
+	if !total.IsZero() {
+		return ErrUnbalancedEntry
+	}
+	return nil
+}
+
+// TelegramUserID — пользователь, чей баланс затрагивает запись, или 0.
+func (e *JournalEntry) TelegramUserID() int64 {
+	for _, p := range e.Postings {
+		if p.Account.Type == AccountTypeUser {
+			return p.Account.TelegramUserID
+		}
+	}
+	return 0
+}
+
+// CounterAccount — системный счёт, за счёт которого меняется баланс
+// пользователя по причине reason. Возврат вывода TON идёт в казначейство,
+// возврат комиссии за вывод подарка — со счёта комиссий, остальные возвраты
+// отменяют игровые операции и идут через house.
+func CounterAccount(reason TransactionReason, metadata *TransactionMetadata) AccountType {
+	switch reason {
+	case TransactionReasonDeposit, TransactionReasonTonWithdrawal:
+		return AccountTypeTreasury
+	case TransactionReasonWithdraw:
+		return AccountTypeFees
+	case TransactionReasonRefund:
+		switch {
+		case metadata == nil:
+			return AccountTypeHouse
+		case metadata.TonWithdrawal != nil:
+			return AccountTypeTreasury
+		case metadata.Gift != nil && metadata.Gift.WithdrawalCommission:
+			return AccountTypeFees
+		default:
+			return AccountTypeHouse
+		}
+	default:
+		return AccountTypeHouse
+	}
+}
+
+// UnbalancedEntry — запись журнала, нарушающая двойную запись.
+type UnbalancedEntry struct {
+	ID            string
+	Reason        TransactionReason
+	Currency      Currency
+	PostingsCount int64
+	Total         decimal.Decimal
+	CreatedAt     time.Time
+}
+
+// BalanceMismatch — расхождение кэшированного баланса с журналом.
+type BalanceMismatch struct {
+	TelegramUserID int64
+	Currency       Currency
+	Cached         decimal.Decimal
+	Ledger         decimal.Decimal
+}
+
+// SystemBalance — сальдо системного счёта.
+type SystemBalance struct {
+	Type     AccountType
+	Currency Currency
+	Amount   decimal.Decimal
+}
@@ -0,0 +1,184 @@
+package payment_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/payment"
+	"github.com/shopspring/decimal"
+)
+
+func TestCounterAccount(t *testing.T) {
+	tests := []struct {
+		name     string
+		reason   payment.TransactionReason
+		metadata *payment.TransactionMetadata
+		want     payment.AccountType
+	}{
+		{
+			name:   "deposit comes from treasury",
+			reason: payment.TransactionReasonDeposit,
+			want:   payment.AccountTypeTreasury,
+		},
+		{
+			name:     "ton withdrawal goes to treasury",
+			reason:   payment.TransactionReasonTonWithdrawal,
+			metadata: payment.NewTonWithdrawalMetadata("w1", "EQdest"),
+			want:     payment.AccountTypeTreasury,
+		},
+		{
+			name:     "gift withdrawal commission goes to fees",
+			reason:   payment.TransactionReasonWithdraw,
+			metadata: payment.NewGiftWithdrawalCommissionMetadata("g1", "Gift", "gift-1"),
+			want:     payment.AccountTypeFees,
+		},
+		{
+			name:     "ton withdrawal refund returns from treasury",
+			reason:   payment.TransactionReasonRefund,
+			metadata: payment.NewTonWithdrawalMetadata("w1", "EQdest"),
+			want:     payment.AccountTypeTreasury,
+		},
+		{
+			name:     "gift withdrawal commission refund returns from fees",
+			reason:   payment.TransactionReasonRefund,
+			metadata: payment.NewGiftWithdrawalCommissionMetadata("g1", "Gift", "gift-1"),
+			want:     payment.AccountTypeFees,
+		},
+		{
+			name:   "gift refund without commission flag returns from house",
+			reason: payment.TransactionReasonRefund,
+			metadata: &payment.TransactionMetadata{
+				Gift: &payment.TransactionMetadataGiftDetails{GiftID: "g1", Title: "Gift", Slug: "gift-1"},
+			},
+			want: payment.AccountTypeHouse,
+		},
+		{
+			name:   "refund without metadata returns from house",
+			reason: payment.TransactionReasonRefund,
+			want:   payment.AccountTypeHouse,
+		},
+		{
+			name:   "purchase goes to house",
+			reason: payment.TransactionReasonPurchase,
+			want:   payment.AccountTypeHouse,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := payment.CounterAccount(tt.reason, tt.metadata); got != tt.want {
+				t.Errorf("CounterAccount(%s) = %s, want %s", tt.reason, got, tt.want)
+			}
+		})
+	}
+}
+
+// Комиссия за вывод и её возврат проходят через один счёт комиссий,
+// поэтому после возврата его сальдо возвращается к нулю.
+func TestWithdrawalCommissionRefundBalancesFees(t *testing.T) {
+	const telegramUserID = 42
+	amount := decimal.RequireFromString("0.15")
+	metadata := payment.NewGiftWithdrawalCommissionMetadata("g1", "Gift", "gift-1")
+	user := payment.UserAccount(telegramUserID, payment.CurrencyTON)
+
+	charge := payment.NewTransferEntry(
+		payment.TransactionReasonWithdraw,
+		user,
+		payment.SystemAccount(payment.CounterAccount(payment.TransactionReasonWithdraw, metadata), payment.CurrencyTON),
+		amount,
+		nil,
+	)
+	refund := payment.NewTransferEntry(
+		payment.TransactionReasonRefund,
+		payment.SystemAccount(payment.CounterAccount(payment.TransactionReasonRefund, metadata), payment.CurrencyTON),
+		user,
+		amount,
+		nil,
+	)
+
+	balances := make(map[payment.LedgerAccount]decimal.Decimal)
+	for _, entry := range []*payment.JournalEntry{charge, refund} {
+		if err := entry.Validate(); err != nil {
+			t.Fatalf("entry %s is invalid: %v", entry.Reason, err)
+		}
+		if got := entry.TelegramUserID(); got != telegramUserID {
+			t.Errorf("entry %s TelegramUserID = %d, want %d", entry.Reason, got, telegramUserID)
+		}
+		for _, p := range entry.Postings {
+			balances[p.Account] = balances[p.Account].Add(p.Amount)
+		}
+	}
+
+	for account, balance := range balances {
+		if !balance.IsZero() {
+			t.Errorf("account %s balance = %s, want 0", account.Type, balance)
+		}
+	}
+}
+
+func TestJournalEntryValidate(t *testing.T) {
+	user := payment.UserAccount(1, payment.CurrencyTON)
+	fees := payment.SystemAccount(payment.AccountTypeFees, payment.CurrencyTON)
+	one := decimal.NewFromInt(1)
+
+	tests := []struct {
+		name    string
+		entry   *payment.JournalEntry
+		wantErr bool
+	}{
+		{
+			name:  "balanced transfer",
+			entry: payment.NewTransferEntry(payment.TransactionReasonWithdraw, user, fees, one, nil),
+		},
+		{
+			name: "single posting",
+			entry: &payment.JournalEntry{
+				Currency: payment.CurrencyTON,
+				Postings: []payment.Posting{{Account: user, Amount: one}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "postings do not sum to zero",
+			entry: &payment.JournalEntry{
+				Currency: payment.CurrencyTON,
+				Postings: []payment.Posting{
+					{Account: user, Amount: one},
+					{Account: fees, Amount: one},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "posting in another currency",
+			entry: &payment.JournalEntry{
+				Currency: payment.CurrencyTON,
+				Postings: []payment.Posting{
+					{Account: user, Amount: one.Neg()},
+					{Account: payment.SystemAccount(payment.AccountTypeFees, payment.CurrencyUSDT), Amount: one},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "zero amount",
+			entry:   payment.NewTransferEntry(payment.TransactionReasonWithdraw, user, fees, decimal.Zero, nil),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.entry.Validate()
+			if tt.wantErr {
+				if !errors.Is(err, payment.ErrUnbalancedEntry) {
+					t.Fatalf("expected ErrUnbalancedEntry, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -5,45 +5,52 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/peterparker2005/giftduels/packages/shared"
-	"github.com/peterparker2005/giftduels/packages/tonamount-go"
 )
 
 type CreateBalanceParams struct {
 	TelegramUserID int64
 }
 
-type CreateTransactionParams struct {
-	TelegramUserID int64
-	Amount         *tonamount.TonAmount
-	Reason         TransactionReason
-	Metadata       []byte
-}
-
-type AddUserBalanceParams struct {
-	TelegramUserID int64
-	Amount         *tonamount.TonAmount
-}
-
-type SpendUserBalanceParams struct {
-	TelegramUserID int64
-	Amount         *tonamount.TonAmount
-}
-
 type Repository interface {
 	WithTx(tx pgx.Tx) Repository
 	Create(ctx context.Context, params *CreateBalanceParams) error
 
-	CreateTransaction(ctx context.Context, params *CreateTransactionParams) error
-	DeleteTransaction(ctx context.Context, id string) error
-
-	GetUserBalance(ctx context.Context, telegramUserID int64) (*Balance, error)
-	AddUserBalance(ctx context.Context, params *AddUserBalanceParams) (*Balance, error)
-	SpendUserBalance(ctx context.Context, params *SpendUserBalanceParams) (*Balance, error)
-
+	GetUserBalance(ctx context.Context, telegramUserID int64, currency Currency) (*Balance, error)
+	// GetUserBalances возвращает балансы пользователя во всех валютах.
+	GetUserBalances(ctx context.Context, telegramUserID int64) ([]*Balance, error)
+	// PostEntry записывает запись журнала, обновляет кэш балансов
+	// пользователей и историю их транзакций. Вызывается в транзакции БД.
+	// Возвращает балансы пользователей, затронутых записью, в порядке проводок.
+	PostEntry(ctx context.Context, entry *JournalEntry) ([]*Balance, error)
+
+	// GetIdempotencyKey возвращает сохранённый ключ идемпотентности.
+	GetIdempotencyKey(ctx context.Context, key string) (*IdempotencyKey, error)
+	// SaveIdempotencyKey сохраняет ключ с результатом запроса. Возвращает
+	// false, если ключ уже занят другим запросом.
+	SaveIdempotencyKey(ctx context.Context, key *IdempotencyKey) (bool, error)
+
+	// GetUserTransactions возвращает страницу транзакций пользователя,
+	// подходящих под filter, от новых к старым.
 	GetUserTransactions(
 		ctx context.Context,
 		telegramUserID int64,
+		filter *TransactionFilter,
 		pagination *shared.PageRequest,
 	) ([]*Transaction, error)
-	GetUserTransactionsCount(ctx context.Context, telegramUserID int64) (int64, error)
+	GetUserTransactionsCount(ctx context.Context, telegramUserID int64, filter *TransactionFilter) (int64, error)
+	// GetUserTransactionTotals возвращает итоги подходящих под filter
+	// транзакций по причинам и валютам.
+	GetUserTransactionTotals(
+		ctx context.Context,
+		telegramUserID int64,
+		filter *TransactionFilter,
+	) ([]*TransactionTotal, error)
+
+	// GetUnbalancedEntries возвращает до limit записей журнала с ненулевой
+	// суммой проводок или меньше чем двумя проводками.
+	GetUnbalancedEntries(ctx context.Context, limit int32) ([]*UnbalancedEntry, error)
+	// GetBalanceMismatches возвращает до limit кэшированных балансов,
+	// расходящихся с суммой проводок по счёту пользователя.
+	GetBalanceMismatches(ctx context.Context, limit int32) ([]*BalanceMismatch, error)
+	GetSystemBalances(ctx context.Context) ([]*SystemBalance, error)
 }
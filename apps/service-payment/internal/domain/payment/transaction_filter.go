@@ -0,0 +1,40 @@
+package payment
+
+import (
+	"time"
+
+	"github.com/peterparker2005/giftduels/packages/tonamount-go"
+	"github.com/shopspring/decimal"
+)
+
+// TransactionFilter — условия выборки истории транзакций. Пустые поля
+// выборку не ограничивают.
+type TransactionFilter struct {
+	Reasons []TransactionReason
+	// From и To — полуинтервал [From, To) по времени создания.
+	From *time.Time
+	To   *time.Time
+	// MinAmount и MaxAmount ограничивают сумму транзакции по модулю
+	// включительно.
+	MinAmount *tonamount.TonAmount
+	MaxAmount *tonamount.TonAmount
+	// Query — подстрока названия или slug подарка либо его точный ID.
+	Query string
+}
+
+// TransactionTotal — итог транзакций одной причины в одной валюте.
+type TransactionTotal struct {
+	Reason   TransactionReason
+	Currency Currency
+	// Amount — сумма со знаком: зачисления положительны, списания отрицательны.
+	Amount decimal.Decimal
+	Count  int64
+}
+
+// ExportFormat — формат выгрузки истории транзакций.
+type ExportFormat string
+
+const (
+	ExportFormatCSV  ExportFormat = "csv"
+	ExportFormatJSON ExportFormat = "json"
+)
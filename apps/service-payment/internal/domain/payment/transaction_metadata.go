@@ -1,21 +1,65 @@
 package payment
 
+import "github.com/peterparker2005/giftduels/packages/tonamount-go"
+
 type TransactionMetadata struct {
-	Gift *TransactionMetadataGiftDetails `json:"gift,omitempty"`
+	Gift          *TransactionMetadataGiftDetails          `json:"gift,omitempty"`
+	TonWithdrawal *TransactionMetadataTonWithdrawalDetails `json:"ton_withdrawal,omitempty"`
+	Deposit       *TransactionMetadataDepositDetails       `json:"deposit,omitempty"`
 }
 
 type TransactionMetadataGiftDetails struct {
 	GiftID string `json:"gift_id"`
 	Title  string `json:"title"`
 	Slug   string `json:"slug"`
+	// WithdrawalCommission — комиссия за вывод подарка или её возврат.
+	WithdrawalCommission bool `json:"withdrawal_commission,omitempty"`
+}
+
+type TransactionMetadataTonWithdrawalDetails struct {
+	WithdrawalID string `json:"withdrawal_id"`
+	Destination  string `json:"destination"`
+}
+
+// TransactionMetadataDepositDetails — исходный депозит. Amount — сумма
+// в валюте депозита, Rate — курс, если сумма конвертирована в валюту баланса.
+type TransactionMetadataDepositDetails struct {
+	DepositID string   `json:"deposit_id"`
+	Currency  Currency `json:"currency"`
+	Amount    string   `json:"amount"`
+	Rate      string   `json:"rate,omitempty"`
 }
 
 func NewGiftWithdrawalCommissionMetadata(giftID, title, slug string) *TransactionMetadata {
 	return &TransactionMetadata{
 		Gift: &TransactionMetadataGiftDetails{
-			GiftID: giftID,
-			Title:  title,
-			Slug:   slug,
+			GiftID:               giftID,
+			Title:                title,
+			Slug:                 slug,
+			WithdrawalCommission: true,
+		},
+	}
+}
+
+func NewTonWithdrawalMetadata(withdrawalID, destination string) *TransactionMetadata {
+	return &TransactionMetadata{
+		TonWithdrawal: &TransactionMetadataTonWithdrawalDetails{
+			WithdrawalID: withdrawalID,
+			Destination:  destination,
+		},
+	}
+}
+
+func NewDepositMetadata(
+	depositID string,
+	currency Currency,
+	amount *tonamount.TonAmount,
+) *TransactionMetadata {
+	return &TransactionMetadata{
+		Deposit: &TransactionMetadataDepositDetails{
+			DepositID: depositID,
+			Currency:  currency,
+			Amount:    amount.String(),
 		},
 	}
 }
@@ -2,6 +2,7 @@ package ton
 
 import (
 	"context"
+	"time"
 
 	"github.com/peterparker2005/giftduels/packages/tonamount-go"
 )
@@ -11,23 +12,101 @@ type MasterchainInfo struct {
 	SeqNo uint32
 }
 
-// Transaction — доменная модель прихода средств.
+// Transaction — доменная модель прихода средств. Для джеттона Sender —
+// владелец исходного джеттон-кошелька, а не сам кошелёк.
 type Transaction struct {
 	Sender   string
 	Amount   *tonamount.TonAmount // строковое представление с нужными десятичными знаками
+	Units    uint64               // сумма в минимальных единицах валюты (нано для TON)
 	Currency string               // "TON" или код джеттона
 	Payload  string               // payload/comment from transaction body
+	Hash     string               // hex-хеш транзакции
 	LastLT   uint64               // для сохранения курсора
 }
 
+// Jetton — джеттон, принимаемый на казначейский кошелёк.
+type Jetton struct {
+	Currency      string // код валюты, например "USDT"
+	MasterAddress string
+	Decimals      int32
+}
+
+// Transfer — исходящий перевод из казначейского кошелька.
+type Transfer struct {
+	Destination string
+	AmountNano  uint64
+	Comment     string
+}
+
+// SignedTransfers — подписанное внешнее сообщение кошелька с пачкой переводов.
+// MsgHash известен до отправки, поэтому его можно сохранить заранее.
+// Кошелёк исполнит сообщение, только пока его seqno равен WalletSeqno
+// и не наступил ValidUntil.
+type SignedTransfers struct {
+	MsgHash     string
+	WalletSeqno uint32
+	ValidUntil  time.Time
+	BOC         []byte
+}
+
+type TransferState string
+
+const (
+	// TransferStateUnknown — транзакция кошелька с внешним сообщением не найдена.
+	TransferStateUnknown TransferState = "unknown"
+	// TransferStateInFlight — кошелёк отправил перевод, получатель его ещё не обработал.
+	TransferStateInFlight TransferState = "in_flight"
+	// TransferStateDelivered — получатель принял перевод.
+	TransferStateDelivered TransferState = "delivered"
+	// TransferStateBounced — получатель не принял перевод, средства вернулись.
+	TransferStateBounced TransferState = "bounced"
+	// TransferStateFailed — кошелёк исполнил сообщение, но перевод не отправил.
+	TransferStateFailed TransferState = "failed"
+)
+
+// TransferStatus — состояние перевода в сети. TxHash и TxLT заполнены
+// для транзакции получателя.
+type TransferStatus struct {
+	State  TransferState
+	TxHash string
+	TxLT   uint64
+}
+
 // API — абстракция над tonutils-go.
 type API interface {
 	CurrentMasterchainInfo(ctx context.Context) (MasterchainInfo, error)
 	GetAccountLastLT(ctx context.Context, addr string) (uint64, error)
+	// JettonWalletAddress возвращает адрес джеттон-кошелька owner.
+	JettonWalletAddress(ctx context.Context, jetton Jetton, owner string) (string, error)
+	// SubscribeTransactions отдаёт входящие переводы TON на addr, а также
+	// уведомления о переводах джеттонов от его джеттон-кошельков
+	// jettonWallets (адрес кошелька → джеттон).
 	SubscribeTransactions(
 		ctx context.Context,
 		addr string,
 		fromLT uint64,
+		jettonWallets map[string]Jetton,
 		out chan<- Transaction,
 	) error
+	// GetIncomingTransactions возвращает входящие переводы TON на addr
+	// новее afterLT (от старых к новым) и lt, с которого продолжать: lt
+	// последней транзакции аккаунта или, если за проход прочитано не всё,
+	// последней прочитанной.
+	GetIncomingTransactions(ctx context.Context, addr string, afterLT uint64) ([]Transaction, uint64, error)
+	// GetAccountBalance возвращает баланс аккаунта в нанотонах.
+	GetAccountBalance(ctx context.Context, addr string) (uint64, error)
+	// SweepDepositAddress отправляет весь баланс подкошелька subwalletID
+	// казначейского сида на адрес to.
+	SweepDepositAddress(ctx context.Context, subwalletID uint32, to, comment string) error
+
+	// SignTransfers подписывает переводы одним внешним сообщением
+	// казначейского кошелька, не отправляя его.
+	SignTransfers(ctx context.Context, transfers []Transfer) (SignedTransfers, error)
+	// SendTransfers отправляет подписанное сообщение в сеть.
+	SendTransfers(ctx context.Context, signed SignedTransfers) error
+	// GetWalletSeqno возвращает текущий seqno казначейского кошелька.
+	// Seqno больше, чем у сообщения, значит сообщение исполнено.
+	GetWalletSeqno(ctx context.Context) (uint32, error)
+	// GetTransferStatus ищет в сети перевод из сообщения msgHash.
+	GetTransferStatus(ctx context.Context, msgHash string, transfer Transfer) (TransferStatus, error)
 }
@@ -4,32 +4,69 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/payment"
 )
 
 type DepositStatus string
 
+// Жизненный цикл: pending → received → confirmed (или partial при недоплате).
+// Баланс пополняется только при подтверждении; pending без оплаты
+// становится expired, но поздний платёж всё равно принимается.
 const (
 	DepositStatusPending   DepositStatus = "pending"
 	DepositStatusReceived  DepositStatus = "received"
 	DepositStatusConfirmed DepositStatus = "confirmed"
 	DepositStatusExpired   DepositStatus = "expired"
+	// DepositStatusPartial — подтверждён, но пришло меньше запрошенного;
+	// зачислено фактически полученное.
+	DepositStatusPartial DepositStatus = "partial"
 )
 
+// Deposit — запрос на пополнение. AmountNano — запрошенная сумма,
+// ReceivedAmountNano — фактически пришедшая и зачисляемая на баланс.
+// Обе суммы — в минимальных единицах Currency (нано для TON).
 type Deposit struct {
-	ID             uuid.UUID
-	TelegramUserID int64
-	Status         DepositStatus
-	AmountNano     uint64
-	Payload        string
-	ExpiresAt      time.Time
-	TxHash         *string
-	TxLt           *uint64
+	ID                 uuid.UUID
+	TelegramUserID     int64
+	Status             DepositStatus
+	Currency           payment.Currency
+	AmountNano         uint64
+	Payload            string
+	ExpiresAt          time.Time
+	TxHash             *string
+	TxLt               *uint64
+	ReceivedAmountNano *uint64
+	SenderAddress      *string
+	// ReceivedMcSeqno — seqno мастерчейна, на котором платёж был замечен.
+	ReceivedMcSeqno *uint32
+	ReceivedAt      *time.Time
+	ConfirmedAt     *time.Time
+	ExpiredAt       *time.Time
+	// DepositAddress — персональный адрес, на который пришёл платёж;
+	// пуст для депозитов с комментарием на казначейский кошелёк.
+	DepositAddress *string
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
 }
 
+// CanReceive сообщает, можно ли принять платёж по депозиту.
+// Платёж после истечения срока тоже принимается: средства уже пришли
+// на казначейский кошелёк.
+func (d *Deposit) CanReceive() bool {
+	return d.Status == DepositStatusPending || d.Status == DepositStatusExpired
+}
+
+// ConfirmedStatus — итоговый статус депозита после подтверждения.
+func (d *Deposit) ConfirmedStatus() DepositStatus {
+	if d.ReceivedAmountNano == nil || *d.ReceivedAmountNano < d.AmountNano {
+		return DepositStatusPartial
+	}
+	return DepositStatusConfirmed
+}
+
 type CreateDepositParams struct {
 	TelegramUserID int64
+	Currency       payment.Currency
 	AmountNano     uint64
 	Payload        string
 	ExpiresAt      time.Time
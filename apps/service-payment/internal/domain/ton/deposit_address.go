@@ -0,0 +1,96 @@
+package ton
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/payment"
+	"github.com/xssnick/tonutils-go/address"
+)
+
+// DerivedDepositPayloadPrefix — префикс payload депозитов, пришедших на
+// персональный адрес: у таких платежей нет комментария, а payload должен
+// быть уникальным, поэтому в него кладётся хеш транзакции.
+const DerivedDepositPayloadPrefix = "derived:"
+
+// DepositAddress — персональный адрес пополнения пользователя: подкошелёк
+// казначейского сида с номером SubwalletID. Всё, что на него приходит,
+// зачисляется пользователю без комментария, затем сметается в казначейство.
+type DepositAddress struct {
+	ID             uuid.UUID
+	TelegramUserID int64
+	SubwalletID    uint32
+	Address        string
+	RawAddress     string
+	// LastLT — lt последней обработанной входящей транзакции.
+	LastLT       uint64
+	SweepPending bool
+	LastSweptAt  *time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+type CreateDepositAddressParams struct {
+	TelegramUserID int64
+	SubwalletID    uint32
+	Address        string
+	RawAddress     string
+}
+
+// AddressDeriver вычисляет адрес подкошелька казначейского сида без
+// обращения к сети.
+type AddressDeriver interface {
+	// DeriveAddress возвращает user-friendly (non-bounceable) и raw-адрес
+	// подкошелька subwalletID.
+	DeriveAddress(subwalletID uint32) (friendly, raw string, err error)
+}
+
+type DepositAddressRepository interface {
+	WithTx(tx pgx.Tx) DepositAddressRepository
+	// NextSubwalletID выдаёт порядковый номер для нового адреса.
+	NextSubwalletID(ctx context.Context) (uint64, error)
+	// CreateDepositAddress сохраняет адрес пользователя. Если адрес уже
+	// создан параллельным запросом, возвращает ErrDepositAddressExists.
+	CreateDepositAddress(ctx context.Context, params *CreateDepositAddressParams) (*DepositAddress, error)
+	GetDepositAddressByUser(ctx context.Context, telegramUserID int64) (*DepositAddress, error)
+	GetDepositAddressByRawAddress(ctx context.Context, rawAddress string) (*DepositAddress, error)
+	ListDepositAddresses(ctx context.Context, limit, offset int32) ([]*DepositAddress, error)
+	// UpdateCursor сдвигает курсор адреса; sweepPending=true помечает адрес
+	// к сметанию (уже выставленный флаг не сбрасывается).
+	UpdateCursor(ctx context.Context, id string, lastLT uint64, sweepPending bool) error
+	GetDepositAddressesToSweep(ctx context.Context, limit int32) ([]*DepositAddress, error)
+	MarkSwept(ctx context.Context, id string) error
+}
+
+// CreateReceivedDepositParams — платёж на персональный адрес. Депозит
+// создаётся сразу в received и подтверждается общим порядком.
+type CreateReceivedDepositParams struct {
+	TelegramUserID  int64
+	Currency        payment.Currency
+	AmountNano      uint64
+	TxHash          string
+	TxLt            uint64
+	SenderAddress   string
+	ReceivedMcSeqno uint32
+	DepositAddress  string
+}
+
+// Payload — уникальный payload депозита по хешу транзакции.
+func (p *CreateReceivedDepositParams) Payload() string {
+	return DerivedDepositPayloadPrefix + p.TxHash
+}
+
+// RawAddress приводит адрес к raw-форме workchain:hex, в которой адреса
+// можно сравнивать независимо от флагов bounceable/testnet.
+func RawAddress(addr string) (string, error) {
+	parsed, err := address.ParseAddr(addr)
+	if err != nil {
+		parsed, err = address.ParseRawAddr(addr)
+		if err != nil {
+			return "", ErrInvalidAddress
+		}
+	}
+	return parsed.StringRaw(), nil
+}
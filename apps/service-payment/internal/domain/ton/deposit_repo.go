@@ -2,25 +2,63 @@ package ton
 
 import (
 	"context"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 )
 
 type SetDepositTransactionParams struct {
-	ID     string
-	TxHash string
-	TxLt   uint64
+	ID                 string
+	TxHash             string
+	TxLt               uint64
+	ReceivedAmountNano uint64
+	SenderAddress      string
+	ReceivedMcSeqno    uint32
 }
 
 // DepositRepository keeps and returns last_lt for given address and network.
 type DepositRepository interface {
 	WithTx(tx pgx.Tx) DepositRepository
 	CreateDeposit(ctx context.Context, params *CreateDepositParams) (*Deposit, error)
+	// GetDepositByPayload возвращает ErrDepositNotFound, если депозита нет.
 	GetDepositByPayload(ctx context.Context, payload string) (*Deposit, error)
+	// CreateReceivedDeposit сохраняет платёж на персональный адрес.
+	// Повторный вызов для той же транзакции возвращает ErrDepositAlreadyProcessed.
+	CreateReceivedDeposit(ctx context.Context, params *CreateReceivedDepositParams) (*Deposit, error)
+	// SetDepositTransaction записывает полученный платёж и переводит депозит
+	// в received. Возвращает ErrDepositAlreadyProcessed, если платёж по
+	// депозиту уже принят.
 	SetDepositTransaction(
 		ctx context.Context,
 		params *SetDepositTransactionParams,
 	) (*Deposit, error)
+	// ExpireDeposits помечает истёкшими до limit ожидающих депозитов
+	// со сроком раньше now и возвращает их число.
+	ExpireDeposits(ctx context.Context, now time.Time, limit int32) (int64, error)
+	// GetDepositsToConfirm возвращает принятые депозиты, замеченные
+	// не позже мастерблока maxMcSeqno.
+	GetDepositsToConfirm(ctx context.Context, maxMcSeqno uint32, limit int32) ([]*Deposit, error)
+	// ConfirmDeposit переводит депозит из received в status. Возвращает
+	// ErrDepositAlreadyProcessed, если депозит уже не в received.
+	ConfirmDeposit(ctx context.Context, id string, status DepositStatus) (*Deposit, error)
+	// CreateUnattributedDeposit сохраняет несопоставленный платёж.
+	// Повторная запись той же транзакции игнорируется, created=false.
+	CreateUnattributedDeposit(
+		ctx context.Context,
+		params *CreateUnattributedDepositParams,
+	) (created bool, err error)
+	ListUnattributedDeposits(
+		ctx context.Context,
+		status UnattributedDepositStatus,
+		limit, offset int32,
+	) ([]*UnattributedDeposit, error)
+	// AssignUnattributedDeposit назначает платёж пользователю. Возвращает
+	// ErrUnattributedDepositNotFound, если платежа нет или он уже назначен.
+	AssignUnattributedDeposit(
+		ctx context.Context,
+		id string,
+		telegramUserID int64,
+	) (*UnattributedDeposit, error)
 	// Get returns saved lastLT for walletAddress and network.
 	// If no record exists, returns 0 and nil-error.
 	GetCursor(ctx context.Context, network, walletAddress string) (uint64, error)
@@ -0,0 +1,47 @@
+package ton
+
+import "errors"
+
+var (
+	ErrInvalidAddress = errors.New("invalid ton address")
+	// ErrWithdrawalNotFound — вывода нет или он уже завершён.
+	ErrWithdrawalNotFound = errors.New("withdrawal not found")
+	// ErrDepositAlreadyProcessed — платёж по депозиту уже принят или подтверждён.
+	ErrDepositAlreadyProcessed = errors.New("deposit already processed")
+	// ErrDepositNotFound — депозита с таким payload нет.
+	ErrDepositNotFound = errors.New("deposit not found")
+	// ErrDepositAddressNotFound — персональный адрес не найден.
+	ErrDepositAddressNotFound = errors.New("deposit address not found")
+	// ErrDepositAddressExists — адрес пользователя уже создан.
+	ErrDepositAddressExists = errors.New("deposit address already exists")
+	// ErrUnattributedDepositNotFound — платежа нет или он уже назначен.
+	ErrUnattributedDepositNotFound = errors.New("unattributed deposit not found")
+)
+
+func IsInvalidAddress(err error) bool {
+	return errors.Is(err, ErrInvalidAddress)
+}
+
+func IsWithdrawalNotFound(err error) bool {
+	return errors.Is(err, ErrWithdrawalNotFound)
+}
+
+func IsDepositAlreadyProcessed(err error) bool {
+	return errors.Is(err, ErrDepositAlreadyProcessed)
+}
+
+func IsDepositNotFound(err error) bool {
+	return errors.Is(err, ErrDepositNotFound)
+}
+
+func IsDepositAddressNotFound(err error) bool {
+	return errors.Is(err, ErrDepositAddressNotFound)
+}
+
+func IsDepositAddressExists(err error) bool {
+	return errors.Is(err, ErrDepositAddressExists)
+}
+
+func IsUnattributedDepositNotFound(err error) bool {
+	return errors.Is(err, ErrUnattributedDepositNotFound)
+}
@@ -0,0 +1,45 @@
+package ton
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/payment"
+)
+
+type UnattributedDepositStatus string
+
+const (
+	// UnattributedDepositStatusPending — платёж ждёт ручного разбора.
+	UnattributedDepositStatusPending UnattributedDepositStatus = "pending"
+	// UnattributedDepositStatusAssigned — платёж зачислен пользователю поддержкой.
+	UnattributedDepositStatusAssigned UnattributedDepositStatus = "assigned"
+)
+
+// UnattributedDeposit — платёж на казначейский кошелёк, который не удалось
+// сопоставить с депозитом: без комментария, с нечитаемым комментарием или
+// с неизвестным payload. Средства уже на кошельке, поэтому платёж не
+// теряется, а ждёт, пока поддержка назначит его пользователю.
+type UnattributedDeposit struct {
+	ID             uuid.UUID
+	TxHash         string
+	TxLt           uint64
+	SenderAddress  string
+	Currency       payment.Currency
+	AmountNano     uint64
+	Comment        *string
+	Status         UnattributedDepositStatus
+	TelegramUserID *int64
+	AssignedAt     *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+type CreateUnattributedDepositParams struct {
+	TxHash        string
+	TxLt          uint64
+	SenderAddress string
+	Currency      payment.Currency
+	AmountNano    uint64
+	Comment       string
+}
@@ -0,0 +1,71 @@
+package ton
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xssnick/tonutils-go/address"
+)
+
+type WithdrawalStatus string
+
+const (
+	// WithdrawalStatusPending — баланс списан, перевод ещё не отправлен.
+	WithdrawalStatusPending WithdrawalStatus = "pending"
+	// WithdrawalStatusSent — внешнее сообщение кошелька отправлено в сеть.
+	WithdrawalStatusSent WithdrawalStatus = "sent"
+	// WithdrawalStatusConfirmed — получатель принял перевод.
+	WithdrawalStatusConfirmed WithdrawalStatus = "confirmed"
+	// WithdrawalStatusFailed — перевод не состоялся, баланс возвращён.
+	WithdrawalStatusFailed WithdrawalStatus = "failed"
+)
+
+type Withdrawal struct {
+	ID             uuid.UUID
+	TelegramUserID int64
+	Destination    string
+	AmountNano     uint64
+	Status         WithdrawalStatus
+	MsgHash        *string
+	WalletSeqno    *uint32
+	ValidUntil     *time.Time
+	TxHash         *string
+	TxLt           *uint64
+	FailureReason  *string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// Comment — комментарий перевода. Уникален для каждого вывода, по нему
+// транзакция находится у получателя.
+func (w *Withdrawal) Comment() string {
+	return "giftduels withdrawal " + w.ID.String()
+}
+
+// Transfer — перевод, который нужно отправить из казначейского кошелька.
+func (w *Withdrawal) Transfer() Transfer {
+	return Transfer{
+		Destination: w.Destination,
+		AmountNano:  w.AmountNano,
+		Comment:     w.Comment(),
+	}
+}
+
+type CreateWithdrawalParams struct {
+	TelegramUserID int64
+	Destination    string
+	AmountNano     uint64
+}
+
+// ParseDestination проверяет адрес получателя и возвращает его в
+// user-friendly формате. Testnet-адреса в mainnet не принимаются.
+func ParseDestination(raw string, testnet bool) (string, error) {
+	addr, err := address.ParseAddr(raw)
+	if err != nil {
+		return "", ErrInvalidAddress
+	}
+	if addr.IsTestnetOnly() && !testnet {
+		return "", ErrInvalidAddress
+	}
+	return addr.String(), nil
+}
@@ -0,0 +1,39 @@
+package ton
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type MarkWithdrawalsSentParams struct {
+	IDs         []string
+	MsgHash     string
+	WalletSeqno uint32
+	ValidUntil  time.Time
+}
+
+type ConfirmWithdrawalParams struct {
+	ID     string
+	TxHash string
+	TxLt   uint64
+}
+
+type WithdrawalRepository interface {
+	WithTx(tx pgx.Tx) WithdrawalRepository
+	CreateWithdrawal(ctx context.Context, params *CreateWithdrawalParams) (*Withdrawal, error)
+	GetWithdrawalsByStatus(
+		ctx context.Context,
+		status WithdrawalStatus,
+		limit int32,
+	) ([]*Withdrawal, error)
+	// MarkWithdrawalsSent переводит выводы из pending в sent.
+	MarkWithdrawalsSent(ctx context.Context, params *MarkWithdrawalsSentParams) error
+	// ConfirmWithdrawal переводит вывод из sent в confirmed.
+	// Возвращает ErrWithdrawalNotFound, если вывод уже не в sent.
+	ConfirmWithdrawal(ctx context.Context, params *ConfirmWithdrawalParams) error
+	// FailWithdrawal помечает незавершённый вывод как failed.
+	// Возвращает ErrWithdrawalNotFound, если вывод уже завершён.
+	FailWithdrawal(ctx context.Context, id, reason string) (*Withdrawal, error)
+}
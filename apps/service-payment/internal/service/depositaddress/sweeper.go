@@ -0,0 +1,126 @@
+package depositaddress
+
+import (
+	"context"
+	"time"
+
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/config"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/payment"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/ton"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+	"github.com/peterparker2005/giftduels/packages/tonamount-go"
+	"go.uber.org/zap"
+)
+
+// sweepComment — комментарий сметания; по отправителю казначейский воркер
+// отличает такие переводы от пополнений.
+const sweepComment = "giftduels sweep"
+
+// Sweeper периодически переводит средства с персональных адресов, на
+// которые приходили платежи, на казначейский кошелёк.
+type Sweeper struct {
+	api       ton.API
+	repo      ton.DepositAddressRepository
+	treasury  string
+	minAmount uint64
+	interval  time.Duration
+	cancel    context.CancelFunc
+	logger    *logger.Logger
+}
+
+func NewSweeper(
+	api ton.API,
+	repo ton.DepositAddressRepository,
+	cfg *config.Config,
+	logger *logger.Logger,
+) (*Sweeper, error) {
+	minAmount, err := tonamount.NewTonAmountFromString(cfg.Ton.Deposit.Addresses.SweepMinAmount)
+	if err != nil {
+		return nil, err
+	}
+	minNano, err := payment.CurrencyTON.UnitsFromAmount(minAmount)
+	if err != nil {
+		return nil, err
+	}
+	return &Sweeper{
+		api:       api,
+		repo:      repo,
+		treasury:  cfg.Ton.WalletAddress,
+		minAmount: minNano,
+		interval:  cfg.Ton.Deposit.Addresses.SweepInterval,
+		logger:    logger,
+	}, nil
+}
+
+func (s *Sweeper) Start() {
+	ctx := context.Background()
+	ctx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+	go func() {
+		s.run(ctx)
+	}()
+}
+
+func (s *Sweeper) Stop(_ context.Context) error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	return nil
+}
+
+func (s *Sweeper) run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("🛑 deposit address sweeper stopping")
+			return
+		case <-ticker.C:
+			s.sweepAll(ctx)
+		}
+	}
+}
+
+// sweepAll сметает адреса, помеченные наблюдателем. За проход
+// обрабатывается одна пачка: отправка сметания требует обращений к сети.
+func (s *Sweeper) sweepAll(ctx context.Context) {
+	addrs, err := s.repo.GetDepositAddressesToSweep(ctx, pageSize)
+	if err != nil {
+		s.logger.Error("failed to get deposit addresses to sweep", zap.Error(err))
+		return
+	}
+	for _, addr := range addrs {
+		if ctx.Err() != nil {
+			return
+		}
+		if err = s.sweep(ctx, addr); err != nil {
+			s.logger.Warn("failed to sweep deposit address",
+				zap.String("address", addr.Address),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// sweep переводит баланс адреса в казначейство. Мелкие остатки не
+// сметаются: комиссия съела бы заметную их часть. Адрес остаётся в
+// очереди и будет сметён, когда баланс дорастёт.
+func (s *Sweeper) sweep(ctx context.Context, addr *ton.DepositAddress) error {
+	balance, err := s.api.GetAccountBalance(ctx, addr.Address)
+	if err != nil {
+		return err
+	}
+	if balance < s.minAmount {
+		return nil
+	}
+	if err = s.api.SweepDepositAddress(ctx, addr.SubwalletID, s.treasury, sweepComment); err != nil {
+		return err
+	}
+	s.logger.Info("🧹 deposit address swept",
+		zap.String("address", addr.Address),
+		zap.Uint64("amount", balance),
+	)
+	return s.repo.MarkSwept(ctx, addr.ID.String())
+}
@@ -0,0 +1,124 @@
+package depositaddress
+
+import (
+	"context"
+	"time"
+
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/config"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/ton"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/service/payment"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+	"go.uber.org/zap"
+)
+
+// pageSize — сколько адресов читается из БД за один запрос.
+const pageSize = 100
+
+// Watcher периодически проверяет персональные адреса пополнения и
+// принимает пришедшие на них платежи. Подтверждение и зачисление делает
+// общий подтвердитель депозитов.
+type Watcher struct {
+	api            ton.API
+	repo           ton.DepositAddressRepository
+	paymentService *payment.Service
+	interval       time.Duration
+	cancel         context.CancelFunc
+	logger         *logger.Logger
+}
+
+func NewWatcher(
+	api ton.API,
+	repo ton.DepositAddressRepository,
+	paymentService *payment.Service,
+	cfg *config.Config,
+	logger *logger.Logger,
+) *Watcher {
+	return &Watcher{
+		api:            api,
+		repo:           repo,
+		paymentService: paymentService,
+		interval:       cfg.Ton.Deposit.Addresses.PollInterval,
+		logger:         logger,
+	}
+}
+
+func (w *Watcher) Start() {
+	ctx := context.Background()
+	ctx, cancel := context.WithCancel(ctx)
+	w.cancel = cancel
+	go func() {
+		w.run(ctx)
+	}()
+}
+
+func (w *Watcher) Stop(_ context.Context) error {
+	if w.cancel != nil {
+		w.cancel()
+	}
+	return nil
+}
+
+func (w *Watcher) run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("🛑 deposit address watcher stopping")
+			return
+		case <-ticker.C:
+			w.scan(ctx)
+		}
+	}
+}
+
+// scan проходит по всем адресам постранично.
+func (w *Watcher) scan(ctx context.Context) {
+	for offset := int32(0); ctx.Err() == nil; offset += pageSize {
+		addrs, err := w.repo.ListDepositAddresses(ctx, pageSize, offset)
+		if err != nil {
+			w.logger.Error("failed to list deposit addresses", zap.Error(err))
+			return
+		}
+		for _, addr := range addrs {
+			if err = w.check(ctx, addr); err != nil {
+				w.logger.Warn("failed to check deposit address",
+					zap.String("address", addr.Address),
+					zap.Error(err),
+				)
+			}
+		}
+		if len(addrs) < pageSize {
+			return
+		}
+	}
+}
+
+// check принимает новые платежи на адрес и сдвигает его курсор. Курсор
+// сдвигается, только если все платежи сохранены, иначе адрес будет
+// перепроверен целиком на следующем проходе.
+func (w *Watcher) check(ctx context.Context, addr *ton.DepositAddress) error {
+	txs, lastLT, err := w.api.GetIncomingTransactions(ctx, addr.Address, addr.LastLT)
+	if err != nil {
+		return err
+	}
+	if lastLT == addr.LastLT {
+		return nil
+	}
+
+	if len(txs) > 0 {
+		master, masterErr := w.api.CurrentMasterchainInfo(ctx)
+		if masterErr != nil {
+			return masterErr
+		}
+		for _, tx := range txs {
+			if err = w.paymentService.ProcessDerivedDeposit(ctx, addr, tx, master.SeqNo); err != nil {
+				return err
+			}
+		}
+	}
+
+	id := addr.ID.String()
+	return w.repo.UpdateCursor(ctx, id, lastLT, len(txs) > 0)
+}
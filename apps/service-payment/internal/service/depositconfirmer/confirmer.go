@@ -0,0 +1,99 @@
+package depositconfirmer
+
+import (
+	"context"
+	"time"
+
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/config"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/ton"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/service/payment"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+	"go.uber.org/zap"
+)
+
+// batchSize — сколько депозитов подтверждается за один проход.
+const batchSize = 100
+
+// Confirmer периодически подтверждает принятые депозиты, когда поверх
+// мастерблока платежа набирается нужное число мастерблоков, и только
+// тогда пополняет баланс.
+type Confirmer struct {
+	api            ton.API
+	paymentService *payment.Service
+	confirmations  uint32
+	interval       time.Duration
+	cancel         context.CancelFunc
+	logger         *logger.Logger
+}
+
+func NewConfirmer(
+	api ton.API,
+	paymentService *payment.Service,
+	cfg *config.Config,
+	logger *logger.Logger,
+) *Confirmer {
+	return &Confirmer{
+		api:            api,
+		paymentService: paymentService,
+		confirmations:  cfg.Ton.Deposit.Confirmations,
+		interval:       cfg.Ton.Deposit.ConfirmInterval,
+		logger:         logger,
+	}
+}
+
+func (c *Confirmer) Start() {
+	ctx := context.Background()
+	ctx, cancel := context.WithCancel(ctx)
+	c.cancel = cancel
+	go func() {
+		c.run(ctx)
+	}()
+}
+
+func (c *Confirmer) Stop(_ context.Context) error {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	return nil
+}
+
+func (c *Confirmer) run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			c.logger.Info("🛑 deposit confirmer stopping")
+			return
+		case <-ticker.C:
+			c.confirm(ctx)
+		}
+	}
+}
+
+// confirm подтверждает депозиты пачками, пока они не закончатся.
+func (c *Confirmer) confirm(ctx context.Context) {
+	master, err := c.api.CurrentMasterchainInfo(ctx)
+	if err != nil {
+		c.logger.Warn("failed to get masterchain info", zap.Error(err))
+		return
+	}
+
+	for ctx.Err() == nil {
+		confirmed, err := c.paymentService.ConfirmDeposits(ctx, master.SeqNo, c.confirmations, batchSize)
+		if err != nil {
+			c.logger.Error("failed to confirm deposits", zap.Error(err))
+			return
+		}
+		if confirmed > 0 {
+			c.logger.Info("✅ deposits confirmed",
+				zap.Int("count", confirmed),
+				zap.Uint32("mc_seqno", master.SeqNo),
+			)
+		}
+		if confirmed < batchSize {
+			return
+		}
+	}
+}
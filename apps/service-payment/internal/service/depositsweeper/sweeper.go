@@ -0,0 +1,83 @@
+package depositsweeper
+
+import (
+	"context"
+	"time"
+
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/config"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/service/payment"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+	"go.uber.org/zap"
+)
+
+// batchSize — сколько депозитов помечается истёкшими за один запрос.
+const batchSize = 500
+
+// Sweeper периодически помечает истёкшими депозиты, которые так и не
+// были оплачены. Платёж, пришедший позже, всё равно будет зачислен.
+type Sweeper struct {
+	paymentService *payment.Service
+	interval       time.Duration
+	cancel         context.CancelFunc
+	logger         *logger.Logger
+}
+
+func NewSweeper(
+	paymentService *payment.Service,
+	cfg *config.Config,
+	logger *logger.Logger,
+) *Sweeper {
+	return &Sweeper{
+		paymentService: paymentService,
+		interval:       cfg.Ton.Deposit.SweepInterval,
+		logger:         logger,
+	}
+}
+
+func (s *Sweeper) Start() {
+	ctx := context.Background()
+	ctx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+	go func() {
+		s.run(ctx)
+	}()
+}
+
+func (s *Sweeper) Stop(_ context.Context) error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	return nil
+}
+
+func (s *Sweeper) run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("🛑 deposit sweeper stopping")
+			return
+		case <-ticker.C:
+			s.sweep(ctx)
+		}
+	}
+}
+
+// sweep помечает просроченные депозиты пачками, пока они не закончатся.
+func (s *Sweeper) sweep(ctx context.Context) {
+	for ctx.Err() == nil {
+		expired, err := s.paymentService.ExpireDeposits(ctx, time.Now(), batchSize)
+		if err != nil {
+			s.logger.Error("failed to expire deposits", zap.Error(err))
+			return
+		}
+		if expired > 0 {
+			s.logger.Info("expired stale deposits", zap.Int64("count", expired))
+		}
+		if expired < batchSize {
+			return
+		}
+	}
+}
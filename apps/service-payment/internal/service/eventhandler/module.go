@@ -6,8 +6,10 @@ import (
 
 	"github.com/ThreeDotsLabs/watermill-amqp/v3/pkg/amqp"
 	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/jackc/pgx/v5/pgxpool"
 	amqputil "github.com/peterparker2005/giftduels/apps/service-payment/internal/adapter/amqp"
 	"github.com/peterparker2005/giftduels/apps/service-payment/internal/config"
+	"github.com/peterparker2005/giftduels/packages/events"
 	paymentEvents "github.com/peterparker2005/giftduels/packages/events/payment"
 	telegramEvents "github.com/peterparker2005/giftduels/packages/events/telegram"
 	"github.com/peterparker2005/giftduels/packages/logger-go"
@@ -16,6 +18,10 @@ import (
 	"go.uber.org/zap"
 )
 
+// HandlerTelegramGiftWithdrawFail — имя обработчика в router, попадает в
+// заголовки poison-сообщений и используется командой dlq.
+const HandlerTelegramGiftWithdrawFail = "telegram_gift_withdraw_fail"
+
 //nolint:gochecknoglobals // fx module pattern
 var Module = fx.Options(
 	fx.Provide(
@@ -35,32 +41,40 @@ var Module = fx.Options(
 		log *logger.Logger,
 		subFac amqputil.SubFactory,
 		pub message.Publisher,
+		pool *pgxpool.Pool,
 		telegramGiftWithdrawFailHandler *TelegramGiftWithdrawFailedHandler,
 	) error {
+		paymentCfg := paymentEvents.Config(cfg.ServiceName.String())
 		router, err := ProvideRouter(
 			log,
 			pub,
-			paymentEvents.Config(cfg.ServiceName.String()).Exchange+".poison",
+			pool,
+			paymentCfg.PoisonTopic(),
 		)
 		if err != nil {
 			return err
 		}
 
+		// poison-очередь объявляем заранее, иначе exchange отбросит сообщения
+		paymentSub, err := subFac(paymentCfg)
+		if err != nil {
+			return err
+		}
+		if err = events.DeclarePoisonQueue(paymentSub, paymentCfg); err != nil {
+			return err
+		}
+
 		telegramSub, err := subFac(telegramEvents.Config(cfg.ServiceName.String()))
 		if err != nil {
 			return err
 		}
 
 		router.AddNoPublisherHandler(
-			"telegram_gift_withdraw_fail",
+			HandlerTelegramGiftWithdrawFail,
 			telegramEvents.TopicTelegramGiftWithdrawFailed.String(),
 			telegramSub,
 			telegramGiftWithdrawFailHandler.Handle,
 		)
-		// router.AddNoPublisherHandler("tg_gift_poison", giftEvents.Config(cfg.ServiceName).Exchange+".poison", giftSub, func(m *message.Message) error {
-		// 	log.Warn("💀 poison", zap.String("body", string(m.Payload)))
-		// 	return nil
-		// })
 
 		lc.Append(fx.Hook{
 			OnStart: func(_ context.Context) error {
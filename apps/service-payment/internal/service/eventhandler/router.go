@@ -5,13 +5,16 @@ import (
 
 	"github.com/ThreeDotsLabs/watermill/message"
 	"github.com/ThreeDotsLabs/watermill/message/router/middleware"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/peterparker2005/giftduels/packages/inbox"
 	"github.com/peterparker2005/giftduels/packages/logger-go"
 )
 
-// ProvideRouter настраивает retry + poison.
+// ProvideRouter настраивает retry + poison + дедупликацию через inbox.
 func ProvideRouter(
 	log *logger.Logger,
 	pub message.Publisher,
+	pool *pgxpool.Pool,
 	poisonKey string, // payment.events.poison и т.п.
 ) (*message.Router, error) {
 	r, err := message.NewRouter(message.RouterConfig{}, logger.NewWatermill(log))
@@ -32,6 +35,8 @@ func ProvideRouter(
 		middleware.Recoverer,
 		retry.Middleware,
 		poison,
+		// самый внутренний: каждая попытка retry идёт в своей транзакции inbox
+		inbox.New(pool, log).Middleware,
 	)
 
 	return r, nil
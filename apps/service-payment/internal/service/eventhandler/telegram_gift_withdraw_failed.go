@@ -1,8 +1,6 @@
 package eventhandler
 
 import (
-	"context"
-
 	"github.com/ThreeDotsLabs/watermill/message"
 	paymentDomain "github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/payment"
 	"github.com/peterparker2005/giftduels/apps/service-payment/internal/service/payment"
@@ -28,7 +26,7 @@ func NewTelegramGiftWithdrawFailedHandler(
 }
 
 func (h *TelegramGiftWithdrawFailedHandler) Handle(msg *message.Message) error {
-	ctx := context.Background()
+	ctx := msg.Context()
 
 	var event giftv1.GiftWithdrawFailedEvent
 	if err := proto.Unmarshal(msg.Payload, &event); err != nil {
@@ -46,19 +44,17 @@ func (h *TelegramGiftWithdrawFailedHandler) Handle(msg *message.Message) error {
 		zap.String("error_reason", event.GetErrorReason()),
 	)
 
-	metadata := paymentDomain.TransactionMetadata{
-		Gift: &paymentDomain.TransactionMetadataGiftDetails{
-			GiftID: event.GetGiftId().GetValue(),
-			Title:  event.GetTitle(),
-			Slug:   event.GetSlug(),
-		},
-	}
+	metadata := paymentDomain.NewGiftWithdrawalCommissionMetadata(
+		event.GetGiftId().GetValue(),
+		event.GetTitle(),
+		event.GetSlug(),
+	)
 
 	err := h.paymentService.RollbackWithdrawalCommission(
 		ctx,
 		telegramUserID,
 		commissionAmount,
-		metadata,
+		*metadata,
 	)
 	if err != nil {
 		h.log.Error("failed to rollback withdrawal commission",
@@ -0,0 +1,83 @@
+package holdsweeper
+
+import (
+	"context"
+	"time"
+
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/config"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/service/payment"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+	"go.uber.org/zap"
+)
+
+// batchSize — сколько холдов снимается за одну транзакцию.
+const batchSize = 100
+
+// Sweeper периодически снимает холды, срок которых истёк, возвращая
+// зарезервированные суммы в доступный баланс.
+type Sweeper struct {
+	paymentService *payment.Service
+	interval       time.Duration
+	cancel         context.CancelFunc
+	logger         *logger.Logger
+}
+
+func NewSweeper(
+	paymentService *payment.Service,
+	cfg *config.Config,
+	logger *logger.Logger,
+) *Sweeper {
+	return &Sweeper{
+		paymentService: paymentService,
+		interval:       cfg.Holds.SweepInterval,
+		logger:         logger,
+	}
+}
+
+func (s *Sweeper) Start() {
+	ctx := context.Background()
+	ctx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+	go func() {
+		s.run(ctx)
+	}()
+}
+
+func (s *Sweeper) Stop(_ context.Context) error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	return nil
+}
+
+func (s *Sweeper) run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("🛑 hold sweeper stopping")
+			return
+		case <-ticker.C:
+			s.sweep(ctx)
+		}
+	}
+}
+
+// sweep снимает истёкшие холды пачками, пока они не закончатся.
+func (s *Sweeper) sweep(ctx context.Context) {
+	for ctx.Err() == nil {
+		expired, err := s.paymentService.ExpireHolds(ctx, time.Now(), batchSize)
+		if err != nil {
+			s.logger.Error("failed to expire holds", zap.Error(err))
+			return
+		}
+		if expired > 0 {
+			s.logger.Info("released expired holds", zap.Int("count", expired))
+		}
+		if expired < batchSize {
+			return
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ccoveille/go-safecast"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/payment"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/ton"
+	"go.uber.org/zap"
+)
+
+// GetDepositAddress возвращает персональный адрес пополнения пользователя,
+// при первом обращении создавая его. Адрес постоянный: всё, что на него
+// приходит, зачисляется пользователю без комментария.
+func (s *Service) GetDepositAddress(ctx context.Context, telegramUserID int64) (*ton.DepositAddress, error) {
+	if !s.cfg.Ton.Deposit.Addresses.Enabled {
+		return nil, ErrDepositAddressesDisabled
+	}
+
+	addr, err := s.addressRepo.GetDepositAddressByUser(ctx, telegramUserID)
+	if err == nil {
+		return addr, nil
+	}
+	if !ton.IsDepositAddressNotFound(err) {
+		return nil, err
+	}
+
+	seq, err := s.addressRepo.NextSubwalletID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	subwalletID, err := safecast.ToUint32(uint64(s.cfg.Ton.Deposit.Addresses.SubwalletBase) + seq)
+	if err != nil {
+		return nil, fmt.Errorf("subwallet id overflow: %w", err)
+	}
+	friendly, raw, err := s.deriver.DeriveAddress(subwalletID)
+	if err != nil {
+		return nil, err
+	}
+
+	addr, err = s.addressRepo.CreateDepositAddress(ctx, &ton.CreateDepositAddressParams{
+		TelegramUserID: telegramUserID,
+		SubwalletID:    subwalletID,
+		Address:        friendly,
+		RawAddress:     raw,
+	})
+	if ton.IsDepositAddressExists(err) {
+		// адрес создан параллельным запросом
+		return s.addressRepo.GetDepositAddressByUser(ctx, telegramUserID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("deposit address created",
+		zap.Int64("telegram_user_id", telegramUserID),
+		zap.Uint32("subwallet_id", subwalletID),
+		zap.String("address", friendly),
+	)
+	return addr, nil
+}
+
+// ProcessDerivedDeposit принимает платёж на персональный адрес: создаёт
+// депозит сразу в статусе received. Зачисление — в ConfirmDeposits,
+// как и для депозитов с комментарием.
+func (s *Service) ProcessDerivedDeposit(
+	ctx context.Context,
+	addr *ton.DepositAddress,
+	tx ton.Transaction,
+	mcSeqNo uint32,
+) error {
+	deposit, err := s.tonRepo.CreateReceivedDeposit(ctx, &ton.CreateReceivedDepositParams{
+		TelegramUserID:  addr.TelegramUserID,
+		Currency:        payment.Currency(tx.Currency),
+		AmountNano:      tx.Units,
+		TxHash:          tx.Hash,
+		TxLt:            tx.LastLT,
+		SenderAddress:   tx.Sender,
+		ReceivedMcSeqno: mcSeqNo,
+		DepositAddress:  addr.Address,
+	})
+	if ton.IsDepositAlreadyProcessed(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	s.log.Info("deposit received on deposit address",
+		zap.String("deposit_id", deposit.ID.String()),
+		zap.Int64("telegram_user_id", addr.TelegramUserID),
+		zap.String("address", addr.Address),
+		zap.String("tx_hash", tx.Hash),
+		zap.Uint64("amount", tx.Units),
+		zap.Uint32("mc_seqno", mcSeqNo),
+	)
+	return nil
+}
+
+// RecordUnattributedDeposit сохраняет платёж на казначейский кошелёк,
+// который не удалось сопоставить с депозитом. comment — расшифрованный
+// комментарий или исходный payload, если его не удалось прочитать.
+func (s *Service) RecordUnattributedDeposit(ctx context.Context, tx ton.Transaction, comment string) error {
+	created, err := s.tonRepo.CreateUnattributedDeposit(ctx, &ton.CreateUnattributedDepositParams{
+		TxHash:        tx.Hash,
+		TxLt:          tx.LastLT,
+		SenderAddress: tx.Sender,
+		Currency:      payment.Currency(tx.Currency),
+		AmountNano:    tx.Units,
+		Comment:       comment,
+	})
+	if err != nil {
+		return err
+	}
+	if created {
+		s.log.Warn("unattributed deposit recorded",
+			zap.String("tx_hash", tx.Hash),
+			zap.String("sender", tx.Sender),
+			zap.Uint64("amount", tx.Units),
+			zap.String("currency", tx.Currency),
+			zap.String("comment", comment),
+		)
+	}
+	return nil
+}
+
+// ListUnattributedDeposits возвращает несопоставленные платежи в статусе status.
+func (s *Service) ListUnattributedDeposits(
+	ctx context.Context,
+	status ton.UnattributedDepositStatus,
+	limit, offset int32,
+) ([]*ton.UnattributedDeposit, error) {
+	return s.tonRepo.ListUnattributedDeposits(ctx, status, limit, offset)
+}
+
+// AssignUnattributedDeposit зачисляет несопоставленный платёж пользователю.
+// Платёж назначается один раз; повторный вызов вернёт
+// ton.ErrUnattributedDepositNotFound.
+func (s *Service) AssignUnattributedDeposit(
+	ctx context.Context,
+	id string,
+	telegramUserID int64,
+) (*ton.UnattributedDeposit, error) {
+	log := s.log.With(zap.String("unattributed_deposit_id", id))
+
+	tx, err := s.txMgr.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			err = tx.Rollback(ctx)
+			if err != nil {
+				log.Error("failed to rollback transaction", zap.Error(err))
+			}
+		}
+	}()
+
+	deposit, err := s.tonRepo.WithTx(tx).AssignUnattributedDeposit(ctx, id, telegramUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := deposit.Currency.AmountFromUnits(deposit.AmountNano)
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.addUserBalance(
+		ctx,
+		s.repo.WithTx(tx),
+		telegramUserID,
+		deposit.Currency,
+		amount,
+		payment.TransactionReasonDeposit,
+		payment.AccountTypeTreasury,
+		payment.NewDepositMetadata(deposit.ID.String(), deposit.Currency, amount),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("unattributed deposit assigned",
+		zap.Int64("telegram_user_id", telegramUserID),
+		zap.String("amount", amount.String()+" "+string(deposit.Currency)),
+	)
+	return deposit, nil
+}
@@ -0,0 +1,159 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/payment"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/ton"
+)
+
+type fakeDeriver struct{}
+
+func (fakeDeriver) DeriveAddress(subwalletID uint32) (string, string, error) {
+	id := strconv.FormatUint(uint64(subwalletID), 10)
+	return "addr-" + id, "0:" + id, nil
+}
+
+type fakeAddressRepo struct {
+	ton.DepositAddressRepository
+
+	store *store
+	// beforeCreate срабатывает перед сохранением адреса, имитируя
+	// параллельный запрос того же пользователя
+	beforeCreate func()
+}
+
+func (r *fakeAddressRepo) WithTx(pgx.Tx) ton.DepositAddressRepository {
+	return r
+}
+
+func (r *fakeAddressRepo) NextSubwalletID(context.Context) (uint64, error) {
+	r.store.st.subwalletID++
+	return r.store.st.subwalletID, nil
+}
+
+func (r *fakeAddressRepo) GetDepositAddressByUser(
+	_ context.Context,
+	telegramUserID int64,
+) (*ton.DepositAddress, error) {
+	addr, ok := r.store.st.addresses[telegramUserID]
+	if !ok {
+		return nil, ton.ErrDepositAddressNotFound
+	}
+	return &addr, nil
+}
+
+func (r *fakeAddressRepo) CreateDepositAddress(
+	_ context.Context,
+	params *ton.CreateDepositAddressParams,
+) (*ton.DepositAddress, error) {
+	if r.beforeCreate != nil {
+		r.beforeCreate()
+	}
+	if _, ok := r.store.st.addresses[params.TelegramUserID]; ok {
+		return nil, ton.ErrDepositAddressExists
+	}
+	addr := ton.DepositAddress{
+		ID:             uuid.New(),
+		TelegramUserID: params.TelegramUserID,
+		SubwalletID:    params.SubwalletID,
+		Address:        params.Address,
+		RawAddress:     params.RawAddress,
+	}
+	r.store.st.addresses[params.TelegramUserID] = addr
+	return &addr, nil
+}
+
+func TestGetDepositAddress(t *testing.T) {
+	const base = 1_000_000
+
+	tests := []struct {
+		name        string
+		disabled    bool
+		setup       func(f *fixture, repo *fakeAddressRepo)
+		wantErr     error
+		wantAddress string
+	}{
+		{
+			name:     "disabled",
+			disabled: true,
+			wantErr:  ErrDepositAddressesDisabled,
+		},
+		{
+			name:        "first request derives the next subwallet",
+			wantAddress: "addr-1000001",
+		},
+		{
+			name: "existing address is returned as is",
+			setup: func(f *fixture, _ *fakeAddressRepo) {
+				f.store.st.addresses[userID] = ton.DepositAddress{TelegramUserID: userID, Address: "addr-existing"}
+			},
+			wantAddress: "addr-existing",
+		},
+		{
+			name: "concurrent request wins",
+			setup: func(f *fixture, repo *fakeAddressRepo) {
+				repo.beforeCreate = func() {
+					f.store.st.addresses[userID] = ton.DepositAddress{TelegramUserID: userID, Address: "addr-winner"}
+				}
+			},
+			wantAddress: "addr-winner",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t)
+			f.cfg.Ton.Deposit.Addresses.Enabled = !tt.disabled
+			f.cfg.Ton.Deposit.Addresses.SubwalletBase = base
+			repo := &fakeAddressRepo{store: f.store}
+			f.service.addressRepo = repo
+			if tt.setup != nil {
+				tt.setup(f, repo)
+			}
+
+			addr, err := f.service.GetDepositAddress(context.Background(), userID)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if addr.Address != tt.wantAddress {
+				t.Errorf("address %s, want %s", addr.Address, tt.wantAddress)
+			}
+		})
+	}
+}
+
+// Повторно прочитанная транзакция на персональный адрес зачисляется один раз.
+func TestProcessDerivedDepositCreditsOnce(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	addr := &ton.DepositAddress{TelegramUserID: userID, Address: "addr-1"}
+	tx := paymentTx(t, "", string(payment.CurrencyTON), 2_000_000_000, 5)
+
+	for range 2 {
+		if err := f.service.ProcessDerivedDeposit(ctx, addr, tx, 10); err != nil {
+			t.Fatalf("process: %v", err)
+		}
+	}
+	if _, err := f.service.ConfirmDeposits(ctx, 10, 0, 10); err != nil {
+		t.Fatalf("confirm: %v", err)
+	}
+
+	if len(f.store.st.deposits) != 1 {
+		t.Fatalf("deposits %d, want 1", len(f.store.st.deposits))
+	}
+	if got := f.userBalance(userID); got != "2" {
+		t.Errorf("credited %s, want 2", got)
+	}
+}
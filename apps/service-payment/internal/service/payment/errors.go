@@ -2,8 +2,77 @@ package payment
 
 import "errors"
 
-var ErrInsufficientBalance = errors.New("insufficient balance")
+var (
+	ErrInsufficientBalance      = errors.New("insufficient balance")
+	ErrWithdrawalAmountTooSmall = errors.New("withdrawal amount is below minimum")
+	ErrCurrencyNotSupported     = errors.New("currency is not supported")
+	ErrDepositAddressesDisabled = errors.New("deposit addresses are disabled")
+	ErrIdempotencyKeyReused     = errors.New("idempotency key reused with different parameters")
+	ErrInvalidHoldAmount        = errors.New("hold amount must be positive")
+	ErrHoldTTLTooLong           = errors.New("hold ttl exceeds maximum")
+	ErrHoldNotFound             = errors.New("hold not found")
+	ErrHoldNotActive            = errors.New("hold is not active")
+	ErrHoldExpired              = errors.New("hold has expired")
+	ErrInvalidTransactionFilter = errors.New("invalid transaction filter")
+	ErrUnknownExportFormat      = errors.New("unknown export format")
+	ErrFeeScheduleNotFound      = errors.New("fee schedule not found")
+	// ErrFeeScheduleVersionConflict — ту же версию одновременно опубликовал
+	// другой запрос; публикацию нужно повторить.
+	ErrFeeScheduleVersionConflict = errors.New("fee schedule version was published concurrently, retry")
+)
 
 func IsInsufficientBalance(err error) bool {
 	return errors.Is(err, ErrInsufficientBalance)
 }
+
+func IsWithdrawalAmountTooSmall(err error) bool {
+	return errors.Is(err, ErrWithdrawalAmountTooSmall)
+}
+
+func IsCurrencyNotSupported(err error) bool {
+	return errors.Is(err, ErrCurrencyNotSupported)
+}
+
+func IsDepositAddressesDisabled(err error) bool {
+	return errors.Is(err, ErrDepositAddressesDisabled)
+}
+
+func IsIdempotencyKeyReused(err error) bool {
+	return errors.Is(err, ErrIdempotencyKeyReused)
+}
+
+func IsInvalidHoldAmount(err error) bool {
+	return errors.Is(err, ErrInvalidHoldAmount)
+}
+
+func IsHoldTTLTooLong(err error) bool {
+	return errors.Is(err, ErrHoldTTLTooLong)
+}
+
+func IsHoldNotFound(err error) bool {
+	return errors.Is(err, ErrHoldNotFound)
+}
+
+func IsHoldNotActive(err error) bool {
+	return errors.Is(err, ErrHoldNotActive)
+}
+
+func IsHoldExpired(err error) bool {
+	return errors.Is(err, ErrHoldExpired)
+}
+
+func IsInvalidTransactionFilter(err error) bool {
+	return errors.Is(err, ErrInvalidTransactionFilter)
+}
+
+func IsUnknownExportFormat(err error) bool {
+	return errors.Is(err, ErrUnknownExportFormat)
+}
+
+func IsFeeScheduleNotFound(err error) bool {
+	return errors.Is(err, ErrFeeScheduleNotFound)
+}
+
+func IsFeeScheduleVersionConflict(err error) bool {
+	return errors.Is(err, ErrFeeScheduleVersionConflict)
+}
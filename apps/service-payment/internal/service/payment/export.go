@@ -0,0 +1,196 @@
+package payment
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/payment"
+	"github.com/peterparker2005/giftduels/packages/shared"
+)
+
+// exportBatchSize — сколько транзакций выгрузка читает из базы за раз.
+const exportBatchSize = 500
+
+// exportRow — транзакция с метаданными, развёрнутыми в плоские колонки.
+type exportRow struct {
+	ID                    string `json:"id"`
+	CreatedAt             string `json:"created_at"`
+	Reason                string `json:"reason"`
+	Currency              string `json:"currency"`
+	Amount                string `json:"amount"`
+	GiftID                string `json:"gift_id"`
+	GiftTitle             string `json:"gift_title"`
+	GiftSlug              string `json:"gift_slug"`
+	WithdrawalID          string `json:"withdrawal_id"`
+	WithdrawalDestination string `json:"withdrawal_destination"`
+	DepositID             string `json:"deposit_id"`
+	DepositCurrency       string `json:"deposit_currency"`
+	DepositAmount         string `json:"deposit_amount"`
+	DepositRate           string `json:"deposit_rate"`
+}
+
+//nolint:gochecknoglobals // CSV header
+var exportColumns = []string{
+	"id", "created_at", "reason", "currency", "amount",
+	"gift_id", "gift_title", "gift_slug",
+	"withdrawal_id", "withdrawal_destination",
+	"deposit_id", "deposit_currency", "deposit_amount", "deposit_rate",
+}
+
+func newExportRow(t *payment.Transaction) exportRow {
+	row := exportRow{
+		ID:        t.ID,
+		CreatedAt: t.CreatedAt.UTC().Format(time.RFC3339),
+		Reason:    string(t.Reason),
+		Currency:  string(t.Currency),
+		Amount:    t.Amount.String(),
+	}
+	if t.Metadata == nil {
+		return row
+	}
+	if g := t.Metadata.Gift; g != nil {
+		row.GiftID, row.GiftTitle, row.GiftSlug = g.GiftID, g.Title, g.Slug
+	}
+	if w := t.Metadata.TonWithdrawal; w != nil {
+		row.WithdrawalID, row.WithdrawalDestination = w.WithdrawalID, w.Destination
+	}
+	if d := t.Metadata.Deposit; d != nil {
+		row.DepositID = d.DepositID
+		row.DepositCurrency = string(d.Currency)
+		row.DepositAmount = d.Amount
+		row.DepositRate = d.Rate
+	}
+	return row
+}
+
+func (r exportRow) values() []string {
+	return []string{
+		r.ID, r.CreatedAt, r.Reason, r.Currency, r.Amount,
+		r.GiftID, r.GiftTitle, r.GiftSlug,
+		r.WithdrawalID, r.WithdrawalDestination,
+		r.DepositID, r.DepositCurrency, r.DepositAmount, r.DepositRate,
+	}
+}
+
+// exportEncoder пишет строки выгрузки в выбранном формате.
+type exportEncoder interface {
+	Begin() error
+	Write(row exportRow) error
+	End() error
+}
+
+type csvExportEncoder struct {
+	w *csv.Writer
+}
+
+func (e *csvExportEncoder) Begin() error {
+	return e.w.Write(exportColumns)
+}
+
+func (e *csvExportEncoder) Write(row exportRow) error {
+	return e.w.Write(row.values())
+}
+
+func (e *csvExportEncoder) End() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// jsonExportEncoder пишет массив построчно, не собирая его в памяти.
+type jsonExportEncoder struct {
+	w     io.Writer
+	first bool
+}
+
+func (e *jsonExportEncoder) Begin() error {
+	e.first = true
+	_, err := io.WriteString(e.w, "[")
+	return err
+}
+
+func (e *jsonExportEncoder) Write(row exportRow) error {
+	b, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+	if !e.first {
+		if _, err = io.WriteString(e.w, ",\n"); err != nil {
+			return err
+		}
+	}
+	e.first = false
+	_, err = e.w.Write(b)
+	return err
+}
+
+func (e *jsonExportEncoder) End() error {
+	_, err := io.WriteString(e.w, "]\n")
+	return err
+}
+
+func newExportEncoder(format payment.ExportFormat, w io.Writer) (exportEncoder, error) {
+	switch format {
+	case payment.ExportFormatCSV:
+		return &csvExportEncoder{w: csv.NewWriter(w)}, nil
+	case payment.ExportFormatJSON:
+		return &jsonExportEncoder{w: w}, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownExportFormat, format)
+	}
+}
+
+// ExportTransactions выгружает всю отфильтрованную историю пользователя
+// в w. Верхняя граница периода фиксируется на момент начала выгрузки,
+// чтобы новые транзакции не сдвигали страницы.
+func (s *Service) ExportTransactions(
+	ctx context.Context,
+	telegramUserID int64,
+	filter *payment.TransactionFilter,
+	format payment.ExportFormat,
+	w io.Writer,
+) error {
+	if err := validateTransactionFilter(filter); err != nil {
+		return err
+	}
+	enc, err := newExportEncoder(format, w)
+	if err != nil {
+		return err
+	}
+
+	snapshot := payment.TransactionFilter{}
+	if filter != nil {
+		snapshot = *filter
+	}
+	now := time.Now()
+	if snapshot.To == nil || snapshot.To.After(now) {
+		snapshot.To = &now
+	}
+
+	if err = enc.Begin(); err != nil {
+		return err
+	}
+	for page := int32(1); ; page++ {
+		batch, batchErr := s.repo.GetUserTransactions(
+			ctx,
+			telegramUserID,
+			&snapshot,
+			shared.NewPageRequest(page, exportBatchSize),
+		)
+		if batchErr != nil {
+			return batchErr
+		}
+		for _, t := range batch {
+			if err = enc.Write(newExportRow(t)); err != nil {
+				return err
+			}
+		}
+		if len(batch) < exportBatchSize {
+			break
+		}
+	}
+	return enc.End()
+}
@@ -0,0 +1,315 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/payment"
+	"github.com/peterparker2005/giftduels/packages/shared"
+)
+
+// fakeHistoryRepo отдаёт transactions постранично и запоминает фильтры,
+// с которыми его вызывали.
+type fakeHistoryRepo struct {
+	payment.Repository
+
+	transactions []*payment.Transaction
+	filters      []payment.TransactionFilter
+}
+
+func (r *fakeHistoryRepo) GetUserTransactions(
+	_ context.Context,
+	_ int64,
+	filter *payment.TransactionFilter,
+	pagination *shared.PageRequest,
+) ([]*payment.Transaction, error) {
+	r.filters = append(r.filters, *filter)
+	from := min(int(pagination.Offset()), len(r.transactions))
+	to := min(from+int(pagination.PageSize()), len(r.transactions))
+	return r.transactions[from:to], nil
+}
+
+//nolint:gochecknoglobals // фиксированный момент для строк выгрузки
+var exportedAt = time.Date(2026, 10, 1, 12, 30, 0, 0, time.FixedZone("MSK", 3*60*60))
+
+func exportTransactions(t *testing.T) []*payment.Transaction {
+	t.Helper()
+	return []*payment.Transaction{
+		{
+			ID:       "tx-1",
+			Currency: payment.CurrencyTON,
+			Amount:   mustAmount(t, "1.5"),
+			Reason:   payment.TransactionReasonPurchase,
+			Metadata: &payment.TransactionMetadata{Gift: &payment.TransactionMetadataGiftDetails{
+				GiftID: "gift-1", Title: "Plush, Pepe", Slug: "plushpepe-1",
+			}},
+			CreatedAt: exportedAt,
+		},
+		{
+			ID:       "tx-2",
+			Currency: payment.CurrencyTON,
+			Amount:   mustAmount(t, "10"),
+			Reason:   payment.TransactionReasonDeposit,
+			Metadata: &payment.TransactionMetadata{Deposit: &payment.TransactionMetadataDepositDetails{
+				DepositID: "dep-1", Currency: payment.CurrencyUSDT, Amount: "32", Rate: "3.2",
+			}},
+			CreatedAt: exportedAt.Add(-time.Hour),
+		},
+		{
+			ID:        "tx-3",
+			Currency:  payment.CurrencyTON,
+			Amount:    mustAmount(t, "0.25"),
+			Reason:    payment.TransactionReasonRefund,
+			CreatedAt: exportedAt.Add(-2 * time.Hour),
+		},
+	}
+}
+
+func TestExportTransactionsFormats(t *testing.T) {
+	tests := []struct {
+		name   string
+		format payment.ExportFormat
+		want   string
+	}{
+		{
+			name:   "csv",
+			format: payment.ExportFormatCSV,
+			want: "id,created_at,reason,currency,amount,gift_id,gift_title,gift_slug," +
+				"withdrawal_id,withdrawal_destination,deposit_id,deposit_currency,deposit_amount,deposit_rate\n" +
+				`tx-1,2026-10-01T09:30:00Z,purchase,TON,1.5,gift-1,"Plush, Pepe",plushpepe-1,,,,,,` + "\n" +
+				"tx-2,2026-10-01T08:30:00Z,deposit,TON,10,,,,,,dep-1,USDT,32,3.2\n" +
+				"tx-3,2026-10-01T07:30:00Z,refund,TON,0.25,,,,,,,,,\n",
+		},
+		{
+			name:   "json",
+			format: payment.ExportFormatJSON,
+			want: `[{"id":"tx-1","created_at":"2026-10-01T09:30:00Z","reason":"purchase","currency":"TON",` +
+				`"amount":"1.5","gift_id":"gift-1","gift_title":"Plush, Pepe","gift_slug":"plushpepe-1",` +
+				`"withdrawal_id":"","withdrawal_destination":"","deposit_id":"","deposit_currency":"",` +
+				`"deposit_amount":"","deposit_rate":""},` + "\n" +
+				`{"id":"tx-2","created_at":"2026-10-01T08:30:00Z","reason":"deposit","currency":"TON",` +
+				`"amount":"10","gift_id":"","gift_title":"","gift_slug":"",` +
+				`"withdrawal_id":"","withdrawal_destination":"","deposit_id":"dep-1","deposit_currency":"USDT",` +
+				`"deposit_amount":"32","deposit_rate":"3.2"},` + "\n" +
+				`{"id":"tx-3","created_at":"2026-10-01T07:30:00Z","reason":"refund","currency":"TON",` +
+				`"amount":"0.25","gift_id":"","gift_title":"","gift_slug":"",` +
+				`"withdrawal_id":"","withdrawal_destination":"","deposit_id":"","deposit_currency":"",` +
+				`"deposit_amount":"","deposit_rate":""}]` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t)
+			f.service.repo = &fakeHistoryRepo{transactions: exportTransactions(t)}
+
+			var buf bytes.Buffer
+			if err := f.service.ExportTransactions(context.Background(), userID, nil, tt.format, &buf); err != nil {
+				t.Fatalf("export: %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("export:\n%s\nwant:\n%s", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+// Пустая история — валидный документ с одним заголовком.
+func TestExportTransactionsEmpty(t *testing.T) {
+	for format, want := range map[payment.ExportFormat]string{
+		payment.ExportFormatCSV: "id,created_at,reason,currency,amount,gift_id,gift_title,gift_slug," +
+			"withdrawal_id,withdrawal_destination,deposit_id,deposit_currency,deposit_amount,deposit_rate\n",
+		payment.ExportFormatJSON: "[]\n",
+	} {
+		f := newFixture(t)
+		f.service.repo = &fakeHistoryRepo{}
+
+		var buf bytes.Buffer
+		if err := f.service.ExportTransactions(context.Background(), userID, nil, format, &buf); err != nil {
+			t.Fatalf("export %s: %v", format, err)
+		}
+		if buf.String() != want {
+			t.Errorf("export %s = %q, want %q", format, buf.String(), want)
+		}
+		if format == payment.ExportFormatJSON && !json.Valid(buf.Bytes()) {
+			t.Errorf("export %s is not valid json", format)
+		}
+	}
+}
+
+func TestExportTransactionsFilter(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name    string
+		filter  *payment.TransactionFilter
+		format  payment.ExportFormat
+		wantErr error
+		// wantTo проверяет верхнюю границу, переданную в репозиторий
+		wantTo func(to *time.Time) bool
+	}{
+		{
+			name:   "no filter is bounded by export start",
+			format: payment.ExportFormatCSV,
+			wantTo: func(to *time.Time) bool { return to != nil && !to.After(time.Now()) },
+		},
+		{
+			name:   "future upper bound is clamped",
+			filter: &payment.TransactionFilter{To: &future},
+			format: payment.ExportFormatCSV,
+			wantTo: func(to *time.Time) bool { return to != nil && to.Before(future) },
+		},
+		{
+			name:   "past upper bound is kept",
+			filter: &payment.TransactionFilter{To: &past},
+			format: payment.ExportFormatCSV,
+			wantTo: func(to *time.Time) bool { return to != nil && to.Equal(past) },
+		},
+		{
+			name:    "empty period",
+			filter:  &payment.TransactionFilter{From: &past, To: &past},
+			format:  payment.ExportFormatCSV,
+			wantErr: ErrInvalidTransactionFilter,
+		},
+		{
+			name:    "unknown format",
+			format:  "xlsx",
+			wantErr: ErrUnknownExportFormat,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t)
+			repo := &fakeHistoryRepo{transactions: exportTransactions(t)}
+			f.service.repo = repo
+
+			var callerTo *time.Time
+			if tt.filter != nil && tt.filter.To != nil {
+				to := *tt.filter.To
+				callerTo = &to
+			}
+
+			var buf bytes.Buffer
+			err := f.service.ExportTransactions(context.Background(), userID, tt.filter, tt.format, &buf)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				// при ошибке в w ничего не пишется
+				if buf.Len() != 0 {
+					t.Errorf("written %q, want nothing", buf.String())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(repo.filters) != 1 || !tt.wantTo(repo.filters[0].To) {
+				t.Errorf("filters %+v", repo.filters)
+			}
+			// фильтр вызывающего не меняется
+			if callerTo != nil && !tt.filter.To.Equal(*callerTo) {
+				t.Errorf("caller upper bound changed to %s", tt.filter.To)
+			}
+		})
+	}
+}
+
+// Выгрузка читает историю пачками, пока не получит неполную страницу.
+func TestExportTransactionsPaginates(t *testing.T) {
+	tests := []struct {
+		name      string
+		count     int
+		wantPages int
+	}{
+		{name: "single page", count: exportBatchSize - 1, wantPages: 1},
+		{name: "exactly one page", count: exportBatchSize, wantPages: 2},
+		{name: "several pages", count: 2*exportBatchSize + 1, wantPages: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t)
+			repo := &fakeHistoryRepo{}
+			for i := range tt.count {
+				repo.transactions = append(repo.transactions, &payment.Transaction{
+					ID:        fmt.Sprintf("tx-%d", i),
+					Currency:  payment.CurrencyTON,
+					Amount:    mustAmount(t, "1"),
+					Reason:    payment.TransactionReasonDeposit,
+					CreatedAt: exportedAt,
+				})
+			}
+			f.service.repo = repo
+
+			var buf bytes.Buffer
+			err := f.service.ExportTransactions(
+				context.Background(), userID, nil, payment.ExportFormatJSON, &buf,
+			)
+			if err != nil {
+				t.Fatalf("export: %v", err)
+			}
+			if len(repo.filters) != tt.wantPages {
+				t.Errorf("pages %d, want %d", len(repo.filters), tt.wantPages)
+			}
+			var rows []exportRow
+			if err = json.Unmarshal(buf.Bytes(), &rows); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if len(rows) != tt.count {
+				t.Errorf("rows %d, want %d", len(rows), tt.count)
+			}
+			// верхняя граница одна на все страницы
+			for _, filter := range repo.filters[1:] {
+				if !filter.To.Equal(*repo.filters[0].To) {
+					t.Errorf("upper bound moved from %s to %s", repo.filters[0].To, filter.To)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateTransactionFilter(t *testing.T) {
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+	low, high := mustAmount(t, "1"), mustAmount(t, "2")
+
+	tests := []struct {
+		name    string
+		filter  *payment.TransactionFilter
+		wantErr bool
+	}{
+		{name: "nil", filter: nil},
+		{name: "empty", filter: &payment.TransactionFilter{}},
+		{name: "period", filter: &payment.TransactionFilter{From: &from, To: &to}},
+		{name: "open period", filter: &payment.TransactionFilter{From: &from}},
+		{name: "empty period", filter: &payment.TransactionFilter{From: &from, To: &from}, wantErr: true},
+		{name: "reversed period", filter: &payment.TransactionFilter{From: &to, To: &from}, wantErr: true},
+		{name: "amount range", filter: &payment.TransactionFilter{MinAmount: low, MaxAmount: high}},
+		{name: "single amount", filter: &payment.TransactionFilter{MinAmount: low, MaxAmount: low}},
+		{
+			name:    "reversed amount range",
+			filter:  &payment.TransactionFilter{MinAmount: high, MaxAmount: low},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateTransactionFilter(tt.filter)
+			if tt.wantErr != errors.Is(err, ErrInvalidTransactionFilter) {
+				t.Fatalf("validate = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package payment
+
+import (
+	"context"
+	"time"
+
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/adapter/pg"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/payment"
+	"github.com/peterparker2005/giftduels/packages/tonamount-go"
+	"go.uber.org/zap"
+)
+
+// PreviewWithdraw считает комиссии за вывод подарков по расписанию,
+// действующему сейчас, и возвращает его версию: по ней сага проверяет,
+// что комиссия не изменилась между предпросмотром и выводом.
+func (s *Service) PreviewWithdraw(
+	ctx context.Context,
+	gifts []*payment.GiftWithdrawRequest,
+) (*payment.WithdrawOptions, error) {
+	now := time.Now()
+	schedule, err := s.GetFeeSchedule(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	options := &payment.WithdrawOptions{
+		GiftFees:           make([]*payment.GiftFee, 0, len(gifts)),
+		TotalTonFee:        tonamount.Zero(),
+		FeeScheduleVersion: schedule.Version,
+	}
+	for _, gift := range gifts {
+		commission, commErr := schedule.Commission(gift, now)
+		if commErr != nil {
+			s.log.Error("failed to calculate withdrawal commission",
+				zap.String("giftID", gift.GiftID),
+				zap.Int64("feeScheduleVersion", schedule.Version),
+				zap.Error(commErr),
+			)
+			return nil, commErr
+		}
+		options.GiftFees = append(options.GiftFees, &payment.GiftFee{
+			GiftID:   gift.GiftID,
+			StarsFee: commission.Stars,
+			TonFee:   commission.Ton,
+		})
+		options.TotalStarsFee += commission.Stars
+		options.TotalTonFee = options.TotalTonFee.Add(commission.Ton)
+	}
+	return options, nil
+}
+
+// GetFeeSchedule возвращает расписание комиссий указанной версии,
+// а при version == 0 — действующее сейчас.
+func (s *Service) GetFeeSchedule(ctx context.Context, version int64) (*payment.FeeSchedule, error) {
+	var (
+		schedule *payment.FeeSchedule
+		err      error
+	)
+	if version == 0 {
+		schedule, err = s.feeRepo.GetActiveFeeSchedule(ctx, time.Now())
+	} else {
+		schedule, err = s.feeRepo.GetFeeScheduleByVersion(ctx, version)
+	}
+	if pg.IsNotFound(err) {
+		return nil, ErrFeeScheduleNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// PublishFeeSchedule сохраняет расписание следующей версией. Версия
+// вступает в силу с EffectiveFrom, а без него — сразу.
+func (s *Service) PublishFeeSchedule(
+	ctx context.Context,
+	schedule *payment.FeeSchedule,
+) (*payment.FeeSchedule, error) {
+	if schedule.EffectiveFrom.IsZero() {
+		schedule.EffectiveFrom = time.Now()
+	}
+	if err := schedule.Validate(); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.txMgr.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			err = tx.Rollback(ctx)
+			if err != nil {
+				s.log.Error("failed to rollback transaction", zap.Error(err))
+			}
+		}
+	}()
+
+	// версия считается как MAX+1 без блокировки: параллельная публикация
+	// упирается в уникальность версии
+	published, err := s.feeRepo.WithTx(tx).CreateFeeSchedule(ctx, schedule)
+	if pg.IsConflict(err) {
+		s.log.Warn("fee schedule version conflict", zap.Error(err))
+		return nil, ErrFeeScheduleVersionConflict
+	}
+	if err != nil {
+		s.log.Error("failed to create fee schedule", zap.Error(err))
+		return nil, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("fee schedule published",
+		zap.Int64("version", published.Version),
+		zap.Time("effectiveFrom", published.EffectiveFrom),
+	)
+	return published, nil
+}
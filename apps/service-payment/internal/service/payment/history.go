@@ -0,0 +1,68 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/payment"
+	"github.com/peterparker2005/giftduels/packages/shared"
+	"go.uber.org/zap"
+)
+
+func (s *Service) GetTransactionHistory(
+	ctx context.Context,
+	telegramUserID int64,
+	filter *payment.TransactionFilter,
+	pagination *shared.PageRequest,
+) ([]*payment.Transaction, int64, error) {
+	if err := validateTransactionFilter(filter); err != nil {
+		return nil, 0, err
+	}
+
+	count, err := s.repo.GetUserTransactionsCount(ctx, telegramUserID, filter)
+	if err != nil {
+		s.log.Error("failed to get user transactions count", zap.Error(err))
+		return nil, 0, err
+	}
+
+	transactions, err := s.repo.GetUserTransactions(ctx, telegramUserID, filter, pagination)
+	if err != nil {
+		s.log.Error("failed to get user transactions", zap.Error(err))
+		return nil, 0, err
+	}
+
+	return transactions, count, nil
+}
+
+// GetTransactionTotals возвращает суммы транзакций по причинам и валютам
+// за выбранный фильтром период.
+func (s *Service) GetTransactionTotals(
+	ctx context.Context,
+	telegramUserID int64,
+	filter *payment.TransactionFilter,
+) ([]*payment.TransactionTotal, error) {
+	if err := validateTransactionFilter(filter); err != nil {
+		return nil, err
+	}
+
+	totals, err := s.repo.GetUserTransactionTotals(ctx, telegramUserID, filter)
+	if err != nil {
+		s.log.Error("failed to get user transaction totals", zap.Error(err))
+		return nil, err
+	}
+	return totals, nil
+}
+
+func validateTransactionFilter(filter *payment.TransactionFilter) error {
+	if filter == nil {
+		return nil
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return fmt.Errorf("%w: created_at range is empty", ErrInvalidTransactionFilter)
+	}
+	if filter.MinAmount != nil && filter.MaxAmount != nil &&
+		filter.MinAmount.Decimal().GreaterThan(filter.MaxAmount.Decimal()) {
+		return fmt.Errorf("%w: min_amount exceeds max_amount", ErrInvalidTransactionFilter)
+	}
+	return nil
+}
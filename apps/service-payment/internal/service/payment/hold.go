@@ -0,0 +1,277 @@
+package payment
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/adapter/pg"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/payment"
+	"github.com/peterparker2005/giftduels/packages/tonamount-go"
+	"go.uber.org/zap"
+)
+
+// CreateHold резервирует amount на TON-балансе пользователя на срок ttl.
+// Нулевой ttl заменяется сроком по умолчанию. При списании холда
+// транзакция получит причину reason и метаданные metadata.
+func (s *Service) CreateHold(
+	ctx context.Context,
+	telegramUserID int64,
+	amount string,
+	reason payment.TransactionReason,
+	metadata *payment.TransactionMetadata,
+	ttl time.Duration,
+) (*payment.Hold, error) {
+	log := s.log.With(
+		zap.Int64("telegram_user_id", telegramUserID),
+		zap.String("amount", amount),
+		zap.String("reason", string(reason)),
+	)
+
+	tonAmount, err := tonamount.NewTonAmountFromString(amount)
+	if err != nil {
+		log.Error("failed to parse amount", zap.Error(err))
+		return nil, err
+	}
+	if !tonAmount.Decimal().IsPositive() {
+		return nil, ErrInvalidHoldAmount
+	}
+	if ttl <= 0 {
+		ttl = s.cfg.Holds.DefaultTTL
+	}
+	if ttl > s.cfg.Holds.MaxTTL {
+		return nil, ErrHoldTTLTooLong
+	}
+
+	tx, err := s.txMgr.BeginTx(ctx)
+	if err != nil {
+		log.Error("failed to begin transaction", zap.Error(err))
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			err = tx.Rollback(ctx)
+			if err != nil {
+				log.Error("failed to rollback transaction", zap.Error(err))
+			}
+		}
+	}()
+
+	holdRepo := s.holdRepo.WithTx(tx)
+	_, err = holdRepo.ReserveBalance(ctx, telegramUserID, payment.CurrencyTON, tonAmount)
+	if err != nil {
+		if pg.IsNotFound(err) {
+			return nil, ErrInsufficientBalance
+		}
+		log.Error("failed to reserve balance", zap.Error(err))
+		return nil, err
+	}
+
+	hold, err := holdRepo.CreateHold(ctx, &payment.CreateHoldParams{
+		TelegramUserID: telegramUserID,
+		Currency:       payment.CurrencyTON,
+		Amount:         tonAmount,
+		Reason:         reason,
+		Metadata:       s.marshalMetadata(metadata),
+		ExpiresAt:      time.Now().Add(ttl),
+	})
+	if err != nil {
+		log.Error("failed to create hold", zap.Error(err))
+		return nil, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		log.Error("failed to commit transaction", zap.Error(err))
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+// CaptureHold списывает зарезервированную холдом сумму с баланса и
+// возвращает баланс после списания.
+func (s *Service) CaptureHold(ctx context.Context, holdID string) (*payment.Balance, error) {
+	log := s.log.With(zap.String("hold_id", holdID))
+
+	id, err := uuid.Parse(holdID)
+	if err != nil {
+		return nil, ErrHoldNotFound
+	}
+
+	tx, err := s.txMgr.BeginTx(ctx)
+	if err != nil {
+		log.Error("failed to begin transaction", zap.Error(err))
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			err = tx.Rollback(ctx)
+			if err != nil {
+				log.Error("failed to rollback transaction", zap.Error(err))
+			}
+		}
+	}()
+
+	holdRepo := s.holdRepo.WithTx(tx)
+	hold, err := s.getActiveHold(ctx, holdRepo, id)
+	if err != nil {
+		return nil, err
+	}
+	if hold.IsExpired(time.Now()) {
+		err = ErrHoldExpired
+		return nil, err
+	}
+
+	// сначала снимаем резерв, иначе списание упрётся в него же
+	if err = s.settleHold(ctx, holdRepo, hold, payment.HoldStatusCaptured); err != nil {
+		log.Error("failed to settle hold", zap.Error(err))
+		return nil, err
+	}
+
+	balance, err := s.spendUserBalance(
+		ctx,
+		s.repo.WithTx(tx),
+		hold.TelegramUserID,
+		hold.Currency,
+		hold.Amount,
+		hold.Reason,
+		payment.CounterAccount(hold.Reason, hold.Metadata),
+		hold.Metadata,
+	)
+	if err != nil {
+		log.Error("failed to spend held amount", zap.Error(err))
+		return nil, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		log.Error("failed to commit transaction", zap.Error(err))
+		return nil, err
+	}
+
+	return balance, nil
+}
+
+// ReleaseHold отменяет холд, возвращая сумму в доступный баланс.
+func (s *Service) ReleaseHold(ctx context.Context, holdID string) (*payment.Hold, error) {
+	log := s.log.With(zap.String("hold_id", holdID))
+
+	id, err := uuid.Parse(holdID)
+	if err != nil {
+		return nil, ErrHoldNotFound
+	}
+
+	tx, err := s.txMgr.BeginTx(ctx)
+	if err != nil {
+		log.Error("failed to begin transaction", zap.Error(err))
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			err = tx.Rollback(ctx)
+			if err != nil {
+				log.Error("failed to rollback transaction", zap.Error(err))
+			}
+		}
+	}()
+
+	holdRepo := s.holdRepo.WithTx(tx)
+	hold, err := s.getActiveHold(ctx, holdRepo, id)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.settleHold(ctx, holdRepo, hold, payment.HoldStatusReleased); err != nil {
+		log.Error("failed to settle hold", zap.Error(err))
+		return nil, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		log.Error("failed to commit transaction", zap.Error(err))
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+// ExpireHolds снимает до limit холдов, истёкших к моменту now, и
+// возвращает их число.
+func (s *Service) ExpireHolds(ctx context.Context, now time.Time, limit int32) (int, error) {
+	tx, err := s.txMgr.BeginTx(ctx)
+	if err != nil {
+		s.log.Error("failed to begin transaction", zap.Error(err))
+		return 0, err
+	}
+
+	defer func() {
+		if err != nil {
+			err = tx.Rollback(ctx)
+			if err != nil {
+				s.log.Error("failed to rollback transaction", zap.Error(err))
+			}
+		}
+	}()
+
+	holdRepo := s.holdRepo.WithTx(tx)
+	holds, err := holdRepo.GetExpiredHolds(ctx, now, limit)
+	if err != nil {
+		s.log.Error("failed to get expired holds", zap.Error(err))
+		return 0, err
+	}
+	for _, hold := range holds {
+		if err = s.settleHold(ctx, holdRepo, hold, payment.HoldStatusExpired); err != nil {
+			s.log.Error("failed to expire hold", zap.String("hold_id", hold.ID.String()), zap.Error(err))
+			return 0, err
+		}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		s.log.Error("failed to commit transaction", zap.Error(err))
+		return 0, err
+	}
+
+	return len(holds), nil
+}
+
+// getActiveHold блокирует холд до конца транзакции и проверяет, что он
+// ещё активен.
+func (s *Service) getActiveHold(
+	ctx context.Context,
+	holdRepo payment.HoldRepository,
+	id uuid.UUID,
+) (*payment.Hold, error) {
+	hold, err := holdRepo.GetHoldForUpdate(ctx, id)
+	if err != nil {
+		if pg.IsNotFound(err) {
+			return nil, ErrHoldNotFound
+		}
+		return nil, err
+	}
+	if !hold.IsActive() {
+		return nil, ErrHoldNotActive
+	}
+	return hold, nil
+}
+
+// settleHold снимает резерв холда с баланса и переводит холд в status.
+func (s *Service) settleHold(
+	ctx context.Context,
+	holdRepo payment.HoldRepository,
+	hold *payment.Hold,
+	status payment.HoldStatus,
+) error {
+	if _, err := holdRepo.UnreserveBalance(ctx, hold.TelegramUserID, hold.Currency, hold.Amount); err != nil {
+		return err
+	}
+	settled, err := holdRepo.SettleHold(ctx, hold.ID, status)
+	if err != nil {
+		return err
+	}
+	*hold = *settled
+	return nil
+}
@@ -0,0 +1,478 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/adapter/pg"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/payment"
+	"github.com/peterparker2005/giftduels/packages/tonamount-go"
+	"github.com/shopspring/decimal"
+)
+
+type fakeHoldRepo struct {
+	payment.HoldRepository
+
+	store *store
+	// beforeReserve срабатывает перед резервированием, имитируя
+	// параллельное изменение баланса
+	beforeReserve func()
+}
+
+func (r *fakeHoldRepo) WithTx(pgx.Tx) payment.HoldRepository {
+	return r
+}
+
+// ReserveBalance, как и SQL-запрос, резервирует сумму, только если её
+// хватает на доступном балансе.
+func (r *fakeHoldRepo) ReserveBalance(
+	_ context.Context,
+	telegramUserID int64,
+	currency payment.Currency,
+	amount *tonamount.TonAmount,
+) (*payment.Balance, error) {
+	if r.beforeReserve != nil {
+		r.beforeReserve()
+	}
+	st := r.store.st
+	account := payment.UserAccount(telegramUserID, currency)
+	if st.accounts[account].Sub(st.held[account]).Cmp(amount.Decimal()) < 0 {
+		return nil, pg.ErrNotFound
+	}
+	st.held[account] = st.held[account].Add(amount.Decimal())
+	return r.store.balance(account)
+}
+
+func (r *fakeHoldRepo) UnreserveBalance(
+	_ context.Context,
+	telegramUserID int64,
+	currency payment.Currency,
+	amount *tonamount.TonAmount,
+) (*payment.Balance, error) {
+	account := payment.UserAccount(telegramUserID, currency)
+	r.store.st.held[account] = r.store.st.held[account].Sub(amount.Decimal())
+	return r.store.balance(account)
+}
+
+func (r *fakeHoldRepo) CreateHold(_ context.Context, params *payment.CreateHoldParams) (*payment.Hold, error) {
+	hold := payment.Hold{
+		ID:             uuid.New(),
+		TelegramUserID: params.TelegramUserID,
+		Currency:       params.Currency,
+		Amount:         params.Amount,
+		Reason:         params.Reason,
+		Status:         payment.HoldStatusActive,
+		ExpiresAt:      params.ExpiresAt,
+	}
+	r.store.st.holds[hold.ID] = hold
+	return &hold, nil
+}
+
+func (r *fakeHoldRepo) GetHoldForUpdate(_ context.Context, id uuid.UUID) (*payment.Hold, error) {
+	hold, ok := r.store.st.holds[id]
+	if !ok {
+		return nil, pg.ErrNotFound
+	}
+	return &hold, nil
+}
+
+func (r *fakeHoldRepo) SettleHold(_ context.Context, id uuid.UUID, status payment.HoldStatus) (*payment.Hold, error) {
+	hold, ok := r.store.st.holds[id]
+	if !ok || !hold.IsActive() {
+		return nil, pg.ErrNotFound
+	}
+	now := time.Now()
+	hold.Status = status
+	hold.SettledAt = &now
+	r.store.st.holds[id] = hold
+	return &hold, nil
+}
+
+func (r *fakeHoldRepo) GetExpiredHolds(_ context.Context, now time.Time, limit int32) ([]*payment.Hold, error) {
+	var holds []*payment.Hold
+	for _, hold := range r.store.st.holds {
+		if hold.IsActive() && hold.IsExpired(now) && len(holds) < int(limit) {
+			holds = append(holds, &hold)
+		}
+	}
+	return holds, nil
+}
+
+// held возвращает зарезервированную часть TON-баланса пользователя.
+func (f *fixture) held(telegramUserID int64) string {
+	return f.store.st.held[payment.UserAccount(telegramUserID, payment.CurrencyTON)].String()
+}
+
+// expire переносит срок холда в прошлое.
+func (f *fixture) expire(id uuid.UUID) {
+	hold := f.store.st.holds[id]
+	hold.ExpiresAt = time.Now().Add(-time.Second)
+	f.store.st.holds[id] = hold
+}
+
+func TestCreateHold(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  string
+		ttl     time.Duration
+		setup   func(f *fixture)
+		wantErr error
+		// wantHeld — резерв после запроса; баланс при этом не меняется
+		wantHeld string
+		wantTTL  time.Duration
+	}{
+		{
+			name:     "default ttl",
+			amount:   "1.5",
+			wantHeld: "1.5",
+			wantTTL:  15 * time.Minute,
+		},
+		{
+			name:     "explicit ttl",
+			amount:   "1",
+			ttl:      time.Hour,
+			wantHeld: "1",
+			wantTTL:  time.Hour,
+		},
+		{
+			name:     "whole balance",
+			amount:   "2",
+			wantHeld: "2",
+			wantTTL:  15 * time.Minute,
+		},
+		{
+			name:     "ttl above maximum",
+			amount:   "1",
+			ttl:      time.Hour + time.Second,
+			wantErr:  ErrHoldTTLTooLong,
+			wantHeld: "0",
+		},
+		{
+			name:     "zero amount",
+			amount:   "0",
+			wantErr:  ErrInvalidHoldAmount,
+			wantHeld: "0",
+		},
+		{
+			name:     "more than balance",
+			amount:   "2.01",
+			wantErr:  ErrInsufficientBalance,
+			wantHeld: "0",
+		},
+		{
+			name:   "more than available after another hold",
+			amount: "1",
+			setup: func(f *fixture) {
+				f.store.st.held[payment.UserAccount(userID, payment.CurrencyTON)] = decimal.RequireFromString("1.5")
+			},
+			wantErr:  ErrInsufficientBalance,
+			wantHeld: "1.5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t)
+			f.fund(userID, "2")
+			if tt.setup != nil {
+				tt.setup(f)
+			}
+
+			before := time.Now()
+			hold, err := f.service.CreateHold(
+				context.Background(), userID, tt.amount, payment.TransactionReasonPurchase, nil, tt.ttl,
+			)
+			if got := f.held(userID); got != tt.wantHeld {
+				t.Errorf("held %s, want %s", got, tt.wantHeld)
+			}
+			if got := f.userBalance(userID); got != "2" {
+				t.Errorf("user balance %s, want 2", got)
+			}
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !hold.IsActive() || hold.Amount.String() != tt.amount {
+				t.Errorf("hold %+v, want active %s", hold, tt.amount)
+			}
+			if ttl := hold.ExpiresAt.Sub(before); ttl < tt.wantTTL || ttl > tt.wantTTL+time.Minute {
+				t.Errorf("ttl %s, want %s", ttl, tt.wantTTL)
+			}
+		})
+	}
+}
+
+func TestSettleHold(t *testing.T) {
+	tests := []struct {
+		name string
+		// setup готовит холд на 1.5 с баланса 2
+		setup       func(t *testing.T, f *fixture, id uuid.UUID)
+		settle      func(f *fixture, id string) error
+		wantErr     error
+		wantStatus  payment.HoldStatus
+		wantBalance string
+		wantHeld    string
+	}{
+		{
+			name:        "capture debits the held amount",
+			settle:      capture,
+			wantStatus:  payment.HoldStatusCaptured,
+			wantBalance: "0.5",
+			wantHeld:    "0",
+		},
+		{
+			name:        "release makes the amount available again",
+			settle:      release,
+			wantStatus:  payment.HoldStatusReleased,
+			wantBalance: "2",
+			wantHeld:    "0",
+		},
+		{
+			name:        "expired hold cannot be captured",
+			setup:       func(_ *testing.T, f *fixture, id uuid.UUID) { f.expire(id) },
+			settle:      capture,
+			wantErr:     ErrHoldExpired,
+			wantStatus:  payment.HoldStatusActive,
+			wantBalance: "2",
+			wantHeld:    "1.5",
+		},
+		{
+			name:        "expired hold can still be released",
+			setup:       func(_ *testing.T, f *fixture, id uuid.UUID) { f.expire(id) },
+			settle:      release,
+			wantStatus:  payment.HoldStatusReleased,
+			wantBalance: "2",
+			wantHeld:    "0",
+		},
+		{
+			name: "captured hold cannot be captured again",
+			setup: func(t *testing.T, f *fixture, id uuid.UUID) {
+				if err := capture(f, id.String()); err != nil {
+					t.Fatalf("capture: %v", err)
+				}
+			},
+			settle:      capture,
+			wantErr:     ErrHoldNotActive,
+			wantStatus:  payment.HoldStatusCaptured,
+			wantBalance: "0.5",
+			wantHeld:    "0",
+		},
+		{
+			name: "released hold cannot be captured",
+			setup: func(t *testing.T, f *fixture, id uuid.UUID) {
+				if err := release(f, id.String()); err != nil {
+					t.Fatalf("release: %v", err)
+				}
+			},
+			settle:      capture,
+			wantErr:     ErrHoldNotActive,
+			wantStatus:  payment.HoldStatusReleased,
+			wantBalance: "2",
+			wantHeld:    "0",
+		},
+		{
+			name:        "unknown hold",
+			settle:      func(f *fixture, _ string) error { return capture(f, uuid.NewString()) },
+			wantErr:     ErrHoldNotFound,
+			wantStatus:  payment.HoldStatusActive,
+			wantBalance: "2",
+			wantHeld:    "1.5",
+		},
+		{
+			name:        "malformed hold id",
+			settle:      func(f *fixture, _ string) error { return release(f, "not-a-uuid") },
+			wantErr:     ErrHoldNotFound,
+			wantStatus:  payment.HoldStatusActive,
+			wantBalance: "2",
+			wantHeld:    "1.5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t)
+			f.fund(userID, "2")
+			hold, err := f.service.CreateHold(
+				context.Background(), userID, "1.5", payment.TransactionReasonPurchase, nil, 0,
+			)
+			if err != nil {
+				t.Fatalf("create hold: %v", err)
+			}
+			if tt.setup != nil {
+				tt.setup(t, f, hold.ID)
+			}
+
+			err = tt.settle(f, hold.ID.String())
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := f.store.st.holds[hold.ID].Status; got != tt.wantStatus {
+				t.Errorf("status %s, want %s", got, tt.wantStatus)
+			}
+			if got := f.userBalance(userID); got != tt.wantBalance {
+				t.Errorf("user balance %s, want %s", got, tt.wantBalance)
+			}
+			if got := f.held(userID); got != tt.wantHeld {
+				t.Errorf("held %s, want %s", got, tt.wantHeld)
+			}
+		})
+	}
+}
+
+func capture(f *fixture, id string) error {
+	_, err := f.service.CaptureHold(context.Background(), id)
+	return err
+}
+
+func release(f *fixture, id string) error {
+	_, err := f.service.ReleaseHold(context.Background(), id)
+	return err
+}
+
+// Списание и холд, которые вместе превышают баланс, не проходят оба —
+// даже если проверки идут параллельно.
+func TestHoldAndSpendCannotOverdraw(t *testing.T) {
+	account := payment.UserAccount(userID, payment.CurrencyTON)
+	spend := func(f *fixture, amount string) error {
+		_, err := f.service.SpendUserBalance(
+			context.Background(), userID, amount, payment.TransactionReasonPurchase, nil, "",
+		)
+		return err
+	}
+	hold := func(f *fixture, amount string) error {
+		_, err := f.service.CreateHold(
+			context.Background(), userID, amount, payment.TransactionReasonPurchase, nil, 0,
+		)
+		return err
+	}
+
+	tests := []struct {
+		name        string
+		run         func(t *testing.T, f *fixture) error
+		wantBalance string
+		wantHeld    string
+	}{
+		{
+			name: "spend after hold",
+			run: func(t *testing.T, f *fixture) error {
+				if err := hold(f, "1.5"); err != nil {
+					t.Fatalf("hold: %v", err)
+				}
+				return spend(f, "1")
+			},
+			wantBalance: "2",
+			wantHeld:    "1.5",
+		},
+		{
+			name: "hold after spend",
+			run: func(t *testing.T, f *fixture) error {
+				if err := spend(f, "1"); err != nil {
+					t.Fatalf("spend: %v", err)
+				}
+				return hold(f, "1.5")
+			},
+			wantBalance: "1",
+			wantHeld:    "0",
+		},
+		{
+			// холд зарезервирован между проверкой баланса и проводкой
+			name: "hold between spend check and posting",
+			run: func(_ *testing.T, f *fixture) error {
+				f.repo.beforePost = func() {
+					f.store.concurrently(func(st *state) {
+						st.held[account] = st.held[account].Add(decimal.RequireFromString("1.5"))
+					})
+				}
+				return spend(f, "1")
+			},
+			wantBalance: "2",
+			wantHeld:    "1.5",
+		},
+		{
+			// списание прошло между проверкой холда и резервированием
+			name: "spend between hold check and reservation",
+			run: func(_ *testing.T, f *fixture) error {
+				f.holdRepo.beforeReserve = func() {
+					f.store.concurrently(func(st *state) {
+						st.accounts[account] = st.accounts[account].Sub(decimal.NewFromInt(1))
+					})
+				}
+				return hold(f, "1.5")
+			},
+			wantBalance: "1",
+			wantHeld:    "0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t)
+			f.fund(userID, "2")
+
+			if err := tt.run(t, f); !errors.Is(err, ErrInsufficientBalance) {
+				t.Fatalf("expected ErrInsufficientBalance, got %v", err)
+			}
+			if got := f.userBalance(userID); got != tt.wantBalance {
+				t.Errorf("user balance %s, want %s", got, tt.wantBalance)
+			}
+			if got := f.held(userID); got != tt.wantHeld {
+				t.Errorf("held %s, want %s", got, tt.wantHeld)
+			}
+		})
+	}
+}
+
+func TestExpireHolds(t *testing.T) {
+	f := newFixture(t)
+	f.fund(userID, "3")
+	ctx := context.Background()
+
+	var ids []uuid.UUID
+	for range 3 {
+		hold, err := f.service.CreateHold(ctx, userID, "1", payment.TransactionReasonPurchase, nil, 0)
+		if err != nil {
+			t.Fatalf("create hold: %v", err)
+		}
+		ids = append(ids, hold.ID)
+	}
+	f.expire(ids[0])
+	f.expire(ids[1])
+
+	// лимит ограничивает пачку, следующий проход добирает остаток
+	for _, want := range []int{1, 1, 0} {
+		n, err := f.service.ExpireHolds(ctx, time.Now(), 1)
+		if err != nil {
+			t.Fatalf("expire holds: %v", err)
+		}
+		if n != want {
+			t.Errorf("expired %d, want %d", n, want)
+		}
+	}
+
+	for i, want := range []payment.HoldStatus{
+		payment.HoldStatusExpired, payment.HoldStatusExpired, payment.HoldStatusActive,
+	} {
+		if got := f.store.st.holds[ids[i]].Status; got != want {
+			t.Errorf("hold %d status %s, want %s", i, got, want)
+		}
+	}
+	if got := f.held(userID); got != "1" {
+		t.Errorf("held %s, want 1", got)
+	}
+	if got := f.userBalance(userID); got != "3" {
+		t.Errorf("user balance %s, want 3", got)
+	}
+}
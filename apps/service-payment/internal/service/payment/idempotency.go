@@ -0,0 +1,103 @@
+package payment
+
+import (
+	"context"
+
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/adapter/pg"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/payment"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+	"go.uber.org/zap"
+)
+
+// mutateBalance выполняет mutate в транзакции БД. Если задан ключ
+// идемпотентности, результат сохраняется вместе с ключом в той же
+// транзакции, а повторный запрос с этим ключом получает исходный результат
+// без новой проводки. Ключ, повторно использованный с другими параметрами,
+// отклоняется.
+func (s *Service) mutateBalance(
+	ctx context.Context,
+	idempotencyKey string,
+	req *payment.BalanceRequest,
+	mutate func(repo payment.Repository) (*payment.Balance, error),
+) (*payment.Balance, error) {
+	log := s.log.With(
+		zap.Int64("telegram_user_id", req.TelegramUserID),
+		zap.String("operation", string(req.Operation)),
+		zap.String("idempotency_key", idempotencyKey),
+	)
+
+	if idempotencyKey != "" {
+		stored, err := s.repo.GetIdempotencyKey(ctx, idempotencyKey)
+		if err == nil {
+			return s.replayBalance(log, stored, req)
+		}
+		if !pg.IsNotFound(err) {
+			log.Error("failed to get idempotency key", zap.Error(err))
+			return nil, err
+		}
+	}
+
+	tx, err := s.txMgr.BeginTx(ctx)
+	if err != nil {
+		log.Error("failed to begin transaction", zap.Error(err))
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			err = tx.Rollback(ctx)
+			if err != nil {
+				log.Error("failed to rollback transaction", zap.Error(err))
+			}
+		}
+	}()
+
+	repo := s.repo.WithTx(tx)
+	balance, err := mutate(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	if idempotencyKey != "" {
+		var saved bool
+		saved, err = repo.SaveIdempotencyKey(ctx, payment.NewIdempotencyKey(idempotencyKey, req, balance))
+		if err != nil {
+			log.Error("failed to save idempotency key", zap.Error(err))
+			return nil, err
+		}
+		if !saved {
+			// параллельный запрос с тем же ключом закоммитился раньше —
+			// отменяем свою проводку и отдаём его результат
+			if rbErr := tx.Rollback(ctx); rbErr != nil {
+				log.Error("failed to rollback transaction", zap.Error(rbErr))
+			}
+			stored, getErr := s.repo.GetIdempotencyKey(ctx, idempotencyKey)
+			if getErr != nil {
+				log.Error("failed to get idempotency key", zap.Error(getErr))
+				return nil, getErr
+			}
+			return s.replayBalance(log, stored, req)
+		}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		log.Error("failed to commit transaction", zap.Error(err))
+		return nil, err
+	}
+
+	return balance, nil
+}
+
+func (s *Service) replayBalance(
+	log *logger.Logger,
+	stored *payment.IdempotencyKey,
+	req *payment.BalanceRequest,
+) (*payment.Balance, error) {
+	if !stored.Matches(req) {
+		log.Warn("idempotency key reused with different parameters")
+		return nil, ErrIdempotencyKeyReused
+	}
+	log.Info("replaying idempotent balance operation")
+	return stored.Balance(), nil
+}
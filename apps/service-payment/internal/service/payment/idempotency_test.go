@@ -0,0 +1,209 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/payment"
+	"github.com/peterparker2005/giftduels/packages/tonamount-go"
+)
+
+const idempotencyKey = "purchase:gift-1"
+
+func mustAmount(t *testing.T, s string) *tonamount.TonAmount {
+	t.Helper()
+	amount, err := tonamount.NewTonAmountFromString(s)
+	if err != nil {
+		t.Fatalf("amount %q: %v", s, err)
+	}
+	return amount
+}
+
+func TestBalanceIdempotency(t *testing.T) {
+	purchase := func(amount, key string) func(f *fixture) (*payment.Balance, error) {
+		return func(f *fixture) (*payment.Balance, error) {
+			return f.service.SpendUserBalance(
+				context.Background(), userID, amount, payment.TransactionReasonPurchase, nil, key,
+			)
+		}
+	}
+
+	tests := []struct {
+		name string
+		// setup выполняется после первого списания 0.5 с ключом idempotencyKey
+		setup       func(f *fixture)
+		repeat      func(f *fixture) (*payment.Balance, error)
+		wantErr     error
+		wantResult  string
+		wantBalance string
+		wantEntries int
+	}{
+		{
+			name:        "replay returns the original result",
+			repeat:      purchase("0.5", idempotencyKey),
+			wantResult:  "1.5",
+			wantBalance: "1.5",
+			wantEntries: 1,
+		},
+		{
+			name: "replay after later operations returns the original result",
+			setup: func(f *fixture) {
+				if _, err := purchase("1", "")(f); err != nil {
+					t.Fatalf("spend: %v", err)
+				}
+			},
+			repeat:      purchase("0.5", idempotencyKey),
+			wantResult:  "1.5",
+			wantBalance: "0.5",
+			wantEntries: 2,
+		},
+		{
+			name:        "different amount is rejected",
+			repeat:      purchase("0.6", idempotencyKey),
+			wantErr:     ErrIdempotencyKeyReused,
+			wantBalance: "1.5",
+			wantEntries: 1,
+		},
+		{
+			name: "different reason is rejected",
+			repeat: func(f *fixture) (*payment.Balance, error) {
+				return f.service.SpendUserBalance(
+					context.Background(), userID, "0.5", payment.TransactionReasonWithdraw, nil, idempotencyKey,
+				)
+			},
+			wantErr:     ErrIdempotencyKeyReused,
+			wantBalance: "1.5",
+			wantEntries: 1,
+		},
+		{
+			name: "different operation is rejected",
+			repeat: func(f *fixture) (*payment.Balance, error) {
+				return f.service.AddUserBalance(
+					context.Background(), userID, "0.5", payment.TransactionReasonPurchase, nil, idempotencyKey,
+				)
+			},
+			wantErr:     ErrIdempotencyKeyReused,
+			wantBalance: "1.5",
+			wantEntries: 1,
+		},
+		{
+			name:        "another key is a new operation",
+			repeat:      purchase("0.5", "purchase:gift-2"),
+			wantResult:  "1",
+			wantBalance: "1",
+			wantEntries: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t)
+			f.fund(userID, "2")
+			if _, err := purchase("0.5", idempotencyKey)(f); err != nil {
+				t.Fatalf("first spend: %v", err)
+			}
+			if tt.setup != nil {
+				tt.setup(f)
+			}
+
+			balance, err := tt.repeat(f)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if balance.TonAmount.String() != tt.wantResult {
+					t.Errorf("result %s, want %s", balance.TonAmount, tt.wantResult)
+				}
+			}
+			if got := f.userBalance(userID); got != tt.wantBalance {
+				t.Errorf("user balance %s, want %s", got, tt.wantBalance)
+			}
+			if len(f.store.st.entries) != tt.wantEntries {
+				t.Errorf("entries %d, want %d", len(f.store.st.entries), tt.wantEntries)
+			}
+		})
+	}
+}
+
+// Без ключа повтор запроса — это новое списание.
+func TestSpendWithoutIdempotencyKeyIsNotDeduplicated(t *testing.T) {
+	f := newFixture(t)
+	f.fund(userID, "2")
+
+	for range 2 {
+		if _, err := f.service.SpendUserBalance(
+			context.Background(), userID, "0.5", payment.TransactionReasonPurchase, nil, "",
+		); err != nil {
+			t.Fatalf("spend: %v", err)
+		}
+	}
+	if got := f.userBalance(userID); got != "1" {
+		t.Errorf("user balance %s, want 1", got)
+	}
+	if len(f.store.keys) != 0 {
+		t.Errorf("keys %v, want none", f.store.keys)
+	}
+}
+
+// Если параллельный запрос с тем же ключом закоммитился между проверкой
+// ключа и его сохранением, своя проводка откатывается, а клиент получает
+// результат победителя.
+func TestConcurrentIdempotentSpendPostsOnce(t *testing.T) {
+	tests := []struct {
+		name       string
+		amount     string
+		wantErr    error
+		wantResult string
+	}{
+		{name: "same request replays the winner", amount: "0.5", wantResult: "1.5"},
+		{name: "conflicting request is rejected", amount: "0.7", wantErr: ErrIdempotencyKeyReused},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t)
+			f.fund(userID, "2")
+			// победитель списал 0.5 и сохранил ключ; его проводка уже в БД
+			winner := &payment.BalanceRequest{
+				Operation:      payment.IdempotencyOperationSpend,
+				TelegramUserID: userID,
+				Amount:         mustAmount(t, "0.5"),
+				Reason:         payment.TransactionReasonPurchase,
+			}
+			f.repo.beforeSaveKey = func() {
+				f.repo.beforeSaveKey = nil
+				f.store.keys[idempotencyKey] = *payment.NewIdempotencyKey(
+					idempotencyKey, winner, &payment.Balance{TonAmount: mustAmount(t, "1.5")},
+				)
+			}
+
+			balance, err := f.service.SpendUserBalance(
+				context.Background(), userID, tt.amount, payment.TransactionReasonPurchase, nil, idempotencyKey,
+			)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if balance.TonAmount.String() != tt.wantResult {
+					t.Errorf("result %s, want %s", balance.TonAmount, tt.wantResult)
+				}
+			}
+			// своя проводка откатилась
+			if got := f.userBalance(userID); got != "2" {
+				t.Errorf("user balance %s, want 2", got)
+			}
+			if len(f.store.st.entries) != 0 {
+				t.Errorf("entries %d, want 0", len(f.store.st.entries))
+			}
+		})
+	}
+}
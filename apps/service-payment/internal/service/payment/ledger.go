@@ -0,0 +1,44 @@
+package payment
+
+import (
+	"context"
+
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/payment"
+)
+
+// LedgerReport — результат проверки инвариантов журнала.
+type LedgerReport struct {
+	// UnbalancedEntries — записи, проводки которых не сходятся в ноль.
+	UnbalancedEntries []*payment.UnbalancedEntry
+	// BalanceMismatches — кэшированные балансы, расходящиеся с журналом.
+	BalanceMismatches []*payment.BalanceMismatch
+	SystemBalances    []*payment.SystemBalance
+}
+
+// OK сообщает, что нарушений не найдено.
+func (r *LedgerReport) OK() bool {
+	return len(r.UnbalancedEntries) == 0 && len(r.BalanceMismatches) == 0
+}
+
+// CheckLedger проверяет, что каждая запись журнала сбалансирована и что
+// кэшированные балансы пользователей совпадают с суммой их проводок.
+// Каждого вида нарушений возвращается не больше limit.
+func (s *Service) CheckLedger(ctx context.Context, limit int32) (*LedgerReport, error) {
+	unbalanced, err := s.repo.GetUnbalancedEntries(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	mismatches, err := s.repo.GetBalanceMismatches(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	system, err := s.repo.GetSystemBalances(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &LedgerReport{
+		UnbalancedEntries: unbalanced,
+		BalanceMismatches: mismatches,
+		SystemBalances:    system,
+	}, nil
+}
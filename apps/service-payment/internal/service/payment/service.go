@@ -3,74 +3,87 @@ package payment
 import (
 	"context"
 	"encoding/json"
-	"math"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/peterparker2005/giftduels/apps/service-payment/internal/adapter/pg"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/config"
 	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/payment"
 	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/ton"
 	"github.com/peterparker2005/giftduels/packages/logger-go"
-	"github.com/peterparker2005/giftduels/packages/shared"
 	"github.com/peterparker2005/giftduels/packages/tonamount-go"
 	"go.uber.org/zap"
 )
 
-const (
-	// Минимальное базовое кол-во звёзд.
-	baseStarsCommission = 25.0
-
-	// Максимальное кол-во звёзд.
-	// maxStarsCommission = 250.0
-	maxStarsCommission = 1.0
-
-	// Сколько TON стоит одна звезда.
-	tonPerStar = 0.2678 / 50.0
-
-	// Какой процент от стоимости подарка мы берём в виде комиссии (в звёздах)
-	// Например, 5% от стоимости подарка.
-	commissionRate = 0.15
-)
-
 type Service struct {
-	log     *logger.Logger
-	repo    payment.Repository
-	tonRepo ton.DepositRepository
-	txMgr   pg.TxManager
+	log            *logger.Logger
+	repo           payment.Repository
+	holdRepo       payment.HoldRepository
+	feeRepo        payment.FeeScheduleRepository
+	tonRepo        ton.DepositRepository
+	withdrawalRepo ton.WithdrawalRepository
+	addressRepo    ton.DepositAddressRepository
+	deriver        ton.AddressDeriver
+	rates          payment.RateProvider
+	txMgr          pg.TxManager
+	cfg            *config.Config
 }
 
 func NewService(
 	repo payment.Repository,
+	holdRepo payment.HoldRepository,
+	feeRepo payment.FeeScheduleRepository,
 	tonRepo ton.DepositRepository,
+	withdrawalRepo ton.WithdrawalRepository,
+	addressRepo ton.DepositAddressRepository,
+	deriver ton.AddressDeriver,
+	rates payment.RateProvider,
 	log *logger.Logger,
 	txMgr pg.TxManager,
+	cfg *config.Config,
 ) *Service {
 	return &Service{
-		log:     log,
-		repo:    repo,
-		tonRepo: tonRepo,
-		txMgr:   txMgr,
+		log:            log,
+		repo:           repo,
+		holdRepo:       holdRepo,
+		feeRepo:        feeRepo,
+		tonRepo:        tonRepo,
+		withdrawalRepo: withdrawalRepo,
+		addressRepo:    addressRepo,
+		deriver:        deriver,
+		rates:          rates,
+		txMgr:          txMgr,
+		cfg:            cfg,
 	}
 }
 
+// CreateDeposit создаёт депозит на amount в валюте currency.
+// Депозиты в USDT доступны, только если задан мастер-контракт джеттона.
 func (s *Service) CreateDeposit(
 	ctx context.Context,
 	telegramUserID int64,
+	currency payment.Currency,
 	amount string,
 ) (*ton.Deposit, error) {
+	if currency == payment.CurrencyUSDT && !s.cfg.Ton.USDT.Enabled() {
+		return nil, ErrCurrencyNotSupported
+	}
+
 	rawPayload := uuid.New().String()
 	tonAmount, err := tonamount.NewTonAmountFromString(amount)
 	if err != nil {
 		return nil, err
 	}
-	nanoAmount, err := tonAmount.ToNano()
+	nanoAmount, err := currency.UnitsFromAmount(tonAmount)
 	if err != nil {
 		return nil, err
 	}
-	expiresAt := time.Now().Add(time.Hour)
+	expiresAt := time.Now().Add(s.cfg.Ton.Deposit.TTL)
 
 	params := &ton.CreateDepositParams{
 		TelegramUserID: telegramUserID,
+		Currency:       currency,
 		AmountNano:     nanoAmount,
 		Payload:        rawPayload, // просто UUID
 		ExpiresAt:      expiresAt,
@@ -78,32 +91,119 @@ func (s *Service) CreateDeposit(
 	return s.tonRepo.CreateDeposit(ctx, params)
 }
 
+// ProcessDepositTransaction принимает платёж по депозиту: запоминает
+// фактически полученную сумму и мастерблок mcSeqNo, на котором платёж
+// замечен. Баланс пополняется позже, в ConfirmDeposits.
+// Платёж по истёкшему депозиту тоже принимается.
 func (s *Service) ProcessDepositTransaction(
 	ctx context.Context,
-	payload, txHash string,
-	txLt uint64,
-	amount *tonamount.TonAmount,
+	payload string,
+	tx ton.Transaction,
+	mcSeqNo uint32,
 ) error {
+	log := s.log.With(
+		zap.String("payload", payload),
+		zap.String("sender", tx.Sender),
+		zap.String("tx_hash", tx.Hash),
+		zap.Uint64("tx_lt", tx.LastLT),
+		zap.String("amount", tx.Amount.String()),
+		zap.String("currency", tx.Currency),
+	)
+
 	deposit, err := s.tonRepo.GetDepositByPayload(ctx, payload)
 	if err != nil {
 		return err
 	}
 
-	if deposit.Status != ton.DepositStatusPending {
-		// Or log and ignore
+	if string(deposit.Currency) != tx.Currency {
+		// Оплата не в той валюте: сумму не пересчитываем, разбирает поддержка
+		log.Warn("deposit paid in another currency",
+			zap.String("deposit_id", deposit.ID.String()),
+			zap.String("deposit_currency", string(deposit.Currency)),
+		)
 		return nil
 	}
 
-	amountNano, err := amount.ToNano()
+	if !deposit.CanReceive() {
+		if deposit.TxLt == nil || *deposit.TxLt != tx.LastLT {
+			// Повторный платёж по тому же payload: оставляем для разбора поддержкой
+			log.Warn("payment for already processed deposit",
+				zap.String("deposit_id", deposit.ID.String()),
+				zap.String("status", string(deposit.Status)),
+			)
+		}
+		return nil
+	}
+
+	_, err = s.tonRepo.SetDepositTransaction(ctx, &ton.SetDepositTransactionParams{
+		ID:                 deposit.ID.String(),
+		TxHash:             tx.Hash,
+		TxLt:               tx.LastLT,
+		ReceivedAmountNano: tx.Units,
+		SenderAddress:      tx.Sender,
+		ReceivedMcSeqno:    mcSeqNo,
+	})
 	if err != nil {
+		if ton.IsDepositAlreadyProcessed(err) {
+			// депозит обработан параллельно
+			return nil
+		}
 		return err
 	}
 
-	if deposit.AmountNano > amountNano {
-		// Handle partial payment, for now, we ignore
-		return nil
+	log.Info("deposit received",
+		zap.String("deposit_id", deposit.ID.String()),
+		zap.Uint32("mc_seqno", mcSeqNo),
+	)
+	return nil
+}
+
+// ConfirmDeposits подтверждает до limit принятых депозитов, замеченных
+// не меньше чем depth мастерблоков назад, и пополняет баланс фактически
+// полученной суммой. Возвращает число подтверждённых депозитов.
+func (s *Service) ConfirmDeposits(
+	ctx context.Context,
+	currentMcSeqNo, depth uint32,
+	limit int32,
+) (int, error) {
+	if currentMcSeqNo < depth {
+		return 0, nil
+	}
+	deposits, err := s.tonRepo.GetDepositsToConfirm(ctx, currentMcSeqNo-depth, limit)
+	if err != nil {
+		return 0, err
 	}
 
+	confirmed := 0
+	for _, deposit := range deposits {
+		err = s.confirmDeposit(ctx, deposit)
+		if ton.IsDepositAlreadyProcessed(err) {
+			continue
+		}
+		if err != nil {
+			return confirmed, err
+		}
+		confirmed++
+	}
+	return confirmed, nil
+}
+
+func (s *Service) confirmDeposit(ctx context.Context, deposit *ton.Deposit) error {
+	log := s.log.With(zap.String("deposit_id", deposit.ID.String()))
+
+	if deposit.ReceivedAmountNano == nil {
+		return fmt.Errorf("deposit %s has no received amount", deposit.ID)
+	}
+	received, err := deposit.Currency.AmountFromUnits(*deposit.ReceivedAmountNano)
+	if err != nil {
+		return err
+	}
+	currency, amount, metadata, err := s.depositCredit(ctx, deposit, received)
+	if err != nil {
+		return err
+	}
+	status := deposit.ConfirmedStatus()
+
 	tx, err := s.txMgr.BeginTx(ctx)
 	if err != nil {
 		return err
@@ -113,66 +213,109 @@ func (s *Service) ProcessDepositTransaction(
 		if err != nil {
 			err = tx.Rollback(ctx)
 			if err != nil {
-				s.log.Error("failed to rollback transaction", zap.Error(err))
+				log.Error("failed to rollback transaction", zap.Error(err))
 			}
 		}
 	}()
 
-	repo := s.repo.WithTx(tx)
-	tonRepo := s.tonRepo.WithTx(tx)
-
-	_, err = tonRepo.SetDepositTransaction(ctx, &ton.SetDepositTransactionParams{
-		ID:     deposit.ID.String(),
-		TxHash: txHash,
-		TxLt:   txLt,
-	})
+	_, err = s.tonRepo.WithTx(tx).ConfirmDeposit(ctx, deposit.ID.String(), status)
 	if err != nil {
 		return err
 	}
 
-	// Используем domain для конвертации нано в TON
-	tonAmount, err := tonamount.NewTonAmountFromNano(amountNano)
+	_, err = s.addUserBalance(
+		ctx,
+		s.repo.WithTx(tx),
+		deposit.TelegramUserID,
+		currency,
+		amount,
+		payment.TransactionReasonDeposit,
+		payment.AccountTypeTreasury,
+		metadata,
+	)
 	if err != nil {
 		return err
 	}
 
-	err = repo.CreateTransaction(ctx, &payment.CreateTransactionParams{
-		TelegramUserID: deposit.TelegramUserID,
-		Amount:         tonAmount,
-		Reason:         payment.TransactionReasonDeposit,
-	})
+	err = tx.Commit(ctx)
 	if err != nil {
 		return err
 	}
 
-	addBalanceParams := &payment.AddUserBalanceParams{
-		TelegramUserID: deposit.TelegramUserID,
-		Amount:         tonAmount,
+	log.Info("deposit credited",
+		zap.String("status", string(status)),
+		zap.String("deposit_currency", string(deposit.Currency)),
+		zap.Uint64("requested_nano", deposit.AmountNano),
+		zap.Uint64("received_nano", *deposit.ReceivedAmountNano),
+		zap.String("credited", amount.String()+" "+string(currency)),
+	)
+	return nil
+}
+
+// depositCredit определяет, в какой валюте и сколько зачислить по депозиту.
+// Джеттоны зачисляются на отдельный баланс или, если включена конвертация,
+// на TON-баланс по текущему курсу.
+func (s *Service) depositCredit(
+	ctx context.Context,
+	deposit *ton.Deposit,
+	received *tonamount.TonAmount,
+) (payment.Currency, *tonamount.TonAmount, *payment.TransactionMetadata, error) {
+	metadata := payment.NewDepositMetadata(deposit.ID.String(), deposit.Currency, received)
+	if deposit.Currency == payment.CurrencyTON || !s.cfg.Ton.USDT.ConvertToTon {
+		return deposit.Currency, received, metadata, nil
 	}
 
-	_, err = repo.AddUserBalance(ctx, addBalanceParams)
+	rate, err := s.rates.Rate(ctx, deposit.Currency, payment.CurrencyTON)
 	if err != nil {
-		return err
+		return "", nil, nil, err
 	}
-
-	err = tx.Commit(ctx)
+	converted, err := tonamount.NewTonAmountFromString(received.Decimal().Mul(rate).String())
 	if err != nil {
-		return err
+		return "", nil, nil, err
 	}
+	metadata.Deposit.Rate = rate.String()
+	return payment.CurrencyTON, converted, metadata, nil
+}
 
-	return nil
+// ExpireDeposits помечает истёкшими до limit просроченных депозитов.
+func (s *Service) ExpireDeposits(ctx context.Context, now time.Time, limit int32) (int64, error) {
+	return s.tonRepo.ExpireDeposits(ctx, now, limit)
 }
 
+// GetBalance возвращает игровой баланс пользователя в TON.
 func (s *Service) GetBalance(ctx context.Context, telegramUserID int64) (*payment.Balance, error) {
-	return s.repo.GetUserBalance(ctx, telegramUserID)
+	return s.repo.GetUserBalance(ctx, telegramUserID, payment.CurrencyTON)
 }
 
+// GetBalances возвращает балансы пользователя во всех валютах.
+// TON-баланс есть всегда, даже нулевой.
+func (s *Service) GetBalances(ctx context.Context, telegramUserID int64) ([]*payment.Balance, error) {
+	balances, err := s.repo.GetUserBalances(ctx, telegramUserID)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range balances {
+		if b.Currency == payment.CurrencyTON {
+			return balances, nil
+		}
+	}
+	ton := &payment.Balance{
+		TelegramUserID: telegramUserID,
+		Currency:       payment.CurrencyTON,
+		TonAmount:      tonamount.Zero(),
+	}
+	return append([]*payment.Balance{ton}, balances...), nil
+}
+
+// SpendUserBalance списывает amount с TON-баланса. Если задан
+// idempotencyKey, повтор запроса возвращает исходный результат.
 func (s *Service) SpendUserBalance(
 	ctx context.Context,
 	telegramUserID int64,
 	amount string,
 	reason payment.TransactionReason,
 	metadata *payment.TransactionMetadata,
+	idempotencyKey string,
 ) (*payment.Balance, error) {
 	log := s.log.With(
 		zap.Int64("telegram_user_id", telegramUserID),
@@ -186,75 +329,57 @@ func (s *Service) SpendUserBalance(
 		return nil, err
 	}
 
-	tx, err := s.txMgr.BeginTx(ctx)
-	if err != nil {
-		log.Error("failed to begin transaction", zap.Error(err))
-		return nil, err
-	}
-
-	defer func() {
-		if err != nil {
-			err = tx.Rollback(ctx)
-			if err != nil {
-				log.Error("failed to rollback transaction", zap.Error(err))
-			}
-		}
-	}()
-
-	repo := s.repo.WithTx(tx)
-
-	currentBalance, err := repo.GetUserBalance(ctx, telegramUserID)
-	if err != nil {
-		return nil, err
-	}
-
-	if currentBalance.TonAmount.Decimal().Cmp(tonAmount.Decimal()) < 0 {
-		return nil, ErrInsufficientBalance
-	}
-
-	balance, err := repo.SpendUserBalance(ctx, &payment.SpendUserBalanceParams{
+	req := &payment.BalanceRequest{
+		Operation:      payment.IdempotencyOperationSpend,
 		TelegramUserID: telegramUserID,
 		Amount:         tonAmount,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	var metadataBytes []byte
-	if metadata != nil {
-		metadataBytes, err = json.Marshal(metadata)
-		if err != nil {
-			log.Error("failed to marshal metadata", zap.Error(err))
-			// не возвращаем ошибку, просто логируем
-		}
-	}
-
-	negativeAmount := tonAmount.Negate()
-	err = repo.CreateTransaction(ctx, &payment.CreateTransactionParams{
-		TelegramUserID: telegramUserID,
-		Amount:         negativeAmount,
 		Reason:         reason,
-		Metadata:       metadataBytes,
+		Metadata:       s.marshalMetadata(metadata),
+	}
+	return s.mutateBalance(ctx, idempotencyKey, req, func(repo payment.Repository) (*payment.Balance, error) {
+		return s.spendUserBalance(
+			ctx,
+			repo,
+			telegramUserID,
+			payment.CurrencyTON,
+			tonAmount,
+			reason,
+			payment.CounterAccount(reason, metadata),
+			metadata,
+		)
 	})
-	if err != nil {
-		return nil, err
-	}
-
-	err = tx.Commit(ctx)
-	if err != nil {
-		log.Error("failed to commit transaction", zap.Error(err))
-		return nil, err
-	}
-
-	return balance, nil
 }
 
+// AddUserBalance зачисляет amount на TON-баланс. Системный счёт, за счёт
+// которого идёт зачисление, определяется по причине. Если задан
+// idempotencyKey, повтор запроса возвращает исходный результат.
 func (s *Service) AddUserBalance(
 	ctx context.Context,
 	telegramUserID int64,
 	amount string,
 	reason payment.TransactionReason,
 	metadata *payment.TransactionMetadata,
+	idempotencyKey string,
+) (*payment.Balance, error) {
+	return s.creditUserBalance(
+		ctx,
+		telegramUserID,
+		amount,
+		reason,
+		payment.CounterAccount(reason, metadata),
+		metadata,
+		idempotencyKey,
+	)
+}
+
+func (s *Service) creditUserBalance(
+	ctx context.Context,
+	telegramUserID int64,
+	amount string,
+	reason payment.TransactionReason,
+	counter payment.AccountType,
+	metadata *payment.TransactionMetadata,
+	idempotencyKey string,
 ) (*payment.Balance, error) {
 	log := s.log.With(zap.Int64("telegram_user_id", telegramUserID), zap.String("amount", amount))
 
@@ -264,123 +389,111 @@ func (s *Service) AddUserBalance(
 		return nil, err
 	}
 
-	tx, err := s.txMgr.BeginTx(ctx)
-	if err != nil {
-		log.Error("failed to begin transaction", zap.Error(err))
-		return nil, err
-	}
-
-	defer func() {
-		if err != nil {
-			err = tx.Rollback(ctx)
-			if err != nil {
-				log.Error("failed to rollback transaction", zap.Error(err))
-			}
-		}
-	}()
-
-	repo := s.repo.WithTx(tx)
-
-	balance, err := repo.AddUserBalance(ctx, &payment.AddUserBalanceParams{
-		TelegramUserID: telegramUserID,
-		Amount:         tonAmount,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	var metadataBytes []byte
-	if metadata != nil {
-		metadataBytes, err = json.Marshal(metadata)
-		if err != nil {
-			log.Error("failed to marshal metadata", zap.Error(err))
-			// не возвращаем ошибку, просто логируем
-		}
-	}
-
-	err = repo.CreateTransaction(ctx, &payment.CreateTransactionParams{
+	req := &payment.BalanceRequest{
+		Operation:      payment.IdempotencyOperationAdd,
 		TelegramUserID: telegramUserID,
 		Amount:         tonAmount,
 		Reason:         reason,
-		Metadata:       metadataBytes,
+		Metadata:       s.marshalMetadata(metadata),
+	}
+	return s.mutateBalance(ctx, idempotencyKey, req, func(repo payment.Repository) (*payment.Balance, error) {
+		return s.addUserBalance(
+			ctx,
+			repo,
+			telegramUserID,
+			payment.CurrencyTON,
+			tonAmount,
+			reason,
+			counter,
+			metadata,
+		)
 	})
+}
+
+// spendUserBalance списывает amount с баланса в валюте currency в пользу
+// системного счёта counter через repo, привязанный к открытой транзакции БД.
+func (s *Service) spendUserBalance(
+	ctx context.Context,
+	repo payment.Repository,
+	telegramUserID int64,
+	currency payment.Currency,
+	amount *tonamount.TonAmount,
+	reason payment.TransactionReason,
+	counter payment.AccountType,
+	metadata *payment.TransactionMetadata,
+) (*payment.Balance, error) {
+	currentBalance, err := repo.GetUserBalance(ctx, telegramUserID, currency)
 	if err != nil {
 		return nil, err
 	}
 
-	err = tx.Commit(ctx)
-	if err != nil {
-		log.Error("failed to commit transaction", zap.Error(err))
-		return nil, err
+	// зарезервированное холдами списать нельзя
+	if currentBalance.Available().Decimal().Cmp(amount.Decimal()) < 0 {
+		return nil, ErrInsufficientBalance
 	}
 
-	return balance, nil
+	return s.postUserEntry(ctx, repo, payment.NewTransferEntry(
+		reason,
+		payment.UserAccount(telegramUserID, currency),
+		payment.SystemAccount(counter, currency),
+		amount.Decimal(),
+		s.marshalMetadata(metadata),
+	))
 }
 
-func (s *Service) PreviewWithdraw(
-	_ context.Context,
-	gifts []*payment.GiftWithdrawRequest,
-) (*payment.WithdrawOptions, error) {
-	var totalStarsFee uint32
-	var totalTonFee *tonamount.TonAmount
-	giftFees := make([]*payment.GiftFee, 0, len(gifts))
+// addUserBalance зачисляет amount на баланс в валюте currency за счёт
+// системного счёта counter через repo, привязанный к открытой транзакции БД.
+func (s *Service) addUserBalance(
+	ctx context.Context,
+	repo payment.Repository,
+	telegramUserID int64,
+	currency payment.Currency,
+	amount *tonamount.TonAmount,
+	reason payment.TransactionReason,
+	counter payment.AccountType,
+	metadata *payment.TransactionMetadata,
+) (*payment.Balance, error) {
+	return s.postUserEntry(ctx, repo, payment.NewTransferEntry(
+		reason,
+		payment.SystemAccount(counter, currency),
+		payment.UserAccount(telegramUserID, currency),
+		amount.Decimal(),
+		s.marshalMetadata(metadata),
+	))
+}
 
-	// Инициализируем totalTonFee нулевым значением
-	zeroTon, err := tonamount.NewTonAmountFromString("0")
+// postUserEntry проводит запись, затрагивающую баланс одного пользователя,
+// и возвращает этот баланс.
+func (s *Service) postUserEntry(
+	ctx context.Context,
+	repo payment.Repository,
+	entry *payment.JournalEntry,
+) (*payment.Balance, error) {
+	balances, err := repo.PostEntry(ctx, entry)
 	if err != nil {
+		if pg.IsNotFound(err) {
+			// баланс изменился параллельно между проверкой и списанием
+			return nil, ErrInsufficientBalance
+		}
 		return nil, err
 	}
-	totalTonFee = zeroTon
-
-	for _, gift := range gifts {
-		// Рассчитываем комиссию для каждого подарка индивидуально
-		priceFloat, _ := gift.Price.Decimal().Float64()
-		starsFee := calculateStarsCommission(priceFloat)
-		tonFee, err := calculateTonCommission(starsFee)
-		if err != nil {
-			return nil, err
-		}
-
-		giftFee := &payment.GiftFee{
-			GiftID:   gift.GiftID,
-			StarsFee: starsFee,
-			TonFee:   tonFee,
-		}
-		giftFees = append(giftFees, giftFee)
-
-		// Суммируем общие комиссии
-		totalStarsFee += starsFee
-		totalTonFee = totalTonFee.Add(tonFee)
+	if len(balances) != 1 {
+		return nil, fmt.Errorf("journal entry touched %d user balances", len(balances))
 	}
-
-	return &payment.WithdrawOptions{
-		GiftFees:      giftFees,
-		TotalStarsFee: totalStarsFee,
-		TotalTonFee:   totalTonFee,
-	}, nil
+	return balances[0], nil
 }
 
-func calculateStarsCommission(giftTonPrice float64) uint32 {
-	giftStars := giftTonPrice / tonPerStar
-	raw := giftStars * commissionRate
-
-	if raw < baseStarsCommission {
-		raw = baseStarsCommission
+func (s *Service) marshalMetadata(metadata *payment.TransactionMetadata) []byte {
+	if metadata == nil {
+		return nil
 	}
-	if raw > maxStarsCommission {
-		raw = maxStarsCommission
+	metadataBytes, err := json.Marshal(metadata)
+	if err != nil {
+		// не возвращаем ошибку, просто логируем
+		s.log.Error("failed to marshal metadata", zap.Error(err))
+		return nil
 	}
-	return uint32(math.Ceil(raw))
-}
-
-func calculateTonCommission(stars uint32) (*tonamount.TonAmount, error) {
-	// 1) считаем «сырое» значение в TON
-	raw := float64(stars) * tonPerStar
-	// 2) округляем до 2 знаков
-	//nolint:mnd // 2 decimal places
-	raw = math.Round(raw*100) / 100
-	// 3) создаём доменный TonAmount с проверкой и округлением внутри
-	return tonamount.NewTonAmountFromFloat64(raw)
+	return metadataBytes
 }
 
 func (s *Service) RollbackWithdrawalCommission(
@@ -397,12 +510,21 @@ func (s *Service) RollbackWithdrawalCommission(
 		return err
 	}
 
-	_, err = s.AddUserBalance(
+	// вывод за Stars не списывал TON-комиссию — возвращать нечего
+	if tonAmount.IsZero() {
+		log.Info("zero withdrawal commission, nothing to roll back")
+		return nil
+	}
+
+	// комиссия возвращается со счёта комиссий, куда она поступила
+	_, err = s.creditUserBalance(
 		ctx,
 		telegramUserID,
 		tonAmount.String(),
 		payment.TransactionReasonRefund,
+		payment.AccountTypeFees,
 		&metadata,
+		"",
 	)
 	if err != nil {
 		log.Error("failed to add user balance", zap.Error(err))
@@ -411,23 +533,3 @@ func (s *Service) RollbackWithdrawalCommission(
 
 	return nil
 }
-
-func (s *Service) GetTransactionHistory(
-	ctx context.Context,
-	telegramUserID int64,
-	pagination *shared.PageRequest,
-) ([]*payment.Transaction, int64, error) {
-	count, err := s.repo.GetUserTransactionsCount(ctx, telegramUserID)
-	if err != nil {
-		s.log.Error("failed to get user transactions count", zap.Error(err))
-		return nil, 0, err
-	}
-
-	transactions, err := s.repo.GetUserTransactions(ctx, telegramUserID, pagination)
-	if err != nil {
-		s.log.Error("failed to get user transactions", zap.Error(err))
-		return nil, 0, err
-	}
-
-	return transactions, count, nil
-}
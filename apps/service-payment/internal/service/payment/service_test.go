@@ -0,0 +1,585 @@
+package payment
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/adapter/pg"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/config"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/payment"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/ton"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+	"github.com/peterparker2005/giftduels/packages/tonamount-go"
+	"github.com/shopspring/decimal"
+)
+
+const userID int64 = 42
+
+// state — содержимое фейковой БД. Значения в картах хранятся по значению,
+// чтобы копия состояния не делила их с оригиналом.
+type state struct {
+	accounts    map[payment.LedgerAccount]decimal.Decimal
+	held        map[payment.LedgerAccount]decimal.Decimal
+	entries     []payment.JournalEntry
+	withdrawals map[string]ton.Withdrawal
+	deposits    map[string]ton.Deposit
+	addresses   map[int64]ton.DepositAddress
+	subwalletID uint64
+	holds       map[uuid.UUID]payment.Hold
+}
+
+func (s *state) clone() *state {
+	return &state{
+		accounts:    maps.Clone(s.accounts),
+		held:        maps.Clone(s.held),
+		entries:     slices.Clone(s.entries),
+		withdrawals: maps.Clone(s.withdrawals),
+		deposits:    maps.Clone(s.deposits),
+		addresses:   maps.Clone(s.addresses),
+		subwalletID: s.subwalletID,
+		holds:       maps.Clone(s.holds),
+	}
+}
+
+// store — фейковая БД. Транзакция снимает копию состояния при BeginTx и
+// восстанавливает её при Rollback, так что откат отменяет всё, что сервис
+// успел записать.
+type store struct {
+	st *state
+	// keys — ключи идемпотентности. Сервис читает их вне транзакции, а
+	// сохраняет непосредственно перед коммитом, поэтому откат их не трогает.
+	keys map[string]payment.IdempotencyKey
+	txs  []*fakeTx
+}
+
+func (s *store) balance(account payment.LedgerAccount) (*payment.Balance, error) {
+	amount, ok := s.st.accounts[account]
+	if !ok {
+		return nil, pg.ErrNotFound
+	}
+	total, err := tonamount.NewTonAmountFromString(amount.String())
+	if err != nil {
+		return nil, err
+	}
+	held, err := tonamount.NewTonAmountFromString(s.st.held[account].String())
+	if err != nil {
+		return nil, err
+	}
+	return &payment.Balance{
+		TelegramUserID: account.TelegramUserID,
+		Currency:       account.Currency,
+		TonAmount:      total,
+		HeldAmount:     held,
+	}, nil
+}
+
+// concurrently применяет fn так, будто её закоммитила параллельная
+// транзакция: изменения переживают откат уже открытых транзакций.
+func (s *store) concurrently(fn func(st *state)) {
+	fn(s.st)
+	for _, tx := range s.txs {
+		if !tx.closed {
+			fn(tx.snapshot)
+		}
+	}
+}
+
+type fakeTx struct {
+	pgx.Tx
+
+	store    *store
+	snapshot *state
+	closed   bool
+}
+
+func (t *fakeTx) Commit(context.Context) error {
+	if t.closed {
+		return pgx.ErrTxClosed
+	}
+	t.closed = true
+	return nil
+}
+
+func (t *fakeTx) Rollback(context.Context) error {
+	if t.closed {
+		return pgx.ErrTxClosed
+	}
+	t.closed = true
+	t.store.st = t.snapshot
+	return nil
+}
+
+type fakeTxManager struct {
+	store *store
+}
+
+func (m *fakeTxManager) BeginTx(context.Context) (pgx.Tx, error) {
+	tx := &fakeTx{store: m.store, snapshot: m.store.st.clone()}
+	m.store.txs = append(m.store.txs, tx)
+	return tx, nil
+}
+
+type fakeRepo struct {
+	payment.Repository
+
+	store *store
+	// beforeSaveKey срабатывает перед сохранением ключа идемпотентности,
+	// имитируя параллельный запрос с тем же ключом
+	beforeSaveKey func()
+	// beforePost срабатывает между проверкой баланса и проводкой, имитируя
+	// параллельное изменение баланса
+	beforePost func()
+}
+
+func (r *fakeRepo) WithTx(pgx.Tx) payment.Repository {
+	return r
+}
+
+func (r *fakeRepo) GetUserBalance(
+	_ context.Context,
+	telegramUserID int64,
+	currency payment.Currency,
+) (*payment.Balance, error) {
+	return r.store.balance(payment.UserAccount(telegramUserID, currency))
+}
+
+func (r *fakeRepo) GetIdempotencyKey(_ context.Context, key string) (*payment.IdempotencyKey, error) {
+	stored, ok := r.store.keys[key]
+	if !ok {
+		return nil, pg.ErrNotFound
+	}
+	return &stored, nil
+}
+
+func (r *fakeRepo) SaveIdempotencyKey(_ context.Context, key *payment.IdempotencyKey) (bool, error) {
+	if r.beforeSaveKey != nil {
+		r.beforeSaveKey()
+	}
+	if _, ok := r.store.keys[key.Key]; ok {
+		return false, nil
+	}
+	r.store.keys[key.Key] = *key
+	return true, nil
+}
+
+// PostEntry, как и SQL-запрос списания, не даёт балансу пользователя
+// опуститься ниже зарезервированной суммы.
+func (r *fakeRepo) PostEntry(_ context.Context, entry *payment.JournalEntry) ([]*payment.Balance, error) {
+	if err := entry.Validate(); err != nil {
+		return nil, err
+	}
+	if r.beforePost != nil {
+		r.beforePost()
+	}
+	st := r.store.st
+	for _, p := range entry.Postings {
+		if p.Account.Type != payment.AccountTypeUser || !p.Amount.IsNegative() {
+			continue
+		}
+		if st.accounts[p.Account].Sub(st.held[p.Account]).Add(p.Amount).IsNegative() {
+			return nil, pg.ErrNotFound
+		}
+	}
+
+	var balances []*payment.Balance
+	for _, p := range entry.Postings {
+		st.accounts[p.Account] = st.accounts[p.Account].Add(p.Amount)
+		if p.Account.Type != payment.AccountTypeUser {
+			continue
+		}
+		balance, err := r.store.balance(p.Account)
+		if err != nil {
+			return nil, err
+		}
+		balances = append(balances, balance)
+	}
+	st.entries = append(st.entries, *entry)
+	return balances, nil
+}
+
+type fakeWithdrawalRepo struct {
+	ton.WithdrawalRepository
+
+	store *store
+}
+
+func (r *fakeWithdrawalRepo) WithTx(pgx.Tx) ton.WithdrawalRepository {
+	return r
+}
+
+func (r *fakeWithdrawalRepo) CreateWithdrawal(
+	_ context.Context,
+	params *ton.CreateWithdrawalParams,
+) (*ton.Withdrawal, error) {
+	w := ton.Withdrawal{
+		ID:             uuid.New(),
+		TelegramUserID: params.TelegramUserID,
+		Destination:    params.Destination,
+		AmountNano:     params.AmountNano,
+		Status:         ton.WithdrawalStatusPending,
+	}
+	r.store.st.withdrawals[w.ID.String()] = w
+	return &w, nil
+}
+
+func (r *fakeWithdrawalRepo) FailWithdrawal(_ context.Context, id, reason string) (*ton.Withdrawal, error) {
+	w, ok := r.store.st.withdrawals[id]
+	if !ok || w.Status == ton.WithdrawalStatusConfirmed || w.Status == ton.WithdrawalStatusFailed {
+		return nil, ton.ErrWithdrawalNotFound
+	}
+	w.Status = ton.WithdrawalStatusFailed
+	w.FailureReason = &reason
+	r.store.st.withdrawals[id] = w
+	return &w, nil
+}
+
+type fakeDepositRepo struct {
+	ton.DepositRepository
+
+	store *store
+}
+
+func (r *fakeDepositRepo) WithTx(pgx.Tx) ton.DepositRepository {
+	return r
+}
+
+func (r *fakeDepositRepo) GetDepositByPayload(_ context.Context, payload string) (*ton.Deposit, error) {
+	for _, d := range r.store.st.deposits {
+		if d.Payload == payload {
+			return &d, nil
+		}
+	}
+	return nil, ton.ErrDepositNotFound
+}
+
+func (r *fakeDepositRepo) CreateReceivedDeposit(
+	_ context.Context,
+	params *ton.CreateReceivedDepositParams,
+) (*ton.Deposit, error) {
+	for _, d := range r.store.st.deposits {
+		if d.Payload == params.Payload() {
+			return nil, ton.ErrDepositAlreadyProcessed
+		}
+	}
+	d := ton.Deposit{
+		ID:                 uuid.New(),
+		TelegramUserID:     params.TelegramUserID,
+		Status:             ton.DepositStatusReceived,
+		Currency:           params.Currency,
+		AmountNano:         params.AmountNano,
+		Payload:            params.Payload(),
+		TxHash:             &params.TxHash,
+		TxLt:               &params.TxLt,
+		ReceivedAmountNano: &params.AmountNano,
+		ReceivedMcSeqno:    &params.ReceivedMcSeqno,
+		DepositAddress:     &params.DepositAddress,
+	}
+	r.store.st.deposits[d.ID.String()] = d
+	return &d, nil
+}
+
+func (r *fakeDepositRepo) SetDepositTransaction(
+	_ context.Context,
+	params *ton.SetDepositTransactionParams,
+) (*ton.Deposit, error) {
+	d, ok := r.store.st.deposits[params.ID]
+	if !ok || !d.CanReceive() {
+		return nil, ton.ErrDepositAlreadyProcessed
+	}
+	d.Status = ton.DepositStatusReceived
+	d.TxHash = &params.TxHash
+	d.TxLt = &params.TxLt
+	d.ReceivedAmountNano = &params.ReceivedAmountNano
+	d.SenderAddress = &params.SenderAddress
+	d.ReceivedMcSeqno = &params.ReceivedMcSeqno
+	r.store.st.deposits[params.ID] = d
+	return &d, nil
+}
+
+func (r *fakeDepositRepo) GetDepositsToConfirm(
+	_ context.Context,
+	maxMcSeqno uint32,
+	limit int32,
+) ([]*ton.Deposit, error) {
+	var deposits []*ton.Deposit
+	for _, d := range r.store.st.deposits {
+		if d.Status == ton.DepositStatusReceived && *d.ReceivedMcSeqno <= maxMcSeqno &&
+			len(deposits) < int(limit) {
+			deposits = append(deposits, &d)
+		}
+	}
+	return deposits, nil
+}
+
+func (r *fakeDepositRepo) ConfirmDeposit(
+	_ context.Context,
+	id string,
+	status ton.DepositStatus,
+) (*ton.Deposit, error) {
+	d, ok := r.store.st.deposits[id]
+	if !ok || d.Status != ton.DepositStatusReceived {
+		return nil, ton.ErrDepositAlreadyProcessed
+	}
+	d.Status = status
+	r.store.st.deposits[id] = d
+	return &d, nil
+}
+
+type fixture struct {
+	store    *store
+	cfg      *config.Config
+	repo     *fakeRepo
+	holdRepo *fakeHoldRepo
+	service  *Service
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	log, err := logger.NewLogger(logger.Config{Service: "test", Level: "fatal"})
+	if err != nil {
+		t.Fatalf("logger: %v", err)
+	}
+
+	st := &store{st: &state{
+		accounts:    map[payment.LedgerAccount]decimal.Decimal{},
+		held:        map[payment.LedgerAccount]decimal.Decimal{},
+		withdrawals: map[string]ton.Withdrawal{},
+		deposits:    map[string]ton.Deposit{},
+		addresses:   map[int64]ton.DepositAddress{},
+		holds:       map[uuid.UUID]payment.Hold{},
+	}, keys: map[string]payment.IdempotencyKey{}}
+	cfg := &config.Config{}
+	cfg.Ton.Network = config.TonNetworkMainnet
+	cfg.Ton.Withdrawal.MinAmount = "0.1"
+	cfg.Holds.DefaultTTL = 15 * time.Minute
+	cfg.Holds.MaxTTL = time.Hour
+
+	f := &fixture{store: st, cfg: cfg, repo: &fakeRepo{store: st}, holdRepo: &fakeHoldRepo{store: st}}
+	f.service = NewService(
+		f.repo,
+		f.holdRepo,
+		nil,
+		&fakeDepositRepo{store: st},
+		&fakeWithdrawalRepo{store: st},
+		&fakeAddressRepo{store: st},
+		fakeDeriver{},
+		nil,
+		log,
+		&fakeTxManager{store: st},
+		cfg,
+	)
+	return f
+}
+
+// fund заводит пользователю TON-баланс amount.
+func (f *fixture) fund(telegramUserID int64, amount string) {
+	f.store.st.accounts[payment.UserAccount(telegramUserID, payment.CurrencyTON)] = decimal.RequireFromString(amount)
+}
+
+// account возвращает сальдо счёта.
+func (f *fixture) account(account payment.LedgerAccount) string {
+	return f.store.st.accounts[account].String()
+}
+
+func (f *fixture) userBalance(telegramUserID int64) string {
+	return f.account(payment.UserAccount(telegramUserID, payment.CurrencyTON))
+}
+
+func (f *fixture) systemBalance(accountType payment.AccountType) string {
+	return f.account(payment.SystemAccount(accountType, payment.CurrencyTON))
+}
+
+// addDeposit заводит депозит на requestedNano нанотонов.
+func (f *fixture) addDeposit(status ton.DepositStatus, requestedNano uint64) ton.Deposit {
+	d := ton.Deposit{
+		ID:             uuid.New(),
+		TelegramUserID: userID,
+		Status:         status,
+		Currency:       payment.CurrencyTON,
+		AmountNano:     requestedNano,
+		Payload:        "dep-" + uuid.NewString(),
+	}
+	f.store.st.deposits[d.ID.String()] = d
+	return d
+}
+
+func (f *fixture) deposit(id uuid.UUID) ton.Deposit {
+	return f.store.st.deposits[id.String()]
+}
+
+func paymentTx(t *testing.T, payload, currency string, units, lt uint64) ton.Transaction {
+	t.Helper()
+	amount, err := tonamount.NewTonAmountFromNano(units)
+	if err != nil {
+		t.Fatalf("amount: %v", err)
+	}
+	return ton.Transaction{
+		Sender:   "sender",
+		Amount:   amount,
+		Units:    units,
+		Currency: currency,
+		Payload:  payload,
+		Hash:     "hash",
+		LastLT:   lt,
+	}
+}
+
+func TestDepositPartialAndOverpayment(t *testing.T) {
+	const requested = 1_000_000_000
+
+	tests := []struct {
+		name         string
+		status       ton.DepositStatus
+		currency     string
+		paidNano     uint64
+		wantStatus   ton.DepositStatus
+		wantCredited string
+	}{
+		{
+			name:         "exact payment",
+			status:       ton.DepositStatusPending,
+			paidNano:     requested,
+			wantStatus:   ton.DepositStatusConfirmed,
+			wantCredited: "1",
+		},
+		{
+			name:         "underpayment credits what arrived",
+			status:       ton.DepositStatusPending,
+			paidNano:     400_000_000,
+			wantStatus:   ton.DepositStatusPartial,
+			wantCredited: "0.4",
+		},
+		{
+			name:         "overpayment credits what arrived",
+			status:       ton.DepositStatusPending,
+			paidNano:     2_500_000_000,
+			wantStatus:   ton.DepositStatusConfirmed,
+			wantCredited: "2.5",
+		},
+		{
+			name:         "payment after expiry is accepted",
+			status:       ton.DepositStatusExpired,
+			paidNano:     requested,
+			wantStatus:   ton.DepositStatusConfirmed,
+			wantCredited: "1",
+		},
+		{
+			name:         "payment in another currency is left to support",
+			status:       ton.DepositStatusPending,
+			currency:     string(payment.CurrencyUSDT),
+			paidNano:     requested,
+			wantStatus:   ton.DepositStatusPending,
+			wantCredited: "0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t)
+			ctx := context.Background()
+			d := f.addDeposit(tt.status, requested)
+			currency := tt.currency
+			if currency == "" {
+				currency = string(payment.CurrencyTON)
+			}
+
+			tx := paymentTx(t, d.Payload, currency, tt.paidNano, 1)
+			if err := f.service.ProcessDepositTransaction(ctx, d.Payload, tx, 10); err != nil {
+				t.Fatalf("process: %v", err)
+			}
+			if _, err := f.service.ConfirmDeposits(ctx, 10, 0, 10); err != nil {
+				t.Fatalf("confirm: %v", err)
+			}
+
+			if got := f.deposit(d.ID).Status; got != tt.wantStatus {
+				t.Errorf("status %s, want %s", got, tt.wantStatus)
+			}
+			if got := f.userBalance(userID); got != tt.wantCredited {
+				t.Errorf("credited %s, want %s", got, tt.wantCredited)
+			}
+			// зачисление идёт за счёт казначейства: журнал сходится
+			wantTreasury := decimal.RequireFromString(tt.wantCredited).Neg().String()
+			if got := f.systemBalance(payment.AccountTypeTreasury); got != wantTreasury {
+				t.Errorf("treasury %s, want %s", got, wantTreasury)
+			}
+		})
+	}
+}
+
+// Второй платёж по тому же payload не перезаписывает первый и не
+// зачисляется: его разбирает поддержка.
+func TestDepositSecondPaymentIsIgnored(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	d := f.addDeposit(ton.DepositStatusPending, 1_000_000_000)
+
+	first := paymentTx(t, d.Payload, "TON", 1_000_000_000, 1)
+	if err := f.service.ProcessDepositTransaction(ctx, d.Payload, first, 10); err != nil {
+		t.Fatalf("first payment: %v", err)
+	}
+	second := paymentTx(t, d.Payload, "TON", 3_000_000_000, 2)
+	if err := f.service.ProcessDepositTransaction(ctx, d.Payload, second, 11); err != nil {
+		t.Fatalf("second payment: %v", err)
+	}
+	if got := *f.deposit(d.ID).TxLt; got != 1 {
+		t.Errorf("deposit tx lt %d, want the first payment", got)
+	}
+
+	if _, err := f.service.ConfirmDeposits(ctx, 20, 0, 10); err != nil {
+		t.Fatalf("confirm: %v", err)
+	}
+	if got := f.userBalance(userID); got != "1" {
+		t.Errorf("credited %s, want only the first payment", got)
+	}
+}
+
+func TestConfirmDepositsWaitsForDepth(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	d := f.addDeposit(ton.DepositStatusPending, 1_000_000_000)
+
+	tx := paymentTx(t, d.Payload, "TON", 1_000_000_000, 1)
+	if err := f.service.ProcessDepositTransaction(ctx, d.Payload, tx, 100); err != nil {
+		t.Fatalf("process: %v", err)
+	}
+	// депозит хранит настоящий хеш и lt транзакции и мастерблок, на котором
+	// её заметили
+	received := f.deposit(d.ID)
+	if received.Status != ton.DepositStatusReceived ||
+		*received.TxHash != "hash" || *received.TxLt != 1 || *received.ReceivedMcSeqno != 100 {
+		t.Fatalf("received deposit %+v, want tx hash/1 at 100", received)
+	}
+
+	steps := []struct {
+		mcSeqNo       uint32
+		wantConfirmed int
+		wantBalance   string
+	}{
+		// глубина 5: платёж на 100-м мастерблоке подтверждается на 105-м
+		{mcSeqNo: 3, wantBalance: "0"},
+		{mcSeqNo: 104, wantBalance: "0"},
+		{mcSeqNo: 105, wantConfirmed: 1, wantBalance: "1"},
+		// повторный проход не зачисляет депозит второй раз
+		{mcSeqNo: 106, wantBalance: "1"},
+	}
+	for _, step := range steps {
+		confirmed, err := f.service.ConfirmDeposits(ctx, step.mcSeqNo, 5, 10)
+		if err != nil {
+			t.Fatalf("confirm at %d: %v", step.mcSeqNo, err)
+		}
+		if confirmed != step.wantConfirmed {
+			t.Errorf("at %d confirmed %d, want %d", step.mcSeqNo, confirmed, step.wantConfirmed)
+		}
+		if got := f.userBalance(userID); got != step.wantBalance {
+			t.Errorf("at %d balance %s, want %s", step.mcSeqNo, got, step.wantBalance)
+		}
+	}
+	if got := f.deposit(d.ID); got.Status != ton.DepositStatusConfirmed || *got.TxHash != "hash" {
+		t.Errorf("confirmed deposit %+v, want confirmed with tx hash", got)
+	}
+}
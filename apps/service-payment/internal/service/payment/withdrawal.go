@@ -0,0 +1,147 @@
+package payment
+
+import (
+	"context"
+
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/config"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/payment"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/ton"
+	"github.com/peterparker2005/giftduels/packages/tonamount-go"
+	"go.uber.org/zap"
+)
+
+// WithdrawTon списывает amount с баланса и ставит перевод на destination
+// в очередь. Перевод отправляет tonwithdrawal.Processor.
+func (s *Service) WithdrawTon(
+	ctx context.Context,
+	telegramUserID int64,
+	destination, amount string,
+) (*ton.Withdrawal, *payment.Balance, error) {
+	log := s.log.With(
+		zap.Int64("telegram_user_id", telegramUserID),
+		zap.String("destination", destination),
+		zap.String("amount", amount),
+	)
+
+	destination, err := ton.ParseDestination(destination, s.cfg.Ton.Network != config.TonNetworkMainnet)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tonAmount, err := tonamount.NewTonAmountFromString(amount)
+	if err != nil {
+		return nil, nil, err
+	}
+	minAmount, err := tonamount.NewTonAmountFromString(s.cfg.Ton.Withdrawal.MinAmount)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tonAmount.Decimal().LessThan(minAmount.Decimal()) {
+		return nil, nil, ErrWithdrawalAmountTooSmall
+	}
+	amountNano, err := tonAmount.ToNano()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tx, err := s.txMgr.BeginTx(ctx)
+	if err != nil {
+		log.Error("failed to begin transaction", zap.Error(err))
+		return nil, nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			err = tx.Rollback(ctx)
+			if err != nil {
+				log.Error("failed to rollback transaction", zap.Error(err))
+			}
+		}
+	}()
+
+	withdrawal, err := s.withdrawalRepo.WithTx(tx).CreateWithdrawal(ctx, &ton.CreateWithdrawalParams{
+		TelegramUserID: telegramUserID,
+		Destination:    destination,
+		AmountNano:     amountNano,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	balance, err := s.spendUserBalance(
+		ctx,
+		s.repo.WithTx(tx),
+		telegramUserID,
+		payment.CurrencyTON,
+		tonAmount,
+		payment.TransactionReasonTonWithdrawal,
+		payment.AccountTypeTreasury,
+		payment.NewTonWithdrawalMetadata(withdrawal.ID.String(), destination),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		log.Error("failed to commit transaction", zap.Error(err))
+		return nil, nil, err
+	}
+
+	log.Info("ton withdrawal created", zap.String("withdrawal_id", withdrawal.ID.String()))
+	return withdrawal, balance, nil
+}
+
+// FailTonWithdrawal помечает вывод как неудавшийся и возвращает сумму на
+// баланс. Для уже завершённого вывода возвращает ton.ErrWithdrawalNotFound.
+func (s *Service) FailTonWithdrawal(ctx context.Context, withdrawalID, reason string) error {
+	log := s.log.With(zap.String("withdrawal_id", withdrawalID), zap.String("reason", reason))
+
+	tx, err := s.txMgr.BeginTx(ctx)
+	if err != nil {
+		log.Error("failed to begin transaction", zap.Error(err))
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			err = tx.Rollback(ctx)
+			if err != nil {
+				log.Error("failed to rollback transaction", zap.Error(err))
+			}
+		}
+	}()
+
+	withdrawal, err := s.withdrawalRepo.WithTx(tx).FailWithdrawal(ctx, withdrawalID, reason)
+	if err != nil {
+		return err
+	}
+
+	tonAmount, err := tonamount.NewTonAmountFromNano(withdrawal.AmountNano)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.addUserBalance(
+		ctx,
+		s.repo.WithTx(tx),
+		withdrawal.TelegramUserID,
+		payment.CurrencyTON,
+		tonAmount,
+		payment.TransactionReasonRefund,
+		payment.AccountTypeTreasury,
+		payment.NewTonWithdrawalMetadata(withdrawalID, withdrawal.Destination),
+	)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		log.Error("failed to commit transaction", zap.Error(err))
+		return err
+	}
+
+	log.Info("ton withdrawal refunded")
+	return nil
+}
@@ -0,0 +1,133 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/payment"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/ton"
+	"github.com/xssnick/tonutils-go/address"
+)
+
+const destination = "EQDtFpEwcFAEcRe5mLVh2N6C0x-_hJEM7W61_JLnSF74p4q2"
+
+func TestWithdrawTon(t *testing.T) {
+	testnetOnly := address.MustParseAddr(destination)
+	testnetOnly.SetTestnetOnly(true)
+
+	tests := []struct {
+		name        string
+		destination string
+		amount      string
+		wantErr     error
+		wantBalance string
+	}{
+		{
+			name:        "balance is debited",
+			destination: destination,
+			amount:      "1.5",
+			wantBalance: "0.5",
+		},
+		{
+			name:        "min amount is inclusive",
+			destination: destination,
+			amount:      "0.1",
+			wantBalance: "1.9",
+		},
+		{
+			name:        "below min amount",
+			destination: destination,
+			amount:      "0.09",
+			wantErr:     ErrWithdrawalAmountTooSmall,
+			wantBalance: "2",
+		},
+		{
+			name:        "invalid destination",
+			destination: "not-an-address",
+			amount:      "1",
+			wantErr:     ton.ErrInvalidAddress,
+			wantBalance: "2",
+		},
+		{
+			name:        "testnet destination on mainnet",
+			destination: testnetOnly.String(),
+			amount:      "1",
+			wantErr:     ton.ErrInvalidAddress,
+			wantBalance: "2",
+		},
+		{
+			name:        "insufficient balance",
+			destination: destination,
+			amount:      "2.01",
+			wantErr:     ErrInsufficientBalance,
+			wantBalance: "2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t)
+			f.fund(userID, "2")
+
+			w, balance, err := f.service.WithdrawTon(context.Background(), userID, tt.destination, tt.amount)
+			if got := f.userBalance(userID); got != tt.wantBalance {
+				t.Errorf("user balance %s, want %s", got, tt.wantBalance)
+			}
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				// откат не оставляет вывода без списания
+				if len(f.store.st.withdrawals) != 0 {
+					t.Errorf("withdrawals %v, want none", f.store.st.withdrawals)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if balance.TonAmount.String() != tt.wantBalance {
+				t.Errorf("returned balance %s, want %s", balance.TonAmount, tt.wantBalance)
+			}
+			if w.Status != ton.WithdrawalStatusPending || w.Destination != destination {
+				t.Errorf("withdrawal %+v, want pending to %s", w, destination)
+			}
+			// сумма ушла в казначейство, откуда её отправит процессор
+			if got := f.systemBalance(payment.AccountTypeTreasury); got != tt.amount {
+				t.Errorf("treasury %s, want %s", got, tt.amount)
+			}
+		})
+	}
+}
+
+func TestFailTonWithdrawalRefundsOnce(t *testing.T) {
+	f := newFixture(t)
+	f.fund(userID, "2")
+	ctx := context.Background()
+
+	w, _, err := f.service.WithdrawTon(ctx, userID, destination, "1.5")
+	if err != nil {
+		t.Fatalf("withdraw: %v", err)
+	}
+
+	if err = f.service.FailTonWithdrawal(ctx, w.ID.String(), "bounced"); err != nil {
+		t.Fatalf("fail withdrawal: %v", err)
+	}
+	if got := f.userBalance(userID); got != "2" {
+		t.Errorf("user balance after refund %s, want 2", got)
+	}
+	if got := f.systemBalance(payment.AccountTypeTreasury); got != "0" {
+		t.Errorf("treasury after refund %s, want 0", got)
+	}
+
+	// повторная отметка не возвращает сумму второй раз
+	err = f.service.FailTonWithdrawal(ctx, w.ID.String(), "bounced")
+	if !errors.Is(err, ton.ErrWithdrawalNotFound) {
+		t.Fatalf("expected ErrWithdrawalNotFound, got %v", err)
+	}
+	if got := f.userBalance(userID); got != "2" {
+		t.Errorf("user balance after second fail %s, want 2", got)
+	}
+}
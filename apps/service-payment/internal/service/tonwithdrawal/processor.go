@@ -0,0 +1,249 @@
+package tonwithdrawal
+
+import (
+	"context"
+	"time"
+
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/config"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/ton"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/service/payment"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+	"go.uber.org/zap"
+)
+
+const (
+	// trackLimit — сколько отправленных выводов проверяется за один проход.
+	trackLimit = 100
+	// expiryGrace — запас после ValidUntil на отставание лайтсерверов.
+	expiryGrace = time.Minute
+)
+
+// Refunder возвращает сумму неудавшегося вывода на баланс.
+type Refunder interface {
+	FailTonWithdrawal(ctx context.Context, withdrawalID, reason string) error
+}
+
+// Processor отправляет выводы TON из казначейского кошелька и следит за
+// ними до подтверждения в сети. При отказе сумма возвращается на баланс.
+//
+// Кошелёк принимает одно сообщение на seqno, поэтому новая пачка
+// подписывается только после того, как предыдущая исполнена или истекла.
+type Processor struct {
+	api       ton.API
+	repo      ton.WithdrawalRepository
+	refunder  Refunder
+	batchSize int32
+	interval  time.Duration
+	now       func() time.Time
+	cancel    context.CancelFunc
+	logger    *logger.Logger
+}
+
+func NewProcessor(
+	api ton.API,
+	repo ton.WithdrawalRepository,
+	paymentService *payment.Service,
+	cfg *config.Config,
+	logger *logger.Logger,
+) *Processor {
+	return newProcessor(api, repo, paymentService, cfg, logger)
+}
+
+func newProcessor(
+	api ton.API,
+	repo ton.WithdrawalRepository,
+	refunder Refunder,
+	cfg *config.Config,
+	logger *logger.Logger,
+) *Processor {
+	return &Processor{
+		api:       api,
+		repo:      repo,
+		refunder:  refunder,
+		batchSize: cfg.Ton.Withdrawal.BatchSize,
+		interval:  cfg.Ton.Withdrawal.PollInterval,
+		now:       time.Now,
+		logger:    logger,
+	}
+}
+
+func (p *Processor) Start() {
+	ctx := context.Background()
+	ctx, cancel := context.WithCancel(ctx)
+	p.cancel = cancel
+	go func() {
+		p.run(ctx)
+	}()
+}
+
+func (p *Processor) Stop(_ context.Context) error {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	return nil
+}
+
+func (p *Processor) run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			p.logger.Info("🛑 TON withdrawal processor stopping")
+			return
+		case <-ticker.C:
+			p.process(ctx)
+		}
+	}
+}
+
+// process проверяет отправленные выводы и, если кошелёк свободен,
+// отправляет следующую пачку.
+func (p *Processor) process(ctx context.Context) {
+	busy, err := p.trackSent(ctx)
+	if err != nil {
+		p.logger.Error("failed to track ton withdrawals", zap.Error(err))
+		return
+	}
+	if busy {
+		return
+	}
+	if err = p.sendPending(ctx); err != nil {
+		p.logger.Error("failed to send ton withdrawals", zap.Error(err))
+	}
+}
+
+// trackSent обновляет статусы отправленных выводов. Возвращает true, пока
+// есть сообщение, которое кошелёк ещё может исполнить.
+func (p *Processor) trackSent(ctx context.Context) (bool, error) {
+	sent, err := p.repo.GetWithdrawalsByStatus(ctx, ton.WithdrawalStatusSent, trackLimit)
+	if err != nil {
+		return false, err
+	}
+	if len(sent) == 0 {
+		return false, nil
+	}
+
+	seqno, err := p.api.GetWalletSeqno(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	busy := false
+	for _, w := range sent {
+		log := p.logger.With(zap.String("withdrawal_id", w.ID.String()))
+		if w.MsgHash == nil || w.WalletSeqno == nil || w.ValidUntil == nil {
+			log.Error("sent ton withdrawal has no message data")
+			continue
+		}
+
+		if seqno <= *w.WalletSeqno {
+			// Сообщение не исполнено. После ValidUntil кошелёк его уже
+			// не примет, и сумму можно вернуть.
+			if p.now().After(w.ValidUntil.Add(expiryGrace)) {
+				p.refund(ctx, w, "wallet message expired")
+				continue
+			}
+			busy = true
+			continue
+		}
+
+		p.trackExecuted(ctx, w)
+	}
+	return busy, nil
+}
+
+// trackExecuted проверяет вывод, сообщение которого кошелёк уже исполнил.
+func (p *Processor) trackExecuted(ctx context.Context, w *ton.Withdrawal) {
+	log := p.logger.With(zap.String("withdrawal_id", w.ID.String()))
+
+	status, err := p.api.GetTransferStatus(ctx, *w.MsgHash, w.Transfer())
+	if err != nil {
+		log.Warn("failed to get ton transfer status", zap.Error(err))
+		return
+	}
+
+	switch status.State {
+	case ton.TransferStateDelivered:
+		err = p.repo.ConfirmWithdrawal(ctx, &ton.ConfirmWithdrawalParams{
+			ID:     w.ID.String(),
+			TxHash: status.TxHash,
+			TxLt:   status.TxLT,
+		})
+		if err != nil && !ton.IsWithdrawalNotFound(err) {
+			log.Error("failed to confirm ton withdrawal", zap.Error(err))
+			return
+		}
+		log.Info("✅ TON withdrawal confirmed", zap.String("tx_hash", status.TxHash))
+	case ton.TransferStateBounced:
+		p.refund(ctx, w, "transfer bounced")
+	case ton.TransferStateFailed:
+		p.refund(ctx, w, "transfer was not sent by wallet")
+	case ton.TransferStateInFlight:
+		// получатель ещё не обработал перевод
+	case ton.TransferStateUnknown:
+		// Сообщение исполнено, но транзакция не нашлась: возвращать
+		// сумму нельзя, перевод мог уйти.
+		log.Warn("executed ton withdrawal transaction not found")
+	}
+}
+
+// sendPending подписывает следующую пачку выводов, сохраняет хеш сообщения
+// и только потом отправляет его: даже при ошибке отправки вывод отслеживается
+// по хешу до истечения сообщения.
+func (p *Processor) sendPending(ctx context.Context) error {
+	pending, err := p.repo.GetWithdrawalsByStatus(ctx, ton.WithdrawalStatusPending, p.batchSize)
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	transfers := make([]ton.Transfer, len(pending))
+	ids := make([]string, len(pending))
+	for i, w := range pending {
+		transfers[i] = w.Transfer()
+		ids[i] = w.ID.String()
+	}
+
+	signed, err := p.api.SignTransfers(ctx, transfers)
+	if err != nil {
+		return err
+	}
+
+	err = p.repo.MarkWithdrawalsSent(ctx, &ton.MarkWithdrawalsSentParams{
+		IDs:         ids,
+		MsgHash:     signed.MsgHash,
+		WalletSeqno: signed.WalletSeqno,
+		ValidUntil:  signed.ValidUntil,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = p.api.SendTransfers(ctx, signed); err != nil {
+		// вывод истечёт и вернётся на баланс, если сообщение не дошло
+		p.logger.Warn("failed to broadcast ton withdrawals",
+			zap.String("msg_hash", signed.MsgHash),
+			zap.Error(err),
+		)
+		return nil
+	}
+	p.logger.Info("📤 TON withdrawals sent",
+		zap.String("msg_hash", signed.MsgHash),
+		zap.Int("count", len(pending)),
+	)
+	return nil
+}
+
+func (p *Processor) refund(ctx context.Context, w *ton.Withdrawal, reason string) {
+	log := p.logger.With(zap.String("withdrawal_id", w.ID.String()), zap.String("reason", reason))
+	err := p.refunder.FailTonWithdrawal(ctx, w.ID.String(), reason)
+	if err != nil && !ton.IsWithdrawalNotFound(err) {
+		log.Error("failed to refund ton withdrawal", zap.Error(err))
+		return
+	}
+	log.Info("↩️ TON withdrawal refunded")
+}
@@ -0,0 +1,270 @@
+package tonwithdrawal
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/config"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/ton"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+)
+
+type fakeAPI struct {
+	ton.API
+
+	seqno    uint32
+	statuses map[string]ton.TransferStatus // по комментарию перевода
+	signed   [][]ton.Transfer
+	sent     []ton.SignedTransfers
+	sendErr  error
+}
+
+func (a *fakeAPI) SignTransfers(_ context.Context, transfers []ton.Transfer) (ton.SignedTransfers, error) {
+	a.signed = append(a.signed, transfers)
+	return ton.SignedTransfers{
+		MsgHash:     "hash-" + transfers[0].Comment,
+		WalletSeqno: a.seqno,
+		ValidUntil:  time.Now().Add(time.Minute),
+	}, nil
+}
+
+func (a *fakeAPI) SendTransfers(_ context.Context, signed ton.SignedTransfers) error {
+	if a.sendErr != nil {
+		return a.sendErr
+	}
+	a.sent = append(a.sent, signed)
+	return nil
+}
+
+func (a *fakeAPI) GetWalletSeqno(context.Context) (uint32, error) {
+	return a.seqno, nil
+}
+
+func (a *fakeAPI) GetTransferStatus(
+	_ context.Context,
+	_ string,
+	transfer ton.Transfer,
+) (ton.TransferStatus, error) {
+	status, ok := a.statuses[transfer.Comment]
+	if !ok {
+		return ton.TransferStatus{State: ton.TransferStateUnknown}, nil
+	}
+	return status, nil
+}
+
+type fakeRepo struct {
+	ton.WithdrawalRepository
+
+	withdrawals []*ton.Withdrawal
+}
+
+func (r *fakeRepo) WithTx(pgx.Tx) ton.WithdrawalRepository { return r }
+
+func (r *fakeRepo) get(id string) *ton.Withdrawal {
+	for _, w := range r.withdrawals {
+		if w.ID.String() == id {
+			return w
+		}
+	}
+	return nil
+}
+
+func (r *fakeRepo) GetWithdrawalsByStatus(
+	_ context.Context,
+	status ton.WithdrawalStatus,
+	limit int32,
+) ([]*ton.Withdrawal, error) {
+	var out []*ton.Withdrawal
+	for _, w := range r.withdrawals {
+		if w.Status == status && int32(len(out)) < limit {
+			out = append(out, w)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeRepo) MarkWithdrawalsSent(_ context.Context, params *ton.MarkWithdrawalsSentParams) error {
+	for _, id := range params.IDs {
+		w := r.get(id)
+		if w == nil || w.Status != ton.WithdrawalStatusPending {
+			continue
+		}
+		w.Status = ton.WithdrawalStatusSent
+		w.MsgHash = &params.MsgHash
+		w.WalletSeqno = &params.WalletSeqno
+		w.ValidUntil = &params.ValidUntil
+	}
+	return nil
+}
+
+func (r *fakeRepo) ConfirmWithdrawal(_ context.Context, params *ton.ConfirmWithdrawalParams) error {
+	w := r.get(params.ID)
+	if w == nil || w.Status != ton.WithdrawalStatusSent {
+		return ton.ErrWithdrawalNotFound
+	}
+	w.Status = ton.WithdrawalStatusConfirmed
+	w.TxHash = &params.TxHash
+	w.TxLt = &params.TxLt
+	return nil
+}
+
+type fakeRefunder struct {
+	repo     *fakeRepo
+	refunded map[string]string
+}
+
+func (f *fakeRefunder) FailTonWithdrawal(_ context.Context, withdrawalID, reason string) error {
+	w := f.repo.get(withdrawalID)
+	if w == nil || w.Status == ton.WithdrawalStatusConfirmed || w.Status == ton.WithdrawalStatusFailed {
+		return ton.ErrWithdrawalNotFound
+	}
+	w.Status = ton.WithdrawalStatusFailed
+	f.refunded[withdrawalID] = reason
+	return nil
+}
+
+type fixture struct {
+	api      *fakeAPI
+	repo     *fakeRepo
+	refunder *fakeRefunder
+	proc     *Processor
+	now      time.Time
+}
+
+func newFixture(t *testing.T, pending int) *fixture {
+	t.Helper()
+	log, err := logger.NewLogger(logger.Config{Service: "test", Level: "fatal"})
+	if err != nil {
+		t.Fatalf("logger: %v", err)
+	}
+
+	repo := &fakeRepo{}
+	for range pending {
+		repo.withdrawals = append(repo.withdrawals, &ton.Withdrawal{
+			ID:             uuid.New(),
+			TelegramUserID: 1,
+			Destination:    "EQDtFpEwcFAEcRe5mLVh2N6C0x-_hJEM7W61_JLnSF74p4q2",
+			AmountNano:     1_000_000_000,
+			Status:         ton.WithdrawalStatusPending,
+		})
+	}
+
+	f := &fixture{
+		api:      &fakeAPI{seqno: 7, statuses: map[string]ton.TransferStatus{}},
+		repo:     repo,
+		refunder: &fakeRefunder{repo: repo, refunded: map[string]string{}},
+		now:      time.Now(),
+	}
+	cfg := &config.Config{}
+	cfg.Ton.Withdrawal.BatchSize = 4
+	cfg.Ton.Withdrawal.PollInterval = time.Second
+	f.proc = newProcessor(f.api, f.repo, f.refunder, cfg, log)
+	f.proc.now = func() time.Time { return f.now }
+	return f
+}
+
+func TestProcessor_SendsPendingBatch(t *testing.T) {
+	f := newFixture(t, 5)
+
+	f.proc.process(context.Background())
+
+	if len(f.api.signed) != 1 || len(f.api.signed[0]) != 4 {
+		t.Fatalf("expected one batch of 4 transfers, got %v", f.api.signed)
+	}
+	if len(f.api.sent) != 1 {
+		t.Fatalf("expected message to be broadcast, got %d", len(f.api.sent))
+	}
+	for i, w := range f.repo.withdrawals[:4] {
+		if w.Status != ton.WithdrawalStatusSent || *w.WalletSeqno != 7 {
+			t.Fatalf("withdrawal %d: status %s, expected sent with seqno 7", i, w.Status)
+		}
+	}
+	if f.repo.withdrawals[4].Status != ton.WithdrawalStatusPending {
+		t.Fatalf("fifth withdrawal should wait for the next batch")
+	}
+}
+
+func TestProcessor_WaitsWhileMessageIsNotExecuted(t *testing.T) {
+	f := newFixture(t, 5)
+	f.proc.process(context.Background())
+
+	f.proc.process(context.Background())
+
+	if len(f.api.signed) != 1 {
+		t.Fatalf("new batch signed while previous message is pending: %d", len(f.api.signed))
+	}
+	if len(f.refunder.refunded) != 0 {
+		t.Fatalf("unexpected refunds: %v", f.refunder.refunded)
+	}
+}
+
+func TestProcessor_RefundsExpiredMessage(t *testing.T) {
+	f := newFixture(t, 1)
+	f.api.sendErr = errors.New("liteserver unavailable")
+	f.proc.process(context.Background())
+
+	w := f.repo.withdrawals[0]
+	if w.Status != ton.WithdrawalStatusSent {
+		t.Fatalf("withdrawal must be tracked after broadcast error, got %s", w.Status)
+	}
+
+	f.now = w.ValidUntil.Add(expiryGrace + time.Second)
+	f.proc.process(context.Background())
+
+	if _, ok := f.refunder.refunded[w.ID.String()]; !ok {
+		t.Fatalf("expired withdrawal was not refunded")
+	}
+}
+
+func TestProcessor_ConfirmsDeliveredTransfer(t *testing.T) {
+	f := newFixture(t, 1)
+	f.proc.process(context.Background())
+
+	w := f.repo.withdrawals[0]
+	f.api.seqno++
+	f.api.statuses[w.Comment()] = ton.TransferStatus{
+		State:  ton.TransferStateDelivered,
+		TxHash: "abc",
+		TxLT:   42,
+	}
+	f.proc.process(context.Background())
+
+	if w.Status != ton.WithdrawalStatusConfirmed || *w.TxHash != "abc" || *w.TxLt != 42 {
+		t.Fatalf("withdrawal not confirmed: %+v", w)
+	}
+}
+
+func TestProcessor_RefundsBouncedTransfer(t *testing.T) {
+	f := newFixture(t, 1)
+	f.proc.process(context.Background())
+
+	w := f.repo.withdrawals[0]
+	f.api.seqno++
+	f.api.statuses[w.Comment()] = ton.TransferStatus{State: ton.TransferStateBounced}
+	f.proc.process(context.Background())
+
+	if reason := f.refunder.refunded[w.ID.String()]; reason != "transfer bounced" {
+		t.Fatalf("bounced withdrawal not refunded, reason %q", reason)
+	}
+}
+
+func TestProcessor_DoesNotRefundExecutedUnknownTransfer(t *testing.T) {
+	f := newFixture(t, 1)
+	f.proc.process(context.Background())
+
+	w := f.repo.withdrawals[0]
+	f.api.seqno++
+	f.now = w.ValidUntil.Add(time.Hour)
+	f.proc.process(context.Background())
+
+	if w.Status != ton.WithdrawalStatusSent {
+		t.Fatalf("executed withdrawal must stay sent, got %s", w.Status)
+	}
+	if len(f.refunder.refunded) != 0 {
+		t.Fatalf("executed withdrawal refunded: %v", f.refunder.refunded)
+	}
+}
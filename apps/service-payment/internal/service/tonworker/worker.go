@@ -2,9 +2,11 @@ package tonworker
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/peterparker2005/giftduels/apps/service-payment/internal/config"
+	paymentdomain "github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/payment"
 	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/ton"
 	"github.com/peterparker2005/giftduels/apps/service-payment/internal/service/payment"
 	"github.com/peterparker2005/giftduels/apps/service-payment/pkg/boc"
@@ -12,11 +14,27 @@ import (
 	"go.uber.org/zap"
 )
 
+const (
+	// txRetryMinDelay и txRetryMaxDelay ограничивают экспоненциальную задержку
+	// между повторами транзакции, которую не удалось обработать.
+	txRetryMinDelay = time.Second
+	txRetryMaxDelay = time.Minute
+)
+
+// DepositProcessor зачисляет депозиты и откладывает несопоставленные
+// платежи; реализуется payment.Service.
+type DepositProcessor interface {
+	ProcessDepositTransaction(ctx context.Context, payload string, tx ton.Transaction, mcSeqNo uint32) error
+	RecordUnattributedDeposit(ctx context.Context, tx ton.Transaction, comment string) error
+}
+
 type Processor struct {
 	api             ton.API
 	depositRepo     ton.DepositRepository
-	paymentService  *payment.Service
+	addressRepo     ton.DepositAddressRepository
+	deposits        DepositProcessor
 	treasuryAddress string
+	jettons         []ton.Jetton
 	cancel          context.CancelFunc
 	logger          *logger.Logger
 }
@@ -24,15 +42,37 @@ type Processor struct {
 func NewProcessor(
 	api ton.API,
 	depositRepo ton.DepositRepository,
+	addressRepo ton.DepositAddressRepository,
 	paymentService *payment.Service,
 	cfg *config.Config,
 	logger *logger.Logger,
 ) *Processor {
+	return newProcessor(api, depositRepo, addressRepo, paymentService, cfg, logger)
+}
+
+func newProcessor(
+	api ton.API,
+	depositRepo ton.DepositRepository,
+	addressRepo ton.DepositAddressRepository,
+	deposits DepositProcessor,
+	cfg *config.Config,
+	logger *logger.Logger,
+) *Processor {
+	var jettons []ton.Jetton
+	if cfg.Ton.USDT.Enabled() {
+		jettons = append(jettons, ton.Jetton{
+			Currency:      string(paymentdomain.CurrencyUSDT),
+			MasterAddress: cfg.Ton.USDT.MasterAddress,
+			Decimals:      paymentdomain.CurrencyUSDT.Decimals(),
+		})
+	}
 	return &Processor{
 		api:             api,
 		depositRepo:     depositRepo,
-		paymentService:  paymentService,
+		addressRepo:     addressRepo,
+		deposits:        deposits,
 		treasuryAddress: cfg.Ton.WalletAddress,
+		jettons:         jettons,
 		logger:          logger,
 	}
 }
@@ -69,19 +109,42 @@ func (p *Processor) run(ctx context.Context) {
 			continue
 		}
 
-		p.subscribeAndProcess(ctx, lastLT, retryDelay)
+		jettonWallets, err := p.jettonWallets(ctx)
+		if err != nil {
+			p.logger.Error("failed to resolve treasury jetton wallets", zap.Error(err))
+			time.Sleep(retryDelay)
+			continue
+		}
+
+		p.subscribeAndProcess(ctx, lastLT, jettonWallets, retryDelay)
 	}
 }
 
+// jettonWallets возвращает джеттон-кошельки казначейства: уведомления
+// о переводах джеттонов приходят от них.
+func (p *Processor) jettonWallets(ctx context.Context) (map[string]ton.Jetton, error) {
+	wallets := make(map[string]ton.Jetton, len(p.jettons))
+	for _, j := range p.jettons {
+		addr, err := p.api.JettonWalletAddress(ctx, j, p.treasuryAddress)
+		if err != nil {
+			return nil, err
+		}
+		wallets[addr] = j
+	}
+	return wallets, nil
+}
+
 func (p *Processor) subscribeAndProcess(
 	ctx context.Context,
 	fromLT uint64,
+	jettonWallets map[string]ton.Jetton,
 	retryDelay time.Duration,
 ) {
 	p.logger.Info("🔍 TON Worker", zap.Uint64("fromLT", fromLT))
 
 	txCh := make(chan ton.Transaction)
-	if err := p.api.SubscribeTransactions(ctx, p.treasuryAddress, fromLT, txCh); err != nil {
+	err := p.api.SubscribeTransactions(ctx, p.treasuryAddress, fromLT, jettonWallets, txCh)
+	if err != nil {
 		p.logger.Error("subscribe error", zap.Error(err))
 		time.Sleep(retryDelay)
 		return
@@ -106,13 +169,42 @@ func (p *Processor) readLoop(
 				time.Sleep(retryDelay)
 				return
 			}
-			p.handleTx(ctx, tx)
+			if err := p.handleTxWithRetry(ctx, tx); err != nil {
+				// воркер останавливается: курсор не сдвигаем, транзакция придёт снова
+				return
+			}
 			p.saveCursor(ctx, tx.LastLT)
 		}
 	}
 }
 
-func (p *Processor) handleTx(ctx context.Context, tx ton.Transaction) {
+// handleTxWithRetry повторяет обработку транзакции с экспоненциальной
+// задержкой, пока она не пройдёт или воркер не остановится.
+func (p *Processor) handleTxWithRetry(ctx context.Context, tx ton.Transaction) error {
+	delay := txRetryMinDelay
+	for {
+		err := p.handleTx(ctx, tx)
+		if err == nil {
+			return nil
+		}
+		p.logger.Warn("failed to handle transaction, will retry",
+			zap.String("tx_hash", tx.Hash),
+			zap.Duration("delay", delay),
+			zap.Error(err),
+		)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, txRetryMaxDelay)
+	}
+}
+
+// handleTx обрабатывает входящую транзакцию. Ошибка означает, что
+// транзакцию нужно обработать повторно, не сдвигая курсор.
+func (p *Processor) handleTx(ctx context.Context, tx ton.Transaction) error {
 	p.logger.Info("🔔 Received",
 		zap.String("amount", tx.Amount.String()),
 		zap.String("currency", tx.Currency),
@@ -120,36 +212,83 @@ func (p *Processor) handleTx(ctx context.Context, tx ton.Transaction) {
 		zap.String("payload", tx.Payload),
 	)
 
-	// ранний выход вместо вложенных if
-	if tx.Payload == "" || tx.Currency != "TON" {
-		return
+	// сметание с персонального адреса уже зачислено пользователю
+	sweep, err := p.isSweep(ctx, tx)
+	if err != nil {
+		return err
+	}
+	if sweep {
+		return nil
 	}
 
-	p.processDeposit(ctx, tx)
+	// платёж без комментария сопоставить не с чем
+	if tx.Payload == "" {
+		return p.recordUnattributed(ctx, tx, "")
+	}
+
+	return p.processDeposit(ctx, tx)
 }
 
-func (p *Processor) processDeposit(ctx context.Context, tx ton.Transaction) {
-	// 1) парсим nano
-	nano, err := tx.Amount.ToNano()
+// isSweep сообщает, пришёл ли перевод с персонального адреса пополнения.
+// Ошибка хранилища возвращается: иначе сметание зачислилось бы повторно
+// как обычный депозит.
+func (p *Processor) isSweep(ctx context.Context, tx ton.Transaction) (bool, error) {
+	raw, err := ton.RawAddress(tx.Sender)
 	if err != nil {
-		p.logger.Warn("invalid amount", zap.String("amount", tx.Amount.String()), zap.Error(err))
-		return
+		return false, nil
 	}
+	_, err = p.addressRepo.GetDepositAddressByRawAddress(ctx, raw)
+	switch {
+	case err == nil:
+		return true, nil
+	case ton.IsDepositAddressNotFound(err):
+		return false, nil
+	default:
+		return false, fmt.Errorf("check deposit address: %w", err)
+	}
+}
 
-	// 2) декодируем BOC
+// processDeposit зачисляет депозит по комментарию. Окончательные исходы —
+// нечитаемый BOC и неизвестный payload — откладываются поддержке, остальные
+// ошибки возвращаются на повтор.
+func (p *Processor) processDeposit(ctx context.Context, tx ton.Transaction) error {
+	// 1) декодируем BOC
 	original, err := boc.DecodeStringFromBOC(tx.Payload)
 	if err != nil {
 		p.logger.Warn("failed to decode BOC", zap.String("payload", tx.Payload), zap.Error(err))
-		return
+		return p.recordUnattributed(ctx, tx, tx.Payload)
 	}
 	p.logger.Info("🔓 Decoded BOC", zap.String("original", original))
 
+	// 2) запоминаем мастерблок, от которого считаются подтверждения; без него
+	// депозит не зачислить, поэтому транзакцию повторяем
+	master, err := p.api.CurrentMasterchainInfo(ctx)
+	if err != nil {
+		return fmt.Errorf("get masterchain info: %w", err)
+	}
+
 	// 3) обрабатываем в сервисе
-	if err = p.paymentService.ProcessDepositTransaction(ctx, original, "", tx.LastLT, tx.Amount); err != nil {
-		p.logger.Warn("failed to process deposit", zap.String("payload", original), zap.Error(err))
-	} else {
-		p.logger.Info("✅ Deposit processed", zap.String("payload", original), zap.Uint64("amount", nano))
+	err = p.deposits.ProcessDepositTransaction(ctx, original, tx, master.SeqNo)
+	switch {
+	case ton.IsDepositNotFound(err):
+		return p.recordUnattributed(ctx, tx, original)
+	case err != nil:
+		return fmt.Errorf("process deposit: %w", err)
 	}
+	p.logger.Info("📥 Deposit received",
+		zap.String("payload", original),
+		zap.Uint64("amount", tx.Units),
+		zap.String("currency", tx.Currency),
+	)
+	return nil
+}
+
+// recordUnattributed откладывает платёж для ручного разбора поддержкой.
+func (p *Processor) recordUnattributed(ctx context.Context, tx ton.Transaction, comment string) error {
+	if err := p.deposits.RecordUnattributedDeposit(ctx, tx, comment); err != nil {
+		return fmt.Errorf("record unattributed deposit: %w", err)
+	}
+	return nil
 }
 
 func (p *Processor) saveCursor(ctx context.Context, lastLT uint64) {
@@ -0,0 +1,305 @@
+package tonworker
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/config"
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/ton"
+	"github.com/peterparker2005/giftduels/apps/service-payment/pkg/boc"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+	"github.com/xssnick/tonutils-go/address"
+)
+
+const sweepSender = "EQDtFpEwcFAEcRe5mLVh2N6C0x-_hJEM7W61_JLnSF74p4q2"
+
+var errStorage = errors.New("storage unavailable")
+
+type fakeAPI struct {
+	ton.API
+
+	masterErr error
+}
+
+func (a *fakeAPI) CurrentMasterchainInfo(context.Context) (ton.MasterchainInfo, error) {
+	if a.masterErr != nil {
+		return ton.MasterchainInfo{}, a.masterErr
+	}
+	return ton.MasterchainInfo{SeqNo: 100}, nil
+}
+
+type fakeDepositRepo struct {
+	ton.DepositRepository
+
+	cursors []uint64
+}
+
+func (r *fakeDepositRepo) UpsertCursor(_ context.Context, _, _ string, lastLT uint64) error {
+	r.cursors = append(r.cursors, lastLT)
+	return nil
+}
+
+type fakeAddressRepo struct {
+	ton.DepositAddressRepository
+
+	raw map[string]bool
+	err error
+}
+
+func (r *fakeAddressRepo) GetDepositAddressByRawAddress(
+	_ context.Context,
+	rawAddress string,
+) (*ton.DepositAddress, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	if !r.raw[rawAddress] {
+		return nil, ton.ErrDepositAddressNotFound
+	}
+	return &ton.DepositAddress{}, nil
+}
+
+type fakeDeposits struct {
+	processErr      error
+	unattributedErr error
+	// onFail вызывается при каждой ошибке зачисления
+	onFail func()
+
+	credited     []string
+	unattributed []string
+}
+
+func (d *fakeDeposits) ProcessDepositTransaction(
+	_ context.Context,
+	payload string,
+	_ ton.Transaction,
+	_ uint32,
+) error {
+	if d.processErr != nil {
+		if d.onFail != nil {
+			d.onFail()
+		}
+		return d.processErr
+	}
+	d.credited = append(d.credited, payload)
+	return nil
+}
+
+func (d *fakeDeposits) RecordUnattributedDeposit(_ context.Context, _ ton.Transaction, comment string) error {
+	if d.unattributedErr != nil {
+		return d.unattributedErr
+	}
+	d.unattributed = append(d.unattributed, comment)
+	return nil
+}
+
+type fixture struct {
+	api       *fakeAPI
+	depRepo   *fakeDepositRepo
+	addrRepo  *fakeAddressRepo
+	deposits  *fakeDeposits
+	processor *Processor
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	log, err := logger.NewLogger(logger.Config{Service: "test", Level: "fatal"})
+	if err != nil {
+		t.Fatalf("logger: %v", err)
+	}
+	raw, err := ton.RawAddress(sweepSender)
+	if err != nil {
+		t.Fatalf("raw address: %v", err)
+	}
+
+	f := &fixture{
+		api:      &fakeAPI{},
+		depRepo:  &fakeDepositRepo{},
+		addrRepo: &fakeAddressRepo{raw: map[string]bool{raw: true}},
+		deposits: &fakeDeposits{},
+	}
+	f.processor = newProcessor(f.api, f.depRepo, f.addrRepo, f.deposits, &config.Config{}, log)
+	return f
+}
+
+func depositTx(t *testing.T, comment string, lt uint64) ton.Transaction {
+	t.Helper()
+	tx := ton.Transaction{Sender: "sender", Currency: "TON", Units: 1, Hash: "hash", LastLT: lt}
+	if comment != "" {
+		payload, err := boc.EncodeStringAsBOC(comment)
+		if err != nil {
+			t.Fatalf("encode BOC: %v", err)
+		}
+		tx.Payload = payload
+	}
+	return tx
+}
+
+func TestHandleTx(t *testing.T) {
+	tests := []struct {
+		name             string
+		setup            func(f *fixture, tx *ton.Transaction)
+		comment          string
+		wantErr          bool
+		wantCredited     []string
+		wantUnattributed []string
+	}{
+		{
+			name:         "deposit credited",
+			comment:      "dep-1",
+			wantCredited: []string{"dep-1"},
+		},
+		{
+			name:    "unknown payload goes to support",
+			comment: "dep-1",
+			setup: func(f *fixture, _ *ton.Transaction) {
+				f.deposits.processErr = ton.ErrDepositNotFound
+			},
+			wantUnattributed: []string{"dep-1"},
+		},
+		{
+			name: "undecodable payload goes to support",
+			setup: func(_ *fixture, tx *ton.Transaction) {
+				tx.Payload = "not-a-boc"
+			},
+			wantUnattributed: []string{"not-a-boc"},
+		},
+		{
+			name:             "payment without comment goes to support",
+			wantUnattributed: []string{""},
+		},
+		{
+			name:    "sweep is not credited again",
+			comment: "dep-1",
+			setup: func(_ *fixture, tx *ton.Transaction) {
+				tx.Sender = sweepSender
+			},
+		},
+		{
+			// адрес отправителя сравнивается в raw-форме, флаги формата не важны
+			name:    "sweep from non-bounceable form is not credited",
+			comment: "dep-1",
+			setup: func(_ *fixture, tx *ton.Transaction) {
+				tx.Sender = address.MustParseAddr(sweepSender).Bounce(false).String()
+			},
+		},
+		{
+			name:         "payment from unknown wallet is credited",
+			comment:      "dep-1",
+			wantCredited: []string{"dep-1"},
+			setup: func(_ *fixture, tx *ton.Transaction) {
+				tx.Sender = "EQCD39VS5jcptHL8vMjEXrzGaRcCVYto7HUn4bpAOg8xqB2N"
+			},
+		},
+		{
+			name:    "deposit storage error is retried",
+			comment: "dep-1",
+			setup: func(f *fixture, _ *ton.Transaction) {
+				f.deposits.processErr = errStorage
+			},
+			wantErr: true,
+		},
+		{
+			name: "unattributed storage error is retried",
+			setup: func(f *fixture, _ *ton.Transaction) {
+				f.deposits.unattributedErr = errStorage
+			},
+			wantErr: true,
+		},
+		{
+			name:    "sweep lookup error is retried",
+			comment: "dep-1",
+			setup: func(f *fixture, tx *ton.Transaction) {
+				tx.Sender = sweepSender
+				f.addrRepo.err = errStorage
+			},
+			wantErr: true,
+		},
+		{
+			name:    "masterchain error is retried",
+			comment: "dep-1",
+			setup: func(f *fixture, _ *ton.Transaction) {
+				f.api.masterErr = errStorage
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t)
+			tx := depositTx(t, tt.comment, 1)
+			if tt.setup != nil {
+				tt.setup(f, &tx)
+			}
+
+			err := f.processor.handleTx(context.Background(), tx)
+			if tt.wantErr {
+				if !errors.Is(err, errStorage) {
+					t.Fatalf("expected storage error, got %v", err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(f.deposits.credited, tt.wantCredited) {
+				t.Errorf("credited %v, want %v", f.deposits.credited, tt.wantCredited)
+			}
+			if !slices.Equal(f.deposits.unattributed, tt.wantUnattributed) {
+				t.Errorf("unattributed %v, want %v", f.deposits.unattributed, tt.wantUnattributed)
+			}
+		})
+	}
+}
+
+// Курсор сдвигается только за обработанными транзакциями: при сбое
+// хранилища воркер повторяет транзакцию, а не пропускает её.
+func TestReadLoopKeepsCursorOnFailure(t *testing.T) {
+	f := newFixture(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	txCh := make(chan ton.Transaction, 2)
+	txCh <- depositTx(t, "dep-1", 10)
+	txCh <- depositTx(t, "dep-2", 20)
+
+	calls := 0
+	f.deposits.onFail = func() {
+		calls++
+		if calls == 2 {
+			// второй повтор не ждём: останавливаем воркер
+			cancel()
+		}
+	}
+	// первая транзакция проходит, вторая упирается в недоступное хранилище
+	f.processor.deposits = &failAfter{fakeDeposits: f.deposits, ok: 1}
+
+	f.processor.readLoop(ctx, txCh, 0)
+
+	if !slices.Equal(f.depRepo.cursors, []uint64{10}) {
+		t.Fatalf("cursors %v, want only the processed transaction", f.depRepo.cursors)
+	}
+	if calls < 2 {
+		t.Fatalf("failed deposit was attempted %d times, want a retry", calls)
+	}
+}
+
+// failAfter зачисляет первые ok депозитов, а дальше отвечает ошибкой хранилища.
+type failAfter struct {
+	*fakeDeposits
+
+	ok int
+}
+
+func (d *failAfter) ProcessDepositTransaction(
+	ctx context.Context,
+	payload string,
+	tx ton.Transaction,
+	mcSeqNo uint32,
+) error {
+	if len(d.credited) >= d.ok {
+		d.processErr = errStorage
+	}
+	return d.fakeDeposits.ProcessDepositTransaction(ctx, payload, tx, mcSeqNo)
+}
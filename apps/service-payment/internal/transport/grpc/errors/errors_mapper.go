@@ -3,6 +3,7 @@ package grpcerrors
 import (
 	"context"
 
+	"github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/ton"
 	"github.com/peterparker2005/giftduels/apps/service-payment/internal/service/payment"
 	"github.com/peterparker2005/giftduels/packages/errors"
 	errorsv1 "github.com/peterparker2005/giftduels/packages/protobuf-go/gen/giftduels/errors/v1"
@@ -18,5 +19,85 @@ func MapError(ctx context.Context, err error) error {
 			errors.WithContext(ctx),
 		)
 	}
+	if ton.IsInvalidAddress(err) {
+		return errors.NewError(
+			errors.WithGRPCCode(codes.InvalidArgument),
+			errors.WithErrorCode(errorsv1.ErrorCode_ERROR_CODE_VALIDATION_GENERAL),
+			errors.WithMessage("invalid ton address"),
+			errors.WithContext(ctx),
+		)
+	}
+	if payment.IsWithdrawalAmountTooSmall(err) {
+		return errors.NewError(
+			errors.WithGRPCCode(codes.InvalidArgument),
+			errors.WithErrorCode(errorsv1.ErrorCode_ERROR_CODE_VALIDATION_GENERAL),
+			errors.WithMessage("withdrawal amount is below minimum"),
+			errors.WithContext(ctx),
+		)
+	}
+	if payment.IsCurrencyNotSupported(err) {
+		return errors.NewError(
+			errors.WithGRPCCode(codes.InvalidArgument),
+			errors.WithErrorCode(errorsv1.ErrorCode_ERROR_CODE_VALIDATION_GENERAL),
+			errors.WithMessage("currency is not supported"),
+			errors.WithContext(ctx),
+		)
+	}
+	if payment.IsDepositAddressesDisabled(err) {
+		return errors.NewError(
+			errors.WithGRPCCode(codes.FailedPrecondition),
+			errors.WithErrorCode(errorsv1.ErrorCode_ERROR_CODE_VALIDATION_GENERAL),
+			errors.WithMessage("deposit addresses are disabled"),
+			errors.WithContext(ctx),
+		)
+	}
+	if payment.IsIdempotencyKeyReused(err) {
+		return errors.NewError(
+			errors.WithGRPCCode(codes.AlreadyExists),
+			errors.WithErrorCode(errorsv1.ErrorCode_ERROR_CODE_ALREADY_EXISTS),
+			errors.WithMessage("idempotency key reused with different parameters"),
+			errors.WithContext(ctx),
+		)
+	}
+	if payment.IsInvalidTransactionFilter(err) || payment.IsUnknownExportFormat(err) {
+		return errors.NewError(
+			errors.WithGRPCCode(codes.InvalidArgument),
+			errors.WithErrorCode(errorsv1.ErrorCode_ERROR_CODE_VALIDATION_GENERAL),
+			errors.WithMessage(err.Error()),
+			errors.WithContext(ctx),
+		)
+	}
+	if payment.IsFeeScheduleNotFound(err) {
+		return errors.NewError(
+			errors.WithGRPCCode(codes.FailedPrecondition),
+			errors.WithErrorCode(errorsv1.ErrorCode_ERROR_CODE_SERVICE_UNAVAILABLE),
+			errors.WithMessage("withdrawal fees are not configured"),
+			errors.WithContext(ctx),
+		)
+	}
+	if payment.IsInvalidHoldAmount(err) || payment.IsHoldTTLTooLong(err) {
+		return errors.NewError(
+			errors.WithGRPCCode(codes.InvalidArgument),
+			errors.WithErrorCode(errorsv1.ErrorCode_ERROR_CODE_VALIDATION_GENERAL),
+			errors.WithMessage(err.Error()),
+			errors.WithContext(ctx),
+		)
+	}
+	if payment.IsHoldNotFound(err) {
+		return errors.NewError(
+			errors.WithGRPCCode(codes.NotFound),
+			errors.WithErrorCode(errorsv1.ErrorCode_ERROR_CODE_NOT_FOUND),
+			errors.WithMessage("hold not found"),
+			errors.WithContext(ctx),
+		)
+	}
+	if payment.IsHoldNotActive(err) || payment.IsHoldExpired(err) {
+		return errors.NewError(
+			errors.WithGRPCCode(codes.FailedPrecondition),
+			errors.WithErrorCode(errorsv1.ErrorCode_ERROR_CODE_VALIDATION_GENERAL),
+			errors.WithMessage(err.Error()),
+			errors.WithContext(ctx),
+		)
+	}
 	return errors.Wrap(ctx, err)
 }
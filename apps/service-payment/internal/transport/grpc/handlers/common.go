@@ -21,8 +21,9 @@ func handlePreviewWithdraw(
 			return nil, err
 		}
 		gifts = append(gifts, &paymentdomain.GiftWithdrawRequest{
-			GiftID: giftReq.GetGiftId().GetValue(),
-			Price:  tonAmount,
+			GiftID:     giftReq.GetGiftId().GetValue(),
+			Price:      tonAmount,
+			Collection: giftReq.GetCollection(),
 		})
 	}
 
@@ -48,5 +49,6 @@ func handlePreviewWithdraw(
 		TotalTonFee: &sharedv1.TonAmount{
 			Value: resp.TotalTonFee.String(),
 		},
+		FeeScheduleVersion: resp.FeeScheduleVersion,
 	}, nil
 }
@@ -39,6 +39,7 @@ func (h *PaymentPrivateHandler) SpendUserBalance(
 		req.GetTonAmount().GetValue(),
 		reason,
 		metadata,
+		req.GetIdempotencyKey(),
 	)
 	if err != nil {
 		return nil, err
@@ -68,6 +69,7 @@ func (h *PaymentPrivateHandler) AddUserBalance(
 		req.GetTonAmount().GetValue(),
 		reason,
 		metadata,
+		req.GetIdempotencyKey(),
 	)
 	if err != nil {
 		return nil, err
@@ -91,9 +93,75 @@ func (h *PaymentPrivateHandler) GetUserBalance(
 		Amount: &sharedv1.TonAmount{
 			Value: balance.TonAmount.String(),
 		},
+		Available: &sharedv1.TonAmount{
+			Value: balance.Available().String(),
+		},
+		Held: &sharedv1.TonAmount{
+			Value: balance.Held().String(),
+		},
+	}, nil
+}
+
+func (h *PaymentPrivateHandler) CreateHold(
+	ctx context.Context,
+	req *paymentv1.CreateHoldRequest,
+) (*paymentv1.CreateHoldResponse, error) {
+	reason, err := proto.TransactionReasonToDomain(req.GetReason())
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := proto.TransactionMetadataToDomain(req.GetMetadata())
+	if err != nil {
+		return nil, err
+	}
+	hold, err := h.paymentService.CreateHold(
+		ctx,
+		req.GetTelegramUserId().GetValue(),
+		req.GetTonAmount().GetValue(),
+		reason,
+		metadata,
+		req.GetTtl().AsDuration(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	holdProto, err := proto.HoldToProto(hold)
+	if err != nil {
+		return nil, err
+	}
+	return &paymentv1.CreateHoldResponse{Hold: holdProto}, nil
+}
+
+func (h *PaymentPrivateHandler) CaptureHold(
+	ctx context.Context,
+	req *paymentv1.CaptureHoldRequest,
+) (*paymentv1.CaptureHoldResponse, error) {
+	balance, err := h.paymentService.CaptureHold(ctx, req.GetHoldId())
+	if err != nil {
+		return nil, err
+	}
+	return &paymentv1.CaptureHoldResponse{
+		NewAmount: &sharedv1.TonAmount{
+			Value: balance.TonAmount.String(),
+		},
 	}, nil
 }
 
+func (h *PaymentPrivateHandler) ReleaseHold(
+	ctx context.Context,
+	req *paymentv1.ReleaseHoldRequest,
+) (*paymentv1.ReleaseHoldResponse, error) {
+	hold, err := h.paymentService.ReleaseHold(ctx, req.GetHoldId())
+	if err != nil {
+		return nil, err
+	}
+	holdProto, err := proto.HoldToProto(hold)
+	if err != nil {
+		return nil, err
+	}
+	return &paymentv1.ReleaseHoldResponse{Hold: holdProto}, nil
+}
+
 func (h *PaymentPrivateHandler) PreviewWithdraw(
 	ctx context.Context,
 	req *paymentv1.PreviewWithdrawRequest,
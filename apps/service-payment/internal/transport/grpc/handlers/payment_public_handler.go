@@ -1,11 +1,13 @@
 package grpchandlers
 
 import (
+	"bufio"
 	"context"
 
 	"github.com/ccoveille/go-safecast"
 	"github.com/peterparker2005/giftduels/apps/service-payment/internal/adapter/proto"
 	"github.com/peterparker2005/giftduels/apps/service-payment/internal/config"
+	paymentdomain "github.com/peterparker2005/giftduels/apps/service-payment/internal/domain/payment"
 	"github.com/peterparker2005/giftduels/apps/service-payment/internal/service/payment"
 	"github.com/peterparker2005/giftduels/apps/service-payment/pkg/boc"
 	"github.com/peterparker2005/giftduels/packages/errors"
@@ -16,6 +18,7 @@ import (
 	"github.com/peterparker2005/giftduels/packages/shared"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type PaymentPublicHandler struct {
@@ -43,17 +46,20 @@ func (h *PaymentPublicHandler) GetBalance(
 	if err != nil {
 		return nil, err
 	}
-	balance, err := h.service.GetBalance(ctx, telegramUserID)
+	balances, err := h.service.GetBalances(ctx, telegramUserID)
 	if err != nil {
 		return nil, err
 	}
-	return &paymentv1.GetBalanceResponse{
-		Balance: &paymentv1.UserBalanceView{
-			TonAmount: &sharedv1.TonAmount{
-				Value: balance.TonAmount.String(),
-			},
-		},
-	}, nil
+
+	resp := &paymentv1.GetBalanceResponse{
+		Balances: proto.BalancesToProto(balances),
+	}
+	for _, b := range balances {
+		if b.Currency == paymentdomain.CurrencyTON {
+			resp.Balance = proto.BalanceViewToProto(b)
+		}
+	}
+	return resp, nil
 }
 
 func (h *PaymentPublicHandler) PreviewWithdraw(
@@ -72,13 +78,27 @@ func (h *PaymentPublicHandler) DepositTon(
 		return nil, err
 	}
 
+	currency, err := proto.CurrencyToDomain(req.GetCurrency())
+	if err != nil {
+		return nil, errors.NewError(
+			errors.WithGRPCCode(codes.InvalidArgument),
+			errors.WithErrorCode(errorsv1.ErrorCode_ERROR_CODE_VALIDATION_GENERAL),
+			errors.WithMessage("unknown currency"),
+		)
+	}
+
 	// 1) Создаём запись в БД с «сырым» UUID-payload:
 	tonAmount := req.GetTonAmount().GetValue()
-	deposit, err := h.service.CreateDeposit(ctx, userID, tonAmount)
+	deposit, err := h.service.CreateDeposit(ctx, userID, currency, tonAmount)
 	if err != nil {
 		return nil, err
 	}
 
+	var jettonMaster string
+	if currency == paymentdomain.CurrencyUSDT {
+		jettonMaster = h.cfg.Ton.USDT.MasterAddress
+	}
+
 	// 2) Упаковываем этот UUID в BOC и кодируем
 	bocPayload, err := boc.EncodeStringAsBOC(deposit.Payload)
 	if err != nil {
@@ -94,9 +114,12 @@ func (h *PaymentPublicHandler) DepositTon(
 	return &paymentv1.DepositTonResponse{
 		DepositId: deposit.ID.String(),
 		// FIXME: переделать на TonAmount?
-		NanoTonAmount:   deposit.AmountNano,
-		Payload:         bocPayload,
-		TreasuryAddress: h.cfg.Ton.WalletAddress,
+		NanoTonAmount:       deposit.AmountNano,
+		Payload:             bocPayload,
+		TreasuryAddress:     h.cfg.Ton.WalletAddress,
+		ExpiresAt:           timestamppb.New(deposit.ExpiresAt),
+		Currency:            proto.CurrencyToProto(deposit.Currency),
+		JettonMasterAddress: jettonMaster,
 	}, nil
 }
 
@@ -113,7 +136,15 @@ func (h *PaymentPublicHandler) GetTransactionHistory(
 		req.GetPagination().GetPage(),
 		req.GetPagination().GetPageSize(),
 	)
-	transactions, count, err := h.service.GetTransactionHistory(ctx, userID, pagination)
+	filter, err := proto.TransactionFilterToDomain(req.GetFilter())
+	if err != nil {
+		return nil, invalidTransactionFilter(err)
+	}
+	transactions, count, err := h.service.GetTransactionHistory(ctx, userID, filter, pagination)
+	if err != nil {
+		return nil, err
+	}
+	totals, err := h.service.GetTransactionTotals(ctx, userID, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -132,8 +163,18 @@ func (h *PaymentPublicHandler) GetTransactionHistory(
 		return nil, err
 	}
 
+	totalsProto := make([]*paymentv1.TransactionReasonTotal, 0, len(totals))
+	for _, total := range totals {
+		totalProto, mapErr := proto.TransactionTotalToProto(total)
+		if mapErr != nil {
+			return nil, mapErr
+		}
+		totalsProto = append(totalsProto, totalProto)
+	}
+
 	return &paymentv1.GetTransactionHistoryResponse{
 		Transactions: transactionsProto,
+		Totals:       totalsProto,
 		Pagination: &sharedv1.PageResponse{
 			Total:      countInt32,
 			Page:       pagination.Page(),
@@ -142,3 +183,102 @@ func (h *PaymentPublicHandler) GetTransactionHistory(
 		},
 	}, nil
 }
+
+// exportChunkSize — размер кусков, которыми выгрузка уходит в стрим.
+const exportChunkSize = 32 * 1024
+
+func (h *PaymentPublicHandler) ExportTransactions(
+	req *paymentv1.ExportTransactionsRequest,
+	srv paymentv1.PaymentPublicService_ExportTransactionsServer,
+) error {
+	ctx := srv.Context()
+	userID, err := authctx.TelegramUserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	filter, err := proto.TransactionFilterToDomain(req.GetFilter())
+	if err != nil {
+		return invalidTransactionFilter(err)
+	}
+	format, err := proto.ExportFormatToDomain(req.GetFormat())
+	if err != nil {
+		return errors.NewError(
+			errors.WithGRPCCode(codes.InvalidArgument),
+			errors.WithErrorCode(errorsv1.ErrorCode_ERROR_CODE_VALIDATION_GENERAL),
+			errors.WithMessage(err.Error()),
+		)
+	}
+
+	w := bufio.NewWriterSize(exportStreamWriter{srv: srv}, exportChunkSize)
+	if err = h.service.ExportTransactions(ctx, userID, filter, format, w); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// exportStreamWriter отправляет каждую запись отдельным сообщением стрима.
+type exportStreamWriter struct {
+	srv paymentv1.PaymentPublicService_ExportTransactionsServer
+}
+
+func (w exportStreamWriter) Write(p []byte) (int, error) {
+	// буфер bufio переиспользуется, поэтому сообщение получает копию
+	chunk := make([]byte, len(p))
+	copy(chunk, p)
+	if err := w.srv.Send(&paymentv1.ExportTransactionsResponse{Chunk: chunk}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func invalidTransactionFilter(err error) error {
+	return errors.NewError(
+		errors.WithGRPCCode(codes.InvalidArgument),
+		errors.WithErrorCode(errorsv1.ErrorCode_ERROR_CODE_VALIDATION_GENERAL),
+		errors.WithMessage("invalid transaction filter: "+err.Error()),
+	)
+}
+
+func (h *PaymentPublicHandler) WithdrawTon(
+	ctx context.Context,
+	req *paymentv1.WithdrawTonRequest,
+) (*paymentv1.WithdrawTonResponse, error) {
+	userID, err := authctx.TelegramUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	withdrawal, balance, err := h.service.WithdrawTon(
+		ctx,
+		userID,
+		req.GetDestination(),
+		req.GetTonAmount().GetValue(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &paymentv1.WithdrawTonResponse{
+		WithdrawalId: withdrawal.ID.String(),
+		Destination:  withdrawal.Destination,
+		Balance:      proto.BalanceViewToProto(balance),
+	}, nil
+}
+
+func (h *PaymentPublicHandler) GetDepositAddress(
+	ctx context.Context,
+	_ *emptypb.Empty,
+) (*paymentv1.GetDepositAddressResponse, error) {
+	telegramUserID, err := authctx.TelegramUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	addr, err := h.service.GetDepositAddress(ctx, telegramUserID)
+	if err != nil {
+		return nil, err
+	}
+	return &paymentv1.GetDepositAddressResponse{
+		Address: addr.Address,
+	}, nil
+}
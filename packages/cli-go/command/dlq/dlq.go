@@ -0,0 +1,260 @@
+// Package dlq implements the `dlq` subcommand shared by service CLIs: it
+// lists, republishes and purges messages from the service poison queue.
+package dlq
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/peterparker2005/giftduels/packages/events"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const defaultListLimit = 50
+
+// Route tells how to decode payloads poisoned by a router handler.
+type Route struct {
+	Handler string
+	// Payload returns an empty event message; nil means the payload is not
+	// protobuf and is printed as is.
+	Payload func() proto.Message
+}
+
+// Setup is resolved from the service config when the command runs.
+type Setup struct {
+	AMQPURI string
+	// Poison is the config of the exchange the service router publishes
+	// poisoned messages to.
+	Poison events.AMQPConfig
+	Routes []Route
+}
+
+type options struct {
+	filter Filter
+	limit  int
+	yes    bool
+}
+
+// NewCmdDLQ builds the `dlq` command. load is called lazily so that --help
+// works without a config.
+func NewCmdDLQ(load func() (*Setup, error)) *cobra.Command {
+	opts := &options{}
+
+	cmd := &cobra.Command{
+		Use:   "dlq",
+		Short: "Inspect and re-drive poison queue messages",
+		Long: `Work with messages that exhausted handler retries:
+  list      - Print poison messages with headers and decoded payloads
+  republish - Send selected messages back to their original handler queue
+  purge     - Delete selected messages (DESTRUCTIVE!)
+
+Messages are selected with --id, --topic, --handler and --error.`,
+	}
+
+	flags := cmd.PersistentFlags()
+	flags.StringSliceVar(&opts.filter.IDs, "id", nil, "message id (x-message-id), repeatable")
+	flags.StringVar(&opts.filter.Topic, "topic", "", "original topic of the message")
+	flags.StringVar(&opts.filter.Handler, "handler", "", "router handler that poisoned the message")
+	flags.StringVar(&opts.filter.Error, "error", "", "substring of the handler error (case-insensitive)")
+	flags.IntVar(&opts.limit, "limit", 0, "max messages to process (0 = whole queue, list defaults to 50)")
+
+	cmd.AddCommand(
+		newListCmd(load, opts),
+		newRepublishCmd(load, opts),
+		newPurgeCmd(load, opts),
+	)
+
+	return cmd
+}
+
+func newListCmd(load func() (*Setup, error), opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "Print poison messages as JSON lines",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			limit := opts.limit
+			if limit == 0 {
+				limit = defaultListLimit
+			}
+
+			return withQueue(load, func(s *Setup, q *Queue) error {
+				msgs, err := q.Fetch(opts.filter, limit)
+				if err != nil {
+					return err
+				}
+
+				for _, m := range msgs {
+					if err = printMessage(cmd.OutOrStdout(), s.Routes, m); err != nil {
+						return err
+					}
+				}
+				slog.Default().Info("📋 poison messages", "queue", q.Name(), "count", len(msgs))
+				return nil
+			})
+		},
+	}
+}
+
+func newRepublishCmd(load func() (*Setup, error), opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "republish",
+		Short: "Send selected messages back to their handler queue",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := requireSelection(opts); err != nil {
+				return err
+			}
+
+			return withQueue(load, func(_ *Setup, q *Queue) error {
+				msgs, err := q.Fetch(opts.filter, opts.limit)
+				if err != nil {
+					return err
+				}
+
+				for _, m := range msgs {
+					if err = q.Republish(cmd.Context(), m); err != nil {
+						return fmt.Errorf("republish %s: %w", m.Message.UUID, err)
+					}
+					slog.Default().Info("🔁 republished",
+						"id", m.Message.UUID, "topic", m.Topic(), "handler", m.Handler())
+				}
+				slog.Default().Info("✅ done", "republished", len(msgs))
+				return nil
+			})
+		},
+	}
+}
+
+func newPurgeCmd(load func() (*Setup, error), opts *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "purge",
+		Short: "Delete selected messages from the poison queue (DESTRUCTIVE)",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := requireSelection(opts); err != nil {
+				return err
+			}
+
+			return withQueue(load, func(_ *Setup, q *Queue) error {
+				msgs, err := q.Fetch(opts.filter, opts.limit)
+				if err != nil {
+					return err
+				}
+				if len(msgs) == 0 {
+					slog.Default().Info("nothing to purge")
+					return nil
+				}
+
+				if !opts.yes && !confirm(
+					cmd.InOrStdin(),
+					fmt.Sprintf("⚠️ This will delete %d message(s) from %s. Type 'yes' to proceed: ",
+						len(msgs), q.Name()),
+				) {
+					slog.Default().Error("❌ cancelled")
+					return nil
+				}
+
+				for _, m := range msgs {
+					if err = q.Purge(m); err != nil {
+						return fmt.Errorf("purge %s: %w", m.Message.UUID, err)
+					}
+				}
+				slog.Default().Info("🗑️ purged", "count", len(msgs))
+				return nil
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.yes, "yes", false, "skip confirmation")
+
+	return cmd
+}
+
+// requireSelection guards bulk operations: re-driving or deleting the whole
+// queue must be asked for explicitly.
+func requireSelection(opts *options) error {
+	f := opts.filter
+	if len(f.IDs) == 0 && f.Topic == "" && f.Handler == "" && f.Error == "" && opts.limit == 0 {
+		return errors.New("select messages with --id, --topic, --handler, --error or --limit")
+	}
+	return nil
+}
+
+func withQueue(load func() (*Setup, error), fn func(*Setup, *Queue) error) error {
+	s, err := load()
+	if err != nil {
+		return err
+	}
+
+	q, err := Open(s.AMQPURI, s.Poison)
+	if err != nil {
+		return err
+	}
+	defer q.Close()
+
+	return fn(s, q)
+}
+
+type messageView struct {
+	ID      string            `json:"id"`
+	Topic   string            `json:"topic"`
+	Handler string            `json:"handler"`
+	Error   string            `json:"error"`
+	Headers map[string]string `json:"headers"`
+	Payload json.RawMessage   `json:"payload"`
+}
+
+func printMessage(w io.Writer, routes []Route, p *Poisoned) error {
+	view := messageView{
+		ID:      p.Message.UUID,
+		Topic:   p.Topic(),
+		Handler: p.Handler(),
+		Error:   p.Reason(),
+		Headers: p.Message.Metadata,
+		Payload: decodePayload(routes, p),
+	}
+
+	line, err := json.Marshal(view)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(line))
+	return err
+}
+
+// decodePayload renders a protobuf payload as JSON when the handler is known,
+// falling back to a string (or base64 for binary data).
+func decodePayload(routes []Route, p *Poisoned) json.RawMessage {
+	for _, r := range routes {
+		if r.Handler != p.Handler() || r.Payload == nil {
+			continue
+		}
+		pm := r.Payload()
+		if err := proto.Unmarshal(p.Message.Payload, pm); err != nil {
+			break
+		}
+		if out, err := protojson.Marshal(pm); err == nil {
+			return out
+		}
+		break
+	}
+
+	var raw any = string(p.Message.Payload)
+	if !utf8.Valid(p.Message.Payload) {
+		raw = p.Message.Payload // encoding/json writes []byte as base64
+	}
+	out, _ := json.Marshal(raw)
+	return out
+}
+
+func confirm(in io.Reader, prompt string) bool {
+	slog.Default().Info(prompt)
+	line, _ := bufio.NewReader(in).ReadString('\n')
+	return strings.TrimSpace(line) == "yes"
+}
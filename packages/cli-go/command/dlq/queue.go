@@ -0,0 +1,248 @@
+package dlq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	watermillAmqp "github.com/ThreeDotsLabs/watermill-amqp/v3/pkg/amqp"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/ThreeDotsLabs/watermill/message/router/middleware"
+	"github.com/peterparker2005/giftduels/packages/events"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// poisonKeys are the metadata entries added by middleware.PoisonQueue. They
+// are dropped on republish so the message looks like the original one.
+//
+//nolint:gochecknoglobals // read-only lookup table
+var poisonKeys = []string{
+	middleware.ReasonForPoisonedKey,
+	middleware.PoisonedTopicKey,
+	middleware.PoisonedHandlerKey,
+	middleware.PoisonedSubscriberKey,
+}
+
+// Filter selects poison messages. Empty fields match everything.
+type Filter struct {
+	IDs     []string
+	Topic   string
+	Handler string
+	Error   string
+}
+
+func (f Filter) match(msg *message.Message) bool {
+	if len(f.IDs) > 0 && !contains(f.IDs, msg.UUID) {
+		return false
+	}
+	if f.Topic != "" && msg.Metadata.Get(middleware.PoisonedTopicKey) != f.Topic {
+		return false
+	}
+	if f.Handler != "" && msg.Metadata.Get(middleware.PoisonedHandlerKey) != f.Handler {
+		return false
+	}
+	if f.Error != "" && !strings.Contains(
+		strings.ToLower(msg.Metadata.Get(middleware.ReasonForPoisonedKey)),
+		strings.ToLower(f.Error),
+	) {
+		return false
+	}
+	return true
+}
+
+// Poisoned is a message fetched from the poison queue.
+type Poisoned struct {
+	Message  *message.Message
+	delivery amqp.Delivery
+}
+
+// Topic is the topic the message was consumed from before it was poisoned.
+func (p *Poisoned) Topic() string { return p.Message.Metadata.Get(middleware.PoisonedTopicKey) }
+
+// Handler is the router handler that failed to process the message.
+func (p *Poisoned) Handler() string { return p.Message.Metadata.Get(middleware.PoisonedHandlerKey) }
+
+// Reason is the last handler error.
+func (p *Poisoned) Reason() string { return p.Message.Metadata.Get(middleware.ReasonForPoisonedKey) }
+
+// Queue is a direct AMQP view of the service poison queue. Messages are
+// fetched with basic.get and stay unacknowledged until Ack; everything that
+// was not acked goes back to the queue on Close.
+type Queue struct {
+	conn      *amqp.Connection
+	ch        *amqp.Channel
+	name      string
+	cfg       events.AMQPConfig
+	marshaler watermillAmqp.Marshaler
+}
+
+// Open connects to RabbitMQ and makes sure the poison queue exists.
+func Open(uri string, cfg events.AMQPConfig) (*Queue, error) {
+	conn, err := amqp.Dial(uri)
+	if err != nil {
+		return nil, fmt.Errorf("dial amqp: %w", err)
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("open channel: %w", err)
+	}
+	if err = ch.Confirm(false); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("enable publisher confirms: %w", err)
+	}
+
+	built := cfg.Build()
+	topic := cfg.PoisonTopic()
+	q := &Queue{
+		conn:      conn,
+		ch:        ch,
+		name:      built.Queue.GenerateName(topic),
+		cfg:       cfg,
+		marshaler: built.Marshaler,
+	}
+
+	if err = q.declare(built, topic); err != nil {
+		_ = q.Close()
+		return nil, err
+	}
+
+	return q, nil
+}
+
+func (q *Queue) declare(built watermillAmqp.Config, topic string) error {
+	if err := q.ch.ExchangeDeclare(
+		q.cfg.Exchange,
+		built.Exchange.Type,
+		built.Exchange.Durable,
+		built.Exchange.AutoDeleted,
+		built.Exchange.Internal,
+		built.Exchange.NoWait,
+		built.Exchange.Arguments,
+	); err != nil {
+		return fmt.Errorf("declare exchange %s: %w", q.cfg.Exchange, err)
+	}
+
+	if _, err := q.ch.QueueDeclare(
+		q.name,
+		built.Queue.Durable,
+		built.Queue.AutoDelete,
+		built.Queue.Exclusive,
+		built.Queue.NoWait,
+		built.Queue.Arguments,
+	); err != nil {
+		return fmt.Errorf("declare queue %s: %w", q.name, err)
+	}
+
+	if err := q.ch.QueueBind(q.name, topic, q.cfg.Exchange, false, nil); err != nil {
+		return fmt.Errorf("bind queue %s: %w", q.name, err)
+	}
+
+	return nil
+}
+
+// Name returns the poison queue name.
+func (q *Queue) Name() string { return q.name }
+
+// Fetch reads up to limit messages matching the filter. A non-positive limit
+// reads the whole queue. Messages that do not match stay unacked and return
+// to the queue on Close.
+func (q *Queue) Fetch(filter Filter, limit int) ([]*Poisoned, error) {
+	var out []*Poisoned
+	for limit <= 0 || len(out) < limit {
+		d, ok, err := q.ch.Get(q.name, false)
+		if err != nil {
+			return nil, fmt.Errorf("get from %s: %w", q.name, err)
+		}
+		if !ok {
+			break
+		}
+
+		msg, err := q.marshaler.Unmarshal(d)
+		if err != nil {
+			return nil, fmt.Errorf("unmarshal delivery %d: %w", d.DeliveryTag, err)
+		}
+
+		if filter.match(msg) {
+			out = append(out, &Poisoned{Message: msg, delivery: d})
+		}
+	}
+	return out, nil
+}
+
+// Republish sends the message straight to the queue of the handler that
+// poisoned it (via the default exchange), so other consumers of the original
+// topic don't see it again, and then removes it from the poison queue.
+func (q *Queue) Republish(ctx context.Context, p *Poisoned) error {
+	topic := p.Topic()
+	if topic == "" {
+		return errors.New("message has no original topic")
+	}
+	target := q.cfg.Build().Queue.GenerateName(topic)
+	if err := q.ensureQueue(target); err != nil {
+		return err
+	}
+
+	headers := amqp.Table{}
+	for k, v := range p.delivery.Headers {
+		if !contains(poisonKeys, k) {
+			headers[k] = v
+		}
+	}
+
+	confirmation, err := q.ch.PublishWithDeferredConfirmWithContext(ctx, "", target, false, false, amqp.Publishing{
+		Headers:      headers,
+		ContentType:  p.delivery.ContentType,
+		DeliveryMode: amqp.Persistent,
+		Body:         p.delivery.Body,
+	})
+	if err != nil {
+		return fmt.Errorf("publish to %s: %w", target, err)
+	}
+	acked, err := confirmation.WaitContext(ctx)
+	if err != nil {
+		return fmt.Errorf("confirm publish to %s: %w", target, err)
+	}
+	if !acked {
+		return fmt.Errorf("broker rejected publish to %s", target)
+	}
+
+	return p.delivery.Ack(false)
+}
+
+// ensureQueue checks that the target queue exists: the default exchange
+// silently drops messages for unknown queues. A failed passive declare closes
+// the channel, so it runs on a separate one.
+func (q *Queue) ensureQueue(name string) error {
+	ch, err := q.conn.Channel()
+	if err != nil {
+		return fmt.Errorf("open channel: %w", err)
+	}
+	defer ch.Close()
+
+	if _, err = ch.QueueDeclarePassive(name, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("queue %s: %w", name, err)
+	}
+	return nil
+}
+
+// Purge removes the message from the poison queue.
+func (q *Queue) Purge(p *Poisoned) error {
+	return p.delivery.Ack(false)
+}
+
+// Close returns all unacked messages to the queue and closes the connection.
+func (q *Queue) Close() error {
+	return q.conn.Close()
+}
+
+func contains(values []string, v string) bool {
+	for _, s := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
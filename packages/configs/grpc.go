@@ -18,4 +18,6 @@ type GRPCConfig struct {
 	Payment     ServiceConfig `yaml:"payment_service"      env-prefix:"GRPC_PAYMENT_SERVICE_"`
 	TelegramBot ServiceConfig `yaml:"telegram_bot_service" env-prefix:"GRPC_TELEGRAM_BOT_SERVICE_"`
 	Event       ServiceConfig `yaml:"event_service"        env-prefix:"GRPC_EVENT_SERVICE_"`
+	// TelegramCustody is the userbot owning the custody account with deposited gifts.
+	TelegramCustody ServiceConfig `yaml:"telegram_custody_service" env-prefix:"GRPC_TELEGRAM_CUSTODY_SERVICE_"`
 }
@@ -1,9 +1,11 @@
 package events
 
 import (
+	"fmt"
 	"time"
 
 	watermillAmqp "github.com/ThreeDotsLabs/watermill-amqp/v3/pkg/amqp"
+	"github.com/ThreeDotsLabs/watermill/message"
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
@@ -55,3 +57,20 @@ func (c *AMQPConfig) Build() watermillAmqp.Config {
 		},
 	}
 }
+
+// PoisonTopic returns the routing key the service router publishes messages
+// that exhausted their retries with.
+func (c *AMQPConfig) PoisonTopic() string {
+	return c.Exchange + ".poison"
+}
+
+// DeclarePoisonQueue creates the durable queue bound to PoisonTopic, so that
+// poisoned messages are kept until inspected instead of being dropped by the
+// exchange.
+func DeclarePoisonQueue(sub message.Subscriber, c AMQPConfig) error {
+	initializer, ok := sub.(message.SubscribeInitializer)
+	if !ok {
+		return fmt.Errorf("subscriber %T cannot declare queues", sub)
+	}
+	return initializer.SubscribeInitialize(c.PoisonTopic())
+}
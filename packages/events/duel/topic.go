@@ -17,3 +17,7 @@ const (
 
 	TopicDuelCreateFailed events.Topic = "duel.create.failed"
 )
+
+// MetadataDuelID is the message metadata key carrying the duel a
+// duel.create.failed gift return belongs to.
+const MetadataDuelID = "duel_id"
@@ -7,4 +7,7 @@ const SQLOutboxTopic = "gift_sql_outbox"
 const (
 	TopicGiftWithdrawRequested events.Topic = "gift.withdraw.requested"
 	TopicGiftDeposited         events.Topic = "gift.deposited"
+	// TopicGiftWithdrawPaidAfterExpiry is published when a Stars invoice is paid
+	// after expiry and the payment has to be refunded.
+	TopicGiftWithdrawPaidAfterExpiry events.Topic = "gift.withdraw.paid-after-expiry"
 )
@@ -17,6 +17,8 @@ type Clients struct {
 	Payment     *PaymentClient
 	TelegramBot *TelegramBotClient
 	Duel        *DuelClient
+	// TelegramCustody is nil unless its address is configured.
+	TelegramCustody *TelegramCustodyClient
 }
 
 // CreateDialOptions returns the base set of options for dial.
@@ -62,13 +64,20 @@ func NewClients(
 		c.Close()
 		return nil, fmt.Errorf("duel client: %w", err)
 	}
+	if cfg.TelegramCustody.Port != "" {
+		c.TelegramCustody, err = NewTelegramCustodyClient(cfg.TelegramCustody.Address(), dialOpts...)
+		if err != nil {
+			c.Close()
+			return nil, fmt.Errorf("telegram custody client: %w", err)
+		}
+	}
 	return c, nil
 }
 
 // Close closes all clients.
 func (c *Clients) Close() {
 	for _, cl := range []io.Closer{
-		c.Identity, c.Gift, c.Payment, c.TelegramBot, c.Duel,
+		c.Identity, c.Gift, c.Payment, c.TelegramBot, c.Duel, c.TelegramCustody,
 	} {
 		if cl != nil {
 			_ = cl.Close()
@@ -26,3 +26,27 @@ func NewTelegramBotClient(address string, opts ...grpc.DialOption) (*TelegramBot
 func (c *TelegramBotClient) Close() error {
 	return c.conn.Close()
 }
+
+type TelegramCustodyClient struct {
+	conn    *grpc.ClientConn
+	Private telegrambotv1.TelegramCustodyPrivateServiceClient
+}
+
+func NewTelegramCustodyClient(address string, opts ...grpc.DialOption) (*TelegramCustodyClient, error) {
+	conn, err := grpc.NewClient(address, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("dial telegram custody service %s: %w", address, err)
+	}
+	return &TelegramCustodyClient{
+		conn:    conn,
+		Private: telegrambotv1.NewTelegramCustodyPrivateServiceClient(conn),
+	}, nil
+}
+
+// Close is nil-safe because the client is optional.
+func (c *TelegramCustodyClient) Close() error {
+	if c == nil {
+		return nil
+	}
+	return c.conn.Close()
+}
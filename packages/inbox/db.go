@@ -0,0 +1,52 @@
+package inbox
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DB is a sqlc DBTX that runs queries in the inbox transaction when ctx
+// carries one and on the pool otherwise. Repositories built on it take part
+// in the handler transaction without any changes to their code.
+type DB struct {
+	pool *pgxpool.Pool
+}
+
+func NewDB(pool *pgxpool.Pool) *DB {
+	return &DB{pool: pool}
+}
+
+func (d *DB) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx.Exec(ctx, sql, args...)
+	}
+	return d.pool.Exec(ctx, sql, args...)
+}
+
+func (d *DB) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx.Query(ctx, sql, args...)
+	}
+	return d.pool.Query(ctx, sql, args...)
+}
+
+func (d *DB) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx.QueryRow(ctx, sql, args...)
+	}
+	return d.pool.QueryRow(ctx, sql, args...)
+}
+
+// BeginTx starts a transaction nested into the inbox transaction (a
+// savepoint) when ctx carries one, and a regular transaction otherwise.
+// Commit of the nested transaction releases the savepoint; the work becomes
+// durable together with the processed-message record.
+func (d *DB) BeginTx(ctx context.Context) (pgx.Tx, error) {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx.Begin(ctx)
+	}
+	return d.pool.Begin(ctx)
+}
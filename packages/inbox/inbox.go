@@ -0,0 +1,108 @@
+// Package inbox makes AMQP consumers idempotent: every handled message is
+// recorded in the service's own Postgres in the same transaction as the
+// handler's work, so a redelivered message is acked without side effects.
+package inbox
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/peterparker2005/giftduels/packages/logger-go"
+	"go.uber.org/zap"
+)
+
+// Table is the ledger of processed messages. Each consuming service creates
+// it in its own migrations:
+//
+//	CREATE TABLE processed_messages (
+//	    handler      TEXT        NOT NULL,
+//	    message_id   TEXT        NOT NULL,
+//	    processed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+//	    PRIMARY KEY (handler, message_id)
+//	);
+const Table = "processed_messages"
+
+const insertProcessedMessage = `INSERT INTO ` + Table + ` (handler, message_id)
+VALUES ($1, $2)
+ON CONFLICT (handler, message_id) DO NOTHING`
+
+// Inbox records processed message ids and skips duplicates.
+type Inbox struct {
+	pool *pgxpool.Pool
+	log  *logger.Logger
+}
+
+func New(pool *pgxpool.Pool, log *logger.Logger) *Inbox {
+	return &Inbox{pool: pool, log: log}
+}
+
+// Middleware wraps a router handler. It opens a transaction, claims the
+// (handler, x-message-id) pair and runs the handler with the transaction in
+// the message context (see TxFromContext). The transaction is committed only
+// when the handler succeeds, so a failed attempt leaves no trace and is
+// retried as usual. A message that was already processed is acked as is.
+//
+// It must be the innermost router middleware so that every retry attempt
+// gets a fresh transaction.
+func (i *Inbox) Middleware(h message.HandlerFunc) message.HandlerFunc {
+	return func(msg *message.Message) ([]*message.Message, error) {
+		ctx := msg.Context()
+		handler := message.HandlerNameFromCtx(ctx)
+
+		// Messages without x-message-id (published outside events.AMQPConfig)
+		// cannot be deduplicated.
+		if msg.UUID == "" {
+			i.log.Warn("message without id, inbox skipped", zap.String("handler", handler))
+			return h(msg)
+		}
+
+		tx, err := i.pool.Begin(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("inbox: begin tx: %w", err)
+		}
+		defer func() {
+			_ = tx.Rollback(context.WithoutCancel(ctx))
+		}()
+
+		tag, err := tx.Exec(ctx, insertProcessedMessage, handler, msg.UUID)
+		if err != nil {
+			return nil, fmt.Errorf("inbox: claim message: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			i.log.Info("duplicate message skipped",
+				zap.String("handler", handler),
+				zap.String("message_id", msg.UUID),
+			)
+			return nil, nil
+		}
+
+		msg.SetContext(WithTx(ctx, tx))
+		produced, err := h(msg)
+		msg.SetContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = tx.Commit(ctx); err != nil {
+			return nil, fmt.Errorf("inbox: commit: %w", err)
+		}
+
+		return produced, nil
+	}
+}
+
+type txKey struct{}
+
+// WithTx returns a copy of ctx carrying tx.
+func WithTx(ctx context.Context, tx pgx.Tx) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// TxFromContext returns the inbox transaction of the message being handled.
+func TxFromContext(ctx context.Context) (pgx.Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(pgx.Tx)
+	return tx, ok
+}
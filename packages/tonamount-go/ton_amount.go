@@ -12,6 +12,8 @@ import (
 const (
 	tonPricePrecision = 2
 	tonNanoScale      = 9
+	// percentScale shifts a percent value into a fraction (5% -> 0.05).
+	percentScale = 2
 )
 
 //nolint:gochecknoglobals // required for ton amount
@@ -100,6 +102,12 @@ func (t *TonAmount) Sub(o *TonAmount) *TonAmount {
 	return &TonAmount{d: t.Decimal().Sub(o.Decimal())}
 }
 
+// Percent returns percent % of t, rounded to tonPricePrecision.
+// Marketplace and sell-back fees both go through it so they round the same way.
+func (t *TonAmount) Percent(percent decimal.Decimal) (*TonAmount, error) {
+	return NewTonAmountFromString(t.d.Mul(percent).Shift(-percentScale).String())
+}
+
 // IsZero checks if the value is zero.
 func (t *TonAmount) IsZero() bool {
 	return t.Decimal().IsZero()
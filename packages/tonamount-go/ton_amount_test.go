@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/peterparker2005/giftduels/packages/tonamount-go"
+	"github.com/shopspring/decimal"
 )
 
 func TestNewTonAmountFromString(t *testing.T) {
@@ -138,3 +139,40 @@ func TestJSONMarshalling(t *testing.T) {
 		t.Errorf("After Unmarshal, String() = %q; want %q", got, "3.5")
 	}
 }
+
+func TestPercent(t *testing.T) {
+	cases := []struct {
+		amount      string
+		percent     string
+		want        string
+		expectError bool
+	}{
+		{"100", "5", "5", false},
+		{"12.34", "5", "0.62", false},
+		{"10", "80", "8", false},
+		{"0.01", "5", "0", false},
+		{"3", "33.3", "1", false},
+		{"10", "-5", "", true},
+	}
+
+	for _, c := range cases {
+		amt, err := tonamount.NewTonAmountFromString(c.amount)
+		if err != nil {
+			t.Fatalf("NewTonAmountFromString(%q): %v", c.amount, err)
+		}
+		got, err := amt.Percent(decimal.RequireFromString(c.percent))
+		if c.expectError {
+			if err == nil {
+				t.Errorf("Percent(%s, %s) expected error, got none", c.amount, c.percent)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Percent(%s, %s) unexpected error: %v", c.amount, c.percent, err)
+			continue
+		}
+		if got.String() != c.want {
+			t.Errorf("Percent(%s, %s) = %s; want %s", c.amount, c.percent, got, c.want)
+		}
+	}
+}